@@ -0,0 +1,92 @@
+package loop
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/swap"
+)
+
+const (
+	// budgetRoutingFeeBase is the margin that is added per off-chain
+	// payment to the estimated routing fee when weighing the routing fees
+	// against the on-chain fee while dividing a total cost budget. It
+	// leaves room for routes that are more expensive than the estimate.
+	budgetRoutingFeeBase = btcutil.Amount(10)
+)
+
+var (
+	// ErrBudgetTooLow is returned when a total cost budget does not cover
+	// the quoted swap and miner fees.
+	ErrBudgetTooLow = errors.New("budget does not cover quoted fees")
+)
+
+// BudgetFromPPM returns the total cost budget for a swap of the given amount
+// when the budget is expressed in parts per million of the swap amount.
+func BudgetFromPPM(amt btcutil.Amount, ppm uint64) btcutil.Amount {
+	return swap.CalcFee(amt, 0, int64(ppm))
+}
+
+// ApplyLoopOutBudget sets all fee limits of a loop out request from a single
+// total cost budget, based on a quote for the request. The quoted swap fee is
+// always reserved in full, because the server won't accept less. The quoted
+// miner fee is reserved as a minimum for the sweep. What remains of the
+// budget is divided over the sweep and the two off-chain payments, in
+// proportion to their estimated cost. The sum of the resulting limits never
+// exceeds the budget.
+func ApplyLoopOutBudget(request *OutRequest, budget btcutil.Amount,
+	quote *LoopOutQuote) error {
+
+	required := quote.SwapFee + quote.MinerFee
+	if budget < required {
+		log.Warnf("Budget %v below quoted swap fee %v plus miner "+
+			"fee %v", budget, quote.SwapFee, quote.MinerFee)
+
+		return ErrBudgetTooLow
+	}
+
+	numSwaps := btcutil.Amount(1)
+	if quote.NumSwaps > 1 {
+		numSwaps = btcutil.Amount(quote.NumSwaps)
+	}
+
+	// Every swap makes two off-chain payments that each get a margin on
+	// top of the estimated routing fee.
+	swapRoutingWeight := quote.SwapRoutingFee +
+		budgetRoutingFeeBase*numSwaps
+	prepayRoutingWeight := quote.PrepayRoutingFee +
+		budgetRoutingFeeBase*numSwaps
+
+	shares := allocateLimit(budget-required, []btcutil.Amount{
+		quote.MinerFee, swapRoutingWeight, prepayRoutingWeight,
+	})
+
+	request.MaxSwapFee = quote.SwapFee
+	request.MaxPrepayAmount = quote.PrepayAmount
+	request.MaxMinerFee = quote.MinerFee + shares[0]
+	request.MaxSwapRoutingFee = shares[1]
+	request.MaxPrepayRoutingFee = shares[2]
+
+	return nil
+}
+
+// ApplyLoopInBudget sets all fee limits of a loop in request from a single
+// total cost budget, based on a quote for the request. The quoted swap fee is
+// reserved in full and the rest of the budget is available for the htlc
+// transaction.
+func ApplyLoopInBudget(request *LoopInRequest, budget btcutil.Amount,
+	quote *LoopInQuote) error {
+
+	required := quote.SwapFee + quote.MinerFee
+	if budget < required {
+		log.Warnf("Budget %v below quoted swap fee %v plus miner "+
+			"fee %v", budget, quote.SwapFee, quote.MinerFee)
+
+		return ErrBudgetTooLow
+	}
+
+	request.MaxSwapFee = quote.SwapFee
+	request.MaxMinerFee = budget - quote.SwapFee
+
+	return nil
+}
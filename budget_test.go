@@ -0,0 +1,89 @@
+package loop
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcutil"
+)
+
+// TestApplyLoopOutBudget tests the division of a total cost budget over the
+// limits of a loop out request.
+func TestApplyLoopOutBudget(t *testing.T) {
+	quote := &LoopOutQuote{
+		SwapFee:      testSwapFee,
+		PrepayAmount: testFixedPrepayAmount,
+		MinerFee:     300,
+		NumSwaps:     1,
+	}
+
+	// A budget that doesn't cover the quoted fees is rejected.
+	request := &OutRequest{Amount: 50000}
+	err := ApplyLoopOutBudget(
+		request, quote.SwapFee+quote.MinerFee-1, quote,
+	)
+	if err != ErrBudgetTooLow {
+		t.Fatalf("expected budget too low, got %v", err)
+	}
+
+	budget := btcutil.Amount(3000)
+	if err := ApplyLoopOutBudget(request, budget, quote); err != nil {
+		t.Fatal(err)
+	}
+
+	if request.MaxSwapFee != quote.SwapFee {
+		t.Fatalf("expected max swap fee %v, got %v", quote.SwapFee,
+			request.MaxSwapFee)
+	}
+
+	if request.MaxPrepayAmount != quote.PrepayAmount {
+		t.Fatalf("expected max prepay %v, got %v", quote.PrepayAmount,
+			request.MaxPrepayAmount)
+	}
+
+	if request.MaxMinerFee < quote.MinerFee {
+		t.Fatalf("max miner fee %v below quote %v",
+			request.MaxMinerFee, quote.MinerFee)
+	}
+
+	if request.MaxSwapRoutingFee == 0 || request.MaxPrepayRoutingFee == 0 {
+		t.Fatal("expected routing fee budget")
+	}
+
+	// The prepay is part of the swap fee, so the budget covers the swap
+	// fee, the miner fee and both routing fees.
+	total := request.MaxSwapFee + request.MaxMinerFee +
+		request.MaxSwapRoutingFee + request.MaxPrepayRoutingFee
+	if total != budget {
+		t.Fatalf("expected limits to add up to %v, got %v", budget,
+			total)
+	}
+}
+
+// TestApplyLoopInBudget tests the division of a total cost budget over the
+// limits of a loop in request.
+func TestApplyLoopInBudget(t *testing.T) {
+	quote := &LoopInQuote{
+		SwapFee:  testSwapFee,
+		MinerFee: 300,
+	}
+
+	request := &LoopInRequest{Amount: 50000}
+	err := ApplyLoopInBudget(request, testSwapFee+299, quote)
+	if err != ErrBudgetTooLow {
+		t.Fatalf("expected budget too low, got %v", err)
+	}
+
+	if err := ApplyLoopInBudget(request, 1000, quote); err != nil {
+		t.Fatal(err)
+	}
+
+	if request.MaxSwapFee != testSwapFee {
+		t.Fatalf("expected max swap fee %v, got %v", testSwapFee,
+			request.MaxSwapFee)
+	}
+
+	if request.MaxMinerFee != 1000-testSwapFee {
+		t.Fatalf("expected max miner fee %v, got %v",
+			1000-testSwapFee, request.MaxMinerFee)
+	}
+}
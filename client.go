@@ -49,6 +49,12 @@ var (
 	ErrSweepConfTargetTooFar = errors.New("sweep confirmation target is " +
 		"beyond swap expiration height")
 
+	// ErrSweepSafetyMarginTooLarge is returned when the client proposes a
+	// sweep safety margin that leaves no room to wait for the sweep fee
+	// rate ceiling before the expiration height proposed by the server.
+	ErrSweepSafetyMarginTooLarge = errors.New("sweep safety margin " +
+		"leaves no room before swap expiration height")
+
 	// serverRPCTimeout is the maximum time a gRPC request to the server
 	// should be allowed to take.
 	serverRPCTimeout = 30 * time.Second
@@ -70,43 +76,62 @@ type Client struct {
 	lndServices *lndclient.LndServices
 	sweeper     *sweep.Sweeper
 	executor    *executor
+	scheduler   *scheduler
 
 	resumeReady chan struct{}
 	wg          sync.WaitGroup
 
+	// quotes holds the quotes handed out by this client, so that swaps
+	// referencing a quote id can be checked against the quoted figures.
+	quotes quoteCache
+
+	// idempotencyKeys holds the idempotency keys of the swap requests
+	// that are in progress.
+	idempotencyKeys idempotencyKeys
+
 	clientConfig
 }
 
-// NewClient returns a new instance to initiate swaps with.
-func NewClient(dbDir string, serverAddress string, insecure bool,
-	tlsPathServer string, lnd *lndclient.LndServices, maxLSATCost,
-	maxLSATFee btcutil.Amount) (*Client, func(), error) {
+// NewClient returns a new instance to initiate swaps with. The first of the
+// given servers is the default server, which executes the swaps that were
+// created before multiple servers were supported.
+func NewClient(dbDir string, servers []ServerConfig,
+	lnd *lndclient.LndServices, maxLSATCost, maxLSATFee btcutil.Amount,
+	paymentRetryPolicy PaymentRetryPolicy,
+	destAllowList []string) (*Client, func(), error) {
 
-	store, err := loopdb.NewBoltSwapStore(dbDir, lnd.ChainParams)
+	err := ValidateDestAllowList(destAllowList, lnd.ChainParams)
 	if err != nil {
 		return nil, nil, err
 	}
-	lsatStore, err := lsat.NewFileStore(dbDir)
+
+	if err := validateServerConfigs(servers); err != nil {
+		return nil, nil, err
+	}
+
+	store, err := loopdb.NewBoltSwapStore(dbDir, lnd.ChainParams)
 	if err != nil {
 		return nil, nil, err
 	}
 
-	swapServerClient, err := newSwapServerClient(
-		serverAddress, insecure, tlsPathServer, lsatStore, lnd,
-		maxLSATCost, maxLSATFee,
+	swapServers, cleanup, err := connectServers(
+		dbDir, servers, lnd, maxLSATCost, maxLSATFee,
 	)
 	if err != nil {
+		if closeErr := store.Close(); closeErr != nil {
+			log.Errorf("Unable to close swap store: %v", closeErr)
+		}
 		return nil, nil, err
 	}
 
 	config := &clientConfig{
 		LndServices: lnd,
-		Server:      swapServerClient,
+		Servers:     swapServers,
 		Store:       store,
-		LsatStore:   lsatStore,
 		CreateExpiryTimer: func(d time.Duration) <-chan time.Time {
 			return time.NewTimer(d).C
 		},
+		DestAllowList: destAllowList,
 	}
 
 	sweeper := &sweep.Sweeper{
@@ -114,10 +139,11 @@ func NewClient(dbDir string, serverAddress string, insecure bool,
 	}
 
 	executor := newExecutor(&executorConfig{
-		lnd:               lnd,
-		store:             store,
-		sweeper:           sweeper,
-		createExpiryTimer: config.CreateExpiryTimer,
+		lnd:                lnd,
+		store:              store,
+		sweeper:            sweeper,
+		createExpiryTimer:  config.CreateExpiryTimer,
+		paymentRetryPolicy: paymentRetryPolicy,
 	})
 
 	client := &Client{
@@ -128,10 +154,13 @@ func NewClient(dbDir string, serverAddress string, insecure bool,
 		executor:     executor,
 		resumeReady:  make(chan struct{}),
 	}
-
-	cleanup := func() {
-		swapServerClient.Close()
-	}
+	client.scheduler = newScheduler(&schedulerConfig{
+		lnd:      lnd,
+		store:    store,
+		loopOut:  client.LoopOut,
+		loopIn:   client.LoopIn,
+		destAddr: client.NextDestAddr,
+	})
 
 	return client, cleanup, nil
 }
@@ -152,9 +181,9 @@ func (s *Client) FetchSwaps() ([]*SwapInfo, error) {
 
 	for _, swp := range loopOutSwaps {
 		htlc, err := swap.NewHtlc(
-			swp.Contract.CltvExpiry, swp.Contract.SenderKey,
-			swp.Contract.ReceiverKey, swp.Hash, swap.HtlcP2WSH,
-			s.lndServices.ChainParams,
+			swp.Contract.HtlcVersion, swp.Contract.CltvExpiry,
+			swp.Contract.SenderKey, swp.Contract.ReceiverKey,
+			swp.Hash, swap.HtlcP2WSH, s.lndServices.ChainParams,
 		)
 		if err != nil {
 			return nil, err
@@ -167,27 +196,36 @@ func (s *Client) FetchSwaps() ([]*SwapInfo, error) {
 			SwapHash:      swp.Hash,
 			LastUpdate:    swp.LastUpdateTime(),
 			HtlcAddress:   htlc.Address,
+			SweepStrategy: newSweepStrategy(swp.Contract),
+			ServerUpdate:  swp.LastServerUpdate(),
 		})
 	}
 
 	for _, swp := range loopInSwaps {
 		htlc, err := swap.NewHtlc(
-			swp.Contract.CltvExpiry, swp.Contract.SenderKey,
-			swp.Contract.ReceiverKey, swp.Hash, swap.HtlcNP2WSH,
+			swp.Contract.HtlcVersion, swp.Contract.CltvExpiry,
+			swp.Contract.SenderKey, swp.Contract.ReceiverKey,
+			swp.Hash, swp.Contract.HtlcOutputType,
 			s.lndServices.ChainParams,
 		)
 		if err != nil {
 			return nil, err
 		}
 
-		swaps = append(swaps, &SwapInfo{
+		info := &SwapInfo{
 			SwapType:      swap.TypeIn,
 			SwapContract:  swp.Contract.SwapContract,
 			SwapStateData: swp.State(),
 			SwapHash:      swp.Hash,
 			LastUpdate:    swp.LastUpdateTime(),
 			HtlcAddress:   htlc.Address,
-		})
+			ServerUpdate:  swp.LastServerUpdate(),
+		}
+		for _, deposit := range swp.Deposits {
+			info.Deposits = append(info.Deposits, *deposit)
+		}
+
+		swaps = append(swaps, info)
 	}
 
 	return swaps, nil
@@ -195,10 +233,11 @@ func (s *Client) FetchSwaps() ([]*SwapInfo, error) {
 
 // Run is a blocking call that executes all swaps. Any pending swaps are
 // restored from persistent storage and resumed.  Subsequent updates will be
-// sent through the passed in statusChan. The function can be terminated by
-// cancelling the context.
-func (s *Client) Run(ctx context.Context,
-	statusChan chan<- SwapInfo) error {
+// sent through the passed in statusChan. Updates that the server pushes are
+// sent through serverUpdateChan, unless it is nil. The function can be
+// terminated by cancelling the context.
+func (s *Client) Run(ctx context.Context, statusChan chan<- SwapInfo,
+	serverUpdateChan chan<- ServerUpdate) error {
 
 	if !atomic.CompareAndSwapUint32(&s.started, 0, 1) {
 		return errors.New("swap client can only be started once")
@@ -229,6 +268,40 @@ func (s *Client) Run(ctx context.Context,
 		return err
 	}
 
+	// Swap updates pass through the server update subscriptions, so that
+	// they learn which swaps are pending with each server.
+	pendingHashes := make(map[string][]lntypes.Hash)
+	addPending := func(hash lntypes.Hash, contract *loopdb.SwapContract) {
+		server, err := s.getServer(contract.ServerID)
+		if err != nil {
+			return
+		}
+		pendingHashes[server.id] = append(
+			pendingHashes[server.id], hash,
+		)
+	}
+	for _, pend := range pendingLoopOutSwaps {
+		if pend.State().State.Type() == loopdb.StateTypePending {
+			addPending(pend.Hash, &pend.Contract.SwapContract)
+		}
+	}
+	for _, pend := range pendingLoopInSwaps {
+		if pend.State().State.Type() == loopdb.StateTypePending {
+			addPending(pend.Hash, &pend.Contract.SwapContract)
+		}
+	}
+
+	swapUpdates := make(chan SwapInfo)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		s.runServerUpdates(
+			mainCtx, pendingHashes, swapUpdates, statusChan,
+			serverUpdateChan,
+		)
+	}()
+
 	// Start goroutine to deliver all pending swaps to the main loop.
 	s.wg.Add(1)
 	go func() {
@@ -243,8 +316,20 @@ func (s *Client) Run(ctx context.Context,
 		close(s.resumeReady)
 	}()
 
+	// Start the scheduler that launches swaps from swap intents once the
+	// executor is ready to accept them.
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		err := s.runScheduler(mainCtx)
+		if err != nil && err != context.Canceled {
+			log.Errorf("Swap scheduler terminating: %v", err)
+		}
+	}()
+
 	// Main event loop.
-	err = s.executor.run(mainCtx, statusChan)
+	err = s.executor.run(mainCtx, swapUpdates)
 
 	// Consider canceled as happy flow.
 	if err == context.Canceled {
@@ -272,19 +357,32 @@ func (s *Client) Run(ctx context.Context,
 	return err
 }
 
+// runScheduler waits for the executor to be ready and then runs the scheduler
+// on the block stream of the executor.
+func (s *Client) runScheduler(ctx context.Context) error {
+	if err := s.waitForInitialized(ctx); err != nil {
+		return err
+	}
+
+	blockChan, err := s.executor.subscribeBlocks(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.scheduler.run(ctx, blockChan, s.executor.height())
+}
+
 // resumeSwaps restarts all pending swaps from the provided list.
 func (s *Client) resumeSwaps(ctx context.Context,
 	loopOutSwaps []*loopdb.LoopOut, loopInSwaps []*loopdb.LoopIn) {
 
-	swapCfg := &swapConfig{
-		lnd:   s.lndServices,
-		store: s.Store,
-	}
-
 	for _, pend := range loopOutSwaps {
 		if pend.State().State.Type() != loopdb.StateTypePending {
 			continue
 		}
+		swapCfg := s.resumeSwapConfig(
+			pend.Hash, &pend.Contract.SwapContract,
+		)
 		swap, err := resumeLoopOutSwap(ctx, swapCfg, pend)
 		if err != nil {
 			log.Errorf("resuming loop out swap: %v", err)
@@ -298,6 +396,9 @@ func (s *Client) resumeSwaps(ctx context.Context,
 		if pend.State().State.Type() != loopdb.StateTypePending {
 			continue
 		}
+		swapCfg := s.resumeSwapConfig(
+			pend.Hash, &pend.Contract.SwapContract,
+		)
 		swap, err := resumeLoopInSwap(ctx, swapCfg, pend)
 		if err != nil {
 			log.Errorf("resuming loop in swap: %v", err)
@@ -308,6 +409,27 @@ func (s *Client) resumeSwaps(ctx context.Context,
 	}
 }
 
+// resumeSwapConfig returns the configuration of a resumed swap. A swap whose
+// server is no longer configured is resumed without server, so that it can
+// still complete or time out on-chain.
+func (s *Client) resumeSwapConfig(hash lntypes.Hash,
+	contract *loopdb.SwapContract) *swapConfig {
+
+	server, err := s.getServer(contract.ServerID)
+	if err != nil {
+		log.Warnf("Swap %v: server %v is not configured, resuming "+
+			"without server", hash, contract.ServerID)
+
+		return &swapConfig{
+			lnd:      s.lndServices,
+			store:    s.Store,
+			serverID: contract.ServerID,
+		}
+	}
+
+	return s.newSwapConfig(server)
+}
+
 // LoopOut initiates a loop out swap. It blocks until the swap is initiation
 // with the swap server is completed (typically this takes only a short amount
 // of time). From there on further status information can be acquired through
@@ -329,15 +451,53 @@ func (s *Client) LoopOut(globalCtx context.Context,
 		return nil, nil, err
 	}
 
+	if err := validateLabels(request.Label, request.Metadata); err != nil {
+		return nil, nil, err
+	}
+
+	// A retried request returns the swap that it started before.
+	if request.IdempotencyKey != "" {
+		info, release, err := s.claimIdempotencyKey(
+			swap.TypeOut, request.IdempotencyKey,
+			request.RequestDigest,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		if info != nil {
+			log.Infof("Returning loop out %v of idempotency key %v",
+				info.SwapHash, request.IdempotencyKey)
+
+			return &info.SwapHash, info.HtlcAddress, nil
+		}
+		defer release()
+	}
+
+	// Check the destination before contacting the server.
+	if err := s.checkDestAddrs(request); err != nil {
+		return nil, nil, err
+	}
+
+	// Select the server to swap with. If the swap is based on a quote,
+	// look up the quoted figures so that the server invoices can be
+	// checked against them.
+	server, quote, err := s.loopOutServer(globalCtx, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if request.ProbeRoute {
+		err := s.probeLoopOut(globalCtx, server, request, quote)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Create a new swap object for this swap.
 	initiationHeight := s.executor.height()
-	swapCfg := &swapConfig{
-		lnd:    s.lndServices,
-		store:  s.Store,
-		server: s.Server,
-	}
 	swap, err := newLoopOutSwap(
-		globalCtx, swapCfg, initiationHeight, request,
+		globalCtx, s.newSwapConfig(server), initiationHeight, request,
+		quote,
 	)
 	if err != nil {
 		return nil, nil, err
@@ -351,27 +511,60 @@ func (s *Client) LoopOut(globalCtx context.Context,
 	return &swap.hash, swap.htlc.Address, nil
 }
 
+// probeLoopOut probes the route to the swap server for the swap invoice amount
+// of a loop out request. If the request doesn't reference a quote, the server
+// is asked for a quote to learn the swap payment destination and fees.
+func (s *Client) probeLoopOut(ctx context.Context, server *swapServer,
+	request *OutRequest, quote *LoopOutQuote) error {
+
+	if quote == nil {
+		var err error
+		quote, err = server.GetLoopOutQuote(
+			ctx, request.Amount, request.SwapPublicationDeadline,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The swap invoice covers the swap amount and the part of the swap
+	// fee that isn't paid with the prepay.
+	invoiceAmt := request.Amount + quote.SwapFee - quote.PrepayAmount
+
+	return probeRoute(
+		ctx, s.lndServices.Router, quote.SwapPaymentDest,
+		invoiceAmt, request.MaxSwapRoutingFee,
+		request.LoopOutChannel,
+	)
+}
+
 // LoopOutQuote takes a LoopOut amount and returns a break down of estimated
 // costs for the client. Both the swap server and the on-chain fee estimator
-// are queried to get to build the quote response.
+// are queried to get to build the quote response. Unless the request names a
+// server, all servers are queried and the cheapest quote is returned.
 func (s *Client) LoopOutQuote(ctx context.Context,
 	request *LoopOutQuoteRequest) (*LoopOutQuote, error) {
 
-	terms, err := s.Server.GetLoopOutTerms(ctx)
+	_, _, quotes, err := s.bestLoopOutQuote(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	if request.Amount < terms.MinSwapAmount {
-		return nil, ErrSwapAmountTooLow
+	if len(quotes) == 1 {
+		return quotes[0], nil
 	}
 
-	if request.Amount > terms.MaxSwapAmount {
-		return nil, ErrSwapAmountTooHigh
-	}
+	return sumLoopOutQuotes(quotes), nil
+}
 
-	quote, err := s.Server.GetLoopOutQuote(
-		ctx, request.Amount, request.SwapPublicationDeadline,
+// getLoopOutQuote returns the quote of the given server for a single loop out
+// swap of the given amount.
+func (s *Client) getLoopOutQuote(ctx context.Context, server *swapServer,
+	amt btcutil.Amount, request *LoopOutQuoteRequest) (*LoopOutQuote,
+	error) {
+
+	quote, err := server.GetLoopOutQuote(
+		ctx, amt, request.SwapPublicationDeadline,
 	)
 	if err != nil {
 		return nil, err
@@ -392,28 +585,97 @@ func (s *Client) LoopOutQuote(ctx context.Context,
 		return nil, err
 	}
 
+	destAddrs := []btcutil.Address{p2wshAddress}
+	for i := 1; i < request.SweepOutputs; i++ {
+		destAddrs = append(destAddrs, p2wshAddress)
+	}
+
 	minerFee, err := s.sweeper.GetSweepFee(
-		ctx, swap.QuoteHtlc.AddSuccessToEstimator,
-		p2wshAddress, request.SweepConfTarget,
+		ctx, swap.QuoteHtlc.AddSuccessToEstimator, destAddrs,
+		request.SweepConfTarget,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// The swap payment pays the swap amount plus the swap fee, minus the
+	// part of the fee that is paid by the prepayment.
+	swapRoutingFee, err := s.estimateRoutingFee(
+		ctx, quote.SwapPaymentDest,
+		amt+swapFee-quote.PrepayAmount, request.LoopOutChannel,
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	return &LoopOutQuote{
-		SwapFee:         swapFee,
-		MinerFee:        minerFee,
-		PrepayAmount:    quote.PrepayAmount,
-		SwapPaymentDest: quote.SwapPaymentDest,
-		CltvDelta:       quote.CltvDelta,
-	}, nil
+	prepayRoutingFee, err := s.estimateRoutingFee(
+		ctx, quote.SwapPaymentDest, quote.PrepayAmount,
+		request.LoopOutChannel,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	loopOutQuote := &LoopOutQuote{
+		SwapFee:          swapFee,
+		MinerFee:         minerFee,
+		PrepayAmount:     quote.PrepayAmount,
+		SwapPaymentDest:  quote.SwapPaymentDest,
+		CltvDelta:        quote.CltvDelta,
+		NumSwaps:         1,
+		QuoteID:          quote.QuoteID,
+		QuoteExpiry:      quote.QuoteExpiry,
+		SwapRoutingFee:   swapRoutingFee,
+		PrepayRoutingFee: prepayRoutingFee,
+		ServerID:         server.id,
+	}
+	s.quotes.addLoopOut(amt, loopOutQuote)
+
+	return loopOutQuote, nil
+}
+
+// estimateRoutingFee returns the routing fee of the best route that lnd finds
+// for a payment of the given amount to the destination. If lnd finds no route,
+// no estimate is available and zero is returned. The quote remains valid in
+// that case, because the route may still be found when the swap is paid.
+func (s *Client) estimateRoutingFee(ctx context.Context, dest [33]byte,
+	amt btcutil.Amount, channel *uint64) (btcutil.Amount, error) {
+
+	route, err := s.lndServices.Client.QueryRoutes(
+		ctx, &lndclient.QueryRoutesRequest{
+			PubKey:          dest,
+			Amount:          amt,
+			OutgoingChannel: channel,
+		},
+	)
+	switch {
+	case err == lndclient.ErrNoRoute:
+		log.Warnf("No route to %x for %v, routing fee estimate "+
+			"unavailable", dest, amt)
+
+		return 0, nil
+
+	case err != nil:
+		return 0, err
+	}
+
+	log.Debugf("Route to %x for %v: %v hops, fee %v", dest, amt,
+		route.NumHops, route.TotalFee)
+
+	return route.TotalFee, nil
 }
 
-// LoopOutTerms returns the terms on which the server executes swaps.
-func (s *Client) LoopOutTerms(ctx context.Context) (
+// LoopOutTerms returns the terms on which the server with the given id
+// executes swaps. An empty id refers to the default server.
+func (s *Client) LoopOutTerms(ctx context.Context, serverID string) (
 	*LoopOutTerms, error) {
 
-	return s.Server.GetLoopOutTerms(ctx)
+	server, err := s.getServer(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	return server.GetLoopOutTerms(ctx)
 }
 
 // waitForInitialized for swaps to be resumed and executor ready.
@@ -446,15 +708,40 @@ func (s *Client) LoopIn(globalCtx context.Context,
 		return nil, nil, err
 	}
 
+	if err := validateLabels(request.Label, request.Metadata); err != nil {
+		return nil, nil, err
+	}
+
+	// A retried request returns the swap that it started before.
+	if request.IdempotencyKey != "" {
+		info, release, err := s.claimIdempotencyKey(
+			swap.TypeIn, request.IdempotencyKey,
+			request.RequestDigest,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		if info != nil {
+			log.Infof("Returning loop in %v of idempotency key %v",
+				info.SwapHash, request.IdempotencyKey)
+
+			return &info.SwapHash, info.HtlcAddress, nil
+		}
+		defer release()
+	}
+
+	// Select the server to swap with. If the swap is based on a quote,
+	// use the quoted swap fee rather than requesting a new quote.
+	server, quote, err := s.loopInServer(globalCtx, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Create a new swap object for this swap.
 	initiationHeight := s.executor.height()
-	swapCfg := swapConfig{
-		lnd:    s.lndServices,
-		store:  s.Store,
-		server: s.Server,
-	}
 	swap, err := newLoopInSwap(
-		globalCtx, &swapCfg, initiationHeight, request,
+		globalCtx, s.newSwapConfig(server), initiationHeight, request,
+		quote,
 	)
 	if err != nil {
 		return nil, nil, err
@@ -470,26 +757,30 @@ func (s *Client) LoopIn(globalCtx context.Context,
 
 // LoopInQuote takes an amount and returns a break down of estimated
 // costs for the client. Both the swap server and the on-chain fee estimator are
-// queried to get to build the quote response.
+// queried to get to build the quote response. Unless the request names a
+// server, all servers are queried and the cheapest quote is returned.
 func (s *Client) LoopInQuote(ctx context.Context,
 	request *LoopInQuoteRequest) (*LoopInQuote, error) {
 
-	// Retrieve current server terms to calculate swap fee.
-	terms, err := s.Server.GetLoopInTerms(ctx)
+	_, _, quotes, err := s.bestLoopInQuote(ctx, request)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check amount limits.
-	if request.Amount < terms.MinSwapAmount {
-		return nil, ErrSwapAmountTooLow
+	if len(quotes) == 1 {
+		return quotes[0], nil
 	}
 
-	if request.Amount > terms.MaxSwapAmount {
-		return nil, ErrSwapAmountTooHigh
-	}
+	return sumLoopInQuotes(quotes), nil
+}
+
+// getLoopInQuote returns the quote of the given server for a single loop in
+// swap of the given amount.
+func (s *Client) getLoopInQuote(ctx context.Context, server *swapServer,
+	amt btcutil.Amount, request *LoopInQuoteRequest) (*LoopInQuote,
+	error) {
 
-	quote, err := s.Server.GetLoopInQuote(ctx, request.Amount)
+	quote, err := server.GetLoopInQuote(ctx, amt)
 	if err != nil {
 		return nil, err
 	}
@@ -498,31 +789,40 @@ func (s *Client) LoopInQuote(ctx context.Context,
 
 	// We don't calculate the on-chain fee if the HTLC is going to be
 	// published externally.
-	if request.ExternalHtlc {
-		return &LoopInQuote{
-			SwapFee:  swapFee,
-			MinerFee: 0,
-		}, nil
+	var minerFee btcutil.Amount
+	if !request.ExternalHtlc {
+		// Get estimate for miner fee.
+		minerFee, err = s.lndServices.Client.EstimateFeeToP2WSH(
+			ctx, amt, request.HtlcConfTarget,
+		)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// Get estimate for miner fee.
-	minerFee, err := s.lndServices.Client.EstimateFeeToP2WSH(
-		ctx, request.Amount, request.HtlcConfTarget,
-	)
-	if err != nil {
-		return nil, err
+	loopInQuote := &LoopInQuote{
+		SwapFee:     swapFee,
+		MinerFee:    minerFee,
+		CltvDelta:   quote.CltvDelta,
+		NumSwaps:    1,
+		QuoteID:     quote.QuoteID,
+		QuoteExpiry: quote.QuoteExpiry,
+		ServerID:    server.id,
 	}
+	s.quotes.addLoopIn(amt, loopInQuote)
 
-	return &LoopInQuote{
-		SwapFee:   swapFee,
-		MinerFee:  minerFee,
-		CltvDelta: quote.CltvDelta,
-	}, nil
+	return loopInQuote, nil
 }
 
-// LoopInTerms returns the terms on which the server executes swaps.
-func (s *Client) LoopInTerms(ctx context.Context) (
+// LoopInTerms returns the terms on which the server with the given id
+// executes swaps. An empty id refers to the default server.
+func (s *Client) LoopInTerms(ctx context.Context, serverID string) (
 	*LoopInTerms, error) {
 
-	return s.Server.GetLoopInTerms(ctx)
+	server, err := s.getServer(serverID)
+	if err != nil {
+		return nil, err
+	}
+
+	return server.GetLoopInTerms(ctx)
 }
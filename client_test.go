@@ -4,14 +4,16 @@ import (
 	"bytes"
 	"context"
 	"crypto/sha256"
-	"errors"
 	"testing"
+	"time"
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcutil"
 	"github.com/lightninglabs/loop/lndclient"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
 	"github.com/lightningnetwork/lnd/lntypes"
 )
 
@@ -70,7 +72,9 @@ func TestFailOffchain(t *testing.T) {
 
 	ctx := createClientTestContext(t, nil)
 
-	_, _, err := ctx.swapClient.LoopOut(context.Background(), testRequest)
+	hash, _, err := ctx.swapClient.LoopOut(
+		context.Background(), testRequest,
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -84,15 +88,34 @@ func TestFailOffchain(t *testing.T) {
 	ctx.AssertRegisterConf()
 
 	signalSwapPaymentResult(
-		errors.New(lndclient.PaymentResultUnknownPaymentHash),
+		test.PaymentStateError(routerrpc.PaymentState_FAILED_NO_ROUTE),
 	)
 	signalPrepaymentResult(
-		errors.New(lndclient.PaymentResultUnknownPaymentHash),
+		test.PaymentStateError(
+			routerrpc.PaymentState_FAILED_INCORRECT_PAYMENT_DETAILS,
+		),
 	)
 	ctx.assertStatus(loopdb.StateFailOffchainPayments)
 
 	ctx.assertStoreFinished(loopdb.StateFailOffchainPayments)
 
+	// The swap fails on whichever payment result is processed first. The
+	// stored failure must name that payment along with its reason.
+	updates := ctx.store.loopOutUpdates[*hash]
+	failure := updates[len(updates)-1].PaymentFailure
+	if failure == nil {
+		t.Fatal("expected payment failure")
+	}
+
+	expectedReason := loopdb.FailureReasonNoRoute
+	if failure.Payment == loopdb.PaymentTypePrepay {
+		expectedReason = loopdb.FailureReasonIncorrectPaymentDetails
+	}
+	if failure.Reason != expectedReason {
+		t.Fatalf("expected %v failure %v, got %v", failure.Payment,
+			expectedReason, failure.Reason)
+	}
+
 	ctx.finish()
 }
 
@@ -134,6 +157,200 @@ func TestFailWrongAmount(t *testing.T) {
 
 }
 
+// TestLoopOutQuoteID asserts that a loop out that references a quote is
+// checked against the quoted figures.
+func TestLoopOutQuoteID(t *testing.T) {
+	defer test.Guard(t)()
+
+	quoteID := []byte{1, 2, 3}
+
+	test := func(t *testing.T, request OutRequest, expiry time.Time,
+		expectedErr error) {
+
+		ctx := createClientTestContext(t, nil)
+		ctx.serverMock.quoteID = quoteID
+		ctx.serverMock.quoteExpiry = expiry
+
+		_, err := ctx.swapClient.LoopOutQuote(
+			context.Background(), &LoopOutQuoteRequest{
+				Amount:          testRequest.Amount,
+				SweepConfTarget: testRequest.SweepConfTarget,
+			},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, _, err = ctx.swapClient.LoopOut(
+			context.Background(), &request,
+		)
+		if err != expectedErr {
+			t.Fatalf("Expected %v, but got %v", expectedErr, err)
+		}
+		ctx.finish()
+	}
+
+	validExpiry := time.Now().Add(time.Hour)
+
+	t.Run("unknown quote", func(t *testing.T) {
+		request := *testRequest
+		request.QuoteID = []byte{4, 5, 6}
+
+		test(t, request, validExpiry, ErrQuoteNotFound)
+	})
+
+	t.Run("amount mismatch", func(t *testing.T) {
+		request := *testRequest
+		request.QuoteID = quoteID
+		request.Amount++
+
+		test(t, request, validExpiry, ErrQuoteAmountMismatch)
+	})
+
+	t.Run("expired quote", func(t *testing.T) {
+		request := *testRequest
+		request.QuoteID = quoteID
+
+		test(t, request, time.Now().Add(-time.Minute), ErrQuoteExpired)
+	})
+
+	// A quote without expiry remains valid, so the swap is checked against
+	// the quoted fee.
+	t.Run("no expiry", func(t *testing.T) {
+		request := *testRequest
+		request.QuoteID = quoteID
+
+		test(t, request, time.Time{}, ErrSwapFeeTooHigh)
+	})
+
+	// The server mock asks for a swap fee that is within the limits of the
+	// request, but above the quoted fee.
+	t.Run("swap fee above quote", func(t *testing.T) {
+		request := *testRequest
+		request.QuoteID = quoteID
+
+		test(t, request, validExpiry, ErrSwapFeeTooHigh)
+	})
+}
+
+// TestLoopOutQuoteRoutingFee tests that the loop out quote contains the
+// routing fees of the routes that lnd finds to the server.
+func TestLoopOutQuoteRoutingFee(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := createClientTestContext(t, nil)
+	ctx.Lnd.RouteFee = 7
+
+	quote, err := ctx.swapClient.LoopOutQuote(
+		context.Background(), &LoopOutQuoteRequest{
+			Amount:          testRequest.Amount,
+			SweepConfTarget: testRequest.SweepConfTarget,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if quote.SwapRoutingFee != 7 || quote.PrepayRoutingFee != 7 {
+		t.Fatalf("unexpected routing fees %v and %v",
+			quote.SwapRoutingFee, quote.PrepayRoutingFee)
+	}
+
+	// Without a route, the quote is still returned, just without routing
+	// fee estimates.
+	ctx.Lnd.NoRoute = true
+
+	quote, err = ctx.swapClient.LoopOutQuote(
+		context.Background(), &LoopOutQuoteRequest{
+			Amount:          testRequest.Amount,
+			SweepConfTarget: testRequest.SweepConfTarget,
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if quote.SwapRoutingFee != 0 || quote.PrepayRoutingFee != 0 {
+		t.Fatalf("expected no routing fee estimates, got %v and %v",
+			quote.SwapRoutingFee, quote.PrepayRoutingFee)
+	}
+
+	ctx.finish()
+}
+
+// TestLoopOutProbe tests that a loop out with route probing is only
+// initiated if the probe payment reaches the server.
+func TestLoopOutProbe(t *testing.T) {
+	defer test.Guard(t)()
+
+	t.Run("no route", func(t *testing.T) {
+		ctx := createClientTestContext(t, nil)
+
+		request := *testRequest
+		request.ProbeRoute = true
+
+		errChan := make(chan error)
+		go func() {
+			_, _, err := ctx.swapClient.LoopOut(
+				context.Background(), &request,
+			)
+			errChan <- err
+		}()
+
+		// The probe is sent for the amount of the swap invoice.
+		invoiceAmt := request.Amount + testSwapFee -
+			testFixedPrepayAmount
+
+		probe := <-ctx.Lnd.RouterSendPaymentChannel
+		if probe.Amount != invoiceAmt {
+			t.Fatalf("expected probe amount %v, got %v",
+				invoiceAmt, probe.Amount)
+		}
+		probe.Updates <- lndclient.PaymentStatus{
+			State: routerrpc.PaymentState_FAILED_NO_ROUTE,
+		}
+
+		err := <-errChan
+		if err != lndclient.ErrNoRouteToServer {
+			t.Fatalf("expected no route error, got %v", err)
+		}
+
+		// The swap must not have been initiated.
+		if len(ctx.store.loopOutSwaps) != 0 {
+			t.Fatal("expected no swap to be stored")
+		}
+
+		ctx.finish()
+	})
+
+	t.Run("route found", func(t *testing.T) {
+		ctx := createClientTestContext(t, nil)
+
+		errChan := make(chan error)
+		go func() {
+			errChan <- probeRoute(
+				context.Background(), ctx.Lnd.Router,
+				[33]byte{}, testRequest.Amount, 10, nil,
+			)
+		}()
+
+		probe := <-ctx.Lnd.RouterSendPaymentChannel
+		probe.Updates <- lndclient.PaymentStatus{
+			State: routerrpc.PaymentState_IN_FLIGHT,
+		}
+		probe.Updates <- lndclient.PaymentStatus{
+			State: routerrpc.
+				PaymentState_FAILED_INCORRECT_PAYMENT_DETAILS,
+		}
+
+		if err := <-errChan; err != nil {
+			t.Fatal(err)
+		}
+
+		ctx.finish()
+	})
+}
+
 // TestResume tests that swaps in various states are properly resumed after a
 // restart.
 func TestResume(t *testing.T) {
@@ -165,7 +382,8 @@ func testResume(t *testing.T, expired, preimageRevealed, expectSuccess bool) {
 		t.Fatal(err)
 	}
 
-	prePayReq, err := getInvoice(hash, 100, prepayInvoiceDesc)
+	prepayHash := lntypes.Hash(sha256.Sum256(hash[:]))
+	prePayReq, err := getInvoice(prepayHash, 100, prepayInvoiceDesc)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -225,8 +443,12 @@ func testResume(t *testing.T, expired, preimageRevealed, expectSuccess bool) {
 		ctx.assertStatus(loopdb.StateInitiated)
 	}
 
+	// The client first looks up the payments of the previous run. The
+	// prepayment was sent before, so it is tracked instead of sent again.
+	// The swap payment doesn't exist yet and is sent now.
+	signalPrepaymentResult := ctx.AssertTrackPayment(prepayHash)
+	ctx.AssertTrackPayment(hash)(channeldb.ErrPaymentNotInitiated)
 	signalSwapPaymentResult := ctx.AssertPaid(swapInvoiceDesc)
-	signalPrepaymentResult := ctx.AssertPaid(prepayInvoiceDesc)
 
 	// Expect client to register for conf
 	confIntent := ctx.AssertRegisterConf()
@@ -241,9 +463,7 @@ func testResume(t *testing.T, expired, preimageRevealed, expectSuccess bool) {
 		return
 	}
 
-	// Because there is no reliable payment yet, an invoice is assumed to be
-	// paid after resume.
-
+	// Both payments have completed already.
 	testSuccess(ctx, amt, hash,
 		func(r error) {},
 		func(r error) {},
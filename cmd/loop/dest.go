@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+
+	"github.com/lightninglabs/loop/looprpc"
+	"github.com/urfave/cli"
+)
+
+var destCommand = cli.Command{
+	Name:  "dest",
+	Usage: "manage the descriptor that loop out addresses are derived from",
+	Description: `
+	Without a subcommand, the registered destination descriptor and the
+	index of the next address are shown.
+
+	If a descriptor is registered, loop outs without a destination address
+	sweep to the next unused address derived from it instead of to the lnd
+	wallet. Addresses that were used by earlier swaps are skipped.`,
+	Action: getDest,
+	Subcommands: []cli.Command{
+		{
+			Name:  "register",
+			Usage: "register an xpub or output descriptor",
+			Description: `
+			Registers an extended public key or an output
+			descriptor of the form wpkh(KEY), sh(wpkh(KEY)) or
+			pkh(KEY), where KEY is an extended public key followed
+			by an unhardened derivation path ending in /*. A bare
+			extended public key derives native segwit addresses
+			like wpkh(KEY/0/*).
+
+			A descriptor that was registered before continues at
+			its previous index.`,
+			ArgsUsage: "descriptor",
+			Action:    registerDest,
+		},
+		{
+			Name:   "unregister",
+			Usage:  "remove the registered descriptor",
+			Action: unregisterDest,
+		},
+	},
+}
+
+func getDest(ctx *cli.Context) error {
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.GetDestDescriptor(
+		context.Background(), &looprpc.GetDestDescriptorRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
+
+func registerDest(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "register")
+	}
+
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.RegisterDestDescriptor(
+		context.Background(), &looprpc.RegisterDestDescriptorRequest{
+			OutputDescriptor: ctx.Args().First(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
+
+func unregisterDest(ctx *cli.Context) error {
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	_, err = client.UnregisterDestDescriptor(
+		context.Background(),
+		&looprpc.UnregisterDestDescriptorRequest{},
+	)
+	return err
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+
+	"github.com/lightninglabs/loop/looprpc"
+	"github.com/urfave/cli"
+)
+
+var listGroupsCommand = cli.Command{
+	Name:  "groups",
+	Usage: "list the groups of swaps created by split swap requests",
+	Description: "Shows the combined state and cost of every group of " +
+		"swaps that was created from a single split swap request",
+	Action: listGroups,
+}
+
+func listGroups(ctx *cli.Context) error {
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.ListSwapGroups(
+		context.Background(), &looprpc.ListSwapGroupsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+
+	"github.com/lightninglabs/loop/looprpc"
+	"github.com/urfave/cli"
+)
+
+var healthCommand = cli.Command{
+	Name:  "health",
+	Usage: "show the connection health of the swap servers",
+	Description: `
+	Shows for every configured swap server whether recent calls to it
+	succeeded. A server that failed repeatedly is reported as offline and
+	is not contacted until the shown retry time.`,
+	Action: health,
+}
+
+func health(ctx *cli.Context) error {
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.GetServerHealth(
+		context.Background(), &looprpc.ServerHealthRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop"
+	"github.com/lightninglabs/loop/looprpc"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/urfave/cli"
+)
+
+// scheduleFlags are the flags that are shared by the schedule subcommands.
+var scheduleFlags = []cli.Flag{
+	cli.Uint64Flag{
+		Name:  "amt",
+		Usage: "the amount in satoshis to swap",
+	},
+	cli.Int64Flag{
+		Name: "max_total_cost",
+		Usage: "the maximum total cost of the swap in satoshis, the " +
+			"limits are derived from a quote that is obtained now",
+	},
+	cli.Uint64Flag{
+		Name: "max_total_cost_ppm",
+		Usage: "the maximum total cost of the swap in parts per " +
+			"million of the swap amount",
+	},
+	cli.Uint64Flag{
+		Name: "max_fee_rate",
+		Usage: "initiate the swap once the on-chain fee estimate " +
+			"drops to this fee rate in sat/vbyte",
+	},
+	cli.Uint64Flag{
+		Name: "fee_conf_target",
+		Usage: "the confirmation target of the fee estimate that " +
+			"is compared against max_fee_rate",
+	},
+	cli.Uint64Flag{
+		Name: "deadline_height",
+		Usage: "initiate the swap at this block height at the " +
+			"latest, regardless of the fee estimate",
+	},
+	cli.Uint64Flag{
+		Name:  "start_height",
+		Usage: "don't initiate the swap before this block height",
+	},
+	cli.DurationFlag{
+		Name:  "start_delay",
+		Usage: "don't initiate the swap before this delay has passed",
+	},
+}
+
+var scheduleCommand = cli.Command{
+	Name:  "schedule",
+	Usage: "schedule a swap that is initiated once conditions are met",
+	Description: `
+	Persists a swap intent in the daemon. The conditions are evaluated on
+	every block and the swap is initiated once all of them are met.
+
+	Scheduled swaps require a total cost budget.`,
+	Subcommands: []cli.Command{
+		{
+			Name:      "out",
+			Usage:     "schedule a loop out swap",
+			ArgsUsage: "amt",
+			Flags: append([]cli.Flag{
+				cli.StringFlag{
+					Name: "addr",
+					Usage: "the optional address that " +
+						"the looped out funds should " +
+						"be sent to",
+				},
+				cli.Uint64Flag{
+					Name: "channel",
+					Usage: "the 8-byte compact channel " +
+						"ID of the channel to loop " +
+						"out",
+				},
+				cli.Uint64Flag{
+					Name: "conf_target",
+					Usage: "the number of blocks within " +
+						"which the on-chain HTLC " +
+						"should be swept",
+					Value: uint64(
+						loop.DefaultSweepConfTarget,
+					),
+				},
+			}, scheduleFlags...),
+			Action: scheduleLoopOut,
+		},
+		{
+			Name:      "in",
+			Usage:     "schedule a loop in swap",
+			ArgsUsage: "amt",
+			Flags: append([]cli.Flag{
+				cli.BoolFlag{
+					Name: "external",
+					Usage: "expect htlc to be published " +
+						"externally",
+				},
+			}, scheduleFlags...),
+			Action: scheduleLoopIn,
+		},
+	},
+}
+
+var listIntentsCommand = cli.Command{
+	Name:   "intents",
+	Usage:  "list all scheduled swaps",
+	Action: listIntents,
+}
+
+var cancelIntentCommand = cli.Command{
+	Name:      "cancelintent",
+	Usage:     "cancel a scheduled swap",
+	ArgsUsage: "id",
+	Action:    cancelIntent,
+}
+
+func scheduleLoopOut(ctx *cli.Context) error {
+	trigger, amt, err := parseSchedule(ctx, swap.TypeOut)
+	if err != nil {
+		return err
+	}
+
+	req := &looprpc.ScheduleSwapRequest{
+		Trigger: trigger,
+		LoopOut: &looprpc.LoopOutRequest{
+			Amt:             int64(amt),
+			Dest:            ctx.String("addr"),
+			LoopOutChannel:  ctx.Uint64("channel"),
+			SweepConfTarget: int32(ctx.Uint64("conf_target")),
+			MaxTotalCost:    ctx.Int64("max_total_cost"),
+			MaxTotalCostPpm: ctx.Uint64("max_total_cost_ppm"),
+		},
+	}
+
+	return schedule(ctx, req)
+}
+
+func scheduleLoopIn(ctx *cli.Context) error {
+	trigger, amt, err := parseSchedule(ctx, swap.TypeIn)
+	if err != nil {
+		return err
+	}
+
+	req := &looprpc.ScheduleSwapRequest{
+		Trigger: trigger,
+		LoopIn: &looprpc.LoopInRequest{
+			Amt:             int64(amt),
+			ExternalHtlc:    ctx.Bool("external"),
+			MaxTotalCost:    ctx.Int64("max_total_cost"),
+			MaxTotalCostPpm: ctx.Uint64("max_total_cost_ppm"),
+		},
+	}
+
+	return schedule(ctx, req)
+}
+
+// parseSchedule parses the amount and the trigger conditions of a schedule
+// command and displays the budget of the swap.
+func parseSchedule(ctx *cli.Context, swapType swap.Type) (
+	*looprpc.SwapTrigger, btcutil.Amount, error) {
+
+	amt, err := parseDeferredAmt(ctx, swapType)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	trigger := &looprpc.SwapTrigger{
+		NotBeforeHeight:       int32(ctx.Uint64("start_height")),
+		MaxFeeRateSatPerVbyte: ctx.Uint64("max_fee_rate"),
+		FeeConfTarget:         int32(ctx.Uint64("fee_conf_target")),
+		DeadlineHeight:        int32(ctx.Uint64("deadline_height")),
+	}
+	if ctx.IsSet("start_delay") {
+		trigger.NotBefore = time.Now().Add(
+			ctx.Duration("start_delay"),
+		).Unix()
+	}
+
+	return trigger, amt, nil
+}
+
+// parseDeferredAmt parses the amount of a swap that the daemon initiates at a
+// later time. Such swaps require a total cost budget, which is displayed for
+// confirmation.
+func parseDeferredAmt(ctx *cli.Context, swapType swap.Type) (btcutil.Amount,
+	error) {
+
+	var amtStr string
+	switch {
+	case ctx.IsSet("amt"):
+		amtStr = ctx.String("amt")
+	case ctx.NArg() > 0:
+		amtStr = ctx.Args().First()
+	default:
+		return 0, errors.New("amount required")
+	}
+
+	amt, err := parseAmt(amtStr)
+	if err != nil {
+		return 0, err
+	}
+
+	budget, useBudget, err := getBudget(ctx, amt)
+	if err != nil {
+		return 0, err
+	}
+	if !useBudget {
+		return 0, errors.New("max_total_cost or max_total_cost_ppm " +
+			"required")
+	}
+
+	err = displayBudget(swapType, amt, budget, "")
+	if err != nil {
+		return 0, err
+	}
+
+	return amt, nil
+}
+
+func schedule(ctx *cli.Context, req *looprpc.ScheduleSwapRequest) error {
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.ScheduleSwap(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
+
+func listIntents(ctx *cli.Context) error {
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.ListSwapIntents(
+		context.Background(), &looprpc.ListSwapIntentsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
+
+func cancelIntent(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "cancelintent")
+	}
+
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.CancelSwapIntent(
+		context.Background(), &looprpc.CancelSwapIntentRequest{
+			Id: ctx.Args().First(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
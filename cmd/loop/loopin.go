@@ -2,9 +2,9 @@ package main
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop"
 	"github.com/lightninglabs/loop/looprpc"
 	"github.com/lightninglabs/loop/swap"
 	"github.com/urfave/cli"
@@ -25,6 +25,46 @@ var loopInCommand = cli.Command{
 			Name:  "external",
 			Usage: "expect htlc to be published externally",
 		},
+		cli.BoolFlag{
+			Name: "native_segwit",
+			Usage: "use a native segwit (P2WSH) htlc instead of " +
+				"a nested segwit htlc",
+		},
+		cli.Int64Flag{
+			Name: "max_total_cost",
+			Usage: "the maximum total cost of the swap in " +
+				"satoshis, instead of the default limits " +
+				"derived from a quote",
+		},
+		cli.Uint64Flag{
+			Name: "max_total_cost_ppm",
+			Usage: "the maximum total cost of the swap in parts " +
+				"per million of the swap amount",
+		},
+		cli.BoolFlag{
+			Name: "split",
+			Usage: "split an amount above the server maximum " +
+				"into multiple server-sized swaps",
+		},
+		cli.StringFlag{
+			Name: "refund_addr",
+			Usage: "the address that the htlc is refunded to if " +
+				"the swap times out, defaults to the lnd " +
+				"wallet",
+		},
+		cli.Uint64Flag{
+			Name: "refund_conf_target",
+			Usage: "the confirmation target of the refund tx " +
+				"(default: 2)",
+		},
+		cli.Uint64Flag{
+			Name: "refund_sat_per_vbyte",
+			Usage: "a fixed fee rate in sat/vbyte for the refund " +
+				"tx",
+		},
+		serverFlag,
+		labelFlag,
+		metadataFlag,
 	},
 	Action: loopIn,
 }
@@ -49,6 +89,11 @@ func loopIn(ctx *cli.Context) error {
 		return err
 	}
 
+	metadata, err := loop.ParseMetadata(ctx.StringSlice("metadata"))
+	if err != nil {
+		return err
+	}
+
 	client, cleanup, err := getClient(ctx)
 	if err != nil {
 		return err
@@ -56,38 +101,69 @@ func loopIn(ctx *cli.Context) error {
 	defer cleanup()
 
 	external := ctx.Bool("external")
-	quote, err := client.GetLoopInQuote(
-		context.Background(),
-		&looprpc.QuoteRequest{
-			Amt:          int64(amt),
-			ExternalHtlc: external,
-		},
-	)
-	if err != nil {
-		return err
+	split := ctx.Bool("split")
+
+	req := &looprpc.LoopInRequest{
+		Amt:          int64(amt),
+		ExternalHtlc: external,
+		Split:        split,
+
+		NativeSegwitHtlc:  ctx.Bool("native_segwit"),
+		RefundAddr:        ctx.String("refund_addr"),
+		RefundConfTarget:  int32(ctx.Uint64("refund_conf_target")),
+		RefundSatPerVbyte: ctx.Uint64("refund_sat_per_vbyte"),
+		ServerId:          ctx.String("server"),
+		Label:             ctx.String("label"),
+		Metadata:          metadata,
 	}
 
-	limits := getInLimits(amt, quote)
-	err = displayLimits(swap.TypeIn, amt, limits, external, "")
+	// With a total cost budget, loopd quotes the swap and derives the
+	// individual limits itself.
+	budget, useBudget, err := getBudget(ctx, amt)
 	if err != nil {
 		return err
 	}
 
-	resp, err := client.LoopIn(context.Background(), &looprpc.LoopInRequest{
-		Amt:          int64(amt),
-		MaxMinerFee:  int64(limits.maxMinerFee),
-		MaxSwapFee:   int64(limits.maxSwapFee),
-		ExternalHtlc: external,
-	})
+	if useBudget {
+		err = displayBudget(swap.TypeIn, amt, budget, "")
+		if err != nil {
+			return err
+		}
+
+		req.MaxTotalCost = ctx.Int64("max_total_cost")
+		req.MaxTotalCostPpm = ctx.Uint64("max_total_cost_ppm")
+	} else {
+		quote, err := client.GetLoopInQuote(
+			context.Background(),
+			&looprpc.QuoteRequest{
+				Amt:          int64(amt),
+				ExternalHtlc: external,
+				Split:        split,
+				ServerId:     req.ServerId,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		limits := getInLimits(amt, quote)
+		err = displayLimits(swap.TypeIn, amt, limits, external, "")
+		if err != nil {
+			return err
+		}
+
+		req.MaxMinerFee = int64(limits.maxMinerFee)
+		req.MaxSwapFee = int64(limits.maxSwapFee)
+		req.QuoteId = quote.QuoteId
+		req.ServerId = quote.ServerId
+	}
+
+	resp, err := client.LoopIn(context.Background(), req)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Swap initiated\n")
-	fmt.Printf("ID:           %v\n", resp.Id)
-	fmt.Printf("HTLC address: %v\n", resp.HtlcAddress)
-	fmt.Println()
-	fmt.Printf("Run `loop monitor` to monitor progress.\n")
+	printSwapResponse(resp)
 
 	return nil
 }
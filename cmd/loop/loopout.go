@@ -2,7 +2,10 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/btcsuite/btcutil"
@@ -23,7 +26,9 @@ var loopOutCommand = cli.Command{
 	The amount is to be specified in satoshis.
 
 	Optionally a BASE58/bech32 encoded bitcoin destination address may be
-	specified. If not specified, a new wallet address will be generated.`,
+	specified. If not specified, the next address of the descriptor that
+	was registered with "loop dest register" is used, or a new wallet
+	address will be generated if there is none.`,
 	Flags: []cli.Flag{
 		cli.Uint64Flag{
 			Name:  "channel",
@@ -61,6 +66,51 @@ var loopOutCommand = cli.Command{
 				"setting this flag might result in a lower " +
 				"swap fee.",
 		},
+		cli.Int64Flag{
+			Name: "max_total_cost",
+			Usage: "the maximum total cost of the swap in " +
+				"satoshis, instead of the default limits " +
+				"derived from a quote",
+		},
+		cli.Uint64Flag{
+			Name: "max_total_cost_ppm",
+			Usage: "the maximum total cost of the swap in parts " +
+				"per million of the swap amount",
+		},
+		cli.BoolFlag{
+			Name: "split",
+			Usage: "split an amount above the server maximum " +
+				"into multiple server-sized swaps",
+		},
+		cli.BoolFlag{
+			Name: "probe",
+			Usage: "probe the route to the swap server with the " +
+				"swap invoice amount before initiating the " +
+				"swap",
+		},
+		cli.Uint64Flag{
+			Name: "max_sweep_fee_rate",
+			Usage: "the maximum fee rate in sat/vbyte at which " +
+				"the on-chain HTLC is swept, the sweep " +
+				"waits while the fee estimate is higher",
+		},
+		cli.Uint64Flag{
+			Name: "sweep_safety_margin",
+			Usage: "the number of blocks before the HTLC expiry " +
+				"at which the sweep no longer waits for " +
+				"max_sweep_fee_rate",
+		},
+		cli.StringSliceFlag{
+			Name: "sweep_output",
+			Usage: "an output of the sweep tx, specified as " +
+				"addr:weight for a weighted share or " +
+				"addr:amtsat for a fixed amount, can be " +
+				"repeated to divide the swept funds over " +
+				"multiple addresses",
+		},
+		serverFlag,
+		labelFlag,
+		metadataFlag,
 	},
 	Action: loopOut,
 }
@@ -93,6 +143,20 @@ func loopOut(ctx *cli.Context) error {
 		destAddr = args.First()
 	}
 
+	var sweepOutputs []*looprpc.SweepOutput
+	for _, output := range ctx.StringSlice("sweep_output") {
+		sweepOutput, err := parseSweepOutput(output)
+		if err != nil {
+			return err
+		}
+		sweepOutputs = append(sweepOutputs, sweepOutput)
+	}
+
+	metadata, err := loop.ParseMetadata(ctx.StringSlice("metadata"))
+	if err != nil {
+		return err
+	}
+
 	client, cleanup, err := getClient(ctx)
 	if err != nil {
 		return err
@@ -108,15 +172,7 @@ func loopOut(ctx *cli.Context) error {
 	}
 
 	sweepConfTarget := int32(ctx.Uint64("conf_target"))
-	quoteReq := &looprpc.QuoteRequest{
-		Amt:                     int64(amt),
-		ConfTarget:              sweepConfTarget,
-		SwapPublicationDeadline: uint64(swapDeadline.Unix()),
-	}
-	quote, err := client.LoopOutQuote(context.Background(), quoteReq)
-	if err != nil {
-		return err
-	}
+	split := ctx.Bool("split")
 
 	// Show a warning if a slow swap was requested.
 	warning := ""
@@ -128,44 +184,126 @@ func loopOut(ctx *cli.Context) error {
 			defaultSwapWaitTime)
 	}
 
-	limits := getLimits(amt, quote)
-	// If configured, use the specified maximum swap routing fee.
-	if ctx.IsSet("max_swap_routing_fee") {
-		*limits.maxSwapRoutingFee = btcutil.Amount(
-			ctx.Int64("max_swap_routing_fee"),
-		)
-	}
-	err = displayLimits(swap.TypeOut, amt, limits, false, warning)
-	if err != nil {
-		return err
-	}
-
 	var unchargeChannel uint64
 	if ctx.IsSet("channel") {
 		unchargeChannel = ctx.Uint64("channel")
 	}
 
-	resp, err := client.LoopOut(context.Background(), &looprpc.LoopOutRequest{
+	req := &looprpc.LoopOutRequest{
 		Amt:                     int64(amt),
 		Dest:                    destAddr,
-		MaxMinerFee:             int64(limits.maxMinerFee),
-		MaxPrepayAmt:            int64(*limits.maxPrepayAmt),
-		MaxSwapFee:              int64(limits.maxSwapFee),
-		MaxPrepayRoutingFee:     int64(*limits.maxPrepayRoutingFee),
-		MaxSwapRoutingFee:       int64(*limits.maxSwapRoutingFee),
 		LoopOutChannel:          unchargeChannel,
 		SweepConfTarget:         sweepConfTarget,
 		SwapPublicationDeadline: uint64(swapDeadline.Unix()),
-	})
+		Split:                   split,
+		Probe:                   ctx.Bool("probe"),
+		SweepFeeRateCeilingSatPerVbyte: ctx.Uint64(
+			"max_sweep_fee_rate",
+		),
+		SweepSafetyMargin: int32(ctx.Uint64("sweep_safety_margin")),
+		SweepOutputs:      sweepOutputs,
+		ServerId:          ctx.String("server"),
+		Label:             ctx.String("label"),
+		Metadata:          metadata,
+	}
+
+	// With a total cost budget, loopd quotes the swap and derives the
+	// individual limits itself.
+	budget, useBudget, err := getBudget(ctx, amt)
 	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Swap initiated\n")
-	fmt.Printf("ID:           %v\n", resp.Id)
-	fmt.Printf("HTLC address: %v\n", resp.HtlcAddress)
-	fmt.Println()
-	fmt.Printf("Run `loop monitor` to monitor progress.\n")
+	if useBudget {
+		if ctx.IsSet("max_swap_routing_fee") {
+			return errors.New("max_swap_routing_fee cannot be " +
+				"combined with a total cost budget")
+		}
+
+		err = displayBudget(swap.TypeOut, amt, budget, warning)
+		if err != nil {
+			return err
+		}
+
+		req.MaxTotalCost = ctx.Int64("max_total_cost")
+		req.MaxTotalCostPpm = ctx.Uint64("max_total_cost_ppm")
+	} else {
+		quoteReq := &looprpc.QuoteRequest{
+			Amt:                     int64(amt),
+			ConfTarget:              sweepConfTarget,
+			SwapPublicationDeadline: uint64(swapDeadline.Unix()),
+			Split:                   split,
+			LoopOutChannel:          unchargeChannel,
+			SweepOutputs:            uint32(len(sweepOutputs)),
+			ServerId:                req.ServerId,
+		}
+		quote, err := client.LoopOutQuote(
+			context.Background(), quoteReq,
+		)
+		if err != nil {
+			return err
+		}
+
+		limits := getLimits(amt, quote)
+		// If configured, use the specified maximum swap routing fee.
+		if ctx.IsSet("max_swap_routing_fee") {
+			*limits.maxSwapRoutingFee = btcutil.Amount(
+				ctx.Int64("max_swap_routing_fee"),
+			)
+		}
+		err = displayLimits(swap.TypeOut, amt, limits, false, warning)
+		if err != nil {
+			return err
+		}
+
+		req.MaxMinerFee = int64(limits.maxMinerFee)
+		req.MaxPrepayAmt = int64(*limits.maxPrepayAmt)
+		req.MaxSwapFee = int64(limits.maxSwapFee)
+		req.MaxPrepayRoutingFee = int64(*limits.maxPrepayRoutingFee)
+		req.MaxSwapRoutingFee = int64(*limits.maxSwapRoutingFee)
+		req.QuoteId = quote.QuoteId
+		req.ServerId = quote.ServerId
+	}
+
+	resp, err := client.LoopOut(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	printSwapResponse(resp)
 
 	return nil
 }
+
+// parseSweepOutput parses a sweep output that is specified as addr:weight for
+// a weighted share or addr:amtsat for a fixed amount.
+func parseSweepOutput(output string) (*looprpc.SweepOutput, error) {
+	sep := strings.LastIndex(output, ":")
+	if sep == -1 {
+		return nil, fmt.Errorf("invalid sweep output %v, expected "+
+			"addr:weight or addr:amtsat", output)
+	}
+	addr, value := output[:sep], output[sep+1:]
+
+	if strings.HasSuffix(value, "sat") {
+		amt, err := parseAmt(strings.TrimSuffix(value, "sat"))
+		if err != nil {
+			return nil, err
+		}
+
+		return &looprpc.SweepOutput{
+			Addr: addr,
+			Amt:  int64(amt),
+		}, nil
+	}
+
+	weight, err := strconv.ParseUint(value, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sweep output weight: %v", err)
+	}
+
+	return &looprpc.SweepOutput{
+		Addr:   addr,
+		Weight: uint32(weight),
+	}, nil
+}
@@ -22,14 +22,42 @@ import (
 )
 
 var (
-	// Define route independent max routing fees. We have currently no way
-	// to get a reliable estimate of the routing fees. Best we can do is
-	// the minimum routing fees, which is not very indicative.
+	// The max routing fees are derived from the routing fee estimates in
+	// the quote. The estimates are multiplied and a base fee is added per
+	// payment, to not fail the swap when the actual route turns out more
+	// expensive than the route that was found at quote time.
+	routingFeeMultiplier = btcutil.Amount(2)
+
 	maxRoutingFeeBase = btcutil.Amount(10)
 
+	// The max routing fees are never below a fee proportional to the
+	// payment amount. This covers payments for which no route was found at
+	// quote time and routes that became more expensive since then.
 	maxRoutingFeeRate = int64(20000)
 
 	defaultSwapWaitTime = 30 * time.Minute
+
+	// serverFlag selects the swap server of a swap or quote.
+	serverFlag = cli.StringFlag{
+		Name: "server",
+		Usage: "the id of the swap server to use, defaults to the " +
+			"server with the cheapest quote",
+	}
+
+	// labelFlag sets the label of a swap.
+	labelFlag = cli.StringFlag{
+		Name: "label",
+		Usage: "an optional label of the swap, which is also " +
+			"attached to its on-chain transactions if lnd " +
+			"supports transaction labels",
+	}
+
+	// metadataFlag sets the metadata of a swap.
+	metadataFlag = cli.StringSliceFlag{
+		Name: "metadata",
+		Usage: "an optional metadata pair of the swap in the form " +
+			"key=value, can be repeated to add multiple pairs",
+	}
 )
 
 func printJSON(resp interface{}) {
@@ -83,6 +111,9 @@ func main() {
 	app.Commands = []cli.Command{
 		loopOutCommand, loopInCommand, termsCommand,
 		monitorCommand, quoteCommand, listAuthCommand,
+		listGroupsCommand, scheduleCommand, listIntentsCommand,
+		cancelIntentCommand, recurringCommand, destCommand,
+		healthCommand,
 	}
 
 	err := app.Run(os.Args)
@@ -103,8 +134,15 @@ func getClient(ctx *cli.Context) (looprpc.SwapClientClient, func(), error) {
 	return loopClient, cleanup, nil
 }
 
-func getMaxRoutingFee(amt btcutil.Amount) btcutil.Amount {
-	return swap.CalcFee(amt, maxRoutingFeeBase, maxRoutingFeeRate)
+func getMaxRoutingFee(amt, estimate btcutil.Amount) btcutil.Amount {
+	maxFee := estimate*routingFeeMultiplier + maxRoutingFeeBase
+
+	minFee := swap.CalcFee(amt, maxRoutingFeeBase, maxRoutingFeeRate)
+	if maxFee < minFee {
+		return minFee
+	}
+
+	return maxFee
 }
 
 type limits struct {
@@ -116,12 +154,25 @@ type limits struct {
 }
 
 func getLimits(amt btcutil.Amount, quote *looprpc.QuoteResponse) *limits {
-	maxSwapRoutingFee := getMaxRoutingFee(amt)
-	maxPrepayRoutingFee := getMaxRoutingFee(btcutil.Amount(
-		quote.PrepayAmt,
-	))
 	maxPrepayAmt := btcutil.Amount(quote.PrepayAmt)
 
+	maxSwapRoutingFee := getMaxRoutingFee(
+		amt, btcutil.Amount(quote.SwapRoutingFee),
+	)
+	maxPrepayRoutingFee := getMaxRoutingFee(
+		maxPrepayAmt, btcutil.Amount(quote.PrepayRoutingFee),
+	)
+
+	// A split swap makes a swap and prepay payment for every part, each of
+	// which pays the base fee.
+	if quote.NumSwaps > 1 {
+		extraBase := maxRoutingFeeBase * btcutil.Amount(
+			quote.NumSwaps-1,
+		)
+		maxSwapRoutingFee += extraBase
+		maxPrepayRoutingFee += extraBase
+	}
+
 	return &limits{
 		maxSwapRoutingFee:   &maxSwapRoutingFee,
 		maxPrepayRoutingFee: &maxPrepayRoutingFee,
@@ -202,6 +253,51 @@ func displayLimits(swapType swap.Type, amt btcutil.Amount, l *limits,
 	return errors.New("swap canceled")
 }
 
+// getBudget returns the total cost budget that was specified on the command
+// line, and whether one was specified at all. A budget in ppm is converted
+// to satoshis for display only, loopd does the same conversion.
+func getBudget(ctx *cli.Context, amt btcutil.Amount) (btcutil.Amount, bool,
+	error) {
+
+	switch {
+	case ctx.IsSet("max_total_cost") && ctx.IsSet("max_total_cost_ppm"):
+		return 0, false, errors.New("max_total_cost and " +
+			"max_total_cost_ppm cannot both be set")
+
+	case ctx.IsSet("max_total_cost"):
+		return btcutil.Amount(ctx.Int64("max_total_cost")), true, nil
+
+	case ctx.IsSet("max_total_cost_ppm"):
+		return swap.CalcFee(
+			amt, 0, int64(ctx.Uint64("max_total_cost_ppm")),
+		), true, nil
+
+	default:
+		return 0, false, nil
+	}
+}
+
+func displayBudget(swapType swap.Type, amt, budget btcutil.Amount,
+	warning string) error {
+
+	fmt.Printf("Max total cost for %d Loop %v: %d\n", amt, swapType,
+		budget)
+
+	if warning != "" {
+		fmt.Println(warning)
+	}
+
+	fmt.Printf("CONTINUE SWAP? (y/n): ")
+
+	var answer string
+	fmt.Scanln(&answer)
+	if answer == "y" {
+		return nil
+	}
+
+	return errors.New("swap canceled")
+}
+
 func parseAmt(text string) (btcutil.Amount, error) {
 	amtInt64, err := strconv.ParseInt(text, 10, 64)
 	if err != nil {
@@ -210,6 +306,27 @@ func parseAmt(text string) (btcutil.Amount, error) {
 	return btcutil.Amount(amtInt64), nil
 }
 
+func printSwapResponse(resp *looprpc.SwapResponse) {
+	if resp.GroupId == "" {
+		fmt.Printf("Swap initiated\n")
+		fmt.Printf("ID:           %v\n", resp.Id)
+		fmt.Printf("HTLC address: %v\n", resp.HtlcAddress)
+	} else {
+		fmt.Printf("Swap group initiated with %v swaps\n",
+			len(resp.Parts))
+		fmt.Printf("Group ID:     %v\n", resp.GroupId)
+		for _, part := range resp.Parts {
+			fmt.Println()
+			fmt.Printf("ID:           %v\n", part.Id)
+			fmt.Printf("Amount:       %v\n",
+				btcutil.Amount(part.Amt))
+			fmt.Printf("HTLC address: %v\n", part.HtlcAddress)
+		}
+	}
+	fmt.Println()
+	fmt.Printf("Run `loop monitor` to monitor progress.\n")
+}
+
 func logSwap(swap *looprpc.SwapStatus) {
 	fmt.Printf("%v %v %v %v - %v",
 		time.Unix(0, swap.LastUpdateTime).Format(time.RFC3339),
@@ -226,7 +343,64 @@ func logSwap(swap *looprpc.SwapStatus) {
 		)
 	}
 
+	if swap.PaymentFailure != nil {
+		fmt.Printf(" (%v failed: %v)", swap.PaymentFailure.Payment,
+			swap.PaymentFailure.Reason)
+	}
+
+	// Only report the sweep while it is pending and a decision was made.
+	strategy := swap.SweepStrategy
+	pending := swap.State == looprpc.SwapState_INITIATED ||
+		swap.State == looprpc.SwapState_PREIMAGE_REVEALED
+	if pending && strategy != nil &&
+		strategy.Status != looprpc.SweepStatus_SWEEP_NONE {
+
+		fmt.Printf(" (sweep: %v", strategy.Status)
+		if strategy.FeeRateCeilingSatPerVbyte != 0 {
+			fmt.Printf(", ceiling %v sat/vbyte, margin %v blocks",
+				strategy.FeeRateCeilingSatPerVbyte,
+				strategy.SafetyMargin)
+		}
+		fmt.Printf(")")
+	}
+
+	// Report what the server said about a pending swap.
+	if swap.ServerStatus != nil && swap.State != looprpc.SwapState_SUCCESS &&
+		swap.State != looprpc.SwapState_FAILED {
+
+		fmt.Printf(" (server: %v", swap.ServerStatus.Status)
+		if swap.ServerStatus.Reason != "" {
+			fmt.Printf(", %v", swap.ServerStatus.Reason)
+		}
+		if swap.ServerStatus.ExpectedPublication != 0 {
+			fmt.Printf(", htlc expected at %v", time.Unix(
+				swap.ServerStatus.ExpectedPublication, 0,
+			).Format(time.RFC3339))
+		}
+		fmt.Printf(")")
+	}
+
+	if swap.Label != "" {
+		fmt.Printf(" (label: %v)", swap.Label)
+	}
+
 	fmt.Println()
+
+	// Report deposits of a loop in that don't fund the swap, so that they
+	// can be tracked until they are refunded.
+	for _, deposit := range swap.Deposits {
+		if deposit.Accepted {
+			continue
+		}
+
+		fmt.Printf("  rejected deposit %v %v - %v",
+			deposit.Outpoint, btcutil.Amount(deposit.Amt),
+			deposit.State)
+		if deposit.SpendTxid != "" {
+			fmt.Printf(" (spend tx %v)", deposit.SpendTxid)
+		}
+		fmt.Println()
+	}
 }
 
 func getClientConn(address string) (*grpc.ClientConn, error) {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/lightninglabs/loop"
 	"github.com/lightninglabs/loop/looprpc"
 	"github.com/urfave/cli"
 )
@@ -12,10 +13,26 @@ var monitorCommand = cli.Command{
 	Name:        "monitor",
 	Usage:       "monitor progress of any active swaps",
 	Description: "Allows the user to monitor progress of any active swaps",
-	Action:      monitor,
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name:  "label",
+			Usage: "only show swaps with this label",
+		},
+		cli.StringSliceFlag{
+			Name: "metadata",
+			Usage: "only show swaps with this metadata pair in " +
+				"the form key=value, can be repeated",
+		},
+	},
+	Action: monitor,
 }
 
 func monitor(ctx *cli.Context) error {
+	metadata, err := loop.ParseMetadata(ctx.StringSlice("metadata"))
+	if err != nil {
+		return err
+	}
+
 	client, cleanup, err := getClient(ctx)
 	if err != nil {
 		return err
@@ -23,7 +40,11 @@ func monitor(ctx *cli.Context) error {
 	defer cleanup()
 
 	stream, err := client.Monitor(
-		context.Background(), &looprpc.MonitorRequest{})
+		context.Background(), &looprpc.MonitorRequest{
+			Label:    ctx.String("label"),
+			Metadata: metadata,
+		},
+	)
 	if err != nil {
 		return err
 	}
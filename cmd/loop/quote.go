@@ -31,6 +31,17 @@ var quoteCommand = cli.Command{
 				"setting this flag might result in a lower " +
 				"swap fee.",
 		},
+		cli.BoolFlag{
+			Name: "split",
+			Usage: "split an amount above the server maximum " +
+				"into multiple server-sized swaps",
+		},
+		cli.Uint64Flag{
+			Name: "channel",
+			Usage: "the 64-bit id of the channel to loop out, " +
+				"used to estimate the off-chain routing fees",
+		},
+		serverFlag,
 	},
 	Action: quote,
 }
@@ -38,7 +49,7 @@ var quoteCommand = cli.Command{
 func quote(ctx *cli.Context) error {
 	// Show command help if the incorrect number arguments and/or flags were
 	// provided.
-	if ctx.NArg() != 1 || ctx.NumFlags() > 1 {
+	if ctx.NArg() != 1 || ctx.NumFlags() > 4 {
 		return cli.ShowCommandHelp(ctx, "quote")
 	}
 
@@ -65,6 +76,9 @@ func quote(ctx *cli.Context) error {
 		Amt:                     int64(amt),
 		ConfTarget:              int32(ctx.Uint64("conf_target")),
 		SwapPublicationDeadline: uint64(swapDeadline.Unix()),
+		Split:                   ctx.Bool("split"),
+		LoopOutChannel:          ctx.Uint64("channel"),
+		ServerId:                ctx.String("server"),
 	})
 	if err != nil {
 		return err
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop"
+	"github.com/lightninglabs/loop/looprpc"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/urfave/cli"
+)
+
+// recurringFlags are the flags that are shared by the recurring swap
+// creation subcommands.
+var recurringFlags = []cli.Flag{
+	cli.Uint64Flag{
+		Name:  "amt",
+		Usage: "the amount in satoshis to swap on every run",
+	},
+	cli.Int64Flag{
+		Name: "max_total_cost",
+		Usage: "the maximum total cost of every swap in satoshis, " +
+			"the limits are derived from a quote that is " +
+			"obtained now",
+	},
+	cli.Uint64Flag{
+		Name: "max_total_cost_ppm",
+		Usage: "the maximum total cost of every swap in parts per " +
+			"million of the swap amount",
+	},
+	cli.DurationFlag{
+		Name:  "interval",
+		Usage: "the time between two runs, at least 1h",
+	},
+	cli.DurationFlag{
+		Name:  "start_delay",
+		Usage: "the delay until the first run",
+	},
+	cli.DurationFlag{
+		Name: "period",
+		Usage: "the window over which the total swapped amount is " +
+			"limited, defaults to the interval",
+	},
+	cli.Uint64Flag{
+		Name: "max_per_period",
+		Usage: "the maximum total amount in satoshis that is " +
+			"swapped within the period",
+	},
+}
+
+var recurringCommand = cli.Command{
+	Name:  "recurring",
+	Usage: "manage swaps that are initiated once per interval",
+	Description: `
+	Recurring swaps initiate a swap with the same parameters once per
+	interval. Every run is recorded, including the ones that failed, for
+	example because the maximum amount per period was exceeded.
+
+	Recurring swaps require a total cost budget.`,
+	Subcommands: []cli.Command{
+		{
+			Name:      "out",
+			Usage:     "create a recurring loop out swap",
+			ArgsUsage: "amt",
+			Flags: append([]cli.Flag{
+				cli.StringFlag{
+					Name: "addr",
+					Usage: "the optional address that " +
+						"the looped out funds should " +
+						"be sent to",
+				},
+				cli.Uint64Flag{
+					Name: "channel",
+					Usage: "the 8-byte compact channel " +
+						"ID of the channel to loop " +
+						"out",
+				},
+				cli.Uint64Flag{
+					Name: "conf_target",
+					Usage: "the number of blocks within " +
+						"which the on-chain HTLC " +
+						"should be swept",
+					Value: uint64(
+						loop.DefaultSweepConfTarget,
+					),
+				},
+			}, recurringFlags...),
+			Action: recurringLoopOut,
+		},
+		{
+			Name:      "in",
+			Usage:     "create a recurring loop in swap",
+			ArgsUsage: "amt",
+			Flags: append([]cli.Flag{
+				cli.BoolFlag{
+					Name: "external",
+					Usage: "expect htlc to be published " +
+						"externally",
+				},
+			}, recurringFlags...),
+			Action: recurringLoopIn,
+		},
+		{
+			Name:   "list",
+			Usage:  "list all recurring swaps and their runs",
+			Action: listRecurring,
+		},
+		{
+			Name:      "cancel",
+			Usage:     "cancel a recurring swap",
+			ArgsUsage: "id",
+			Action:    cancelRecurring,
+		},
+	},
+}
+
+func recurringLoopOut(ctx *cli.Context) error {
+	req, amt, err := parseRecurring(ctx, swap.TypeOut)
+	if err != nil {
+		return err
+	}
+
+	req.LoopOut = &looprpc.LoopOutRequest{
+		Amt:             int64(amt),
+		Dest:            ctx.String("addr"),
+		LoopOutChannel:  ctx.Uint64("channel"),
+		SweepConfTarget: int32(ctx.Uint64("conf_target")),
+		MaxTotalCost:    ctx.Int64("max_total_cost"),
+		MaxTotalCostPpm: ctx.Uint64("max_total_cost_ppm"),
+	}
+
+	return createRecurring(ctx, req)
+}
+
+func recurringLoopIn(ctx *cli.Context) error {
+	req, amt, err := parseRecurring(ctx, swap.TypeIn)
+	if err != nil {
+		return err
+	}
+
+	req.LoopIn = &looprpc.LoopInRequest{
+		Amt:             int64(amt),
+		ExternalHtlc:    ctx.Bool("external"),
+		MaxTotalCost:    ctx.Int64("max_total_cost"),
+		MaxTotalCostPpm: ctx.Uint64("max_total_cost_ppm"),
+	}
+
+	return createRecurring(ctx, req)
+}
+
+// parseRecurring parses the amount and schedule of a recurring swap command
+// and displays the budget of every swap.
+func parseRecurring(ctx *cli.Context, swapType swap.Type) (
+	*looprpc.CreateRecurringSwapRequest, btcutil.Amount, error) {
+
+	if !ctx.IsSet("interval") {
+		return nil, 0, errors.New("interval required")
+	}
+
+	amt, err := parseDeferredAmt(ctx, swapType)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	req := &looprpc.CreateRecurringSwapRequest{
+		IntervalSec:     uint64(ctx.Duration("interval") / time.Second),
+		PeriodSec:       uint64(ctx.Duration("period") / time.Second),
+		MaxAmtPerPeriod: int64(ctx.Uint64("max_per_period")),
+	}
+	if ctx.IsSet("start_delay") {
+		req.StartTime = time.Now().Add(
+			ctx.Duration("start_delay"),
+		).Unix()
+	}
+
+	return req, amt, nil
+}
+
+func createRecurring(ctx *cli.Context,
+	req *looprpc.CreateRecurringSwapRequest) error {
+
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.CreateRecurringSwap(context.Background(), req)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
+
+func listRecurring(ctx *cli.Context) error {
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.ListRecurringSwaps(
+		context.Background(), &looprpc.ListRecurringSwapsRequest{},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
+
+func cancelRecurring(ctx *cli.Context) error {
+	if ctx.NArg() != 1 {
+		return cli.ShowCommandHelp(ctx, "cancel")
+	}
+
+	client, cleanup, err := getClient(ctx)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	resp, err := client.CancelRecurringSwap(
+		context.Background(), &looprpc.CancelRecurringSwapRequest{
+			Id: ctx.Args().First(),
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	printRespJSON(resp)
+	return nil
+}
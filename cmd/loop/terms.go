@@ -11,8 +11,15 @@ import (
 )
 
 var termsCommand = cli.Command{
-	Name:   "terms",
-	Usage:  "Display the current swap terms imposed by the server.",
+	Name:  "terms",
+	Usage: "Display the current swap terms imposed by the server.",
+	Flags: []cli.Flag{
+		cli.StringFlag{
+			Name: "server",
+			Usage: "the id of the swap server, defaults to the " +
+				"default server",
+		},
+	},
 	Action: terms,
 }
 
@@ -32,7 +39,9 @@ func terms(ctx *cli.Context) error {
 
 	fmt.Println("Loop Out")
 	fmt.Println("--------")
-	req := &looprpc.TermsRequest{}
+	req := &looprpc.TermsRequest{
+		ServerId: ctx.String("server"),
+	}
 	loopOutTerms, err := client.LoopOutTerms(context.Background(), req)
 	if err != nil {
 		fmt.Println(err)
@@ -44,9 +53,7 @@ func terms(ctx *cli.Context) error {
 
 	fmt.Println("Loop In")
 	fmt.Println("------")
-	loopInTerms, err := client.GetLoopInTerms(
-		context.Background(), &looprpc.TermsRequest{},
-	)
+	loopInTerms, err := client.GetLoopInTerms(context.Background(), req)
 	if err != nil {
 		fmt.Println(err)
 	} else {
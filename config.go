@@ -5,14 +5,20 @@ import (
 
 	"github.com/lightninglabs/loop/lndclient"
 	"github.com/lightninglabs/loop/loopdb"
-	"github.com/lightninglabs/loop/lsat"
 )
 
 // clientConfig contains config items for the swap client.
 type clientConfig struct {
 	LndServices       *lndclient.LndServices
-	Server            swapServerClient
 	Store             loopdb.SwapStore
-	LsatStore         lsat.Store
 	CreateExpiryTimer func(expiry time.Duration) <-chan time.Time
+
+	// Servers are the swap servers that the client is connected to. The
+	// first server is the default server.
+	Servers []*swapServer
+
+	// DestAllowList optionally restricts loop out destinations to the
+	// listed addresses and the addresses derived from the listed
+	// descriptors.
+	DestAllowList []string
 }
@@ -0,0 +1,250 @@
+package loop
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+const (
+	// destAllowListGap is the number of addresses of an allow-listed
+	// descriptor that are checked beyond the next index of the registered
+	// destination descriptor.
+	destAllowListGap = 100
+)
+
+var (
+	// ErrUnsupportedDestAddr is returned when a loop out destination
+	// address has a type that can't be swept to.
+	ErrUnsupportedDestAddr = errors.New("unsupported destination address " +
+		"type")
+
+	// ErrDestAddrIsHtlc is returned when a loop out would sweep to the
+	// htlc address of the swap itself.
+	ErrDestAddrIsHtlc = errors.New("destination address is the swap htlc " +
+		"address")
+
+	// ErrDestAddrReused is returned when a loop out destination address
+	// was already used by an earlier swap.
+	ErrDestAddrReused = errors.New("destination address already used by " +
+		"an earlier swap")
+
+	// ErrDestAddrNotAllowed is returned when a destination allow-list is
+	// configured and a loop out destination address isn't on it.
+	ErrDestAddrNotAllowed = errors.New("destination address not on " +
+		"allow-list")
+)
+
+// validateDestAddrType checks that the given address is for the given
+// network and of a type that the sweep tx can pay to.
+func validateDestAddrType(addr btcutil.Address,
+	chainParams *chaincfg.Params) error {
+
+	switch addr.(type) {
+	case *btcutil.AddressWitnessScriptHash,
+		*btcutil.AddressWitnessPubKeyHash,
+		*btcutil.AddressScriptHash,
+		*btcutil.AddressPubKeyHash:
+
+	default:
+		log.Warnf("Destination address %v has unsupported type %T",
+			addr, addr)
+
+		return ErrUnsupportedDestAddr
+	}
+
+	if !addr.IsForNet(chainParams) {
+		return fmt.Errorf("destination address %v is not for %v", addr,
+			chainParams.Name)
+	}
+
+	return nil
+}
+
+// destAddrs returns all addresses that the sweep of a loop out pays to.
+func destAddrs(request *OutRequest) []btcutil.Address {
+	addrs := []btcutil.Address{request.DestAddr}
+	for _, output := range request.SweepOutputs {
+		addrs = append(addrs, output.Addr)
+	}
+
+	return addrs
+}
+
+// checkDestAddrs checks the destination addresses of a loop out before the
+// swap is initiated. The addresses need to be of a supported type, unused by
+// earlier swaps and, if configured, on the allow-list.
+func (s *Client) checkDestAddrs(request *OutRequest) error {
+	if request.DestAddr == nil {
+		return errors.New("destination address required")
+	}
+
+	addrs := destAddrs(request)
+	for _, addr := range addrs {
+		if addr == nil {
+			return errors.New("destination address required")
+		}
+
+		err := validateDestAddrType(addr, s.lndServices.ChainParams)
+		if err != nil {
+			return err
+		}
+	}
+
+	usedAddrs, err := s.usedSwapAddrs(request.ownSwaps)
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if _, ok := usedAddrs[addr.String()]; ok {
+			log.Warnf("Destination address %v already used", addr)
+			return ErrDestAddrReused
+		}
+	}
+
+	if len(s.DestAllowList) == 0 {
+		return nil
+	}
+
+	allowed, err := s.allowedDestAddrs()
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range addrs {
+		if _, ok := allowed[addr.String()]; !ok {
+			log.Warnf("Destination address %v not on allow-list",
+				addr)
+
+			return ErrDestAddrNotAllowed
+		}
+	}
+
+	return nil
+}
+
+// usedSwapAddrs returns the set of addresses that earlier swaps used. These
+// are the destination addresses of loop outs and the htlc addresses of all
+// swaps. The destination addresses of the given own swaps are left out.
+func (s *Client) usedSwapAddrs(ownSwaps map[lntypes.Hash]struct{}) (
+	map[string]struct{}, error) {
+
+	chainParams := s.lndServices.ChainParams
+	usedAddrs := make(map[string]struct{})
+
+	loopOuts, err := s.Store.FetchLoopOutSwaps()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, swp := range loopOuts {
+		contract := swp.Contract
+
+		if _, ok := ownSwaps[swp.Hash]; !ok {
+			usedAddrs[contract.DestAddr.String()] = struct{}{}
+			for _, output := range contract.SweepOutputs {
+				usedAddrs[output.Addr.String()] = struct{}{}
+			}
+		}
+
+		htlc, err := swap.NewHtlc(
+			contract.HtlcVersion, contract.CltvExpiry,
+			contract.SenderKey, contract.ReceiverKey, swp.Hash,
+			swap.HtlcP2WSH, chainParams,
+		)
+		if err != nil {
+			return nil, err
+		}
+		usedAddrs[htlc.Address.String()] = struct{}{}
+	}
+
+	loopIns, err := s.Store.FetchLoopInSwaps()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, swp := range loopIns {
+		contract := swp.Contract
+
+		htlc, err := swap.NewHtlc(
+			contract.HtlcVersion, contract.CltvExpiry,
+			contract.SenderKey, contract.ReceiverKey, swp.Hash,
+			contract.HtlcOutputType, chainParams,
+		)
+		if err != nil {
+			return nil, err
+		}
+		usedAddrs[htlc.Address.String()] = struct{}{}
+	}
+
+	return usedAddrs, nil
+}
+
+// allowedDestAddrs returns the set of addresses on the destination
+// allow-list. For descriptors on the allow-list, the addresses up to the next
+// index of the registered destination descriptor plus destAllowListGap are
+// included.
+func (s *Client) allowedDestAddrs() (map[string]struct{}, error) {
+	chainParams := s.lndServices.ChainParams
+
+	var nextIndex uint32
+	dest, err := s.Store.FetchDestDescriptor()
+	if err == nil {
+		nextIndex = dest.NextIndex
+	}
+
+	allowed := make(map[string]struct{})
+	for _, entry := range s.DestAllowList {
+		addr, err := btcutil.DecodeAddress(entry, chainParams)
+		if err == nil {
+			allowed[addr.String()] = struct{}{}
+			continue
+		}
+
+		descriptor, err := parseDestDescriptor(entry, chainParams)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allow-list entry %v: "+
+				"%v", entry, err)
+		}
+
+		for i := uint32(0); i < nextIndex+destAllowListGap; i++ {
+			addr, err := descriptor.address(i)
+			if err != nil {
+				return nil, err
+			}
+			allowed[addr.String()] = struct{}{}
+		}
+	}
+
+	return allowed, nil
+}
+
+// ValidateDestAllowList checks that every entry of a destination allow-list
+// is either an address or a destination descriptor for the given network.
+func ValidateDestAllowList(allowList []string,
+	chainParams *chaincfg.Params) error {
+
+	for _, entry := range allowList {
+		addr, err := btcutil.DecodeAddress(entry, chainParams)
+		if err == nil {
+			err := validateDestAddrType(addr, chainParams)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		_, err = parseDestDescriptor(entry, chainParams)
+		if err != nil {
+			return fmt.Errorf("invalid allow-list entry %v: %v",
+				entry, err)
+		}
+	}
+
+	return nil
+}
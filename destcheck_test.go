@@ -0,0 +1,124 @@
+package loop
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/test"
+)
+
+// TestCheckDestAddrs tests the checks on loop out destination addresses.
+func TestCheckDestAddrs(t *testing.T) {
+	defer test.Guard(t)()
+
+	params := &chaincfg.MainNetParams
+
+	lnd := test.NewMockLnd()
+	lndServices := lnd.LndServices
+	lndServices.ChainParams = params
+
+	store := newStoreMock(t)
+	client := newSwapClient(&clientConfig{
+		LndServices: &lndServices,
+		Store:       store,
+	})
+
+	decode := func(addr string) btcutil.Address {
+		t.Helper()
+
+		decoded, err := btcutil.DecodeAddress(addr, params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return decoded
+	}
+	firstAddr := decode(testFirstAddr)
+	secondAddr := decode(testSecondAddr)
+
+	privKey, err := btcec.NewPrivateKey(btcec.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pubKeyAddr, err := btcutil.NewAddressPubKey(
+		privKey.PubKey().SerializeCompressed(), params,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherAddr, err := btcutil.NewAddressWitnessScriptHash(
+		make([]byte, 32), params,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// assertCheck asserts the outcome of checking a loop out to the given
+	// destination address.
+	assertCheck := func(addr btcutil.Address, expectedErr error) {
+		t.Helper()
+
+		err := client.checkDestAddrs(&OutRequest{DestAddr: addr})
+		if err != expectedErr {
+			t.Fatalf("%v: expected error %v, got %v", addr,
+				expectedErr, err)
+		}
+	}
+
+	assertCheck(firstAddr, nil)
+	assertCheck(pubKeyAddr, ErrUnsupportedDestAddr)
+
+	// Addresses of another network are rejected.
+	err = client.checkDestAddrs(&OutRequest{DestAddr: testAddr})
+	if err == nil {
+		t.Fatal("expected network mismatch to be rejected")
+	}
+
+	// Addresses of earlier swaps can't be reused.
+	store.loopOutSwaps[testPreimage.Hash()] = &loopdb.LoopOutContract{
+		DestAddr: firstAddr,
+	}
+	assertCheck(firstAddr, ErrDestAddrReused)
+
+	// Sweep outputs are checked as well.
+	err = client.checkDestAddrs(&OutRequest{
+		DestAddr: secondAddr,
+		SweepOutputs: []loopdb.SweepOutput{
+			{Addr: secondAddr, Weight: 1},
+			{Addr: firstAddr, Weight: 1},
+		},
+	})
+	if err != ErrDestAddrReused {
+		t.Fatalf("expected reused sweep output, got %v", err)
+	}
+
+	// With an allow-list, only listed addresses and addresses derived
+	// from listed descriptors are accepted.
+	allowList := []string{testAccountXpub, otherAddr.String()}
+	if err := ValidateDestAllowList(allowList, params); err != nil {
+		t.Fatal(err)
+	}
+	client.DestAllowList = allowList
+
+	assertCheck(secondAddr, nil)
+	assertCheck(otherAddr, nil)
+
+	thirdAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+		make([]byte, 20), params,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertCheck(thirdAddr, ErrDestAddrNotAllowed)
+
+	// Allow-list entries need to be valid for the network.
+	err = ValidateDestAllowList(
+		[]string{testAccountXpub}, &chaincfg.TestNet3Params,
+	)
+	if err == nil {
+		t.Fatal("expected invalid allow-list to be rejected")
+	}
+}
@@ -0,0 +1,360 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/hdkeychain"
+	"github.com/lightninglabs/loop/loopdb"
+)
+
+var (
+	// ErrDescriptorChecksum is returned when the checksum of an output
+	// descriptor doesn't match.
+	ErrDescriptorChecksum = errors.New("invalid descriptor checksum")
+
+	// ErrDescriptorPrivateKey is returned when a descriptor contains an
+	// extended private key. Only public keys are accepted, because the
+	// descriptor is stored in the loop database.
+	ErrDescriptorPrivateKey = errors.New("descriptor must not contain a " +
+		"private key")
+
+	// ErrDescriptorNoWildcard is returned when the derivation path of a
+	// descriptor doesn't end in an unhardened wildcard, so that it only
+	// describes a single address.
+	ErrDescriptorNoWildcard = errors.New("descriptor derivation path " +
+		"must end in /*")
+
+	// ErrDescriptorHardened is returned when the derivation path of a
+	// descriptor contains a hardened step, which can't be derived from an
+	// extended public key.
+	ErrDescriptorHardened = errors.New("hardened derivation not " +
+		"supported")
+)
+
+const (
+	// descriptorInputCharset is the character set of output descriptors,
+	// ordered as required by the descriptor checksum.
+	descriptorInputCharset = "0123456789()[],'/*abcdefgh@:$%{}" +
+		"IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~" +
+		"ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+
+	// descriptorChecksumCharset is the character set of the descriptor
+	// checksum.
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// destAddrType is the type of address that is derived from a destination
+// descriptor.
+type destAddrType uint8
+
+const (
+	// destAddrP2WKH derives native segwit addresses.
+	destAddrP2WKH destAddrType = iota
+
+	// destAddrNP2WKH derives segwit addresses nested in p2sh.
+	destAddrNP2WKH
+
+	// destAddrP2PKH derives legacy addresses.
+	destAddrP2PKH
+)
+
+// destDescriptor derives loop out destination addresses from an extended
+// public key.
+type destDescriptor struct {
+	// key is the extended public key at the fixed part of the derivation
+	// path. Addresses are derived from its children.
+	key *hdkeychain.ExtendedKey
+
+	addrType destAddrType
+
+	chainParams *chaincfg.Params
+}
+
+// parseDestDescriptor parses a bare extended public key or an output
+// descriptor of the form wpkh(KEY), sh(wpkh(KEY)) or pkh(KEY), where KEY is an
+// extended public key followed by an unhardened derivation path ending in /*.
+// A bare extended public key derives native segwit addresses on its external
+// branch, like wpkh(xpub/0/*). An optional key origin and checksum are
+// accepted.
+func parseDestDescriptor(descriptor string,
+	chainParams *chaincfg.Params) (*destDescriptor, error) {
+
+	if sep := strings.LastIndex(descriptor, "#"); sep != -1 {
+		body, checksum := descriptor[:sep], descriptor[sep+1:]
+		expected, err := descriptorChecksum(body)
+		if err != nil {
+			return nil, err
+		}
+		if checksum != expected {
+			return nil, ErrDescriptorChecksum
+		}
+		descriptor = body
+	}
+
+	addrType := destAddrP2WKH
+	keyExpr := descriptor
+	switch {
+	case strings.HasPrefix(descriptor, "wpkh(") &&
+		strings.HasSuffix(descriptor, ")"):
+
+		keyExpr = descriptor[len("wpkh(") : len(descriptor)-1]
+
+	case strings.HasPrefix(descriptor, "sh(wpkh(") &&
+		strings.HasSuffix(descriptor, "))"):
+
+		keyExpr = descriptor[len("sh(wpkh(") : len(descriptor)-2]
+		addrType = destAddrNP2WKH
+
+	case strings.HasPrefix(descriptor, "pkh(") &&
+		strings.HasSuffix(descriptor, ")"):
+
+		keyExpr = descriptor[len("pkh(") : len(descriptor)-1]
+		addrType = destAddrP2PKH
+
+	case strings.Contains(descriptor, "("):
+		return nil, fmt.Errorf("unsupported descriptor %v, expected "+
+			"wpkh, sh(wpkh) or pkh", descriptor)
+
+	// A bare extended public key derives from its external branch.
+	case !strings.Contains(descriptor, "/"):
+		keyExpr = descriptor + "/0/*"
+	}
+
+	// Skip the key origin, which is only informational.
+	if strings.HasPrefix(keyExpr, "[") {
+		end := strings.Index(keyExpr, "]")
+		if end == -1 {
+			return nil, errors.New("unterminated key origin")
+		}
+		keyExpr = keyExpr[end+1:]
+	}
+
+	path := strings.Split(keyExpr, "/")
+	if len(path) < 2 || path[len(path)-1] != "*" {
+		return nil, ErrDescriptorNoWildcard
+	}
+
+	key, err := hdkeychain.NewKeyFromString(path[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid extended key: %v", err)
+	}
+	if key.IsPrivate() {
+		return nil, ErrDescriptorPrivateKey
+	}
+	if !key.IsForNet(chainParams) {
+		return nil, fmt.Errorf("extended key is not for %v",
+			chainParams.Name)
+	}
+
+	for _, step := range path[1 : len(path)-1] {
+		hardened := strings.HasSuffix(step, "'") ||
+			strings.HasSuffix(step, "h")
+		if hardened {
+			return nil, ErrDescriptorHardened
+		}
+
+		index, err := strconv.ParseUint(step, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid derivation step %v",
+				step)
+		}
+		if index >= hdkeychain.HardenedKeyStart {
+			return nil, ErrDescriptorHardened
+		}
+
+		key, err = key.Child(uint32(index))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &destDescriptor{
+		key:         key,
+		addrType:    addrType,
+		chainParams: chainParams,
+	}, nil
+}
+
+// address derives the address at the given index.
+func (d *destDescriptor) address(index uint32) (btcutil.Address, error) {
+	if index >= hdkeychain.HardenedKeyStart {
+		return nil, errors.New("descriptor derivation index exhausted")
+	}
+
+	child, err := d.key.Child(index)
+	if err != nil {
+		return nil, err
+	}
+
+	pubKey, err := child.ECPubKey()
+	if err != nil {
+		return nil, err
+	}
+	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+
+	switch d.addrType {
+	case destAddrP2WKH:
+		return btcutil.NewAddressWitnessPubKeyHash(
+			pubKeyHash, d.chainParams,
+		)
+
+	case destAddrNP2WKH:
+		// The redeem script of a nested p2wkh output is the witness
+		// program: OP_0 <pubkey hash>.
+		redeemScript := append([]byte{0x00, 0x14}, pubKeyHash...)
+
+		return btcutil.NewAddressScriptHash(
+			redeemScript, d.chainParams,
+		)
+
+	case destAddrP2PKH:
+		return btcutil.NewAddressPubKeyHash(pubKeyHash, d.chainParams)
+
+	default:
+		return nil, fmt.Errorf("unknown address type %v", d.addrType)
+	}
+}
+
+// descriptorPolymod is the step function of the descriptor checksum.
+func descriptorPolymod(c uint64, val int) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ uint64(val)
+
+	generators := []uint64{
+		0xf5dee51989, 0xa9fdca3312, 0x1bab10e32d, 0x3706b1677a,
+		0x644d626ffd,
+	}
+	for i, generator := range generators {
+		if c0&(1<<uint(i)) != 0 {
+			c ^= generator
+		}
+	}
+
+	return c
+}
+
+// descriptorChecksum computes the eight character checksum of an output
+// descriptor.
+func descriptorChecksum(descriptor string) (string, error) {
+	var (
+		c        uint64 = 1
+		class    int
+		classCnt int
+	)
+	for _, ch := range descriptor {
+		pos := strings.IndexRune(descriptorInputCharset, ch)
+		if pos == -1 {
+			return "", fmt.Errorf("invalid descriptor character "+
+				"%q", ch)
+		}
+
+		c = descriptorPolymod(c, pos&31)
+		class = class*3 + pos>>5
+		classCnt++
+		if classCnt == 3 {
+			c = descriptorPolymod(c, class)
+			class = 0
+			classCnt = 0
+		}
+	}
+	if classCnt > 0 {
+		c = descriptorPolymod(c, class)
+	}
+	for i := 0; i < 8; i++ {
+		c = descriptorPolymod(c, 0)
+	}
+	c ^= 1
+
+	checksum := make([]byte, 8)
+	for i := range checksum {
+		checksum[i] = descriptorChecksumCharset[(c>>(5*uint(7-i)))&31]
+	}
+
+	return string(checksum), nil
+}
+
+// RegisterDestDescriptor registers an extended public key or output
+// descriptor that loop out destination addresses are derived from when no
+// destination address is specified. A descriptor that was registered before
+// continues where it left off.
+func (s *Client) RegisterDestDescriptor(descriptor string) (
+	*loopdb.DestDescriptor, error) {
+
+	_, err := parseDestDescriptor(descriptor, s.lndServices.ChainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("Registering destination descriptor %v", descriptor)
+
+	return s.Store.RegisterDestDescriptor(descriptor)
+}
+
+// UnregisterDestDescriptor removes the registered destination descriptor.
+// Loop outs without a destination address sweep to the lnd wallet again.
+func (s *Client) UnregisterDestDescriptor() error {
+	return s.Store.UnregisterDestDescriptor()
+}
+
+// FetchDestDescriptor returns the registered destination descriptor.
+func (s *Client) FetchDestDescriptor() (*loopdb.DestDescriptor, error) {
+	return s.Store.FetchDestDescriptor()
+}
+
+// NextDestAddr returns a fresh loop out destination address. If a destination
+// descriptor is registered, the address is derived at its next unused index.
+// Addresses that were already used by an earlier loop out are skipped.
+// Otherwise a new address of the lnd wallet is returned.
+func (s *Client) NextDestAddr(ctx context.Context) (btcutil.Address, error) {
+	dest, err := s.Store.FetchDestDescriptor()
+	switch {
+	case err == loopdb.ErrNoDestDescriptor:
+		return s.lndServices.WalletKit.NextAddr(ctx)
+
+	case err != nil:
+		return nil, err
+	}
+
+	descriptor, err := parseDestDescriptor(
+		dest.Descriptor, s.lndServices.ChainParams,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	usedAddrs, err := s.usedSwapAddrs(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		dest, err := s.Store.NextDestIndex()
+		if err != nil {
+			return nil, err
+		}
+
+		addr, err := descriptor.address(dest.NextIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := usedAddrs[addr.String()]; ok {
+			log.Infof("Skipping destination address %v at index "+
+				"%v, already used by an earlier swap", addr,
+				dest.NextIndex)
+
+			continue
+		}
+
+		log.Infof("Derived destination address %v at index %v", addr,
+			dest.NextIndex)
+
+		return addr, nil
+	}
+}
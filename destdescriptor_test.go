@@ -0,0 +1,222 @@
+package loop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/test"
+)
+
+const (
+	// testAccountXpub is the BIP84 account key of the BIP39 test mnemonic
+	// "abandon abandon ... about", in xpub encoding.
+	testAccountXpub = "xpub6CatWdiZiodmUeTDp8LT5or8nmbKNcuyvz7WyksVFkKB4R" +
+		"HwCD3XyuvPEbvqAQY3rAPshWcMLoP2fMFMKHPJ4ZeZXYVUhLv1VMrjPC7PW6V"
+
+	// testFirstAddr is the first receive address of the BIP84 test
+	// vectors.
+	testFirstAddr = "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu"
+
+	// testSecondAddr is the second receive address of the BIP84 test
+	// vectors.
+	testSecondAddr = "bc1qnjg0jd8228aq7egyzacy8cys3knf9xvrerkf9g"
+)
+
+// TestParseDestDescriptor tests the parsing of destination descriptors and
+// the derivation of addresses from them.
+func TestParseDestDescriptor(t *testing.T) {
+	params := &chaincfg.MainNetParams
+
+	checksum, err := descriptorChecksum("raw(deadbeef)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checksum != "89f8spxm" {
+		t.Fatalf("unexpected checksum %v", checksum)
+	}
+
+	wpkh := "wpkh([d34db33f/84'/0'/0']" + testAccountXpub + "/0/*)"
+	wpkhChecksum, err := descriptorChecksum(wpkh)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validTests := []struct {
+		descriptor string
+		addr       string
+	}{
+		{
+			descriptor: testAccountXpub,
+			addr:       testFirstAddr,
+		},
+		{
+			descriptor: wpkh,
+			addr:       testFirstAddr,
+		},
+		{
+			descriptor: wpkh + "#" + wpkhChecksum,
+			addr:       testFirstAddr,
+		},
+	}
+
+	for _, test := range validTests {
+		descriptor, err := parseDestDescriptor(test.descriptor, params)
+		if err != nil {
+			t.Fatalf("%v: %v", test.descriptor, err)
+		}
+
+		addr, err := descriptor.address(0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if addr.String() != test.addr {
+			t.Fatalf("%v: expected address %v, got %v",
+				test.descriptor, test.addr, addr)
+		}
+	}
+
+	// Legacy descriptors derive the p2pkh address of the same key.
+	firstAddr, err := btcutil.DecodeAddress(testFirstAddr, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	descriptor, err := parseDestDescriptor(
+		"pkh("+testAccountXpub+"/0/*)", params,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err := descriptor.address(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := addr.(*btcutil.AddressPubKeyHash); !ok {
+		t.Fatalf("expected p2pkh address, got %T", addr)
+	}
+	if string(addr.ScriptAddress()) != string(firstAddr.ScriptAddress()) {
+		t.Fatalf("unexpected pubkey hash of %v", addr)
+	}
+
+	// Nested segwit descriptors derive p2sh addresses.
+	descriptor, err = parseDestDescriptor(
+		"sh(wpkh("+testAccountXpub+"/0/*))", params,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, err = descriptor.address(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := addr.(*btcutil.AddressScriptHash); !ok {
+		t.Fatalf("expected p2sh address, got %T", addr)
+	}
+
+	invalidTests := []struct {
+		descriptor string
+		err        error
+	}{
+		{
+			descriptor: wpkh + "#qqqqqqqq",
+			err:        ErrDescriptorChecksum,
+		},
+		{
+			descriptor: "wpkh(" + testAccountXpub + "/0/1)",
+			err:        ErrDescriptorNoWildcard,
+		},
+		{
+			descriptor: "wpkh(" + testAccountXpub + "/0'/*)",
+			err:        ErrDescriptorHardened,
+		},
+	}
+
+	for _, test := range invalidTests {
+		_, err := parseDestDescriptor(test.descriptor, params)
+		if err != test.err {
+			t.Fatalf("%v: expected error %v, got %v",
+				test.descriptor, test.err, err)
+		}
+	}
+
+	// Keys of another network are rejected.
+	_, err = parseDestDescriptor(
+		testAccountXpub, &chaincfg.TestNet3Params,
+	)
+	if err == nil {
+		t.Fatal("expected network mismatch to be rejected")
+	}
+}
+
+// TestNextDestAddr tests that loop out destination addresses are derived from
+// the registered descriptor and that addresses of earlier swaps are skipped.
+func TestNextDestAddr(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+	lndServices := lnd.LndServices
+	lndServices.ChainParams = &chaincfg.MainNetParams
+
+	store := newStoreMock(t)
+	client := newSwapClient(&clientConfig{
+		LndServices: &lndServices,
+		Store:       store,
+	})
+
+	ctx := context.Background()
+
+	// Without a descriptor, the address comes from the lnd wallet.
+	addr, err := client.NextDestAddr(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr.String() == testFirstAddr {
+		t.Fatal("expected wallet address")
+	}
+
+	// Private keys are rejected.
+	_, err = client.RegisterDestDescriptor(
+		"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqj" +
+			"iChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+	)
+	if err != ErrDescriptorPrivateKey {
+		t.Fatalf("expected private key error, got %v", err)
+	}
+
+	_, err = client.RegisterDestDescriptor(testAccountXpub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first address was already used by an earlier swap, so it is
+	// skipped.
+	firstAddr, err := btcutil.DecodeAddress(
+		testFirstAddr, lndServices.ChainParams,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.loopOutSwaps[testPreimage.Hash()] = &loopdb.LoopOutContract{
+		DestAddr: firstAddr,
+	}
+
+	addr, err = client.NextDestAddr(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr.String() != testSecondAddr {
+		t.Fatalf("expected address %v, got %v", testSecondAddr, addr)
+	}
+
+	// The next address is derived at the next index.
+	dest, err := client.FetchDestDescriptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dest.NextIndex != 2 {
+		t.Fatalf("expected next index 2, got %v", dest.NextIndex)
+	}
+}
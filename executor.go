@@ -22,6 +22,8 @@ type executorConfig struct {
 	store loopdb.SwapStore
 
 	createExpiryTimer func(expiry time.Duration) <-chan time.Time
+
+	paymentRetryPolicy PaymentRetryPolicy
 }
 
 // executor is responsible for executing swaps.
@@ -31,7 +33,12 @@ type executor struct {
 	wg            sync.WaitGroup
 	newSwaps      chan genericSwap
 	currentHeight uint32
-	ready         chan struct{}
+
+	// newBlockSubscribers receives the queues of subscribers that want to
+	// be notified of every new block.
+	newBlockSubscribers chan *queue.ConcurrentQueue
+
+	ready chan struct{}
 
 	executorConfig
 }
@@ -39,9 +46,10 @@ type executor struct {
 // newExecutor returns a new swap executor instance.
 func newExecutor(cfg *executorConfig) *executor {
 	return &executor{
-		executorConfig: *cfg,
-		newSwaps:       make(chan genericSwap),
-		ready:          make(chan struct{}),
+		executorConfig:      *cfg,
+		newSwaps:            make(chan genericSwap),
+		ready:               make(chan struct{}),
+		newBlockSubscribers: make(chan *queue.ConcurrentQueue),
 	}
 }
 
@@ -86,11 +94,18 @@ func (s *executor) run(mainCtx context.Context,
 	// swaps.
 	blockEpochQueues := make(map[int]*queue.ConcurrentQueue)
 
+	// Block subscribers that aren't swaps are kept separately, because
+	// they live as long as the executor.
+	var blockSubscribers []*queue.ConcurrentQueue
+
 	// On exit, stop all queue goroutines.
 	defer func() {
 		for _, queue := range blockEpochQueues {
 			queue.Stop()
 		}
+		for _, queue := range blockSubscribers {
+			queue.Stop()
+		}
 	}()
 
 	swapDoneChan := make(chan int)
@@ -109,10 +124,11 @@ func (s *executor) run(mainCtx context.Context,
 				defer s.wg.Done()
 
 				newSwap.execute(mainCtx, &executeConfig{
-					statusChan:     statusChan,
-					sweeper:        s.sweeper,
-					blockEpochChan: queue.ChanOut(),
-					timerFactory:   s.executorConfig.createExpiryTimer,
+					statusChan:         statusChan,
+					sweeper:            s.sweeper,
+					blockEpochChan:     queue.ChanOut(),
+					timerFactory:       s.executorConfig.createExpiryTimer,
+					paymentRetryPolicy: s.paymentRetryPolicy,
 				}, height)
 
 				select {
@@ -133,6 +149,9 @@ func (s *executor) run(mainCtx context.Context,
 			queue.Stop()
 			delete(blockEpochQueues, doneID)
 
+		case subscriber := <-s.newBlockSubscribers:
+			blockSubscribers = append(blockSubscribers, subscriber)
+
 		case h := <-blockEpochChan:
 			setHeight(h)
 			for _, queue := range blockEpochQueues {
@@ -142,6 +161,13 @@ func (s *executor) run(mainCtx context.Context,
 					return mainCtx.Err()
 				}
 			}
+			for _, queue := range blockSubscribers {
+				select {
+				case queue.ChanIn() <- h:
+				case <-mainCtx.Done():
+					return mainCtx.Err()
+				}
+			}
 
 		case err := <-blockErrorChan:
 			return fmt.Errorf("block error: %v", err)
@@ -163,6 +189,25 @@ func (s *executor) initiateSwap(ctx context.Context,
 	}
 }
 
+// subscribeBlocks returns a channel that delivers the height of every new
+// block that the executor receives from now on. The subscription ends when the
+// executor exits.
+func (s *executor) subscribeBlocks(ctx context.Context) (<-chan interface{},
+	error) {
+
+	queue := queue.NewConcurrentQueue(10)
+	queue.Start()
+
+	select {
+	case s.newBlockSubscribers <- queue:
+		return queue.ChanOut(), nil
+
+	case <-ctx.Done():
+		queue.Stop()
+		return nil, ctx.Err()
+	}
+}
+
 // height returns the current height known to the swap server.
 func (s *executor) height() int32 {
 	return int32(atomic.LoadUint32(&s.currentHeight))
@@ -0,0 +1,95 @@
+package loop
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/lightninglabs/loop/swap"
+)
+
+var (
+	// ErrIdempotencyKeyConflict is returned when a swap request reuses the
+	// idempotency key of an earlier request with different parameters.
+	ErrIdempotencyKeyConflict = errors.New("idempotency key was used " +
+		"for a request with different parameters")
+
+	// ErrIdempotencyKeyInUse is returned when a request with the same
+	// idempotency key is still being processed.
+	ErrIdempotencyKeyInUse = errors.New("a request with this " +
+		"idempotency key is in progress")
+
+	// ErrIdempotencyKeySplit is returned when a split swap request
+	// carries an idempotency key.
+	ErrIdempotencyKeySplit = errors.New("idempotency keys are not " +
+		"supported for split swaps")
+)
+
+// idempotencyKeys tracks the idempotency keys of the requests that are being
+// processed, so that concurrent requests with the same key can't start two
+// swaps. The zero value is ready to use.
+type idempotencyKeys struct {
+	pending map[string]struct{}
+
+	sync.Mutex
+}
+
+// IdempotentSwap returns the swap that was started with the given idempotency
+// key, or nil if no swap was started with it. If the key was used for a swap
+// of a different type or a request with a different digest,
+// ErrIdempotencyKeyConflict is returned.
+func (s *Client) IdempotentSwap(swapType swap.Type, key string,
+	digest [32]byte) (*SwapInfo, error) {
+
+	swaps, err := s.FetchSwaps()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, info := range swaps {
+		if info.IdempotencyKey != key {
+			continue
+		}
+
+		if info.SwapType != swapType || info.RequestDigest != digest {
+			return nil, ErrIdempotencyKeyConflict
+		}
+
+		return info, nil
+	}
+
+	return nil, nil
+}
+
+// claimIdempotencyKey returns the swap that was started with the given key, if
+// any. Otherwise, the key is reserved until the returned release function is
+// called. The swap must be persisted before, so that later requests with the
+// key find it.
+func (s *Client) claimIdempotencyKey(swapType swap.Type, key string,
+	digest [32]byte) (*SwapInfo, func(), error) {
+
+	s.idempotencyKeys.Lock()
+	defer s.idempotencyKeys.Unlock()
+
+	if _, ok := s.idempotencyKeys.pending[key]; ok {
+		return nil, nil, ErrIdempotencyKeyInUse
+	}
+
+	info, err := s.IdempotentSwap(swapType, key, digest)
+	if err != nil || info != nil {
+		return info, nil, err
+	}
+
+	if s.idempotencyKeys.pending == nil {
+		s.idempotencyKeys.pending = make(map[string]struct{})
+	}
+	s.idempotencyKeys.pending[key] = struct{}{}
+
+	release := func() {
+		s.idempotencyKeys.Lock()
+		defer s.idempotencyKeys.Unlock()
+
+		delete(s.idempotencyKeys.pending, key)
+	}
+
+	return nil, release, nil
+}
@@ -0,0 +1,103 @@
+package loop
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/lightninglabs/loop/lndclient"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// TestClaimIdempotencyKey tests that a request with the idempotency key of a
+// stored swap returns that swap, and that conflicting or concurrent requests
+// with the key are rejected.
+func TestClaimIdempotencyKey(t *testing.T) {
+	defer test.Guard(t)()
+
+	_, senderPubKey := test.CreateKey(1)
+	var senderKey [33]byte
+	copy(senderKey[:], senderPubKey.SerializeCompressed())
+
+	_, receiverPubKey := test.CreateKey(2)
+	var receiverKey [33]byte
+	copy(receiverKey[:], receiverPubKey.SerializeCompressed())
+
+	digest := sha256.Sum256([]byte("request"))
+	hash := lntypes.Hash(sha256.Sum256(testPreimage[:]))
+
+	store := newStoreMock(t)
+	store.loopOutSwaps[hash] = &loopdb.LoopOutContract{
+		SwapContract: loopdb.SwapContract{
+			Preimage:       testPreimage,
+			CltvExpiry:     744,
+			ReceiverKey:    receiverKey,
+			SenderKey:      senderKey,
+			IdempotencyKey: "retry",
+			RequestDigest:  digest,
+		},
+	}
+
+	client := &Client{
+		lndServices: &lndclient.LndServices{
+			ChainParams: &chaincfg.TestNet3Params,
+		},
+		clientConfig: clientConfig{
+			Store: store,
+		},
+	}
+
+	// A repeated request returns the stored swap.
+	info, _, err := client.claimIdempotencyKey(
+		swap.TypeOut, "retry", digest,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info == nil || info.SwapHash != hash {
+		t.Fatalf("expected swap %v to be returned", hash)
+	}
+
+	// Reusing the key with different parameters or for another swap type
+	// is rejected.
+	_, _, err = client.claimIdempotencyKey(
+		swap.TypeOut, "retry", sha256.Sum256([]byte("other")),
+	)
+	if err != ErrIdempotencyKeyConflict {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+
+	_, _, err = client.claimIdempotencyKey(swap.TypeIn, "retry", digest)
+	if err != ErrIdempotencyKeyConflict {
+		t.Fatalf("expected conflict, got %v", err)
+	}
+
+	// A new key is reserved until it is released.
+	info, release, err := client.claimIdempotencyKey(
+		swap.TypeOut, "new", digest,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info != nil {
+		t.Fatal("expected no swap for new key")
+	}
+
+	_, _, err = client.claimIdempotencyKey(swap.TypeOut, "new", digest)
+	if err != ErrIdempotencyKeyInUse {
+		t.Fatalf("expected key in use, got %v", err)
+	}
+
+	release()
+
+	_, release, err = client.claimIdempotencyKey(
+		swap.TypeOut, "new", digest,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/swap"
 	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 )
 
 // OutRequest contains the required parameters for a loop out swap.
@@ -18,6 +19,13 @@ type OutRequest struct {
 	// Destination address for the swap.
 	DestAddr btcutil.Address
 
+	// SweepOutputs optionally divides the swept funds over multiple
+	// outputs, each receiving either a fixed amount or a weighted share of
+	// the value that remains after fees. At least one output needs to be
+	// weighted and DestAddr needs to be the address of the first output.
+	// If empty, all funds are swept to DestAddr.
+	SweepOutputs []loopdb.SweepOutput
+
 	// MaxSwapRoutingFee is the maximum off-chain fee in msat that may be
 	// paid for payment to the server. This limit is applied during path
 	// finding. Typically this value is taken from the response of the
@@ -63,6 +71,20 @@ type OutRequest struct {
 	// client sweep tx.
 	SweepConfTarget int32
 
+	// SweepFeeRateCeiling optionally specifies the maximum fee rate at
+	// which the htlc is swept. While the fee estimate for SweepConfTarget
+	// is above the ceiling, the preimage isn't revealed and the sweep
+	// waits for fees to come down. Once the swap is SweepSafetyMargin
+	// blocks away from its expiry, the ceiling no longer applies and the
+	// sweep fee is escalated to make sure the htlc is swept in time.
+	SweepFeeRateCeiling chainfee.SatPerKWeight
+
+	// SweepSafetyMargin is the number of blocks before the htlc expiry at
+	// which the sweep stops waiting for the fee rate ceiling. If zero,
+	// DefaultSweepSafetyMargin is used. It only applies if a fee rate
+	// ceiling is set.
+	SweepSafetyMargin int32
+
 	// LoopOutChannel optionally specifies the short channel id of the
 	// channel to loop out.
 	LoopOutChannel *uint64
@@ -70,6 +92,44 @@ type OutRequest struct {
 	// SwapPublicationDeadline can be set by the client to allow the server
 	// delaying publication of the swap HTLC to save on chain fees.
 	SwapPublicationDeadline time.Time
+
+	// QuoteID optionally references a quote obtained from LoopOutQuote.
+	// If set, the server is asked to lock the quoted fees and the swap is
+	// rejected if the server invoices exceed them.
+	QuoteID []byte
+
+	// ProbeRoute indicates that a probe payment for the swap invoice
+	// amount should be sent to the server before the swap is initiated.
+	// If the probe doesn't find a route, the swap is aborted before any
+	// funds move.
+	ProbeRoute bool
+
+	// ServerID optionally selects the swap server. If not set, the server
+	// of the referenced quote or otherwise the cheapest eligible server
+	// is used.
+	ServerID string
+
+	// IdempotencyKey optionally identifies the request across retries. If
+	// a swap was already started with this key, that swap is returned
+	// instead of starting another one.
+	IdempotencyKey string
+
+	// RequestDigest commits to the parameters of the request. A request
+	// that reuses an idempotency key with a different digest is rejected.
+	// It is only used along with an idempotency key.
+	RequestDigest [32]byte
+
+	// Label is an optional user label of the swap. It is also attached to
+	// the on-chain transactions of the swap if the wallet supports it.
+	Label string
+
+	// Metadata holds optional user key/value pairs of the swap.
+	Metadata map[string]string
+
+	// ownSwaps are the earlier swaps of the recurring loop out that
+	// initiates this swap. Their destination addresses don't count as
+	// reused, so that every run can sweep to a fixed address.
+	ownSwaps map[lntypes.Hash]struct{}
 }
 
 // Out contains the full details of a loop out request. This includes things
@@ -103,6 +163,23 @@ type LoopOutQuoteRequest struct {
 	// delaying publication of the swap HTLC to save on chain fees.
 	SwapPublicationDeadline time.Time
 
+	// Split indicates that amounts above the server maximum should be
+	// quoted as multiple server-sized swaps.
+	Split bool
+
+	// SweepOutputs is the number of outputs of the sweep tx, which is
+	// used to estimate the miner fee. Zero is treated as a single output.
+	SweepOutputs int
+
+	// LoopOutChannel optionally restricts the routes that are used to
+	// estimate the off-chain routing fees to the given channel.
+	LoopOutChannel *uint64
+
+	// ServerID optionally restricts the quote to a single swap server. If
+	// not set, all servers are queried and the cheapest quote is
+	// returned.
+	ServerID string
+
 	// TODO: Add argument to specify confirmation target for server
 	// publishing htlc. This may influence the swap fee quote, because the
 	// server needs to pay more for faster confirmations.
@@ -146,6 +223,31 @@ type LoopOutQuote struct {
 	// SwapPaymentDest is the node pubkey where to swap payment needs to be
 	// sent to.
 	SwapPaymentDest [33]byte
+
+	// NumSwaps is the number of swaps that this quote covers. It is
+	// larger than one for split quotes, in which case all fees are the
+	// combined fees of all swaps.
+	NumSwaps int
+
+	// QuoteID identifies this quote at the server. It can be passed in a
+	// swap request to lock the quoted fees. It is not set for split
+	// quotes or by servers that don't support quote ids.
+	QuoteID []byte
+
+	// QuoteExpiry is the time until which the server honors the quote.
+	QuoteExpiry time.Time
+
+	// SwapRoutingFee is an estimate of the routing fee of the off-chain
+	// swap payment, based on the best route that lnd finds to the swap
+	// payment destination. It is zero if lnd finds no route.
+	SwapRoutingFee btcutil.Amount
+
+	// PrepayRoutingFee is an estimate of the routing fee of the off-chain
+	// prepayment. It is zero if lnd finds no route.
+	PrepayRoutingFee btcutil.Amount
+
+	// ServerID identifies the swap server that issued the quote.
+	ServerID string
 }
 
 // LoopInRequest contains the required parameters for the swap.
@@ -180,6 +282,53 @@ type LoopInRequest struct {
 	// ExternalHtlc specifies whether the htlc is published by an external
 	// source.
 	ExternalHtlc bool
+
+	// NativeSegwitHtlc specifies whether the htlc is a native segwit
+	// (P2WSH) output instead of the default nested segwit (NP2WSH) output.
+	// This saves weight for callers with segwit wallets. The server may
+	// fall back to a nested segwit htlc if it doesn't support it.
+	NativeSegwitHtlc bool
+
+	// RefundAddr optionally specifies the address that the htlc is
+	// refunded to if the swap times out. If not set, the funds return to
+	// the lnd wallet. Swaps with an external htlc should specify the
+	// address of the wallet that published the htlc.
+	RefundAddr btcutil.Address
+
+	// RefundConfTarget optionally specifies the confirmation target of
+	// the refund tx. Defaults to TimeoutTxConfTarget.
+	RefundConfTarget int32
+
+	// RefundFeeRate optionally specifies a fixed fee rate for the refund
+	// tx. It can't be combined with RefundConfTarget.
+	RefundFeeRate chainfee.SatPerKWeight
+
+	// QuoteID optionally references a quote obtained from LoopInQuote. If
+	// set, the quoted swap fee is used instead of requesting a new quote
+	// and the server is asked to honor it.
+	QuoteID []byte
+
+	// ServerID optionally selects the swap server. If not set, the server
+	// of the referenced quote or otherwise the cheapest eligible server
+	// is used.
+	ServerID string
+
+	// IdempotencyKey optionally identifies the request across retries. If
+	// a swap was already started with this key, that swap is returned
+	// instead of starting another one.
+	IdempotencyKey string
+
+	// RequestDigest commits to the parameters of the request. A request
+	// that reuses an idempotency key with a different digest is rejected.
+	// It is only used along with an idempotency key.
+	RequestDigest [32]byte
+
+	// Label is an optional user label of the swap. It is also attached to
+	// the on-chain transactions of the swap if the wallet supports it.
+	Label string
+
+	// Metadata holds optional user key/value pairs of the swap.
+	Metadata map[string]string
 }
 
 // LoopInTerms are the server terms on which it executes loop in swaps.
@@ -217,6 +366,15 @@ type LoopInQuoteRequest struct {
 	// ExternalHtlc specifies whether the htlc is published by an external
 	// source.
 	ExternalHtlc bool
+
+	// Split indicates that amounts above the server maximum should be
+	// quoted as multiple server-sized swaps.
+	Split bool
+
+	// ServerID optionally restricts the quote to a single swap server. If
+	// not set, all servers are queried and the cheapest quote is
+	// returned.
+	ServerID string
 }
 
 // LoopInQuote contains estimates for the fees making up the total swap cost
@@ -232,6 +390,22 @@ type LoopInQuote struct {
 	// Time lock delta relative to current block height that swap server
 	// will accept on the swap initiation call.
 	CltvDelta int32
+
+	// NumSwaps is the number of swaps that this quote covers. It is
+	// larger than one for split quotes, in which case all fees are the
+	// combined fees of all swaps.
+	NumSwaps int
+
+	// QuoteID identifies this quote at the server. It can be passed in a
+	// swap request to lock the quoted fees. It is not set for split
+	// quotes or by servers that don't support quote ids.
+	QuoteID []byte
+
+	// QuoteExpiry is the time until which the server honors the quote.
+	QuoteExpiry time.Time
+
+	// ServerID identifies the swap server that issued the quote.
+	ServerID string
 }
 
 // SwapInfoKit contains common swap info fields.
@@ -244,6 +418,67 @@ type SwapInfoKit struct {
 	LastUpdateTime time.Time
 }
 
+// SweepStatus describes the most recent decision of a loop out swap on the
+// sweep of its htlc.
+type SweepStatus uint8
+
+const (
+	// SweepStatusNone indicates that no sweep decision was made yet in
+	// the current run of the daemon.
+	SweepStatusNone SweepStatus = iota
+
+	// SweepStatusWaiting indicates that the sweep is postponed because
+	// the fee rate exceeds the fee rate ceiling or the maximum miner fee.
+	SweepStatusWaiting
+
+	// SweepStatusPublished indicates that the sweep was published within
+	// the limits of the sweep strategy.
+	SweepStatusPublished
+
+	// SweepStatusEscalated indicates that the swap reached its safety
+	// margin and the sweep was published with a fee rate that is no
+	// longer limited by the fee rate ceiling.
+	SweepStatusEscalated
+)
+
+// String returns a string representation of the sweep status.
+func (s SweepStatus) String() string {
+	switch s {
+	case SweepStatusNone:
+		return "None"
+
+	case SweepStatusWaiting:
+		return "Waiting"
+
+	case SweepStatusPublished:
+		return "Published"
+
+	case SweepStatusEscalated:
+		return "Escalated"
+
+	default:
+		return "Unknown"
+	}
+}
+
+// SweepStrategy describes how a loop out swap sweeps its htlc.
+type SweepStrategy struct {
+	// ConfTarget is the confirmation target of the sweep.
+	ConfTarget int32
+
+	// FeeRateCeiling is the maximum fee rate of the sweep while the swap
+	// is outside of its safety margin. Zero means that the sweep is only
+	// limited by the maximum miner fee.
+	FeeRateCeiling chainfee.SatPerKWeight
+
+	// SafetyMargin is the number of blocks before the htlc expiry at
+	// which the fee rate ceiling no longer applies.
+	SafetyMargin int32
+
+	// Status is the most recent sweep decision.
+	Status SweepStatus
+}
+
 // SwapInfo exposes common info fields for loop in and loop out swaps.
 type SwapInfo struct {
 	loopdb.SwapStateData
@@ -257,6 +492,79 @@ type SwapInfo struct {
 	loopdb.SwapContract
 
 	HtlcAddress btcutil.Address
+
+	// SweepStrategy describes how the htlc of a loop out swap is swept.
+	// It is nil for loop in swaps.
+	SweepStrategy *SweepStrategy
+
+	// Deposits are the outputs that pay to the htlc of a loop in swap.
+	Deposits []loopdb.LoopInDeposit
+
+	// ServerUpdate is the most recent update that the server pushed for
+	// this swap, if any. Updates from the swap itself leave it nil, so
+	// the receiver merges it with the server updates it received.
+	ServerUpdate *loopdb.ServerSwapUpdate
+}
+
+// ServerUpdate is an update that the server pushed to the client. Either
+// SwapUpdate or TermsUpdate is set.
+type ServerUpdate struct {
+	// ServerID identifies the server that pushed the update.
+	ServerID string
+
+	// SwapHash identifies the swap that a swap update refers to.
+	SwapHash lntypes.Hash
+
+	// SwapUpdate is the new server side state of the swap.
+	SwapUpdate *loopdb.ServerSwapUpdate
+
+	// TermsUpdate describes a change of the terms or fees of the server.
+	TermsUpdate *TermsUpdate
+}
+
+// TermsUpdate is a change of the terms or fees of the server.
+type TermsUpdate struct {
+	// LoopOutTerms are the new loop out terms, if they changed.
+	LoopOutTerms *LoopOutTerms
+
+	// LoopInTerms are the new loop in terms, if they changed.
+	LoopInTerms *LoopInTerms
+
+	// FeesChanged indicates that the swap fees changed. Quotes that were
+	// handed out before are no longer valid.
+	FeesChanged bool
+}
+
+// SwapGroupPart describes a single swap that was launched as part of a swap
+// group.
+type SwapGroupPart struct {
+	// Hash is the hash that uniquely identifies the swap.
+	Hash lntypes.Hash
+
+	// HtlcAddress is the address of the on-chain htlc of the swap.
+	HtlcAddress btcutil.Address
+
+	// Amount is the amount swapped by this part.
+	Amount btcutil.Amount
+}
+
+// SwapGroupInfo exposes the combined status of a group of swaps that were
+// created from a single split swap request.
+type SwapGroupInfo struct {
+	loopdb.SwapGroup
+
+	// State is the combined state of all swaps in the group.
+	State SwapGroupState
+
+	// AmountSwapped is the sum of the amounts of all swaps in the group
+	// that completed successfully.
+	AmountSwapped btcutil.Amount
+
+	// Cost is the sum of the costs of all swaps in the group.
+	Cost loopdb.SwapCost
+
+	// Swaps contains the individual swaps of the group.
+	Swaps []*SwapInfo
 }
 
 // LastUpdate returns the last update time of the swap
@@ -0,0 +1,136 @@
+package loop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightninglabs/loop/lndclient"
+	"github.com/lightninglabs/loop/loopdb"
+)
+
+const (
+	// MaxLabelLength is the maximum length of a swap label. It matches
+	// the maximum length of an lnd transaction label, so that the label
+	// can be attached to the transactions of the swap.
+	MaxLabelLength = 500
+
+	// MaxMetadataPairs is the maximum number of metadata pairs of a swap.
+	MaxMetadataPairs = 32
+
+	// MaxMetadataLength is the maximum length of a metadata key or value.
+	MaxMetadataLength = 256
+)
+
+var (
+	// ErrLabelTooLong is returned when a swap label exceeds
+	// MaxLabelLength.
+	ErrLabelTooLong = fmt.Errorf("label exceeds %v characters",
+		MaxLabelLength)
+
+	// ErrTooManyMetadataPairs is returned when a swap request carries more
+	// than MaxMetadataPairs metadata pairs.
+	ErrTooManyMetadataPairs = fmt.Errorf("more than %v metadata pairs",
+		MaxMetadataPairs)
+
+	// ErrInvalidMetadata is returned when a metadata key is empty or a
+	// metadata key or value exceeds MaxMetadataLength.
+	ErrInvalidMetadata = fmt.Errorf("metadata keys must be non-empty "+
+		"and keys and values must not exceed %v characters",
+		MaxMetadataLength)
+)
+
+// validateLabels checks the label and metadata of a swap request.
+func validateLabels(label string, metadata map[string]string) error {
+	if len(label) > MaxLabelLength {
+		return ErrLabelTooLong
+	}
+
+	if len(metadata) > MaxMetadataPairs {
+		return ErrTooManyMetadataPairs
+	}
+
+	for key, value := range metadata {
+		if key == "" || len(key) > MaxMetadataLength ||
+			len(value) > MaxMetadataLength {
+
+			return ErrInvalidMetadata
+		}
+	}
+
+	return nil
+}
+
+// ParseMetadata parses metadata pairs of the form key=value. It returns nil if
+// no pairs are given.
+func ParseMetadata(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+
+	metadata := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid metadata pair %v, "+
+				"expected key=value", pair)
+		}
+
+		if _, ok := metadata[parts[0]]; ok {
+			return nil, fmt.Errorf("duplicate metadata key %v",
+				parts[0])
+		}
+
+		metadata[parts[0]] = parts[1]
+	}
+
+	return metadata, nil
+}
+
+// SwapFilter selects swaps by their label and metadata. The zero value
+// matches all swaps.
+type SwapFilter struct {
+	// Label only matches swaps with this label if set.
+	Label string
+
+	// Metadata only matches swaps that have all of these metadata pairs.
+	Metadata map[string]string
+}
+
+// Matches returns whether the swap with the given contract passes the filter.
+func (f *SwapFilter) Matches(contract *loopdb.SwapContract) bool {
+	if f.Label != "" && contract.Label != f.Label {
+		return false
+	}
+
+	for key, value := range f.Metadata {
+		swapValue, ok := contract.Metadata[key]
+		if !ok || swapValue != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// labelTx attaches the label of a swap to one of its transactions in the lnd
+// wallet. Failures are only logged, because the label is informational and
+// older lnd versions don't support transaction labels.
+func labelTx(ctx context.Context, walletKit lndclient.WalletKitClient,
+	label string, tx *wire.MsgTx) {
+
+	if label == "" {
+		return
+	}
+
+	txHash := tx.TxHash()
+	err := walletKit.LabelTransaction(ctx, txHash, label)
+	switch {
+	case err == lndclient.ErrTxLabelsUnsupported:
+		log.Debugf("Unable to label tx %v: %v", txHash, err)
+
+	case err != nil:
+		log.Warnf("Unable to label tx %v: %v", txHash, err)
+	}
+}
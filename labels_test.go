@@ -0,0 +1,152 @@
+package loop
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lightninglabs/loop/loopdb"
+)
+
+// TestValidateLabels tests the limits on the label and metadata of a swap.
+func TestValidateLabels(t *testing.T) {
+	tooManyPairs := make(map[string]string)
+	for i := 0; i <= MaxMetadataPairs; i++ {
+		tooManyPairs[strings.Repeat("k", i+1)] = "v"
+	}
+
+	tests := []struct {
+		name     string
+		label    string
+		metadata map[string]string
+		err      error
+	}{
+		{
+			name:     "valid",
+			label:    "treasury",
+			metadata: map[string]string{"unit": "payments"},
+		},
+		{
+			name:  "label too long",
+			label: strings.Repeat("l", MaxLabelLength+1),
+			err:   ErrLabelTooLong,
+		},
+		{
+			name:     "too many pairs",
+			metadata: tooManyPairs,
+			err:      ErrTooManyMetadataPairs,
+		},
+		{
+			name:     "empty key",
+			metadata: map[string]string{"": "payments"},
+			err:      ErrInvalidMetadata,
+		},
+		{
+			name: "value too long",
+			metadata: map[string]string{
+				"unit": strings.Repeat("v", MaxMetadataLength+1),
+			},
+			err: ErrInvalidMetadata,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			err := validateLabels(test.label, test.metadata)
+			if err != test.err {
+				t.Fatalf("expected %v, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+// TestParseMetadata tests parsing of key=value metadata pairs.
+func TestParseMetadata(t *testing.T) {
+	metadata, err := ParseMetadata([]string{"unit=payments", "ref=a=b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(metadata) != 2 || metadata["unit"] != "payments" ||
+		metadata["ref"] != "a=b" {
+
+		t.Fatalf("unexpected metadata %v", metadata)
+	}
+
+	metadata, err = ParseMetadata(nil)
+	if err != nil || metadata != nil {
+		t.Fatalf("expected no metadata, got %v (%v)", metadata, err)
+	}
+
+	invalid := [][]string{
+		{"unit"},
+		{"=payments"},
+		{"unit=payments", "unit=treasury"},
+	}
+	for _, pairs := range invalid {
+		if _, err := ParseMetadata(pairs); err == nil {
+			t.Fatalf("expected %v to be rejected", pairs)
+		}
+	}
+}
+
+// TestSwapFilter tests that swaps are selected by label and metadata.
+func TestSwapFilter(t *testing.T) {
+	contract := &loopdb.SwapContract{
+		Label: "treasury",
+		Metadata: map[string]string{
+			"unit":  "payments",
+			"batch": "7",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		filter  SwapFilter
+		matches bool
+	}{
+		{
+			name:    "empty filter",
+			matches: true,
+		},
+		{
+			name:    "label",
+			filter:  SwapFilter{Label: "treasury"},
+			matches: true,
+		},
+		{
+			name:   "other label",
+			filter: SwapFilter{Label: "payroll"},
+		},
+		{
+			name: "metadata subset",
+			filter: SwapFilter{
+				Label:    "treasury",
+				Metadata: map[string]string{"unit": "payments"},
+			},
+			matches: true,
+		},
+		{
+			name: "other metadata value",
+			filter: SwapFilter{
+				Metadata: map[string]string{"batch": "8"},
+			},
+		},
+		{
+			name: "missing metadata key",
+			filter: SwapFilter{
+				Metadata: map[string]string{"region": "eu"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			if test.filter.Matches(contract) != test.matches {
+				t.Fatalf("expected match %v", test.matches)
+			}
+		})
+	}
+}
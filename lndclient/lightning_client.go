@@ -16,6 +16,7 @@ import (
 	"github.com/lightningnetwork/lnd/lnrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/invoicesrpc"
 	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwire"
 	"github.com/lightningnetwork/lnd/zpay32"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -41,6 +42,57 @@ type LightningClient interface {
 	// ListTransactions returns all known transactions of the backing lnd
 	// node.
 	ListTransactions(ctx context.Context) ([]*wire.MsgTx, error)
+
+	// QueryRoutes returns the best route that lnd finds for the given
+	// payment. If no route is found, ErrNoRoute is returned.
+	QueryRoutes(ctx context.Context, req *QueryRoutesRequest) (*Route,
+		error)
+
+	// ListChannels returns all open channels of the backing lnd node.
+	ListChannels(ctx context.Context) ([]ChannelInfo, error)
+}
+
+// ChannelInfo describes an open channel.
+type ChannelInfo struct {
+	// ChannelID is the short channel id of the channel.
+	ChannelID uint64
+
+	// PubKeyBytes is the public key of the channel peer.
+	PubKeyBytes [33]byte
+
+	// Active indicates whether the channel can be used for payments.
+	Active bool
+
+	// LocalBalance is our balance in the channel.
+	LocalBalance btcutil.Amount
+
+	// Capacity is the total capacity of the channel.
+	Capacity btcutil.Amount
+}
+
+// QueryRoutesRequest describes a payment for which a route is requested.
+type QueryRoutesRequest struct {
+	// PubKey is the destination of the payment.
+	PubKey [33]byte
+
+	// Amount is the amount that the destination should receive.
+	Amount btcutil.Amount
+
+	// OutgoingChannel optionally restricts the first hop of the route to
+	// the peer of the given channel.
+	OutgoingChannel *uint64
+}
+
+// Route describes a route that was found for a payment.
+type Route struct {
+	// TotalFee is the total routing fee of the route.
+	TotalFee btcutil.Amount
+
+	// TotalTimeLock is the absolute time lock of the first htlc.
+	TotalTimeLock uint32
+
+	// NumHops is the number of hops of the route.
+	NumHops int
 }
 
 // Info contains info about the connected lnd node.
@@ -63,6 +115,10 @@ var (
 	// is no route to the server.
 	ErrNoRouteToServer = errors.New("no off-chain route to server")
 
+	// ErrNoRoute is returned by QueryRoutes if lnd can't find a route for
+	// the payment.
+	ErrNoRoute = errors.New("no route found")
+
 	// PaymentResultUnknownPaymentHash is the string result returned by
 	// SendPayment when the final node indicates the hash is unknown.
 	PaymentResultUnknownPaymentHash = "UnknownPaymentHash"
@@ -384,3 +440,155 @@ func (s *lightningClient) ListTransactions(ctx context.Context) ([]*wire.MsgTx,
 
 	return txs, nil
 }
+
+// QueryRoutes returns the best route that lnd finds for the given payment. If
+// an outgoing channel is given, all peers other than the channel peer are
+// excluded as first hop. lnd doesn't allow restricting the route to a
+// specific channel, so any channel with that peer may be used.
+func (s *lightningClient) QueryRoutes(ctx context.Context,
+	req *QueryRoutesRequest) (*Route, error) {
+
+	rpcCtx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+
+	rpcCtx = s.adminMac.WithMacaroonAuth(rpcCtx)
+
+	rpcReq := &lnrpc.QueryRoutesRequest{
+		PubKey:            hex.EncodeToString(req.PubKey[:]),
+		Amt:               int64(req.Amount),
+		UseMissionControl: true,
+	}
+
+	if req.OutgoingChannel != nil {
+		ignoredPairs, err := s.ignoredFirstHops(
+			rpcCtx, *req.OutgoingChannel,
+		)
+		if err != nil {
+			return nil, err
+		}
+		rpcReq.IgnoredPairs = ignoredPairs
+	}
+
+	resp, err := s.client.QueryRoutes(rpcCtx, rpcReq)
+	if err != nil {
+		// lnd returns path finding failures as plain errors, which
+		// arrive with the Unknown code. Failures to reach lnd or to
+		// authenticate have their own codes and are returned as is.
+		switch status.Code(err) {
+		case codes.Unknown, codes.NotFound:
+			return nil, ErrNoRoute
+		}
+
+		return nil, err
+	}
+
+	if len(resp.Routes) == 0 {
+		return nil, ErrNoRoute
+	}
+
+	route := resp.Routes[0]
+
+	return &Route{
+		TotalFee: lnwire.MilliSatoshi(
+			route.TotalFeesMsat,
+		).ToSatoshis(),
+		TotalTimeLock: route.TotalTimeLock,
+		NumHops:       len(route.Hops),
+	}, nil
+}
+
+// ignoredFirstHops returns the node pairs from our node to all peers except
+// the peer of the given channel.
+func (s *lightningClient) ignoredFirstHops(ctx context.Context,
+	channel uint64) ([]*lnrpc.NodePair, error) {
+
+	info, err := s.client.GetInfo(ctx, &lnrpc.GetInfoRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	self, err := hex.DecodeString(info.IdentityPubkey)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, err := s.client.ListChannels(
+		ctx, &lnrpc.ListChannelsRequest{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var channelPeer string
+	for _, c := range channels.Channels {
+		if c.ChanId == channel {
+			channelPeer = c.RemotePubkey
+			break
+		}
+	}
+	if channelPeer == "" {
+		return nil, fmt.Errorf("channel %v not found", channel)
+	}
+
+	var (
+		pairs []*lnrpc.NodePair
+		seen  = make(map[string]struct{})
+	)
+	for _, c := range channels.Channels {
+		if c.RemotePubkey == channelPeer {
+			continue
+		}
+		if _, ok := seen[c.RemotePubkey]; ok {
+			continue
+		}
+		seen[c.RemotePubkey] = struct{}{}
+
+		peer, err := hex.DecodeString(c.RemotePubkey)
+		if err != nil {
+			return nil, err
+		}
+
+		pairs = append(pairs, &lnrpc.NodePair{
+			From: self,
+			To:   peer,
+		})
+	}
+
+	return pairs, nil
+}
+
+// ListChannels returns all open channels of the backing lnd node.
+func (s *lightningClient) ListChannels(ctx context.Context) ([]ChannelInfo,
+	error) {
+
+	rpcCtx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+
+	rpcCtx = s.adminMac.WithMacaroonAuth(rpcCtx)
+	resp, err := s.client.ListChannels(
+		rpcCtx, &lnrpc.ListChannelsRequest{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := make([]ChannelInfo, 0, len(resp.Channels))
+	for _, c := range resp.Channels {
+		pubKey, err := hex.DecodeString(c.RemotePubkey)
+		if err != nil {
+			return nil, err
+		}
+
+		channel := ChannelInfo{
+			ChannelID:    c.ChanId,
+			Active:       c.Active,
+			LocalBalance: btcutil.Amount(c.LocalBalance),
+			Capacity:     btcutil.Amount(c.Capacity),
+		}
+		copy(channel.PubKeyBytes[:], pubKey)
+
+		channels = append(channels, channel)
+	}
+
+	return channels, nil
+}
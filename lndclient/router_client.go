@@ -38,6 +38,10 @@ type PaymentStatus struct {
 	Preimage lntypes.Preimage
 	Fee      lnwire.MilliSatoshi
 	Route    *route.Route
+
+	// FirstHops are the outgoing channels of the htlcs that lnd sent in
+	// attempt to complete the payment.
+	FirstHops []uint64
 }
 
 // SendPaymentRequest defines the payment parameters for a new payment.
@@ -208,6 +212,16 @@ func unmarshallPaymentStatus(rpcStatus *routerrpc.PaymentStatus) (
 		State: rpcStatus.State,
 	}
 
+	for _, htlc := range rpcStatus.Htlcs {
+		if htlc.Route == nil || len(htlc.Route.Hops) == 0 {
+			continue
+		}
+
+		status.FirstHops = append(
+			status.FirstHops, htlc.Route.Hops[0].ChanId,
+		)
+	}
+
 	if status.State == routerrpc.PaymentState_SUCCEEDED {
 		preimage, err := lntypes.MakePreimage(
 			rpcStatus.Preimage,
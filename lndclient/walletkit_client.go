@@ -2,16 +2,21 @@ package lndclient
 
 import (
 	"context"
+	"errors"
 
 	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/golang/protobuf/proto"
 	"github.com/lightninglabs/loop/swap"
 	"github.com/lightningnetwork/lnd/keychain"
 	"github.com/lightningnetwork/lnd/lnrpc/signrpc"
 	"github.com/lightningnetwork/lnd/lnrpc/walletrpc"
 	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // WalletKitClient exposes wallet functionality.
@@ -31,10 +36,28 @@ type WalletKitClient interface {
 
 	EstimateFee(ctx context.Context, confTarget int32) (chainfee.SatPerKWeight,
 		error)
+
+	// LabelTransaction attaches a label to a wallet transaction. It
+	// returns ErrTxLabelsUnsupported if lnd doesn't support labels.
+	LabelTransaction(ctx context.Context, txid chainhash.Hash,
+		label string) error
 }
 
+var (
+	// ErrTxLabelsUnsupported is returned when the connected lnd doesn't
+	// support transaction labels.
+	ErrTxLabelsUnsupported = errors.New("lnd doesn't support " +
+		"transaction labels")
+)
+
+// labelTransactionMethod is the lnd rpc method that labels a transaction.
+// The lnd version that loop builds against doesn't have bindings for it, so
+// it is invoked on the connection directly.
+const labelTransactionMethod = "/walletrpc.WalletKit/LabelTransaction"
+
 type walletKitClient struct {
 	client       walletrpc.WalletKitClient
+	conn         *grpc.ClientConn
 	walletKitMac serializedMacaroon
 }
 
@@ -43,6 +66,7 @@ func newWalletKitClient(conn *grpc.ClientConn,
 
 	return &walletKitClient{
 		client:       walletrpc.NewWalletKitClient(conn),
+		conn:         conn,
 		walletKitMac: walletKitMac,
 	}
 }
@@ -188,3 +212,46 @@ func (m *walletKitClient) EstimateFee(ctx context.Context, confTarget int32) (
 
 	return chainfee.SatPerKWeight(resp.SatPerKw), nil
 }
+
+// labelTransactionRequest is the request of the lnd LabelTransaction rpc.
+type labelTransactionRequest struct {
+	Txid      []byte `protobuf:"bytes,1,opt,name=txid,proto3"`
+	Label     string `protobuf:"bytes,2,opt,name=label,proto3"`
+	Overwrite bool   `protobuf:"varint,3,opt,name=overwrite,proto3"`
+}
+
+func (m *labelTransactionRequest) Reset()         { *m = labelTransactionRequest{} }
+func (m *labelTransactionRequest) String() string { return proto.CompactTextString(m) }
+func (*labelTransactionRequest) ProtoMessage()    {}
+
+// labelTransactionResponse is the empty response of the lnd LabelTransaction
+// rpc.
+type labelTransactionResponse struct{}
+
+func (m *labelTransactionResponse) Reset()         { *m = labelTransactionResponse{} }
+func (m *labelTransactionResponse) String() string { return proto.CompactTextString(m) }
+func (*labelTransactionResponse) ProtoMessage()    {}
+
+// LabelTransaction attaches a label to a wallet transaction, replacing any
+// existing label. It returns ErrTxLabelsUnsupported if lnd doesn't support
+// labels.
+func (m *walletKitClient) LabelTransaction(ctx context.Context,
+	txid chainhash.Hash, label string) error {
+
+	rpcCtx, cancel := context.WithTimeout(ctx, rpcTimeout)
+	defer cancel()
+
+	rpcCtx = m.walletKitMac.WithMacaroonAuth(rpcCtx)
+	err := m.conn.Invoke(
+		rpcCtx, labelTransactionMethod, &labelTransactionRequest{
+			Txid:      txid[:],
+			Label:     label,
+			Overwrite: true,
+		}, &labelTransactionResponse{},
+	)
+	if status.Code(err) == codes.Unimplemented {
+		return ErrTxLabelsUnsupported
+	}
+
+	return err
+}
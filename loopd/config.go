@@ -2,8 +2,10 @@ package loopd
 
 import (
 	"path/filepath"
+	"time"
 
 	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop"
 	"github.com/lightninglabs/loop/lsat"
 )
 
@@ -25,7 +27,10 @@ type lndConfig struct {
 	TLSPath     string `long:"tlspath" description:"Path to lnd tls certificate"`
 }
 
-type viewParameters struct{}
+type viewParameters struct {
+	Label    string   `long:"label" description:"Only show swaps with this label"`
+	Metadata []string `long:"metadata" description:"Only show swaps with this metadata pair in the form key=value. May be specified multiple times."`
+}
 
 type config struct {
 	ShowVersion    bool   `short:"V" long:"version" description:"Display version information and exit"`
@@ -44,6 +49,18 @@ type config struct {
 	MaxLSATCost uint32 `long:"maxlsatcost" description:"Maximum cost in satoshis that loopd is going to pay for an LSAT token automatically. Does not include routing fees."`
 	MaxLSATFee  uint32 `long:"maxlsatfee" description:"Maximum routing fee in satoshis that we are willing to pay while paying for an LSAT token."`
 
+	PaymentMaxAttempts  int           `long:"paymentmaxattempts" description:"Maximum number of attempts for each of the off-chain payments of a loop out. Set to 1 to disable retries."`
+	PaymentRetryBackoff time.Duration `long:"paymentretrybackoff" description:"Delay before the first retry of a failed loop out payment. The delay doubles for every following retry."`
+	PaymentMaxBackoff   time.Duration `long:"paymentmaxbackoff" description:"Maximum delay between two attempts of a loop out payment. Set to 0 to not limit the delay."`
+	PaymentExpiryDelta  int32         `long:"paymentexpirydelta" description:"Minimum number of blocks that must be left until the latest preimage reveal height of a loop out for a failed payment to be retried."`
+
+	DestAllowList []string `long:"destallowlist" description:"Address or xpub/output descriptor that loop out funds may be sent to. May be specified multiple times. If set, loop outs to any other destination are rejected."`
+
+	ExtraSwapServers []string `long:"extraswapserver" description:"Additional swap server in the form id@host:port, optionally followed by ,tlspath=<path> or ,insecure. May be specified multiple times. Quotes are requested from all servers and swaps use the cheapest one unless a server is selected."`
+
+	Proxy           string `long:"proxy" description:"Connect to the swap servers through the SOCKS5 proxy at host:port, such as the SOCKS port of Tor. Required for onion server addresses."`
+	StreamIsolation bool   `long:"streamisolation" description:"Use a separate Tor circuit for every connection to the swap servers. Requires --proxy."`
+
 	Lnd *lndConfig `group:"lnd" namespace:"lnd"`
 
 	View viewParameters `command:"view" alias:"v" description:"View all swaps in the database. This command can only be executed when loopd is not running."`
@@ -52,6 +69,11 @@ type config struct {
 const (
 	mainnetServer = "swap.lightning.today:11009"
 	testnetServer = "test.swap.lightning.today:11009"
+
+	// defaultServerID is the id of the server that is set with
+	// --swapserver. It executes the swaps that were created before
+	// multiple servers were supported.
+	defaultServerID = "default"
 )
 
 var defaultConfig = config{
@@ -65,6 +87,12 @@ var defaultConfig = config{
 	DebugLevel:     defaultLogLevel,
 	MaxLSATCost:    lsat.DefaultMaxCostSats,
 	MaxLSATFee:     lsat.DefaultMaxRoutingFeeSats,
+
+	PaymentMaxAttempts:  loop.DefaultPaymentRetryPolicy.MaxAttempts,
+	PaymentRetryBackoff: loop.DefaultPaymentRetryPolicy.Backoff,
+	PaymentMaxBackoff:   loop.DefaultPaymentRetryPolicy.MaxBackoff,
+	PaymentExpiryDelta:  loop.DefaultPaymentRetryPolicy.ExpiryDelta,
+
 	Lnd: &lndConfig{
 		Host: "localhost:10009",
 	},
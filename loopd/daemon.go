@@ -54,6 +54,14 @@ func daemon(config *config, lisCfg *listenerCfg) error {
 	}
 
 	log.Infof("Swap server address: %v", config.SwapServer)
+	for _, server := range config.ExtraSwapServers {
+		log.Infof("Additional swap server: %v", server)
+	}
+	if config.Proxy != "" {
+		log.Infof("Connecting to swap servers through proxy %v "+
+			"(stream isolation: %v)", config.Proxy,
+			config.StreamIsolation)
+	}
 
 	// Create an instance of the loop client library.
 	swapClient, cleanup, err := getClient(config, &lnd.LndServices)
@@ -131,6 +139,7 @@ func daemon(config *config, lisCfg *listenerCfg) error {
 	}
 
 	statusChan := make(chan loop.SwapInfo)
+	serverUpdateChan := make(chan loop.ServerUpdate)
 
 	mainCtx, cancel := context.WithCancel(context.Background())
 	var wg sync.WaitGroup
@@ -141,7 +150,7 @@ func daemon(config *config, lisCfg *listenerCfg) error {
 		defer wg.Done()
 
 		log.Infof("Starting swap client")
-		err := swapClient.Run(mainCtx, statusChan)
+		err := swapClient.Run(mainCtx, statusChan, serverUpdateChan)
 		if err != nil {
 			log.Error(err)
 		}
@@ -158,22 +167,65 @@ func daemon(config *config, lisCfg *listenerCfg) error {
 	go func() {
 		defer wg.Done()
 
+		// broadcast stores the swap and sends it to all subscribers.
+		// The caller must hold the swaps lock.
+		broadcast := func(swap loop.SwapInfo) bool {
+			swaps[swap.SwapHash] = swap
+
+			for _, subscriber := range subscribers {
+				select {
+				case subscriber <- swap:
+				case <-mainCtx.Done():
+					return false
+				}
+			}
+
+			return true
+		}
+
 		log.Infof("Waiting for updates")
 		for {
 			select {
 			case swap := <-statusChan:
 				swapsLock.Lock()
-				swaps[swap.SwapHash] = swap
-
-				for _, subscriber := range subscribers {
-					select {
-					case subscriber <- swap:
-					case <-mainCtx.Done():
-						return
-					}
+
+				// Updates of the swap itself don't carry the
+				// server status, so we keep the last one.
+				if swap.ServerUpdate == nil {
+					prev := swaps[swap.SwapHash]
+					swap.ServerUpdate = prev.ServerUpdate
 				}
 
+				ok := broadcast(swap)
 				swapsLock.Unlock()
+				if !ok {
+					return
+				}
+
+			case update := <-serverUpdateChan:
+				if update.TermsUpdate != nil {
+					logTermsUpdate(
+						update.ServerID,
+						update.TermsUpdate,
+					)
+					continue
+				}
+
+				swapsLock.Lock()
+				swap, known := swaps[update.SwapHash]
+				if !known {
+					swapsLock.Unlock()
+					continue
+				}
+
+				swap.ServerUpdate = update.SwapUpdate
+
+				ok := broadcast(swap)
+				swapsLock.Unlock()
+				if !ok {
+					return
+				}
+
 			case <-mainCtx.Done():
 				return
 			}
@@ -220,3 +272,23 @@ func daemon(config *config, lisCfg *listenerCfg) error {
 
 	return nil
 }
+
+// logTermsUpdate logs a change of the server terms or fees.
+func logTermsUpdate(serverID string, update *loop.TermsUpdate) {
+	if update.LoopOutTerms != nil {
+		log.Infof("Server %v changed loop out terms: min %v, max %v",
+			serverID, update.LoopOutTerms.MinSwapAmount,
+			update.LoopOutTerms.MaxSwapAmount)
+	}
+
+	if update.LoopInTerms != nil {
+		log.Infof("Server %v changed loop in terms: min %v, max %v",
+			serverID, update.LoopInTerms.MinSwapAmount,
+			update.LoopInTerms.MaxSwapAmount)
+	}
+
+	if update.FeesChanged {
+		log.Infof("Server %v changed its swap fees, previous quotes "+
+			"are no longer valid", serverID)
+	}
+}
@@ -2,11 +2,15 @@ package loopd
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"sort"
 	"time"
 
+	"github.com/golang/protobuf/proto"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/lightningnetwork/lnd/queue"
 
 	"github.com/lightninglabs/loop"
@@ -18,6 +22,22 @@ import (
 	"github.com/lightninglabs/loop/looprpc"
 )
 
+var (
+	// errBudgetWithLimits is returned when a swap request specifies both a
+	// total cost budget and individual limits.
+	errBudgetWithLimits = errors.New("a total cost budget cannot be " +
+		"combined with individual limits or a quote id")
+
+	// errScheduledUnsupported is returned when a scheduled or recurring
+	// swap uses request options that only apply to swaps that are
+	// initiated right away.
+	errScheduledUnsupported = errors.New("split, quote_id, probe, " +
+		"swap_publication_deadline, the sweep fee rate ceiling, " +
+		"sweep outputs, the refund policy, native segwit htlcs, " +
+		"idempotency keys, labels and metadata are not supported " +
+		"for scheduled or recurring swaps")
+)
+
 const (
 	completedSwapsCount = 5
 
@@ -43,6 +63,54 @@ func (s *swapClientServer) LoopOut(ctx context.Context,
 
 	log.Infof("Loop out request received")
 
+	// A retried request returns the swap that it started before, without
+	// deriving a new address or requesting a new quote.
+	if in.IdempotencyKey != "" && !in.Split {
+		params := proto.Clone(in).(*looprpc.LoopOutRequest)
+		params.IdempotencyKey = ""
+
+		resp, err := s.idempotentSwap(
+			swap.TypeOut, in.IdempotencyKey, params,
+		)
+		if err != nil || resp != nil {
+			return resp, err
+		}
+	}
+
+	req, err := s.loopOutRequest(ctx, in, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Split {
+		groupID, parts, err := s.impl.LoopOutSplit(ctx, req)
+		if err != nil {
+			log.Errorf("LoopOut split: %v", err)
+			return nil, err
+		}
+
+		return marshallGroupResponse(groupID, parts), nil
+	}
+
+	hash, htlc, err := s.impl.LoopOut(ctx, req)
+	if err != nil {
+		log.Errorf("LoopOut: %v", err)
+		return nil, err
+	}
+
+	return &looprpc.SwapResponse{
+		Id:          hash.String(),
+		HtlcAddress: htlc.String(),
+	}, nil
+}
+
+// loopOutRequest converts an rpc loop out request into a client request. If a
+// total cost budget is given, the limits are derived from a new quote. Deferred
+// requests without a destination are left without one, so that every swap
+// obtains a fresh address when it is initiated.
+func (s *swapClientServer) loopOutRequest(ctx context.Context,
+	in *looprpc.LoopOutRequest, deferred bool) (*loop.OutRequest, error) {
+
 	sweepConfTarget, err := validateConfTarget(
 		in.SweepConfTarget, loop.DefaultSweepConfTarget,
 	)
@@ -50,15 +118,34 @@ func (s *swapClientServer) LoopOut(ctx context.Context,
 		return nil, err
 	}
 
+	sweepOutputs, err := s.sweepOutputs(in.SweepOutputs)
+	if err != nil {
+		return nil, err
+	}
+
 	var sweepAddr btcutil.Address
-	if in.Dest == "" {
-		// Generate sweep address if none specified.
+	switch {
+	case len(sweepOutputs) != 0:
+		// With explicit sweep outputs, the destination address is the
+		// address of the first output.
+		sweepAddr = sweepOutputs[0].Addr
+		if in.Dest != "" && in.Dest != sweepAddr.String() {
+			return nil, loop.ErrSweepOutputDestMismatch
+		}
+
+	case in.Dest == "" && deferred:
+
+	case in.Dest == "":
+		// Generate sweep address if none specified. It is derived
+		// from the registered destination descriptor or otherwise
+		// taken from the lnd wallet.
 		var err error
-		sweepAddr, err = s.lnd.WalletKit.NextAddr(context.Background())
+		sweepAddr, err = s.impl.NextDestAddr(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("NextAddr error: %v", err)
+			return nil, fmt.Errorf("NextDestAddr error: %v", err)
 		}
-	} else {
+
+	default:
 		var err error
 		sweepAddr, err = btcutil.DecodeAddress(
 			in.Dest, s.lnd.ChainParams,
@@ -80,20 +167,96 @@ func (s *swapClientServer) LoopOut(ctx context.Context,
 		SwapPublicationDeadline: time.Unix(
 			int64(in.SwapPublicationDeadline), 0,
 		),
+		QuoteID:      in.QuoteId,
+		ProbeRoute:   in.Probe,
+		SweepOutputs: sweepOutputs,
+		ServerID:     in.ServerId,
+		Label:        in.Label,
+		Metadata:     in.Metadata,
 	}
 	if in.LoopOutChannel != 0 {
 		req.LoopOutChannel = &in.LoopOutChannel
 	}
-	hash, htlc, err := s.impl.LoopOut(ctx, req)
+
+	// The digest of a keyed request covers the request as it was sent,
+	// before any limits are derived from a budget.
+	if in.IdempotencyKey != "" {
+		params := proto.Clone(in).(*looprpc.LoopOutRequest)
+		params.IdempotencyKey = ""
+
+		req.IdempotencyKey = in.IdempotencyKey
+		req.RequestDigest, err = requestDigest(params)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case in.SweepSafetyMargin < 0:
+		return nil, errors.New("sweep_safety_margin must not be " +
+			"negative")
+
+	case in.SweepSafetyMargin != 0 &&
+		in.SweepFeeRateCeilingSatPerVbyte == 0:
+
+		return nil, errors.New("sweep_safety_margin requires a " +
+			"sweep fee rate ceiling")
+	}
+	if in.SweepFeeRateCeilingSatPerVbyte != 0 {
+		req.SweepFeeRateCeiling = chainfee.SatPerKVByte(
+			in.SweepFeeRateCeilingSatPerVbyte * 1000,
+		).FeePerKWeight()
+		req.SweepSafetyMargin = in.SweepSafetyMargin
+	}
+
+	budget, err := getBudget(
+		req.Amount, in.MaxTotalCost, in.MaxTotalCostPpm,
+	)
 	if err != nil {
-		log.Errorf("LoopOut: %v", err)
 		return nil, err
 	}
 
-	return &looprpc.SwapResponse{
-		Id:          hash.String(),
-		HtlcAddress: htlc.String(),
-	}, nil
+	// If a total budget is given, we obtain a quote and derive all limits
+	// from it.
+	if budget != 0 {
+		if in.MaxSwapRoutingFee != 0 || in.MaxPrepayRoutingFee != 0 ||
+			in.MaxSwapFee != 0 || in.MaxPrepayAmt != 0 ||
+			in.MaxMinerFee != 0 || len(in.QuoteId) != 0 {
+
+			return nil, errBudgetWithLimits
+		}
+
+		quoteReq := &loop.LoopOutQuoteRequest{
+			Amount:                  req.Amount,
+			SweepConfTarget:         req.SweepConfTarget,
+			SwapPublicationDeadline: req.SwapPublicationDeadline,
+			Split:                   in.Split,
+			LoopOutChannel:          req.LoopOutChannel,
+			SweepOutputs:            len(sweepOutputs),
+			ServerID:                req.ServerID,
+		}
+		quote, err := s.impl.LoopOutQuote(ctx, quoteReq)
+		if err != nil {
+			return nil, err
+		}
+
+		err = loop.ApplyLoopOutBudget(req, budget, quote)
+		if err != nil {
+			return nil, err
+		}
+		req.QuoteID = quote.QuoteID
+
+		// The limits only apply to the server that issued the quote.
+		req.ServerID = quote.ServerID
+
+		log.Infof("Loop out budget %v: max swap fee %v, max prepay "+
+			"%v, max miner fee %v, max swap routing fee %v, max "+
+			"prepay routing fee %v", budget, req.MaxSwapFee,
+			req.MaxPrepayAmount, req.MaxMinerFee,
+			req.MaxSwapRoutingFee, req.MaxPrepayRoutingFee)
+	}
+
+	return req, nil
 }
 
 func (s *swapClientServer) marshallSwap(loopSwap *loop.SwapInfo) (
@@ -126,6 +289,28 @@ func (s *swapClientServer) marshallSwap(loopSwap *loop.SwapInfo) (
 		return nil, errors.New("unknown swap type")
 	}
 
+	var paymentFailure *looprpc.PaymentFailure
+	if loopSwap.PaymentFailure != nil {
+		paymentFailure = marshallPaymentFailure(loopSwap.PaymentFailure)
+	}
+
+	var sweepStrategy *looprpc.SweepStrategy
+	if loopSwap.SweepStrategy != nil {
+		sweepStrategy = marshallSweepStrategy(loopSwap.SweepStrategy)
+	}
+
+	var refundTxid string
+	if loopSwap.RefundTxHash != nil {
+		refundTxid = loopSwap.RefundTxHash.String()
+	}
+
+	deposits := make([]*looprpc.HtlcDeposit, 0, len(loopSwap.Deposits))
+	for i := range loopSwap.Deposits {
+		deposits = append(
+			deposits, marshallDeposit(&loopSwap.Deposits[i]),
+		)
+	}
+
 	return &looprpc.SwapStatus{
 		Amt:            int64(loopSwap.AmountRequested),
 		Id:             loopSwap.SwapHash.String(),
@@ -137,16 +322,772 @@ func (s *swapClientServer) marshallSwap(loopSwap *loop.SwapInfo) (
 		CostServer:     int64(loopSwap.Cost.Server),
 		CostOnchain:    int64(loopSwap.Cost.Onchain),
 		CostOffchain:   int64(loopSwap.Cost.Offchain),
+		PaymentFailure: paymentFailure,
+		SweepStrategy:  sweepStrategy,
+		RefundTxid:     refundTxid,
+		Deposits:       deposits,
+		ServerStatus:   marshallServerUpdate(loopSwap.ServerUpdate),
+		ServerId:       loopSwap.ServerID,
+		Label:          loopSwap.Label,
+		Metadata:       loopSwap.Metadata,
+	}, nil
+}
+
+// marshallServerUpdate converts the last server update of a swap into its rpc
+// representation. It returns nil if the server didn't push any update.
+func marshallServerUpdate(
+	update *loopdb.ServerSwapUpdate) *looprpc.ServerSwapStatus {
+
+	if update == nil {
+		return nil
+	}
+
+	var status looprpc.ServerStatus
+	switch update.State {
+	case loopdb.ServerHtlcDelayed:
+		status = looprpc.ServerStatus_SERVER_STATUS_HTLC_DELAYED
+	case loopdb.ServerHtlcPublished:
+		status = looprpc.ServerStatus_SERVER_STATUS_HTLC_PUBLISHED
+	case loopdb.ServerSwapRejected:
+		status = looprpc.ServerStatus_SERVER_STATUS_REJECTED
+	default:
+		status = looprpc.ServerStatus_SERVER_STATUS_ACCEPTED
+	}
+
+	var expectedPublication int64
+	if !update.ExpectedPublication.IsZero() {
+		expectedPublication = update.ExpectedPublication.Unix()
+	}
+
+	return &looprpc.ServerSwapStatus{
+		Status:              status,
+		Reason:              update.Reason,
+		ExpectedPublication: expectedPublication,
+		UpdateTime:          update.Time.UnixNano(),
+	}
+}
+
+// marshallDeposit converts an htlc deposit of a loop in swap into its rpc
+// representation.
+func marshallDeposit(deposit *loopdb.LoopInDeposit) *looprpc.HtlcDeposit {
+	var state looprpc.DepositState
+	switch deposit.State {
+	case loopdb.DepositSwept:
+		state = looprpc.DepositState_DEPOSIT_SWEPT
+	case loopdb.DepositRefunded:
+		state = looprpc.DepositState_DEPOSIT_REFUNDED
+	default:
+		state = looprpc.DepositState_DEPOSIT_CONFIRMED
+	}
+
+	var spendTxid string
+	if deposit.SpendTxHash != nil {
+		spendTxid = deposit.SpendTxHash.String()
+	}
+
+	return &looprpc.HtlcDeposit{
+		Outpoint:  deposit.Outpoint.String(),
+		Amt:       int64(deposit.Value),
+		Accepted:  deposit.Accepted,
+		State:     state,
+		SpendTxid: spendTxid,
+	}
+}
+
+// marshallSweepStrategy converts the sweep strategy of a loop out swap into
+// its rpc representation.
+func marshallSweepStrategy(
+	strategy *loop.SweepStrategy) *looprpc.SweepStrategy {
+
+	var status looprpc.SweepStatus
+	switch strategy.Status {
+	case loop.SweepStatusWaiting:
+		status = looprpc.SweepStatus_SWEEP_WAITING
+	case loop.SweepStatusPublished:
+		status = looprpc.SweepStatus_SWEEP_PUBLISHED
+	case loop.SweepStatusEscalated:
+		status = looprpc.SweepStatus_SWEEP_ESCALATED
+	default:
+		status = looprpc.SweepStatus_SWEEP_NONE
+	}
+
+	return &looprpc.SweepStrategy{
+		ConfTarget: strategy.ConfTarget,
+		FeeRateCeilingSatPerVbyte: uint64(
+			strategy.FeeRateCeiling.FeePerKVByte() / 1000,
+		),
+		SafetyMargin: strategy.SafetyMargin,
+		Status:       status,
+	}
+}
+
+// marshallPaymentFailure converts a stored payment failure to its rpc
+// representation.
+func marshallPaymentFailure(
+	failure *loopdb.PaymentFailure) *looprpc.PaymentFailure {
+
+	payment := looprpc.PaymentType_SWAP_PAYMENT
+	if failure.Payment == loopdb.PaymentTypePrepay {
+		payment = looprpc.PaymentType_PREPAYMENT
+	}
+
+	var reason looprpc.PaymentFailureReason
+	switch failure.Reason {
+	case loopdb.FailureReasonTimeout:
+		reason = looprpc.PaymentFailureReason_FAILURE_REASON_TIMEOUT
+
+	case loopdb.FailureReasonNoRoute:
+		reason = looprpc.PaymentFailureReason_FAILURE_REASON_NO_ROUTE
+
+	case loopdb.FailureReasonError:
+		reason = looprpc.PaymentFailureReason_FAILURE_REASON_ERROR
+
+	case loopdb.FailureReasonIncorrectPaymentDetails:
+		reason = looprpc.
+			PaymentFailureReason_FAILURE_REASON_INCORRECT_PAYMENT_DETAILS
+
+	case loopdb.FailureReasonInsufficientBalance:
+		reason = looprpc.
+			PaymentFailureReason_FAILURE_REASON_INSUFFICIENT_BALANCE
+
+	default:
+		reason = looprpc.PaymentFailureReason_FAILURE_REASON_UNKNOWN
+	}
+
+	return &looprpc.PaymentFailure{
+		Payment: payment,
+		Reason:  reason,
+	}
+}
+
+// marshallGroupResponse returns the rpc response for a split swap request. The
+// id and htlc address of the response refer to the first swap of the group.
+func marshallGroupResponse(groupID *loopdb.GroupID,
+	parts []*loop.SwapGroupPart) *looprpc.SwapResponse {
+
+	resp := &looprpc.SwapResponse{
+		GroupId: groupID.String(),
+		Parts:   make([]*looprpc.SwapGroupPart, len(parts)),
+	}
+
+	for i, part := range parts {
+		resp.Parts[i] = &looprpc.SwapGroupPart{
+			Id:          part.Hash.String(),
+			HtlcAddress: part.HtlcAddress.String(),
+			Amt:         int64(part.Amount),
+		}
+	}
+
+	if len(parts) > 0 {
+		resp.Id = resp.Parts[0].Id
+		resp.HtlcAddress = resp.Parts[0].HtlcAddress
+	}
+
+	return resp
+}
+
+// ListSwapGroups returns all swap groups along with their combined state and
+// cost.
+func (s *swapClientServer) ListSwapGroups(ctx context.Context,
+	_ *looprpc.ListSwapGroupsRequest) (*looprpc.ListSwapGroupsResponse,
+	error) {
+
+	log.Infof("List swap groups request received")
+
+	groups, err := s.impl.FetchSwapGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	// Return groups new to old.
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].InitiationTime.After(
+			groups[j].InitiationTime,
+		)
+	})
+
+	rpcGroups := make([]*looprpc.SwapGroup, 0, len(groups))
+	for _, group := range groups {
+		var state looprpc.SwapGroupState
+		switch group.State {
+		case loop.GroupStatePending:
+			state = looprpc.SwapGroupState_GROUP_PENDING
+		case loop.GroupStateSuccess:
+			state = looprpc.SwapGroupState_GROUP_SUCCESS
+		case loop.GroupStateFailed:
+			state = looprpc.SwapGroupState_GROUP_FAILED
+		case loop.GroupStatePartial:
+			state = looprpc.SwapGroupState_GROUP_PARTIAL
+		default:
+			return nil, errors.New("unknown group state")
+		}
+
+		var swapType looprpc.SwapType
+		switch group.Type {
+		case swap.TypeIn:
+			swapType = looprpc.SwapType_LOOP_IN
+		case swap.TypeOut:
+			swapType = looprpc.SwapType_LOOP_OUT
+		default:
+			return nil, errors.New("unknown swap type")
+		}
+
+		rpcSwaps := make([]*looprpc.SwapStatus, 0, len(group.Swaps))
+		for _, swp := range group.Swaps {
+			rpcSwap, err := s.marshallSwap(swp)
+			if err != nil {
+				return nil, err
+			}
+			rpcSwaps = append(rpcSwaps, rpcSwap)
+		}
+
+		rpcGroups = append(rpcGroups, &looprpc.SwapGroup{
+			Id:             group.ID.String(),
+			Type:           swapType,
+			State:          state,
+			Amt:            int64(group.AmountRequested),
+			AmtSwapped:     int64(group.AmountSwapped),
+			InitiationTime: group.InitiationTime.UnixNano(),
+			CostServer:     int64(group.Cost.Server),
+			CostOnchain:    int64(group.Cost.Onchain),
+			CostOffchain:   int64(group.Cost.Offchain),
+			Swaps:          rpcSwaps,
+		})
+	}
+
+	return &looprpc.ListSwapGroupsResponse{Groups: rpcGroups}, nil
+}
+
+// ScheduleSwap persists a swap intent that initiates a loop out or loop in
+// swap once its trigger conditions are met.
+func (s *swapClientServer) ScheduleSwap(ctx context.Context,
+	in *looprpc.ScheduleSwapRequest) (*looprpc.SwapIntent, error) {
+
+	log.Infof("Schedule swap request received")
+
+	trigger, err := unmarshallTrigger(in.Trigger)
+	if err != nil {
+		return nil, err
+	}
+
+	var intent *loopdb.SwapIntent
+	switch {
+	case in.LoopOut != nil && in.LoopIn != nil:
+		return nil, errors.New("loop_out and loop_in cannot both be " +
+			"set")
+
+	case in.LoopOut != nil:
+		if err := checkDeferredLoopOut(in.LoopOut); err != nil {
+			return nil, err
+		}
+
+		req, err := s.loopOutRequest(ctx, in.LoopOut, true)
+		if err != nil {
+			return nil, err
+		}
+
+		intent, err = s.impl.ScheduleLoopOut(req, *trigger)
+		if err != nil {
+			return nil, err
+		}
+
+	case in.LoopIn != nil:
+		if err := checkDeferredLoopIn(in.LoopIn); err != nil {
+			return nil, err
+		}
+
+		req, err := s.loopInRequest(ctx, in.LoopIn)
+		if err != nil {
+			return nil, err
+		}
+
+		intent, err = s.impl.ScheduleLoopIn(req, *trigger)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, errors.New("either loop_out or loop_in must be set")
+	}
+
+	return marshallSwapIntent(intent), nil
+}
+
+// ListSwapIntents returns all swap intents.
+func (s *swapClientServer) ListSwapIntents(ctx context.Context,
+	_ *looprpc.ListSwapIntentsRequest) (*looprpc.ListSwapIntentsResponse,
+	error) {
+
+	log.Infof("List swap intents request received")
+
+	intents, err := s.impl.FetchSwapIntents()
+	if err != nil {
+		return nil, err
+	}
+
+	// Show the most recent intents last, in line with the swap listings.
+	sort.Slice(intents, func(i, j int) bool {
+		return intents[i].CreationTime.Before(intents[j].CreationTime)
+	})
+
+	rpcIntents := make([]*looprpc.SwapIntent, 0, len(intents))
+	for _, intent := range intents {
+		rpcIntents = append(rpcIntents, marshallSwapIntent(intent))
+	}
+
+	return &looprpc.ListSwapIntentsResponse{Intents: rpcIntents}, nil
+}
+
+// CancelSwapIntent cancels a pending swap intent.
+func (s *swapClientServer) CancelSwapIntent(ctx context.Context,
+	in *looprpc.CancelSwapIntentRequest) (*looprpc.SwapIntent, error) {
+
+	log.Infof("Cancel swap intent request received")
+
+	idBytes, err := hex.DecodeString(in.Id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid intent id: %v", err)
+	}
+
+	var id loopdb.IntentID
+	if len(idBytes) != len(id) {
+		return nil, fmt.Errorf("intent id must be %v bytes", len(id))
+	}
+	copy(id[:], idBytes)
+
+	intent, err := s.impl.CancelSwapIntent(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshallSwapIntent(intent), nil
+}
+
+// sweepOutputs converts the rpc sweep outputs of a loop out request.
+func (s *swapClientServer) sweepOutputs(in []*looprpc.SweepOutput) (
+	[]loopdb.SweepOutput, error) {
+
+	var outputs []loopdb.SweepOutput
+	for _, output := range in {
+		addr, err := btcutil.DecodeAddress(
+			output.Addr, s.lnd.ChainParams,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("decode sweep output address: "+
+				"%v", err)
+		}
+
+		outputs = append(outputs, loopdb.SweepOutput{
+			Addr:   addr,
+			Amount: btcutil.Amount(output.Amt),
+			Weight: output.Weight,
+		})
+	}
+
+	return outputs, nil
+}
+
+// idempotentSwap returns the response for the swap that was started with the
+// given idempotency key, or nil if no swap was started with it. The params are
+// the request without its idempotency key.
+func (s *swapClientServer) idempotentSwap(swapType swap.Type, key string,
+	params proto.Message) (*looprpc.SwapResponse, error) {
+
+	digest, err := requestDigest(params)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := s.impl.IdempotentSwap(swapType, key, digest)
+	if err != nil || info == nil {
+		return nil, err
+	}
+
+	log.Infof("Returning swap %v of idempotency key %v", info.SwapHash,
+		key)
+
+	return &looprpc.SwapResponse{
+		Id:          info.SwapHash.String(),
+		HtlcAddress: info.HtlcAddress.String(),
 	}, nil
 }
 
+// requestDigest returns the digest of the deterministic serialization of an rpc
+// swap request. It commits to the parameters of requests that carry an
+// idempotency key.
+func requestDigest(in proto.Message) ([32]byte, error) {
+	var b proto.Buffer
+	b.SetDeterministic(true)
+	if err := b.Marshal(in); err != nil {
+		return [32]byte{}, err
+	}
+
+	return sha256.Sum256(b.Bytes()), nil
+}
+
+// checkDeferredLoopOut checks that a loop out request that is initiated at a
+// later time doesn't use options that only apply to swaps that are initiated
+// right away or that aren't persisted with the request.
+func checkDeferredLoopOut(in *looprpc.LoopOutRequest) error {
+	if in.Split || len(in.QuoteId) != 0 || in.Probe ||
+		in.SwapPublicationDeadline != 0 ||
+		in.SweepFeeRateCeilingSatPerVbyte != 0 ||
+		in.SweepSafetyMargin != 0 || len(in.SweepOutputs) != 0 ||
+		in.IdempotencyKey != "" || in.Label != "" ||
+		len(in.Metadata) != 0 {
+
+		return errScheduledUnsupported
+	}
+
+	return nil
+}
+
+// checkDeferredLoopIn checks that a loop in request that is initiated at a
+// later time doesn't use options that only apply to swaps that are initiated
+// right away or that aren't persisted with the request.
+func checkDeferredLoopIn(in *looprpc.LoopInRequest) error {
+	if in.Split || len(in.QuoteId) != 0 || in.RefundAddr != "" ||
+		in.RefundConfTarget != 0 || in.RefundSatPerVbyte != 0 ||
+		in.NativeSegwitHtlc || in.IdempotencyKey != "" ||
+		in.Label != "" || len(in.Metadata) != 0 {
+
+		return errScheduledUnsupported
+	}
+
+	return nil
+}
+
+// unmarshallTrigger converts the trigger conditions of an rpc request. A fee
+// rate condition without confirmation target uses the default target.
+func unmarshallTrigger(trigger *looprpc.SwapTrigger) (*loopdb.IntentTrigger,
+	error) {
+
+	if trigger == nil {
+		return &loopdb.IntentTrigger{}, nil
+	}
+
+	result := &loopdb.IntentTrigger{
+		NotBeforeHeight: trigger.NotBeforeHeight,
+		DeadlineHeight:  trigger.DeadlineHeight,
+	}
+
+	if trigger.NotBefore != 0 {
+		result.NotBefore = time.Unix(trigger.NotBefore, 0)
+	}
+
+	if trigger.MaxFeeRateSatPerVbyte != 0 {
+		result.MaxFeeRate = chainfee.SatPerKVByte(
+			trigger.MaxFeeRateSatPerVbyte * 1000,
+		).FeePerKWeight()
+
+		result.FeeConfTarget = defaultConfTarget
+		if trigger.FeeConfTarget != 0 {
+			confTarget, err := validateConfTarget(
+				trigger.FeeConfTarget, defaultConfTarget,
+			)
+			if err != nil {
+				return nil, err
+			}
+			result.FeeConfTarget = confTarget
+		}
+	}
+
+	return result, nil
+}
+
+// marshallSwapIntent converts a swap intent into its rpc representation.
+func marshallSwapIntent(intent *loopdb.SwapIntent) *looprpc.SwapIntent {
+	var swapType looprpc.SwapType
+	switch intent.Type {
+	case swap.TypeIn:
+		swapType = looprpc.SwapType_LOOP_IN
+	case swap.TypeOut:
+		swapType = looprpc.SwapType_LOOP_OUT
+	}
+
+	var state looprpc.SwapIntentState
+	switch intent.State {
+	case loopdb.IntentStateLaunched:
+		state = looprpc.SwapIntentState_INTENT_LAUNCHED
+	case loopdb.IntentStateCanceled:
+		state = looprpc.SwapIntentState_INTENT_CANCELED
+	case loopdb.IntentStateFailed:
+		state = looprpc.SwapIntentState_INTENT_FAILED
+	default:
+		state = looprpc.SwapIntentState_INTENT_PENDING
+	}
+
+	trigger := &looprpc.SwapTrigger{
+		NotBeforeHeight: intent.Trigger.NotBeforeHeight,
+		MaxFeeRateSatPerVbyte: uint64(
+			intent.Trigger.MaxFeeRate.FeePerKVByte() / 1000,
+		),
+		FeeConfTarget:  intent.Trigger.FeeConfTarget,
+		DeadlineHeight: intent.Trigger.DeadlineHeight,
+	}
+	if !intent.Trigger.NotBefore.IsZero() {
+		trigger.NotBefore = intent.Trigger.NotBefore.Unix()
+	}
+
+	rpcIntent := &looprpc.SwapIntent{
+		Id:             intent.ID.String(),
+		Type:           swapType,
+		State:          state,
+		Trigger:        trigger,
+		Amt:            int64(intent.Amount),
+		CreationTime:   intent.CreationTime.UnixNano(),
+		LastUpdateTime: intent.Time.UnixNano(),
+		Error:          intent.Error,
+	}
+	if intent.DestAddr != nil {
+		rpcIntent.Dest = intent.DestAddr.String()
+	}
+	if intent.State == loopdb.IntentStateLaunched {
+		rpcIntent.SwapId = intent.SwapHash.String()
+	}
+
+	return rpcIntent
+}
+
+// CreateRecurringSwap persists a swap that is initiated once per interval.
+func (s *swapClientServer) CreateRecurringSwap(ctx context.Context,
+	in *looprpc.CreateRecurringSwapRequest) (*looprpc.RecurringSwap,
+	error) {
+
+	log.Infof("Create recurring swap request received")
+
+	schedule := &loop.RecurringSchedule{
+		Interval:     time.Duration(in.IntervalSec) * time.Second,
+		Period:       time.Duration(in.PeriodSec) * time.Second,
+		MaxPerPeriod: btcutil.Amount(in.MaxAmtPerPeriod),
+	}
+	if in.StartTime != 0 {
+		schedule.StartTime = time.Unix(in.StartTime, 0)
+	}
+
+	var recurring *loopdb.RecurringSwap
+	switch {
+	case in.LoopOut != nil && in.LoopIn != nil:
+		return nil, errors.New("loop_out and loop_in cannot both be " +
+			"set")
+
+	case in.LoopOut != nil:
+		if err := checkDeferredLoopOut(in.LoopOut); err != nil {
+			return nil, err
+		}
+
+		req, err := s.loopOutRequest(ctx, in.LoopOut, true)
+		if err != nil {
+			return nil, err
+		}
+
+		recurring, err = s.impl.CreateRecurringLoopOut(req, schedule)
+		if err != nil {
+			return nil, err
+		}
+
+	case in.LoopIn != nil:
+		if err := checkDeferredLoopIn(in.LoopIn); err != nil {
+			return nil, err
+		}
+
+		req, err := s.loopInRequest(ctx, in.LoopIn)
+		if err != nil {
+			return nil, err
+		}
+
+		recurring, err = s.impl.CreateRecurringLoopIn(req, schedule)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, errors.New("either loop_out or loop_in must be set")
+	}
+
+	return marshallRecurringSwap(recurring), nil
+}
+
+// ListRecurringSwaps returns all recurring swaps and their runs.
+func (s *swapClientServer) ListRecurringSwaps(ctx context.Context,
+	_ *looprpc.ListRecurringSwapsRequest) (
+	*looprpc.ListRecurringSwapsResponse, error) {
+
+	log.Infof("List recurring swaps request received")
+
+	recurringSwaps, err := s.impl.FetchRecurringSwaps()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(recurringSwaps, func(i, j int) bool {
+		return recurringSwaps[i].CreationTime.Before(
+			recurringSwaps[j].CreationTime,
+		)
+	})
+
+	rpcRecurring := make([]*looprpc.RecurringSwap, 0, len(recurringSwaps))
+	for _, recurring := range recurringSwaps {
+		rpcRecurring = append(
+			rpcRecurring, marshallRecurringSwap(recurring),
+		)
+	}
+
+	return &looprpc.ListRecurringSwapsResponse{
+		RecurringSwaps: rpcRecurring,
+	}, nil
+}
+
+// CancelRecurringSwap cancels an active recurring swap.
+func (s *swapClientServer) CancelRecurringSwap(ctx context.Context,
+	in *looprpc.CancelRecurringSwapRequest) (*looprpc.RecurringSwap,
+	error) {
+
+	log.Infof("Cancel recurring swap request received")
+
+	idBytes, err := hex.DecodeString(in.Id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recurring swap id: %v", err)
+	}
+
+	var id loopdb.RecurringID
+	if len(idBytes) != len(id) {
+		return nil, fmt.Errorf("recurring swap id must be %v bytes",
+			len(id))
+	}
+	copy(id[:], idBytes)
+
+	recurring, err := s.impl.CancelRecurringSwap(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshallRecurringSwap(recurring), nil
+}
+
+// RegisterDestDescriptor registers an xpub or output descriptor that loop
+// out destination addresses are derived from.
+func (s *swapClientServer) RegisterDestDescriptor(ctx context.Context,
+	in *looprpc.RegisterDestDescriptorRequest) (*looprpc.DestDescriptor,
+	error) {
+
+	log.Infof("Register destination descriptor request received")
+
+	dest, err := s.impl.RegisterDestDescriptor(in.OutputDescriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshallDestDescriptor(dest), nil
+}
+
+// GetDestDescriptor returns the registered destination descriptor.
+func (s *swapClientServer) GetDestDescriptor(ctx context.Context,
+	_ *looprpc.GetDestDescriptorRequest) (*looprpc.DestDescriptor, error) {
+
+	log.Infof("Get destination descriptor request received")
+
+	dest, err := s.impl.FetchDestDescriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	return marshallDestDescriptor(dest), nil
+}
+
+// UnregisterDestDescriptor removes the registered destination descriptor.
+func (s *swapClientServer) UnregisterDestDescriptor(ctx context.Context,
+	_ *looprpc.UnregisterDestDescriptorRequest) (
+	*looprpc.UnregisterDestDescriptorResponse, error) {
+
+	log.Infof("Unregister destination descriptor request received")
+
+	if err := s.impl.UnregisterDestDescriptor(); err != nil {
+		return nil, err
+	}
+
+	return &looprpc.UnregisterDestDescriptorResponse{}, nil
+}
+
+// marshallDestDescriptor converts a destination descriptor into its rpc
+// representation.
+func marshallDestDescriptor(
+	dest *loopdb.DestDescriptor) *looprpc.DestDescriptor {
+
+	return &looprpc.DestDescriptor{
+		OutputDescriptor: dest.Descriptor,
+		NextIndex:        dest.NextIndex,
+	}
+}
+
+// marshallRecurringSwap converts a recurring swap into its rpc
+// representation.
+func marshallRecurringSwap(
+	recurring *loopdb.RecurringSwap) *looprpc.RecurringSwap {
+
+	var swapType looprpc.SwapType
+	switch recurring.Type {
+	case swap.TypeIn:
+		swapType = looprpc.SwapType_LOOP_IN
+	case swap.TypeOut:
+		swapType = looprpc.SwapType_LOOP_OUT
+	}
+
+	state := looprpc.RecurringSwapState_RECURRING_ACTIVE
+	if recurring.State == loopdb.RecurringStateCanceled {
+		state = looprpc.RecurringSwapState_RECURRING_CANCELED
+	}
+
+	rpcRecurring := &looprpc.RecurringSwap{
+		Id:              recurring.ID.String(),
+		Type:            swapType,
+		State:           state,
+		Amt:             int64(recurring.Amount),
+		CreationTime:    recurring.CreationTime.UnixNano(),
+		StartTime:       recurring.StartTime.Unix(),
+		IntervalSec:     uint64(recurring.Interval / time.Second),
+		PeriodSec:       uint64(recurring.Period / time.Second),
+		MaxAmtPerPeriod: int64(recurring.MaxPerPeriod),
+	}
+	if recurring.DestAddr != nil {
+		rpcRecurring.Dest = recurring.DestAddr.String()
+	}
+
+	for _, run := range recurring.Runs {
+		rpcRun := &looprpc.RecurringSwapRun{
+			ScheduledTime: run.ScheduledTime.Unix(),
+			Time:          run.Time.Unix(),
+			Amt:           int64(run.Amount),
+			Error:         run.Error,
+		}
+		if run.Failed() {
+			rpcRecurring.FailedRuns++
+		} else {
+			rpcRun.SwapId = run.SwapHash.String()
+		}
+
+		rpcRecurring.Runs = append(rpcRecurring.Runs, rpcRun)
+	}
+
+	return rpcRecurring
+}
+
 // Monitor will return a stream of swap updates for currently active swaps.
 func (s *swapClientServer) Monitor(in *looprpc.MonitorRequest,
 	server looprpc.SwapClient_MonitorServer) error {
 
 	log.Infof("Monitor request received")
 
+	filter := loop.SwapFilter{
+		Label:    in.Label,
+		Metadata: in.Metadata,
+	}
+
 	send := func(info loop.SwapInfo) error {
+		if !filter.Matches(&info.SwapContract) {
+			return nil
+		}
+
 		rpcSwap, err := s.marshallSwap(&info)
 		if err != nil {
 			return err
@@ -170,6 +1111,10 @@ func (s *swapClientServer) Monitor(in *looprpc.MonitorRequest,
 
 	var pendingSwaps, completedSwaps []loop.SwapInfo
 	for _, swap := range swaps {
+		if !filter.Matches(&swap.SwapContract) {
+			continue
+		}
+
 		if swap.State.Type() == loopdb.StateTypePending {
 			pendingSwaps = append(pendingSwaps, swap)
 		} else {
@@ -240,7 +1185,7 @@ func (s *swapClientServer) LoopOutTerms(ctx context.Context,
 
 	log.Infof("Loop out terms request received")
 
-	terms, err := s.impl.LoopOutTerms(ctx)
+	terms, err := s.impl.LoopOutTerms(ctx, req.ServerId)
 	if err != nil {
 		log.Errorf("Terms request: %v", err)
 		return nil, err
@@ -263,23 +1208,37 @@ func (s *swapClientServer) LoopOutQuote(ctx context.Context,
 	if err != nil {
 		return nil, err
 	}
-	quote, err := s.impl.LoopOutQuote(ctx, &loop.LoopOutQuoteRequest{
+	quoteReq := &loop.LoopOutQuoteRequest{
 		Amount:          btcutil.Amount(req.Amt),
 		SweepConfTarget: confTarget,
 		SwapPublicationDeadline: time.Unix(
 			int64(req.SwapPublicationDeadline), 0,
 		),
-	})
+		Split:        req.Split,
+		SweepOutputs: int(req.SweepOutputs),
+		ServerID:     req.ServerId,
+	}
+	if req.LoopOutChannel != 0 {
+		quoteReq.LoopOutChannel = &req.LoopOutChannel
+	}
+
+	quote, err := s.impl.LoopOutQuote(ctx, quoteReq)
 	if err != nil {
 		return nil, err
 	}
 
 	return &looprpc.QuoteResponse{
-		MinerFee:        int64(quote.MinerFee),
-		PrepayAmt:       int64(quote.PrepayAmount),
-		SwapFee:         int64(quote.SwapFee),
-		SwapPaymentDest: quote.SwapPaymentDest[:],
-		CltvDelta:       quote.CltvDelta,
+		MinerFee:         int64(quote.MinerFee),
+		PrepayAmt:        int64(quote.PrepayAmount),
+		SwapFee:          int64(quote.SwapFee),
+		SwapPaymentDest:  quote.SwapPaymentDest[:],
+		CltvDelta:        quote.CltvDelta,
+		NumSwaps:         int32(quote.NumSwaps),
+		QuoteId:          quote.QuoteID,
+		QuoteExpiry:      marshallQuoteExpiry(quote.QuoteExpiry),
+		SwapRoutingFee:   int64(quote.SwapRoutingFee),
+		PrepayRoutingFee: int64(quote.PrepayRoutingFee),
+		ServerId:         quote.ServerID,
 	}, nil
 }
 
@@ -289,7 +1248,7 @@ func (s *swapClientServer) GetLoopInTerms(ctx context.Context, req *looprpc.Term
 
 	log.Infof("Loop in terms request received")
 
-	terms, err := s.impl.LoopInTerms(ctx)
+	terms, err := s.impl.LoopInTerms(ctx, req.ServerId)
 	if err != nil {
 		log.Errorf("Terms request: %v", err)
 		return nil, err
@@ -311,13 +1270,19 @@ func (s *swapClientServer) GetLoopInQuote(ctx context.Context,
 		Amount:         btcutil.Amount(req.Amt),
 		HtlcConfTarget: defaultConfTarget,
 		ExternalHtlc:   req.ExternalHtlc,
+		Split:          req.Split,
+		ServerID:       req.ServerId,
 	})
 	if err != nil {
 		return nil, err
 	}
 	return &looprpc.QuoteResponse{
-		MinerFee: int64(quote.MinerFee),
-		SwapFee:  int64(quote.SwapFee),
+		MinerFee:    int64(quote.MinerFee),
+		SwapFee:     int64(quote.SwapFee),
+		NumSwaps:    int32(quote.NumSwaps),
+		QuoteId:     quote.QuoteID,
+		QuoteExpiry: marshallQuoteExpiry(quote.QuoteExpiry),
+		ServerId:    quote.ServerID,
 	}, nil
 }
 
@@ -327,26 +1292,139 @@ func (s *swapClientServer) LoopIn(ctx context.Context,
 
 	log.Infof("Loop in request received")
 
+	// A retried request returns the swap that it started before, without
+	// requesting a new quote.
+	if in.IdempotencyKey != "" && !in.Split {
+		params := proto.Clone(in).(*looprpc.LoopInRequest)
+		params.IdempotencyKey = ""
+
+		resp, err := s.idempotentSwap(
+			swap.TypeIn, in.IdempotencyKey, params,
+		)
+		if err != nil || resp != nil {
+			return resp, err
+		}
+	}
+
+	req, err := s.loopInRequest(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+
+	if in.Split {
+		groupID, parts, err := s.impl.LoopInSplit(ctx, req)
+		if err != nil {
+			log.Errorf("Loop in split: %v", err)
+			return nil, err
+		}
+
+		return marshallGroupResponse(groupID, parts), nil
+	}
+
+	hash, htlc, err := s.impl.LoopIn(ctx, req)
+	if err != nil {
+		log.Errorf("Loop in: %v", err)
+		return nil, err
+	}
+
+	return &looprpc.SwapResponse{
+		Id:          hash.String(),
+		HtlcAddress: htlc.String(),
+	}, nil
+}
+
+// loopInRequest converts an rpc loop in request into a client request. If a
+// total cost budget is given, the limits are derived from a new quote.
+func (s *swapClientServer) loopInRequest(ctx context.Context,
+	in *looprpc.LoopInRequest) (*loop.LoopInRequest, error) {
+
 	req := &loop.LoopInRequest{
 		Amount:         btcutil.Amount(in.Amt),
 		MaxMinerFee:    btcutil.Amount(in.MaxMinerFee),
 		MaxSwapFee:     btcutil.Amount(in.MaxSwapFee),
 		HtlcConfTarget: defaultConfTarget,
 		ExternalHtlc:   in.ExternalHtlc,
+		QuoteID:        in.QuoteId,
+		ServerID:       in.ServerId,
+		Label:          in.Label,
+		Metadata:       in.Metadata,
+
+		NativeSegwitHtlc: in.NativeSegwitHtlc,
+		RefundConfTarget: in.RefundConfTarget,
+		RefundFeeRate: chainfee.SatPerKVByte(
+			in.RefundSatPerVbyte * 1000,
+		).FeePerKWeight(),
 	}
 	if in.LoopInChannel != 0 {
 		req.LoopInChannel = &in.LoopInChannel
 	}
-	hash, htlc, err := s.impl.LoopIn(ctx, req)
+
+	// The digest of a keyed request covers the request as it was sent,
+	// before any limits are derived from a budget.
+	if in.IdempotencyKey != "" {
+		params := proto.Clone(in).(*looprpc.LoopInRequest)
+		params.IdempotencyKey = ""
+
+		req.IdempotencyKey = in.IdempotencyKey
+
+		var err error
+		req.RequestDigest, err = requestDigest(params)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if in.RefundAddr != "" {
+		refundAddr, err := btcutil.DecodeAddress(
+			in.RefundAddr, s.lnd.ChainParams,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("decode refund address: %v", err)
+		}
+		req.RefundAddr = refundAddr
+	}
+
+	budget, err := getBudget(
+		req.Amount, in.MaxTotalCost, in.MaxTotalCostPpm,
+	)
 	if err != nil {
-		log.Errorf("Loop in: %v", err)
 		return nil, err
 	}
 
-	return &looprpc.SwapResponse{
-		Id:          hash.String(),
-		HtlcAddress: htlc.String(),
-	}, nil
+	// If a total budget is given, we obtain a quote and derive all limits
+	// from it.
+	if budget != 0 {
+		if in.MaxSwapFee != 0 || in.MaxMinerFee != 0 ||
+			len(in.QuoteId) != 0 {
+
+			return nil, errBudgetWithLimits
+		}
+
+		quote, err := s.impl.LoopInQuote(ctx, &loop.LoopInQuoteRequest{
+			Amount:         req.Amount,
+			HtlcConfTarget: req.HtlcConfTarget,
+			ExternalHtlc:   req.ExternalHtlc,
+			Split:          in.Split,
+			ServerID:       req.ServerID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		err = loop.ApplyLoopInBudget(req, budget, quote)
+		if err != nil {
+			return nil, err
+		}
+		req.QuoteID = quote.QuoteID
+
+		// The limits only apply to the server that issued the quote.
+		req.ServerID = quote.ServerID
+
+		log.Infof("Loop in budget %v: max swap fee %v, max miner "+
+			"fee %v", budget, req.MaxSwapFee, req.MaxMinerFee)
+	}
+
+	return req, nil
 }
 
 // GetLsatTokens returns all tokens that are contained in the LSAT token store.
@@ -355,34 +1433,119 @@ func (s *swapClientServer) GetLsatTokens(ctx context.Context,
 
 	log.Infof("Get LSAT tokens request received")
 
-	tokens, err := s.impl.LsatStore.AllTokens()
-	if err != nil {
-		return nil, err
-	}
-
-	rpcTokens := make([]*looprpc.LsatToken, len(tokens))
-	idx := 0
-	for key, token := range tokens {
-		macBytes, err := token.BaseMacaroon().MarshalBinary()
+	var rpcTokens []*looprpc.LsatToken
+	for serverID, store := range s.impl.LsatStores() {
+		tokens, err := store.AllTokens()
 		if err != nil {
 			return nil, err
 		}
-		rpcTokens[idx] = &looprpc.LsatToken{
-			BaseMacaroon:       macBytes,
-			PaymentHash:        token.PaymentHash[:],
-			PaymentPreimage:    token.Preimage[:],
-			AmountPaidMsat:     int64(token.AmountPaid),
-			RoutingFeePaidMsat: int64(token.RoutingFeePaid),
-			TimeCreated:        token.TimeCreated.Unix(),
-			Expired:            !token.IsValid(),
-			StorageName:        key,
+
+		for key, token := range tokens {
+			macBytes, err := token.BaseMacaroon().MarshalBinary()
+			if err != nil {
+				return nil, err
+			}
+			rpcTokens = append(rpcTokens, &looprpc.LsatToken{
+				BaseMacaroon:       macBytes,
+				PaymentHash:        token.PaymentHash[:],
+				PaymentPreimage:    token.Preimage[:],
+				AmountPaidMsat:     int64(token.AmountPaid),
+				RoutingFeePaidMsat: int64(token.RoutingFeePaid),
+				TimeCreated:        token.TimeCreated.Unix(),
+				Expired:            !token.IsValid(),
+				StorageName:        key,
+				ServerId:           serverID,
+			})
 		}
-		idx++
 	}
 
 	return &looprpc.TokensResponse{Tokens: rpcTokens}, nil
 }
 
+// GetServerHealth returns the connection health of all configured swap
+// servers.
+func (s *swapClientServer) GetServerHealth(ctx context.Context,
+	_ *looprpc.ServerHealthRequest) (*looprpc.ServerHealthResponse, error) {
+
+	log.Infof("Server health request received")
+
+	var rpcServers []*looprpc.ServerHealth
+	for _, health := range s.impl.ServerHealth() {
+		rpcServers = append(rpcServers, marshallServerHealth(health))
+	}
+
+	return &looprpc.ServerHealthResponse{Servers: rpcServers}, nil
+}
+
+// marshallServerHealth converts the connection health of a swap server into
+// its rpc representation.
+func marshallServerHealth(health loop.ServerHealth) *looprpc.ServerHealth {
+	var state looprpc.ServerConnectionState
+	switch health.State {
+	case loop.ServerStateDegraded:
+		state = looprpc.ServerConnectionState_SERVER_DEGRADED
+	case loop.ServerStateOffline:
+		state = looprpc.ServerConnectionState_SERVER_OFFLINE
+	default:
+		state = looprpc.ServerConnectionState_SERVER_ONLINE
+	}
+
+	rpcHealth := &looprpc.ServerHealth{
+		ServerId:            health.ServerID,
+		State:               state,
+		ConsecutiveFailures: uint32(health.ConsecutiveFailures),
+	}
+
+	if health.LastError != nil {
+		rpcHealth.LastError = health.LastError.Error()
+	}
+	if !health.LastSuccess.IsZero() {
+		rpcHealth.LastSuccess = health.LastSuccess.Unix()
+	}
+	if !health.RetryAt.IsZero() {
+		rpcHealth.RetryAt = health.RetryAt.Unix()
+	}
+
+	return rpcHealth
+}
+
+// getBudget returns the total cost budget of a swap request, or zero if the
+// request uses individual limits.
+func getBudget(amt btcutil.Amount, totalCost int64,
+	totalCostPPM uint64) (btcutil.Amount, error) {
+
+	switch {
+	case totalCost < 0:
+		return 0, errors.New("max_total_cost cannot be negative")
+
+	case totalCost != 0 && totalCostPPM != 0:
+		return 0, errors.New("max_total_cost and max_total_cost_ppm " +
+			"cannot both be set")
+
+	case totalCostPPM != 0:
+		budget := loop.BudgetFromPPM(amt, totalCostPPM)
+		if budget == 0 {
+			return 0, errors.New("max_total_cost_ppm too low " +
+				"for swap amount")
+		}
+
+		return budget, nil
+
+	default:
+		return btcutil.Amount(totalCost), nil
+	}
+}
+
+// marshallQuoteExpiry returns the unix time of a quote expiry, or zero if the
+// quote does not expire.
+func marshallQuoteExpiry(expiry time.Time) int64 {
+	if expiry.IsZero() {
+		return 0
+	}
+
+	return expiry.Unix()
+}
+
 // validateConfTarget ensures the given confirmation target is valid. If one
 // isn't specified (0 value), then the default target is used.
 func validateConfTarget(target, defaultTarget int32) (int32, error) {
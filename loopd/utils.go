@@ -1,8 +1,11 @@
 package loopd
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/btcsuite/btcutil"
 	"github.com/lightninglabs/loop"
@@ -18,10 +21,21 @@ func getClient(config *config, lnd *lndclient.LndServices) (*loop.Client,
 		return nil, nil, err
 	}
 
+	paymentRetryPolicy := loop.DefaultPaymentRetryPolicy
+	paymentRetryPolicy.MaxAttempts = config.PaymentMaxAttempts
+	paymentRetryPolicy.Backoff = config.PaymentRetryBackoff
+	paymentRetryPolicy.MaxBackoff = config.PaymentMaxBackoff
+	paymentRetryPolicy.ExpiryDelta = config.PaymentExpiryDelta
+
+	servers, err := swapServerConfigs(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	swapClient, cleanUp, err := loop.NewClient(
-		storeDir, config.SwapServer, config.Insecure,
-		config.TLSPathSwapSrv, lnd, btcutil.Amount(config.MaxLSATCost),
-		btcutil.Amount(config.MaxLSATFee),
+		storeDir, servers, lnd, btcutil.Amount(config.MaxLSATCost),
+		btcutil.Amount(config.MaxLSATFee), paymentRetryPolicy,
+		config.DestAllowList,
 	)
 	if err != nil {
 		return nil, nil, err
@@ -30,6 +44,70 @@ func getClient(config *config, lnd *lndclient.LndServices) (*loop.Client,
 	return swapClient, cleanUp, nil
 }
 
+// swapServerConfigs returns the configured swap servers. The server set with
+// --swapserver is the default server.
+func swapServerConfigs(config *config) ([]loop.ServerConfig, error) {
+	if config.StreamIsolation && config.Proxy == "" {
+		return nil, errors.New("stream isolation requires a proxy")
+	}
+
+	servers := []loop.ServerConfig{{
+		ID:       defaultServerID,
+		Address:  config.SwapServer,
+		Insecure: config.Insecure,
+		TLSPath:  config.TLSPathSwapSrv,
+	}}
+
+	for _, extra := range config.ExtraSwapServers {
+		server, err := parseSwapServer(extra)
+		if err != nil {
+			return nil, err
+		}
+		servers = append(servers, *server)
+	}
+
+	// The proxy applies to all servers, as connecting to any of them
+	// directly would reveal our ip address.
+	for i := range servers {
+		servers[i].Proxy = config.Proxy
+		servers[i].StreamIsolation = config.StreamIsolation
+	}
+
+	return servers, nil
+}
+
+// parseSwapServer parses a swap server of the form id@host:port, optionally
+// followed by the options ,tlspath=<path> and ,insecure.
+func parseSwapServer(s string) (*loop.ServerConfig, error) {
+	at := strings.Index(s, "@")
+	if at < 0 {
+		return nil, fmt.Errorf("swap server %v: expected "+
+			"id@host:port", s)
+	}
+
+	fields := strings.Split(s[at+1:], ",")
+	server := &loop.ServerConfig{
+		ID:      s[:at],
+		Address: fields[0],
+	}
+
+	for _, option := range fields[1:] {
+		switch {
+		case option == "insecure":
+			server.Insecure = true
+
+		case strings.HasPrefix(option, "tlspath="):
+			server.TLSPath = strings.TrimPrefix(option, "tlspath=")
+
+		default:
+			return nil, fmt.Errorf("swap server %v: unknown "+
+				"option %v", server.ID, option)
+		}
+	}
+
+	return server, nil
+}
+
 func getStoreDir(network string) (string, error) {
 	dir := filepath.Join(loopDirBase, network)
 	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
@@ -2,6 +2,7 @@ package loopd
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
 
 	"github.com/btcsuite/btcd/chaincfg"
@@ -29,25 +30,42 @@ func view(config *config, lisCfg *listenerCfg) error {
 	}
 	defer cleanup()
 
-	if err := viewOut(swapClient, chainParams); err != nil {
+	metadata, err := loop.ParseMetadata(config.View.Metadata)
+	if err != nil {
+		return err
+	}
+
+	filter := &loop.SwapFilter{
+		Label:    config.View.Label,
+		Metadata: metadata,
+	}
+
+	if err := viewOut(swapClient, chainParams, filter); err != nil {
 		return err
 	}
 
-	if err := viewIn(swapClient, chainParams); err != nil {
+	if err := viewIn(swapClient, chainParams, filter); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func viewOut(swapClient *loop.Client, chainParams *chaincfg.Params) error {
+func viewOut(swapClient *loop.Client, chainParams *chaincfg.Params,
+	filter *loop.SwapFilter) error {
+
 	swaps, err := swapClient.Store.FetchLoopOutSwaps()
 	if err != nil {
 		return err
 	}
 
 	for _, s := range swaps {
+		if !filter.Matches(&s.Contract.SwapContract) {
+			continue
+		}
+
 		htlc, err := swap.NewHtlc(
+			s.Contract.HtlcVersion,
 			s.Contract.CltvExpiry,
 			s.Contract.SenderKey,
 			s.Contract.ReceiverKey,
@@ -63,6 +81,7 @@ func viewOut(swapClient *loop.Client, chainParams *chaincfg.Params) error {
 		)
 		fmt.Printf("   Preimage: %v\n", s.Contract.Preimage)
 		fmt.Printf("   Htlc address: %v\n", htlc.Address)
+		printLabels(&s.Contract.SwapContract)
 
 		unchargeChannel := "any"
 		if s.Contract.UnchargeChannel != nil {
@@ -90,24 +109,32 @@ func viewOut(swapClient *loop.Client, chainParams *chaincfg.Params) error {
 
 			fmt.Println()
 		}
+		printServerUpdates(s.ServerUpdates)
 		fmt.Println()
 	}
 
 	return nil
 }
 
-func viewIn(swapClient *loop.Client, chainParams *chaincfg.Params) error {
+func viewIn(swapClient *loop.Client, chainParams *chaincfg.Params,
+	filter *loop.SwapFilter) error {
+
 	swaps, err := swapClient.Store.FetchLoopInSwaps()
 	if err != nil {
 		return err
 	}
 
 	for _, s := range swaps {
+		if !filter.Matches(&s.Contract.SwapContract) {
+			continue
+		}
+
 		htlc, err := swap.NewHtlc(
+			s.Contract.HtlcVersion,
 			s.Contract.CltvExpiry,
 			s.Contract.SenderKey,
 			s.Contract.ReceiverKey,
-			s.Hash, swap.HtlcNP2WSH, chainParams,
+			s.Hash, s.Contract.HtlcOutputType, chainParams,
 		)
 		if err != nil {
 			return err
@@ -118,7 +145,13 @@ func viewIn(swapClient *loop.Client, chainParams *chaincfg.Params) error {
 			s.Contract.InitiationTime, s.Contract.InitiationHeight,
 		)
 		fmt.Printf("   Preimage: %v\n", s.Contract.Preimage)
-		fmt.Printf("   Htlc address: %v\n", htlc.Address)
+		fmt.Printf("   Htlc address: %v (%v)\n", htlc.Address,
+			s.Contract.HtlcOutputType)
+		printLabels(&s.Contract.SwapContract)
+		if s.Contract.RefundAddr != nil {
+			fmt.Printf("   Refund address: %v\n",
+				s.Contract.RefundAddr)
+		}
 		fmt.Printf("   Amt: %v, Expiry: %v\n",
 			s.Contract.AmountRequested, s.Contract.CltvExpiry,
 		)
@@ -126,9 +159,59 @@ func viewIn(swapClient *loop.Client, chainParams *chaincfg.Params) error {
 			fmt.Printf("   Update %v, Time %v, State: %v\n",
 				i, e.Time, e.State,
 			)
+			if e.RefundTxHash != nil {
+				fmt.Printf("      Refund tx: %v, Cost: "+
+					"onchain=%v\n", e.RefundTxHash,
+					e.Cost.Onchain)
+			}
+		}
+		for _, d := range s.Deposits {
+			fmt.Printf("   Deposit %v, Amt: %v, Accepted: %v, "+
+				"State: %v\n", d.Outpoint, d.Value, d.Accepted,
+				d.State,
+			)
+			if d.SpendTxHash != nil {
+				fmt.Printf("      Spend tx: %v\n", d.SpendTxHash)
+			}
 		}
+		printServerUpdates(s.ServerUpdates)
 		fmt.Println()
 	}
 
 	return nil
 }
+
+// printLabels prints the label and metadata of a swap, if any.
+func printLabels(contract *loopdb.SwapContract) {
+	if contract.Label != "" {
+		fmt.Printf("   Label: %v\n", contract.Label)
+	}
+
+	keys := make([]string, 0, len(contract.Metadata))
+	for key := range contract.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		fmt.Printf("   Metadata: %v=%v\n", key,
+			contract.Metadata[key])
+	}
+}
+
+// printServerUpdates prints the updates that the server pushed for a swap.
+func printServerUpdates(updates []*loopdb.ServerSwapUpdate) {
+	for i, u := range updates {
+		fmt.Printf("   Server update %v, Time %v, State: %v",
+			i, u.Time, u.State,
+		)
+		if u.Reason != "" {
+			fmt.Printf(", Reason: %v", u.Reason)
+		}
+		if !u.ExpectedPublication.IsZero() {
+			fmt.Printf(", Expected publication: %v",
+				u.ExpectedPublication)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,161 @@
+package loopdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// DepositState indicates whether an htlc deposit of a loop in swap has been
+// spent and by whom.
+type DepositState uint8
+
+const (
+	// DepositConfirmed indicates that the deposit is confirmed and not yet
+	// spent.
+	DepositConfirmed DepositState = 0
+
+	// DepositSwept indicates that the server swept the deposit with the
+	// preimage.
+	DepositSwept DepositState = 1
+
+	// DepositRefunded indicates that the deposit was refunded by a
+	// timeout tx.
+	DepositRefunded DepositState = 2
+)
+
+// String returns a string representation of the deposit state.
+func (s DepositState) String() string {
+	switch s {
+	case DepositConfirmed:
+		return "Confirmed"
+
+	case DepositSwept:
+		return "Swept"
+
+	case DepositRefunded:
+		return "Refunded"
+
+	default:
+		return "Unknown"
+	}
+}
+
+// LoopInDeposit is a confirmed output that pays to the htlc script of a loop
+// in swap.
+type LoopInDeposit struct {
+	// Outpoint is the outpoint of the deposit.
+	Outpoint wire.OutPoint
+
+	// Value is the value of the deposit.
+	Value btcutil.Amount
+
+	// ConfHeight is the height of the block that confirmed the deposit.
+	ConfHeight int32
+
+	// Accepted indicates that the deposit funds the swap. Only a single
+	// deposit of exactly the swap amount is accepted. All other deposits
+	// are refunded once the htlc expires.
+	Accepted bool
+
+	// State indicates whether the deposit has been spent.
+	State DepositState
+
+	// SpendTxHash is the hash of the tx that spent the deposit. It is nil
+	// while the deposit is unspent.
+	SpendTxHash *chainhash.Hash
+}
+
+// serializeDeposit serializes the deposit, excluding its outpoint which is
+// used as its key.
+func serializeDeposit(deposit *LoopInDeposit) ([]byte, error) {
+	var b bytes.Buffer
+
+	if err := binary.Write(&b, byteOrder, deposit.Value); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, deposit.ConfHeight); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, deposit.Accepted); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, deposit.State); err != nil {
+		return nil, err
+	}
+
+	// An unspent deposit is serialized with a zero spend tx hash.
+	var spendTxHash chainhash.Hash
+	if deposit.SpendTxHash != nil {
+		spendTxHash = *deposit.SpendTxHash
+	}
+	if _, err := b.Write(spendTxHash[:]); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// deserializeDeposit deserializes a deposit that is stored under the given
+// outpoint key.
+func deserializeDeposit(key, value []byte) (*LoopInDeposit, error) {
+	deposit := &LoopInDeposit{}
+
+	err := readOutpoint(bytes.NewReader(key), &deposit.Outpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	r := bytes.NewReader(value)
+
+	if err := binary.Read(r, byteOrder, &deposit.Value); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, byteOrder, &deposit.ConfHeight); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, byteOrder, &deposit.Accepted); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, byteOrder, &deposit.State); err != nil {
+		return nil, err
+	}
+
+	var spendTxHash chainhash.Hash
+	if _, err := io.ReadFull(r, spendTxHash[:]); err != nil {
+		return nil, err
+	}
+	if spendTxHash != (chainhash.Hash{}) {
+		deposit.SpendTxHash = &spendTxHash
+	}
+
+	return deposit, nil
+}
+
+// writeOutpoint serializes an outpoint as its tx hash followed by its index.
+func writeOutpoint(w io.Writer, outpoint *wire.OutPoint) error {
+	if _, err := w.Write(outpoint.Hash[:]); err != nil {
+		return err
+	}
+
+	return binary.Write(w, byteOrder, outpoint.Index)
+}
+
+// readOutpoint deserializes an outpoint that was written by writeOutpoint.
+func readOutpoint(r io.Reader, outpoint *wire.OutPoint) error {
+	if _, err := io.ReadFull(r, outpoint.Hash[:]); err != nil {
+		return err
+	}
+
+	return binary.Read(r, byteOrder, &outpoint.Index)
+}
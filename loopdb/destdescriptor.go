@@ -0,0 +1,18 @@
+package loopdb
+
+import "errors"
+
+// ErrNoDestDescriptor is returned when no destination descriptor is
+// registered.
+var ErrNoDestDescriptor = errors.New("no destination descriptor registered")
+
+// DestDescriptor is an extended public key or output descriptor that loop out
+// destination addresses are derived from.
+type DestDescriptor struct {
+	// Descriptor is the registered xpub or output descriptor.
+	Descriptor string
+
+	// NextIndex is the derivation index of the next address that is
+	// handed out.
+	NextIndex uint32
+}
@@ -33,6 +33,64 @@ type SwapStore interface {
 	UpdateLoopIn(hash lntypes.Hash, time time.Time,
 		state SwapStateData) error
 
+	// StoreLoopInDeposit adds or updates an htlc deposit of a loop in
+	// swap. Deposits are identified by their outpoint.
+	StoreLoopInDeposit(hash lntypes.Hash, deposit *LoopInDeposit) error
+
+	// StoreServerUpdate appends an update that the server pushed for a
+	// loop out or loop in swap to its history.
+	StoreServerUpdate(hash lntypes.Hash, update *ServerSwapUpdate) error
+
+	// CreateSwapGroup adds a new swap group to the store.
+	CreateSwapGroup(group *SwapGroup) error
+
+	// AddSwapToGroup links the swap with the given hash to an existing
+	// swap group.
+	AddSwapToGroup(id GroupID, hash lntypes.Hash) error
+
+	// FetchSwapGroups returns all swap groups currently in the store.
+	FetchSwapGroups() ([]*SwapGroup, error)
+
+	// CreateSwapIntent adds a new swap intent to the store.
+	CreateSwapIntent(intent *SwapIntent) error
+
+	// UpdateSwapIntent replaces the state of an existing swap intent.
+	UpdateSwapIntent(id IntentID, update *IntentUpdate) error
+
+	// FetchSwapIntents returns all swap intents currently in the store.
+	FetchSwapIntents() ([]*SwapIntent, error)
+
+	// CreateRecurringSwap adds a new recurring swap to the store.
+	CreateRecurringSwap(recurring *RecurringSwap) error
+
+	// UpdateRecurringSwap replaces the state of an existing recurring
+	// swap.
+	UpdateRecurringSwap(id RecurringID, state RecurringState) error
+
+	// AddRecurringRun records a run of an existing recurring swap.
+	AddRecurringRun(id RecurringID, run *RecurringRun) error
+
+	// FetchRecurringSwaps returns all recurring swaps currently in the
+	// store, including their runs.
+	FetchRecurringSwaps() ([]*RecurringSwap, error)
+
+	// RegisterDestDescriptor makes the given descriptor the source of
+	// loop out destination addresses. A descriptor that was registered
+	// before resumes at its previous derivation index.
+	RegisterDestDescriptor(descriptor string) (*DestDescriptor, error)
+
+	// UnregisterDestDescriptor removes the registered destination
+	// descriptor.
+	UnregisterDestDescriptor() error
+
+	// FetchDestDescriptor returns the registered destination descriptor
+	// or ErrNoDestDescriptor if there is none.
+	FetchDestDescriptor() (*DestDescriptor, error)
+
+	// NextDestIndex reserves the next derivation index of the registered
+	// destination descriptor.
+	NextDestIndex() (*DestDescriptor, error)
+
 	// Close closes the underlying database.
 	Close() error
 }
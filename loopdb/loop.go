@@ -3,9 +3,14 @@ package loopdb
 import (
 	"bytes"
 	"encoding/binary"
+	"io"
+	"sort"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/swap"
 	"github.com/lightningnetwork/lnd/lntypes"
 )
 
@@ -43,12 +48,43 @@ type SwapContract struct {
 
 	// InitiationTime is the time at which the swap was initiated.
 	InitiationTime time.Time
+
+	// HtlcVersion is the version of the htlc script that was agreed on
+	// with the server.
+	HtlcVersion swap.ScriptVersion
+
+	// ServerID identifies the swap server that the swap was negotiated
+	// with. It is empty for swaps that were created before multiple
+	// servers were supported, which belong to the default server.
+	ServerID string
+
+	// IdempotencyKey is the key that the client supplied with the swap
+	// request, so that a retried request returns this swap instead of
+	// starting another one. It is empty if no key was supplied.
+	IdempotencyKey string
+
+	// RequestDigest commits to the parameters of the request that carried
+	// the idempotency key. A request that reuses the key with different
+	// parameters is rejected. It is only set along with the key.
+	RequestDigest [32]byte
+
+	// Label is an optional user label of the swap. It is also attached to
+	// the on-chain transactions of the swap if the wallet supports it.
+	Label string
+
+	// Metadata holds optional user key/value pairs of the swap. It is nil
+	// if no pairs were supplied.
+	Metadata map[string]string
 }
 
 // Loop contains fields shared between LoopIn and LoopOut
 type Loop struct {
 	Hash   lntypes.Hash
 	Events []*LoopEvent
+
+	// ServerUpdates are the updates that the server pushed for this swap,
+	// oldest first.
+	ServerUpdates []*ServerSwapUpdate
 }
 
 // LoopEvent contains the dynamic data of a swap.
@@ -83,6 +119,16 @@ func (s *Loop) LastUpdate() *LoopEvent {
 	return lastEvent
 }
 
+// LastServerUpdate returns the most recent server update of this swap, or nil
+// if the server didn't push any.
+func (s *Loop) LastServerUpdate() *ServerSwapUpdate {
+	if len(s.ServerUpdates) == 0 {
+		return nil
+	}
+
+	return s.ServerUpdates[len(s.ServerUpdates)-1]
+}
+
 // serializeLoopEvent serializes a state update of a swap. This is used for both
 // in and out swaps.
 func serializeLoopEvent(time time.Time, state SwapStateData) (
@@ -110,6 +156,31 @@ func serializeLoopEvent(time time.Time, state SwapStateData) (
 		return nil, err
 	}
 
+	// The optional payment failure and refund tx hash are each preceded
+	// by a flag that records whether the event has them.
+	hasPaymentFailure := state.PaymentFailure != nil
+	if err := binary.Write(&b, byteOrder, hasPaymentFailure); err != nil {
+		return nil, err
+	}
+
+	if hasPaymentFailure {
+		err := binary.Write(&b, byteOrder, state.PaymentFailure)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	hasRefundTxHash := state.RefundTxHash != nil
+	if err := binary.Write(&b, byteOrder, hasRefundTxHash); err != nil {
+		return nil, err
+	}
+
+	if hasRefundTxHash {
+		if _, err := b.Write(state.RefundTxHash[:]); err != nil {
+			return nil, err
+		}
+	}
+
 	return b.Bytes(), nil
 }
 
@@ -142,5 +213,138 @@ func deserializeLoopEvent(value []byte) (*LoopEvent, error) {
 		return nil, err
 	}
 
+	// Events that were stored before the optional fields were added end
+	// after the costs.
+	if r.Len() == 0 {
+		return update, nil
+	}
+
+	var hasPaymentFailure bool
+	if err := binary.Read(r, byteOrder, &hasPaymentFailure); err != nil {
+		return nil, err
+	}
+
+	if hasPaymentFailure {
+		var failure PaymentFailure
+		err := binary.Read(r, byteOrder, &failure)
+		if err != nil {
+			return nil, err
+		}
+		update.PaymentFailure = &failure
+	}
+
+	var hasRefundTxHash bool
+	if err := binary.Read(r, byteOrder, &hasRefundTxHash); err != nil {
+		return nil, err
+	}
+
+	if hasRefundTxHash {
+		var refundTxHash chainhash.Hash
+		_, err := io.ReadFull(r, refundTxHash[:])
+		if err != nil {
+			return nil, err
+		}
+		update.RefundTxHash = &refundTxHash
+	}
+
 	return update, nil
 }
+
+// writeIdempotencyKey writes the idempotency key of a contract, followed by the
+// request digest if a key is set.
+func writeIdempotencyKey(w io.Writer, contract *SwapContract) error {
+	if err := wire.WriteVarString(w, 0, contract.IdempotencyKey); err != nil {
+		return err
+	}
+
+	if contract.IdempotencyKey == "" {
+		return nil
+	}
+
+	_, err := w.Write(contract.RequestDigest[:])
+	return err
+}
+
+// readIdempotencyKey reads the idempotency key and request digest of a
+// contract.
+func readIdempotencyKey(r io.Reader, contract *SwapContract) error {
+	var err error
+	contract.IdempotencyKey, err = wire.ReadVarString(r, 0)
+	if err != nil {
+		return err
+	}
+
+	if contract.IdempotencyKey == "" {
+		return nil
+	}
+
+	_, err = io.ReadFull(r, contract.RequestDigest[:])
+	return err
+}
+
+// writeLabels writes the label of a contract, followed by the number of
+// metadata pairs and the pairs in key order.
+func writeLabels(w io.Writer, contract *SwapContract) error {
+	if err := wire.WriteVarString(w, 0, contract.Label); err != nil {
+		return err
+	}
+
+	err := wire.WriteVarInt(w, 0, uint64(len(contract.Metadata)))
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(contract.Metadata))
+	for key := range contract.Metadata {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := wire.WriteVarString(w, 0, key); err != nil {
+			return err
+		}
+
+		err := wire.WriteVarString(w, 0, contract.Metadata[key])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readLabels reads the label and metadata pairs of a contract.
+func readLabels(r io.Reader, contract *SwapContract) error {
+	var err error
+	contract.Label, err = wire.ReadVarString(r, 0)
+	if err != nil {
+		return err
+	}
+
+	count, err := wire.ReadVarInt(r, 0)
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	contract.Metadata = make(map[string]string)
+	for i := uint64(0); i < count; i++ {
+		key, err := wire.ReadVarString(r, 0)
+		if err != nil {
+			return err
+		}
+
+		value, err := wire.ReadVarString(r, 0)
+		if err != nil {
+			return err
+		}
+
+		contract.Metadata[key] = value
+	}
+
+	return nil
+}
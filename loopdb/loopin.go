@@ -5,6 +5,12 @@ import (
 	"encoding/binary"
 	"fmt"
 	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 )
 
 // LoopInContract contains the data that is serialized to persistent storage for
@@ -23,6 +29,22 @@ type LoopInContract struct {
 	// ExternalHtlc specifies whether the htlc is published by an external
 	// source.
 	ExternalHtlc bool
+
+	// RefundAddr is the address that the htlc is refunded to if the swap
+	// times out. If nil, a new address of the lnd wallet is used.
+	RefundAddr btcutil.Address
+
+	// RefundConfTarget is the confirmation target of the refund tx. If
+	// zero, the default timeout tx confirmation target is used.
+	RefundConfTarget int32
+
+	// RefundFeeRate is the fee rate of the refund tx. If set, it takes
+	// precedence over RefundConfTarget.
+	RefundFeeRate chainfee.SatPerKWeight
+
+	// HtlcOutputType is the output type of the htlc that was agreed on
+	// with the server.
+	HtlcOutputType swap.HtlcOutputType
 }
 
 // LoopIn is a combination of the contract and the updates.
@@ -30,6 +52,9 @@ type LoopIn struct {
 	Loop
 
 	Contract *LoopInContract
+
+	// Deposits are all confirmed outputs that pay to the htlc script.
+	Deposits []*LoopInDeposit
 }
 
 // LastUpdateTime returns the last update time of this swap.
@@ -108,11 +133,54 @@ func serializeLoopInContract(swap *LoopInContract) (
 		return nil, err
 	}
 
+	// An empty refund address indicates that the lnd wallet is used.
+	var refundAddr string
+	if swap.RefundAddr != nil {
+		refundAddr = swap.RefundAddr.String()
+	}
+	if err := wire.WriteVarString(&b, 0, refundAddr); err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(&b, byteOrder, swap.RefundConfTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(&b, byteOrder, swap.RefundFeeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(&b, byteOrder, swap.HtlcOutputType)
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(&b, byteOrder, swap.HtlcVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wire.WriteVarString(&b, 0, swap.ServerID); err != nil {
+		return nil, err
+	}
+
+	if err := writeIdempotencyKey(&b, &swap.SwapContract); err != nil {
+		return nil, err
+	}
+
+	if err := writeLabels(&b, &swap.SwapContract); err != nil {
+		return nil, err
+	}
+
 	return b.Bytes(), nil
 }
 
 // deserializeLoopInContract deserializes the loop in contract from a byte slice.
-func deserializeLoopInContract(value []byte) (*LoopInContract, error) {
+func deserializeLoopInContract(value []byte, chainParams *chaincfg.Params) (
+	*LoopInContract, error) {
+
 	r := bytes.NewReader(value)
 
 	contract := LoopInContract{}
@@ -179,5 +247,51 @@ func deserializeLoopInContract(value []byte) (*LoopInContract, error) {
 		return nil, err
 	}
 
+	refundAddr, err := wire.ReadVarString(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if refundAddr != "" {
+		contract.RefundAddr, err = btcutil.DecodeAddress(
+			refundAddr, chainParams,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = binary.Read(r, byteOrder, &contract.RefundConfTarget)
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Read(r, byteOrder, &contract.RefundFeeRate)
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Read(r, byteOrder, &contract.HtlcOutputType)
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Read(r, byteOrder, &contract.HtlcVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	contract.ServerID, err = wire.ReadVarString(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := readIdempotencyKey(r, &contract.SwapContract); err != nil {
+		return nil, err
+	}
+
+	if err := readLabels(r, &contract.SwapContract); err != nil {
+		return nil, err
+	}
+
 	return &contract, nil
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 )
 
 // LoopOutContract contains the data that is serialized to persistent storage
@@ -51,6 +52,21 @@ type LoopOutContract struct {
 	// allow the server to delay the publication in exchange for possibly
 	// lower fees.
 	SwapPublicationDeadline time.Time
+
+	// SweepFeeRateCeiling is the maximum fee rate at which the htlc is
+	// swept while the swap is more than SweepSafetyMargin blocks away from
+	// its expiry. Zero means that the sweep fee rate is only limited by
+	// MaxMinerFee.
+	SweepFeeRateCeiling chainfee.SatPerKWeight
+
+	// SweepSafetyMargin is the number of blocks before the htlc expiry at
+	// which the sweep fee rate is escalated beyond SweepFeeRateCeiling.
+	SweepSafetyMargin int32
+
+	// SweepOutputs are the outputs of the sweep tx. If empty, the full
+	// swept value goes to DestAddr. Otherwise DestAddr is the address of
+	// the first output.
+	SweepOutputs []SweepOutput
 }
 
 // LoopOut is a combination of the contract and the updates.
@@ -171,6 +187,39 @@ func deserializeLoopOutContract(value []byte, chainParams *chaincfg.Params) (
 	}
 	contract.SwapPublicationDeadline = time.Unix(0, deadlineNano)
 
+	err = binary.Read(r, byteOrder, &contract.SweepFeeRateCeiling)
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Read(r, byteOrder, &contract.SweepSafetyMargin)
+	if err != nil {
+		return nil, err
+	}
+
+	contract.SweepOutputs, err = readSweepOutputs(r, chainParams)
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Read(r, byteOrder, &contract.HtlcVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	contract.ServerID, err = wire.ReadVarString(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := readIdempotencyKey(r, &contract.SwapContract); err != nil {
+		return nil, err
+	}
+
+	if err := readLabels(r, &contract.SwapContract); err != nil {
+		return nil, err
+	}
+
 	return &contract, nil
 }
 
@@ -261,5 +310,36 @@ func serializeLoopOutContract(swap *LoopOutContract) (
 		return nil, err
 	}
 
+	err = binary.Write(&b, byteOrder, swap.SweepFeeRateCeiling)
+	if err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(&b, byteOrder, swap.SweepSafetyMargin)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeSweepOutputs(&b, swap.SweepOutputs); err != nil {
+		return nil, err
+	}
+
+	err = binary.Write(&b, byteOrder, swap.HtlcVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := wire.WriteVarString(&b, 0, swap.ServerID); err != nil {
+		return nil, err
+	}
+
+	if err := writeIdempotencyKey(&b, &swap.SwapContract); err != nil {
+		return nil, err
+	}
+
+	if err := writeLabels(&b, &swap.SwapContract); err != nil {
+		return nil, err
+	}
+
 	return b.Bytes(), nil
 }
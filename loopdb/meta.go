@@ -35,6 +35,15 @@ var (
 	migrations = []migration{
 		migrateCosts,
 		migrateSwapPublicationDeadline,
+		migrateSweepStrategy,
+		migrateSweepOutputs,
+		migrateLoopInRefund,
+		migrateLoopInHtlcType,
+		migrateHtlcVersion,
+		migrateServerID,
+		migrateIdempotencyKey,
+		migrateLabels,
+		migrateSwapRequestServerID,
 	}
 
 	latestDBVersion = uint32(len(migrations))
@@ -0,0 +1,52 @@
+package loopdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/coreos/bbolt"
+)
+
+// migrateSweepStrategy migrates the database to v03, by adding the
+// SweepFeeRateCeiling and SweepSafetyMargin fields to loop out contracts.
+// Existing swaps don't have a fee rate ceiling, so both fields are zero.
+func migrateSweepStrategy(tx *bbolt.Tx, _ *chaincfg.Params) error {
+	rootBucket := tx.Bucket(loopOutBucketKey)
+	if rootBucket == nil {
+		return errors.New("bucket does not exist")
+	}
+
+	return rootBucket.ForEach(func(swapHash, v []byte) error {
+		// Only go into things that we know are sub-bucket
+		// keys.
+		if v != nil {
+			return nil
+		}
+
+		swapBucket := rootBucket.Bucket(swapHash)
+		if swapBucket == nil {
+			return fmt.Errorf("swap bucket %x not found",
+				swapHash)
+		}
+
+		contractBytes := swapBucket.Get(contractKey)
+		if contractBytes == nil {
+			return errors.New("contract not found")
+		}
+
+		// Append the zero fee rate ceiling (8 bytes) and safety margin
+		// (4 bytes) to the current contract serialization.
+		b := &bytes.Buffer{}
+		if _, err := b.Write(contractBytes); err != nil {
+			return err
+		}
+		var sweepStrategy [12]byte
+		if _, err := b.Write(sweepStrategy[:]); err != nil {
+			return err
+		}
+
+		return swapBucket.Put(contractKey, b.Bytes())
+	})
+}
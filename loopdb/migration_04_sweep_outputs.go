@@ -0,0 +1,52 @@
+package loopdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/coreos/bbolt"
+)
+
+// migrateSweepOutputs migrates the database to v04, by adding the
+// SweepOutputs field to loop out contracts. Existing swaps sweep to their
+// destination address only, so their list of sweep outputs is empty.
+func migrateSweepOutputs(tx *bbolt.Tx, _ *chaincfg.Params) error {
+	rootBucket := tx.Bucket(loopOutBucketKey)
+	if rootBucket == nil {
+		return errors.New("bucket does not exist")
+	}
+
+	return rootBucket.ForEach(func(swapHash, v []byte) error {
+		// Only go into things that we know are sub-bucket
+		// keys.
+		if v != nil {
+			return nil
+		}
+
+		swapBucket := rootBucket.Bucket(swapHash)
+		if swapBucket == nil {
+			return fmt.Errorf("swap bucket %x not found",
+				swapHash)
+		}
+
+		contractBytes := swapBucket.Get(contractKey)
+		if contractBytes == nil {
+			return errors.New("contract not found")
+		}
+
+		// Append the zero output count (4 bytes) to the current
+		// contract serialization.
+		b := &bytes.Buffer{}
+		if _, err := b.Write(contractBytes); err != nil {
+			return err
+		}
+		var outputCount [4]byte
+		if _, err := b.Write(outputCount[:]); err != nil {
+			return err
+		}
+
+		return swapBucket.Put(contractKey, b.Bytes())
+	})
+}
@@ -0,0 +1,54 @@
+package loopdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/coreos/bbolt"
+)
+
+// migrateLoopInRefund migrates the database to v05, by adding the RefundAddr,
+// RefundConfTarget and RefundFeeRate fields to loop in contracts. Existing
+// swaps refund to the lnd wallet with the default confirmation target, so the
+// address is empty and both fee fields are zero.
+func migrateLoopInRefund(tx *bbolt.Tx, _ *chaincfg.Params) error {
+	rootBucket := tx.Bucket(loopInBucketKey)
+	if rootBucket == nil {
+		return errors.New("bucket does not exist")
+	}
+
+	return rootBucket.ForEach(func(swapHash, v []byte) error {
+		// Only go into things that we know are sub-bucket
+		// keys.
+		if v != nil {
+			return nil
+		}
+
+		swapBucket := rootBucket.Bucket(swapHash)
+		if swapBucket == nil {
+			return fmt.Errorf("swap bucket %x not found",
+				swapHash)
+		}
+
+		contractBytes := swapBucket.Get(contractKey)
+		if contractBytes == nil {
+			return errors.New("contract not found")
+		}
+
+		// Append the empty refund address (1 byte var string length),
+		// the zero conf target (4 bytes) and the zero fee rate (8
+		// bytes) to the current contract serialization.
+		b := &bytes.Buffer{}
+		if _, err := b.Write(contractBytes); err != nil {
+			return err
+		}
+		var refund [13]byte
+		if _, err := b.Write(refund[:]); err != nil {
+			return err
+		}
+
+		return swapBucket.Put(contractKey, b.Bytes())
+	})
+}
@@ -0,0 +1,53 @@
+package loopdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/coreos/bbolt"
+	"github.com/lightninglabs/loop/swap"
+)
+
+// migrateLoopInHtlcType migrates the database to v06, by adding the
+// HtlcOutputType field to loop in contracts. All existing loop in swaps use a
+// nested segwit htlc.
+func migrateLoopInHtlcType(tx *bbolt.Tx, _ *chaincfg.Params) error {
+	rootBucket := tx.Bucket(loopInBucketKey)
+	if rootBucket == nil {
+		return errors.New("bucket does not exist")
+	}
+
+	return rootBucket.ForEach(func(swapHash, v []byte) error {
+		// Only go into things that we know are sub-bucket
+		// keys.
+		if v != nil {
+			return nil
+		}
+
+		swapBucket := rootBucket.Bucket(swapHash)
+		if swapBucket == nil {
+			return fmt.Errorf("swap bucket %x not found",
+				swapHash)
+		}
+
+		contractBytes := swapBucket.Get(contractKey)
+		if contractBytes == nil {
+			return errors.New("contract not found")
+		}
+
+		// Append the htlc output type (1 byte) to the current contract
+		// serialization.
+		b := &bytes.Buffer{}
+		if _, err := b.Write(contractBytes); err != nil {
+			return err
+		}
+		err := b.WriteByte(byte(swap.HtlcNP2WSH))
+		if err != nil {
+			return err
+		}
+
+		return swapBucket.Put(contractKey, b.Bytes())
+	})
+}
@@ -0,0 +1,60 @@
+package loopdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/coreos/bbolt"
+	"github.com/lightninglabs/loop/swap"
+)
+
+// migrateHtlcVersion migrates the database to v07, by adding the HtlcVersion
+// field to loop out and loop in contracts. All existing swaps use the original
+// htlc script.
+func migrateHtlcVersion(tx *bbolt.Tx, _ *chaincfg.Params) error {
+	for _, key := range [][]byte{loopOutBucketKey, loopInBucketKey} {
+		rootBucket := tx.Bucket(key)
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+
+		err := rootBucket.ForEach(func(swapHash, v []byte) error {
+			// Only go into things that we know are sub-bucket
+			// keys.
+			if v != nil {
+				return nil
+			}
+
+			swapBucket := rootBucket.Bucket(swapHash)
+			if swapBucket == nil {
+				return fmt.Errorf("swap bucket %x not found",
+					swapHash)
+			}
+
+			contractBytes := swapBucket.Get(contractKey)
+			if contractBytes == nil {
+				return errors.New("contract not found")
+			}
+
+			// Append the script version (1 byte) to the current
+			// contract serialization.
+			b := &bytes.Buffer{}
+			if _, err := b.Write(contractBytes); err != nil {
+				return err
+			}
+			err := b.WriteByte(byte(swap.HtlcScriptV1))
+			if err != nil {
+				return err
+			}
+
+			return swapBucket.Put(contractKey, b.Bytes())
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package loopdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coreos/bbolt"
+)
+
+// migrateServerID migrates the database to v08, by adding the ServerID field
+// to loop out and loop in contracts. All existing swaps were negotiated with
+// the default server, which is recorded as an empty id.
+func migrateServerID(tx *bbolt.Tx, _ *chaincfg.Params) error {
+	for _, key := range [][]byte{loopOutBucketKey, loopInBucketKey} {
+		rootBucket := tx.Bucket(key)
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+
+		err := rootBucket.ForEach(func(swapHash, v []byte) error {
+			// Only go into things that we know are sub-bucket
+			// keys.
+			if v != nil {
+				return nil
+			}
+
+			swapBucket := rootBucket.Bucket(swapHash)
+			if swapBucket == nil {
+				return fmt.Errorf("swap bucket %x not found",
+					swapHash)
+			}
+
+			contractBytes := swapBucket.Get(contractKey)
+			if contractBytes == nil {
+				return errors.New("contract not found")
+			}
+
+			// Append the empty server id to the current contract
+			// serialization.
+			b := &bytes.Buffer{}
+			if _, err := b.Write(contractBytes); err != nil {
+				return err
+			}
+			if err := wire.WriteVarString(b, 0, ""); err != nil {
+				return err
+			}
+
+			return swapBucket.Put(contractKey, b.Bytes())
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
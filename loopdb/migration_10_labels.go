@@ -0,0 +1,63 @@
+package loopdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coreos/bbolt"
+)
+
+// migrateLabels migrates the database to v10, by adding the Label and
+// Metadata fields to loop out and loop in contracts. None of the existing
+// swaps was labeled, which is recorded as an empty label without metadata
+// pairs.
+func migrateLabels(tx *bbolt.Tx, _ *chaincfg.Params) error {
+	for _, key := range [][]byte{loopOutBucketKey, loopInBucketKey} {
+		rootBucket := tx.Bucket(key)
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+
+		err := rootBucket.ForEach(func(swapHash, v []byte) error {
+			// Only go into things that we know are sub-bucket
+			// keys.
+			if v != nil {
+				return nil
+			}
+
+			swapBucket := rootBucket.Bucket(swapHash)
+			if swapBucket == nil {
+				return fmt.Errorf("swap bucket %x not found",
+					swapHash)
+			}
+
+			contractBytes := swapBucket.Get(contractKey)
+			if contractBytes == nil {
+				return errors.New("contract not found")
+			}
+
+			// Append the empty label and the zero metadata count
+			// to the current contract serialization.
+			b := &bytes.Buffer{}
+			if _, err := b.Write(contractBytes); err != nil {
+				return err
+			}
+			if err := wire.WriteVarString(b, 0, ""); err != nil {
+				return err
+			}
+			if err := wire.WriteVarInt(b, 0, 0); err != nil {
+				return err
+			}
+
+			return swapBucket.Put(contractKey, b.Bytes())
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
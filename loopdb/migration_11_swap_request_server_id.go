@@ -0,0 +1,67 @@
+package loopdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/coreos/bbolt"
+)
+
+// migrateSwapRequestServerID migrates the database to v11, by adding the
+// ServerID field to the swap requests of swap intents and recurring swaps.
+// The swap request is the last part of both records. Existing requests didn't
+// select a server, which is recorded as an empty server id.
+func migrateSwapRequestServerID(tx *bbolt.Tx, _ *chaincfg.Params) error {
+	buckets := []struct {
+		rootKey []byte
+		infoKey []byte
+	}{
+		{swapIntentsBucketKey, intentInfoKey},
+		{recurringSwapsBucketKey, recurringInfoKey},
+	}
+
+	for _, bucket := range buckets {
+		rootBucket := tx.Bucket(bucket.rootKey)
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+
+		err := rootBucket.ForEach(func(id, v []byte) error {
+			// Only go into things that we know are sub-bucket
+			// keys.
+			if v != nil {
+				return nil
+			}
+
+			subBucket := rootBucket.Bucket(id)
+			if subBucket == nil {
+				return fmt.Errorf("bucket %x not found", id)
+			}
+
+			infoBytes := subBucket.Get(bucket.infoKey)
+			if infoBytes == nil {
+				return errors.New("swap request not found")
+			}
+
+			// Append the empty server id to the current
+			// serialization.
+			b := &bytes.Buffer{}
+			if _, err := b.Write(infoBytes); err != nil {
+				return err
+			}
+			if err := wire.WriteVarString(b, 0, ""); err != nil {
+				return err
+			}
+
+			return subBucket.Put(bucket.infoKey, b.Bytes())
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package loopdb
+
+// PaymentType identifies one of the off-chain payments of a swap.
+type PaymentType uint8
+
+const (
+	// PaymentTypeSwap is the off-chain payment of the swap invoice.
+	PaymentTypeSwap PaymentType = 0
+
+	// PaymentTypePrepay is the off-chain payment of the prepay invoice.
+	PaymentTypePrepay PaymentType = 1
+)
+
+// String returns a string representation of the payment type.
+func (p PaymentType) String() string {
+	switch p {
+	case PaymentTypeSwap:
+		return "SwapPayment"
+
+	case PaymentTypePrepay:
+		return "Prepayment"
+
+	default:
+		return "Unknown"
+	}
+}
+
+// FailureReason describes why an off-chain payment failed.
+type FailureReason uint8
+
+const (
+	// FailureReasonUnknown indicates that the payment failed without a
+	// final payment state being reported by lnd, for example because of
+	// an rpc error.
+	FailureReasonUnknown FailureReason = 0
+
+	// FailureReasonTimeout indicates that lnd gave up on the payment
+	// because the payment timeout expired.
+	FailureReasonTimeout FailureReason = 1
+
+	// FailureReasonNoRoute indicates that no route to the destination
+	// could be found within the fee limit.
+	FailureReasonNoRoute FailureReason = 2
+
+	// FailureReasonError indicates that the payment failed with a
+	// non-recoverable error along the route.
+	FailureReasonError FailureReason = 3
+
+	// FailureReasonIncorrectPaymentDetails indicates that the destination
+	// rejected the payment, for example because it doesn't know the
+	// payment hash.
+	FailureReasonIncorrectPaymentDetails FailureReason = 4
+
+	// FailureReasonInsufficientBalance indicates that our node doesn't
+	// have enough outbound balance to send the payment.
+	FailureReasonInsufficientBalance FailureReason = 5
+)
+
+// String returns a string representation of the failure reason.
+func (f FailureReason) String() string {
+	switch f {
+	case FailureReasonUnknown:
+		return "Unknown"
+
+	case FailureReasonTimeout:
+		return "Timeout"
+
+	case FailureReasonNoRoute:
+		return "NoRoute"
+
+	case FailureReasonError:
+		return "Error"
+
+	case FailureReasonIncorrectPaymentDetails:
+		return "IncorrectPaymentDetails"
+
+	case FailureReasonInsufficientBalance:
+		return "InsufficientBalance"
+
+	default:
+		return "Invalid"
+	}
+}
+
+// PaymentFailure describes the failure of one of the off-chain payments of a
+// swap.
+type PaymentFailure struct {
+	// Payment is the payment that failed.
+	Payment PaymentType
+
+	// Reason is the reason why the payment failed.
+	Reason FailureReason
+}
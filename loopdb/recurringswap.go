@@ -0,0 +1,261 @@
+package loopdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// ErrRecurringSwapNotFound is returned when a recurring swap is not found in
+// the store.
+var ErrRecurringSwapNotFound = errors.New("recurring swap not found")
+
+// RecurringID uniquely identifies a recurring swap.
+type RecurringID [32]byte
+
+// String returns the hex encoded recurring swap id.
+func (i RecurringID) String() string {
+	return hex.EncodeToString(i[:])
+}
+
+// RecurringState indicates whether a recurring swap still initiates swaps.
+type RecurringState uint8
+
+const (
+	// RecurringStateActive indicates that the recurring swap initiates a
+	// swap on every interval.
+	RecurringStateActive RecurringState = 0
+
+	// RecurringStateCanceled indicates that the recurring swap was
+	// canceled by the user and won't initiate swaps anymore.
+	RecurringStateCanceled RecurringState = 1
+)
+
+// String returns a string representation of the recurring swap state.
+func (s RecurringState) String() string {
+	switch s {
+	case RecurringStateActive:
+		return "Active"
+
+	case RecurringStateCanceled:
+		return "Canceled"
+
+	default:
+		return "Unknown"
+	}
+}
+
+// RecurringRun records a single attempt of a recurring swap to initiate a
+// swap.
+type RecurringRun struct {
+	// ScheduledTime is the start of the interval that this run belongs
+	// to.
+	ScheduledTime time.Time
+
+	// Time is the time at which the swap was initiated.
+	Time time.Time
+
+	// Amount is the amount of the swap that was initiated.
+	Amount btcutil.Amount
+
+	// SwapHash is the hash of the swap that was initiated. It is only set
+	// if the swap was initiated successfully.
+	SwapHash lntypes.Hash
+
+	// Error describes why the swap could not be initiated. It is empty if
+	// the run succeeded.
+	Error string
+}
+
+// Failed returns whether the run failed to initiate its swap.
+func (r *RecurringRun) Failed() bool {
+	return r.Error != ""
+}
+
+// RecurringSwap describes a swap that is initiated repeatedly at a fixed
+// interval.
+type RecurringSwap struct {
+	// ID is the unique identifier of this recurring swap.
+	ID RecurringID
+
+	// CreationTime is the time at which the recurring swap was created.
+	CreationTime time.Time
+
+	// StartTime is the time of the first run. Subsequent runs are
+	// scheduled at multiples of Interval after StartTime.
+	StartTime time.Time
+
+	// Interval is the time between two runs.
+	Interval time.Duration
+
+	// Period is the sliding window over which the amounts of successful
+	// runs are limited to MaxPerPeriod.
+	Period time.Duration
+
+	// MaxPerPeriod is the maximum total amount that is swapped within
+	// Period. Zero disables the limit.
+	MaxPerPeriod btcutil.Amount
+
+	// SwapRequest contains the parameters of the swap that is initiated
+	// on every run.
+	SwapRequest
+
+	// State is the current state of the recurring swap.
+	State RecurringState
+
+	// Runs contains all runs in the order in which they were recorded.
+	Runs []*RecurringRun
+}
+
+// LastRun returns the most recent run of the recurring swap or nil if it
+// didn't run yet.
+func (r *RecurringSwap) LastRun() *RecurringRun {
+	if len(r.Runs) == 0 {
+		return nil
+	}
+
+	return r.Runs[len(r.Runs)-1]
+}
+
+// serializeRecurringSwap serializes the static part of a recurring swap. The
+// state and runs are stored separately.
+func serializeRecurringSwap(recurring *RecurringSwap) ([]byte, error) {
+	var b bytes.Buffer
+
+	if err := binary.Write(&b, byteOrder, recurring.CreationTime.UnixNano()); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, recurring.StartTime.UnixNano()); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, int64(recurring.Interval)); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, int64(recurring.Period)); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, recurring.MaxPerPeriod); err != nil {
+		return nil, err
+	}
+
+	if err := writeSwapRequest(&b, &recurring.SwapRequest); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// deserializeRecurringSwap deserializes the static part of a recurring swap.
+func deserializeRecurringSwap(value []byte, chainParams *chaincfg.Params) (
+	*RecurringSwap, error) {
+
+	r := bytes.NewReader(value)
+
+	recurring := RecurringSwap{}
+
+	var unixNano int64
+	if err := binary.Read(r, byteOrder, &unixNano); err != nil {
+		return nil, err
+	}
+	recurring.CreationTime = time.Unix(0, unixNano)
+
+	if err := binary.Read(r, byteOrder, &unixNano); err != nil {
+		return nil, err
+	}
+	recurring.StartTime = time.Unix(0, unixNano)
+
+	var duration int64
+	if err := binary.Read(r, byteOrder, &duration); err != nil {
+		return nil, err
+	}
+	recurring.Interval = time.Duration(duration)
+
+	if err := binary.Read(r, byteOrder, &duration); err != nil {
+		return nil, err
+	}
+	recurring.Period = time.Duration(duration)
+
+	if err := binary.Read(r, byteOrder, &recurring.MaxPerPeriod); err != nil {
+		return nil, err
+	}
+
+	req, err := readSwapRequest(r, chainParams)
+	if err != nil {
+		return nil, err
+	}
+	recurring.SwapRequest = *req
+
+	return &recurring, nil
+}
+
+// serializeRecurringRun serializes a run of a recurring swap.
+func serializeRecurringRun(run *RecurringRun) ([]byte, error) {
+	var b bytes.Buffer
+
+	if err := binary.Write(&b, byteOrder, run.ScheduledTime.UnixNano()); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, run.Time.UnixNano()); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, run.Amount); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, run.SwapHash); err != nil {
+		return nil, err
+	}
+
+	if err := wire.WriteVarString(&b, 0, run.Error); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// deserializeRecurringRun deserializes a run of a recurring swap.
+func deserializeRecurringRun(value []byte) (*RecurringRun, error) {
+	r := bytes.NewReader(value)
+
+	run := RecurringRun{}
+
+	var unixNano int64
+	if err := binary.Read(r, byteOrder, &unixNano); err != nil {
+		return nil, err
+	}
+	run.ScheduledTime = time.Unix(0, unixNano)
+
+	if err := binary.Read(r, byteOrder, &unixNano); err != nil {
+		return nil, err
+	}
+	run.Time = time.Unix(0, unixNano)
+
+	if err := binary.Read(r, byteOrder, &run.Amount); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, byteOrder, &run.SwapHash); err != nil {
+		return nil, err
+	}
+
+	var err error
+	run.Error, err = wire.ReadVarString(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
@@ -0,0 +1,131 @@
+package loopdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+)
+
+// ServerSwapState is the state of a swap as reported by the server.
+type ServerSwapState uint8
+
+const (
+	// ServerSwapAccepted indicates that the server accepted the swap and
+	// is going to follow up on it.
+	ServerSwapAccepted ServerSwapState = 0
+
+	// ServerHtlcDelayed indicates that the server delays the publication
+	// of the loop out htlc, for example because it batches htlcs until the
+	// swap publication deadline.
+	ServerHtlcDelayed ServerSwapState = 1
+
+	// ServerHtlcPublished indicates that the server published the loop out
+	// htlc.
+	ServerHtlcPublished ServerSwapState = 2
+
+	// ServerSwapRejected indicates that the server won't continue with the
+	// swap.
+	ServerSwapRejected ServerSwapState = 3
+)
+
+// String returns a string representation of the server swap state.
+func (s ServerSwapState) String() string {
+	switch s {
+	case ServerSwapAccepted:
+		return "Accepted"
+
+	case ServerHtlcDelayed:
+		return "HtlcDelayed"
+
+	case ServerHtlcPublished:
+		return "HtlcPublished"
+
+	case ServerSwapRejected:
+		return "Rejected"
+
+	default:
+		return "Unknown"
+	}
+}
+
+// ServerSwapUpdate is an update of the state of a swap that the server pushed
+// to the client.
+type ServerSwapUpdate struct {
+	// Time is the time at which the update was received.
+	Time time.Time
+
+	// State is the state of the swap on the server side.
+	State ServerSwapState
+
+	// Reason is an optional human readable explanation of the update.
+	Reason string
+
+	// ExpectedPublication is the time at which the server expects to
+	// publish the htlc. It is only set for ServerHtlcDelayed updates.
+	ExpectedPublication time.Time
+}
+
+// serializeServerUpdate serializes a server update of a swap.
+func serializeServerUpdate(update *ServerSwapUpdate) ([]byte, error) {
+	var b bytes.Buffer
+
+	err := binary.Write(&b, byteOrder, update.Time.UnixNano())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, update.State); err != nil {
+		return nil, err
+	}
+
+	if err := wire.WriteVarString(&b, 0, update.Reason); err != nil {
+		return nil, err
+	}
+
+	// The zero time is stored as zero, so that it is read back as the
+	// zero time rather than the unix epoch.
+	var expectedPublication int64
+	if !update.ExpectedPublication.IsZero() {
+		expectedPublication = update.ExpectedPublication.UnixNano()
+	}
+	err = binary.Write(&b, byteOrder, expectedPublication)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// deserializeServerUpdate deserializes a server update of a swap.
+func deserializeServerUpdate(value []byte) (*ServerSwapUpdate, error) {
+	update := &ServerSwapUpdate{}
+
+	r := bytes.NewReader(value)
+
+	var unixNano int64
+	if err := binary.Read(r, byteOrder, &unixNano); err != nil {
+		return nil, err
+	}
+	update.Time = time.Unix(0, unixNano)
+
+	if err := binary.Read(r, byteOrder, &update.State); err != nil {
+		return nil, err
+	}
+
+	var err error
+	update.Reason, err = wire.ReadVarString(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, byteOrder, &unixNano); err != nil {
+		return nil, err
+	}
+	if unixNano != 0 {
+		update.ExpectedPublication = time.Unix(0, unixNano)
+	}
+
+	return update, nil
+}
@@ -1,6 +1,7 @@
 package loopdb
 
 import (
+	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -34,6 +35,114 @@ var (
 	// maps: swapHash -> swapBucket
 	loopInBucketKey = []byte("loop-in")
 
+	// swapGroupsBucketKey is a bucket that contains all swap groups. Each
+	// group links the swaps that were created from a single split swap
+	// request. This bucket is keyed by the group id, and leads to a nested
+	// sub-bucket that houses information for that group.
+	//
+	// maps: groupID -> groupBucket
+	swapGroupsBucketKey = []byte("swap-groups")
+
+	// groupInfoKey is the key that stores the serialized static group
+	// data. It is nested within the sub-bucket for each group.
+	//
+	// path: swapGroupsBucket -> groupBucket[id] -> groupInfoKey
+	//
+	// value: time || type || amount
+	groupInfoKey = []byte("info")
+
+	// groupSwapsBucketKey is a bucket that contains the hashes of all
+	// swaps that belong to a group. This list only ever grows.
+	//
+	// path: swapGroupsBucket -> groupBucket[id] -> groupSwapsBucket
+	//
+	// maps: sequenceNumber -> swapHash
+	groupSwapsBucketKey = []byte("swaps")
+
+	// swapIntentsBucketKey is a bucket that contains all swap intents.
+	// This bucket is keyed by the intent id, and leads to a nested
+	// sub-bucket that houses information for that intent.
+	//
+	// maps: intentID -> intentBucket
+	swapIntentsBucketKey = []byte("swap-intents")
+
+	// intentInfoKey is the key that stores the serialized static intent
+	// data. It is nested within the sub-bucket for each intent.
+	//
+	// path: swapIntentsBucket -> intentBucket[id] -> intentInfoKey
+	//
+	// value: time || trigger || request
+	intentInfoKey = []byte("info")
+
+	// intentStateKey is the key that stores the current state of an
+	// intent. It is nested within the sub-bucket for each intent and
+	// overwritten on every update.
+	//
+	// path: swapIntentsBucket -> intentBucket[id] -> intentStateKey
+	//
+	// value: state || time || swapHash || error
+	intentStateKey = []byte("state")
+
+	// recurringSwapsBucketKey is a bucket that contains all recurring
+	// swaps. This bucket is keyed by the recurring swap id, and leads to
+	// a nested sub-bucket that houses information for that recurring swap.
+	//
+	// maps: recurringID -> recurringBucket
+	recurringSwapsBucketKey = []byte("recurring-swaps")
+
+	// recurringInfoKey is the key that stores the serialized static data
+	// of a recurring swap. It is nested within the sub-bucket for each
+	// recurring swap.
+	//
+	// path: recurringSwapsBucket -> recurringBucket[id] -> recurringInfoKey
+	//
+	// value: time || startTime || interval || period || maxPerPeriod ||
+	//        request
+	recurringInfoKey = []byte("info")
+
+	// recurringStateKey is the key that stores the current state of a
+	// recurring swap. It is nested within the sub-bucket for each
+	// recurring swap and overwritten on every update.
+	//
+	// path: recurringSwapsBucket -> recurringBucket[id] -> recurringStateKey
+	//
+	// value: state
+	recurringStateKey = []byte("state")
+
+	// recurringRunsBucketKey is a bucket that contains all runs of a
+	// recurring swap, including the failed ones. This list only ever
+	// grows.
+	//
+	// path: recurringSwapsBucket -> recurringBucket[id] -> runsBucket
+	//
+	// maps: sequenceNumber -> scheduledTime || time || amount ||
+	//       swapHash || error
+	recurringRunsBucketKey = []byte("runs")
+
+	// destDescriptorsBucketKey is a bucket that contains the active
+	// destination descriptor and the derivation indexes of all
+	// descriptors that were ever registered.
+	destDescriptorsBucketKey = []byte("dest-descriptors")
+
+	// activeDescriptorKey is the key that stores the descriptor that
+	// destination addresses are currently derived from. It is absent if
+	// no descriptor is registered.
+	//
+	// path: destDescriptorsBucket -> activeDescriptorKey
+	//
+	// value: descriptor
+	activeDescriptorKey = []byte("active")
+
+	// descriptorIndexesBucketKey is a bucket that contains the next
+	// derivation index of every descriptor that was ever registered, so
+	// that a descriptor that is registered again doesn't hand out the
+	// same addresses.
+	//
+	// path: destDescriptorsBucket -> descriptorIndexesBucket
+	//
+	// maps: descriptor -> nextIndex
+	descriptorIndexesBucketKey = []byte("indexes")
+
 	// updatesBucketKey is a bucket that contains all updates pertaining to
 	// a swap. This is a sub-bucket of the swap bucket for a particular
 	// swap. This list only ever grows.
@@ -51,6 +160,27 @@ var (
 	// value: time || rawSwapState
 	contractKey = []byte("contract")
 
+	// depositsBucketKey is a bucket that contains the htlc deposits of a
+	// loop in swap. This is a sub-bucket of the swap bucket that is
+	// created when the first deposit is stored.
+	//
+	// path: loopInBucket -> swapBucket[hash] -> depositsBucket
+	//
+	// maps: outpoint -> value || accepted || state || spendTxHash
+	depositsBucketKey = []byte("deposits")
+
+	// serverUpdatesBucketKey is a bucket that contains the updates that
+	// the server pushed for a swap. It is kept apart from the updates
+	// bucket, because server updates arrive independently of the state
+	// transitions of the swap. This is a sub-bucket of the swap bucket
+	// that is created when the first server update is stored.
+	//
+	// path: loopInBucket/loopOutBucket -> swapBucket[hash] ->
+	//	serverUpdatesBucket
+	//
+	// maps: updateNumber -> time || state || reason || expectedPublication
+	serverUpdatesBucketKey = []byte("server-updates")
+
 	byteOrder = binary.BigEndian
 
 	keyLength = 33
@@ -127,6 +257,39 @@ func NewBoltSwapStore(dbPath string, chainParams *chaincfg.Params) (
 			return err
 		}
 
+		// Swap groups don't alter the existing data, so we create the
+		// bucket without bumping the db version either.
+		_, err = tx.CreateBucketIfNotExists(swapGroupsBucketKey)
+		if err != nil {
+			return err
+		}
+
+		// The same goes for swap intents and recurring swaps.
+		_, err = tx.CreateBucketIfNotExists(swapIntentsBucketKey)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.CreateBucketIfNotExists(recurringSwapsBucketKey)
+		if err != nil {
+			return err
+		}
+
+		// Finally, we create the bucket for destination descriptors.
+		destBucket, err := tx.CreateBucketIfNotExists(
+			destDescriptorsBucketKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = destBucket.CreateBucketIfNotExists(
+			descriptorIndexesBucketKey,
+		)
+		if err != nil {
+			return err
+		}
+
 		return nil
 	})
 	if err != nil {
@@ -147,7 +310,7 @@ func NewBoltSwapStore(dbPath string, chainParams *chaincfg.Params) (
 }
 
 func (s *boltSwapStore) fetchSwaps(bucketKey []byte,
-	callback func([]byte, Loop) error) error {
+	callback func([]byte, Loop, *bbolt.Bucket) error) error {
 
 	return s.db.View(func(tx *bbolt.Tx) error {
 		// First, we'll grab our main loop in bucket key.
@@ -203,15 +366,21 @@ func (s *boltSwapStore) fetchSwaps(bucketKey []byte,
 				return err
 			}
 
+			serverUpdates, err := fetchServerUpdates(swapBucket)
+			if err != nil {
+				return err
+			}
+
 			var hash lntypes.Hash
 			copy(hash[:], swapHash)
 
 			loop := Loop{
-				Hash:   hash,
-				Events: updates,
+				Hash:          hash,
+				Events:        updates,
+				ServerUpdates: serverUpdates,
 			}
 
-			return callback(contractBytes, loop)
+			return callback(contractBytes, loop, swapBucket)
 		})
 	})
 }
@@ -223,7 +392,7 @@ func (s *boltSwapStore) FetchLoopOutSwaps() ([]*LoopOut, error) {
 	var swaps []*LoopOut
 
 	err := s.fetchSwaps(loopOutBucketKey,
-		func(contractBytes []byte, loop Loop) error {
+		func(contractBytes []byte, loop Loop, _ *bbolt.Bucket) error {
 			contract, err := deserializeLoopOutContract(
 				contractBytes, s.chainParams,
 			)
@@ -253,17 +422,25 @@ func (s *boltSwapStore) FetchLoopInSwaps() ([]*LoopIn, error) {
 	var swaps []*LoopIn
 
 	err := s.fetchSwaps(loopInBucketKey,
-		func(contractBytes []byte, loop Loop) error {
+		func(contractBytes []byte, loop Loop,
+			swapBucket *bbolt.Bucket) error {
+
 			contract, err := deserializeLoopInContract(
-				contractBytes,
+				contractBytes, s.chainParams,
 			)
 			if err != nil {
 				return err
 			}
 
+			deposits, err := fetchDeposits(swapBucket)
+			if err != nil {
+				return err
+			}
+
 			swaps = append(swaps, &LoopIn{
 				Contract: contract,
 				Loop:     loop,
+				Deposits: deposits,
 			})
 
 			return nil
@@ -276,6 +453,58 @@ func (s *boltSwapStore) FetchLoopInSwaps() ([]*LoopIn, error) {
 	return swaps, nil
 }
 
+// fetchDeposits returns the htlc deposits that are stored in the given loop
+// in swap bucket.
+func fetchDeposits(swapBucket *bbolt.Bucket) ([]*LoopInDeposit, error) {
+	depositsBucket := swapBucket.Bucket(depositsBucketKey)
+	if depositsBucket == nil {
+		return nil, nil
+	}
+
+	var deposits []*LoopInDeposit
+	err := depositsBucket.ForEach(func(k, v []byte) error {
+		deposit, err := deserializeDeposit(k, v)
+		if err != nil {
+			return err
+		}
+
+		deposits = append(deposits, deposit)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return deposits, nil
+}
+
+// fetchServerUpdates returns the server updates that are stored in the given
+// swap bucket.
+func fetchServerUpdates(swapBucket *bbolt.Bucket) ([]*ServerSwapUpdate,
+	error) {
+
+	updatesBucket := swapBucket.Bucket(serverUpdatesBucketKey)
+	if updatesBucket == nil {
+		return nil, nil
+	}
+
+	var updates []*ServerSwapUpdate
+	err := updatesBucket.ForEach(func(k, v []byte) error {
+		update, err := deserializeServerUpdate(v)
+		if err != nil {
+			return err
+		}
+
+		updates = append(updates, update)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updates, nil
+}
+
 // createLoop creates a swap in the store. It requires that the contract is
 // already serialized to be able to use this function for both in and out swaps.
 func (s *boltSwapStore) createLoop(bucketKey []byte, hash lntypes.Hash,
@@ -416,6 +645,685 @@ func (s *boltSwapStore) UpdateLoopIn(hash lntypes.Hash, time time.Time,
 	return s.updateLoop(loopInBucketKey, hash, time, state)
 }
 
+// StoreLoopInDeposit adds or updates an htlc deposit of a loop in swap.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) StoreLoopInDeposit(hash lntypes.Hash,
+	deposit *LoopInDeposit) error {
+
+	value, err := serializeDeposit(deposit)
+	if err != nil {
+		return err
+	}
+
+	var key bytes.Buffer
+	if err := writeOutpoint(&key, &deposit.Outpoint); err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		rootBucket := tx.Bucket(loopInBucketKey)
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+		swapBucket := rootBucket.Bucket(hash[:])
+		if swapBucket == nil {
+			return errors.New("swap not found")
+		}
+
+		depositsBucket, err := swapBucket.CreateBucketIfNotExists(
+			depositsBucketKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		return depositsBucket.Put(key.Bytes(), value)
+	})
+}
+
+// StoreServerUpdate appends an update that the server pushed for the swap
+// with the given hash. The swap can be either a loop out or a loop in swap.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) StoreServerUpdate(hash lntypes.Hash,
+	update *ServerSwapUpdate) error {
+
+	value, err := serializeServerUpdate(update)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		var swapBucket *bbolt.Bucket
+		for _, key := range [][]byte{loopOutBucketKey, loopInBucketKey} {
+			rootBucket := tx.Bucket(key)
+			if rootBucket == nil {
+				return errors.New("bucket does not exist")
+			}
+
+			swapBucket = rootBucket.Bucket(hash[:])
+			if swapBucket != nil {
+				break
+			}
+		}
+		if swapBucket == nil {
+			return errors.New("swap not found")
+		}
+
+		updatesBucket, err := swapBucket.CreateBucketIfNotExists(
+			serverUpdatesBucketKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		id, err := updatesBucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		return updatesBucket.Put(itob(id), value)
+	})
+}
+
+// CreateSwapGroup adds a new swap group to the store. Swaps are linked to the
+// group afterwards using AddSwapToGroup.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) CreateSwapGroup(group *SwapGroup) error {
+	groupBytes, err := serializeSwapGroup(group)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		rootBucket, err := tx.CreateBucketIfNotExists(
+			swapGroupsBucketKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		// We don't want to override an existing group.
+		if rootBucket.Bucket(group.ID[:]) != nil {
+			return fmt.Errorf("swap group %v already exists",
+				group.ID)
+		}
+
+		groupBucket, err := rootBucket.CreateBucket(group.ID[:])
+		if err != nil {
+			return err
+		}
+
+		err = groupBucket.Put(groupInfoKey, groupBytes)
+		if err != nil {
+			return err
+		}
+
+		swapsBucket, err := groupBucket.CreateBucket(
+			groupSwapsBucketKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		// Store any swaps that are already part of the group.
+		for _, hash := range group.Swaps {
+			id, err := swapsBucket.NextSequence()
+			if err != nil {
+				return err
+			}
+
+			err = swapsBucket.Put(itob(id), hash[:])
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// AddSwapToGroup links the swap with the given hash to an existing swap group.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) AddSwapToGroup(id GroupID, hash lntypes.Hash) error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		rootBucket := tx.Bucket(swapGroupsBucketKey)
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+		groupBucket := rootBucket.Bucket(id[:])
+		if groupBucket == nil {
+			return errors.New("swap group not found")
+		}
+		swapsBucket := groupBucket.Bucket(groupSwapsBucketKey)
+		if swapsBucket == nil {
+			return errors.New("group swaps bucket not found")
+		}
+
+		seq, err := swapsBucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		return swapsBucket.Put(itob(seq), hash[:])
+	})
+}
+
+// FetchSwapGroups returns all swap groups currently in the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) FetchSwapGroups() ([]*SwapGroup, error) {
+	var groups []*SwapGroup
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		rootBucket := tx.Bucket(swapGroupsBucketKey)
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+
+		return rootBucket.ForEach(func(groupID, v []byte) error {
+			// Only go into things that we know are sub-bucket
+			// keys.
+			if v != nil {
+				return nil
+			}
+
+			groupBucket := rootBucket.Bucket(groupID)
+			if groupBucket == nil {
+				return fmt.Errorf("group bucket %x not found",
+					groupID)
+			}
+
+			groupBytes := groupBucket.Get(groupInfoKey)
+			if groupBytes == nil {
+				return errors.New("group info not found")
+			}
+
+			group, err := deserializeSwapGroup(groupBytes)
+			if err != nil {
+				return err
+			}
+			copy(group.ID[:], groupID)
+
+			swapsBucket := groupBucket.Bucket(groupSwapsBucketKey)
+			if swapsBucket == nil {
+				return errors.New("group swaps bucket not " +
+					"found")
+			}
+
+			err = swapsBucket.ForEach(func(_, v []byte) error {
+				var hash lntypes.Hash
+				copy(hash[:], v)
+				group.Swaps = append(group.Swaps, hash)
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			groups = append(groups, group)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// CreateSwapIntent adds a new pending swap intent to the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) CreateSwapIntent(intent *SwapIntent) error {
+	intentBytes, err := serializeSwapIntent(intent)
+	if err != nil {
+		return err
+	}
+
+	stateBytes, err := serializeIntentUpdate(&intent.IntentUpdate)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		rootBucket, err := tx.CreateBucketIfNotExists(
+			swapIntentsBucketKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		// We don't want to override an existing intent.
+		if rootBucket.Bucket(intent.ID[:]) != nil {
+			return fmt.Errorf("swap intent %v already exists",
+				intent.ID)
+		}
+
+		intentBucket, err := rootBucket.CreateBucket(intent.ID[:])
+		if err != nil {
+			return err
+		}
+
+		err = intentBucket.Put(intentInfoKey, intentBytes)
+		if err != nil {
+			return err
+		}
+
+		return intentBucket.Put(intentStateKey, stateBytes)
+	})
+}
+
+// UpdateSwapIntent replaces the state of an existing swap intent.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) UpdateSwapIntent(id IntentID,
+	update *IntentUpdate) error {
+
+	stateBytes, err := serializeIntentUpdate(update)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		rootBucket := tx.Bucket(swapIntentsBucketKey)
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+		intentBucket := rootBucket.Bucket(id[:])
+		if intentBucket == nil {
+			return ErrSwapIntentNotFound
+		}
+
+		return intentBucket.Put(intentStateKey, stateBytes)
+	})
+}
+
+// FetchSwapIntents returns all swap intents currently in the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) FetchSwapIntents() ([]*SwapIntent, error) {
+	var intents []*SwapIntent
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		rootBucket := tx.Bucket(swapIntentsBucketKey)
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+
+		return rootBucket.ForEach(func(intentID, v []byte) error {
+			// Only go into things that we know are sub-bucket
+			// keys.
+			if v != nil {
+				return nil
+			}
+
+			intentBucket := rootBucket.Bucket(intentID)
+			if intentBucket == nil {
+				return fmt.Errorf("intent bucket %x not found",
+					intentID)
+			}
+
+			intentBytes := intentBucket.Get(intentInfoKey)
+			if intentBytes == nil {
+				return errors.New("intent info not found")
+			}
+
+			intent, err := deserializeSwapIntent(
+				intentBytes, s.chainParams,
+			)
+			if err != nil {
+				return err
+			}
+			copy(intent.ID[:], intentID)
+
+			stateBytes := intentBucket.Get(intentStateKey)
+			if stateBytes == nil {
+				return errors.New("intent state not found")
+			}
+
+			update, err := deserializeIntentUpdate(stateBytes)
+			if err != nil {
+				return err
+			}
+			intent.IntentUpdate = *update
+
+			intents = append(intents, intent)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return intents, nil
+}
+
+// CreateRecurringSwap adds a new recurring swap to the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) CreateRecurringSwap(recurring *RecurringSwap) error {
+	recurringBytes, err := serializeRecurringSwap(recurring)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		rootBucket, err := tx.CreateBucketIfNotExists(
+			recurringSwapsBucketKey,
+		)
+		if err != nil {
+			return err
+		}
+
+		// We don't want to override an existing recurring swap.
+		if rootBucket.Bucket(recurring.ID[:]) != nil {
+			return fmt.Errorf("recurring swap %v already exists",
+				recurring.ID)
+		}
+
+		recurringBucket, err := rootBucket.CreateBucket(
+			recurring.ID[:],
+		)
+		if err != nil {
+			return err
+		}
+
+		err = recurringBucket.Put(recurringInfoKey, recurringBytes)
+		if err != nil {
+			return err
+		}
+
+		err = recurringBucket.Put(
+			recurringStateKey, []byte{byte(recurring.State)},
+		)
+		if err != nil {
+			return err
+		}
+
+		_, err = recurringBucket.CreateBucket(recurringRunsBucketKey)
+		return err
+	})
+}
+
+// UpdateRecurringSwap replaces the state of an existing recurring swap.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) UpdateRecurringSwap(id RecurringID,
+	state RecurringState) error {
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		rootBucket := tx.Bucket(recurringSwapsBucketKey)
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+		recurringBucket := rootBucket.Bucket(id[:])
+		if recurringBucket == nil {
+			return ErrRecurringSwapNotFound
+		}
+
+		return recurringBucket.Put(
+			recurringStateKey, []byte{byte(state)},
+		)
+	})
+}
+
+// AddRecurringRun records a run of an existing recurring swap.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) AddRecurringRun(id RecurringID,
+	run *RecurringRun) error {
+
+	runBytes, err := serializeRecurringRun(run)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		rootBucket := tx.Bucket(recurringSwapsBucketKey)
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+		recurringBucket := rootBucket.Bucket(id[:])
+		if recurringBucket == nil {
+			return ErrRecurringSwapNotFound
+		}
+		runsBucket := recurringBucket.Bucket(recurringRunsBucketKey)
+		if runsBucket == nil {
+			return errors.New("recurring runs bucket not found")
+		}
+
+		seq, err := runsBucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		return runsBucket.Put(itob(seq), runBytes)
+	})
+}
+
+// FetchRecurringSwaps returns all recurring swaps currently in the store,
+// including their runs.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) FetchRecurringSwaps() ([]*RecurringSwap, error) {
+	var recurringSwaps []*RecurringSwap
+
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		rootBucket := tx.Bucket(recurringSwapsBucketKey)
+		if rootBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+
+		return rootBucket.ForEach(func(recurringID, v []byte) error {
+			// Only go into things that we know are sub-bucket
+			// keys.
+			if v != nil {
+				return nil
+			}
+
+			recurringBucket := rootBucket.Bucket(recurringID)
+			if recurringBucket == nil {
+				return fmt.Errorf("recurring swap bucket %x "+
+					"not found", recurringID)
+			}
+
+			recurringBytes := recurringBucket.Get(recurringInfoKey)
+			if recurringBytes == nil {
+				return errors.New("recurring swap info not " +
+					"found")
+			}
+
+			recurring, err := deserializeRecurringSwap(
+				recurringBytes, s.chainParams,
+			)
+			if err != nil {
+				return err
+			}
+			copy(recurring.ID[:], recurringID)
+
+			stateBytes := recurringBucket.Get(recurringStateKey)
+			if len(stateBytes) != 1 {
+				return errors.New("recurring swap state not " +
+					"found")
+			}
+			recurring.State = RecurringState(stateBytes[0])
+
+			runsBucket := recurringBucket.Bucket(
+				recurringRunsBucketKey,
+			)
+			if runsBucket == nil {
+				return errors.New("recurring runs bucket not " +
+					"found")
+			}
+
+			err = runsBucket.ForEach(func(_, v []byte) error {
+				run, err := deserializeRecurringRun(v)
+				if err != nil {
+					return err
+				}
+				recurring.Runs = append(recurring.Runs, run)
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			recurringSwaps = append(recurringSwaps, recurring)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return recurringSwaps, nil
+}
+
+// RegisterDestDescriptor makes the given descriptor the source of loop out
+// destination addresses. A descriptor that was registered before resumes at
+// its previous derivation index.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) RegisterDestDescriptor(descriptor string) (
+	*DestDescriptor, error) {
+
+	if descriptor == "" {
+		return nil, errors.New("empty descriptor")
+	}
+
+	dest := &DestDescriptor{
+		Descriptor: descriptor,
+	}
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		destBucket := tx.Bucket(destDescriptorsBucketKey)
+		if destBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+		indexBucket := destBucket.Bucket(descriptorIndexesBucketKey)
+		if indexBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+
+		var indexBytes [4]byte
+		if index := indexBucket.Get([]byte(descriptor)); index != nil {
+			dest.NextIndex = byteOrder.Uint32(index)
+		}
+		byteOrder.PutUint32(indexBytes[:], dest.NextIndex)
+
+		err := indexBucket.Put([]byte(descriptor), indexBytes[:])
+		if err != nil {
+			return err
+		}
+
+		return destBucket.Put(activeDescriptorKey, []byte(descriptor))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// UnregisterDestDescriptor removes the registered destination descriptor. Its
+// derivation index is retained in case it is registered again.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) UnregisterDestDescriptor() error {
+	return s.db.Update(func(tx *bbolt.Tx) error {
+		destBucket := tx.Bucket(destDescriptorsBucketKey)
+		if destBucket == nil {
+			return errors.New("bucket does not exist")
+		}
+		if destBucket.Get(activeDescriptorKey) == nil {
+			return ErrNoDestDescriptor
+		}
+
+		return destBucket.Delete(activeDescriptorKey)
+	})
+}
+
+// FetchDestDescriptor returns the registered destination descriptor.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) FetchDestDescriptor() (*DestDescriptor, error) {
+	var dest *DestDescriptor
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		var err error
+		dest, err = fetchDestDescriptor(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// NextDestIndex reserves the next derivation index of the registered
+// destination descriptor. The returned descriptor contains the reserved
+// index, which is never returned again.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *boltSwapStore) NextDestIndex() (*DestDescriptor, error) {
+	var dest *DestDescriptor
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		var err error
+		dest, err = fetchDestDescriptor(tx)
+		if err != nil {
+			return err
+		}
+
+		indexBucket := tx.Bucket(destDescriptorsBucketKey).Bucket(
+			descriptorIndexesBucketKey,
+		)
+
+		var indexBytes [4]byte
+		byteOrder.PutUint32(indexBytes[:], dest.NextIndex+1)
+
+		return indexBucket.Put([]byte(dest.Descriptor), indexBytes[:])
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return dest, nil
+}
+
+// fetchDestDescriptor reads the registered destination descriptor and its
+// next derivation index.
+func fetchDestDescriptor(tx *bbolt.Tx) (*DestDescriptor, error) {
+	destBucket := tx.Bucket(destDescriptorsBucketKey)
+	if destBucket == nil {
+		return nil, errors.New("bucket does not exist")
+	}
+	indexBucket := destBucket.Bucket(descriptorIndexesBucketKey)
+	if indexBucket == nil {
+		return nil, errors.New("bucket does not exist")
+	}
+
+	descriptor := destBucket.Get(activeDescriptorKey)
+	if descriptor == nil {
+		return nil, ErrNoDestDescriptor
+	}
+
+	index := indexBucket.Get(descriptor)
+	if index == nil {
+		return nil, fmt.Errorf("index of descriptor %s not found",
+			descriptor)
+	}
+
+	return &DestDescriptor{
+		Descriptor: string(descriptor),
+		NextIndex:  byteOrder.Uint32(index),
+	}, nil
+}
+
 // Close closes the underlying database.
 //
 // NOTE: Part of the loopdb.SwapStore interface.
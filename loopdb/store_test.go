@@ -10,7 +10,10 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/coreos/bbolt"
+	"github.com/lightninglabs/loop/swap"
 	"github.com/lightninglabs/loop/test"
 	"github.com/lightningnetwork/lnd/lntypes"
 )
@@ -81,6 +84,13 @@ func TestLoopOutStore(t *testing.T) {
 			// Convert to/from unix to remove timezone, so that it
 			// doesn't interfere with DeepEqual.
 			InitiationTime: time.Unix(0, initiationTime.UnixNano()),
+			IdempotencyKey: "retry-1",
+			RequestDigest:  sha256.Sum256([]byte("request")),
+			Label:          "treasury",
+			Metadata: map[string]string{
+				"unit":  "payments",
+				"batch": "7",
+			},
 		},
 		MaxPrepayRoutingFee:     40,
 		PrepayInvoice:           "prepayinvoice",
@@ -89,6 +99,12 @@ func TestLoopOutStore(t *testing.T) {
 		MaxSwapRoutingFee:       30,
 		SweepConfTarget:         2,
 		SwapPublicationDeadline: time.Unix(0, initiationTime.UnixNano()),
+		SweepFeeRateCeiling:     2500,
+		SweepSafetyMargin:       18,
+		SweepOutputs: []SweepOutput{
+			{Addr: destAddr, Weight: 7},
+			{Addr: destAddr, Amount: 30},
+		},
 	}
 
 	// checkSwap is a test helper function that'll assert the state of a
@@ -213,10 +229,15 @@ func TestLoopInStore(t *testing.T) {
 			// Convert to/from unix to remove timezone, so that it
 			// doesn't interfere with DeepEqual.
 			InitiationTime: time.Unix(0, initiationTime.UnixNano()),
+			ServerID:       "alt",
 		},
-		HtlcConfTarget: 2,
-		LoopInChannel:  &loopInChannel,
-		ExternalHtlc:   true,
+		HtlcConfTarget:   2,
+		LoopInChannel:    &loopInChannel,
+		ExternalHtlc:     true,
+		RefundAddr:       test.GetDestAddr(t, 0),
+		RefundConfTarget: 6,
+		RefundFeeRate:    2500,
+		HtlcOutputType:   swap.HtlcNP2WSH,
 	}
 
 	// checkSwap is a test helper function that'll assert the state of a
@@ -284,6 +305,63 @@ func TestLoopInStore(t *testing.T) {
 	}
 	checkSwap(StateFailInsufficientValue)
 
+	// Store two deposits and update the first one, which overwrites it.
+	spendTxHash := chainhash.Hash{2}
+	deposits := []*LoopInDeposit{
+		{
+			Outpoint:   wire.OutPoint{Hash: chainhash.Hash{1}},
+			Value:      100,
+			ConfHeight: 610,
+			Accepted:   true,
+		},
+		{
+			Outpoint: wire.OutPoint{
+				Hash:  chainhash.Hash{1},
+				Index: 1,
+			},
+			Value:      50,
+			ConfHeight: 610,
+		},
+	}
+	for _, deposit := range deposits {
+		err := store.StoreLoopInDeposit(hash, deposit)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deposits[0].State = DepositSwept
+	deposits[0].SpendTxHash = &spendTxHash
+	if err := store.StoreLoopInDeposit(hash, deposits[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	// Store server updates, with and without expected publication time.
+	serverUpdates := []*ServerSwapUpdate{
+		{
+			Time:  time.Unix(0, testTime.UnixNano()),
+			State: ServerSwapAccepted,
+		},
+		{
+			Time:   time.Unix(0, testTime.UnixNano()),
+			State:  ServerHtlcDelayed,
+			Reason: "batching",
+			ExpectedPublication: time.Unix(
+				0, initiationTime.UnixNano(),
+			),
+		},
+	}
+	for _, update := range serverUpdates {
+		if err := store.StoreServerUpdate(hash, update); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err = store.StoreServerUpdate(lntypes.Hash{1}, serverUpdates[0])
+	if err == nil {
+		t.Fatal("expected error on server update of unknown swap")
+	}
+
 	if err := store.Close(); err != nil {
 		t.Fatal(err)
 	}
@@ -295,6 +373,485 @@ func TestLoopInStore(t *testing.T) {
 		t.Fatal(err)
 	}
 	checkSwap(StateFailInsufficientValue)
+
+	swaps, err = store.FetchLoopInSwaps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(swaps[0].Deposits, deposits) {
+		t.Fatalf("unexpected deposits %v", swaps[0].Deposits)
+	}
+	if !reflect.DeepEqual(swaps[0].ServerUpdates, serverUpdates) {
+		t.Fatalf("unexpected server updates %v",
+			swaps[0].ServerUpdates)
+	}
+}
+
+// TestPaymentFailureEvent tests that a payment failure is stored with the
+// swap event and that events without failure are still read back correctly.
+func TestPaymentFailureEvent(t *testing.T) {
+	failure := &PaymentFailure{
+		Payment: PaymentTypePrepay,
+		Reason:  FailureReasonNoRoute,
+	}
+
+	for _, expected := range []*PaymentFailure{nil, failure} {
+		value, err := serializeLoopEvent(testTime, SwapStateData{
+			State:          StateFailOffchainPayments,
+			PaymentFailure: expected,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		event, err := deserializeLoopEvent(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(event.PaymentFailure, expected) {
+			t.Fatalf("expected failure %v, got %v", expected,
+				event.PaymentFailure)
+		}
+	}
+}
+
+// TestRefundTxHashEvent tests that the refund tx hash is stored with the swap
+// event, with and without a payment failure.
+func TestRefundTxHashEvent(t *testing.T) {
+	failure := &PaymentFailure{
+		Payment: PaymentTypeSwap,
+		Reason:  FailureReasonTimeout,
+	}
+	refundTxHash := &chainhash.Hash{1, 2, 3}
+
+	for _, expectedFailure := range []*PaymentFailure{nil, failure} {
+		value, err := serializeLoopEvent(testTime, SwapStateData{
+			State:          StateFailTimeout,
+			PaymentFailure: expectedFailure,
+			RefundTxHash:   refundTxHash,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		event, err := deserializeLoopEvent(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(event.PaymentFailure, expectedFailure) {
+			t.Fatalf("expected failure %v, got %v",
+				expectedFailure, event.PaymentFailure)
+		}
+		if event.RefundTxHash == nil ||
+			*event.RefundTxHash != *refundTxHash {
+
+			t.Fatalf("expected refund tx %v, got %v", refundTxHash,
+				event.RefundTxHash)
+		}
+	}
+}
+
+// TestLegacyLoopEvent tests that events that were stored before the optional
+// fields were added are still read.
+func TestLegacyLoopEvent(t *testing.T) {
+	value, err := serializeLoopEvent(testTime, SwapStateData{
+		State: StateSuccess,
+		Cost: SwapCost{
+			Server: 1,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Strip the flags of the optional fields, which leaves the time, the
+	// state and the costs.
+	legacyValue := value[:len(value)-2]
+
+	event, err := deserializeLoopEvent(legacyValue)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if event.State != StateSuccess || event.Cost.Server != 1 {
+		t.Fatalf("unexpected event %v", event)
+	}
+	if event.PaymentFailure != nil || event.RefundTxHash != nil {
+		t.Fatalf("unexpected optional fields %v", event)
+	}
+}
+
+// TestSwapGroupStore tests storing and retrieving swap groups.
+func TestSwapGroupStore(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDirName)
+
+	store, err := NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First, verify that an empty database has no groups.
+	groups, err := store.FetchSwapGroups()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(groups) != 0 {
+		t.Fatal("expected empty store")
+	}
+
+	hash1 := lntypes.Hash(sha256.Sum256([]byte{1}))
+	hash2 := lntypes.Hash(sha256.Sum256([]byte{2}))
+
+	group := SwapGroup{
+		ID:              GroupID{1, 2, 3},
+		Type:            swap.TypeOut,
+		AmountRequested: 2000000,
+
+		// Convert to/from unix to remove timezone, so that it doesn't
+		// interfere with DeepEqual.
+		InitiationTime: time.Unix(0, testTime.UnixNano()),
+		Swaps:          []lntypes.Hash{hash1},
+	}
+
+	// checkGroup is a test helper function that'll assert the state of the
+	// stored group.
+	checkGroup := func(expectedSwaps ...lntypes.Hash) {
+		t.Helper()
+
+		groups, err := store.FetchSwapGroups()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(groups) != 1 {
+			t.Fatal("expected group in store")
+		}
+
+		expected := group
+		expected.Swaps = expectedSwaps
+		if !reflect.DeepEqual(groups[0], &expected) {
+			t.Fatalf("expected group %v, got %v", expected,
+				groups[0])
+		}
+	}
+
+	if err := store.CreateSwapGroup(&group); err != nil {
+		t.Fatal(err)
+	}
+	checkGroup(hash1)
+
+	// Creating the same group again should fail.
+	if err := store.CreateSwapGroup(&group); err == nil {
+		t.Fatal("expected error on storing duplicate group")
+	}
+
+	// Adding to an unknown group should fail as well.
+	if err := store.AddSwapToGroup(GroupID{9}, hash2); err == nil {
+		t.Fatal("expected error on adding to unknown group")
+	}
+
+	if err := store.AddSwapToGroup(group.ID, hash2); err != nil {
+		t.Fatal(err)
+	}
+	checkGroup(hash1, hash2)
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// If we re-open the same store, then the group should still be there.
+	store, err = NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGroup(hash1, hash2)
+}
+
+// TestSwapIntentStore tests the storage of swap intents and their state.
+func TestSwapIntentStore(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDirName)
+
+	store, err := NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First, verify that an empty database has no intents.
+	intents, err := store.FetchSwapIntents()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(intents) != 0 {
+		t.Fatal("expected empty store")
+	}
+
+	destAddr := test.GetDestAddr(t, 0)
+	channel := uint64(123)
+
+	// Convert to/from unix to remove timezone, so that it doesn't
+	// interfere with DeepEqual.
+	creationTime := time.Unix(0, testTime.UnixNano())
+
+	loopOutIntent := SwapIntent{
+		ID:           IntentID{1, 2, 3},
+		CreationTime: creationTime,
+		Trigger: IntentTrigger{
+			NotBefore:      creationTime.Add(time.Hour),
+			MaxFeeRate:     2500,
+			FeeConfTarget:  6,
+			DeadlineHeight: 700,
+		},
+		SwapRequest: SwapRequest{
+			Type:                swap.TypeOut,
+			Amount:              2000000,
+			DestAddr:            destAddr,
+			MaxSwapFee:          5000,
+			MaxMinerFee:         4000,
+			MaxPrepayAmount:     1000,
+			MaxSwapRoutingFee:   300,
+			MaxPrepayRoutingFee: 20,
+			ConfTarget:          2,
+			Channel:             &channel,
+			ServerID:            "server",
+		},
+		IntentUpdate: IntentUpdate{
+			State: IntentStatePending,
+			Time:  creationTime,
+		},
+	}
+
+	loopInIntent := SwapIntent{
+		ID:           IntentID{4, 5, 6},
+		CreationTime: creationTime,
+		Trigger: IntentTrigger{
+			NotBeforeHeight: 650,
+		},
+		SwapRequest: SwapRequest{
+			Type:         swap.TypeIn,
+			Amount:       500000,
+			MaxSwapFee:   1000,
+			MaxMinerFee:  2000,
+			ConfTarget:   6,
+			ExternalHtlc: true,
+		},
+		IntentUpdate: IntentUpdate{
+			State: IntentStatePending,
+			Time:  creationTime,
+		},
+	}
+
+	// checkIntents is a test helper function that'll assert the stored
+	// intents.
+	checkIntents := func(expected ...*SwapIntent) {
+		t.Helper()
+
+		intents, err := store.FetchSwapIntents()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(intents) != len(expected) {
+			t.Fatalf("expected %v intents, got %v", len(expected),
+				len(intents))
+		}
+
+		for i, intent := range intents {
+			if !reflect.DeepEqual(intent, expected[i]) {
+				t.Fatalf("expected intent %v, got %v",
+					expected[i], intent)
+			}
+		}
+	}
+
+	if err := store.CreateSwapIntent(&loopOutIntent); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CreateSwapIntent(&loopInIntent); err != nil {
+		t.Fatal(err)
+	}
+	checkIntents(&loopOutIntent, &loopInIntent)
+
+	// Creating the same intent again should fail.
+	if err := store.CreateSwapIntent(&loopOutIntent); err == nil {
+		t.Fatal("expected error on storing duplicate intent")
+	}
+
+	// Updating an unknown intent should fail as well.
+	err = store.UpdateSwapIntent(IntentID{9}, &IntentUpdate{})
+	if err != ErrSwapIntentNotFound {
+		t.Fatalf("expected intent not found, got %v", err)
+	}
+
+	loopOutIntent.IntentUpdate = IntentUpdate{
+		State:    IntentStateLaunched,
+		Time:     creationTime.Add(2 * time.Hour),
+		SwapHash: lntypes.Hash{1},
+	}
+	err = store.UpdateSwapIntent(
+		loopOutIntent.ID, &loopOutIntent.IntentUpdate,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loopInIntent.IntentUpdate = IntentUpdate{
+		State: IntentStateFailed,
+		Time:  creationTime.Add(3 * time.Hour),
+		Error: "swap amount too low",
+	}
+	err = store.UpdateSwapIntent(
+		loopInIntent.ID, &loopInIntent.IntentUpdate,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIntents(&loopOutIntent, &loopInIntent)
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// If we re-open the same store, then the intents should still be
+	// there.
+	store, err = NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkIntents(&loopOutIntent, &loopInIntent)
+}
+
+// TestRecurringSwapStore tests the storage of recurring swaps and their runs.
+func TestRecurringSwapStore(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDirName)
+
+	store, err := NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// First, verify that an empty database has no recurring swaps.
+	recurringSwaps, err := store.FetchRecurringSwaps()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recurringSwaps) != 0 {
+		t.Fatal("expected empty store")
+	}
+
+	// Convert to/from unix to remove timezone, so that it doesn't
+	// interfere with DeepEqual.
+	creationTime := time.Unix(0, testTime.UnixNano())
+
+	recurring := RecurringSwap{
+		ID:           RecurringID{1, 2, 3},
+		CreationTime: creationTime,
+		StartTime:    creationTime.Add(time.Hour),
+		Interval:     24 * time.Hour,
+		Period:       7 * 24 * time.Hour,
+		MaxPerPeriod: 5000000,
+		SwapRequest: SwapRequest{
+			Type:        swap.TypeOut,
+			Amount:      1000000,
+			DestAddr:    test.GetDestAddr(t, 0),
+			MaxSwapFee:  5000,
+			MaxMinerFee: 4000,
+			ConfTarget:  6,
+			ServerID:    "server",
+		},
+		State: RecurringStateActive,
+	}
+
+	// checkRecurring is a test helper function that'll assert the stored
+	// recurring swap.
+	checkRecurring := func() {
+		t.Helper()
+
+		recurringSwaps, err := store.FetchRecurringSwaps()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(recurringSwaps) != 1 {
+			t.Fatalf("expected 1 recurring swap, got %v",
+				len(recurringSwaps))
+		}
+
+		if !reflect.DeepEqual(recurringSwaps[0], &recurring) {
+			t.Fatalf("expected recurring swap %v, got %v",
+				&recurring, recurringSwaps[0])
+		}
+	}
+
+	if err := store.CreateRecurringSwap(&recurring); err != nil {
+		t.Fatal(err)
+	}
+	checkRecurring()
+
+	// Creating the same recurring swap again should fail.
+	if err := store.CreateRecurringSwap(&recurring); err == nil {
+		t.Fatal("expected error on storing duplicate recurring swap")
+	}
+
+	// Adding a run to an unknown recurring swap should fail as well.
+	err = store.AddRecurringRun(RecurringID{9}, &RecurringRun{})
+	if err != ErrRecurringSwapNotFound {
+		t.Fatalf("expected recurring swap not found, got %v", err)
+	}
+
+	// Both successful and failed runs are recorded in order.
+	runs := []*RecurringRun{
+		{
+			ScheduledTime: recurring.StartTime,
+			Time:          recurring.StartTime.Add(time.Minute),
+			Amount:        1000000,
+			SwapHash:      lntypes.Hash{1},
+		},
+		{
+			ScheduledTime: recurring.StartTime.Add(24 * time.Hour),
+			Time:          recurring.StartTime.Add(25 * time.Hour),
+			Amount:        1000000,
+			Error:         "swap amount too low",
+		},
+	}
+	for _, run := range runs {
+		if err := store.AddRecurringRun(recurring.ID, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	recurring.Runs = runs
+	checkRecurring()
+
+	err = store.UpdateRecurringSwap(recurring.ID, RecurringStateCanceled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	recurring.State = RecurringStateCanceled
+	checkRecurring()
+
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// If we re-open the same store, then the recurring swap should still
+	// be there.
+	store, err = NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRecurring()
 }
 
 // TestVersionNew tests that a new database is initialized with the current
@@ -365,3 +922,90 @@ func createVersionZeroDb(t *testing.T, dbPath string) {
 		t.Fatal(err)
 	}
 }
+
+// TestDestDescriptorStore tests the registration of destination descriptors
+// and the reservation of their derivation indexes.
+func TestDestDescriptorStore(t *testing.T) {
+	tempDirName, err := ioutil.TempDir("", "clientstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDirName)
+
+	store, err := NewBoltSwapStore(tempDirName, &chaincfg.MainNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// An empty database has no descriptor registered.
+	_, err = store.FetchDestDescriptor()
+	if err != ErrNoDestDescriptor {
+		t.Fatalf("expected no descriptor, got %v", err)
+	}
+	_, err = store.NextDestIndex()
+	if err != ErrNoDestDescriptor {
+		t.Fatalf("expected no descriptor, got %v", err)
+	}
+
+	// assertNextIndex reserves the next index and asserts its value.
+	assertNextIndex := func(descriptor string, expected uint32) {
+		t.Helper()
+
+		dest, err := store.NextDestIndex()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dest.Descriptor != descriptor || dest.NextIndex != expected {
+			t.Fatalf("expected %v at index %v, got %v at %v",
+				descriptor, expected, dest.Descriptor,
+				dest.NextIndex)
+		}
+	}
+
+	dest, err := store.RegisterDestDescriptor("first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dest.NextIndex != 0 {
+		t.Fatalf("expected index 0, got %v", dest.NextIndex)
+	}
+	assertNextIndex("first", 0)
+	assertNextIndex("first", 1)
+
+	// Registering another descriptor replaces the first one.
+	if _, err := store.RegisterDestDescriptor("second"); err != nil {
+		t.Fatal(err)
+	}
+	assertNextIndex("second", 0)
+
+	// Registering the first descriptor again resumes at its next index.
+	dest, err = store.RegisterDestDescriptor("first")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dest.NextIndex != 2 {
+		t.Fatalf("expected index 2, got %v", dest.NextIndex)
+	}
+	assertNextIndex("first", 2)
+
+	fetched, err := store.FetchDestDescriptor()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetched.Descriptor != "first" || fetched.NextIndex != 3 {
+		t.Fatalf("unexpected descriptor %v", fetched)
+	}
+
+	// After unregistering, no descriptor is registered anymore.
+	if err := store.UnregisterDestDescriptor(); err != nil {
+		t.Fatal(err)
+	}
+	_, err = store.FetchDestDescriptor()
+	if err != ErrNoDestDescriptor {
+		t.Fatalf("expected no descriptor, got %v", err)
+	}
+	err = store.UnregisterDestDescriptor()
+	if err != ErrNoDestDescriptor {
+		t.Fatalf("expected no descriptor, got %v", err)
+	}
+}
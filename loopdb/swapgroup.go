@@ -0,0 +1,86 @@
+package loopdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// GroupID uniquely identifies a group of swaps that were created from a single
+// split swap request.
+type GroupID [32]byte
+
+// String returns the hex encoded group id.
+func (g GroupID) String() string {
+	return hex.EncodeToString(g[:])
+}
+
+// SwapGroup links a number of swaps that together make up a single swap
+// request that was split into multiple server-sized parts.
+type SwapGroup struct {
+	// ID is the unique identifier of this group.
+	ID GroupID
+
+	// Type is the type of all swaps in this group.
+	Type swap.Type
+
+	// AmountRequested is the total amount that was requested to be
+	// swapped. It is the sum of the amounts of all swaps in the group.
+	AmountRequested btcutil.Amount
+
+	// InitiationTime is the time at which the group was created.
+	InitiationTime time.Time
+
+	// Swaps contains the hashes of the swaps that belong to this group, in
+	// the order in which they were initiated.
+	Swaps []lntypes.Hash
+}
+
+// serializeSwapGroup serializes the static part of a swap group. The member
+// swaps are stored separately, so that they can be appended one by one.
+func serializeSwapGroup(group *SwapGroup) ([]byte, error) {
+	var b bytes.Buffer
+
+	if err := binary.Write(&b, byteOrder, group.InitiationTime.UnixNano()); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, group.Type); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, group.AmountRequested); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// deserializeSwapGroup deserializes the static part of a swap group.
+func deserializeSwapGroup(value []byte) (*SwapGroup, error) {
+	r := bytes.NewReader(value)
+
+	group := SwapGroup{}
+
+	var unixNano int64
+	if err := binary.Read(r, byteOrder, &unixNano); err != nil {
+		return nil, err
+	}
+	group.InitiationTime = time.Unix(0, unixNano)
+
+	if err := binary.Read(r, byteOrder, &group.Type); err != nil {
+		return nil, err
+	}
+
+	err := binary.Read(r, byteOrder, &group.AmountRequested)
+	if err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
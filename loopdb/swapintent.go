@@ -0,0 +1,270 @@
+package loopdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+)
+
+// ErrSwapIntentNotFound is returned when a swap intent is not found in the
+// store.
+var ErrSwapIntentNotFound = errors.New("swap intent not found")
+
+// IntentID uniquely identifies a swap intent.
+type IntentID [32]byte
+
+// String returns the hex encoded intent id.
+func (i IntentID) String() string {
+	return hex.EncodeToString(i[:])
+}
+
+// IntentState indicates the current state of a swap intent.
+type IntentState uint8
+
+const (
+	// IntentStatePending indicates that the intent is waiting for its
+	// trigger conditions to be met.
+	IntentStatePending IntentState = 0
+
+	// IntentStateLaunched indicates that the trigger conditions were met
+	// and the swap was initiated.
+	IntentStateLaunched IntentState = 1
+
+	// IntentStateCanceled indicates that the intent was canceled by the
+	// user before it triggered.
+	IntentStateCanceled IntentState = 2
+
+	// IntentStateFailed indicates that the trigger conditions were met,
+	// but the swap could not be initiated.
+	IntentStateFailed IntentState = 3
+)
+
+// String returns a string representation of the intent state.
+func (s IntentState) String() string {
+	switch s {
+	case IntentStatePending:
+		return "Pending"
+
+	case IntentStateLaunched:
+		return "Launched"
+
+	case IntentStateCanceled:
+		return "Canceled"
+
+	case IntentStateFailed:
+		return "Failed"
+
+	default:
+		return "Unknown"
+	}
+}
+
+// IntentTrigger describes the conditions under which a swap intent launches
+// its swap. All conditions that are set need to be met.
+type IntentTrigger struct {
+	// NotBefore is the earliest time at which the swap may be launched.
+	// A zero time disables this condition.
+	NotBefore time.Time
+
+	// NotBeforeHeight is the earliest block height at which the swap may
+	// be launched. Zero disables this condition.
+	NotBeforeHeight int32
+
+	// MaxFeeRate is the fee rate that the on-chain fee estimate for
+	// FeeConfTarget needs to drop to for the swap to be launched. Zero
+	// disables this condition.
+	MaxFeeRate chainfee.SatPerKWeight
+
+	// FeeConfTarget is the confirmation target of the fee estimate that is
+	// compared against MaxFeeRate.
+	FeeConfTarget int32
+
+	// DeadlineHeight is the block height at which the swap is launched
+	// regardless of the fee estimate. Zero means that the swap waits for
+	// the fee estimate indefinitely.
+	DeadlineHeight int32
+}
+
+// IntentUpdate contains the mutable state of a swap intent.
+type IntentUpdate struct {
+	// State is the state of the intent.
+	State IntentState
+
+	// Time is the time at which the state was last changed.
+	Time time.Time
+
+	// SwapHash is the hash of the swap that was launched by this intent.
+	// It is only set in the launched state.
+	SwapHash lntypes.Hash
+
+	// Error describes why the swap could not be launched. It is only set
+	// in the failed state.
+	Error string
+}
+
+// SwapIntent describes a swap that is initiated once its trigger conditions
+// are met.
+type SwapIntent struct {
+	// ID is the unique identifier of this intent.
+	ID IntentID
+
+	// CreationTime is the time at which the intent was created.
+	CreationTime time.Time
+
+	// Trigger contains the conditions for launching the swap.
+	Trigger IntentTrigger
+
+	// SwapRequest contains the parameters of the swap that is launched.
+	SwapRequest
+
+	// IntentUpdate contains the current state of the intent.
+	IntentUpdate
+}
+
+// serializeSwapIntent serializes the static part of a swap intent. The state
+// of the intent is stored separately, so that it can be updated.
+func serializeSwapIntent(intent *SwapIntent) ([]byte, error) {
+	var b bytes.Buffer
+
+	if err := binary.Write(&b, byteOrder, intent.CreationTime.UnixNano()); err != nil {
+		return nil, err
+	}
+
+	trigger := &intent.Trigger
+	var notBefore int64
+	if !trigger.NotBefore.IsZero() {
+		notBefore = trigger.NotBefore.UnixNano()
+	}
+	if err := binary.Write(&b, byteOrder, notBefore); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, trigger.NotBeforeHeight); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, trigger.MaxFeeRate); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, trigger.FeeConfTarget); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, trigger.DeadlineHeight); err != nil {
+		return nil, err
+	}
+
+	if err := writeSwapRequest(&b, &intent.SwapRequest); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// deserializeSwapIntent deserializes the static part of a swap intent.
+func deserializeSwapIntent(value []byte, chainParams *chaincfg.Params) (
+	*SwapIntent, error) {
+
+	r := bytes.NewReader(value)
+
+	intent := SwapIntent{}
+
+	var unixNano int64
+	if err := binary.Read(r, byteOrder, &unixNano); err != nil {
+		return nil, err
+	}
+	intent.CreationTime = time.Unix(0, unixNano)
+
+	trigger := &intent.Trigger
+	var notBefore int64
+	if err := binary.Read(r, byteOrder, &notBefore); err != nil {
+		return nil, err
+	}
+	if notBefore != 0 {
+		trigger.NotBefore = time.Unix(0, notBefore)
+	}
+
+	if err := binary.Read(r, byteOrder, &trigger.NotBeforeHeight); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, byteOrder, &trigger.MaxFeeRate); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, byteOrder, &trigger.FeeConfTarget); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, byteOrder, &trigger.DeadlineHeight); err != nil {
+		return nil, err
+	}
+
+	req, err := readSwapRequest(r, chainParams)
+	if err != nil {
+		return nil, err
+	}
+	intent.SwapRequest = *req
+
+	return &intent, nil
+}
+
+// serializeIntentUpdate serializes the state of a swap intent.
+func serializeIntentUpdate(update *IntentUpdate) ([]byte, error) {
+	var b bytes.Buffer
+
+	if err := binary.Write(&b, byteOrder, update.State); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, update.Time.UnixNano()); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Write(&b, byteOrder, update.SwapHash); err != nil {
+		return nil, err
+	}
+
+	if err := wire.WriteVarString(&b, 0, update.Error); err != nil {
+		return nil, err
+	}
+
+	return b.Bytes(), nil
+}
+
+// deserializeIntentUpdate deserializes the state of a swap intent.
+func deserializeIntentUpdate(value []byte) (*IntentUpdate, error) {
+	r := bytes.NewReader(value)
+
+	update := IntentUpdate{}
+
+	if err := binary.Read(r, byteOrder, &update.State); err != nil {
+		return nil, err
+	}
+
+	var unixNano int64
+	if err := binary.Read(r, byteOrder, &unixNano); err != nil {
+		return nil, err
+	}
+	update.Time = time.Unix(0, unixNano)
+
+	if err := binary.Read(r, byteOrder, &update.SwapHash); err != nil {
+		return nil, err
+	}
+
+	var err error
+	update.Error, err = wire.ReadVarString(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &update, nil
+}
@@ -0,0 +1,162 @@
+package loopdb
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/swap"
+)
+
+// SwapRequest holds the parameters of a swap that the daemon initiates by
+// itself at a later time.
+type SwapRequest struct {
+	// Type is the type of swap to initiate.
+	Type swap.Type
+
+	// Amount is the requested swap amount.
+	Amount btcutil.Amount
+
+	// DestAddr is the destination address of a loop out swap. It is nil
+	// for loop in swaps.
+	DestAddr btcutil.Address
+
+	// MaxSwapFee is the maximum swap fee that may be paid to the server.
+	MaxSwapFee btcutil.Amount
+
+	// MaxMinerFee is the maximum on-chain fee of the swap.
+	MaxMinerFee btcutil.Amount
+
+	// MaxPrepayAmount is the maximum prepay amount of a loop out swap.
+	MaxPrepayAmount btcutil.Amount
+
+	// MaxSwapRoutingFee is the maximum routing fee of the swap payment of
+	// a loop out swap.
+	MaxSwapRoutingFee btcutil.Amount
+
+	// MaxPrepayRoutingFee is the maximum routing fee of the prepayment of
+	// a loop out swap.
+	MaxPrepayRoutingFee btcutil.Amount
+
+	// ConfTarget is the sweep confirmation target of a loop out swap or
+	// the htlc confirmation target of a loop in swap.
+	ConfTarget int32
+
+	// Channel optionally specifies the channel to loop out of or to loop
+	// in to.
+	Channel *uint64
+
+	// ExternalHtlc indicates that the htlc of a loop in swap is published
+	// by an external source.
+	ExternalHtlc bool
+
+	// ServerID optionally selects the swap server. If not set, the server
+	// that offers the best quote is selected when the swap is initiated.
+	ServerID string
+}
+
+// writeSwapRequest serializes a swap request to the given writer.
+func writeSwapRequest(w io.Writer, req *SwapRequest) error {
+	if err := binary.Write(w, byteOrder, req.Type); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, byteOrder, req.Amount); err != nil {
+		return err
+	}
+
+	var addr string
+	if req.DestAddr != nil {
+		addr = req.DestAddr.String()
+	}
+	if err := wire.WriteVarString(w, 0, addr); err != nil {
+		return err
+	}
+
+	for _, amt := range []btcutil.Amount{
+		req.MaxSwapFee, req.MaxMinerFee, req.MaxPrepayAmount,
+		req.MaxSwapRoutingFee, req.MaxPrepayRoutingFee,
+	} {
+		if err := binary.Write(w, byteOrder, amt); err != nil {
+			return err
+		}
+	}
+
+	if err := binary.Write(w, byteOrder, req.ConfTarget); err != nil {
+		return err
+	}
+
+	var channel uint64
+	if req.Channel != nil {
+		channel = *req.Channel
+	}
+	if err := binary.Write(w, byteOrder, channel); err != nil {
+		return err
+	}
+
+	if err := binary.Write(w, byteOrder, req.ExternalHtlc); err != nil {
+		return err
+	}
+
+	return wire.WriteVarString(w, 0, req.ServerID)
+}
+
+// readSwapRequest deserializes a swap request from the given reader.
+func readSwapRequest(r io.Reader, chainParams *chaincfg.Params) (*SwapRequest,
+	error) {
+
+	req := SwapRequest{}
+
+	if err := binary.Read(r, byteOrder, &req.Type); err != nil {
+		return nil, err
+	}
+
+	if err := binary.Read(r, byteOrder, &req.Amount); err != nil {
+		return nil, err
+	}
+
+	addr, err := wire.ReadVarString(r, 0)
+	if err != nil {
+		return nil, err
+	}
+	if addr != "" {
+		req.DestAddr, err = btcutil.DecodeAddress(addr, chainParams)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, amt := range []*btcutil.Amount{
+		&req.MaxSwapFee, &req.MaxMinerFee, &req.MaxPrepayAmount,
+		&req.MaxSwapRoutingFee, &req.MaxPrepayRoutingFee,
+	} {
+		if err := binary.Read(r, byteOrder, amt); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := binary.Read(r, byteOrder, &req.ConfTarget); err != nil {
+		return nil, err
+	}
+
+	var channel uint64
+	if err := binary.Read(r, byteOrder, &channel); err != nil {
+		return nil, err
+	}
+	if channel != 0 {
+		req.Channel = &channel
+	}
+
+	if err := binary.Read(r, byteOrder, &req.ExternalHtlc); err != nil {
+		return nil, err
+	}
+
+	req.ServerID, err = wire.ReadVarString(r, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
@@ -1,6 +1,9 @@
 package loopdb
 
-import "github.com/btcsuite/btcutil"
+import (
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcutil"
+)
 
 // SwapState indicates the current state of a swap. This enumeration is the
 // union of loop in and loop out states. A single type is used for both swap
@@ -147,4 +150,12 @@ type SwapStateData struct {
 
 	// Cost are the accrued (final) costs so far.
 	Cost SwapCost
+
+	// PaymentFailure is set if the swap failed because one of its
+	// off-chain payments failed.
+	PaymentFailure *PaymentFailure
+
+	// RefundTxHash is set if the htlc of a loop in swap was refunded by
+	// the timeout tx.
+	RefundTxHash *chainhash.Hash
 }
@@ -0,0 +1,92 @@
+package loopdb
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+)
+
+// SweepOutput describes one of the outputs of a loop out sweep tx. An output
+// either receives a fixed amount or a share of the value that remains after
+// the fixed amounts and the sweep fee are deducted.
+type SweepOutput struct {
+	// Addr is the address that the output pays to.
+	Addr btcutil.Address
+
+	// Amount is the fixed amount that the output receives. It is zero for
+	// weighted outputs.
+	Amount btcutil.Amount
+
+	// Weight is the relative share of the remaining value that the output
+	// receives. It is zero for fixed amount outputs.
+	Weight uint32
+}
+
+// writeSweepOutputs serializes a list of sweep outputs, prefixed by its
+// length.
+func writeSweepOutputs(w io.Writer, outputs []SweepOutput) error {
+	if err := binary.Write(w, byteOrder, uint32(len(outputs))); err != nil {
+		return err
+	}
+
+	for _, output := range outputs {
+		err := wire.WriteVarString(w, 0, output.Addr.String())
+		if err != nil {
+			return err
+		}
+
+		err = binary.Write(w, byteOrder, output.Amount)
+		if err != nil {
+			return err
+		}
+
+		err = binary.Write(w, byteOrder, output.Weight)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readSweepOutputs deserializes a list of sweep outputs that was serialized
+// by writeSweepOutputs.
+func readSweepOutputs(r io.Reader, chainParams *chaincfg.Params) (
+	[]SweepOutput, error) {
+
+	var count uint32
+	if err := binary.Read(r, byteOrder, &count); err != nil {
+		return nil, err
+	}
+
+	var outputs []SweepOutput
+	for i := uint32(0); i < count; i++ {
+		var output SweepOutput
+
+		addr, err := wire.ReadVarString(r, 0)
+		if err != nil {
+			return nil, err
+		}
+		output.Addr, err = btcutil.DecodeAddress(addr, chainParams)
+		if err != nil {
+			return nil, err
+		}
+
+		err = binary.Read(r, byteOrder, &output.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		err = binary.Read(r, byteOrder, &output.Weight)
+		if err != nil {
+			return nil, err
+		}
+
+		outputs = append(outputs, output)
+	}
+
+	return outputs, nil
+}
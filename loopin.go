@@ -1,16 +1,23 @@
 package loop
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/sha256"
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcutil"
 
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/input"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 	"github.com/lightningnetwork/lnd/lnwire"
 
 	"github.com/btcsuite/btcd/wire"
@@ -41,6 +48,21 @@ var (
 	// TimeoutTxConfTarget defines the confirmation target for the loop in
 	// timeout tx.
 	TimeoutTxConfTarget = int32(2)
+
+	// ErrRefundFeePolicy is returned when a loop in request specifies both
+	// a refund confirmation target and a refund fee rate.
+	ErrRefundFeePolicy = errors.New("refund conf target and refund fee " +
+		"rate are mutually exclusive")
+
+	// ErrRefundFeeRateTooLow is returned when the refund fee rate of a
+	// loop in request is below the minimum relay fee rate.
+	ErrRefundFeeRateTooLow = errors.New("refund fee rate below minimum " +
+		"relay fee rate")
+
+	// ErrInvalidReceiverSig is returned when the server signature for a
+	// cooperative refund doesn't verify.
+	ErrInvalidReceiverSig = errors.New("invalid receiver signature for " +
+		"cooperative refund")
 )
 
 // loopInSwap contains all the in-memory state related to a pending loop in
@@ -53,16 +75,27 @@ type loopInSwap struct {
 	timeoutAddr btcutil.Address
 }
 
-// newLoopInSwap initiates a new loop in swap.
+// newLoopInSwap initiates a new loop in swap. If the swap is based on a
+// quote, the quoted swap fee is used instead of requesting a new quote.
 func newLoopInSwap(globalCtx context.Context, cfg *swapConfig,
-	currentHeight int32, request *LoopInRequest) (*loopInSwap, error) {
+	currentHeight int32, request *LoopInRequest,
+	quote *LoopInQuote) (*loopInSwap, error) {
+
+	err := validateLoopInRefund(request, cfg.lnd.ChainParams)
+	if err != nil {
+		return nil, err
+	}
 
 	// Request current server loop in terms and use these to calculate the
 	// swap fee that we should subtract from the swap amount in the payment
 	// request that we send to the server.
-	quote, err := cfg.server.GetLoopInQuote(globalCtx, request.Amount)
-	if err != nil {
-		return nil, fmt.Errorf("loop in terms: %v", err)
+	if quote == nil {
+		quote, err = cfg.server.GetLoopInQuote(
+			globalCtx, request.Amount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("loop in terms: %v", err)
+		}
 	}
 
 	swapFee := quote.SwapFee
@@ -112,14 +145,26 @@ func newLoopInSwap(globalCtx context.Context, cfg *swapConfig,
 	// Post the swap parameters to the swap server. The response contains
 	// the server success key and the expiry height of the on-chain swap
 	// htlc.
+	htlcType := swap.HtlcNP2WSH
+	if request.NativeSegwitHtlc {
+		htlcType = swap.HtlcP2WSH
+	}
+
 	log.Infof("Initiating swap request at height %v", currentHeight)
 	swapResp, err := cfg.server.NewLoopInSwap(globalCtx, swapHash,
-		request.Amount, senderKey, swapInvoice,
+		request.Amount, senderKey, swapInvoice, request.QuoteID,
+		htlcType,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("cannot initiate swap: %v", err)
 	}
 
+	// The server determines the htlc type that it watches.
+	if swapResp.htlcType != htlcType {
+		log.Warnf("Server doesn't support requested htlc type, using "+
+			"type %v", swapResp.htlcType)
+	}
+
 	// Validate the response parameters the prevent us continuing with a
 	// swap that is based on parameters outside our allowed range.
 	err = validateLoopInContract(cfg.lnd, currentHeight, request, swapResp)
@@ -132,9 +177,13 @@ func newLoopInSwap(globalCtx context.Context, cfg *swapConfig,
 	initiationTime := time.Now()
 
 	contract := loopdb.LoopInContract{
-		HtlcConfTarget: request.HtlcConfTarget,
-		LoopInChannel:  request.LoopInChannel,
-		ExternalHtlc:   request.ExternalHtlc,
+		HtlcConfTarget:   request.HtlcConfTarget,
+		LoopInChannel:    request.LoopInChannel,
+		ExternalHtlc:     request.ExternalHtlc,
+		RefundAddr:       request.RefundAddr,
+		RefundConfTarget: request.RefundConfTarget,
+		RefundFeeRate:    request.RefundFeeRate,
+		HtlcOutputType:   swapResp.htlcType,
 		SwapContract: loopdb.SwapContract{
 			InitiationHeight: currentHeight,
 			InitiationTime:   initiationTime,
@@ -145,12 +194,18 @@ func newLoopInSwap(globalCtx context.Context, cfg *swapConfig,
 			CltvExpiry:       swapResp.expiry,
 			MaxMinerFee:      request.MaxMinerFee,
 			MaxSwapFee:       request.MaxSwapFee,
+			HtlcVersion:      swapResp.htlcVersion,
+			ServerID:         cfg.serverID,
+			IdempotencyKey:   request.IdempotencyKey,
+			RequestDigest:    request.RequestDigest,
+			Label:            request.Label,
+			Metadata:         request.Metadata,
 		},
 	}
 
 	swapKit, err := newSwapKit(
 		swapHash, swap.TypeIn, cfg, &contract.SwapContract,
-		swap.HtlcNP2WSH,
+		contract.HtlcOutputType,
 	)
 	if err != nil {
 		return nil, err
@@ -184,7 +239,7 @@ func resumeLoopInSwap(reqContext context.Context, cfg *swapConfig,
 
 	swapKit, err := newSwapKit(
 		hash, swap.TypeIn, cfg, &pend.Contract.SwapContract,
-		swap.HtlcNP2WSH,
+		pend.Contract.HtlcOutputType,
 	)
 	if err != nil {
 		return nil, err
@@ -194,6 +249,7 @@ func resumeLoopInSwap(reqContext context.Context, cfg *swapConfig,
 		LoopInContract: *pend.Contract,
 		swapKit:        *swapKit,
 	}
+	swap.deposits = pend.Deposits
 
 	lastUpdate := pend.LastUpdate()
 	if lastUpdate == nil {
@@ -206,6 +262,34 @@ func resumeLoopInSwap(reqContext context.Context, cfg *swapConfig,
 	return swap, nil
 }
 
+// validateLoopInRefund checks the refund address and fee policy of a loop in
+// request.
+func validateLoopInRefund(request *LoopInRequest,
+	chainParams *chaincfg.Params) error {
+
+	if request.RefundAddr != nil {
+		err := validateDestAddrType(request.RefundAddr, chainParams)
+		if err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case request.RefundConfTarget < 0:
+		return errors.New("refund conf target must not be negative")
+
+	case request.RefundConfTarget != 0 && request.RefundFeeRate != 0:
+		return ErrRefundFeePolicy
+
+	case request.RefundFeeRate != 0 &&
+		request.RefundFeeRate < chainfee.FeePerKwFloor:
+
+		return ErrRefundFeeRateTooLow
+	}
+
+	return nil
+}
+
 // validateLoopInContract validates the contract parameters against our
 // request.
 func validateLoopInContract(lnd *lndclient.LndServices,
@@ -299,29 +383,9 @@ func (s *loopInSwap) executeSwap(globalCtx context.Context) error {
 		}
 	}
 
-	// Wait for the htlc to confirm. After a restart this will pick up a
-	// previously published tx.
-	conf, err := s.waitForHtlcConf(globalCtx)
-	if err != nil {
-		return err
-	}
-
-	// Determine the htlc outpoint by inspecting the htlc tx.
-	htlcOutpoint, htlcValue, err := swap.GetScriptOutput(
-		conf.Tx, s.htlc.PkScript,
-	)
-	if err != nil {
-		return err
-	}
-
-	// TODO: Add miner fee of htlc tx to swap cost balance.
-
-	// The server is expected to see the htlc on-chain and knowing that it
-	// can sweep that htlc with the preimage, it should pay our swap
-	// invoice, receive the preimage and sweep the htlc. We are waiting for
-	// this to happen and simultaneously watch the htlc expiry height. When
-	// the htlc expires, we will publish a timeout tx to reclaim the funds.
-	err = s.waitForSwapComplete(globalCtx, htlcOutpoint, htlcValue)
+	// Watch the htlc for deposits until the swap completes. After a
+	// restart this will pick up previously published txs.
+	err = s.waitForSwapComplete(globalCtx)
 	if err != nil {
 		return err
 	}
@@ -334,40 +398,6 @@ func (s *loopInSwap) executeSwap(globalCtx context.Context) error {
 	return nil
 }
 
-// waitForHtlcConf watches the chain until the htlc confirms.
-func (s *loopInSwap) waitForHtlcConf(globalCtx context.Context) (
-	*chainntnfs.TxConfirmation, error) {
-
-	ctx, cancel := context.WithCancel(globalCtx)
-	defer cancel()
-	confChan, confErr, err := s.lnd.ChainNotifier.RegisterConfirmationsNtfn(
-		ctx, nil, s.htlc.PkScript, 1, s.InitiationHeight,
-	)
-	if err != nil {
-		return nil, err
-	}
-	for {
-		select {
-
-		// Htlc confirmed.
-		case conf := <-confChan:
-			return conf, nil
-
-		// Conf ntfn error.
-		case err := <-confErr:
-			return nil, err
-
-		// Keep up with block height.
-		case notification := <-s.blockEpochChan:
-			s.height = notification.(int32)
-
-		// Cancel.
-		case <-globalCtx.Done():
-			return nil, globalCtx.Err()
-		}
-	}
-}
-
 // publishOnChainHtlc checks whether there are still enough blocks left and if
 // so, it publishes the htlc and advances the swap state.
 func (s *loopInSwap) publishOnChainHtlc(ctx context.Context) (bool, error) {
@@ -410,87 +440,282 @@ func (s *loopInSwap) publishOnChainHtlc(ctx context.Context) (bool, error) {
 		return false, fmt.Errorf("send outputs: %v", err)
 	}
 	s.log.Infof("Published on chain HTLC tx %v", tx.TxHash())
+	labelTx(ctx, s.lnd.WalletKit, s.Label, tx)
 
 	return true, nil
 
 }
 
-// waitForSwapComplete waits until a spending tx of the htlc gets confirmed and
-// the swap invoice is either settled or canceled. If the htlc times out, the
-// timeout tx will be published.
-func (s *loopInSwap) waitForSwapComplete(ctx context.Context,
-	htlc *wire.OutPoint, htlcValue btcutil.Amount) error {
-
-	// Register the htlc spend notification.
+// waitForSwapComplete watches the htlc script for deposits until the swap
+// completes and all deposits are spent. The first deposit of exactly the swap
+// amount funds the swap. The server is expected to see it on-chain and knowing
+// that it can sweep it with the preimage, it should pay our swap invoice,
+// receive the preimage and sweep the htlc. All other deposits are refunded
+// once the htlc expires, as is the accepted deposit if the server doesn't
+// sweep it in time. If the swap invoice is canceled before that, the deposits
+// are refunded early if the server co-signs the refunds.
+func (s *loopInSwap) waitForSwapComplete(ctx context.Context) error {
 	rpcCtx, cancel := context.WithCancel(ctx)
 	defer cancel()
-	spendChan, spendErr, err := s.lnd.ChainNotifier.RegisterSpendNtfn(
-		rpcCtx, nil, s.htlc.PkScript, s.InitiationHeight,
-	)
-	if err != nil {
-		return fmt.Errorf("register spend ntfn: %v", err)
+
+	// The spend notifications of all deposits are delivered on a single
+	// channel.
+	spendChan := make(chan *chainntnfs.SpendDetail)
+	spendErr := make(chan error, 1)
+	watchSpend := func(deposit *loopdb.LoopInDeposit) error {
+		outpoint := deposit.Outpoint
+		spend, errChan, err := s.lnd.ChainNotifier.RegisterSpendNtfn(
+			rpcCtx, &outpoint, s.htlc.PkScript, s.InitiationHeight,
+		)
+		if err != nil {
+			return fmt.Errorf("register spend ntfn: %v", err)
+		}
+
+		go func() {
+			select {
+			case detail := <-spend:
+				select {
+				case spendChan <- detail:
+				case <-rpcCtx.Done():
+				}
+
+			case err := <-errChan:
+				select {
+				case spendErr <- err:
+				case <-rpcCtx.Done():
+				}
+
+			case <-rpcCtx.Done():
+			}
+		}()
+
+		return nil
 	}
 
-	// Register for swap invoice updates.
-	rpcCtx, cancel = context.WithCancel(ctx)
-	defer cancel()
-	s.log.Infof("Subscribing to swap invoice %v", s.hash)
-	swapInvoiceChan, swapInvoiceErr, err := s.lnd.Invoices.SubscribeSingleInvoice(
-		rpcCtx, s.hash,
+	// The swap invoice is watched from the first deposit on.
+	var (
+		swapInvoiceChan <-chan lndclient.InvoiceUpdate
+		swapInvoiceErr  <-chan error
 	)
-	if err != nil {
-		return fmt.Errorf("subscribe to swap invoice: %v", err)
+	subscribeInvoice := func() error {
+		if swapInvoiceChan != nil {
+			return nil
+		}
+
+		s.log.Infof("Subscribing to swap invoice %v", s.hash)
+
+		var err error
+		swapInvoiceChan, swapInvoiceErr, err =
+			s.lnd.Invoices.SubscribeSingleInvoice(rpcCtx, s.hash)
+		if err != nil {
+			return fmt.Errorf("subscribe to swap invoice: %v", err)
+		}
+
+		return nil
 	}
 
-	// checkTimeout publishes the timeout tx if the contract has expired.
-	checkTimeout := func() error {
-		if s.height >= s.LoopInContract.CltvExpiry {
-			return s.publishTimeoutTx(ctx, htlc)
+	// A confirmation notification only reports a single tx that pays to
+	// the htlc script. To find further deposits, the registration is
+	// renewed with a height hint at the block of the last new deposit, so
+	// that other deposits in the same block are still found. Once only a
+	// known tx is reported, the hint moves past its block. After a
+	// restart, the search resumes at the block of the last deposit.
+	var (
+		confChan   chan *chainntnfs.TxConfirmation
+		confErr    chan error
+		confCancel = func() {}
+		confHint   = s.InitiationHeight
+	)
+	defer func() {
+		confCancel()
+	}()
+	advanceConfHint := func(height int32) {
+		if height > confHint {
+			confHint = height
+		}
+	}
+	for _, deposit := range s.deposits {
+		advanceConfHint(deposit.ConfHeight)
+	}
+	registerConf := func() error {
+		confCancel()
+
+		var (
+			confCtx context.Context
+			err     error
+		)
+		confCtx, confCancel = context.WithCancel(rpcCtx)
+		notifier := s.lnd.ChainNotifier
+		confChan, confErr, err = notifier.RegisterConfirmationsNtfn(
+			confCtx, nil, s.htlc.PkScript, 1, confHint,
+		)
+		if err != nil {
+			return fmt.Errorf("register conf ntfn: %v", err)
 		}
 
 		return nil
 	}
 
+	// Resume watching the deposits that were found before a restart. If
+	// the accepted deposit was already spent, it determines the outcome
+	// of the swap.
+	for _, deposit := range s.deposits {
+		if deposit.State != loopdb.DepositConfirmed {
+			continue
+		}
+		if err := watchSpend(deposit); err != nil {
+			return err
+		}
+	}
+	if len(s.deposits) > 0 {
+		if err := subscribeInvoice(); err != nil {
+			return err
+		}
+	}
+	if accepted := s.acceptedDeposit(); accepted != nil {
+		switch accepted.State {
+		case loopdb.DepositSwept:
+			s.setState(loopdb.StateSuccess)
+
+		case loopdb.DepositRefunded:
+			s.setState(loopdb.StateFailTimeout)
+		}
+	}
+
+	if err := registerConf(); err != nil {
+		return err
+	}
+
 	// Check timeout at current height. After a restart we may want to
-	// publish the tx immediately.
-	err = checkTimeout()
+	// publish the timeout txes immediately.
+	err := s.checkTimeout(ctx)
 	if err != nil {
 		return err
 	}
 
-	htlcSpend := false
+	// Once the swap invoice is canceled, the swap can no longer complete.
+	// This happens when the server rejects the swap, upon which the client
+	// cancels the invoice. We then ask the server to co-sign refunds of
+	// the unspent deposits, so that we don't need to wait for the htlc to
+	// expire. Each deposit is only tried once and remains on the timeout
+	// path if the server doesn't cooperate. Swaps of a server that is no
+	// longer configured always take the timeout path.
+	var (
+		invoiceCanceled bool
+		coopRefunds     = make(map[wire.OutPoint]struct{})
+	)
+	refundEarly := func() {
+		if !invoiceCanceled || s.height >= s.CltvExpiry ||
+			!s.htlc.SupportsCooperativeRefund() || s.server == nil {
+
+			return
+		}
+
+		for _, deposit := range s.deposits {
+			if deposit.State != loopdb.DepositConfirmed {
+				continue
+			}
+
+			if _, ok := coopRefunds[deposit.Outpoint]; ok {
+				continue
+			}
+			coopRefunds[deposit.Outpoint] = struct{}{}
+
+			err := s.publishCooperativeRefund(ctx, deposit)
+			if err != nil {
+				s.log.Warnf("Cooperative refund of deposit %v "+
+					"failed, refunding after htlc expiry "+
+					"at height %v: %v", deposit.Outpoint,
+					s.CltvExpiry, err)
+			}
+		}
+	}
+
 	invoiceFinalized := false
-	for !htlcSpend || !invoiceFinalized {
+	for !invoiceFinalized || !s.depositsSpent() {
 		select {
+		// A tx paying to the htlc script confirmed. Record its
+		// deposits and watch them for spends.
+		case conf := <-confChan:
+			confHeight := int32(conf.BlockHeight)
+			deposits := s.newDeposits(conf.Tx, confHeight)
+
+			// If only a known tx was reported, its block holds no
+			// further deposits that the notifier reports. We look
+			// past it from the next block on.
+			if len(deposits) == 0 {
+				advanceConfHint(confHeight + 1)
+				if err := registerConf(); err != nil {
+					return err
+				}
+				continue
+			}
+
+			rejected := false
+			for _, deposit := range deposits {
+				err := s.addDeposit(deposit)
+				if err != nil {
+					return err
+				}
+
+				if err := watchSpend(deposit); err != nil {
+					return err
+				}
+
+				rejected = rejected || !deposit.Accepted
+			}
+
+			if err := subscribeInvoice(); err != nil {
+				return err
+			}
+
+			advanceConfHint(confHeight)
+			if err := registerConf(); err != nil {
+				return err
+			}
+
+			// Report deposits that don't fund the swap.
+			if rejected {
+				if err := s.sendUpdate(ctx); err != nil {
+					return err
+				}
+			}
+
+			// Deposits that confirm after the swap failed are
+			// refunded right away.
+			refundEarly()
+			if err := s.checkTimeout(ctx); err != nil {
+				return err
+			}
+
+		// Conf ntfn error.
+		case err := <-confErr:
+			return err
+
 		// Spend notification error.
 		case err := <-spendErr:
 			return err
 
-		// Receive block epochs and start publishing the timeout tx
+		// Receive block epochs and start publishing the timeout txes
 		// whenever possible.
 		case notification := <-s.blockEpochChan:
 			s.height = notification.(int32)
 
-			err := checkTimeout()
-			if err != nil {
+			if err := s.checkTimeout(ctx); err != nil {
 				return err
 			}
 
-		// The htlc spend is confirmed. Inspect the spending tx to
-		// determine the final swap state.
+		// A deposit spend is confirmed. Inspect the spending tx to
+		// determine the outcome.
 		case spendDetails := <-spendChan:
 			s.log.Infof("Htlc spend by tx: %v",
 				spendDetails.SpenderTxHash)
 
-			err := s.processHtlcSpend(
-				ctx, spendDetails, htlcValue,
-			)
+			err := s.processDepositSpend(ctx, spendDetails)
 			if err != nil {
 				return err
 			}
 
-			htlcSpend = true
-
 		// Swap invoice ntfn error.
 		case err := <-swapInvoiceErr:
 			return err
@@ -527,6 +752,9 @@ func (s *loopInSwap) waitForSwapComplete(ctx context.Context,
 			// balance.
 			case channeldb.ContractCanceled:
 				invoiceFinalized = true
+				invoiceCanceled = true
+
+				refundEarly()
 			}
 
 		case <-ctx.Done():
@@ -537,6 +765,211 @@ func (s *loopInSwap) waitForSwapComplete(ctx context.Context,
 	return nil
 }
 
+// newDeposits returns the outputs of the given tx that pay to the htlc script
+// and aren't known yet. The tx confirmed at the given height.
+func (s *loopInSwap) newDeposits(tx *wire.MsgTx,
+	confHeight int32) []*loopdb.LoopInDeposit {
+
+	txHash := tx.TxHash()
+
+	var deposits []*loopdb.LoopInDeposit
+	for i, txOut := range tx.TxOut {
+		if !bytes.Equal(txOut.PkScript, s.htlc.PkScript) {
+			continue
+		}
+
+		outpoint := wire.OutPoint{
+			Hash:  txHash,
+			Index: uint32(i),
+		}
+		if s.deposit(outpoint) != nil {
+			continue
+		}
+
+		deposits = append(deposits, &loopdb.LoopInDeposit{
+			Outpoint:   outpoint,
+			Value:      btcutil.Amount(txOut.Value),
+			ConfHeight: confHeight,
+		})
+	}
+
+	return deposits
+}
+
+// addDeposit decides whether a new deposit funds the swap and persists it.
+// Only a single deposit of exactly the swap amount that confirms before the
+// htlc expires is accepted.
+func (s *loopInSwap) addDeposit(deposit *loopdb.LoopInDeposit) error {
+	switch {
+	case s.acceptedDeposit() != nil:
+		s.log.Warnf("Extra deposit %v of %v, refunding after htlc "+
+			"expiry at height %v", deposit.Outpoint,
+			deposit.Value, s.CltvExpiry)
+
+	case deposit.Value != s.AmountRequested:
+		s.log.Warnf("Deposit %v of %v doesn't match swap amount %v, "+
+			"refunding after htlc expiry at height %v",
+			deposit.Outpoint, deposit.Value, s.AmountRequested,
+			s.CltvExpiry)
+
+	case s.height >= s.CltvExpiry:
+		s.log.Warnf("Deposit %v confirmed after htlc expiry, "+
+			"refunding", deposit.Outpoint)
+
+	default:
+		s.log.Infof("Htlc deposit %v of %v accepted",
+			deposit.Outpoint, deposit.Value)
+
+		deposit.Accepted = true
+	}
+
+	err := s.store.StoreLoopInDeposit(s.hash, deposit)
+	if err != nil {
+		return err
+	}
+
+	s.deposits = append(s.deposits, deposit)
+
+	return nil
+}
+
+// deposit returns the known deposit at the given outpoint, if any.
+func (s *loopInSwap) deposit(outpoint wire.OutPoint) *loopdb.LoopInDeposit {
+	for _, deposit := range s.deposits {
+		if deposit.Outpoint == outpoint {
+			return deposit
+		}
+	}
+
+	return nil
+}
+
+// acceptedDeposit returns the deposit that funds the swap, if any.
+func (s *loopInSwap) acceptedDeposit() *loopdb.LoopInDeposit {
+	for _, deposit := range s.deposits {
+		if deposit.Accepted {
+			return deposit
+		}
+	}
+
+	return nil
+}
+
+// depositsSpent returns whether there is at least one deposit and all
+// deposits are spent.
+func (s *loopInSwap) depositsSpent() bool {
+	for _, deposit := range s.deposits {
+		if deposit.State == loopdb.DepositConfirmed {
+			return false
+		}
+	}
+
+	return len(s.deposits) > 0
+}
+
+// checkTimeout publishes timeout txes for all unspent deposits once the htlc
+// has expired. If no deposit funds the swap by then, the swap fails.
+func (s *loopInSwap) checkTimeout(ctx context.Context) error {
+	if s.height < s.CltvExpiry {
+		return nil
+	}
+
+	for _, deposit := range s.deposits {
+		if deposit.State != loopdb.DepositConfirmed {
+			continue
+		}
+
+		err := s.publishTimeoutTx(ctx, &deposit.Outpoint, deposit.Value)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Without any deposit, we keep waiting for the htlc to confirm.
+	if len(s.deposits) == 0 || s.acceptedDeposit() != nil ||
+		s.state == loopdb.StateFailTimeout {
+
+		return nil
+	}
+
+	s.log.Warnf("Htlc expired without a deposit of the swap amount %v",
+		s.AmountRequested)
+
+	s.setState(loopdb.StateFailTimeout)
+
+	// The server can no longer sweep a deposit, so the swap invoice can be
+	// canceled. We still need to query the final invoice state.
+	err := s.lnd.Invoices.CancelInvoice(ctx, s.hash)
+	if err != nil && err != channeldb.ErrInvoiceAlreadySettled {
+		return err
+	}
+
+	return nil
+}
+
+// processDepositSpend updates the deposit that is spent by the given tx. The
+// spend of the accepted deposit determines the outcome of the swap.
+func (s *loopInSwap) processDepositSpend(ctx context.Context,
+	spend *chainntnfs.SpendDetail) error {
+
+	htlcInput := spend.SpendingTx.TxIn[spend.SpenderInputIndex]
+
+	deposit := s.deposit(htlcInput.PreviousOutPoint)
+	if deposit == nil {
+		s.log.Warnf("Spend of unknown htlc outpoint %v",
+			htlcInput.PreviousOutPoint)
+
+		return nil
+	}
+
+	// Ignore repeated notifications.
+	if deposit.State != loopdb.DepositConfirmed {
+		return nil
+	}
+
+	deposit.SpendTxHash = spend.SpenderTxHash
+	if s.htlc.IsSuccessWitness(htlcInput.Witness) {
+		deposit.State = loopdb.DepositSwept
+	} else {
+		deposit.State = loopdb.DepositRefunded
+	}
+
+	err := s.store.StoreLoopInDeposit(s.hash, deposit)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case deposit.Accepted:
+		err := s.processHtlcSpend(ctx, spend, deposit.Value)
+		if err != nil {
+			return err
+		}
+
+	case deposit.State == loopdb.DepositSwept:
+		s.log.Warnf("Server swept deposit %v of %v", deposit.Outpoint,
+			deposit.Value)
+
+		s.cost.Server += deposit.Value
+
+	default:
+		fee := deposit.Value - txOutputValue(spend.SpendingTx)
+		s.cost.Onchain += fee
+
+		s.log.Infof("Deposit %v refunded by tx %v with fee %v",
+			deposit.Outpoint, spend.SpenderTxHash, fee)
+	}
+
+	// Report the spend if other deposits are still waiting to be spent.
+	// Otherwise the swap is about to complete and its final state is
+	// persisted.
+	if s.depositsSpent() {
+		return nil
+	}
+
+	return s.sendUpdate(ctx)
+}
+
 func (s *loopInSwap) processHtlcSpend(ctx context.Context,
 	spend *chainntnfs.SpendDetail, htlcValue btcutil.Amount) error {
 
@@ -553,7 +986,7 @@ func (s *loopInSwap) processHtlcSpend(ctx context.Context,
 	} else {
 		s.setState(loopdb.StateFailTimeout)
 
-		// Now that the timeout tx confirmed, we can safely cancel the
+		// Now that the refund tx confirmed, we can safely cancel the
 		// swap invoice. We still need to query the final invoice state.
 		// This is not a hodl invoice, so it may be that the invoice was
 		// already settled. This means that the server didn't succeed in
@@ -563,29 +996,37 @@ func (s *loopInSwap) processHtlcSpend(ctx context.Context,
 			return err
 		}
 
-		// TODO: Add miner fee of timeout tx to swap cost balance.
+		// The refund tx only spends the htlc, so its fee is the part
+		// of the htlc value that isn't paid to its outputs.
+		fee := htlcValue - txOutputValue(spend.SpendingTx)
+		s.cost.Onchain += fee
+		s.refundTxHash = spend.SpenderTxHash
+
+		s.log.Infof("Htlc refunded by tx %v with fee %v",
+			spend.SpenderTxHash, fee)
 	}
 
 	return nil
 }
 
-// publishTimeoutTx publishes a timeout tx after the on-chain htlc has expired.
-// The swap failed and we are reclaiming our funds.
+// publishTimeoutTx publishes a timeout tx for a deposit after the on-chain
+// htlc has expired. Either the swap failed and we are reclaiming our funds or
+// the deposit didn't fund the swap.
 func (s *loopInSwap) publishTimeoutTx(ctx context.Context,
-	htlc *wire.OutPoint) error {
+	htlc *wire.OutPoint, amount btcutil.Amount) error {
 
-	if s.timeoutAddr == nil {
-		var err error
-		s.timeoutAddr, err = s.lnd.WalletKit.NextAddr(ctx)
-		if err != nil {
-			return err
-		}
+	if err := s.setTimeoutAddr(ctx); err != nil {
+		return err
+	}
+
+	fee, err := s.refundFee(ctx, s.htlc.AddTimeoutToEstimator)
+	if err != nil {
+		return err
 	}
 
-	// Calculate sweep tx fee
-	fee, err := s.sweeper.GetSweepFee(
-		ctx, s.htlc.AddTimeoutToEstimator, s.timeoutAddr,
-		TimeoutTxConfTarget,
+	txOuts, err := sweepTxOuts(
+		[]loopdb.SweepOutput{{Addr: s.timeoutAddr, Weight: 1}},
+		amount-fee,
 	)
 	if err != nil {
 		return err
@@ -597,7 +1038,7 @@ func (s *loopInSwap) publishTimeoutTx(ctx context.Context,
 
 	timeoutTx, err := s.sweeper.CreateSweepTx(
 		ctx, s.height, s.htlc, *htlc, s.SenderKey, witnessFunc,
-		s.LoopInContract.AmountRequested, fee, s.timeoutAddr,
+		amount, txOuts,
 	)
 	if err != nil {
 		return err
@@ -610,19 +1051,178 @@ func (s *loopInSwap) publishTimeoutTx(ctx context.Context,
 	err = s.lnd.WalletKit.PublishTransaction(ctx, timeoutTx)
 	if err != nil {
 		s.log.Warnf("publish timeout: %v", err)
+	} else {
+		labelTx(ctx, s.lnd.WalletKit, s.Label, timeoutTx)
 	}
 
 	return nil
 }
 
+// publishCooperativeRefund refunds a deposit before the htlc expires through
+// the path that requires both our signature and the signature of the server.
+// The server signature is verified before the refund tx is published.
+func (s *loopInSwap) publishCooperativeRefund(ctx context.Context,
+	deposit *loopdb.LoopInDeposit) error {
+
+	if err := s.setTimeoutAddr(ctx); err != nil {
+		return err
+	}
+
+	fee, err := s.refundFee(ctx, s.htlc.AddCooperativeRefundToEstimator)
+	if err != nil {
+		return err
+	}
+
+	txOuts, err := sweepTxOuts(
+		[]loopdb.SweepOutput{{Addr: s.timeoutAddr, Weight: 1}},
+		deposit.Value-fee,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Signatures don't commit to the witness, so we can sign the refund
+	// tx before the server does and add the witness afterwards.
+	var senderSig []byte
+	witnessFunc := func(sig []byte) (wire.TxWitness, error) {
+		senderSig = sig
+		return nil, nil
+	}
+
+	refundTx, err := s.sweeper.CreateSweepTx(
+		ctx, s.height, s.htlc, deposit.Outpoint, s.SenderKey,
+		witnessFunc, deposit.Value, txOuts,
+	)
+	if err != nil {
+		return err
+	}
+
+	receiverSig, err := s.server.CooperativeLoopInRefund(
+		ctx, s.hash, refundTx, deposit.Value,
+	)
+	if err != nil {
+		return fmt.Errorf("server refused to sign: %v", err)
+	}
+
+	err = s.verifyReceiverSig(refundTx, deposit.Value, receiverSig)
+	if err != nil {
+		return err
+	}
+
+	refundTx.TxIn[0].Witness, err = s.htlc.GenCooperativeRefundWitness(
+		senderSig, receiverSig,
+	)
+	if err != nil {
+		return err
+	}
+
+	s.log.Infof("Publishing cooperative refund tx %v for deposit %v "+
+		"with fee %v to addr %v", refundTx.TxHash(), deposit.Outpoint,
+		fee, s.timeoutAddr)
+
+	err = s.lnd.WalletKit.PublishTransaction(ctx, refundTx)
+	if err != nil {
+		return err
+	}
+	labelTx(ctx, s.lnd.WalletKit, s.Label, refundTx)
+
+	return nil
+}
+
+// verifyReceiverSig checks that the server signature is valid for the htlc
+// input of a cooperative refund tx.
+func (s *loopInSwap) verifyReceiverSig(refundTx *wire.MsgTx,
+	htlcValue btcutil.Amount, receiverSig []byte) error {
+
+	sigHash, err := txscript.CalcWitnessSigHash(
+		s.htlc.Script, txscript.NewTxSigHashes(refundTx),
+		txscript.SigHashAll, refundTx, 0, int64(htlcValue),
+	)
+	if err != nil {
+		return err
+	}
+
+	sig, err := btcec.ParseDERSignature(receiverSig, btcec.S256())
+	if err != nil {
+		return fmt.Errorf("invalid receiver signature: %v", err)
+	}
+
+	receiverKey, err := btcec.ParsePubKey(s.ReceiverKey[:], btcec.S256())
+	if err != nil {
+		return err
+	}
+
+	if !sig.Verify(sigHash, receiverKey) {
+		return ErrInvalidReceiverSig
+	}
+
+	return nil
+}
+
+// setTimeoutAddr sets the address that deposits are refunded to. This is the
+// refund address of the contract if there is one. Otherwise the funds go back
+// to the lnd wallet.
+func (s *loopInSwap) setTimeoutAddr(ctx context.Context) error {
+	switch {
+	case s.timeoutAddr != nil:
+
+	case s.RefundAddr != nil:
+		s.timeoutAddr = s.RefundAddr
+
+	default:
+		var err error
+		s.timeoutAddr, err = s.lnd.WalletKit.NextAddr(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// refundFee returns the fee of a refund tx according to the refund fee policy
+// of the contract.
+func (s *loopInSwap) refundFee(ctx context.Context,
+	addInputEstimate func(*input.TxWeightEstimator)) (btcutil.Amount,
+	error) {
+
+	destAddrs := []btcutil.Address{s.timeoutAddr}
+
+	if s.RefundFeeRate != 0 {
+		return s.sweeper.GetSweepFeeForRate(
+			addInputEstimate, destAddrs, s.RefundFeeRate,
+		)
+	}
+
+	confTarget := s.RefundConfTarget
+	if confTarget == 0 {
+		confTarget = TimeoutTxConfTarget
+	}
+
+	return s.sweeper.GetSweepFee(
+		ctx, addInputEstimate, destAddrs, confTarget,
+	)
+}
+
+// txOutputValue returns the total value of the outputs of a tx.
+func txOutputValue(tx *wire.MsgTx) btcutil.Amount {
+	var value btcutil.Amount
+	for _, txOut := range tx.TxOut {
+		value += btcutil.Amount(txOut.Value)
+	}
+
+	return value
+}
+
 // persistState updates the swap state and sends out an update notification.
 func (s *loopInSwap) persistState(ctx context.Context) error {
 	// Update state in store.
 	err := s.store.UpdateLoopIn(
 		s.hash, s.lastUpdateTime,
 		loopdb.SwapStateData{
-			State: s.state,
-			Cost:  s.cost,
+			State:        s.state,
+			Cost:         s.cost,
+			RefundTxHash: s.refundTxHash,
 		},
 	)
 	if err != nil {
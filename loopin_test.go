@@ -1,7 +1,9 @@
 package loop
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/lightninglabs/loop/lndclient"
@@ -11,6 +13,7 @@ import (
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/channeldb"
 
+	"github.com/btcsuite/btcd/txscript"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 )
@@ -40,7 +43,7 @@ func TestLoopInSuccess(t *testing.T) {
 
 	swap, err := newLoopInSwap(
 		context.Background(), cfg,
-		height, &testLoopInRequest,
+		height, &testLoopInRequest, nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -82,6 +85,9 @@ func TestLoopInSuccess(t *testing.T) {
 		t.Fatal("client subscribing to wrong invoice")
 	}
 
+	// Client keeps watching for further deposits.
+	<-ctx.lnd.RegisterConfChannel
+
 	// Server has already paid invoice before spending the htlc. Signal
 	// settled.
 	subscription.Update <- lndclient.InvoiceUpdate{
@@ -96,6 +102,9 @@ func TestLoopInSuccess(t *testing.T) {
 	// Server spends htlc.
 	successTx := wire.MsgTx{}
 	successTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{
+			Hash: htlcTx.TxHash(),
+		},
 		Witness: [][]byte{{}, {}, {}},
 	})
 
@@ -113,8 +122,45 @@ func TestLoopInSuccess(t *testing.T) {
 	}
 }
 
+// TestLoopInNativeSegwit tests that a native segwit htlc is negotiated with
+// the server and persisted with the contract.
+func TestLoopInNativeSegwit(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := newLoopInTestContext(t)
+
+	cfg := &swapConfig{
+		lnd:    &ctx.lnd.LndServices,
+		store:  ctx.store,
+		server: ctx.server,
+	}
+
+	req := testLoopInRequest
+	req.NativeSegwitHtlc = true
+
+	loopIn, err := newLoopInSwap(
+		context.Background(), cfg, 600, &req, nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx.store.assertLoopInStored()
+
+	if loopIn.htlc.OutputType != swap.HtlcP2WSH {
+		t.Fatalf("expected P2WSH htlc, got %v", loopIn.htlc.OutputType)
+	}
+
+	contract := ctx.store.loopInSwaps[ctx.server.swapHash]
+	if contract.HtlcOutputType != swap.HtlcP2WSH {
+		t.Fatalf("expected stored P2WSH htlc, got %v",
+			contract.HtlcOutputType)
+	}
+}
+
 // TestLoopInTimeout tests the scenario where the server doesn't sweep the htlc
-// and the client is forced to reclaim the funds using the timeout tx.
+// and the client is forced to reclaim the funds using the timeout tx, which
+// pays to the refund address of the request.
 func TestLoopInTimeout(t *testing.T) {
 	defer test.Guard(t)()
 
@@ -128,9 +174,13 @@ func TestLoopInTimeout(t *testing.T) {
 		server: ctx.server,
 	}
 
+	req := testLoopInRequest
+	req.RefundAddr = testAddr
+	req.RefundFeeRate = 2500
+
 	swap, err := newLoopInSwap(
 		context.Background(), cfg,
-		height, &testLoopInRequest,
+		height, &req, nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -172,14 +222,30 @@ func TestLoopInTimeout(t *testing.T) {
 		t.Fatal("client subscribing to wrong invoice")
 	}
 
+	// Client keeps watching for further deposits.
+	<-ctx.lnd.RegisterConfChannel
+
 	// Let htlc expire.
 	ctx.blockEpochChan <- swap.LoopInContract.CltvExpiry
 
-	// Expect timeout tx to be published.
+	// Expect timeout tx to be published to the refund address.
 	timeoutTx := <-ctx.lnd.TxPublishChannel
 
+	refundScript, err := txscript.PayToAddrScript(testAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(timeoutTx.TxOut) != 1 ||
+		!bytes.Equal(timeoutTx.TxOut[0].PkScript, refundScript) {
+
+		t.Fatal("timeout tx doesn't pay to refund address")
+	}
+	refundFee := req.Amount - btcutil.Amount(timeoutTx.TxOut[0].Value)
+
 	// Confirm timeout tx.
+	timeoutTxHash := timeoutTx.TxHash()
 	ctx.lnd.SpendChannel <- &chainntnfs.SpendDetail{
+		SpenderTxHash:     &timeoutTxHash,
 		SpendingTx:        timeoutTx,
 		SpenderInputIndex: 0,
 	}
@@ -194,7 +260,356 @@ func TestLoopInTimeout(t *testing.T) {
 	}
 
 	ctx.assertState(loopdb.StateFailTimeout)
-	ctx.store.assertLoopInState(loopdb.StateFailTimeout)
+
+	// The refund tx and its fee are recorded in the final state.
+	state := <-ctx.store.loopInUpdateChan
+	if state.State != loopdb.StateFailTimeout {
+		t.Fatalf("expected state %v, got %v", loopdb.StateFailTimeout,
+			state.State)
+	}
+	if state.RefundTxHash == nil || *state.RefundTxHash != timeoutTxHash {
+		t.Fatalf("expected refund tx %v, got %v", timeoutTxHash,
+			state.RefundTxHash)
+	}
+	if state.Cost.Onchain != refundFee {
+		t.Fatalf("expected onchain cost %v, got %v", refundFee,
+			state.Cost.Onchain)
+	}
+
+	err = <-errChan
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestLoopInExtraDeposit tests that a deposit that doesn't match the swap
+// amount is reported and refunded after the htlc expires, while the deposit of
+// the swap amount completes the swap. Both deposits are found whether they
+// confirm in separate blocks or in the same block.
+func TestLoopInExtraDeposit(t *testing.T) {
+	t.Run("separate blocks", func(t *testing.T) {
+		testLoopInExtraDeposit(t, 602)
+	})
+	t.Run("same block", func(t *testing.T) {
+		testLoopInExtraDeposit(t, 601)
+	})
+}
+
+func testLoopInExtraDeposit(t *testing.T, htlcConfHeight uint32) {
+	defer test.Guard(t)()
+
+	ctx := newLoopInTestContext(t)
+
+	height := int32(600)
+
+	cfg := &swapConfig{
+		lnd:    &ctx.lnd.LndServices,
+		store:  ctx.store,
+		server: ctx.server,
+	}
+
+	swap, err := newLoopInSwap(
+		context.Background(), cfg,
+		height, &testLoopInRequest, nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx.store.assertLoopInStored()
+
+	errChan := make(chan error)
+	go func() {
+		err := swap.execute(context.Background(), ctx.cfg, height)
+		if err != nil {
+			log.Error(err)
+		}
+		errChan <- err
+	}()
+
+	ctx.assertState(loopdb.StateInitiated)
+
+	ctx.assertState(loopdb.StateHtlcPublished)
+	ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+
+	// Expect htlc to be published.
+	htlcTx := <-ctx.lnd.SendOutputsChannel
+
+	// Expect register for htlc conf.
+	assertConfHint := func(expected int32) {
+		t.Helper()
+
+		registration := <-ctx.lnd.RegisterConfChannel
+		if registration.HeightHint != expected {
+			t.Fatalf("expected height hint %v, got %v", expected,
+				registration.HeightHint)
+		}
+	}
+	assertConfHint(height)
+
+	// A deposit of the wrong amount confirms first.
+	wrongTx := wire.MsgTx{}
+	wrongTx.AddTxOut(&wire.TxOut{
+		PkScript: swap.htlc.PkScript,
+		Value:    int64(testLoopInRequest.Amount) - 10000,
+	})
+	ctx.lnd.ConfChannel <- &chainntnfs.TxConfirmation{
+		BlockHeight: 601,
+		Tx:          &wrongTx,
+	}
+
+	// Client starts listening for spend of the deposit, for swap invoice
+	// updates and for further deposits, including those in the same
+	// block.
+	<-ctx.lnd.RegisterSpendChannel
+	subscription := <-ctx.lnd.SingleInvoiceSubcribeChannel
+	assertConfHint(601)
+
+	// The rejected deposit is reported.
+	update := <-ctx.statusChan
+	if len(update.Deposits) != 1 || update.Deposits[0].Accepted {
+		t.Fatalf("expected rejected deposit, got %v", update.Deposits)
+	}
+
+	// Confirm the htlc of the swap amount.
+	ctx.lnd.ConfChannel <- &chainntnfs.TxConfirmation{
+		BlockHeight: htlcConfHeight,
+		Tx:          &htlcTx,
+	}
+
+	<-ctx.lnd.RegisterSpendChannel
+	assertConfHint(int32(htlcConfHeight))
+
+	// Server pays the invoice and sweeps the htlc.
+	subscription.Update <- lndclient.InvoiceUpdate{
+		State:   channeldb.ContractSettled,
+		AmtPaid: 49000,
+	}
+
+	ctx.assertState(loopdb.StateInvoiceSettled)
+	ctx.store.assertLoopInState(loopdb.StateInvoiceSettled)
+
+	successTx := wire.MsgTx{}
+	successTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{
+			Hash: htlcTx.TxHash(),
+		},
+		Witness: [][]byte{{}, {}, {}},
+	})
+	successTxHash := successTx.TxHash()
+
+	ctx.lnd.SpendChannel <- &chainntnfs.SpendDetail{
+		SpendingTx:        &successTx,
+		SpenderTxHash:     &successTxHash,
+		SpenderInputIndex: 0,
+	}
+
+	// The sweep is reported while the wrong deposit is still unspent.
+	update = <-ctx.statusChan
+	if update.State != loopdb.StateSuccess {
+		t.Fatalf("expected state %v, got %v", loopdb.StateSuccess,
+			update.State)
+	}
+
+	// Let htlc expire. Only the wrong deposit is refunded.
+	ctx.blockEpochChan <- swap.LoopInContract.CltvExpiry
+
+	timeoutTx := <-ctx.lnd.TxPublishChannel
+	wrongOutpoint := wire.OutPoint{Hash: wrongTx.TxHash()}
+	if timeoutTx.TxIn[0].PreviousOutPoint != wrongOutpoint {
+		t.Fatal("timeout tx doesn't spend the wrong deposit")
+	}
+
+	timeoutTxHash := timeoutTx.TxHash()
+	ctx.lnd.SpendChannel <- &chainntnfs.SpendDetail{
+		SpendingTx:        timeoutTx,
+		SpenderTxHash:     &timeoutTxHash,
+		SpenderInputIndex: 0,
+	}
+
+	// The swap completes with the refund of the wrong deposit.
+	update = <-ctx.statusChan
+	if update.State != loopdb.StateSuccess {
+		t.Fatalf("expected state %v, got %v", loopdb.StateSuccess,
+			update.State)
+	}
+	if len(update.Deposits) != 2 {
+		t.Fatalf("expected two deposits, got %v", update.Deposits)
+	}
+	for _, deposit := range update.Deposits {
+		expectedState := loopdb.DepositRefunded
+		if deposit.Accepted {
+			expectedState = loopdb.DepositSwept
+		}
+		if deposit.State != expectedState {
+			t.Fatalf("expected deposit %v to be %v, got %v",
+				deposit.Outpoint, expectedState, deposit.State)
+		}
+	}
+
+	// The refund fee is accounted for.
+	state := <-ctx.store.loopInUpdateChan
+	if state.State != loopdb.StateSuccess {
+		t.Fatalf("expected state %v, got %v", loopdb.StateSuccess,
+			state.State)
+	}
+	refundFee := btcutil.Amount(
+		wrongTx.TxOut[0].Value - timeoutTx.TxOut[0].Value,
+	)
+	if state.Cost.Onchain != refundFee {
+		t.Fatalf("expected onchain cost %v, got %v", refundFee,
+			state.Cost.Onchain)
+	}
+
+	err = <-errChan
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestLoopInCooperativeRefund tests that the deposit of a loop in whose swap
+// invoice is canceled is refunded before the htlc expires if the server
+// co-signs the refund, and that the client falls back to the timeout tx
+// otherwise.
+func TestLoopInCooperativeRefund(t *testing.T) {
+	t.Run("cooperative", func(t *testing.T) {
+		testLoopInCooperativeRefund(t, nil)
+	})
+	t.Run("uncooperative", func(t *testing.T) {
+		testLoopInCooperativeRefund(t, errors.New("not cooperating"))
+	})
+}
+
+func testLoopInCooperativeRefund(t *testing.T, serverErr error) {
+	defer test.Guard(t)()
+
+	ctx := newLoopInTestContext(t)
+	ctx.server.htlcVersion = swap.HtlcScriptV2
+	ctx.server.coopRefundErr = serverErr
+
+	height := int32(600)
+
+	cfg := &swapConfig{
+		lnd:    &ctx.lnd.LndServices,
+		store:  ctx.store,
+		server: ctx.server,
+	}
+
+	req := testLoopInRequest
+	req.RefundAddr = testAddr
+	req.RefundFeeRate = 2500
+
+	loopIn, err := newLoopInSwap(
+		context.Background(), cfg, height, &req, nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx.store.assertLoopInStored()
+
+	errChan := make(chan error)
+	go func() {
+		err := loopIn.execute(context.Background(), ctx.cfg, height)
+		if err != nil {
+			log.Error(err)
+		}
+		errChan <- err
+	}()
+
+	ctx.assertState(loopdb.StateInitiated)
+
+	ctx.assertState(loopdb.StateHtlcPublished)
+	ctx.store.assertLoopInState(loopdb.StateHtlcPublished)
+
+	// Expect htlc to be published.
+	htlcTx := <-ctx.lnd.SendOutputsChannel
+
+	// Expect register for htlc conf.
+	<-ctx.lnd.RegisterConfChannel
+
+	// Confirm htlc.
+	ctx.lnd.ConfChannel <- &chainntnfs.TxConfirmation{
+		Tx: &htlcTx,
+	}
+
+	// Client starts listening for spend of htlc.
+	<-ctx.lnd.RegisterSpendChannel
+
+	// Client starts listening for swap invoice updates.
+	subscription := <-ctx.lnd.SingleInvoiceSubcribeChannel
+
+	// Client keeps watching for further deposits.
+	<-ctx.lnd.RegisterConfChannel
+
+	// The swap fails off-chain before the htlc expires. The client
+	// cancels the swap invoice when the server rejects the swap, which lnd
+	// reports on the invoice subscription.
+	subscription.Update <- lndclient.InvoiceUpdate{
+		State: channeldb.ContractCanceled,
+	}
+
+	// Without the cooperation of the server, the deposit is only
+	// refunded once the htlc expires.
+	if serverErr != nil {
+		ctx.blockEpochChan <- loopIn.LoopInContract.CltvExpiry
+	}
+
+	refundTx := <-ctx.lnd.TxPublishChannel
+
+	htlcOutpoint := wire.OutPoint{Hash: htlcTx.TxHash()}
+	if len(refundTx.TxIn) != 1 ||
+		refundTx.TxIn[0].PreviousOutPoint != htlcOutpoint {
+
+		t.Fatal("refund tx doesn't spend htlc")
+	}
+
+	refundScript, err := txscript.PayToAddrScript(testAddr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refundTx.TxOut) != 1 ||
+		!bytes.Equal(refundTx.TxOut[0].PkScript, refundScript) {
+
+		t.Fatal("refund tx doesn't pay to refund address")
+	}
+
+	witness := refundTx.TxIn[0].Witness
+	if serverErr == nil {
+		// The cooperative refund tx is the one that the server
+		// signed, and it carries both signatures.
+		if ctx.server.coopRefundTx.TxHash() != refundTx.TxHash() {
+			t.Fatal("server didn't sign refund tx")
+		}
+		if len(witness) != 5 {
+			t.Fatalf("expected cooperative refund witness, got "+
+				"%v elements", len(witness))
+		}
+	} else if len(witness) != 4 || loopIn.htlc.IsSuccessWitness(witness) {
+		t.Fatal("expected timeout witness")
+	}
+
+	// Confirm refund tx.
+	refundTxHash := refundTx.TxHash()
+	ctx.lnd.SpendChannel <- &chainntnfs.SpendDetail{
+		SpenderTxHash:     &refundTxHash,
+		SpendingTx:        refundTx,
+		SpenderInputIndex: 0,
+	}
+
+	<-ctx.lnd.FailInvoiceChannel
+
+	ctx.assertState(loopdb.StateFailTimeout)
+
+	state := <-ctx.store.loopInUpdateChan
+	if state.State != loopdb.StateFailTimeout {
+		t.Fatalf("expected state %v, got %v", loopdb.StateFailTimeout,
+			state.State)
+	}
+	if state.RefundTxHash == nil || *state.RefundTxHash != refundTxHash {
+		t.Fatalf("expected refund tx %v, got %v", refundTxHash,
+			state.RefundTxHash)
+	}
 
 	err = <-errChan
 	if err != nil {
@@ -233,6 +648,7 @@ func testLoopInResume(t *testing.T, state loopdb.SwapState, expired bool) {
 
 	contract := &loopdb.LoopInContract{
 		HtlcConfTarget: 2,
+		HtlcOutputType: swap.HtlcNP2WSH,
 		SwapContract: loopdb.SwapContract{
 			Preimage:        testPreimage,
 			AmountRequested: 100000,
@@ -258,8 +674,10 @@ func testLoopInResume(t *testing.T, state loopdb.SwapState, expired bool) {
 	}
 
 	htlc, err := swap.NewHtlc(
-		contract.CltvExpiry, contract.SenderKey, contract.ReceiverKey,
-		testPreimage.Hash(), swap.HtlcNP2WSH, cfg.lnd.ChainParams,
+		contract.HtlcVersion, contract.CltvExpiry, contract.SenderKey,
+		contract.ReceiverKey,
+		testPreimage.Hash(), contract.HtlcOutputType,
+		cfg.lnd.ChainParams,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -332,6 +750,7 @@ func testLoopInResume(t *testing.T, state loopdb.SwapState, expired bool) {
 
 		htlcTx.AddTxOut(&wire.TxOut{
 			PkScript: htlc.PkScript,
+			Value:    int64(contract.AmountRequested),
 		})
 	}
 
@@ -352,6 +771,9 @@ func testLoopInResume(t *testing.T, state loopdb.SwapState, expired bool) {
 		t.Fatal("client subscribing to wrong invoice")
 	}
 
+	// Client keeps watching for further deposits.
+	<-ctx.lnd.RegisterConfChannel
+
 	// Server has already paid invoice before spending the htlc. Signal
 	// settled.
 	subscription.Update <- lndclient.InvoiceUpdate{
@@ -366,6 +788,9 @@ func testLoopInResume(t *testing.T, state loopdb.SwapState, expired bool) {
 	// Server spends htlc.
 	successTx := wire.MsgTx{}
 	successTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: wire.OutPoint{
+			Hash: htlcTx.TxHash(),
+		},
 		Witness: [][]byte{{}, {}, {}},
 	})
 	successTxHash := successTx.TxHash()
@@ -378,3 +803,208 @@ func testLoopInResume(t *testing.T, state loopdb.SwapState, expired bool) {
 
 	ctx.assertState(loopdb.StateSuccess)
 }
+
+// TestLoopInResumeDeposits tests that a resumed swap continues the search for
+// deposits from the block of the last known deposit, and finds deposits that
+// confirm after the restart.
+func TestLoopInResumeDeposits(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := newLoopInTestContext(t)
+
+	cfg := &swapConfig{
+		lnd:    &ctx.lnd.LndServices,
+		store:  ctx.store,
+		server: ctx.server,
+	}
+
+	var senderKey, receiverKey [33]byte
+	_, senderPubKey := test.CreateKey(1)
+	copy(senderKey[:], senderPubKey.SerializeCompressed())
+	_, receiverPubKey := test.CreateKey(2)
+	copy(receiverKey[:], receiverPubKey.SerializeCompressed())
+
+	contract := &loopdb.LoopInContract{
+		HtlcConfTarget: 2,
+		HtlcOutputType: swap.HtlcNP2WSH,
+		SwapContract: loopdb.SwapContract{
+			Preimage:         testPreimage,
+			AmountRequested:  100000,
+			CltvExpiry:       744,
+			ReceiverKey:      receiverKey,
+			SenderKey:        senderKey,
+			MaxSwapFee:       60000,
+			MaxMinerFee:      50000,
+			InitiationHeight: 600,
+		},
+	}
+
+	htlc, err := swap.NewHtlc(
+		contract.HtlcVersion, contract.CltvExpiry, contract.SenderKey,
+		contract.ReceiverKey, testPreimage.Hash(),
+		contract.HtlcOutputType, cfg.lnd.ChainParams,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The deposit of the swap amount was found before the restart.
+	htlcTx := wire.MsgTx{}
+	htlcTx.AddTxOut(&wire.TxOut{
+		PkScript: htlc.PkScript,
+		Value:    int64(contract.AmountRequested),
+	})
+	deposit := &loopdb.LoopInDeposit{
+		Outpoint:   wire.OutPoint{Hash: htlcTx.TxHash()},
+		Value:      contract.AmountRequested,
+		ConfHeight: 605,
+		Accepted:   true,
+		State:      loopdb.DepositConfirmed,
+	}
+
+	err = ctx.store.CreateLoopIn(testPreimage.Hash(), contract)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.store.StoreLoopInDeposit(testPreimage.Hash(), deposit)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pendSwap := &loopdb.LoopIn{
+		Contract: contract,
+		Loop: loopdb.Loop{
+			Events: []*loopdb.LoopEvent{
+				{
+					SwapStateData: loopdb.SwapStateData{
+						State: loopdb.StateHtlcPublished,
+					},
+				},
+			},
+			Hash: testPreimage.Hash(),
+		},
+		Deposits: []*loopdb.LoopInDeposit{deposit},
+	}
+
+	swap, err := resumeLoopInSwap(context.Background(), cfg, pendSwap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errChan := make(chan error)
+	go func() {
+		err := swap.execute(context.Background(), ctx.cfg, 610)
+		if err != nil {
+			log.Error(err)
+		}
+		errChan <- err
+	}()
+
+	ctx.assertState(loopdb.StateHtlcPublished)
+
+	assertConfHint := func(expected int32) {
+		t.Helper()
+
+		registration := <-ctx.lnd.RegisterConfChannel
+		if registration.HeightHint != expected {
+			t.Fatalf("expected height hint %v, got %v", expected,
+				registration.HeightHint)
+		}
+	}
+
+	// The client resumes watching the deposit and the swap invoice, and
+	// searches for further deposits from the block of the deposit on.
+	<-ctx.lnd.RegisterSpendChannel
+	subscription := <-ctx.lnd.SingleInvoiceSubcribeChannel
+	assertConfHint(605)
+
+	// The notifier reports the known deposit again. The search moves past
+	// its block.
+	ctx.lnd.ConfChannel <- &chainntnfs.TxConfirmation{
+		BlockHeight: 605,
+		Tx:          &htlcTx,
+	}
+	assertConfHint(606)
+
+	// An extra deposit confirms after the restart.
+	extraTx := wire.MsgTx{}
+	extraTx.AddTxOut(&wire.TxOut{
+		PkScript: htlc.PkScript,
+		Value:    int64(contract.AmountRequested),
+	})
+	extraTx.LockTime = 1
+	ctx.lnd.ConfChannel <- &chainntnfs.TxConfirmation{
+		BlockHeight: 612,
+		Tx:          &extraTx,
+	}
+
+	<-ctx.lnd.RegisterSpendChannel
+	assertConfHint(612)
+
+	// The extra deposit is reported as rejected.
+	update := <-ctx.statusChan
+	if len(update.Deposits) != 2 {
+		t.Fatalf("expected two deposits, got %v", update.Deposits)
+	}
+	extraOutpoint := wire.OutPoint{Hash: extraTx.TxHash()}
+	for _, deposit := range update.Deposits {
+		if deposit.Accepted == (deposit.Outpoint == extraOutpoint) {
+			t.Fatalf("unexpected deposit %v", deposit)
+		}
+	}
+
+	// Server pays the invoice and sweeps the accepted deposit.
+	subscription.Update <- lndclient.InvoiceUpdate{
+		State:   channeldb.ContractSettled,
+		AmtPaid: 49000,
+	}
+
+	ctx.assertState(loopdb.StateInvoiceSettled)
+	ctx.store.assertLoopInState(loopdb.StateInvoiceSettled)
+
+	successTx := wire.MsgTx{}
+	successTx.AddTxIn(&wire.TxIn{
+		PreviousOutPoint: deposit.Outpoint,
+		Witness:          [][]byte{{}, {}, {}},
+	})
+	successTxHash := successTx.TxHash()
+
+	ctx.lnd.SpendChannel <- &chainntnfs.SpendDetail{
+		SpendingTx:        &successTx,
+		SpenderTxHash:     &successTxHash,
+		SpenderInputIndex: 0,
+	}
+
+	update = <-ctx.statusChan
+	if update.State != loopdb.StateSuccess {
+		t.Fatalf("expected state %v, got %v", loopdb.StateSuccess,
+			update.State)
+	}
+
+	// Let htlc expire. The extra deposit is refunded.
+	ctx.blockEpochChan <- contract.CltvExpiry
+
+	timeoutTx := <-ctx.lnd.TxPublishChannel
+	if timeoutTx.TxIn[0].PreviousOutPoint != extraOutpoint {
+		t.Fatal("timeout tx doesn't spend the extra deposit")
+	}
+
+	timeoutTxHash := timeoutTx.TxHash()
+	ctx.lnd.SpendChannel <- &chainntnfs.SpendDetail{
+		SpendingTx:        timeoutTx,
+		SpenderTxHash:     &timeoutTxHash,
+		SpenderInputIndex: 0,
+	}
+
+	update = <-ctx.statusChan
+	if update.State != loopdb.StateSuccess {
+		t.Fatalf("expected state %v, got %v", loopdb.StateSuccess,
+			update.State)
+	}
+	<-ctx.store.loopInUpdateChan
+
+	err = <-errChan
+	if err != nil {
+		t.Fatal(err)
+	}
+}
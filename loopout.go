@@ -32,6 +32,14 @@ var (
 	//
 	// TODO(wilmer): tune?
 	DefaultSweepConfTargetDelta = DefaultSweepConfTarget * 2
+
+	// DefaultSweepSafetyMargin is the default number of blocks before the
+	// htlc expiry at which a sweep with a fee rate ceiling is escalated.
+	DefaultSweepSafetyMargin = DefaultSweepConfTargetDelta
+
+	// minSweepConfTarget is the lowest confirmation target that is used
+	// when a sweep is escalated.
+	minSweepConfTarget int32 = 2
 )
 
 // loopOutSwap contains all the in-memory state related to a pending loop out
@@ -41,8 +49,17 @@ type loopOutSwap struct {
 
 	loopdb.LoopOutContract
 
-	swapPaymentChan chan lndclient.PaymentResult
-	prePaymentChan  chan lndclient.PaymentResult
+	// resumed indicates that the swap was restored from the database. The
+	// off-chain payments of a resumed swap may have been sent in a
+	// previous run already.
+	resumed bool
+
+	swapPaymentChan chan paymentResult
+	prePaymentChan  chan paymentResult
+
+	// htlcPublished is closed once the htlc of the server is on chain.
+	// Failed off-chain payments aren't retried after that.
+	htlcPublished chan struct{}
 }
 
 // executeConfig contains extra configuration to execute the swap.
@@ -51,12 +68,22 @@ type executeConfig struct {
 	statusChan     chan<- SwapInfo
 	blockEpochChan <-chan interface{}
 	timerFactory   func(d time.Duration) <-chan time.Time
+
+	paymentRetryPolicy PaymentRetryPolicy
 }
 
 // newLoopOutSwap initiates a new swap with the server and returns a
-// corresponding swap object.
+// corresponding swap object. If the swap is based on a quote, the quote is
+// passed in so that the server invoices can be checked against it.
 func newLoopOutSwap(globalCtx context.Context, cfg *swapConfig,
-	currentHeight int32, request *OutRequest) (*loopOutSwap, error) {
+	currentHeight int32, request *OutRequest,
+	quote *LoopOutQuote) (*loopOutSwap, error) {
+
+	// Check the sweep outputs before contacting the server, so that we
+	// don't initiate a swap that can't be swept.
+	if err := validateSweepOutputs(request); err != nil {
+		return nil, err
+	}
 
 	// Generate random preimage.
 	var swapPreimage [32]byte
@@ -83,14 +110,14 @@ func newLoopOutSwap(globalCtx context.Context, cfg *swapConfig,
 	// latest swap publication time.
 	swapResp, err := cfg.server.NewLoopOutSwap(
 		globalCtx, swapHash, request.Amount, receiverKey,
-		request.SwapPublicationDeadline,
+		request.SwapPublicationDeadline, request.QuoteID,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("cannot initiate swap: %v", err)
 	}
 
 	err = validateLoopOutContract(
-		cfg.lnd, currentHeight, request, swapHash, swapResp,
+		cfg.lnd, currentHeight, request, quote, swapHash, swapResp,
 	)
 	if err != nil {
 		return nil, err
@@ -109,6 +136,9 @@ func newLoopOutSwap(globalCtx context.Context, cfg *swapConfig,
 		PrepayInvoice:           swapResp.prepayInvoice,
 		MaxPrepayRoutingFee:     request.MaxPrepayRoutingFee,
 		SwapPublicationDeadline: request.SwapPublicationDeadline,
+		SweepFeeRateCeiling:     request.SweepFeeRateCeiling,
+		SweepSafetyMargin:       sweepSafetyMargin(request),
+		SweepOutputs:            request.SweepOutputs,
 		SwapContract: loopdb.SwapContract{
 			InitiationHeight: currentHeight,
 			InitiationTime:   initiationTime,
@@ -119,6 +149,12 @@ func newLoopOutSwap(globalCtx context.Context, cfg *swapConfig,
 			CltvExpiry:       swapResp.expiry,
 			MaxMinerFee:      request.MaxMinerFee,
 			MaxSwapFee:       request.MaxSwapFee,
+			HtlcVersion:      swapResp.htlcVersion,
+			ServerID:         cfg.serverID,
+			IdempotencyKey:   request.IdempotencyKey,
+			RequestDigest:    request.RequestDigest,
+			Label:            request.Label,
+			Metadata:         request.Metadata,
 		},
 	}
 
@@ -130,11 +166,21 @@ func newLoopOutSwap(globalCtx context.Context, cfg *swapConfig,
 		return nil, err
 	}
 
+	// The htlc address is only known now that the server has provided
+	// its key. Sweeping to it would lock the funds in the htlc again.
+	for _, addr := range destAddrs(request) {
+		if addr.String() == swapKit.htlc.Address.String() {
+			return nil, ErrDestAddrIsHtlc
+		}
+	}
+
 	swapKit.lastUpdateTime = initiationTime
+	swapKit.sweepStrategy = newSweepStrategy(&contract)
 
 	swap := &loopOutSwap{
 		LoopOutContract: contract,
 		swapKit:         *swapKit,
+		htlcPublished:   make(chan struct{}),
 	}
 
 	// Persist the data before exiting this function, so that the caller
@@ -163,10 +209,13 @@ func resumeLoopOutSwap(reqContext context.Context, cfg *swapConfig,
 	if err != nil {
 		return nil, err
 	}
+	swapKit.sweepStrategy = newSweepStrategy(pend.Contract)
 
 	swap := &loopOutSwap{
 		LoopOutContract: *pend.Contract,
 		swapKit:         *swapKit,
+		resumed:         true,
+		htlcPublished:   make(chan struct{}),
 	}
 
 	lastUpdate := pend.LastUpdate()
@@ -177,6 +226,11 @@ func resumeLoopOutSwap(reqContext context.Context, cfg *swapConfig,
 		swap.lastUpdateTime = lastUpdate.Time
 	}
 
+	// The preimage is only revealed after the htlc was confirmed.
+	if swap.state == loopdb.StatePreimageRevealed {
+		swap.setHtlcPublished()
+	}
+
 	return swap, nil
 }
 
@@ -236,27 +290,27 @@ func (s *loopOutSwap) executeAndFinalize(globalCtx context.Context) error {
 		select {
 		case result := <-s.swapPaymentChan:
 			s.swapPaymentChan = nil
-			if result.Err != nil {
+			if result.err != nil {
 				// Server didn't pull the swap payment.
 				s.log.Infof("Swap payment failed: %v",
-					result.Err)
+					result.err)
 
 				continue
 			}
-			s.cost.Server += result.PaidAmt
-			s.cost.Offchain += result.PaidFee
+			s.cost.Server += result.paidAmt
+			s.cost.Offchain += result.paidFee
 
 		case result := <-s.prePaymentChan:
 			s.prePaymentChan = nil
-			if result.Err != nil {
+			if result.err != nil {
 				// Server didn't pull the prepayment.
 				s.log.Infof("Prepayment failed: %v",
-					result.Err)
+					result.err)
 
 				continue
 			}
-			s.cost.Server += result.PaidAmt
-			s.cost.Offchain += result.PaidFee
+			s.cost.Server += result.paidAmt
+			s.cost.Offchain += result.paidFee
 
 		case <-globalCtx.Done():
 			return globalCtx.Err()
@@ -278,8 +332,8 @@ func (s *loopOutSwap) executeAndFinalize(globalCtx context.Context) error {
 // executeSwap executes the swap, but returns as soon as the swap outcome is
 // final. At that point, there may still be pending off-chain payment(s).
 func (s *loopOutSwap) executeSwap(globalCtx context.Context) error {
-	// We always pay both invoices (again). This is currently the only way
-	// to sort of resume payments.
+	// We always pay both invoices. If the swap was resumed, payments that
+	// were sent in a previous run are picked up instead of sent again.
 	//
 	// TODO: We shouldn't pay the invoices if it is already too late to
 	// start the swap. But because we don't know if we already fired the
@@ -368,8 +422,9 @@ func (s *loopOutSwap) persistState(ctx context.Context) error {
 	err := s.store.UpdateLoopOut(
 		s.hash, updateTime,
 		loopdb.SwapStateData{
-			State: s.state,
-			Cost:  s.cost,
+			State:          s.state,
+			Cost:           s.cost,
+			PaymentFailure: s.paymentFailure,
 		},
 	)
 	if err != nil {
@@ -384,19 +439,33 @@ func (s *loopOutSwap) persistState(ctx context.Context) error {
 func (s *loopOutSwap) payInvoices(ctx context.Context) {
 	// Pay the swap invoice.
 	s.log.Infof("Sending swap payment %v", s.SwapInvoice)
-	s.swapPaymentChan = s.lnd.Client.PayInvoice(
-		ctx, s.SwapInvoice, s.MaxSwapRoutingFee,
-		s.LoopOutContract.UnchargeChannel,
+	s.swapPaymentChan = s.payInvoice(
+		ctx, loopdb.PaymentTypeSwap, s.SwapInvoice,
+		s.MaxSwapRoutingFee, s.LoopOutContract.UnchargeChannel,
 	)
 
 	// Pay the prepay invoice.
 	s.log.Infof("Sending prepayment %v", s.PrepayInvoice)
-	s.prePaymentChan = s.lnd.Client.PayInvoice(
-		ctx, s.PrepayInvoice, s.MaxPrepayRoutingFee,
-		nil,
+	s.prePaymentChan = s.payInvoice(
+		ctx, loopdb.PaymentTypePrepay, s.PrepayInvoice,
+		s.MaxPrepayRoutingFee, nil,
 	)
 }
 
+// failOffchain moves the swap to the final off-chain payment failure state
+// and records which payment failed and why.
+func (s *loopOutSwap) failOffchain(payment loopdb.PaymentType,
+	result paymentResult) {
+
+	s.log.Infof("Failed %v: %v", payment, result.err)
+
+	s.state = loopdb.StateFailOffchainPayments
+	s.paymentFailure = &loopdb.PaymentFailure{
+		Payment: payment,
+		Reason:  result.failure,
+	}
+}
+
 // waitForConfirmedHtlc waits for a confirmed htlc to appear on the chain. In
 // case we haven't revealed the preimage yet, it also monitors block height and
 // off-chain payment failure.
@@ -462,30 +531,28 @@ func (s *loopOutSwap) waitForConfirmedHtlc(globalCtx context.Context) (
 			// have lost the prepayment.
 			case result := <-s.swapPaymentChan:
 				s.swapPaymentChan = nil
-				if result.Err != nil {
-					s.state = loopdb.StateFailOffchainPayments
-					s.log.Infof("Failed swap payment: %v",
-						result.Err)
-
+				if result.err != nil {
+					s.failOffchain(
+						loopdb.PaymentTypeSwap, result,
+					)
 					return nil, nil
 				}
-				s.cost.Server += result.PaidAmt
-				s.cost.Offchain += result.PaidFee
+				s.cost.Server += result.paidAmt
+				s.cost.Offchain += result.paidFee
 
 			// If the prepay fails, abandon the swap. Because we
 			// didn't reveal the preimage, the swap payment will be
 			// canceled or time out.
 			case result := <-s.prePaymentChan:
 				s.prePaymentChan = nil
-				if result.Err != nil {
-					s.state = loopdb.StateFailOffchainPayments
-					s.log.Infof("Failed prepayment: %v",
-						result.Err)
-
+				if result.err != nil {
+					s.failOffchain(
+						loopdb.PaymentTypePrepay, result,
+					)
 					return nil, nil
 				}
-				s.cost.Server += result.PaidAmt
-				s.cost.Offchain += result.PaidFee
+				s.cost.Server += result.paidAmt
+				s.cost.Offchain += result.paidFee
 
 			// Unexpected error on the confirm channel happened,
 			// abandon the swap.
@@ -514,6 +581,7 @@ func (s *loopOutSwap) waitForConfirmedHtlc(globalCtx context.Context) (
 		}
 
 		s.log.Infof("Swap script confirmed on chain")
+		s.setHtlcPublished()
 
 	} else {
 		s.log.Infof("Retrieving htlc onchain")
@@ -533,6 +601,16 @@ func (s *loopOutSwap) waitForConfirmedHtlc(globalCtx context.Context) (
 	return txConf, nil
 }
 
+// setHtlcPublished records that the htlc of the server is on chain, which
+// stops the retries of failed off-chain payments.
+func (s *loopOutSwap) setHtlcPublished() {
+	select {
+	case <-s.htlcPublished:
+	default:
+		close(s.htlcPublished)
+	}
+}
+
 // waitForHtlcSpendConfirmed waits for the htlc to be spent either by our own
 // sweep or a server revocation tx. During this process, this function will try
 // to spend the htlc every block by calling spendFunc.
@@ -601,17 +679,40 @@ func (s *loopOutSwap) sweep(ctx context.Context,
 	}
 
 	// Calculate the transaction fee based on the confirmation target
-	// required to sweep the HTLC before the timeout. We'll use the
-	// confirmation target provided by the client unless we've come too
-	// close to the expiration height, in which case we'll use the default
-	// if it is better than what the client provided.
-	confTarget := s.SweepConfTarget
-	if s.CltvExpiry-s.height <= DefaultSweepConfTargetDelta &&
-		confTarget > DefaultSweepConfTarget {
-		confTarget = DefaultSweepConfTarget
+	// required to sweep the HTLC before the timeout.
+	confTarget, escalated := s.sweepConfTarget()
+	feeRate, err := s.lnd.WalletKit.EstimateFee(ctx, confTarget)
+	if err != nil {
+		return fmt.Errorf("estimate fee: %v", err)
 	}
-	fee, err := s.sweeper.GetSweepFee(
-		ctx, s.htlc.AddSuccessToEstimator, s.DestAddr, confTarget,
+
+	status := SweepStatusPublished
+	if escalated {
+		status = SweepStatusEscalated
+	}
+
+	// Outside of the safety margin, we don't sweep above the fee rate
+	// ceiling.
+	ceiling := s.SweepFeeRateCeiling
+	if ceiling != 0 && !escalated && feeRate > ceiling {
+		s.log.Infof("Fee rate %v exceeds ceiling of %v, %v blocks "+
+			"left until safety margin", feeRate, ceiling,
+			s.CltvExpiry-s.height-s.SweepSafetyMargin)
+
+		if s.state != loopdb.StatePreimageRevealed {
+			s.log.Infof("Not revealing preimage")
+			return s.setSweepStatus(ctx, SweepStatusWaiting)
+		}
+
+		// The preimage is already revealed, so we keep the sweep at
+		// the ceiling until we reach the safety margin.
+		feeRate = ceiling
+	}
+
+	sweepOutputs := s.sweepOutputs()
+	fee, err := s.sweeper.GetSweepFeeForRate(
+		s.htlc.AddSuccessToEstimator, sweepOutputAddrs(sweepOutputs),
+		feeRate,
 	)
 	if err != nil {
 		return err
@@ -629,14 +730,19 @@ func (s *loopOutSwap) sweep(ctx context.Context,
 			fee = s.MaxMinerFee
 		} else {
 			s.log.Warnf("Not revealing preimage")
-			return nil
+			return s.setSweepStatus(ctx, SweepStatusWaiting)
 		}
 	}
 
 	// Create sweep tx.
+	txOuts, err := sweepTxOuts(sweepOutputs, htlcValue-fee)
+	if err != nil {
+		return err
+	}
+
 	sweepTx, err := s.sweeper.CreateSweepTx(
 		ctx, s.height, s.htlc, htlcOutpoint, s.ReceiverKey, witnessFunc,
-		htlcValue, fee, s.DestAddr,
+		htlcValue, txOuts,
 	)
 	if err != nil {
 		return err
@@ -644,9 +750,12 @@ func (s *loopOutSwap) sweep(ctx context.Context,
 
 	// Before publishing the tx, already mark the preimage as revealed. This
 	// is a precaution in case the publish call never returns and would
-	// leave us thinking we didn't reveal yet.
-	if s.state != loopdb.StatePreimageRevealed {
+	// leave us thinking we didn't reveal yet. The update for the revealed
+	// state also reports the sweep status.
+	revealed := s.state == loopdb.StatePreimageRevealed
+	if !revealed {
 		s.state = loopdb.StatePreimageRevealed
+		s.sweepStrategy.Status = status
 
 		err := s.persistState(ctx)
 		if err != nil {
@@ -655,22 +764,118 @@ func (s *loopOutSwap) sweep(ctx context.Context,
 	}
 
 	// Publish tx.
-	s.log.Infof("Sweep on chain HTLC to address %v with fee %v (tx %v)",
-		s.DestAddr, fee, sweepTx.TxHash())
+	s.log.Infof("Sweep on chain HTLC to %v output(s) with fee %v (tx %v)",
+		len(txOuts), fee, sweepTx.TxHash())
 
 	err = s.lnd.WalletKit.PublishTransaction(ctx, sweepTx)
 	if err != nil {
 		s.log.Warnf("Publish sweep: %v", err)
+	} else {
+		labelTx(ctx, s.lnd.WalletKit, s.Label, sweepTx)
+	}
+
+	if revealed {
+		return s.setSweepStatus(ctx, status)
 	}
 
 	return nil
 }
 
+// sweepOutputs returns the outputs of the sweep tx. Swaps without explicit
+// sweep outputs sweep everything to their destination address.
+func (s *loopOutSwap) sweepOutputs() []loopdb.SweepOutput {
+	if len(s.SweepOutputs) != 0 {
+		return s.SweepOutputs
+	}
+
+	return []loopdb.SweepOutput{{Addr: s.DestAddr, Weight: 1}}
+}
+
+// sweepConfTarget returns the confirmation target for the sweep at the
+// current height and whether the sweep is escalated because the swap reached
+// its safety margin.
+func (s *loopOutSwap) sweepConfTarget() (int32, bool) {
+	blocksLeft := s.CltvExpiry - s.height
+	confTarget := s.SweepConfTarget
+
+	// Without a fee rate ceiling, we'll use the confirmation target
+	// provided by the client unless we've come too close to the
+	// expiration height, in which case we'll use the default if it is
+	// better than what the client provided.
+	if s.SweepFeeRateCeiling == 0 {
+		if blocksLeft <= DefaultSweepConfTargetDelta &&
+			confTarget > DefaultSweepConfTarget {
+
+			confTarget = DefaultSweepConfTarget
+		}
+
+		return confTarget, false
+	}
+
+	if blocksLeft > s.SweepSafetyMargin {
+		return confTarget, false
+	}
+
+	// Within the safety margin, we aim to confirm within half of the
+	// remaining blocks, but never use a target beyond the default.
+	escalatedTarget := blocksLeft / 2
+	if escalatedTarget > DefaultSweepConfTarget {
+		escalatedTarget = DefaultSweepConfTarget
+	}
+	if escalatedTarget < minSweepConfTarget {
+		escalatedTarget = minSweepConfTarget
+	}
+	if escalatedTarget < confTarget {
+		confTarget = escalatedTarget
+	}
+
+	return confTarget, true
+}
+
+// setSweepStatus records the most recent sweep decision and reports it if it
+// changed.
+func (s *loopOutSwap) setSweepStatus(ctx context.Context,
+	status SweepStatus) error {
+
+	if s.sweepStrategy.Status == status {
+		return nil
+	}
+
+	s.log.Infof("Sweep status %v", status)
+	s.sweepStrategy.Status = status
+
+	return s.sendUpdate(ctx)
+}
+
+// newSweepStrategy returns the sweep strategy of a loop out contract.
+func newSweepStrategy(contract *loopdb.LoopOutContract) *SweepStrategy {
+	return &SweepStrategy{
+		ConfTarget:     contract.SweepConfTarget,
+		FeeRateCeiling: contract.SweepFeeRateCeiling,
+		SafetyMargin:   contract.SweepSafetyMargin,
+	}
+}
+
+// sweepSafetyMargin returns the safety margin of the sweep of a loop out
+// request. It is only set if the request has a fee rate ceiling.
+func sweepSafetyMargin(request *OutRequest) int32 {
+	switch {
+	case request.SweepFeeRateCeiling == 0:
+		return 0
+
+	case request.SweepSafetyMargin == 0:
+		return DefaultSweepSafetyMargin
+
+	default:
+		return request.SweepSafetyMargin
+	}
+}
+
 // validateLoopOutContract validates the contract parameters against our
 // request.
 func validateLoopOutContract(lnd *lndclient.LndServices,
-	height int32, request *OutRequest, swapHash lntypes.Hash,
-	response *newLoopOutResponse) error {
+	height int32, request *OutRequest, quote *LoopOutQuote,
+	swapHash lntypes.Hash, response *newLoopOutResponse) error {
 
 	// Check invoice amounts.
 	chainParams := lnd.ChainParams
@@ -710,6 +915,24 @@ func validateLoopOutContract(lnd *lndclient.LndServices,
 		return ErrPrepayAmountTooHigh
 	}
 
+	// If the swap is based on a quote, the server must stick to the quoted
+	// figures, even if our limits would allow for more.
+	if quote != nil {
+		if swapFee > quote.SwapFee {
+			log.Warnf("Swap fee %v exceeding quoted fee %v",
+				swapFee, quote.SwapFee)
+
+			return ErrSwapFeeTooHigh
+		}
+
+		if prepayInvoiceAmt > quote.PrepayAmount {
+			log.Warnf("Prepay amount %v exceeding quoted amount %v",
+				prepayInvoiceAmt, quote.PrepayAmount)
+
+			return ErrPrepayAmountTooHigh
+		}
+	}
+
 	if response.expiry-height < MinLoopOutPreimageRevealDelta {
 		log.Warnf("Proposed expiry %v (delta %v) too soon",
 			response.expiry, response.expiry-height)
@@ -723,5 +946,14 @@ func validateLoopOutContract(lnd *lndclient.LndServices,
 		return ErrSweepConfTargetTooFar
 	}
 
+	// With a fee rate ceiling, there must be room to wait for the fee
+	// rate before the swap reaches its safety margin.
+	if request.SweepFeeRateCeiling != 0 &&
+		height+request.SweepConfTarget >=
+			response.expiry-sweepSafetyMargin(request) {
+
+		return ErrSweepSafetyMarginTooLarge
+	}
+
 	return nil
 }
@@ -7,12 +7,18 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcd/blockchain"
+	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 	"github.com/lightninglabs/loop/lndclient"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/sweep"
 	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+	"github.com/lightningnetwork/lnd/lnwire"
+	"github.com/lightningnetwork/lnd/zpay32"
 )
 
 // TestLateHtlcPublish tests that the client is not revealing the preimage if
@@ -42,7 +48,7 @@ func TestLateHtlcPublish(t *testing.T) {
 	}
 
 	swap, err := newLoopOutSwap(
-		context.Background(), cfg, height, testRequest,
+		context.Background(), cfg, height, testRequest, nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -127,7 +133,7 @@ func TestCustomSweepConfTarget(t *testing.T) {
 		server: newServerMock(),
 	}
 	swap, err := newLoopOutSwap(
-		context.Background(), cfg, ctx.Lnd.Height, testRequest,
+		context.Background(), cfg, ctx.Lnd.Height, testRequest, nil,
 	)
 	if err != nil {
 		t.Fatal(err)
@@ -265,3 +271,424 @@ func TestCustomSweepConfTarget(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// TestSweepFeeRateCeiling tests that a loop out swap with a sweep fee rate
+// ceiling waits for the fee rate to drop before revealing the preimage, caps
+// its sweep at the ceiling afterwards and escalates once it reaches its safety
+// margin.
+func TestSweepFeeRateCeiling(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+	ctx := test.NewContext(t, lnd)
+
+	request := *testRequest
+	request.SweepConfTarget = 10
+	request.SweepFeeRateCeiling = 1000
+	request.SweepSafetyMargin = 10
+	request.Label = "treasury"
+
+	// The escalated confirmation target is half of the remaining blocks
+	// once the safety margin is reached.
+	escalatedConfTarget := request.SweepSafetyMargin / 2
+
+	ctx.Lnd.SetFeeEstimate(request.SweepConfTarget, 5000)
+	ctx.Lnd.SetFeeEstimate(escalatedConfTarget, 8000)
+
+	cfg := &swapConfig{
+		lnd:    &lnd.LndServices,
+		store:  newStoreMock(t),
+		server: newServerMock(),
+	}
+	swap, err := newLoopOutSwap(
+		context.Background(), cfg, ctx.Lnd.Height, &request, nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sweeper := &sweep.Sweeper{Lnd: &lnd.LndServices}
+	blockEpochChan := make(chan interface{})
+	statusChan := make(chan SwapInfo)
+	expiryChan := make(chan time.Time)
+	timerFactory := func(expiry time.Duration) <-chan time.Time {
+		return expiryChan
+	}
+
+	errChan := make(chan error)
+	go func() {
+		err := swap.execute(context.Background(), &executeConfig{
+			statusChan:     statusChan,
+			blockEpochChan: blockEpochChan,
+			timerFactory:   timerFactory,
+			sweeper:        sweeper,
+		}, ctx.Lnd.Height)
+		if err != nil {
+			log.Error(err)
+		}
+		errChan <- err
+	}()
+
+	// assertStatus asserts the next status update of the swap.
+	assertStatus := func(expectedState loopdb.SwapState,
+		expectedStatus SweepStatus) {
+
+		t.Helper()
+
+		status := <-statusChan
+		if status.State != expectedState {
+			t.Fatalf("expected state %v, got %v", expectedState,
+				status.State)
+		}
+
+		strategy := status.SweepStrategy
+		if strategy.FeeRateCeiling != 1000 ||
+			strategy.SafetyMargin != 10 ||
+			strategy.ConfTarget != request.SweepConfTarget {
+
+			t.Fatalf("unexpected sweep strategy %v", strategy)
+		}
+		if strategy.Status != expectedStatus {
+			t.Fatalf("expected sweep status %v, got %v",
+				expectedStatus, strategy.Status)
+		}
+	}
+
+	cfg.store.(*storeMock).assertLoopOutStored()
+	assertStatus(loopdb.StateInitiated, SweepStatusNone)
+
+	signalSwapPaymentResult := ctx.AssertPaid(swapInvoiceDesc)
+	signalPrepaymentResult := ctx.AssertPaid(prepayInvoiceDesc)
+	signalSwapPaymentResult(nil)
+	signalPrepaymentResult(nil)
+
+	ctx.AssertRegisterConf()
+	blockEpochChan <- ctx.Lnd.Height + 1
+
+	htlcTx := wire.NewMsgTx(2)
+	htlcTx.AddTxOut(&wire.TxOut{
+		Value:    int64(swap.AmountRequested),
+		PkScript: swap.htlc.PkScript,
+	})
+	ctx.NotifyConf(htlcTx)
+	ctx.AssertRegisterSpendNtfn(swap.htlc.PkScript)
+
+	// The fee rate exceeds the ceiling, so the preimage isn't revealed.
+	expiryChan <- time.Now()
+	assertStatus(loopdb.StateInitiated, SweepStatusWaiting)
+
+	// assertSweepFee asserts that the next sweep tx pays the fee for the
+	// given fee rate.
+	assertSweepFee := func(feeRate chainfee.SatPerKWeight) *wire.MsgTx {
+		t.Helper()
+
+		sweepTx := ctx.ReceiveTx()
+
+		expectedFee, err := sweeper.GetSweepFeeForRate(
+			swap.htlc.AddSuccessToEstimator,
+			[]btcutil.Address{swap.DestAddr}, feeRate,
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fee := btcutil.Amount(
+			htlcTx.TxOut[0].Value - sweepTx.TxOut[0].Value,
+		)
+		if fee != expectedFee {
+			t.Fatalf("expected sweep fee %v, got %v", expectedFee,
+				fee)
+		}
+
+		return sweepTx
+	}
+
+	// Once the fee rate drops below the ceiling, the htlc is swept.
+	ctx.Lnd.SetFeeEstimate(request.SweepConfTarget, 800)
+	blockEpochChan <- ctx.Lnd.Height + 2
+	expiryChan <- time.Now()
+
+	cfg.store.(*storeMock).assertLoopOutState(loopdb.StatePreimageRevealed)
+	assertStatus(loopdb.StatePreimageRevealed, SweepStatusPublished)
+	assertSweepFee(800)
+
+	// If the fee rate rises again after the preimage was revealed, the
+	// sweep is republished at the ceiling.
+	ctx.Lnd.SetFeeEstimate(request.SweepConfTarget, 5000)
+	blockEpochChan <- ctx.Lnd.Height + 3
+	expiryChan <- time.Now()
+	assertSweepFee(1000)
+
+	// When the safety margin is reached, the sweep is escalated.
+	blockEpochChan <- swap.CltvExpiry - request.SweepSafetyMargin
+	expiryChan <- time.Now()
+	sweepTx := assertSweepFee(8000)
+	assertStatus(loopdb.StatePreimageRevealed, SweepStatusEscalated)
+
+	ctx.NotifySpend(sweepTx, 0)
+
+	cfg.store.(*storeMock).assertLoopOutState(loopdb.StateSuccess)
+	assertStatus(loopdb.StateSuccess, SweepStatusEscalated)
+
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+
+	// The swap label is attached to the published sweep.
+	if label := ctx.Lnd.TxLabel(sweepTx.TxHash()); label != "treasury" {
+		t.Fatalf("expected sweep label treasury, got %v", label)
+	}
+}
+
+// TestPaymentRetry tests that failed swap payments are retried over different
+// outgoing channels until the maximum number of attempts is reached, and that
+// failures that can't be resolved by another route, payments of expired
+// invoices and payments of swaps with a published htlc are not retried.
+func TestPaymentRetry(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+	lnd.Channels = []lndclient.ChannelInfo{
+		{ChannelID: 1, Active: true, LocalBalance: 100000},
+		{ChannelID: 2, Active: true, LocalBalance: 200000},
+		{ChannelID: 3, Active: false, LocalBalance: 300000},
+		{ChannelID: 4, Active: true, LocalBalance: 1000},
+	}
+
+	cfg := &swapConfig{
+		lnd:    &lnd.LndServices,
+		store:  newStoreMock(t),
+		server: newServerMock(),
+	}
+
+	swap, err := newLoopOutSwap(
+		context.Background(), cfg, 600, testRequest, nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	swap.paymentRetryPolicy = PaymentRetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     time.Millisecond,
+	}
+
+	// The invoices of the server mock have expired already.
+	expiredInvoice := swap.SwapInvoice
+
+	payReq, err := zpay32.NewInvoice(
+		&chaincfg.TestNet3Params, swap.hash, time.Now(),
+		zpay32.Description(swapInvoiceDesc),
+		zpay32.Amount(lnwire.NewMSatFromSatoshis(50950)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invoice, err := test.EncodePayReq(payReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pay := func(invoice string) chan paymentResult {
+		return swap.payInvoice(
+			context.Background(), loopdb.PaymentTypeSwap,
+			invoice, swap.MaxSwapRoutingFee, nil,
+		)
+	}
+
+	// failAttempt asserts that the next attempt uses the expected
+	// outgoing channel and fails it with the given state, reporting htlcs
+	// over the given first hops.
+	failAttempt := func(expectedChannel *uint64,
+		state routerrpc.PaymentState, firstHops ...uint64) {
+
+		t.Helper()
+
+		attempt := <-lnd.RouterSendPaymentChannel
+		switch {
+		case expectedChannel == nil && attempt.OutgoingChannel != nil:
+			t.Fatalf("expected no outgoing channel, got %v",
+				*attempt.OutgoingChannel)
+
+		case expectedChannel != nil && (attempt.OutgoingChannel == nil ||
+			*attempt.OutgoingChannel != *expectedChannel):
+
+			t.Fatalf("expected outgoing channel %v",
+				*expectedChannel)
+		}
+
+		attempt.Updates <- lndclient.PaymentStatus{
+			State:     state,
+			FirstHops: firstHops,
+		}
+	}
+
+	// The retries use the active channels that can carry the payment, in
+	// order of decreasing local balance.
+	channel1, channel2 := uint64(1), uint64(2)
+	resultChan := pay(invoice)
+	failAttempt(nil, routerrpc.PaymentState_FAILED_NO_ROUTE)
+	failAttempt(
+		&channel2, routerrpc.PaymentState_FAILED_INSUFFICIENT_BALANCE,
+	)
+	failAttempt(&channel1, routerrpc.PaymentState_FAILED_NO_ROUTE)
+
+	result := <-resultChan
+	if result.failure != loopdb.FailureReasonNoRoute {
+		t.Fatalf("expected no route failure, got %v", result.failure)
+	}
+
+	// The channels that lnd picked by itself are not selected for the
+	// retries.
+	resultChan = pay(invoice)
+	failAttempt(nil, routerrpc.PaymentState_FAILED_NO_ROUTE, 2)
+	failAttempt(&channel1, routerrpc.PaymentState_FAILED_NO_ROUTE)
+	failAttempt(nil, routerrpc.PaymentState_FAILED_NO_ROUTE)
+
+	result = <-resultChan
+	if result.failure != loopdb.FailureReasonNoRoute {
+		t.Fatalf("expected no route failure, got %v", result.failure)
+	}
+
+	// A payment that is rejected by the server is not retried.
+	resultChan = pay(invoice)
+	failAttempt(
+		nil, routerrpc.PaymentState_FAILED_INCORRECT_PAYMENT_DETAILS,
+	)
+
+	result = <-resultChan
+	if result.failure != loopdb.FailureReasonIncorrectPaymentDetails {
+		t.Fatalf("expected incorrect payment details, got %v",
+			result.failure)
+	}
+
+	// The payment of an expired invoice is not retried either.
+	resultChan = pay(expiredInvoice)
+	failAttempt(nil, routerrpc.PaymentState_FAILED_NO_ROUTE)
+
+	result = <-resultChan
+	if result.failure != loopdb.FailureReasonNoRoute {
+		t.Fatalf("expected no route failure, got %v", result.failure)
+	}
+
+	// Once the htlc is published, failed payments aren't retried.
+	swap.setHtlcPublished()
+
+	resultChan = pay(invoice)
+	failAttempt(nil, routerrpc.PaymentState_FAILED_NO_ROUTE)
+
+	result = <-resultChan
+	if result.failure != loopdb.FailureReasonNoRoute {
+		t.Fatalf("expected no route failure, got %v", result.failure)
+	}
+}
+
+// TestResumePayment asserts that a resumed swap picks up the payments of a
+// previous run before sending new ones.
+func TestResumePayment(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+
+	cfg := &swapConfig{
+		lnd:    &lnd.LndServices,
+		store:  newStoreMock(t),
+		server: newServerMock(),
+	}
+
+	swap, err := newLoopOutSwap(
+		context.Background(), cfg, 600, testRequest, nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	swap.resumed = true
+	swap.paymentRetryPolicy = PaymentRetryPolicy{
+		MaxAttempts: 2,
+		Backoff:     time.Millisecond,
+	}
+
+	payReq, err := zpay32.NewInvoice(
+		&chaincfg.TestNet3Params, swap.hash, time.Now(),
+		zpay32.Description(swapInvoiceDesc),
+		zpay32.Amount(lnwire.NewMSatFromSatoshis(50950)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	invoice, err := test.EncodePayReq(payReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pay := func() chan paymentResult {
+		return swap.payInvoice(
+			context.Background(), loopdb.PaymentTypeSwap,
+			invoice, swap.MaxSwapRoutingFee, nil,
+		)
+	}
+
+	assertTracked := func() test.TrackPaymentMessage {
+		t.Helper()
+
+		payment := <-lnd.TrackPaymentChannel
+		if payment.Hash != swap.hash {
+			t.Fatalf("unexpected payment tracked: %v", payment.Hash)
+		}
+
+		return payment
+	}
+
+	// An existing payment is followed to its final state and the fee that
+	// was paid is reported.
+	resultChan := pay()
+	payment := assertTracked()
+	payment.Updates <- lndclient.PaymentStatus{
+		State: routerrpc.PaymentState_IN_FLIGHT,
+	}
+	payment.Updates <- lndclient.PaymentStatus{
+		State: routerrpc.PaymentState_SUCCEEDED,
+		Fee:   lnwire.NewMSatFromSatoshis(25),
+	}
+
+	result := <-resultChan
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+	if result.paidAmt != 50950 || result.paidFee != 25 {
+		t.Fatalf("unexpected payment result: amt %v, fee %v",
+			result.paidAmt, result.paidFee)
+	}
+
+	// If no payment exists, a new one is sent.
+	resultChan = pay()
+	payment = assertTracked()
+	payment.Errors <- channeldb.ErrPaymentNotInitiated
+
+	attempt := <-lnd.RouterSendPaymentChannel
+	attempt.Updates <- lndclient.PaymentStatus{
+		State: routerrpc.PaymentState_SUCCEEDED,
+	}
+
+	result = <-resultChan
+	if result.err != nil {
+		t.Fatal(result.err)
+	}
+
+	// A failed existing payment counts as the first attempt and is
+	// retried according to the policy.
+	resultChan = pay()
+	payment = assertTracked()
+	payment.Updates <- lndclient.PaymentStatus{
+		State: routerrpc.PaymentState_FAILED_TIMEOUT,
+	}
+
+	attempt = <-lnd.RouterSendPaymentChannel
+	attempt.Updates <- lndclient.PaymentStatus{
+		State: routerrpc.PaymentState_FAILED_NO_ROUTE,
+	}
+
+	result = <-resultChan
+	if result.failure != loopdb.FailureReasonNoRoute {
+		t.Fatalf("expected no route failure, got %v", result.failure)
+	}
+}
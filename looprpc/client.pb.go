@@ -23,6 +23,323 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+type SwapGroupState int32
+
+const (
+	//*
+	//GROUP_PENDING indicates that at least one swap of the group is still
+	//pending.
+	SwapGroupState_GROUP_PENDING SwapGroupState = 0
+	//*
+	//GROUP_SUCCESS indicates that all swaps of the group succeeded.
+	SwapGroupState_GROUP_SUCCESS SwapGroupState = 1
+	//*
+	//GROUP_FAILED indicates that all swaps of the group failed.
+	SwapGroupState_GROUP_FAILED SwapGroupState = 2
+	//*
+	//GROUP_PARTIAL indicates that all swaps of the group are final, but only
+	//some of them succeeded.
+	SwapGroupState_GROUP_PARTIAL SwapGroupState = 3
+)
+
+var SwapGroupState_name = map[int32]string{
+	0: "GROUP_PENDING",
+	1: "GROUP_SUCCESS",
+	2: "GROUP_FAILED",
+	3: "GROUP_PARTIAL",
+}
+
+var SwapGroupState_value = map[string]int32{
+	"GROUP_PENDING": 0,
+	"GROUP_SUCCESS": 1,
+	"GROUP_FAILED":  2,
+	"GROUP_PARTIAL": 3,
+}
+
+func (x SwapGroupState) String() string {
+	return proto.EnumName(SwapGroupState_name, int32(x))
+}
+
+func (SwapGroupState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{0}
+}
+
+type SwapIntentState int32
+
+const (
+	//*
+	//INTENT_PENDING indicates that the intent is waiting for its trigger
+	//conditions to be met.
+	SwapIntentState_INTENT_PENDING SwapIntentState = 0
+	//*
+	//INTENT_LAUNCHED indicates that the trigger conditions were met and the
+	//swap was initiated.
+	SwapIntentState_INTENT_LAUNCHED SwapIntentState = 1
+	//*
+	//INTENT_CANCELED indicates that the intent was canceled before it
+	//triggered.
+	SwapIntentState_INTENT_CANCELED SwapIntentState = 2
+	//*
+	//INTENT_FAILED indicates that the trigger conditions were met, but the swap
+	//could not be initiated.
+	SwapIntentState_INTENT_FAILED SwapIntentState = 3
+)
+
+var SwapIntentState_name = map[int32]string{
+	0: "INTENT_PENDING",
+	1: "INTENT_LAUNCHED",
+	2: "INTENT_CANCELED",
+	3: "INTENT_FAILED",
+}
+
+var SwapIntentState_value = map[string]int32{
+	"INTENT_PENDING":  0,
+	"INTENT_LAUNCHED": 1,
+	"INTENT_CANCELED": 2,
+	"INTENT_FAILED":   3,
+}
+
+func (x SwapIntentState) String() string {
+	return proto.EnumName(SwapIntentState_name, int32(x))
+}
+
+func (SwapIntentState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{1}
+}
+
+type RecurringSwapState int32
+
+const (
+	//*
+	//RECURRING_ACTIVE indicates that the recurring swap initiates a swap on
+	//every interval.
+	RecurringSwapState_RECURRING_ACTIVE RecurringSwapState = 0
+	//*
+	//RECURRING_CANCELED indicates that the recurring swap was canceled.
+	RecurringSwapState_RECURRING_CANCELED RecurringSwapState = 1
+)
+
+var RecurringSwapState_name = map[int32]string{
+	0: "RECURRING_ACTIVE",
+	1: "RECURRING_CANCELED",
+}
+
+var RecurringSwapState_value = map[string]int32{
+	"RECURRING_ACTIVE":   0,
+	"RECURRING_CANCELED": 1,
+}
+
+func (x RecurringSwapState) String() string {
+	return proto.EnumName(RecurringSwapState_name, int32(x))
+}
+
+func (RecurringSwapState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{2}
+}
+
+type ServerStatus int32
+
+const (
+	//*
+	//SERVER_STATUS_ACCEPTED indicates that the server accepted the swap and is
+	//going to follow up on it.
+	ServerStatus_SERVER_STATUS_ACCEPTED ServerStatus = 0
+	//*
+	//SERVER_STATUS_HTLC_DELAYED indicates that the server delays the
+	//publication of the loop out HTLC, for example to batch it until the swap
+	//publication deadline.
+	ServerStatus_SERVER_STATUS_HTLC_DELAYED ServerStatus = 1
+	//*
+	//SERVER_STATUS_HTLC_PUBLISHED indicates that the server published the loop
+	//out HTLC.
+	ServerStatus_SERVER_STATUS_HTLC_PUBLISHED ServerStatus = 2
+	//*
+	//SERVER_STATUS_REJECTED indicates that the server won't continue with the
+	//swap.
+	ServerStatus_SERVER_STATUS_REJECTED ServerStatus = 3
+)
+
+var ServerStatus_name = map[int32]string{
+	0: "SERVER_STATUS_ACCEPTED",
+	1: "SERVER_STATUS_HTLC_DELAYED",
+	2: "SERVER_STATUS_HTLC_PUBLISHED",
+	3: "SERVER_STATUS_REJECTED",
+}
+
+var ServerStatus_value = map[string]int32{
+	"SERVER_STATUS_ACCEPTED":       0,
+	"SERVER_STATUS_HTLC_DELAYED":   1,
+	"SERVER_STATUS_HTLC_PUBLISHED": 2,
+	"SERVER_STATUS_REJECTED":       3,
+}
+
+func (x ServerStatus) String() string {
+	return proto.EnumName(ServerStatus_name, int32(x))
+}
+
+func (ServerStatus) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{3}
+}
+
+type DepositState int32
+
+const (
+	//*
+	//DEPOSIT_CONFIRMED indicates that the deposit is confirmed and not yet
+	//spent.
+	DepositState_DEPOSIT_CONFIRMED DepositState = 0
+	//*
+	//DEPOSIT_SWEPT indicates that the server swept the deposit.
+	DepositState_DEPOSIT_SWEPT DepositState = 1
+	//*
+	//DEPOSIT_REFUNDED indicates that the deposit was refunded by a timeout tx.
+	DepositState_DEPOSIT_REFUNDED DepositState = 2
+)
+
+var DepositState_name = map[int32]string{
+	0: "DEPOSIT_CONFIRMED",
+	1: "DEPOSIT_SWEPT",
+	2: "DEPOSIT_REFUNDED",
+}
+
+var DepositState_value = map[string]int32{
+	"DEPOSIT_CONFIRMED": 0,
+	"DEPOSIT_SWEPT":     1,
+	"DEPOSIT_REFUNDED":  2,
+}
+
+func (x DepositState) String() string {
+	return proto.EnumName(DepositState_name, int32(x))
+}
+
+func (DepositState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{4}
+}
+
+type SweepStatus int32
+
+const (
+	//*
+	//SWEEP_NONE indicates that the daemon didn't attempt to sweep the HTLC
+	//since it started.
+	SweepStatus_SWEEP_NONE SweepStatus = 0
+	//*
+	//SWEEP_WAITING indicates that the sweep is postponed because the fee rate
+	//exceeds the fee rate ceiling or the maximum miner fee.
+	SweepStatus_SWEEP_WAITING SweepStatus = 1
+	//*
+	//SWEEP_PUBLISHED indicates that the sweep was published within the limits
+	//of the sweep strategy.
+	SweepStatus_SWEEP_PUBLISHED SweepStatus = 2
+	//*
+	//SWEEP_ESCALATED indicates that the swap reached its safety margin and the
+	//sweep was published regardless of the fee rate ceiling.
+	SweepStatus_SWEEP_ESCALATED SweepStatus = 3
+)
+
+var SweepStatus_name = map[int32]string{
+	0: "SWEEP_NONE",
+	1: "SWEEP_WAITING",
+	2: "SWEEP_PUBLISHED",
+	3: "SWEEP_ESCALATED",
+}
+
+var SweepStatus_value = map[string]int32{
+	"SWEEP_NONE":      0,
+	"SWEEP_WAITING":   1,
+	"SWEEP_PUBLISHED": 2,
+	"SWEEP_ESCALATED": 3,
+}
+
+func (x SweepStatus) String() string {
+	return proto.EnumName(SweepStatus_name, int32(x))
+}
+
+func (SweepStatus) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{5}
+}
+
+type PaymentType int32
+
+const (
+	// SWAP_PAYMENT is the payment of the swap invoice.
+	PaymentType_SWAP_PAYMENT PaymentType = 0
+	// PREPAYMENT is the payment of the prepay invoice.
+	PaymentType_PREPAYMENT PaymentType = 1
+)
+
+var PaymentType_name = map[int32]string{
+	0: "SWAP_PAYMENT",
+	1: "PREPAYMENT",
+}
+
+var PaymentType_value = map[string]int32{
+	"SWAP_PAYMENT": 0,
+	"PREPAYMENT":   1,
+}
+
+func (x PaymentType) String() string {
+	return proto.EnumName(PaymentType_name, int32(x))
+}
+
+func (PaymentType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{6}
+}
+
+type PaymentFailureReason int32
+
+const (
+	//*
+	//FAILURE_REASON_UNKNOWN indicates that the payment failed without a final
+	//payment state, for example because of an rpc error.
+	PaymentFailureReason_FAILURE_REASON_UNKNOWN PaymentFailureReason = 0
+	//*
+	//FAILURE_REASON_TIMEOUT indicates that the payment timeout expired before a
+	//route was found.
+	PaymentFailureReason_FAILURE_REASON_TIMEOUT PaymentFailureReason = 1
+	//*
+	//FAILURE_REASON_NO_ROUTE indicates that no route was found within the fee
+	//limit.
+	PaymentFailureReason_FAILURE_REASON_NO_ROUTE PaymentFailureReason = 2
+	//*
+	//FAILURE_REASON_ERROR indicates a non-recoverable failure along the route.
+	PaymentFailureReason_FAILURE_REASON_ERROR PaymentFailureReason = 3
+	//*
+	//FAILURE_REASON_INCORRECT_PAYMENT_DETAILS indicates that the destination
+	//rejected the payment.
+	PaymentFailureReason_FAILURE_REASON_INCORRECT_PAYMENT_DETAILS PaymentFailureReason = 4
+	//*
+	//FAILURE_REASON_INSUFFICIENT_BALANCE indicates that there was not enough
+	//outbound balance to send the payment.
+	PaymentFailureReason_FAILURE_REASON_INSUFFICIENT_BALANCE PaymentFailureReason = 5
+)
+
+var PaymentFailureReason_name = map[int32]string{
+	0: "FAILURE_REASON_UNKNOWN",
+	1: "FAILURE_REASON_TIMEOUT",
+	2: "FAILURE_REASON_NO_ROUTE",
+	3: "FAILURE_REASON_ERROR",
+	4: "FAILURE_REASON_INCORRECT_PAYMENT_DETAILS",
+	5: "FAILURE_REASON_INSUFFICIENT_BALANCE",
+}
+
+var PaymentFailureReason_value = map[string]int32{
+	"FAILURE_REASON_UNKNOWN":                   0,
+	"FAILURE_REASON_TIMEOUT":                   1,
+	"FAILURE_REASON_NO_ROUTE":                  2,
+	"FAILURE_REASON_ERROR":                     3,
+	"FAILURE_REASON_INCORRECT_PAYMENT_DETAILS": 4,
+	"FAILURE_REASON_INSUFFICIENT_BALANCE":      5,
+}
+
+func (x PaymentFailureReason) String() string {
+	return proto.EnumName(PaymentFailureReason_name, int32(x))
+}
+
+func (PaymentFailureReason) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{7}
+}
+
 type SwapType int32
 
 const (
@@ -47,7 +364,7 @@ func (x SwapType) String() string {
 }
 
 func (SwapType) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{0}
+	return fileDescriptor_014de31d7ac8c57c, []int{8}
 }
 
 type SwapState int32
@@ -106,7 +423,44 @@ func (x SwapState) String() string {
 }
 
 func (SwapState) EnumDescriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{1}
+	return fileDescriptor_014de31d7ac8c57c, []int{9}
+}
+
+type ServerConnectionState int32
+
+const (
+	//*
+	//SERVER_ONLINE indicates that the last call to the server succeeded.
+	ServerConnectionState_SERVER_ONLINE ServerConnectionState = 0
+	//*
+	//SERVER_DEGRADED indicates that recent calls to the server failed, but not
+	//often enough to consider it down.
+	ServerConnectionState_SERVER_DEGRADED ServerConnectionState = 1
+	//*
+	//SERVER_OFFLINE indicates that calls to the server failed repeatedly. Calls
+	//fail without contacting the server until retry_at, after which a single
+	//call probes whether the server is back.
+	ServerConnectionState_SERVER_OFFLINE ServerConnectionState = 2
+)
+
+var ServerConnectionState_name = map[int32]string{
+	0: "SERVER_ONLINE",
+	1: "SERVER_DEGRADED",
+	2: "SERVER_OFFLINE",
+}
+
+var ServerConnectionState_value = map[string]int32{
+	"SERVER_ONLINE":   0,
+	"SERVER_DEGRADED": 1,
+	"SERVER_OFFLINE":  2,
+}
+
+func (x ServerConnectionState) String() string {
+	return proto.EnumName(ServerConnectionState_name, int32(x))
+}
+
+func (ServerConnectionState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{10}
 }
 
 type LoopOutRequest struct {
@@ -165,10 +519,80 @@ type LoopOutRequest struct {
 	//server the opportunity to batch multiple swaps together, and wait for
 	//low-fee periods before publishing the HTLC, potentially resulting in a
 	//lower total swap fee.
-	SwapPublicationDeadline uint64   `protobuf:"varint,10,opt,name=swap_publication_deadline,json=swapPublicationDeadline,proto3" json:"swap_publication_deadline,omitempty"`
-	XXX_NoUnkeyedLiteral    struct{} `json:"-"`
-	XXX_unrecognized        []byte   `json:"-"`
-	XXX_sizecache           int32    `json:"-"`
+	SwapPublicationDeadline uint64 `protobuf:"varint,10,opt,name=swap_publication_deadline,json=swapPublicationDeadline,proto3" json:"swap_publication_deadline,omitempty"`
+	//*
+	//If split is true, an amount above the server maximum is split into
+	//multiple server-sized swaps. All limits apply to the combined swaps and
+	//are divided over the individual swaps in proportion to their quotes.
+	//The first swap sweeps to dest, every further swap to a fresh address.
+	//Sweep outputs are not supported for split swaps.
+	Split bool `protobuf:"varint,11,opt,name=split,proto3" json:"split,omitempty"`
+	//*
+	//The id of the quote that this swap is based on, as returned by
+	//LoopOutQuote. If set, the swap is rejected if the quote has expired or if
+	//the server asks for more than the quoted fees. Ignored for split swaps,
+	//which obtain a quote for every part.
+	QuoteId []byte `protobuf:"bytes,12,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+	//*
+	//Maximum total cost of the swap in sat. This is an alternative to the
+	//individual max_* limits: the daemon obtains a quote and divides the budget
+	//over the swap fee, the miner fee and the routing fees. It cannot be
+	//combined with the individual limits, max_total_cost_ppm or quote_id.
+	MaxTotalCost int64 `protobuf:"varint,13,opt,name=max_total_cost,json=maxTotalCost,proto3" json:"max_total_cost,omitempty"`
+	//*
+	//Maximum total cost of the swap in parts per million of the swap amount.
+	//This is an alternative to max_total_cost.
+	MaxTotalCostPpm uint64 `protobuf:"varint,14,opt,name=max_total_cost_ppm,json=maxTotalCostPpm,proto3" json:"max_total_cost_ppm,omitempty"`
+	//*
+	//If probe is true, a payment with a random hash for the swap invoice amount
+	//is sent to the swap server before the swap is initiated, to check that a route
+	//exists. If no route is found, the swap is aborted before any funds move.
+	Probe bool `protobuf:"varint,15,opt,name=probe,proto3" json:"probe,omitempty"`
+	//*
+	//The maximum fee rate in sat/vbyte at which the on-chain HTLC is swept.
+	//While the fee estimate for sweep_conf_target is above this rate, the
+	//preimage isn't revealed and the sweep waits for fees to come down. Zero
+	//means that the sweep is only limited by max_miner_fee.
+	SweepFeeRateCeilingSatPerVbyte uint64 `protobuf:"varint,16,opt,name=sweep_fee_rate_ceiling_sat_per_vbyte,json=sweepFeeRateCeilingSatPerVbyte,proto3" json:"sweep_fee_rate_ceiling_sat_per_vbyte,omitempty"`
+	//*
+	//The number of blocks before the HTLC expiry at which the sweep stops
+	//waiting for the fee rate ceiling and escalates its fee. Only applies if
+	//a fee rate ceiling is set. Defaults to 12 blocks.
+	SweepSafetyMargin int32 `protobuf:"varint,17,opt,name=sweep_safety_margin,json=sweepSafetyMargin,proto3" json:"sweep_safety_margin,omitempty"`
+	//*
+	//Optionally divides the swept funds over multiple outputs. Every output
+	//receives either a fixed amount or a weighted share of the value that
+	//remains after fees, so at least one output needs to be weighted. If set,
+	//dest must be empty or equal to the address of the first output. Fixed
+	//amounts cannot be combined with split.
+	SweepOutputs []*SweepOutput `protobuf:"bytes,18,rep,name=sweep_outputs,json=sweepOutputs,proto3" json:"sweep_outputs,omitempty"`
+	//*
+	//The id of the swap server to execute the swap with. If not set, the server
+	//that issued quote_id is used or, without quote, the server with the
+	//cheapest quote.
+	ServerId string `protobuf:"bytes,19,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	//*
+	//An optional key that identifies the request across retries. If a swap was
+	//already started with this key, the id and HTLC address of that swap are
+	//returned instead of starting another one. A request that reuses the key
+	//with different parameters is rejected. Not supported for split, scheduled
+	//or recurring swaps.
+	IdempotencyKey string `protobuf:"bytes,20,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	//*
+	//An optional label of the swap, of at most 500 characters. It is returned
+	//in the swap status and attached to the on-chain transactions of the swap
+	//if the lnd wallet supports transaction labels. Not supported for
+	//scheduled or recurring swaps.
+	Label string `protobuf:"bytes,21,opt,name=label,proto3" json:"label,omitempty"`
+	//*
+	//Optional key/value metadata of the swap, of at most 32 pairs. Keys must
+	//be non-empty and keys and values must not exceed 256 characters. It is
+	//returned in the swap status. Not supported for scheduled or recurring
+	//swaps.
+	Metadata             map[string]string `protobuf:"bytes,22,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *LoopOutRequest) Reset()         { *m = LoopOutRequest{} }
@@ -266,162 +690,1776 @@ func (m *LoopOutRequest) GetSwapPublicationDeadline() uint64 {
 	return 0
 }
 
-type LoopInRequest struct {
-	//*
-	//Requested swap amount in sat. This does not include the swap and miner
-	//fee.
-	Amt int64 `protobuf:"varint,1,opt,name=amt,proto3" json:"amt,omitempty"`
-	//*
-	//Maximum we are willing to pay the server for the swap. This value is not
-	//disclosed in the swap initiation call, but if the server asks for a
-	//higher fee, we abort the swap. Typically this value is taken from the
-	//response of the GetQuote call.
-	MaxSwapFee int64 `protobuf:"varint,2,opt,name=max_swap_fee,json=maxSwapFee,proto3" json:"max_swap_fee,omitempty"`
-	//*
-	//Maximum in on-chain fees that we are willing to spent. If we want to
-	//publish the on-chain htlc and the fee estimate turns out higher than this
-	//value, we cancel the swap.
-	//
-	//max_miner_fee is typically taken from the response of the GetQuote call.
-	MaxMinerFee int64 `protobuf:"varint,3,opt,name=max_miner_fee,json=maxMinerFee,proto3" json:"max_miner_fee,omitempty"`
-	//*
-	//The channel to loop in. If zero, the channel to loop in is selected based
-	//on the lowest routing fee for the swap payment from the server.
-	//
-	//Note: NOT YET IMPLEMENTED
-	LoopInChannel uint64 `protobuf:"varint,4,opt,name=loop_in_channel,json=loopInChannel,proto3" json:"loop_in_channel,omitempty"`
-	//*
-	//If external_htlc is true, we expect the htlc to be published by an external
-	//actor.
-	ExternalHtlc         bool     `protobuf:"varint,5,opt,name=external_htlc,json=externalHtlc,proto3" json:"external_htlc,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *LoopOutRequest) GetSplit() bool {
+	if m != nil {
+		return m.Split
+	}
+	return false
 }
 
-func (m *LoopInRequest) Reset()         { *m = LoopInRequest{} }
-func (m *LoopInRequest) String() string { return proto.CompactTextString(m) }
-func (*LoopInRequest) ProtoMessage()    {}
-func (*LoopInRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{1}
+func (m *LoopOutRequest) GetQuoteId() []byte {
+	if m != nil {
+		return m.QuoteId
+	}
+	return nil
 }
 
-func (m *LoopInRequest) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_LoopInRequest.Unmarshal(m, b)
-}
-func (m *LoopInRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_LoopInRequest.Marshal(b, m, deterministic)
-}
-func (m *LoopInRequest) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_LoopInRequest.Merge(m, src)
-}
-func (m *LoopInRequest) XXX_Size() int {
-	return xxx_messageInfo_LoopInRequest.Size(m)
-}
-func (m *LoopInRequest) XXX_DiscardUnknown() {
-	xxx_messageInfo_LoopInRequest.DiscardUnknown(m)
+func (m *LoopOutRequest) GetMaxTotalCost() int64 {
+	if m != nil {
+		return m.MaxTotalCost
+	}
+	return 0
 }
 
-var xxx_messageInfo_LoopInRequest proto.InternalMessageInfo
-
-func (m *LoopInRequest) GetAmt() int64 {
+func (m *LoopOutRequest) GetMaxTotalCostPpm() uint64 {
 	if m != nil {
-		return m.Amt
+		return m.MaxTotalCostPpm
 	}
 	return 0
 }
 
-func (m *LoopInRequest) GetMaxSwapFee() int64 {
+func (m *LoopOutRequest) GetProbe() bool {
 	if m != nil {
-		return m.MaxSwapFee
+		return m.Probe
 	}
-	return 0
+	return false
 }
 
-func (m *LoopInRequest) GetMaxMinerFee() int64 {
+func (m *LoopOutRequest) GetSweepFeeRateCeilingSatPerVbyte() uint64 {
 	if m != nil {
-		return m.MaxMinerFee
+		return m.SweepFeeRateCeilingSatPerVbyte
 	}
 	return 0
 }
 
-func (m *LoopInRequest) GetLoopInChannel() uint64 {
+func (m *LoopOutRequest) GetSweepSafetyMargin() int32 {
 	if m != nil {
-		return m.LoopInChannel
+		return m.SweepSafetyMargin
 	}
 	return 0
 }
 
-func (m *LoopInRequest) GetExternalHtlc() bool {
+func (m *LoopOutRequest) GetSweepOutputs() []*SweepOutput {
 	if m != nil {
-		return m.ExternalHtlc
+		return m.SweepOutputs
 	}
-	return false
+	return nil
 }
 
-type SwapResponse struct {
-	//*
-	//Swap identifier to track status in the update stream that is returned from
-	//the Start() call. Currently this is the hash that locks the htlcs.
-	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	//*
-	//The address of the on-chain htlc.
-	HtlcAddress          string   `protobuf:"bytes,2,opt,name=htlc_address,json=htlcAddress,proto3" json:"htlc_address,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+func (m *LoopOutRequest) GetServerId() string {
+	if m != nil {
+		return m.ServerId
+	}
+	return ""
 }
 
-func (m *SwapResponse) Reset()         { *m = SwapResponse{} }
-func (m *SwapResponse) String() string { return proto.CompactTextString(m) }
-func (*SwapResponse) ProtoMessage()    {}
-func (*SwapResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{2}
+func (m *LoopOutRequest) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
+func (m *LoopOutRequest) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
+
+func (m *LoopOutRequest) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+type SweepOutput struct {
+	//*
+	//The address that the output pays to.
+	Addr string `protobuf:"bytes,1,opt,name=addr,proto3" json:"addr,omitempty"`
+	//*
+	//The fixed amount in sat that the output receives. Must be zero for
+	//weighted outputs.
+	Amt int64 `protobuf:"varint,2,opt,name=amt,proto3" json:"amt,omitempty"`
+	//*
+	//The relative share of the value remaining after fees and fixed amounts
+	//that the output receives. Must be zero for fixed amount outputs.
+	Weight               uint32   `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SweepOutput) Reset()         { *m = SweepOutput{} }
+func (m *SweepOutput) String() string { return proto.CompactTextString(m) }
+func (*SweepOutput) ProtoMessage()    {}
+func (*SweepOutput) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{1}
+}
+
+func (m *SweepOutput) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SweepOutput.Unmarshal(m, b)
+}
+func (m *SweepOutput) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SweepOutput.Marshal(b, m, deterministic)
+}
+func (m *SweepOutput) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SweepOutput.Merge(m, src)
+}
+func (m *SweepOutput) XXX_Size() int {
+	return xxx_messageInfo_SweepOutput.Size(m)
+}
+func (m *SweepOutput) XXX_DiscardUnknown() {
+	xxx_messageInfo_SweepOutput.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SweepOutput proto.InternalMessageInfo
+
+func (m *SweepOutput) GetAddr() string {
+	if m != nil {
+		return m.Addr
+	}
+	return ""
+}
+
+func (m *SweepOutput) GetAmt() int64 {
+	if m != nil {
+		return m.Amt
+	}
+	return 0
+}
+
+func (m *SweepOutput) GetWeight() uint32 {
+	if m != nil {
+		return m.Weight
+	}
+	return 0
+}
+
+type LoopInRequest struct {
+	//*
+	//Requested swap amount in sat. This does not include the swap and miner
+	//fee.
+	Amt int64 `protobuf:"varint,1,opt,name=amt,proto3" json:"amt,omitempty"`
+	//*
+	//Maximum we are willing to pay the server for the swap. This value is not
+	//disclosed in the swap initiation call, but if the server asks for a
+	//higher fee, we abort the swap. Typically this value is taken from the
+	//response of the GetQuote call.
+	MaxSwapFee int64 `protobuf:"varint,2,opt,name=max_swap_fee,json=maxSwapFee,proto3" json:"max_swap_fee,omitempty"`
+	//*
+	//Maximum in on-chain fees that we are willing to spent. If we want to
+	//publish the on-chain htlc and the fee estimate turns out higher than this
+	//value, we cancel the swap.
+	//
+	//max_miner_fee is typically taken from the response of the GetQuote call.
+	MaxMinerFee int64 `protobuf:"varint,3,opt,name=max_miner_fee,json=maxMinerFee,proto3" json:"max_miner_fee,omitempty"`
+	//*
+	//The channel to loop in. If zero, the channel to loop in is selected based
+	//on the lowest routing fee for the swap payment from the server.
+	//
+	//Note: NOT YET IMPLEMENTED
+	LoopInChannel uint64 `protobuf:"varint,4,opt,name=loop_in_channel,json=loopInChannel,proto3" json:"loop_in_channel,omitempty"`
+	//*
+	//If external_htlc is true, we expect the htlc to be published by an external
+	//actor.
+	ExternalHtlc bool `protobuf:"varint,5,opt,name=external_htlc,json=externalHtlc,proto3" json:"external_htlc,omitempty"`
+	//*
+	//If split is true, an amount above the server maximum is split into
+	//multiple server-sized swaps. All limits apply to the combined swaps and
+	//are divided over the individual swaps in proportion to their quotes.
+	Split bool `protobuf:"varint,6,opt,name=split,proto3" json:"split,omitempty"`
+	//*
+	//The id of the quote that this swap is based on, as returned by
+	//GetLoopInQuote. If set, the swap is rejected if the quote has expired and
+	//the quoted swap fee is used. Ignored for split swaps, which obtain a quote
+	//for every part.
+	QuoteId []byte `protobuf:"bytes,7,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+	//*
+	//Maximum total cost of the swap in sat. This is an alternative to
+	//max_swap_fee and max_miner_fee: the daemon obtains a quote and divides the
+	//budget over the swap fee and the miner fee. It cannot be combined with the
+	//individual limits, max_total_cost_ppm or quote_id.
+	MaxTotalCost int64 `protobuf:"varint,8,opt,name=max_total_cost,json=maxTotalCost,proto3" json:"max_total_cost,omitempty"`
+	//*
+	//Maximum total cost of the swap in parts per million of the swap amount.
+	//This is an alternative to max_total_cost.
+	MaxTotalCostPpm uint64 `protobuf:"varint,9,opt,name=max_total_cost_ppm,json=maxTotalCostPpm,proto3" json:"max_total_cost_ppm,omitempty"`
+	//*
+	//The address that the HTLC is refunded to if the swap times out. If not
+	//set, the funds return to the lnd wallet. Swaps with an external HTLC
+	//should specify an address of the wallet that published the HTLC.
+	RefundAddr string `protobuf:"bytes,10,opt,name=refund_addr,json=refundAddr,proto3" json:"refund_addr,omitempty"`
+	//*
+	//The confirmation target of the refund tx. Defaults to 2 blocks. It cannot
+	//be combined with refund_sat_per_vbyte.
+	RefundConfTarget int32 `protobuf:"varint,11,opt,name=refund_conf_target,json=refundConfTarget,proto3" json:"refund_conf_target,omitempty"`
+	//*
+	//A fixed fee rate in sat/vbyte for the refund tx.
+	RefundSatPerVbyte uint64 `protobuf:"varint,12,opt,name=refund_sat_per_vbyte,json=refundSatPerVbyte,proto3" json:"refund_sat_per_vbyte,omitempty"`
+	//*
+	//If native_segwit_htlc is true, the HTLC is a native segwit (P2WSH) output
+	//instead of a nested segwit (NP2WSH) output. This reduces the on-chain
+	//cost for wallets that can pay to native segwit addresses. The server may
+	//fall back to a nested segwit HTLC, so the HTLC address of the response
+	//should be used.
+	NativeSegwitHtlc bool `protobuf:"varint,13,opt,name=native_segwit_htlc,json=nativeSegwitHtlc,proto3" json:"native_segwit_htlc,omitempty"`
+	//*
+	//The id of the swap server to execute the swap with. If not set, the server
+	//that issued quote_id is used or, without quote, the server with the
+	//cheapest quote.
+	ServerId string `protobuf:"bytes,14,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	//*
+	//An optional key that identifies the request across retries. If a swap was
+	//already started with this key, the id and HTLC address of that swap are
+	//returned instead of starting another one. A request that reuses the key
+	//with different parameters is rejected. Not supported for split, scheduled
+	//or recurring swaps.
+	IdempotencyKey string `protobuf:"bytes,15,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	//*
+	//An optional label of the swap, of at most 500 characters. It is returned
+	//in the swap status and attached to the on-chain transactions of the swap
+	//if the lnd wallet supports transaction labels. Not supported for
+	//scheduled or recurring swaps.
+	Label string `protobuf:"bytes,16,opt,name=label,proto3" json:"label,omitempty"`
+	//*
+	//Optional key/value metadata of the swap, of at most 32 pairs. Keys must
+	//be non-empty and keys and values must not exceed 256 characters. It is
+	//returned in the swap status. Not supported for scheduled or recurring
+	//swaps.
+	Metadata             map[string]string `protobuf:"bytes,17,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *LoopInRequest) Reset()         { *m = LoopInRequest{} }
+func (m *LoopInRequest) String() string { return proto.CompactTextString(m) }
+func (*LoopInRequest) ProtoMessage()    {}
+func (*LoopInRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{2}
+}
+
+func (m *LoopInRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LoopInRequest.Unmarshal(m, b)
+}
+func (m *LoopInRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LoopInRequest.Marshal(b, m, deterministic)
+}
+func (m *LoopInRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LoopInRequest.Merge(m, src)
+}
+func (m *LoopInRequest) XXX_Size() int {
+	return xxx_messageInfo_LoopInRequest.Size(m)
+}
+func (m *LoopInRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_LoopInRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LoopInRequest proto.InternalMessageInfo
+
+func (m *LoopInRequest) GetAmt() int64 {
+	if m != nil {
+		return m.Amt
+	}
+	return 0
+}
+
+func (m *LoopInRequest) GetMaxSwapFee() int64 {
+	if m != nil {
+		return m.MaxSwapFee
+	}
+	return 0
+}
+
+func (m *LoopInRequest) GetMaxMinerFee() int64 {
+	if m != nil {
+		return m.MaxMinerFee
+	}
+	return 0
+}
+
+func (m *LoopInRequest) GetLoopInChannel() uint64 {
+	if m != nil {
+		return m.LoopInChannel
+	}
+	return 0
+}
+
+func (m *LoopInRequest) GetExternalHtlc() bool {
+	if m != nil {
+		return m.ExternalHtlc
+	}
+	return false
+}
+
+func (m *LoopInRequest) GetSplit() bool {
+	if m != nil {
+		return m.Split
+	}
+	return false
+}
+
+func (m *LoopInRequest) GetQuoteId() []byte {
+	if m != nil {
+		return m.QuoteId
+	}
+	return nil
+}
+
+func (m *LoopInRequest) GetMaxTotalCost() int64 {
+	if m != nil {
+		return m.MaxTotalCost
+	}
+	return 0
+}
+
+func (m *LoopInRequest) GetMaxTotalCostPpm() uint64 {
+	if m != nil {
+		return m.MaxTotalCostPpm
+	}
+	return 0
+}
+
+func (m *LoopInRequest) GetRefundAddr() string {
+	if m != nil {
+		return m.RefundAddr
+	}
+	return ""
+}
+
+func (m *LoopInRequest) GetRefundConfTarget() int32 {
+	if m != nil {
+		return m.RefundConfTarget
+	}
+	return 0
+}
+
+func (m *LoopInRequest) GetRefundSatPerVbyte() uint64 {
+	if m != nil {
+		return m.RefundSatPerVbyte
+	}
+	return 0
+}
+
+func (m *LoopInRequest) GetNativeSegwitHtlc() bool {
+	if m != nil {
+		return m.NativeSegwitHtlc
+	}
+	return false
+}
+
+func (m *LoopInRequest) GetServerId() string {
+	if m != nil {
+		return m.ServerId
+	}
+	return ""
+}
+
+func (m *LoopInRequest) GetIdempotencyKey() string {
+	if m != nil {
+		return m.IdempotencyKey
+	}
+	return ""
+}
+
+func (m *LoopInRequest) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
+
+func (m *LoopInRequest) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+type SwapResponse struct {
+	//*
+	//Swap identifier to track status in the update stream that is returned from
+	//the Start() call. Currently this is the hash that locks the htlcs.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	//*
+	//The address of the on-chain htlc.
+	HtlcAddress string `protobuf:"bytes,2,opt,name=htlc_address,json=htlcAddress,proto3" json:"htlc_address,omitempty"`
+	//*
+	//The identifier of the swap group if the request was split. In that case,
+	//id and htlc_address refer to the first swap of the group.
+	GroupId string `protobuf:"bytes,3,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	//*
+	//All swaps that were created for a split request.
+	Parts                []*SwapGroupPart `protobuf:"bytes,4,rep,name=parts,proto3" json:"parts,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *SwapResponse) Reset()         { *m = SwapResponse{} }
+func (m *SwapResponse) String() string { return proto.CompactTextString(m) }
+func (*SwapResponse) ProtoMessage()    {}
+func (*SwapResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{3}
+}
+
+func (m *SwapResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SwapResponse.Unmarshal(m, b)
+}
+func (m *SwapResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SwapResponse.Marshal(b, m, deterministic)
+}
+func (m *SwapResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SwapResponse.Merge(m, src)
+}
+func (m *SwapResponse) XXX_Size() int {
+	return xxx_messageInfo_SwapResponse.Size(m)
+}
+func (m *SwapResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_SwapResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SwapResponse proto.InternalMessageInfo
+
+func (m *SwapResponse) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *SwapResponse) GetHtlcAddress() string {
+	if m != nil {
+		return m.HtlcAddress
+	}
+	return ""
+}
+
+func (m *SwapResponse) GetGroupId() string {
+	if m != nil {
+		return m.GroupId
+	}
+	return ""
+}
+
+func (m *SwapResponse) GetParts() []*SwapGroupPart {
+	if m != nil {
+		return m.Parts
+	}
+	return nil
+}
+
+type SwapGroupPart struct {
+	//*
+	//Swap identifier, this is the hash that locks the htlcs.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	//*
+	//The address of the on-chain htlc.
+	HtlcAddress string `protobuf:"bytes,2,opt,name=htlc_address,json=htlcAddress,proto3" json:"htlc_address,omitempty"`
+	//*
+	//The amount swapped by this part in sat.
+	Amt                  int64    `protobuf:"varint,3,opt,name=amt,proto3" json:"amt,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SwapGroupPart) Reset()         { *m = SwapGroupPart{} }
+func (m *SwapGroupPart) String() string { return proto.CompactTextString(m) }
+func (*SwapGroupPart) ProtoMessage()    {}
+func (*SwapGroupPart) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{4}
+}
+
+func (m *SwapGroupPart) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SwapGroupPart.Unmarshal(m, b)
+}
+func (m *SwapGroupPart) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SwapGroupPart.Marshal(b, m, deterministic)
+}
+func (m *SwapGroupPart) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SwapGroupPart.Merge(m, src)
+}
+func (m *SwapGroupPart) XXX_Size() int {
+	return xxx_messageInfo_SwapGroupPart.Size(m)
+}
+func (m *SwapGroupPart) XXX_DiscardUnknown() {
+	xxx_messageInfo_SwapGroupPart.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SwapGroupPart proto.InternalMessageInfo
+
+func (m *SwapGroupPart) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *SwapGroupPart) GetHtlcAddress() string {
+	if m != nil {
+		return m.HtlcAddress
+	}
+	return ""
+}
+
+func (m *SwapGroupPart) GetAmt() int64 {
+	if m != nil {
+		return m.Amt
+	}
+	return 0
+}
+
+type ListSwapGroupsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListSwapGroupsRequest) Reset()         { *m = ListSwapGroupsRequest{} }
+func (m *ListSwapGroupsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListSwapGroupsRequest) ProtoMessage()    {}
+func (*ListSwapGroupsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{5}
+}
+
+func (m *ListSwapGroupsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSwapGroupsRequest.Unmarshal(m, b)
+}
+func (m *ListSwapGroupsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSwapGroupsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListSwapGroupsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSwapGroupsRequest.Merge(m, src)
+}
+func (m *ListSwapGroupsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListSwapGroupsRequest.Size(m)
+}
+func (m *ListSwapGroupsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSwapGroupsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSwapGroupsRequest proto.InternalMessageInfo
+
+type ListSwapGroupsResponse struct {
+	//*
+	//All swap groups known to the daemon.
+	Groups               []*SwapGroup `protobuf:"bytes,1,rep,name=groups,proto3" json:"groups,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *ListSwapGroupsResponse) Reset()         { *m = ListSwapGroupsResponse{} }
+func (m *ListSwapGroupsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListSwapGroupsResponse) ProtoMessage()    {}
+func (*ListSwapGroupsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{6}
+}
+
+func (m *ListSwapGroupsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSwapGroupsResponse.Unmarshal(m, b)
+}
+func (m *ListSwapGroupsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSwapGroupsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListSwapGroupsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSwapGroupsResponse.Merge(m, src)
+}
+func (m *ListSwapGroupsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListSwapGroupsResponse.Size(m)
+}
+func (m *ListSwapGroupsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSwapGroupsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSwapGroupsResponse proto.InternalMessageInfo
+
+func (m *ListSwapGroupsResponse) GetGroups() []*SwapGroup {
+	if m != nil {
+		return m.Groups
+	}
+	return nil
+}
+
+type SwapGroup struct {
+	//*
+	//The group identifier.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	//*
+	//The type of all swaps in the group.
+	Type SwapType `protobuf:"varint,2,opt,name=type,proto3,enum=looprpc.SwapType" json:"type,omitempty"`
+	//*
+	//The combined state of all swaps in the group.
+	State SwapGroupState `protobuf:"varint,3,opt,name=state,proto3,enum=looprpc.SwapGroupState" json:"state,omitempty"`
+	//*
+	//The total amount requested in sat.
+	Amt int64 `protobuf:"varint,4,opt,name=amt,proto3" json:"amt,omitempty"`
+	//*
+	//The sum of the amounts of all successful swaps in sat.
+	AmtSwapped int64 `protobuf:"varint,5,opt,name=amt_swapped,json=amtSwapped,proto3" json:"amt_swapped,omitempty"`
+	//*
+	//Initiation time of the group.
+	InitiationTime int64 `protobuf:"varint,6,opt,name=initiation_time,json=initiationTime,proto3" json:"initiation_time,omitempty"`
+	//*
+	//Combined swap server cost.
+	CostServer int64 `protobuf:"varint,7,opt,name=cost_server,json=costServer,proto3" json:"cost_server,omitempty"`
+	//*
+	//Combined on-chain transaction cost.
+	CostOnchain int64 `protobuf:"varint,8,opt,name=cost_onchain,json=costOnchain,proto3" json:"cost_onchain,omitempty"`
+	//*
+	//Combined off-chain routing fees.
+	CostOffchain int64 `protobuf:"varint,9,opt,name=cost_offchain,json=costOffchain,proto3" json:"cost_offchain,omitempty"`
+	//*
+	//The status of every swap in the group.
+	Swaps                []*SwapStatus `protobuf:"bytes,10,rep,name=swaps,proto3" json:"swaps,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *SwapGroup) Reset()         { *m = SwapGroup{} }
+func (m *SwapGroup) String() string { return proto.CompactTextString(m) }
+func (*SwapGroup) ProtoMessage()    {}
+func (*SwapGroup) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{7}
+}
+
+func (m *SwapGroup) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SwapGroup.Unmarshal(m, b)
+}
+func (m *SwapGroup) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SwapGroup.Marshal(b, m, deterministic)
+}
+func (m *SwapGroup) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SwapGroup.Merge(m, src)
+}
+func (m *SwapGroup) XXX_Size() int {
+	return xxx_messageInfo_SwapGroup.Size(m)
+}
+func (m *SwapGroup) XXX_DiscardUnknown() {
+	xxx_messageInfo_SwapGroup.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SwapGroup proto.InternalMessageInfo
+
+func (m *SwapGroup) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *SwapGroup) GetType() SwapType {
+	if m != nil {
+		return m.Type
+	}
+	return SwapType_LOOP_OUT
+}
+
+func (m *SwapGroup) GetState() SwapGroupState {
+	if m != nil {
+		return m.State
+	}
+	return SwapGroupState_GROUP_PENDING
+}
+
+func (m *SwapGroup) GetAmt() int64 {
+	if m != nil {
+		return m.Amt
+	}
+	return 0
+}
+
+func (m *SwapGroup) GetAmtSwapped() int64 {
+	if m != nil {
+		return m.AmtSwapped
+	}
+	return 0
+}
+
+func (m *SwapGroup) GetInitiationTime() int64 {
+	if m != nil {
+		return m.InitiationTime
+	}
+	return 0
+}
+
+func (m *SwapGroup) GetCostServer() int64 {
+	if m != nil {
+		return m.CostServer
+	}
+	return 0
+}
+
+func (m *SwapGroup) GetCostOnchain() int64 {
+	if m != nil {
+		return m.CostOnchain
+	}
+	return 0
+}
+
+func (m *SwapGroup) GetCostOffchain() int64 {
+	if m != nil {
+		return m.CostOffchain
+	}
+	return 0
+}
+
+func (m *SwapGroup) GetSwaps() []*SwapStatus {
+	if m != nil {
+		return m.Swaps
+	}
+	return nil
+}
+
+type SwapTrigger struct {
+	//*
+	//The earliest time (in unix seconds) at which the swap may be initiated.
+	//Zero disables this condition.
+	NotBefore int64 `protobuf:"varint,1,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	//*
+	//The earliest block height at which the swap may be initiated. Zero
+	//disables this condition.
+	NotBeforeHeight int32 `protobuf:"varint,2,opt,name=not_before_height,json=notBeforeHeight,proto3" json:"not_before_height,omitempty"`
+	//*
+	//The fee rate in sat/vbyte that the on-chain fee estimate for
+	//fee_conf_target needs to drop to for the swap to be initiated. Zero
+	//disables this condition.
+	MaxFeeRateSatPerVbyte uint64 `protobuf:"varint,3,opt,name=max_fee_rate_sat_per_vbyte,json=maxFeeRateSatPerVbyte,proto3" json:"max_fee_rate_sat_per_vbyte,omitempty"`
+	//*
+	//The confirmation target of the fee estimate that is compared against
+	//max_fee_rate_sat_per_vbyte.
+	FeeConfTarget int32 `protobuf:"varint,4,opt,name=fee_conf_target,json=feeConfTarget,proto3" json:"fee_conf_target,omitempty"`
+	//*
+	//The block height at which the swap is initiated regardless of the fee
+	//estimate. Zero means that the swap waits for the fee estimate
+	//indefinitely.
+	DeadlineHeight       int32    `protobuf:"varint,5,opt,name=deadline_height,json=deadlineHeight,proto3" json:"deadline_height,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SwapTrigger) Reset()         { *m = SwapTrigger{} }
+func (m *SwapTrigger) String() string { return proto.CompactTextString(m) }
+func (*SwapTrigger) ProtoMessage()    {}
+func (*SwapTrigger) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{8}
+}
+
+func (m *SwapTrigger) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SwapTrigger.Unmarshal(m, b)
+}
+func (m *SwapTrigger) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SwapTrigger.Marshal(b, m, deterministic)
+}
+func (m *SwapTrigger) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SwapTrigger.Merge(m, src)
+}
+func (m *SwapTrigger) XXX_Size() int {
+	return xxx_messageInfo_SwapTrigger.Size(m)
+}
+func (m *SwapTrigger) XXX_DiscardUnknown() {
+	xxx_messageInfo_SwapTrigger.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SwapTrigger proto.InternalMessageInfo
+
+func (m *SwapTrigger) GetNotBefore() int64 {
+	if m != nil {
+		return m.NotBefore
+	}
+	return 0
+}
+
+func (m *SwapTrigger) GetNotBeforeHeight() int32 {
+	if m != nil {
+		return m.NotBeforeHeight
+	}
+	return 0
+}
+
+func (m *SwapTrigger) GetMaxFeeRateSatPerVbyte() uint64 {
+	if m != nil {
+		return m.MaxFeeRateSatPerVbyte
+	}
+	return 0
+}
+
+func (m *SwapTrigger) GetFeeConfTarget() int32 {
+	if m != nil {
+		return m.FeeConfTarget
+	}
+	return 0
+}
+
+func (m *SwapTrigger) GetDeadlineHeight() int32 {
+	if m != nil {
+		return m.DeadlineHeight
+	}
+	return 0
+}
+
+type ScheduleSwapRequest struct {
+	//*
+	//The conditions that all need to be met for the swap to be initiated.
+	Trigger *SwapTrigger `protobuf:"bytes,1,opt,name=trigger,proto3" json:"trigger,omitempty"`
+	//*
+	//The loop out swap to initiate. Exactly one of loop_out and loop_in must be
+	//set. Split swaps, quote ids and probes are not supported for scheduled
+	//swaps. If a total cost budget is given, the limits are derived from a
+	//quote that is obtained when the swap is scheduled.
+	LoopOut *LoopOutRequest `protobuf:"bytes,2,opt,name=loop_out,json=loopOut,proto3" json:"loop_out,omitempty"`
+	//*
+	//The loop in swap to initiate.
+	LoopIn               *LoopInRequest `protobuf:"bytes,3,opt,name=loop_in,json=loopIn,proto3" json:"loop_in,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *ScheduleSwapRequest) Reset()         { *m = ScheduleSwapRequest{} }
+func (m *ScheduleSwapRequest) String() string { return proto.CompactTextString(m) }
+func (*ScheduleSwapRequest) ProtoMessage()    {}
+func (*ScheduleSwapRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{9}
+}
+
+func (m *ScheduleSwapRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ScheduleSwapRequest.Unmarshal(m, b)
+}
+func (m *ScheduleSwapRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ScheduleSwapRequest.Marshal(b, m, deterministic)
+}
+func (m *ScheduleSwapRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ScheduleSwapRequest.Merge(m, src)
+}
+func (m *ScheduleSwapRequest) XXX_Size() int {
+	return xxx_messageInfo_ScheduleSwapRequest.Size(m)
+}
+func (m *ScheduleSwapRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ScheduleSwapRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ScheduleSwapRequest proto.InternalMessageInfo
+
+func (m *ScheduleSwapRequest) GetTrigger() *SwapTrigger {
+	if m != nil {
+		return m.Trigger
+	}
+	return nil
+}
+
+func (m *ScheduleSwapRequest) GetLoopOut() *LoopOutRequest {
+	if m != nil {
+		return m.LoopOut
+	}
+	return nil
+}
+
+func (m *ScheduleSwapRequest) GetLoopIn() *LoopInRequest {
+	if m != nil {
+		return m.LoopIn
+	}
+	return nil
+}
+
+type SwapIntent struct {
+	//*
+	//The intent identifier.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	//*
+	//The type of the swap.
+	Type SwapType `protobuf:"varint,2,opt,name=type,proto3,enum=looprpc.SwapType" json:"type,omitempty"`
+	//*
+	//The state of the intent.
+	State SwapIntentState `protobuf:"varint,3,opt,name=state,proto3,enum=looprpc.SwapIntentState" json:"state,omitempty"`
+	//*
+	//The conditions for initiating the swap.
+	Trigger *SwapTrigger `protobuf:"bytes,4,opt,name=trigger,proto3" json:"trigger,omitempty"`
+	//*
+	//Requested swap amount in sat.
+	Amt int64 `protobuf:"varint,5,opt,name=amt,proto3" json:"amt,omitempty"`
+	//*
+	//The destination address of a loop out swap.
+	Dest string `protobuf:"bytes,6,opt,name=dest,proto3" json:"dest,omitempty"`
+	//*
+	//Creation time of the intent.
+	CreationTime int64 `protobuf:"varint,7,opt,name=creation_time,json=creationTime,proto3" json:"creation_time,omitempty"`
+	//*
+	//Time of the last state change of the intent.
+	LastUpdateTime int64 `protobuf:"varint,8,opt,name=last_update_time,json=lastUpdateTime,proto3" json:"last_update_time,omitempty"`
+	//*
+	//The identifier of the swap that was initiated by this intent.
+	SwapId string `protobuf:"bytes,9,opt,name=swap_id,json=swapId,proto3" json:"swap_id,omitempty"`
+	//*
+	//The reason why the swap could not be initiated.
+	Error                string   `protobuf:"bytes,10,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *SwapIntent) Reset()         { *m = SwapIntent{} }
+func (m *SwapIntent) String() string { return proto.CompactTextString(m) }
+func (*SwapIntent) ProtoMessage()    {}
+func (*SwapIntent) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{10}
+}
+
+func (m *SwapIntent) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SwapIntent.Unmarshal(m, b)
+}
+func (m *SwapIntent) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SwapIntent.Marshal(b, m, deterministic)
+}
+func (m *SwapIntent) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SwapIntent.Merge(m, src)
+}
+func (m *SwapIntent) XXX_Size() int {
+	return xxx_messageInfo_SwapIntent.Size(m)
+}
+func (m *SwapIntent) XXX_DiscardUnknown() {
+	xxx_messageInfo_SwapIntent.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SwapIntent proto.InternalMessageInfo
+
+func (m *SwapIntent) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *SwapIntent) GetType() SwapType {
+	if m != nil {
+		return m.Type
+	}
+	return SwapType_LOOP_OUT
+}
+
+func (m *SwapIntent) GetState() SwapIntentState {
+	if m != nil {
+		return m.State
+	}
+	return SwapIntentState_INTENT_PENDING
+}
+
+func (m *SwapIntent) GetTrigger() *SwapTrigger {
+	if m != nil {
+		return m.Trigger
+	}
+	return nil
+}
+
+func (m *SwapIntent) GetAmt() int64 {
+	if m != nil {
+		return m.Amt
+	}
+	return 0
+}
+
+func (m *SwapIntent) GetDest() string {
+	if m != nil {
+		return m.Dest
+	}
+	return ""
+}
+
+func (m *SwapIntent) GetCreationTime() int64 {
+	if m != nil {
+		return m.CreationTime
+	}
+	return 0
+}
+
+func (m *SwapIntent) GetLastUpdateTime() int64 {
+	if m != nil {
+		return m.LastUpdateTime
+	}
+	return 0
+}
+
+func (m *SwapIntent) GetSwapId() string {
+	if m != nil {
+		return m.SwapId
+	}
+	return ""
+}
+
+func (m *SwapIntent) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type ListSwapIntentsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListSwapIntentsRequest) Reset()         { *m = ListSwapIntentsRequest{} }
+func (m *ListSwapIntentsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListSwapIntentsRequest) ProtoMessage()    {}
+func (*ListSwapIntentsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{11}
+}
+
+func (m *ListSwapIntentsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSwapIntentsRequest.Unmarshal(m, b)
+}
+func (m *ListSwapIntentsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSwapIntentsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListSwapIntentsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSwapIntentsRequest.Merge(m, src)
+}
+func (m *ListSwapIntentsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListSwapIntentsRequest.Size(m)
+}
+func (m *ListSwapIntentsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSwapIntentsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSwapIntentsRequest proto.InternalMessageInfo
+
+type ListSwapIntentsResponse struct {
+	//*
+	//All swap intents known to the daemon.
+	Intents              []*SwapIntent `protobuf:"bytes,1,rep,name=intents,proto3" json:"intents,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *ListSwapIntentsResponse) Reset()         { *m = ListSwapIntentsResponse{} }
+func (m *ListSwapIntentsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListSwapIntentsResponse) ProtoMessage()    {}
+func (*ListSwapIntentsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{12}
+}
+
+func (m *ListSwapIntentsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListSwapIntentsResponse.Unmarshal(m, b)
+}
+func (m *ListSwapIntentsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListSwapIntentsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListSwapIntentsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListSwapIntentsResponse.Merge(m, src)
+}
+func (m *ListSwapIntentsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListSwapIntentsResponse.Size(m)
+}
+func (m *ListSwapIntentsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListSwapIntentsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListSwapIntentsResponse proto.InternalMessageInfo
+
+func (m *ListSwapIntentsResponse) GetIntents() []*SwapIntent {
+	if m != nil {
+		return m.Intents
+	}
+	return nil
+}
+
+type CancelSwapIntentRequest struct {
+	//*
+	//The identifier of the intent to cancel.
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CancelSwapIntentRequest) Reset()         { *m = CancelSwapIntentRequest{} }
+func (m *CancelSwapIntentRequest) String() string { return proto.CompactTextString(m) }
+func (*CancelSwapIntentRequest) ProtoMessage()    {}
+func (*CancelSwapIntentRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{13}
+}
+
+func (m *CancelSwapIntentRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CancelSwapIntentRequest.Unmarshal(m, b)
+}
+func (m *CancelSwapIntentRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CancelSwapIntentRequest.Marshal(b, m, deterministic)
+}
+func (m *CancelSwapIntentRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CancelSwapIntentRequest.Merge(m, src)
+}
+func (m *CancelSwapIntentRequest) XXX_Size() int {
+	return xxx_messageInfo_CancelSwapIntentRequest.Size(m)
+}
+func (m *CancelSwapIntentRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CancelSwapIntentRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CancelSwapIntentRequest proto.InternalMessageInfo
+
+func (m *CancelSwapIntentRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type CreateRecurringSwapRequest struct {
+	//*
+	//The time (in unix seconds) of the first run. If zero, the first run
+	//happens immediately.
+	StartTime int64 `protobuf:"varint,1,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	//*
+	//The number of seconds between two runs. The minimum interval is one hour.
+	IntervalSec uint64 `protobuf:"varint,2,opt,name=interval_sec,json=intervalSec,proto3" json:"interval_sec,omitempty"`
+	//*
+	//The length in seconds of the sliding window over which the total swapped
+	//amount is limited to max_amt_per_period. If zero, the interval is used.
+	PeriodSec uint64 `protobuf:"varint,3,opt,name=period_sec,json=periodSec,proto3" json:"period_sec,omitempty"`
+	//*
+	//The maximum total amount in sat that is swapped within the period. Runs
+	//that would exceed it fail. Zero disables the limit.
+	MaxAmtPerPeriod int64 `protobuf:"varint,4,opt,name=max_amt_per_period,json=maxAmtPerPeriod,proto3" json:"max_amt_per_period,omitempty"`
+	//*
+	//The loop out swap to initiate on every run. Exactly one of loop_out and
+	//loop_in must be set. Split swaps, quote ids and probes are not supported
+	//for recurring swaps. If a total cost budget is given, the limits are
+	//derived from a quote that is obtained when the recurring swap is created.
+	LoopOut *LoopOutRequest `protobuf:"bytes,5,opt,name=loop_out,json=loopOut,proto3" json:"loop_out,omitempty"`
+	//*
+	//The loop in swap to initiate on every run.
+	LoopIn               *LoopInRequest `protobuf:"bytes,6,opt,name=loop_in,json=loopIn,proto3" json:"loop_in,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
+	XXX_unrecognized     []byte         `json:"-"`
+	XXX_sizecache        int32          `json:"-"`
+}
+
+func (m *CreateRecurringSwapRequest) Reset()         { *m = CreateRecurringSwapRequest{} }
+func (m *CreateRecurringSwapRequest) String() string { return proto.CompactTextString(m) }
+func (*CreateRecurringSwapRequest) ProtoMessage()    {}
+func (*CreateRecurringSwapRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{14}
+}
+
+func (m *CreateRecurringSwapRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CreateRecurringSwapRequest.Unmarshal(m, b)
+}
+func (m *CreateRecurringSwapRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CreateRecurringSwapRequest.Marshal(b, m, deterministic)
+}
+func (m *CreateRecurringSwapRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CreateRecurringSwapRequest.Merge(m, src)
+}
+func (m *CreateRecurringSwapRequest) XXX_Size() int {
+	return xxx_messageInfo_CreateRecurringSwapRequest.Size(m)
+}
+func (m *CreateRecurringSwapRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CreateRecurringSwapRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CreateRecurringSwapRequest proto.InternalMessageInfo
+
+func (m *CreateRecurringSwapRequest) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *CreateRecurringSwapRequest) GetIntervalSec() uint64 {
+	if m != nil {
+		return m.IntervalSec
+	}
+	return 0
+}
+
+func (m *CreateRecurringSwapRequest) GetPeriodSec() uint64 {
+	if m != nil {
+		return m.PeriodSec
+	}
+	return 0
+}
+
+func (m *CreateRecurringSwapRequest) GetMaxAmtPerPeriod() int64 {
+	if m != nil {
+		return m.MaxAmtPerPeriod
+	}
+	return 0
+}
+
+func (m *CreateRecurringSwapRequest) GetLoopOut() *LoopOutRequest {
+	if m != nil {
+		return m.LoopOut
+	}
+	return nil
+}
+
+func (m *CreateRecurringSwapRequest) GetLoopIn() *LoopInRequest {
+	if m != nil {
+		return m.LoopIn
+	}
+	return nil
+}
+
+type RecurringSwapRun struct {
+	//*
+	//The start (in unix seconds) of the interval that this run belongs to.
+	ScheduledTime int64 `protobuf:"varint,1,opt,name=scheduled_time,json=scheduledTime,proto3" json:"scheduled_time,omitempty"`
+	//*
+	//The time (in unix seconds) at which the run happened.
+	Time int64 `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`
+	//*
+	//The amount of the swap in sat.
+	Amt int64 `protobuf:"varint,3,opt,name=amt,proto3" json:"amt,omitempty"`
+	//*
+	//The identifier of the swap that was initiated. Empty if the run failed.
+	SwapId string `protobuf:"bytes,4,opt,name=swap_id,json=swapId,proto3" json:"swap_id,omitempty"`
+	//*
+	//The reason why the swap could not be initiated. Empty if the run
+	//succeeded.
+	Error                string   `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RecurringSwapRun) Reset()         { *m = RecurringSwapRun{} }
+func (m *RecurringSwapRun) String() string { return proto.CompactTextString(m) }
+func (*RecurringSwapRun) ProtoMessage()    {}
+func (*RecurringSwapRun) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{15}
+}
+
+func (m *RecurringSwapRun) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RecurringSwapRun.Unmarshal(m, b)
+}
+func (m *RecurringSwapRun) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RecurringSwapRun.Marshal(b, m, deterministic)
+}
+func (m *RecurringSwapRun) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecurringSwapRun.Merge(m, src)
+}
+func (m *RecurringSwapRun) XXX_Size() int {
+	return xxx_messageInfo_RecurringSwapRun.Size(m)
+}
+func (m *RecurringSwapRun) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecurringSwapRun.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecurringSwapRun proto.InternalMessageInfo
+
+func (m *RecurringSwapRun) GetScheduledTime() int64 {
+	if m != nil {
+		return m.ScheduledTime
+	}
+	return 0
+}
+
+func (m *RecurringSwapRun) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+func (m *RecurringSwapRun) GetAmt() int64 {
+	if m != nil {
+		return m.Amt
+	}
+	return 0
+}
+
+func (m *RecurringSwapRun) GetSwapId() string {
+	if m != nil {
+		return m.SwapId
+	}
+	return ""
+}
+
+func (m *RecurringSwapRun) GetError() string {
+	if m != nil {
+		return m.Error
+	}
+	return ""
+}
+
+type RecurringSwap struct {
+	//*
+	//The recurring swap identifier.
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	//*
+	//The type of the swaps.
+	Type SwapType `protobuf:"varint,2,opt,name=type,proto3,enum=looprpc.SwapType" json:"type,omitempty"`
+	//*
+	//The state of the recurring swap.
+	State RecurringSwapState `protobuf:"varint,3,opt,name=state,proto3,enum=looprpc.RecurringSwapState" json:"state,omitempty"`
+	//*
+	//Amount of every swap in sat.
+	Amt int64 `protobuf:"varint,4,opt,name=amt,proto3" json:"amt,omitempty"`
+	//*
+	//The destination address of loop out swaps.
+	Dest string `protobuf:"bytes,5,opt,name=dest,proto3" json:"dest,omitempty"`
+	//*
+	//Creation time of the recurring swap.
+	CreationTime int64 `protobuf:"varint,6,opt,name=creation_time,json=creationTime,proto3" json:"creation_time,omitempty"`
+	//*
+	//The time (in unix seconds) of the first run.
+	StartTime int64 `protobuf:"varint,7,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	//*
+	//The number of seconds between two runs.
+	IntervalSec uint64 `protobuf:"varint,8,opt,name=interval_sec,json=intervalSec,proto3" json:"interval_sec,omitempty"`
+	//*
+	//The length in seconds of the window over which the swapped amount is
+	//limited.
+	PeriodSec uint64 `protobuf:"varint,9,opt,name=period_sec,json=periodSec,proto3" json:"period_sec,omitempty"`
+	//*
+	//The maximum total amount in sat that is swapped within the period.
+	MaxAmtPerPeriod int64 `protobuf:"varint,10,opt,name=max_amt_per_period,json=maxAmtPerPeriod,proto3" json:"max_amt_per_period,omitempty"`
+	//*
+	//All runs of the recurring swap, in the order in which they happened.
+	Runs []*RecurringSwapRun `protobuf:"bytes,11,rep,name=runs,proto3" json:"runs,omitempty"`
+	//*
+	//The number of runs that failed.
+	FailedRuns           uint32   `protobuf:"varint,12,opt,name=failed_runs,json=failedRuns,proto3" json:"failed_runs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RecurringSwap) Reset()         { *m = RecurringSwap{} }
+func (m *RecurringSwap) String() string { return proto.CompactTextString(m) }
+func (*RecurringSwap) ProtoMessage()    {}
+func (*RecurringSwap) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{16}
+}
+
+func (m *RecurringSwap) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RecurringSwap.Unmarshal(m, b)
+}
+func (m *RecurringSwap) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RecurringSwap.Marshal(b, m, deterministic)
+}
+func (m *RecurringSwap) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RecurringSwap.Merge(m, src)
+}
+func (m *RecurringSwap) XXX_Size() int {
+	return xxx_messageInfo_RecurringSwap.Size(m)
+}
+func (m *RecurringSwap) XXX_DiscardUnknown() {
+	xxx_messageInfo_RecurringSwap.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RecurringSwap proto.InternalMessageInfo
+
+func (m *RecurringSwap) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *RecurringSwap) GetType() SwapType {
+	if m != nil {
+		return m.Type
+	}
+	return SwapType_LOOP_OUT
+}
+
+func (m *RecurringSwap) GetState() RecurringSwapState {
+	if m != nil {
+		return m.State
+	}
+	return RecurringSwapState_RECURRING_ACTIVE
+}
+
+func (m *RecurringSwap) GetAmt() int64 {
+	if m != nil {
+		return m.Amt
+	}
+	return 0
+}
+
+func (m *RecurringSwap) GetDest() string {
+	if m != nil {
+		return m.Dest
+	}
+	return ""
+}
+
+func (m *RecurringSwap) GetCreationTime() int64 {
+	if m != nil {
+		return m.CreationTime
+	}
+	return 0
+}
+
+func (m *RecurringSwap) GetStartTime() int64 {
+	if m != nil {
+		return m.StartTime
+	}
+	return 0
+}
+
+func (m *RecurringSwap) GetIntervalSec() uint64 {
+	if m != nil {
+		return m.IntervalSec
+	}
+	return 0
+}
+
+func (m *RecurringSwap) GetPeriodSec() uint64 {
+	if m != nil {
+		return m.PeriodSec
+	}
+	return 0
+}
+
+func (m *RecurringSwap) GetMaxAmtPerPeriod() int64 {
+	if m != nil {
+		return m.MaxAmtPerPeriod
+	}
+	return 0
+}
+
+func (m *RecurringSwap) GetRuns() []*RecurringSwapRun {
+	if m != nil {
+		return m.Runs
+	}
+	return nil
+}
+
+func (m *RecurringSwap) GetFailedRuns() uint32 {
+	if m != nil {
+		return m.FailedRuns
+	}
+	return 0
+}
+
+type ListRecurringSwapsRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ListRecurringSwapsRequest) Reset()         { *m = ListRecurringSwapsRequest{} }
+func (m *ListRecurringSwapsRequest) String() string { return proto.CompactTextString(m) }
+func (*ListRecurringSwapsRequest) ProtoMessage()    {}
+func (*ListRecurringSwapsRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{17}
+}
+
+func (m *ListRecurringSwapsRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRecurringSwapsRequest.Unmarshal(m, b)
+}
+func (m *ListRecurringSwapsRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRecurringSwapsRequest.Marshal(b, m, deterministic)
+}
+func (m *ListRecurringSwapsRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRecurringSwapsRequest.Merge(m, src)
+}
+func (m *ListRecurringSwapsRequest) XXX_Size() int {
+	return xxx_messageInfo_ListRecurringSwapsRequest.Size(m)
+}
+func (m *ListRecurringSwapsRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRecurringSwapsRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRecurringSwapsRequest proto.InternalMessageInfo
+
+type ListRecurringSwapsResponse struct {
+	//*
+	//All recurring swaps known to the daemon.
+	RecurringSwaps       []*RecurringSwap `protobuf:"bytes,1,rep,name=recurring_swaps,json=recurringSwaps,proto3" json:"recurring_swaps,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}         `json:"-"`
+	XXX_unrecognized     []byte           `json:"-"`
+	XXX_sizecache        int32            `json:"-"`
+}
+
+func (m *ListRecurringSwapsResponse) Reset()         { *m = ListRecurringSwapsResponse{} }
+func (m *ListRecurringSwapsResponse) String() string { return proto.CompactTextString(m) }
+func (*ListRecurringSwapsResponse) ProtoMessage()    {}
+func (*ListRecurringSwapsResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{18}
+}
+
+func (m *ListRecurringSwapsResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ListRecurringSwapsResponse.Unmarshal(m, b)
+}
+func (m *ListRecurringSwapsResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ListRecurringSwapsResponse.Marshal(b, m, deterministic)
+}
+func (m *ListRecurringSwapsResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ListRecurringSwapsResponse.Merge(m, src)
+}
+func (m *ListRecurringSwapsResponse) XXX_Size() int {
+	return xxx_messageInfo_ListRecurringSwapsResponse.Size(m)
+}
+func (m *ListRecurringSwapsResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ListRecurringSwapsResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ListRecurringSwapsResponse proto.InternalMessageInfo
+
+func (m *ListRecurringSwapsResponse) GetRecurringSwaps() []*RecurringSwap {
+	if m != nil {
+		return m.RecurringSwaps
+	}
+	return nil
+}
+
+type CancelRecurringSwapRequest struct {
+	//*
+	//The identifier of the recurring swap to cancel.
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *CancelRecurringSwapRequest) Reset()         { *m = CancelRecurringSwapRequest{} }
+func (m *CancelRecurringSwapRequest) String() string { return proto.CompactTextString(m) }
+func (*CancelRecurringSwapRequest) ProtoMessage()    {}
+func (*CancelRecurringSwapRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{19}
+}
+
+func (m *CancelRecurringSwapRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_CancelRecurringSwapRequest.Unmarshal(m, b)
+}
+func (m *CancelRecurringSwapRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_CancelRecurringSwapRequest.Marshal(b, m, deterministic)
+}
+func (m *CancelRecurringSwapRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_CancelRecurringSwapRequest.Merge(m, src)
+}
+func (m *CancelRecurringSwapRequest) XXX_Size() int {
+	return xxx_messageInfo_CancelRecurringSwapRequest.Size(m)
+}
+func (m *CancelRecurringSwapRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_CancelRecurringSwapRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_CancelRecurringSwapRequest proto.InternalMessageInfo
+
+func (m *CancelRecurringSwapRequest) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+type RegisterDestDescriptorRequest struct {
+	//*
+	//An extended public key or an output descriptor of the form wpkh(KEY),
+	//sh(wpkh(KEY)) or pkh(KEY), where KEY is an extended public key followed by
+	//an unhardened derivation path ending in /*. A bare extended public key
+	//derives native segwit addresses like wpkh(KEY/0/*).
+	OutputDescriptor     string   `protobuf:"bytes,1,opt,name=output_descriptor,json=outputDescriptor,proto3" json:"output_descriptor,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RegisterDestDescriptorRequest) Reset()         { *m = RegisterDestDescriptorRequest{} }
+func (m *RegisterDestDescriptorRequest) String() string { return proto.CompactTextString(m) }
+func (*RegisterDestDescriptorRequest) ProtoMessage()    {}
+func (*RegisterDestDescriptorRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{20}
+}
+
+func (m *RegisterDestDescriptorRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RegisterDestDescriptorRequest.Unmarshal(m, b)
+}
+func (m *RegisterDestDescriptorRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RegisterDestDescriptorRequest.Marshal(b, m, deterministic)
+}
+func (m *RegisterDestDescriptorRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RegisterDestDescriptorRequest.Merge(m, src)
+}
+func (m *RegisterDestDescriptorRequest) XXX_Size() int {
+	return xxx_messageInfo_RegisterDestDescriptorRequest.Size(m)
+}
+func (m *RegisterDestDescriptorRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RegisterDestDescriptorRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RegisterDestDescriptorRequest proto.InternalMessageInfo
+
+func (m *RegisterDestDescriptorRequest) GetOutputDescriptor() string {
+	if m != nil {
+		return m.OutputDescriptor
+	}
+	return ""
+}
+
+type GetDestDescriptorRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *GetDestDescriptorRequest) Reset()         { *m = GetDestDescriptorRequest{} }
+func (m *GetDestDescriptorRequest) String() string { return proto.CompactTextString(m) }
+func (*GetDestDescriptorRequest) ProtoMessage()    {}
+func (*GetDestDescriptorRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{21}
+}
+
+func (m *GetDestDescriptorRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetDestDescriptorRequest.Unmarshal(m, b)
+}
+func (m *GetDestDescriptorRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetDestDescriptorRequest.Marshal(b, m, deterministic)
+}
+func (m *GetDestDescriptorRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetDestDescriptorRequest.Merge(m, src)
+}
+func (m *GetDestDescriptorRequest) XXX_Size() int {
+	return xxx_messageInfo_GetDestDescriptorRequest.Size(m)
+}
+func (m *GetDestDescriptorRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetDestDescriptorRequest.DiscardUnknown(m)
 }
 
-func (m *SwapResponse) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_SwapResponse.Unmarshal(m, b)
+var xxx_messageInfo_GetDestDescriptorRequest proto.InternalMessageInfo
+
+type UnregisterDestDescriptorRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
-func (m *SwapResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_SwapResponse.Marshal(b, m, deterministic)
+
+func (m *UnregisterDestDescriptorRequest) Reset()         { *m = UnregisterDestDescriptorRequest{} }
+func (m *UnregisterDestDescriptorRequest) String() string { return proto.CompactTextString(m) }
+func (*UnregisterDestDescriptorRequest) ProtoMessage()    {}
+func (*UnregisterDestDescriptorRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{22}
 }
-func (m *SwapResponse) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_SwapResponse.Merge(m, src)
+
+func (m *UnregisterDestDescriptorRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnregisterDestDescriptorRequest.Unmarshal(m, b)
 }
-func (m *SwapResponse) XXX_Size() int {
-	return xxx_messageInfo_SwapResponse.Size(m)
+func (m *UnregisterDestDescriptorRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnregisterDestDescriptorRequest.Marshal(b, m, deterministic)
 }
-func (m *SwapResponse) XXX_DiscardUnknown() {
-	xxx_messageInfo_SwapResponse.DiscardUnknown(m)
+func (m *UnregisterDestDescriptorRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnregisterDestDescriptorRequest.Merge(m, src)
+}
+func (m *UnregisterDestDescriptorRequest) XXX_Size() int {
+	return xxx_messageInfo_UnregisterDestDescriptorRequest.Size(m)
+}
+func (m *UnregisterDestDescriptorRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnregisterDestDescriptorRequest.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_SwapResponse proto.InternalMessageInfo
+var xxx_messageInfo_UnregisterDestDescriptorRequest proto.InternalMessageInfo
 
-func (m *SwapResponse) GetId() string {
+type UnregisterDestDescriptorResponse struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *UnregisterDestDescriptorResponse) Reset()         { *m = UnregisterDestDescriptorResponse{} }
+func (m *UnregisterDestDescriptorResponse) String() string { return proto.CompactTextString(m) }
+func (*UnregisterDestDescriptorResponse) ProtoMessage()    {}
+func (*UnregisterDestDescriptorResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{23}
+}
+
+func (m *UnregisterDestDescriptorResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_UnregisterDestDescriptorResponse.Unmarshal(m, b)
+}
+func (m *UnregisterDestDescriptorResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_UnregisterDestDescriptorResponse.Marshal(b, m, deterministic)
+}
+func (m *UnregisterDestDescriptorResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_UnregisterDestDescriptorResponse.Merge(m, src)
+}
+func (m *UnregisterDestDescriptorResponse) XXX_Size() int {
+	return xxx_messageInfo_UnregisterDestDescriptorResponse.Size(m)
+}
+func (m *UnregisterDestDescriptorResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_UnregisterDestDescriptorResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_UnregisterDestDescriptorResponse proto.InternalMessageInfo
+
+type DestDescriptor struct {
+	//*
+	//The registered xpub or output descriptor.
+	OutputDescriptor string `protobuf:"bytes,1,opt,name=output_descriptor,json=outputDescriptor,proto3" json:"output_descriptor,omitempty"`
+	//*
+	//The derivation index of the next destination address.
+	NextIndex            uint32   `protobuf:"varint,2,opt,name=next_index,json=nextIndex,proto3" json:"next_index,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *DestDescriptor) Reset()         { *m = DestDescriptor{} }
+func (m *DestDescriptor) String() string { return proto.CompactTextString(m) }
+func (*DestDescriptor) ProtoMessage()    {}
+func (*DestDescriptor) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{24}
+}
+
+func (m *DestDescriptor) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_DestDescriptor.Unmarshal(m, b)
+}
+func (m *DestDescriptor) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_DestDescriptor.Marshal(b, m, deterministic)
+}
+func (m *DestDescriptor) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_DestDescriptor.Merge(m, src)
+}
+func (m *DestDescriptor) XXX_Size() int {
+	return xxx_messageInfo_DestDescriptor.Size(m)
+}
+func (m *DestDescriptor) XXX_DiscardUnknown() {
+	xxx_messageInfo_DestDescriptor.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_DestDescriptor proto.InternalMessageInfo
+
+func (m *DestDescriptor) GetOutputDescriptor() string {
 	if m != nil {
-		return m.Id
+		return m.OutputDescriptor
 	}
 	return ""
 }
 
-func (m *SwapResponse) GetHtlcAddress() string {
+func (m *DestDescriptor) GetNextIndex() uint32 {
 	if m != nil {
-		return m.HtlcAddress
+		return m.NextIndex
 	}
-	return ""
+	return 0
 }
 
 type MonitorRequest struct {
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	//*
+	//If set, only swaps with this label are returned.
+	Label string `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	//*
+	//If set, only swaps that have all of these metadata pairs are returned.
+	Metadata             map[string]string `protobuf:"bytes,2,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *MonitorRequest) Reset()         { *m = MonitorRequest{} }
 func (m *MonitorRequest) String() string { return proto.CompactTextString(m) }
 func (*MonitorRequest) ProtoMessage()    {}
 func (*MonitorRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{3}
+	return fileDescriptor_014de31d7ac8c57c, []int{25}
 }
 
 func (m *MonitorRequest) XXX_Unmarshal(b []byte) error {
@@ -442,6 +2480,20 @@ func (m *MonitorRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_MonitorRequest proto.InternalMessageInfo
 
+func (m *MonitorRequest) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
+
+func (m *MonitorRequest) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
 type SwapStatus struct {
 	//*
 	//Requested swap amount in sat. This does not include the swap and miner
@@ -471,108 +2523,476 @@ type SwapStatus struct {
 	// On-chain transaction cost
 	CostOnchain int64 `protobuf:"varint,9,opt,name=cost_onchain,json=costOnchain,proto3" json:"cost_onchain,omitempty"`
 	// Off-chain routing fees
-	CostOffchain         int64    `protobuf:"varint,10,opt,name=cost_offchain,json=costOffchain,proto3" json:"cost_offchain,omitempty"`
+	CostOffchain int64 `protobuf:"varint,10,opt,name=cost_offchain,json=costOffchain,proto3" json:"cost_offchain,omitempty"`
+	//*
+	//If the swap failed because one of its off-chain payments failed, the
+	//payment that failed and the reason why.
+	PaymentFailure *PaymentFailure `protobuf:"bytes,11,opt,name=payment_failure,json=paymentFailure,proto3" json:"payment_failure,omitempty"`
+	//*
+	//For loop out swaps, the strategy that is used to sweep the HTLC and the
+	//most recent sweep decision.
+	SweepStrategy *SweepStrategy `protobuf:"bytes,12,opt,name=sweep_strategy,json=sweepStrategy,proto3" json:"sweep_strategy,omitempty"`
+	//*
+	//For loop in swaps that timed out, the id of the tx that refunded the HTLC.
+	//Its fee is included in cost_onchain.
+	RefundTxid string `protobuf:"bytes,13,opt,name=refund_txid,json=refundTxid,proto3" json:"refund_txid,omitempty"`
+	//*
+	//For loop in swaps, the outputs that pay to the HTLC. Only a single deposit
+	//of exactly the swap amount funds the swap. All other deposits are refunded
+	//once the HTLC expires.
+	Deposits []*HtlcDeposit `protobuf:"bytes,14,rep,name=deposits,proto3" json:"deposits,omitempty"`
+	//*
+	//The most recent status of the swap that the server reported, if any.
+	ServerStatus *ServerSwapStatus `protobuf:"bytes,15,opt,name=server_status,json=serverStatus,proto3" json:"server_status,omitempty"`
+	//*
+	//The id of the swap server that executes the swap. It is empty for swaps
+	//that were created before multiple servers were supported, which are
+	//executed by the default server.
+	ServerId string `protobuf:"bytes,16,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	//*
+	//The label of the swap, if any.
+	Label string `protobuf:"bytes,17,opt,name=label,proto3" json:"label,omitempty"`
+	//*
+	//The key/value metadata of the swap, if any.
+	Metadata             map[string]string `protobuf:"bytes,18,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
+}
+
+func (m *SwapStatus) Reset()         { *m = SwapStatus{} }
+func (m *SwapStatus) String() string { return proto.CompactTextString(m) }
+func (*SwapStatus) ProtoMessage()    {}
+func (*SwapStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{26}
+}
+
+func (m *SwapStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SwapStatus.Unmarshal(m, b)
+}
+func (m *SwapStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SwapStatus.Marshal(b, m, deterministic)
+}
+func (m *SwapStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SwapStatus.Merge(m, src)
+}
+func (m *SwapStatus) XXX_Size() int {
+	return xxx_messageInfo_SwapStatus.Size(m)
+}
+func (m *SwapStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_SwapStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SwapStatus proto.InternalMessageInfo
+
+func (m *SwapStatus) GetAmt() int64 {
+	if m != nil {
+		return m.Amt
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetType() SwapType {
+	if m != nil {
+		return m.Type
+	}
+	return SwapType_LOOP_OUT
+}
+
+func (m *SwapStatus) GetState() SwapState {
+	if m != nil {
+		return m.State
+	}
+	return SwapState_INITIATED
+}
+
+func (m *SwapStatus) GetInitiationTime() int64 {
+	if m != nil {
+		return m.InitiationTime
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetLastUpdateTime() int64 {
+	if m != nil {
+		return m.LastUpdateTime
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetHtlcAddress() string {
+	if m != nil {
+		return m.HtlcAddress
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetCostServer() int64 {
+	if m != nil {
+		return m.CostServer
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetCostOnchain() int64 {
+	if m != nil {
+		return m.CostOnchain
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetCostOffchain() int64 {
+	if m != nil {
+		return m.CostOffchain
+	}
+	return 0
+}
+
+func (m *SwapStatus) GetPaymentFailure() *PaymentFailure {
+	if m != nil {
+		return m.PaymentFailure
+	}
+	return nil
+}
+
+func (m *SwapStatus) GetSweepStrategy() *SweepStrategy {
+	if m != nil {
+		return m.SweepStrategy
+	}
+	return nil
+}
+
+func (m *SwapStatus) GetRefundTxid() string {
+	if m != nil {
+		return m.RefundTxid
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetDeposits() []*HtlcDeposit {
+	if m != nil {
+		return m.Deposits
+	}
+	return nil
+}
+
+func (m *SwapStatus) GetServerStatus() *ServerSwapStatus {
+	if m != nil {
+		return m.ServerStatus
+	}
+	return nil
+}
+
+func (m *SwapStatus) GetServerId() string {
+	if m != nil {
+		return m.ServerId
+	}
+	return ""
+}
+
+type ServerSwapStatus struct {
+	//*
+	//The status of the swap on the server side.
+	Status ServerStatus `protobuf:"varint,1,opt,name=status,proto3,enum=looprpc.ServerStatus" json:"status,omitempty"`
+	//*
+	//An optional human readable explanation of the status.
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	//*
+	//The unix time in seconds at which the server expects to publish the HTLC.
+	//Only set for SERVER_STATUS_HTLC_DELAYED.
+	ExpectedPublication int64 `protobuf:"varint,3,opt,name=expected_publication,json=expectedPublication,proto3" json:"expected_publication,omitempty"`
+	//*
+	//The unix time in nanoseconds at which the status was received.
+	UpdateTime           int64    `protobuf:"varint,4,opt,name=update_time,json=updateTime,proto3" json:"update_time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServerSwapStatus) Reset()         { *m = ServerSwapStatus{} }
+func (m *ServerSwapStatus) String() string { return proto.CompactTextString(m) }
+func (*ServerSwapStatus) ProtoMessage()    {}
+func (*ServerSwapStatus) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{27}
+}
+
+func (m *SwapStatus) GetLabel() string {
+	if m != nil {
+		return m.Label
+	}
+	return ""
+}
+
+func (m *SwapStatus) GetMetadata() map[string]string {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *ServerSwapStatus) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerSwapStatus.Unmarshal(m, b)
+}
+func (m *ServerSwapStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerSwapStatus.Marshal(b, m, deterministic)
+}
+func (m *ServerSwapStatus) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerSwapStatus.Merge(m, src)
+}
+func (m *ServerSwapStatus) XXX_Size() int {
+	return xxx_messageInfo_ServerSwapStatus.Size(m)
+}
+func (m *ServerSwapStatus) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerSwapStatus.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerSwapStatus proto.InternalMessageInfo
+
+func (m *ServerSwapStatus) GetStatus() ServerStatus {
+	if m != nil {
+		return m.Status
+	}
+	return ServerStatus_SERVER_STATUS_ACCEPTED
+}
+
+func (m *ServerSwapStatus) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *ServerSwapStatus) GetExpectedPublication() int64 {
+	if m != nil {
+		return m.ExpectedPublication
+	}
+	return 0
+}
+
+func (m *ServerSwapStatus) GetUpdateTime() int64 {
+	if m != nil {
+		return m.UpdateTime
+	}
+	return 0
+}
+
+type HtlcDeposit struct {
+	//*
+	//The outpoint of the deposit in the form txid:index.
+	Outpoint string `protobuf:"bytes,1,opt,name=outpoint,proto3" json:"outpoint,omitempty"`
+	//*
+	//The value of the deposit in sat.
+	Amt int64 `protobuf:"varint,2,opt,name=amt,proto3" json:"amt,omitempty"`
+	//*
+	//Whether the deposit funds the swap.
+	Accepted bool `protobuf:"varint,3,opt,name=accepted,proto3" json:"accepted,omitempty"`
+	//*
+	//Whether the deposit has been spent and by whom.
+	State DepositState `protobuf:"varint,4,opt,name=state,proto3,enum=looprpc.DepositState" json:"state,omitempty"`
+	//*
+	//The id of the tx that spent the deposit, if any.
+	SpendTxid            string   `protobuf:"bytes,5,opt,name=spend_txid,json=spendTxid,proto3" json:"spend_txid,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
-func (m *SwapStatus) Reset()         { *m = SwapStatus{} }
-func (m *SwapStatus) String() string { return proto.CompactTextString(m) }
-func (*SwapStatus) ProtoMessage()    {}
-func (*SwapStatus) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{4}
+func (m *HtlcDeposit) Reset()         { *m = HtlcDeposit{} }
+func (m *HtlcDeposit) String() string { return proto.CompactTextString(m) }
+func (*HtlcDeposit) ProtoMessage()    {}
+func (*HtlcDeposit) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{28}
 }
 
-func (m *SwapStatus) XXX_Unmarshal(b []byte) error {
-	return xxx_messageInfo_SwapStatus.Unmarshal(m, b)
+func (m *HtlcDeposit) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_HtlcDeposit.Unmarshal(m, b)
 }
-func (m *SwapStatus) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
-	return xxx_messageInfo_SwapStatus.Marshal(b, m, deterministic)
+func (m *HtlcDeposit) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_HtlcDeposit.Marshal(b, m, deterministic)
 }
-func (m *SwapStatus) XXX_Merge(src proto.Message) {
-	xxx_messageInfo_SwapStatus.Merge(m, src)
+func (m *HtlcDeposit) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_HtlcDeposit.Merge(m, src)
 }
-func (m *SwapStatus) XXX_Size() int {
-	return xxx_messageInfo_SwapStatus.Size(m)
+func (m *HtlcDeposit) XXX_Size() int {
+	return xxx_messageInfo_HtlcDeposit.Size(m)
 }
-func (m *SwapStatus) XXX_DiscardUnknown() {
-	xxx_messageInfo_SwapStatus.DiscardUnknown(m)
+func (m *HtlcDeposit) XXX_DiscardUnknown() {
+	xxx_messageInfo_HtlcDeposit.DiscardUnknown(m)
 }
 
-var xxx_messageInfo_SwapStatus proto.InternalMessageInfo
+var xxx_messageInfo_HtlcDeposit proto.InternalMessageInfo
 
-func (m *SwapStatus) GetAmt() int64 {
+func (m *HtlcDeposit) GetOutpoint() string {
+	if m != nil {
+		return m.Outpoint
+	}
+	return ""
+}
+
+func (m *HtlcDeposit) GetAmt() int64 {
 	if m != nil {
 		return m.Amt
 	}
 	return 0
 }
 
-func (m *SwapStatus) GetId() string {
+func (m *HtlcDeposit) GetAccepted() bool {
 	if m != nil {
-		return m.Id
+		return m.Accepted
 	}
-	return ""
+	return false
 }
 
-func (m *SwapStatus) GetType() SwapType {
+func (m *HtlcDeposit) GetState() DepositState {
 	if m != nil {
-		return m.Type
+		return m.State
 	}
-	return SwapType_LOOP_OUT
+	return DepositState_DEPOSIT_CONFIRMED
 }
 
-func (m *SwapStatus) GetState() SwapState {
+func (m *HtlcDeposit) GetSpendTxid() string {
 	if m != nil {
-		return m.State
+		return m.SpendTxid
 	}
-	return SwapState_INITIATED
+	return ""
 }
 
-func (m *SwapStatus) GetInitiationTime() int64 {
+type SweepStrategy struct {
+	//*
+	//The confirmation target of the sweep.
+	ConfTarget int32 `protobuf:"varint,1,opt,name=conf_target,json=confTarget,proto3" json:"conf_target,omitempty"`
+	//*
+	//The maximum fee rate in sat/vbyte of the sweep outside of the safety
+	//margin. Zero if the sweep is only limited by the maximum miner fee.
+	FeeRateCeilingSatPerVbyte uint64 `protobuf:"varint,2,opt,name=fee_rate_ceiling_sat_per_vbyte,json=feeRateCeilingSatPerVbyte,proto3" json:"fee_rate_ceiling_sat_per_vbyte,omitempty"`
+	//*
+	//The number of blocks before the HTLC expiry at which the fee rate ceiling
+	//no longer applies.
+	SafetyMargin int32 `protobuf:"varint,3,opt,name=safety_margin,json=safetyMargin,proto3" json:"safety_margin,omitempty"`
+	//*
+	//The most recent sweep decision.
+	Status               SweepStatus `protobuf:"varint,4,opt,name=status,proto3,enum=looprpc.SweepStatus" json:"status,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *SweepStrategy) Reset()         { *m = SweepStrategy{} }
+func (m *SweepStrategy) String() string { return proto.CompactTextString(m) }
+func (*SweepStrategy) ProtoMessage()    {}
+func (*SweepStrategy) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{29}
+}
+
+func (m *SweepStrategy) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_SweepStrategy.Unmarshal(m, b)
+}
+func (m *SweepStrategy) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_SweepStrategy.Marshal(b, m, deterministic)
+}
+func (m *SweepStrategy) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_SweepStrategy.Merge(m, src)
+}
+func (m *SweepStrategy) XXX_Size() int {
+	return xxx_messageInfo_SweepStrategy.Size(m)
+}
+func (m *SweepStrategy) XXX_DiscardUnknown() {
+	xxx_messageInfo_SweepStrategy.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_SweepStrategy proto.InternalMessageInfo
+
+func (m *SweepStrategy) GetConfTarget() int32 {
 	if m != nil {
-		return m.InitiationTime
+		return m.ConfTarget
 	}
 	return 0
 }
 
-func (m *SwapStatus) GetLastUpdateTime() int64 {
+func (m *SweepStrategy) GetFeeRateCeilingSatPerVbyte() uint64 {
 	if m != nil {
-		return m.LastUpdateTime
+		return m.FeeRateCeilingSatPerVbyte
 	}
 	return 0
 }
 
-func (m *SwapStatus) GetHtlcAddress() string {
+func (m *SweepStrategy) GetSafetyMargin() int32 {
 	if m != nil {
-		return m.HtlcAddress
+		return m.SafetyMargin
 	}
-	return ""
+	return 0
 }
 
-func (m *SwapStatus) GetCostServer() int64 {
+func (m *SweepStrategy) GetStatus() SweepStatus {
 	if m != nil {
-		return m.CostServer
+		return m.Status
 	}
-	return 0
+	return SweepStatus_SWEEP_NONE
 }
 
-func (m *SwapStatus) GetCostOnchain() int64 {
+type PaymentFailure struct {
+	//*
+	//The off-chain payment of the swap that failed.
+	Payment PaymentType `protobuf:"varint,1,opt,name=payment,proto3,enum=looprpc.PaymentType" json:"payment,omitempty"`
+	//*
+	//The reason why the payment failed.
+	Reason               PaymentFailureReason `protobuf:"varint,2,opt,name=reason,proto3,enum=looprpc.PaymentFailureReason" json:"reason,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *PaymentFailure) Reset()         { *m = PaymentFailure{} }
+func (m *PaymentFailure) String() string { return proto.CompactTextString(m) }
+func (*PaymentFailure) ProtoMessage()    {}
+func (*PaymentFailure) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{30}
+}
+
+func (m *PaymentFailure) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PaymentFailure.Unmarshal(m, b)
+}
+func (m *PaymentFailure) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PaymentFailure.Marshal(b, m, deterministic)
+}
+func (m *PaymentFailure) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PaymentFailure.Merge(m, src)
+}
+func (m *PaymentFailure) XXX_Size() int {
+	return xxx_messageInfo_PaymentFailure.Size(m)
+}
+func (m *PaymentFailure) XXX_DiscardUnknown() {
+	xxx_messageInfo_PaymentFailure.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PaymentFailure proto.InternalMessageInfo
+
+func (m *PaymentFailure) GetPayment() PaymentType {
 	if m != nil {
-		return m.CostOnchain
+		return m.Payment
 	}
-	return 0
+	return PaymentType_SWAP_PAYMENT
 }
 
-func (m *SwapStatus) GetCostOffchain() int64 {
+func (m *PaymentFailure) GetReason() PaymentFailureReason {
 	if m != nil {
-		return m.CostOffchain
+		return m.Reason
 	}
-	return 0
+	return PaymentFailureReason_FAILURE_REASON_UNKNOWN
 }
 
 type TermsRequest struct {
+	//*
+	//The id of the swap server to return the terms of. If not set, the terms of
+	//the default server are returned.
+	ServerId             string   `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -582,7 +3002,7 @@ func (m *TermsRequest) Reset()         { *m = TermsRequest{} }
 func (m *TermsRequest) String() string { return proto.CompactTextString(m) }
 func (*TermsRequest) ProtoMessage()    {}
 func (*TermsRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{5}
+	return fileDescriptor_014de31d7ac8c57c, []int{31}
 }
 
 func (m *TermsRequest) XXX_Unmarshal(b []byte) error {
@@ -603,6 +3023,13 @@ func (m *TermsRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_TermsRequest proto.InternalMessageInfo
 
+func (m *TermsRequest) GetServerId() string {
+	if m != nil {
+		return m.ServerId
+	}
+	return ""
+}
+
 type TermsResponse struct {
 	//*
 	//Minimum swap amount (sat)
@@ -619,7 +3046,7 @@ func (m *TermsResponse) Reset()         { *m = TermsResponse{} }
 func (m *TermsResponse) String() string { return proto.CompactTextString(m) }
 func (*TermsResponse) ProtoMessage()    {}
 func (*TermsResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{6}
+	return fileDescriptor_014de31d7ac8c57c, []int{32}
 }
 
 func (m *TermsResponse) XXX_Unmarshal(b []byte) error {
@@ -674,17 +3101,33 @@ type QuoteRequest struct {
 	//server the opportunity to batch multiple swaps together, and wait for
 	//low-fee periods before publishing the HTLC, potentially resulting in a
 	//lower total swap fee.
-	SwapPublicationDeadline uint64   `protobuf:"varint,4,opt,name=swap_publication_deadline,json=swapPublicationDeadline,proto3" json:"swap_publication_deadline,omitempty"`
-	XXX_NoUnkeyedLiteral    struct{} `json:"-"`
-	XXX_unrecognized        []byte   `json:"-"`
-	XXX_sizecache           int32    `json:"-"`
+	SwapPublicationDeadline uint64 `protobuf:"varint,4,opt,name=swap_publication_deadline,json=swapPublicationDeadline,proto3" json:"swap_publication_deadline,omitempty"`
+	//*
+	//If split is true, an amount above the server maximum is quoted as multiple
+	//server-sized swaps. The returned fees are the combined fees of all swaps.
+	Split bool `protobuf:"varint,5,opt,name=split,proto3" json:"split,omitempty"`
+	//*
+	//The channel to loop out. If set, the off-chain routing fees of a Loop Out
+	//are estimated for routes that leave through the peer of this channel.
+	LoopOutChannel uint64 `protobuf:"varint,6,opt,name=loop_out_channel,json=loopOutChannel,proto3" json:"loop_out_channel,omitempty"`
+	//*
+	//The number of outputs of the Loop Out sweep tx that the miner fee is
+	//estimated for. Zero is treated as a single output.
+	SweepOutputs uint32 `protobuf:"varint,7,opt,name=sweep_outputs,json=sweepOutputs,proto3" json:"sweep_outputs,omitempty"`
+	//*
+	//The id of the swap server to request the quote from. If not set, all
+	//servers are asked and the cheapest quote is returned.
+	ServerId             string   `protobuf:"bytes,8,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *QuoteRequest) Reset()         { *m = QuoteRequest{} }
 func (m *QuoteRequest) String() string { return proto.CompactTextString(m) }
 func (*QuoteRequest) ProtoMessage()    {}
 func (*QuoteRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{7}
+	return fileDescriptor_014de31d7ac8c57c, []int{33}
 }
 
 func (m *QuoteRequest) XXX_Unmarshal(b []byte) error {
@@ -733,6 +3176,34 @@ func (m *QuoteRequest) GetSwapPublicationDeadline() uint64 {
 	return 0
 }
 
+func (m *QuoteRequest) GetSplit() bool {
+	if m != nil {
+		return m.Split
+	}
+	return false
+}
+
+func (m *QuoteRequest) GetLoopOutChannel() uint64 {
+	if m != nil {
+		return m.LoopOutChannel
+	}
+	return 0
+}
+
+func (m *QuoteRequest) GetSweepOutputs() uint32 {
+	if m != nil {
+		return m.SweepOutputs
+	}
+	return 0
+}
+
+func (m *QuoteRequest) GetServerId() string {
+	if m != nil {
+		return m.ServerId
+	}
+	return ""
+}
+
 type QuoteResponse struct {
 	//*
 	//The fee that the swap server is charging for the swap.
@@ -749,7 +3220,29 @@ type QuoteResponse struct {
 	SwapPaymentDest []byte `protobuf:"bytes,4,opt,name=swap_payment_dest,json=swapPaymentDest,proto3" json:"swap_payment_dest,omitempty"`
 	//*
 	//On-chain cltv expiry delta
-	CltvDelta            int32    `protobuf:"varint,5,opt,name=cltv_delta,json=cltvDelta,proto3" json:"cltv_delta,omitempty"`
+	CltvDelta int32 `protobuf:"varint,5,opt,name=cltv_delta,json=cltvDelta,proto3" json:"cltv_delta,omitempty"`
+	//*
+	//The number of swaps that the quote covers.
+	NumSwaps int32 `protobuf:"varint,6,opt,name=num_swaps,json=numSwaps,proto3" json:"num_swaps,omitempty"`
+	//*
+	//The id of this quote. It can be passed to LoopOut or LoopIn to lock the
+	//quoted fees. Not set for split quotes.
+	QuoteId []byte `protobuf:"bytes,7,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+	//*
+	//The unix time in seconds until which the quote is valid.
+	QuoteExpiry int64 `protobuf:"varint,8,opt,name=quote_expiry,json=quoteExpiry,proto3" json:"quote_expiry,omitempty"`
+	//*
+	//An estimate of the routing fee of the off-chain swap payment of a Loop
+	//Out, based on the best route to the swap payment destination. Zero if no
+	//route was found.
+	SwapRoutingFee int64 `protobuf:"varint,9,opt,name=swap_routing_fee,json=swapRoutingFee,proto3" json:"swap_routing_fee,omitempty"`
+	//*
+	//An estimate of the routing fee of the off-chain prepayment of a Loop Out.
+	//Zero if no route was found.
+	PrepayRoutingFee int64 `protobuf:"varint,10,opt,name=prepay_routing_fee,json=prepayRoutingFee,proto3" json:"prepay_routing_fee,omitempty"`
+	//*
+	//The id of the swap server that issued the quote.
+	ServerId             string   `protobuf:"bytes,11,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -759,7 +3252,7 @@ func (m *QuoteResponse) Reset()         { *m = QuoteResponse{} }
 func (m *QuoteResponse) String() string { return proto.CompactTextString(m) }
 func (*QuoteResponse) ProtoMessage()    {}
 func (*QuoteResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{8}
+	return fileDescriptor_014de31d7ac8c57c, []int{34}
 }
 
 func (m *QuoteResponse) XXX_Unmarshal(b []byte) error {
@@ -815,6 +3308,48 @@ func (m *QuoteResponse) GetCltvDelta() int32 {
 	return 0
 }
 
+func (m *QuoteResponse) GetNumSwaps() int32 {
+	if m != nil {
+		return m.NumSwaps
+	}
+	return 0
+}
+
+func (m *QuoteResponse) GetQuoteId() []byte {
+	if m != nil {
+		return m.QuoteId
+	}
+	return nil
+}
+
+func (m *QuoteResponse) GetQuoteExpiry() int64 {
+	if m != nil {
+		return m.QuoteExpiry
+	}
+	return 0
+}
+
+func (m *QuoteResponse) GetSwapRoutingFee() int64 {
+	if m != nil {
+		return m.SwapRoutingFee
+	}
+	return 0
+}
+
+func (m *QuoteResponse) GetPrepayRoutingFee() int64 {
+	if m != nil {
+		return m.PrepayRoutingFee
+	}
+	return 0
+}
+
+func (m *QuoteResponse) GetServerId() string {
+	if m != nil {
+		return m.ServerId
+	}
+	return ""
+}
+
 type TokensRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -825,7 +3360,7 @@ func (m *TokensRequest) Reset()         { *m = TokensRequest{} }
 func (m *TokensRequest) String() string { return proto.CompactTextString(m) }
 func (*TokensRequest) ProtoMessage()    {}
 func (*TokensRequest) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{9}
+	return fileDescriptor_014de31d7ac8c57c, []int{35}
 }
 
 func (m *TokensRequest) XXX_Unmarshal(b []byte) error {
@@ -859,7 +3394,7 @@ func (m *TokensResponse) Reset()         { *m = TokensResponse{} }
 func (m *TokensResponse) String() string { return proto.CompactTextString(m) }
 func (*TokensResponse) ProtoMessage()    {}
 func (*TokensResponse) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{10}
+	return fileDescriptor_014de31d7ac8c57c, []int{36}
 }
 
 func (m *TokensResponse) XXX_Unmarshal(b []byte) error {
@@ -914,7 +3449,10 @@ type LsatToken struct {
 	//*
 	//Identifying attribute of this token in the store. Currently represents the
 	//file name of the token where it's stored on the file system.
-	StorageName          string   `protobuf:"bytes,8,opt,name=storage_name,json=storageName,proto3" json:"storage_name,omitempty"`
+	StorageName string `protobuf:"bytes,8,opt,name=storage_name,json=storageName,proto3" json:"storage_name,omitempty"`
+	//*
+	//The id of the swap server that issued the token.
+	ServerId             string   `protobuf:"bytes,9,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -924,7 +3462,7 @@ func (m *LsatToken) Reset()         { *m = LsatToken{} }
 func (m *LsatToken) String() string { return proto.CompactTextString(m) }
 func (*LsatToken) ProtoMessage()    {}
 func (*LsatToken) Descriptor() ([]byte, []int) {
-	return fileDescriptor_014de31d7ac8c57c, []int{11}
+	return fileDescriptor_014de31d7ac8c57c, []int{37}
 }
 
 func (m *LsatToken) XXX_Unmarshal(b []byte) error {
@@ -959,56 +3497,268 @@ func (m *LsatToken) GetPaymentHash() []byte {
 	return nil
 }
 
-func (m *LsatToken) GetPaymentPreimage() []byte {
+func (m *LsatToken) GetPaymentPreimage() []byte {
+	if m != nil {
+		return m.PaymentPreimage
+	}
+	return nil
+}
+
+func (m *LsatToken) GetAmountPaidMsat() int64 {
+	if m != nil {
+		return m.AmountPaidMsat
+	}
+	return 0
+}
+
+func (m *LsatToken) GetRoutingFeePaidMsat() int64 {
+	if m != nil {
+		return m.RoutingFeePaidMsat
+	}
+	return 0
+}
+
+func (m *LsatToken) GetTimeCreated() int64 {
+	if m != nil {
+		return m.TimeCreated
+	}
+	return 0
+}
+
+func (m *LsatToken) GetExpired() bool {
+	if m != nil {
+		return m.Expired
+	}
+	return false
+}
+
+func (m *LsatToken) GetStorageName() string {
+	if m != nil {
+		return m.StorageName
+	}
+	return ""
+}
+
+func (m *LsatToken) GetServerId() string {
+	if m != nil {
+		return m.ServerId
+	}
+	return ""
+}
+
+type ServerHealthRequest struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServerHealthRequest) Reset()         { *m = ServerHealthRequest{} }
+func (m *ServerHealthRequest) String() string { return proto.CompactTextString(m) }
+func (*ServerHealthRequest) ProtoMessage()    {}
+func (*ServerHealthRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{38}
+}
+
+func (m *ServerHealthRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerHealthRequest.Unmarshal(m, b)
+}
+func (m *ServerHealthRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerHealthRequest.Marshal(b, m, deterministic)
+}
+func (m *ServerHealthRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerHealthRequest.Merge(m, src)
+}
+func (m *ServerHealthRequest) XXX_Size() int {
+	return xxx_messageInfo_ServerHealthRequest.Size(m)
+}
+func (m *ServerHealthRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerHealthRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerHealthRequest proto.InternalMessageInfo
+
+type ServerHealthResponse struct {
+	//*
+	//The connection health of every configured swap server.
+	Servers              []*ServerHealth `protobuf:"bytes,1,rep,name=servers,proto3" json:"servers,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ServerHealthResponse) Reset()         { *m = ServerHealthResponse{} }
+func (m *ServerHealthResponse) String() string { return proto.CompactTextString(m) }
+func (*ServerHealthResponse) ProtoMessage()    {}
+func (*ServerHealthResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{39}
+}
+
+func (m *ServerHealthResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerHealthResponse.Unmarshal(m, b)
+}
+func (m *ServerHealthResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerHealthResponse.Marshal(b, m, deterministic)
+}
+func (m *ServerHealthResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerHealthResponse.Merge(m, src)
+}
+func (m *ServerHealthResponse) XXX_Size() int {
+	return xxx_messageInfo_ServerHealthResponse.Size(m)
+}
+func (m *ServerHealthResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerHealthResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerHealthResponse proto.InternalMessageInfo
+
+func (m *ServerHealthResponse) GetServers() []*ServerHealth {
+	if m != nil {
+		return m.Servers
+	}
+	return nil
+}
+
+type ServerHealth struct {
+	//*
+	//The id of the swap server.
+	ServerId string `protobuf:"bytes,1,opt,name=server_id,json=serverId,proto3" json:"server_id,omitempty"`
+	//*
+	//The state of the connection to the server.
+	State ServerConnectionState `protobuf:"varint,2,opt,name=state,proto3,enum=looprpc.ServerConnectionState" json:"state,omitempty"`
+	//*
+	//The number of calls that failed since the last successful call.
+	ConsecutiveFailures uint32 `protobuf:"varint,3,opt,name=consecutive_failures,json=consecutiveFailures,proto3" json:"consecutive_failures,omitempty"`
+	//*
+	//The error of the last failed call, if calls failed since the last
+	//successful call.
+	LastError string `protobuf:"bytes,4,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	//*
+	//The time of the last successful call as UNIX timestamp in seconds, or zero
+	//if no call succeeded yet.
+	LastSuccess int64 `protobuf:"varint,5,opt,name=last_success,json=lastSuccess,proto3" json:"last_success,omitempty"`
+	//*
+	//The time at which calls are let through again as UNIX timestamp in
+	//seconds. Only set if the server is offline.
+	RetryAt              int64    `protobuf:"varint,6,opt,name=retry_at,json=retryAt,proto3" json:"retry_at,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServerHealth) Reset()         { *m = ServerHealth{} }
+func (m *ServerHealth) String() string { return proto.CompactTextString(m) }
+func (*ServerHealth) ProtoMessage()    {}
+func (*ServerHealth) Descriptor() ([]byte, []int) {
+	return fileDescriptor_014de31d7ac8c57c, []int{40}
+}
+
+func (m *ServerHealth) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerHealth.Unmarshal(m, b)
+}
+func (m *ServerHealth) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerHealth.Marshal(b, m, deterministic)
+}
+func (m *ServerHealth) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerHealth.Merge(m, src)
+}
+func (m *ServerHealth) XXX_Size() int {
+	return xxx_messageInfo_ServerHealth.Size(m)
+}
+func (m *ServerHealth) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerHealth.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerHealth proto.InternalMessageInfo
+
+func (m *ServerHealth) GetServerId() string {
 	if m != nil {
-		return m.PaymentPreimage
+		return m.ServerId
 	}
-	return nil
+	return ""
 }
 
-func (m *LsatToken) GetAmountPaidMsat() int64 {
+func (m *ServerHealth) GetState() ServerConnectionState {
 	if m != nil {
-		return m.AmountPaidMsat
+		return m.State
 	}
-	return 0
+	return ServerConnectionState_SERVER_ONLINE
 }
 
-func (m *LsatToken) GetRoutingFeePaidMsat() int64 {
+func (m *ServerHealth) GetConsecutiveFailures() uint32 {
 	if m != nil {
-		return m.RoutingFeePaidMsat
+		return m.ConsecutiveFailures
 	}
 	return 0
 }
 
-func (m *LsatToken) GetTimeCreated() int64 {
+func (m *ServerHealth) GetLastError() string {
 	if m != nil {
-		return m.TimeCreated
+		return m.LastError
 	}
-	return 0
+	return ""
 }
 
-func (m *LsatToken) GetExpired() bool {
+func (m *ServerHealth) GetLastSuccess() int64 {
 	if m != nil {
-		return m.Expired
+		return m.LastSuccess
 	}
-	return false
+	return 0
 }
 
-func (m *LsatToken) GetStorageName() string {
+func (m *ServerHealth) GetRetryAt() int64 {
 	if m != nil {
-		return m.StorageName
+		return m.RetryAt
 	}
-	return ""
+	return 0
 }
 
 func init() {
+	proto.RegisterEnum("looprpc.SwapGroupState", SwapGroupState_name, SwapGroupState_value)
+	proto.RegisterEnum("looprpc.SwapIntentState", SwapIntentState_name, SwapIntentState_value)
+	proto.RegisterEnum("looprpc.RecurringSwapState", RecurringSwapState_name, RecurringSwapState_value)
+	proto.RegisterEnum("looprpc.ServerStatus", ServerStatus_name, ServerStatus_value)
+	proto.RegisterEnum("looprpc.DepositState", DepositState_name, DepositState_value)
+	proto.RegisterEnum("looprpc.SweepStatus", SweepStatus_name, SweepStatus_value)
+	proto.RegisterEnum("looprpc.PaymentType", PaymentType_name, PaymentType_value)
+	proto.RegisterEnum("looprpc.PaymentFailureReason", PaymentFailureReason_name, PaymentFailureReason_value)
 	proto.RegisterEnum("looprpc.SwapType", SwapType_name, SwapType_value)
 	proto.RegisterEnum("looprpc.SwapState", SwapState_name, SwapState_value)
+	proto.RegisterEnum("looprpc.ServerConnectionState", ServerConnectionState_name, ServerConnectionState_value)
 	proto.RegisterType((*LoopOutRequest)(nil), "looprpc.LoopOutRequest")
+	proto.RegisterMapType((map[string]string)(nil), "looprpc.LoopOutRequest.MetadataEntry")
+	proto.RegisterType((*SweepOutput)(nil), "looprpc.SweepOutput")
 	proto.RegisterType((*LoopInRequest)(nil), "looprpc.LoopInRequest")
+	proto.RegisterMapType((map[string]string)(nil), "looprpc.LoopInRequest.MetadataEntry")
 	proto.RegisterType((*SwapResponse)(nil), "looprpc.SwapResponse")
+	proto.RegisterType((*SwapGroupPart)(nil), "looprpc.SwapGroupPart")
+	proto.RegisterType((*ListSwapGroupsRequest)(nil), "looprpc.ListSwapGroupsRequest")
+	proto.RegisterType((*ListSwapGroupsResponse)(nil), "looprpc.ListSwapGroupsResponse")
+	proto.RegisterType((*SwapGroup)(nil), "looprpc.SwapGroup")
+	proto.RegisterType((*SwapTrigger)(nil), "looprpc.SwapTrigger")
+	proto.RegisterType((*ScheduleSwapRequest)(nil), "looprpc.ScheduleSwapRequest")
+	proto.RegisterType((*SwapIntent)(nil), "looprpc.SwapIntent")
+	proto.RegisterType((*ListSwapIntentsRequest)(nil), "looprpc.ListSwapIntentsRequest")
+	proto.RegisterType((*ListSwapIntentsResponse)(nil), "looprpc.ListSwapIntentsResponse")
+	proto.RegisterType((*CancelSwapIntentRequest)(nil), "looprpc.CancelSwapIntentRequest")
+	proto.RegisterType((*CreateRecurringSwapRequest)(nil), "looprpc.CreateRecurringSwapRequest")
+	proto.RegisterType((*RecurringSwapRun)(nil), "looprpc.RecurringSwapRun")
+	proto.RegisterType((*RecurringSwap)(nil), "looprpc.RecurringSwap")
+	proto.RegisterType((*ListRecurringSwapsRequest)(nil), "looprpc.ListRecurringSwapsRequest")
+	proto.RegisterType((*ListRecurringSwapsResponse)(nil), "looprpc.ListRecurringSwapsResponse")
+	proto.RegisterType((*CancelRecurringSwapRequest)(nil), "looprpc.CancelRecurringSwapRequest")
+	proto.RegisterType((*RegisterDestDescriptorRequest)(nil), "looprpc.RegisterDestDescriptorRequest")
+	proto.RegisterType((*GetDestDescriptorRequest)(nil), "looprpc.GetDestDescriptorRequest")
+	proto.RegisterType((*UnregisterDestDescriptorRequest)(nil), "looprpc.UnregisterDestDescriptorRequest")
+	proto.RegisterType((*UnregisterDestDescriptorResponse)(nil), "looprpc.UnregisterDestDescriptorResponse")
+	proto.RegisterType((*DestDescriptor)(nil), "looprpc.DestDescriptor")
 	proto.RegisterType((*MonitorRequest)(nil), "looprpc.MonitorRequest")
+	proto.RegisterMapType((map[string]string)(nil), "looprpc.MonitorRequest.MetadataEntry")
 	proto.RegisterType((*SwapStatus)(nil), "looprpc.SwapStatus")
+	proto.RegisterMapType((map[string]string)(nil), "looprpc.SwapStatus.MetadataEntry")
+	proto.RegisterType((*ServerSwapStatus)(nil), "looprpc.ServerSwapStatus")
+	proto.RegisterType((*HtlcDeposit)(nil), "looprpc.HtlcDeposit")
+	proto.RegisterType((*SweepStrategy)(nil), "looprpc.SweepStrategy")
+	proto.RegisterType((*PaymentFailure)(nil), "looprpc.PaymentFailure")
 	proto.RegisterType((*TermsRequest)(nil), "looprpc.TermsRequest")
 	proto.RegisterType((*TermsResponse)(nil), "looprpc.TermsResponse")
 	proto.RegisterType((*QuoteRequest)(nil), "looprpc.QuoteRequest")
@@ -1016,95 +3766,255 @@ func init() {
 	proto.RegisterType((*TokensRequest)(nil), "looprpc.TokensRequest")
 	proto.RegisterType((*TokensResponse)(nil), "looprpc.TokensResponse")
 	proto.RegisterType((*LsatToken)(nil), "looprpc.LsatToken")
+	proto.RegisterType((*ServerHealthRequest)(nil), "looprpc.ServerHealthRequest")
+	proto.RegisterType((*ServerHealthResponse)(nil), "looprpc.ServerHealthResponse")
+	proto.RegisterType((*ServerHealth)(nil), "looprpc.ServerHealth")
 }
 
 func init() { proto.RegisterFile("client.proto", fileDescriptor_014de31d7ac8c57c) }
 
 var fileDescriptor_014de31d7ac8c57c = []byte{
-	// 1322 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x56, 0xcd, 0x72, 0x1a, 0xc7,
-	0x16, 0xf6, 0xc0, 0x20, 0xe0, 0x30, 0x0c, 0xa3, 0x96, 0x2d, 0x21, 0xee, 0x75, 0x19, 0xcf, 0xbd,
-	0x76, 0x88, 0x16, 0x26, 0xb6, 0x57, 0x71, 0x65, 0x43, 0x10, 0xb6, 0x50, 0x49, 0x82, 0x0c, 0xc8,
-	0x55, 0xce, 0x66, 0xd2, 0x86, 0x96, 0x34, 0x15, 0xa6, 0x67, 0x3c, 0xdd, 0xd8, 0x52, 0xa5, 0xbc,
-	0xc9, 0x2b, 0x64, 0x97, 0x65, 0xde, 0x20, 0x55, 0xd9, 0x65, 0x95, 0x67, 0xc8, 0x3e, 0xab, 0x3c,
-	0x48, 0xaa, 0x7f, 0x66, 0x34, 0x88, 0xd8, 0x0b, 0xef, 0xe0, 0xeb, 0xaf, 0xbf, 0x3e, 0xe7, 0xf4,
-	0x77, 0x4e, 0x0f, 0x58, 0xb3, 0x45, 0x40, 0x28, 0x7f, 0x14, 0x27, 0x11, 0x8f, 0x50, 0x79, 0x11,
-	0x45, 0x71, 0x12, 0xcf, 0x5a, 0xff, 0x3d, 0x8f, 0xa2, 0xf3, 0x05, 0xe9, 0xe2, 0x38, 0xe8, 0x62,
-	0x4a, 0x23, 0x8e, 0x79, 0x10, 0x51, 0xa6, 0x68, 0xee, 0xcf, 0x45, 0xb0, 0x8f, 0xa2, 0x28, 0x1e,
-	0x2d, 0xb9, 0x47, 0xde, 0x2c, 0x09, 0xe3, 0xc8, 0x81, 0x22, 0x0e, 0x79, 0xd3, 0x68, 0x1b, 0x9d,
-	0xa2, 0x27, 0x7e, 0x22, 0x04, 0xe6, 0x9c, 0x30, 0xde, 0x2c, 0xb4, 0x8d, 0x4e, 0xd5, 0x93, 0xbf,
-	0x51, 0x17, 0x6e, 0x87, 0xf8, 0xd2, 0x67, 0xef, 0x70, 0xec, 0x27, 0xd1, 0x92, 0x07, 0xf4, 0xdc,
-	0x3f, 0x23, 0xa4, 0x59, 0x94, 0xdb, 0x36, 0x43, 0x7c, 0x39, 0x79, 0x87, 0x63, 0x4f, 0xad, 0x3c,
-	0x27, 0x04, 0x3d, 0x85, 0x6d, 0xb1, 0x21, 0x4e, 0x48, 0x8c, 0xaf, 0x56, 0xb6, 0x98, 0x72, 0xcb,
-	0x56, 0x88, 0x2f, 0xc7, 0x72, 0x31, 0xb7, 0xa9, 0x0d, 0x56, 0x76, 0x8a, 0xa0, 0x96, 0x24, 0x15,
-	0xb4, 0xba, 0x60, 0xfc, 0x1f, 0xec, 0x9c, 0xac, 0x08, 0x7c, 0x43, 0x72, 0xac, 0x4c, 0xae, 0x17,
-	0x72, 0xe4, 0x42, 0x5d, 0xb0, 0xc2, 0x80, 0x92, 0x44, 0x0a, 0x95, 0x25, 0xa9, 0x16, 0xe2, 0xcb,
-	0x63, 0x81, 0x09, 0xa5, 0x0e, 0x38, 0xa2, 0x66, 0x7e, 0xb4, 0xe4, 0xfe, 0xec, 0x02, 0x53, 0x4a,
-	0x16, 0xcd, 0x4a, 0xdb, 0xe8, 0x98, 0x9e, 0xbd, 0x50, 0x15, 0xea, 0x2b, 0x14, 0xed, 0xc1, 0x26,
-	0x7b, 0x47, 0x48, 0xec, 0xcf, 0x22, 0x7a, 0xe6, 0x73, 0x9c, 0x9c, 0x13, 0xde, 0xac, 0xb6, 0x8d,
-	0x4e, 0xc9, 0x6b, 0xc8, 0x85, 0x7e, 0x44, 0xcf, 0xa6, 0x12, 0x46, 0xcf, 0x60, 0x57, 0x46, 0x1f,
-	0x2f, 0x5f, 0x2f, 0x82, 0x99, 0xac, 0xbd, 0x3f, 0x27, 0x78, 0xbe, 0x08, 0x28, 0x69, 0x82, 0x94,
-	0xdf, 0x11, 0x84, 0xf1, 0xf5, 0xfa, 0xbe, 0x5e, 0x76, 0x7f, 0x33, 0xa0, 0x2e, 0x2e, 0x67, 0x48,
-	0x3f, 0x7c, 0x37, 0x37, 0x2b, 0x54, 0x58, 0xab, 0xd0, 0x5a, 0xee, 0xc5, 0xf5, 0xdc, 0x1f, 0x42,
-	0x43, 0xe6, 0x1e, 0xd0, 0x2c, 0x75, 0x53, 0xc6, 0x56, 0x5f, 0xc8, 0xf3, 0xd3, 0xcc, 0xff, 0x07,
-	0x75, 0x72, 0xc9, 0x49, 0x42, 0xf1, 0xc2, 0xbf, 0xe0, 0x8b, 0x99, 0xbc, 0x90, 0x8a, 0x67, 0xa5,
-	0xe0, 0x01, 0x5f, 0xcc, 0xdc, 0x1e, 0x58, 0xf2, 0xee, 0x09, 0x8b, 0x23, 0xca, 0x08, 0xb2, 0xa1,
-	0x10, 0xcc, 0x65, 0xcc, 0x55, 0xaf, 0x10, 0xcc, 0xd1, 0x7d, 0xb0, 0xc4, 0x5e, 0x1f, 0xcf, 0xe7,
-	0x09, 0x61, 0x4c, 0xdb, 0xaa, 0x26, 0xb0, 0x9e, 0x82, 0x5c, 0x07, 0xec, 0xe3, 0x88, 0x06, 0x3c,
-	0x4a, 0x74, 0xe6, 0xee, 0x5f, 0x05, 0x00, 0xa1, 0x3a, 0xe1, 0x98, 0x2f, 0xd9, 0xbf, 0x14, 0x42,
-	0x9d, 0x52, 0xc8, 0x4e, 0x79, 0x00, 0x26, 0xbf, 0x8a, 0x55, 0xb6, 0xf6, 0x93, 0xcd, 0x47, 0xba,
-	0x1f, 0x1e, 0x09, 0x91, 0xe9, 0x55, 0x4c, 0x3c, 0xb9, 0x8c, 0x3a, 0x50, 0x62, 0x1c, 0x73, 0xe5,
-	0x42, 0xfb, 0x09, 0x5a, 0xe1, 0x89, 0xc3, 0x88, 0xa7, 0x08, 0xe8, 0x33, 0x68, 0x04, 0x34, 0xe0,
-	0x81, 0xba, 0x43, 0x1e, 0x84, 0xa9, 0x1d, 0xed, 0x6b, 0x78, 0x1a, 0x84, 0xca, 0x48, 0x98, 0x71,
-	0x7f, 0x19, 0xcf, 0x31, 0x27, 0x8a, 0xa9, 0x4c, 0x69, 0x0b, 0xfc, 0x54, 0xc2, 0x92, 0x79, 0xb3,
-	0x12, 0xe5, 0xb5, 0x4a, 0xa0, 0x7b, 0x50, 0x9b, 0x45, 0x8c, 0xfb, 0x8c, 0x24, 0x6f, 0x49, 0x22,
-	0x0d, 0x59, 0xf4, 0x40, 0x40, 0x13, 0x89, 0x08, 0x0d, 0x49, 0x88, 0xe8, 0xec, 0x02, 0x07, 0x54,
-	0xfa, 0xb0, 0xe8, 0xc9, 0x4d, 0x23, 0x05, 0x89, 0x5b, 0x53, 0x94, 0xb3, 0x33, 0xc5, 0x01, 0xd5,
-	0x22, 0x92, 0xa3, 0x31, 0xd7, 0x06, 0x6b, 0x4a, 0x92, 0x90, 0xa5, 0x05, 0x7f, 0x0f, 0x75, 0xfd,
-	0x5f, 0x5f, 0xe3, 0x43, 0x68, 0x84, 0x01, 0x55, 0x4e, 0xc3, 0x61, 0xb4, 0xa4, 0x5c, 0xe7, 0x5f,
-	0x0f, 0x03, 0x2a, 0xaa, 0xd5, 0x93, 0xa0, 0xe4, 0xa5, 0x8e, 0xd4, 0xbc, 0x0d, 0xcd, 0x53, 0xa6,
-	0x54, 0xbc, 0x43, 0xb3, 0x62, 0x38, 0x85, 0x43, 0xb3, 0x52, 0x70, 0x8a, 0x87, 0x66, 0xa5, 0xe8,
-	0x98, 0x87, 0x66, 0xc5, 0x74, 0x4a, 0x87, 0x66, 0xa5, 0xec, 0x54, 0xdc, 0x5f, 0x0c, 0xb0, 0xbe,
-	0x59, 0x46, 0x9c, 0x7c, 0xd8, 0xfa, 0xb2, 0x34, 0xd7, 0x0d, 0x58, 0x90, 0x0d, 0x08, 0xb3, 0xeb,
-	0xde, 0x5b, 0x73, 0x6b, 0x71, 0xdd, 0xad, 0x1f, 0x6f, 0x50, 0xf3, 0xe3, 0x0d, 0xfa, 0xab, 0x01,
-	0x75, 0x1d, 0xa4, 0x2e, 0xd2, 0x2e, 0x54, 0xb2, 0x56, 0x54, 0xa1, 0x96, 0x99, 0xee, 0xc3, 0xbb,
-	0x00, 0xb9, 0x29, 0xa5, 0xfa, 0xb4, 0x1a, 0x67, 0x23, 0xea, 0x3f, 0x50, 0xbd, 0xd9, 0xa2, 0x95,
-	0x30, 0xed, 0x4f, 0x39, 0x71, 0x44, 0x90, 0xf8, 0x2a, 0x24, 0x94, 0xfb, 0x72, 0x1c, 0x8b, 0xe0,
-	0x2c, 0x31, 0x71, 0x70, 0x3c, 0x56, 0xf8, 0xbe, 0x28, 0xd4, 0x5d, 0x80, 0xd9, 0x82, 0xbf, 0xf5,
-	0xe7, 0x64, 0xc1, 0xb1, 0xbc, 0xa2, 0x92, 0x57, 0x15, 0xc8, 0xbe, 0x00, 0xdc, 0x06, 0xd4, 0xa7,
-	0xd1, 0xf7, 0x84, 0x66, 0x17, 0xfd, 0x15, 0xd8, 0x29, 0xa0, 0x93, 0xd8, 0x83, 0x0d, 0x2e, 0x91,
-	0xa6, 0xd1, 0x2e, 0x76, 0x6a, 0xb9, 0xa6, 0x38, 0x62, 0x98, 0x4b, 0xb2, 0xa7, 0x19, 0xee, 0xef,
-	0x05, 0xa8, 0x66, 0xa8, 0xa8, 0xf8, 0x6b, 0xcc, 0x88, 0x1f, 0xe2, 0x19, 0x4e, 0xa2, 0x88, 0xca,
-	0x1a, 0x58, 0x9e, 0x25, 0xc0, 0x63, 0x8d, 0x09, 0xc7, 0xa6, 0x79, 0x5c, 0x60, 0x76, 0x21, 0x4b,
-	0x61, 0x79, 0x35, 0x8d, 0x1d, 0x60, 0x76, 0x81, 0x3e, 0x07, 0x27, 0xa5, 0xc4, 0x09, 0x09, 0x42,
-	0x7c, 0xae, 0x6a, 0x62, 0x79, 0x0d, 0x8d, 0x8f, 0x35, 0x2c, 0xba, 0x4d, 0xb9, 0xcc, 0x8f, 0x71,
-	0x30, 0xf7, 0x43, 0x86, 0xb9, 0x7e, 0x51, 0x6c, 0x85, 0x8f, 0x71, 0x30, 0x3f, 0x66, 0x98, 0xa3,
-	0xc7, 0x70, 0x27, 0xf7, 0xec, 0xe4, 0xe8, 0xca, 0xc6, 0x28, 0xc9, 0xde, 0x9d, 0x6c, 0xcb, 0x7d,
-	0xb0, 0x44, 0xfb, 0xfa, 0xb3, 0x84, 0x60, 0x4e, 0xe6, 0xda, 0xc8, 0x35, 0x81, 0xf5, 0x15, 0x84,
-	0x9a, 0x50, 0x26, 0x97, 0x71, 0x90, 0x90, 0xb9, 0x6c, 0xdf, 0x8a, 0x97, 0xfe, 0x15, 0x9b, 0x19,
-	0x8f, 0x12, 0x7c, 0x4e, 0x7c, 0x8a, 0x43, 0x22, 0x7b, 0xb7, 0xea, 0xd5, 0x34, 0x76, 0x82, 0x43,
-	0xb2, 0xf7, 0x00, 0x2a, 0xe9, 0x3c, 0x42, 0x16, 0x54, 0x8e, 0x46, 0xa3, 0xb1, 0x3f, 0x3a, 0x9d,
-	0x3a, 0xb7, 0x50, 0x0d, 0xca, 0xf2, 0xdf, 0xf0, 0xc4, 0x31, 0xf6, 0x18, 0x54, 0xb3, 0x71, 0x84,
-	0xea, 0x50, 0x1d, 0x9e, 0x0c, 0xa7, 0xc3, 0xde, 0x74, 0xb0, 0xef, 0xdc, 0x42, 0x77, 0x60, 0x73,
-	0xec, 0x0d, 0x86, 0xc7, 0xbd, 0x17, 0x03, 0xdf, 0x1b, 0xbc, 0x1c, 0xf4, 0x8e, 0x06, 0xfb, 0x8e,
-	0x81, 0x10, 0xd8, 0x07, 0xd3, 0xa3, 0xbe, 0x3f, 0x3e, 0xfd, 0xfa, 0x68, 0x38, 0x39, 0x18, 0xec,
-	0x3b, 0x05, 0xa1, 0x39, 0x39, 0xed, 0xf7, 0x07, 0x93, 0x89, 0x53, 0x44, 0x00, 0x1b, 0xcf, 0x7b,
-	0x43, 0x41, 0x36, 0xd1, 0x16, 0x34, 0x86, 0x27, 0x2f, 0x47, 0xc3, 0xfe, 0xc0, 0x9f, 0x0c, 0xa6,
-	0x53, 0x01, 0x96, 0x9e, 0xfc, 0x51, 0x52, 0x13, 0xb7, 0x2f, 0x3f, 0x2b, 0x90, 0x07, 0x65, 0xfd,
-	0xa1, 0x80, 0x76, 0xae, 0xfd, 0xb0, 0xf2, 0xe9, 0xd0, 0xba, 0xb3, 0x32, 0x3d, 0x53, 0x3f, 0xb9,
-	0x3b, 0x3f, 0xfe, 0xf9, 0xf7, 0x4f, 0x85, 0x4d, 0xd7, 0xea, 0xbe, 0x7d, 0xdc, 0x15, 0x8c, 0x6e,
-	0xb4, 0xe4, 0xcf, 0x8c, 0x3d, 0x34, 0x82, 0x0d, 0xf5, 0xbe, 0xa1, 0xed, 0x15, 0xc9, 0xec, 0xc1,
-	0xfb, 0x90, 0xe2, 0xb6, 0x54, 0x74, 0xdc, 0x5a, 0xa6, 0x18, 0x50, 0x21, 0xf8, 0x25, 0x94, 0xf5,
-	0xbb, 0x91, 0x0b, 0x72, 0xf5, 0x25, 0x69, 0x6d, 0xad, 0x8d, 0xf8, 0x25, 0xfb, 0xc2, 0x40, 0xaf,
-	0xc0, 0xd2, 0xd9, 0xc8, 0xb1, 0x87, 0xae, 0x4f, 0xce, 0x8f, 0xc5, 0xd6, 0xf6, 0x4d, 0x58, 0x47,
-	0xd4, 0x92, 0x11, 0xdd, 0x46, 0x28, 0x9f, 0x63, 0x97, 0x4b, 0x29, 0x3f, 0x93, 0x96, 0xc3, 0x22,
-	0x27, 0x9d, 0x9f, 0x70, 0x39, 0xe9, 0x95, 0x99, 0xe2, 0xb6, 0xa5, 0x74, 0x0b, 0x35, 0x57, 0xa4,
-	0xdf, 0x08, 0x4e, 0xf7, 0x07, 0x1c, 0xf2, 0xf7, 0xe8, 0x5b, 0xb0, 0x5f, 0x10, 0xae, 0x2a, 0xf7,
-	0x49, 0xd1, 0xef, 0xca, 0x23, 0xb6, 0xd0, 0x66, 0xae, 0x9e, 0x3a, 0xf8, 0xef, 0x72, 0xda, 0x9f,
-	0x14, 0xfe, 0x3d, 0xa9, 0xbd, 0x8b, 0x76, 0xf2, 0xda, 0xf9, 0xe8, 0x5f, 0x41, 0x5d, 0x9c, 0x90,
-	0x0e, 0x11, 0x96, 0x33, 0xc3, 0xca, 0xa4, 0x6a, 0xed, 0xac, 0xe1, 0xab, 0x06, 0x43, 0x0d, 0x79,
-	0x04, 0xc3, 0xbc, 0xab, 0xa6, 0xd3, 0xeb, 0x0d, 0xf9, 0x95, 0xfb, 0xf4, 0x9f, 0x00, 0x00, 0x00,
-	0xff, 0xff, 0x55, 0xbe, 0xbc, 0x0e, 0x1c, 0x0b, 0x00, 0x00,
+	// 3834 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x5a, 0xcd, 0x73, 0xdb, 0xc8,
+	0x72, 0x37, 0x48, 0x8a, 0x1f, 0xcd, 0x2f, 0x68, 0xf4, 0x45, 0xd3, 0x6b, 0x5b, 0x86, 0xf7, 0x43,
+	0xab, 0xf5, 0x5a, 0xcf, 0x7e, 0x49, 0x55, 0x76, 0x93, 0x97, 0x3c, 0x2e, 0x05, 0xc9, 0xf4, 0x4a,
+	0x24, 0x03, 0x52, 0x76, 0xf9, 0xe5, 0x03, 0x81, 0xc9, 0x91, 0x84, 0x2c, 0x09, 0x60, 0x81, 0xa1,
+	0x2d, 0xd5, 0xab, 0x3d, 0x24, 0x87, 0x54, 0x2a, 0x87, 0xe4, 0x90, 0x7f, 0x20, 0x5f, 0x95, 0x43,
+	0xaa, 0xf2, 0x47, 0xe4, 0x92, 0x54, 0xce, 0xb9, 0xe4, 0x90, 0x4b, 0xaa, 0xde, 0x3f, 0x90, 0xff,
+	0x20, 0x35, 0x3d, 0x03, 0x10, 0x00, 0x49, 0xd9, 0xcf, 0x55, 0x7b, 0xe3, 0x74, 0x37, 0x7a, 0x7a,
+	0x7a, 0xfa, 0xd7, 0xd3, 0xd3, 0x43, 0xa8, 0x8c, 0x26, 0x36, 0x75, 0xd8, 0x63, 0xcf, 0x77, 0x99,
+	0x4b, 0x0a, 0x13, 0xd7, 0xf5, 0x7c, 0x6f, 0xd4, 0xfc, 0xe8, 0xc2, 0x75, 0x2f, 0x26, 0xf4, 0xc0,
+	0xf2, 0xec, 0x03, 0xcb, 0x71, 0x5c, 0x66, 0x31, 0xdb, 0x75, 0x02, 0x21, 0xa6, 0xfd, 0x67, 0x01,
+	0x6a, 0x27, 0xae, 0xeb, 0xf5, 0x66, 0xcc, 0xa0, 0xdf, 0xcf, 0x68, 0xc0, 0x88, 0x0a, 0x59, 0x6b,
+	0xca, 0x1a, 0xca, 0xae, 0xb2, 0x97, 0x35, 0xf8, 0x4f, 0x42, 0x20, 0x37, 0xa6, 0x01, 0x6b, 0x64,
+	0x76, 0x95, 0xbd, 0x92, 0x81, 0xbf, 0xc9, 0x01, 0x6c, 0x4e, 0xad, 0x2b, 0x33, 0x78, 0x6b, 0x79,
+	0xa6, 0xef, 0xce, 0x98, 0xed, 0x5c, 0x98, 0xe7, 0x94, 0x36, 0xb2, 0xf8, 0xd9, 0xfa, 0xd4, 0xba,
+	0x1a, 0xbc, 0xb5, 0x3c, 0x43, 0x70, 0x8e, 0x28, 0x25, 0x3f, 0x85, 0x6d, 0xfe, 0x81, 0xe7, 0x53,
+	0xcf, 0xba, 0x4e, 0x7c, 0x92, 0xc3, 0x4f, 0x36, 0xa6, 0xd6, 0x55, 0x1f, 0x99, 0xb1, 0x8f, 0x76,
+	0xa1, 0x12, 0xcd, 0xc2, 0x45, 0xd7, 0x50, 0x14, 0xa4, 0x76, 0x2e, 0xf1, 0x31, 0xd4, 0x62, 0x6a,
+	0xb9, 0xe1, 0x79, 0x94, 0xa9, 0x44, 0xea, 0x5a, 0x53, 0x46, 0x34, 0xa8, 0x72, 0xa9, 0xa9, 0xed,
+	0x50, 0x1f, 0x15, 0x15, 0x50, 0xa8, 0x3c, 0xb5, 0xae, 0x4e, 0x39, 0x8d, 0x6b, 0xda, 0x03, 0x95,
+	0xfb, 0xcc, 0x74, 0x67, 0xcc, 0x1c, 0x5d, 0x5a, 0x8e, 0x43, 0x27, 0x8d, 0xe2, 0xae, 0xb2, 0x97,
+	0x33, 0x6a, 0x13, 0xe1, 0xa1, 0xb6, 0xa0, 0x92, 0x7d, 0x58, 0x0f, 0xde, 0x52, 0xea, 0x99, 0x23,
+	0xd7, 0x39, 0x37, 0x99, 0xe5, 0x5f, 0x50, 0xd6, 0x28, 0xed, 0x2a, 0x7b, 0x6b, 0x46, 0x1d, 0x19,
+	0x6d, 0xd7, 0x39, 0x1f, 0x22, 0x99, 0x7c, 0x0d, 0xb7, 0xd1, 0x7a, 0x6f, 0xf6, 0x7a, 0x62, 0x8f,
+	0xd0, 0xf7, 0xe6, 0x98, 0x5a, 0xe3, 0x89, 0xed, 0xd0, 0x06, 0xa0, 0xfa, 0x1d, 0x2e, 0xd0, 0x9f,
+	0xf3, 0x0f, 0x25, 0x9b, 0x6c, 0xc2, 0x5a, 0xe0, 0x4d, 0x6c, 0xd6, 0x28, 0xef, 0x2a, 0x7b, 0x45,
+	0x43, 0x0c, 0xc8, 0x6d, 0x28, 0x7e, 0x3f, 0x73, 0x19, 0x35, 0xed, 0x71, 0xa3, 0xb2, 0xab, 0xec,
+	0x55, 0x8c, 0x02, 0x8e, 0x3b, 0xe3, 0xd0, 0x19, 0xcc, 0x65, 0xd6, 0xc4, 0x1c, 0xb9, 0x01, 0x6b,
+	0x54, 0x23, 0x67, 0x0c, 0x39, 0xb1, 0xed, 0x06, 0x8c, 0x7c, 0x01, 0x24, 0x29, 0x65, 0x7a, 0xde,
+	0xb4, 0x51, 0x43, 0x5b, 0xea, 0x71, 0xc9, 0xbe, 0x37, 0xe5, 0x36, 0x78, 0xbe, 0xfb, 0x9a, 0x36,
+	0xea, 0xc2, 0x06, 0x1c, 0x90, 0x13, 0xf8, 0x58, 0x78, 0xe0, 0x9c, 0x52, 0xd3, 0xb7, 0x18, 0x35,
+	0x47, 0xd4, 0x9e, 0xf0, 0x0d, 0x0d, 0x2c, 0x66, 0x7a, 0xd4, 0x37, 0xdf, 0xbc, 0xbe, 0x66, 0xb4,
+	0xa1, 0xa2, 0xd2, 0x7b, 0x28, 0x7b, 0x44, 0xa9, 0x61, 0x31, 0xda, 0x16, 0x82, 0x03, 0x8b, 0xf5,
+	0xa9, 0xff, 0x82, 0x4b, 0x91, 0xc7, 0xb0, 0x21, 0xb4, 0x05, 0xd6, 0x39, 0x65, 0xd7, 0xe6, 0xd4,
+	0xf2, 0x2f, 0x6c, 0xa7, 0xb1, 0x8e, 0x1e, 0x15, 0xae, 0x1e, 0x20, 0xe7, 0x14, 0x19, 0xe4, 0x2b,
+	0xa8, 0x0a, 0x79, 0x77, 0xc6, 0xbc, 0x19, 0x0b, 0x1a, 0x64, 0x37, 0xbb, 0x57, 0x7e, 0xba, 0xf9,
+	0x58, 0xc6, 0xfc, 0xe3, 0x01, 0xe7, 0xf6, 0x90, 0x69, 0x54, 0x82, 0xf9, 0x20, 0x20, 0x77, 0xa0,
+	0x14, 0x50, 0xff, 0x0d, 0xf5, 0xb9, 0xf7, 0x36, 0x30, 0x9e, 0x8b, 0x82, 0xd0, 0x19, 0x93, 0xcf,
+	0xa0, 0x6e, 0x8f, 0xe9, 0xd4, 0x73, 0x19, 0x75, 0x46, 0xd7, 0xe6, 0x77, 0xf4, 0xba, 0xb1, 0x89,
+	0x22, 0xb5, 0x18, 0xf9, 0x5b, 0x7a, 0xcd, 0x9d, 0x32, 0xb1, 0x5e, 0xd3, 0x49, 0x63, 0x0b, 0xd9,
+	0x62, 0x40, 0x5a, 0x50, 0x9c, 0x52, 0x66, 0x8d, 0x2d, 0x66, 0x35, 0xb6, 0xd1, 0xa2, 0x4f, 0x22,
+	0x8b, 0x92, 0x18, 0x7b, 0x7c, 0x2a, 0xe5, 0x74, 0x87, 0xf9, 0xd7, 0x46, 0xf4, 0x59, 0xf3, 0xb7,
+	0xa1, 0x9a, 0x60, 0x71, 0x30, 0x72, 0x33, 0x14, 0x9c, 0x27, 0xfb, 0x9d, 0x98, 0xfb, 0x8d, 0x35,
+	0x99, 0x51, 0x89, 0x46, 0x31, 0xf8, 0x3a, 0xf3, 0x5b, 0x8a, 0xf6, 0x2d, 0x94, 0x63, 0x0b, 0xe7,
+	0xa8, 0xb5, 0xc6, 0x63, 0x5f, 0x7e, 0x8b, 0xbf, 0x43, 0x6c, 0x67, 0xe6, 0xd8, 0xde, 0x86, 0xfc,
+	0x5b, 0x6a, 0x5f, 0x5c, 0x32, 0x44, 0x6e, 0xd5, 0x90, 0x23, 0xed, 0xdf, 0xd6, 0xa0, 0xca, 0x8d,
+	0xee, 0x38, 0xab, 0xf3, 0x42, 0x1a, 0x9d, 0x99, 0x05, 0x74, 0x2e, 0xe0, 0x2e, 0xbb, 0x88, 0xbb,
+	0x4f, 0xa1, 0x8e, 0xb8, 0xb3, 0x9d, 0x08, 0x76, 0x39, 0x0c, 0x9b, 0xea, 0x04, 0xe7, 0x0f, 0x51,
+	0xf7, 0x10, 0xaa, 0xf4, 0x8a, 0x51, 0xdf, 0xb1, 0x26, 0xe6, 0x25, 0x9b, 0x8c, 0x30, 0x19, 0x14,
+	0x8d, 0x4a, 0x48, 0x7c, 0xc6, 0x26, 0xa3, 0x39, 0x64, 0xf2, 0xab, 0x20, 0x53, 0x78, 0x17, 0x64,
+	0x8a, 0xef, 0x0d, 0x99, 0xd2, 0x72, 0xc8, 0xdc, 0x87, 0xb2, 0x4f, 0xcf, 0x67, 0xce, 0xd8, 0x44,
+	0xff, 0x03, 0xfa, 0x1f, 0x04, 0xa9, 0xc5, 0x77, 0xe1, 0x11, 0x10, 0x29, 0x10, 0x4f, 0x20, 0x65,
+	0x0c, 0x77, 0x55, 0x70, 0x62, 0x19, 0xe4, 0x00, 0x36, 0xa5, 0x74, 0x12, 0x5b, 0x15, 0x9c, 0x7d,
+	0x5d, 0xf0, 0xe2, 0x70, 0x7a, 0x04, 0xc4, 0xb1, 0x98, 0xfd, 0x86, 0x9a, 0x01, 0xbd, 0x78, 0x6b,
+	0x33, 0xe1, 0xad, 0x2a, 0x3a, 0x44, 0x15, 0x9c, 0x01, 0x32, 0xd0, 0x63, 0x09, 0x44, 0xd4, 0xde,
+	0x8d, 0x88, 0xfa, 0xcd, 0x88, 0x50, 0xe3, 0x88, 0xf8, 0x79, 0x0c, 0x11, 0xeb, 0x88, 0x88, 0x8f,
+	0x13, 0x88, 0xe8, 0x38, 0x3f, 0x2a, 0x20, 0xfe, 0x4a, 0x81, 0x0a, 0x9e, 0x42, 0x34, 0xf0, 0x5c,
+	0x27, 0xa0, 0xa4, 0x06, 0x19, 0x7b, 0x2c, 0xbf, 0xcd, 0xd8, 0x63, 0xf2, 0x00, 0x2a, 0xdc, 0x37,
+	0xb8, 0x4f, 0x34, 0x08, 0xa4, 0x86, 0x32, 0xa7, 0xb5, 0x04, 0x89, 0x87, 0xce, 0x85, 0xef, 0xce,
+	0x3c, 0xee, 0x9d, 0x2c, 0xb2, 0x0b, 0x38, 0xee, 0x8c, 0xc9, 0x23, 0x58, 0xf3, 0x2c, 0x9f, 0x05,
+	0x8d, 0x1c, 0x2e, 0x6d, 0x3b, 0x96, 0x7e, 0x2c, 0xef, 0x98, 0x0b, 0xf5, 0x2d, 0x9f, 0x19, 0x42,
+	0x48, 0x1b, 0x42, 0x35, 0x41, 0xff, 0x10, 0x63, 0x24, 0x04, 0xb3, 0x11, 0x04, 0xb5, 0x1d, 0xd8,
+	0x3a, 0xb1, 0x03, 0x16, 0x69, 0x0e, 0xa4, 0x43, 0xb5, 0x43, 0xd8, 0x4e, 0x33, 0xa4, 0x13, 0xf6,
+	0x21, 0x8f, 0x2b, 0x08, 0x1a, 0x0a, 0xda, 0x4d, 0x16, 0xed, 0x36, 0xa4, 0x84, 0xf6, 0xbf, 0x19,
+	0x28, 0x45, 0xd4, 0x05, 0x8b, 0x3f, 0x81, 0x1c, 0xbb, 0xf6, 0x84, 0xe3, 0x6b, 0x4f, 0xd7, 0x13,
+	0x7a, 0x86, 0xd7, 0x1e, 0x35, 0x90, 0x4d, 0xbe, 0x84, 0xb5, 0x80, 0x59, 0x4c, 0x80, 0xbf, 0xf6,
+	0x74, 0x67, 0x71, 0xbe, 0x01, 0x67, 0x1b, 0x42, 0x2a, 0x5c, 0x64, 0x6e, 0x9e, 0x67, 0xee, 0x43,
+	0xd9, 0x9a, 0x32, 0xcc, 0x33, 0x1e, 0x1d, 0x87, 0x45, 0x80, 0x35, 0xc5, 0xd5, 0x79, 0x54, 0x84,
+	0xa9, 0x63, 0x33, 0x5b, 0x1c, 0xaf, 0xcc, 0x9e, 0x52, 0x59, 0x05, 0xd4, 0xe6, 0xe4, 0xa1, 0x3d,
+	0xa5, 0x5c, 0x13, 0xa2, 0x57, 0x04, 0xb8, 0xac, 0x02, 0x80, 0x93, 0x06, 0x48, 0xe1, 0x9b, 0x80,
+	0x02, 0xae, 0x33, 0xba, 0xb4, 0x6c, 0x47, 0x26, 0x03, 0xfc, 0xa8, 0x27, 0x48, 0x3c, 0x0f, 0x09,
+	0x91, 0xf3, 0x73, 0x21, 0x53, 0x12, 0x09, 0x03, 0x65, 0x24, 0x8d, 0x7c, 0x0e, 0x6b, 0xdc, 0xdc,
+	0xa0, 0x01, 0xe8, 0xe3, 0x8d, 0xc4, 0x9a, 0xf9, 0x72, 0x67, 0x81, 0x21, 0x24, 0xb4, 0x5f, 0x29,
+	0x3c, 0x6f, 0x5b, 0xde, 0xd0, 0xb7, 0x2f, 0x2e, 0xa8, 0x4f, 0xee, 0x02, 0x38, 0x2e, 0x33, 0x5f,
+	0xd3, 0x73, 0xd7, 0xa7, 0x32, 0xdd, 0x96, 0x1c, 0x97, 0x7d, 0x83, 0x04, 0x5e, 0x7c, 0xcc, 0xd9,
+	0xe6, 0xa5, 0xc8, 0xdd, 0x19, 0x51, 0x7c, 0x44, 0x52, 0xcf, 0x90, 0x4c, 0xbe, 0x82, 0x26, 0x4f,
+	0x5b, 0xd1, 0x21, 0x9d, 0x4c, 0x20, 0x59, 0x4c, 0x20, 0x5b, 0x53, 0xeb, 0x4a, 0x1e, 0xcd, 0xf1,
+	0x24, 0xf2, 0x29, 0xd4, 0xf9, 0x67, 0xf1, 0x04, 0x95, 0xc3, 0x49, 0xaa, 0xe7, 0x94, 0xc6, 0xb2,
+	0xd3, 0x67, 0x50, 0x0f, 0xcb, 0x99, 0xd0, 0x98, 0x35, 0x94, 0xab, 0x85, 0x64, 0x61, 0x8b, 0xf6,
+	0x2f, 0x0a, 0x6c, 0x0c, 0x46, 0x97, 0x74, 0x3c, 0x9b, 0x50, 0x01, 0x4a, 0x71, 0xac, 0x3c, 0x86,
+	0x02, 0x13, 0x2b, 0xc7, 0xb5, 0x26, 0x8f, 0xf1, 0xc8, 0x2b, 0x46, 0x28, 0x44, 0x9e, 0x42, 0x31,
+	0x2c, 0xd3, 0x70, 0xd9, 0xe5, 0xa7, 0x3b, 0x89, 0x9c, 0x32, 0x3f, 0x65, 0x8d, 0x82, 0xac, 0xdb,
+	0xc8, 0x01, 0x14, 0xe4, 0x11, 0x83, 0x8b, 0x8e, 0x63, 0x35, 0x91, 0x86, 0x8c, 0xbc, 0x38, 0x72,
+	0xb4, 0xff, 0xc8, 0x00, 0xf0, 0xd9, 0x3b, 0x0e, 0xa3, 0x0e, 0xfb, 0xd0, 0xc0, 0x7f, 0x9c, 0x0c,
+	0xfc, 0x46, 0x42, 0x4e, 0xa8, 0x4e, 0x44, 0x7e, 0xcc, 0x15, 0xb9, 0xf7, 0x71, 0x85, 0x44, 0xca,
+	0xda, 0x62, 0xa5, 0x9e, 0x8f, 0x55, 0xea, 0x3c, 0x5e, 0x7d, 0x1a, 0x83, 0x46, 0x41, 0xc6, 0xab,
+	0x4f, 0xe7, 0xc0, 0xe0, 0xc5, 0xaf, 0x15, 0x30, 0x73, 0xe6, 0x8d, 0x79, 0xa0, 0xa0, 0x9c, 0x88,
+	0xfd, 0x1a, 0xa7, 0x9f, 0x21, 0x19, 0x25, 0x77, 0xa0, 0x80, 0x07, 0xbe, 0x3d, 0xc6, 0xc0, 0x2f,
+	0x19, 0x79, 0x3e, 0xec, 0x8c, 0x79, 0x1e, 0xa6, 0xbe, 0xef, 0x86, 0x07, 0x9e, 0x18, 0x68, 0x8d,
+	0x79, 0x1e, 0x12, 0x2b, 0x8e, 0x32, 0xd4, 0x33, 0xd8, 0x59, 0xe0, 0xc8, 0x14, 0xf5, 0x25, 0x14,
+	0x6c, 0x41, 0x6a, 0x28, 0x4b, 0xf0, 0x23, 0xc4, 0x8d, 0x50, 0x46, 0xfb, 0x1c, 0x76, 0xda, 0x96,
+	0x33, 0xa2, 0x93, 0x18, 0x53, 0x46, 0x57, 0x6a, 0xe7, 0xb4, 0xbf, 0xc9, 0x40, 0xb3, 0xcd, 0x17,
+	0x4e, 0x0d, 0x3a, 0x9a, 0xf9, 0x3e, 0xaf, 0x44, 0x63, 0xc1, 0x78, 0x17, 0x20, 0x60, 0x96, 0xcf,
+	0x84, 0x03, 0x24, 0xf6, 0x90, 0x82, 0x6b, 0x7f, 0x00, 0x15, 0x3e, 0xa7, 0xff, 0xc6, 0x9a, 0x98,
+	0x01, 0x1d, 0xe1, 0xfe, 0xe7, 0x8c, 0x72, 0x48, 0x1b, 0xd0, 0x11, 0xd7, 0xe0, 0x51, 0xdf, 0x76,
+	0xc7, 0x28, 0x20, 0x20, 0x56, 0x12, 0x14, 0xce, 0x96, 0x85, 0x84, 0x35, 0x15, 0x40, 0x14, 0x0c,
+	0x99, 0xeb, 0x78, 0x21, 0xd1, 0x9a, 0x72, 0x08, 0xf6, 0x91, 0x9c, 0x08, 0xf5, 0xb5, 0x5f, 0x3f,
+	0xd4, 0xf3, 0xef, 0x15, 0xea, 0x7f, 0xad, 0x80, 0x9a, 0xf4, 0xc5, 0xcc, 0x21, 0x9f, 0x40, 0x2d,
+	0x90, 0x58, 0x1d, 0xc7, 0x7d, 0x51, 0x8d, 0xa8, 0xe8, 0x0f, 0x02, 0x39, 0x64, 0x8a, 0xc2, 0x0f,
+	0x7f, 0x2f, 0x9e, 0x51, 0xf1, 0x88, 0xc9, 0x2d, 0x8f, 0x98, 0xb5, 0x78, 0xc4, 0xfc, 0x43, 0x16,
+	0xaa, 0x09, 0x83, 0x3e, 0x14, 0x7e, 0x4f, 0x92, 0xf0, 0xbb, 0x13, 0xc9, 0x25, 0xb4, 0xbf, 0xe3,
+	0xec, 0x09, 0x11, 0xb5, 0x76, 0x13, 0xa2, 0xf2, 0x4b, 0x10, 0x95, 0x0c, 0xa5, 0xc2, 0xbb, 0x42,
+	0xa9, 0xf8, 0xae, 0x50, 0x2a, 0xbd, 0x5f, 0x28, 0xc1, 0xf2, 0x50, 0xfa, 0x12, 0x72, 0xfe, 0xcc,
+	0x09, 0x1a, 0x65, 0x84, 0xd3, 0xed, 0xe5, 0xae, 0x30, 0x66, 0x8e, 0x81, 0x62, 0xfc, 0x9c, 0x3c,
+	0xb7, 0x6c, 0xbe, 0xf9, 0xf8, 0x55, 0x05, 0xaf, 0x06, 0x20, 0x48, 0xc6, 0xcc, 0x09, 0xb4, 0x3b,
+	0x70, 0x9b, 0x83, 0x37, 0xf1, 0x79, 0x84, 0xec, 0x3f, 0x82, 0xe6, 0x32, 0xa6, 0x04, 0xf7, 0xef,
+	0x41, 0xdd, 0x0f, 0x39, 0xa6, 0x38, 0x24, 0x95, 0x54, 0x01, 0x95, 0xb4, 0xaa, 0xe6, 0x27, 0x14,
+	0x69, 0x8f, 0xa0, 0x29, 0xe0, 0xbe, 0x14, 0xc2, 0x69, 0xc4, 0x9f, 0xc0, 0x5d, 0x83, 0x5e, 0xd8,
+	0x01, 0xa3, 0xfe, 0x21, 0x0d, 0xd8, 0x21, 0x0d, 0x46, 0xbe, 0xed, 0x31, 0xd7, 0x0f, 0x3f, 0xf8,
+	0x02, 0xd6, 0xc5, 0x3d, 0xd2, 0x1c, 0x47, 0x3c, 0xf9, 0xbd, 0x2a, 0x18, 0xf3, 0x6f, 0xb4, 0x26,
+	0x34, 0x8e, 0x29, 0x5b, 0xaa, 0x48, 0x7b, 0x00, 0xf7, 0xcf, 0x1c, 0xff, 0xa6, 0xb9, 0x34, 0x0d,
+	0x76, 0x57, 0x8b, 0x08, 0xff, 0x68, 0x7f, 0x08, 0xb5, 0x24, 0xe7, 0xd7, 0xb2, 0x10, 0xcb, 0x07,
+	0x7a, 0xc5, 0x4c, 0xdb, 0x19, 0xd3, 0x2b, 0x84, 0x48, 0xd5, 0x28, 0x71, 0x4a, 0x87, 0x13, 0xb4,
+	0x7f, 0x56, 0xa0, 0x76, 0xea, 0x3a, 0x76, 0xcc, 0x01, 0x51, 0xed, 0xae, 0xac, 0xba, 0xcd, 0x66,
+	0x52, 0xb7, 0xd9, 0xa4, 0x82, 0x1f, 0xa7, 0x78, 0xff, 0xfb, 0x3c, 0x40, 0x88, 0xcf, 0x59, 0xb0,
+	0xe4, 0xf6, 0x29, 0x36, 0x3a, 0xb3, 0x90, 0x15, 0xb2, 0x37, 0x67, 0x85, 0xbd, 0x30, 0x2b, 0xe4,
+	0x50, 0x8e, 0x2c, 0x54, 0x66, 0x51, 0x32, 0x58, 0x52, 0x55, 0xae, 0x2d, 0xad, 0x2a, 0x97, 0x1d,
+	0x9e, 0xf9, 0xa5, 0x87, 0x67, 0xba, 0xc6, 0x2f, 0x2c, 0xd6, 0xf8, 0xa9, 0x12, 0xb5, 0xf8, 0xce,
+	0x12, 0xb5, 0xf4, 0x1e, 0x25, 0x2a, 0x2c, 0x29, 0x51, 0x7f, 0x0e, 0x75, 0xcf, 0xba, 0x9e, 0x52,
+	0x87, 0x99, 0x1c, 0xd8, 0x33, 0x9f, 0x36, 0xca, 0xa9, 0x43, 0xa6, 0x2f, 0xf8, 0x47, 0x82, 0x6d,
+	0xd4, 0xbc, 0xc4, 0x98, 0xfc, 0x0c, 0x6a, 0xb2, 0x6f, 0xc3, 0x78, 0x79, 0x79, 0x71, 0x8d, 0x89,
+	0x22, 0x79, 0x13, 0xa2, 0xd4, 0x1b, 0x48, 0xae, 0x51, 0x0d, 0xe2, 0xc3, 0xd8, 0x3d, 0x99, 0x5d,
+	0xd9, 0xe3, 0x46, 0x35, 0x7e, 0x4f, 0x1e, 0x5e, 0xd9, 0x63, 0xf2, 0x13, 0x28, 0x8e, 0xa9, 0xe7,
+	0x06, 0x36, 0x0b, 0x1a, 0xb5, 0x54, 0x8b, 0x87, 0xdf, 0x5d, 0x0f, 0x05, 0xd3, 0x88, 0xa4, 0xc8,
+	0xef, 0x42, 0x55, 0x5e, 0x66, 0x03, 0x0c, 0x1b, 0xbc, 0xad, 0xc6, 0xf3, 0x9d, 0xf0, 0x61, 0xac,
+	0x08, 0xaf, 0x08, 0x79, 0x31, 0x4a, 0x5e, 0x86, 0xd5, 0xd4, 0x65, 0x38, 0xc2, 0xc9, 0x7a, 0x1c,
+	0x27, 0x3f, 0x8b, 0xe1, 0x44, 0xf4, 0xa1, 0x1e, 0x2c, 0x29, 0xf6, 0x7f, 0x1c, 0x8c, 0xfc, 0xab,
+	0x02, 0x6a, 0x7a, 0x45, 0xe4, 0x4b, 0xc8, 0xcb, 0xc5, 0x2b, 0x18, 0xe1, 0x5b, 0xe9, 0xc5, 0x8b,
+	0x85, 0x4b, 0x21, 0xde, 0x00, 0xf2, 0xa9, 0x15, 0xb8, 0x8e, 0x54, 0x2f, 0x47, 0xe4, 0x09, 0x6c,
+	0xd2, 0x2b, 0x8f, 0x8e, 0x18, 0x1d, 0xc7, 0x9b, 0x97, 0xf2, 0x60, 0xdf, 0x08, 0x79, 0xb1, 0xbe,
+	0x25, 0xdf, 0xd0, 0x38, 0x04, 0xc4, 0x29, 0x0a, 0xb3, 0x28, 0xfc, 0xb5, 0x7f, 0x54, 0xa0, 0x1c,
+	0xdb, 0x38, 0xd2, 0x84, 0x22, 0xcf, 0x5f, 0xae, 0xed, 0x30, 0xb9, 0xe0, 0x68, 0xbc, 0xa4, 0x55,
+	0xd5, 0x84, 0xa2, 0x35, 0x1a, 0x51, 0x8f, 0x51, 0x71, 0x15, 0x2f, 0x1a, 0xd1, 0x98, 0x7c, 0x91,
+	0x44, 0xf5, 0x7c, 0xcd, 0x72, 0xaa, 0x04, 0xb0, 0xf9, 0xd1, 0xec, 0xd1, 0x30, 0xee, 0xc4, 0xc9,
+	0x5e, 0x42, 0x0a, 0x0f, 0x3b, 0xed, 0xdf, 0x15, 0x7e, 0x55, 0x4f, 0x45, 0x6a, 0xfc, 0x22, 0xa4,
+	0xe0, 0x05, 0x07, 0x46, 0xf3, 0x5b, 0x50, 0x0b, 0xee, 0xbd, 0xa3, 0x13, 0x2a, 0x4a, 0xc5, 0xdb,
+	0xe7, 0x2b, 0x9b, 0xa0, 0x0f, 0xa1, 0x9a, 0x6c, 0x7f, 0x66, 0x71, 0x96, 0x4a, 0x10, 0xef, 0x7c,
+	0x3e, 0x8a, 0xf6, 0x56, 0xac, 0x73, 0x33, 0x8d, 0xb4, 0xf8, 0xd6, 0x6a, 0x6f, 0xa1, 0x96, 0x44,
+	0x30, 0xbf, 0x61, 0x48, 0x0c, 0x37, 0x94, 0x94, 0x02, 0x29, 0x89, 0x99, 0x32, 0x14, 0x22, 0xbf,
+	0x99, 0x08, 0x8e, 0xda, 0xd3, 0xbb, 0xab, 0x52, 0x03, 0x0a, 0x85, 0xb1, 0xa3, 0x7d, 0x01, 0x95,
+	0x21, 0xf5, 0xa7, 0x61, 0x41, 0x90, 0x84, 0x95, 0x92, 0x84, 0x95, 0xf6, 0x03, 0x54, 0xa5, 0xb0,
+	0x2c, 0x10, 0x3e, 0x85, 0xfa, 0xd4, 0x76, 0x44, 0x5b, 0xd1, 0x9a, 0xba, 0x33, 0x27, 0xbc, 0xe2,
+	0x54, 0xa7, 0xb6, 0xc3, 0x03, 0xbd, 0x85, 0x44, 0x94, 0xb3, 0xae, 0x12, 0x72, 0x79, 0x29, 0x67,
+	0x5d, 0xcd, 0xe5, 0x9e, 0xe7, 0x8a, 0x8a, 0x9a, 0x79, 0x9e, 0x2b, 0x66, 0xd4, 0xec, 0xf3, 0x5c,
+	0x31, 0xab, 0xe6, 0x9e, 0xe7, 0x8a, 0x39, 0x75, 0xed, 0x79, 0xae, 0x58, 0x50, 0x8b, 0xda, 0x3f,
+	0x65, 0xa0, 0xf2, 0xfb, 0x33, 0x97, 0xd1, 0xd5, 0x7d, 0xce, 0xd4, 0xf6, 0x67, 0x16, 0xb6, 0x7f,
+	0xa1, 0x35, 0x99, 0x5d, 0xd2, 0x9a, 0xbc, 0xf1, 0x25, 0x20, 0xf7, 0x9e, 0x2f, 0x01, 0x6b, 0xf1,
+	0xb6, 0xe6, 0xb2, 0x17, 0x8b, 0xfc, 0xd2, 0x17, 0x8b, 0x87, 0xe9, 0x8e, 0x79, 0x01, 0xeb, 0x82,
+	0x1b, 0x7a, 0xe3, 0xc5, 0xd4, 0x2e, 0xfd, 0x59, 0x16, 0xaa, 0xd2, 0x4d, 0x72, 0x9b, 0x6e, 0x43,
+	0x31, 0xea, 0xfc, 0x0a, 0x67, 0x61, 0x99, 0xcf, 0x5b, 0xba, 0xbc, 0x72, 0x9d, 0x3f, 0xc8, 0x08,
+	0x08, 0x97, 0xbc, 0xe8, 0x35, 0xe6, 0x0e, 0x94, 0xd2, 0x1d, 0xe1, 0xe2, 0x34, 0x6c, 0x07, 0xe3,
+	0xe3, 0x0a, 0x77, 0x93, 0x3c, 0x9b, 0xb0, 0xfa, 0xce, 0x61, 0xd3, 0xb6, 0x8e, 0xee, 0x11, 0xf4,
+	0x43, 0x79, 0x5d, 0x1b, 0x4d, 0xd8, 0x1b, 0x73, 0x4c, 0x27, 0xcc, 0x92, 0x7d, 0x87, 0x12, 0xa7,
+	0x1c, 0x72, 0x02, 0x9f, 0xc7, 0x99, 0x4d, 0x65, 0x8d, 0x99, 0x47, 0x6e, 0xd1, 0x99, 0x4d, 0xb1,
+	0x8a, 0xbc, 0xa9, 0x27, 0xfc, 0x00, 0x2a, 0x82, 0x45, 0xaf, 0x3c, 0xdb, 0xbf, 0x0e, 0x9b, 0x40,
+	0x48, 0xd3, 0x91, 0xc4, 0x5d, 0xbf, 0xf0, 0xf4, 0x25, 0x0e, 0xe2, 0x5a, 0x90, 0x7c, 0xf7, 0x7a,
+	0x04, 0x64, 0xc9, 0x9b, 0x97, 0x38, 0x90, 0x55, 0x2f, 0xfd, 0xe0, 0x95, 0xd8, 0x83, 0x72, 0x6a,
+	0x0f, 0xea, 0x50, 0x1d, 0xba, 0xdf, 0x51, 0x27, 0x2a, 0xb4, 0x7f, 0x07, 0x6a, 0x21, 0x61, 0xde,
+	0xdc, 0x63, 0x48, 0x59, 0x68, 0xee, 0x9d, 0x04, 0x16, 0x43, 0x61, 0x43, 0x4a, 0x68, 0xff, 0x9d,
+	0x81, 0x52, 0x44, 0xe5, 0x21, 0xf2, 0xda, 0x0a, 0xa8, 0x39, 0xb5, 0x46, 0x96, 0xef, 0xba, 0x0e,
+	0xee, 0x69, 0xc5, 0xa8, 0x70, 0xe2, 0xa9, 0xa4, 0x71, 0xcf, 0x84, 0xfb, 0x72, 0x69, 0x05, 0x97,
+	0xb8, 0xb5, 0x15, 0xa3, 0x2c, 0x69, 0xcf, 0xac, 0xe0, 0x92, 0x7c, 0x0e, 0x6a, 0x28, 0xe2, 0xf9,
+	0xd4, 0x9e, 0x5a, 0x17, 0x62, 0x8f, 0x2b, 0x46, 0x58, 0x6e, 0xf4, 0x25, 0x99, 0x3b, 0x51, 0xe0,
+	0xd6, 0xf4, 0x2c, 0x7b, 0x6c, 0x4e, 0x03, 0x2b, 0xbc, 0x7a, 0xd5, 0x04, 0xbd, 0x6f, 0xd9, 0xe3,
+	0xd3, 0xc0, 0x62, 0xe4, 0x09, 0x6c, 0xc5, 0xbc, 0x17, 0x13, 0x17, 0x89, 0x81, 0xf8, 0x91, 0x07,
+	0xa3, 0x4f, 0x1e, 0x40, 0x85, 0x9f, 0x42, 0x26, 0x5e, 0xca, 0xe8, 0x58, 0xa6, 0x86, 0x32, 0xa7,
+	0x89, 0x06, 0xc0, 0x98, 0x34, 0xa0, 0x80, 0x3b, 0x4c, 0x45, 0x04, 0x14, 0x8d, 0x70, 0xc8, 0x3f,
+	0x0e, 0x98, 0xeb, 0x5b, 0x17, 0xd4, 0x74, 0x2c, 0xd9, 0x0a, 0x29, 0x19, 0x65, 0x49, 0xeb, 0x5a,
+	0xd3, 0xd4, 0x4e, 0x95, 0x52, 0x3b, 0xb5, 0x05, 0x1b, 0xe2, 0xb0, 0x7d, 0x46, 0xad, 0x09, 0xbb,
+	0x0c, 0xf7, 0xeb, 0x18, 0x36, 0x93, 0x64, 0xb9, 0x6b, 0x07, 0x50, 0x10, 0x9f, 0x86, 0xdb, 0x96,
+	0x3e, 0xb3, 0xa5, 0x7c, 0x28, 0xa5, 0xfd, 0x1f, 0xef, 0x6c, 0xc7, 0x38, 0x37, 0x66, 0x58, 0xf2,
+	0x1b, 0xe1, 0xe1, 0x28, 0x92, 0xf8, 0xbd, 0x94, 0xf2, 0xb6, 0xeb, 0x38, 0x74, 0xc4, 0xf3, 0x4d,
+	0xe2, 0x94, 0x7c, 0x02, 0x9b, 0x23, 0x6e, 0xdd, 0x68, 0x86, 0x6f, 0x09, 0xb2, 0x46, 0x0c, 0xe4,
+	0x3b, 0xd1, 0x46, 0x8c, 0x27, 0x0f, 0x81, 0x80, 0xe3, 0x11, 0x0b, 0x61, 0x71, 0xab, 0x17, 0x97,
+	0xfd, 0x12, 0xa7, 0xe8, 0x9c, 0xc0, 0xbd, 0x8a, 0xec, 0x60, 0x36, 0x1a, 0xf1, 0xea, 0x57, 0x6c,
+	0x5e, 0x99, 0xd3, 0x06, 0x82, 0xc4, 0x51, 0xe9, 0x53, 0xe6, 0x5f, 0x9b, 0x56, 0x98, 0xcc, 0x0b,
+	0x38, 0x6e, 0xb1, 0xfd, 0x3f, 0x80, 0x5a, 0xb2, 0x61, 0x4c, 0xd6, 0xa1, 0x7a, 0x6c, 0xf4, 0xce,
+	0xfa, 0x66, 0x5f, 0xef, 0x1e, 0x76, 0xba, 0xc7, 0xea, 0xad, 0x39, 0x69, 0x70, 0xd6, 0x6e, 0xeb,
+	0x83, 0x81, 0xaa, 0x10, 0x15, 0x2a, 0x82, 0x74, 0xd4, 0xea, 0x9c, 0xe8, 0x87, 0x6a, 0x26, 0xf6,
+	0x5d, 0xcb, 0x18, 0x76, 0x5a, 0x27, 0x6a, 0x76, 0xff, 0x35, 0xd4, 0x53, 0x4d, 0x39, 0x42, 0xa0,
+	0xd6, 0xe9, 0x0e, 0xf5, 0xee, 0x30, 0xa6, 0x7e, 0x03, 0xea, 0x92, 0x76, 0xd2, 0x3a, 0xeb, 0xb6,
+	0x9f, 0xe9, 0x87, 0xaa, 0x12, 0x23, 0xb6, 0x5b, 0xdd, 0xb6, 0x1e, 0xcd, 0x21, 0x89, 0x72, 0xda,
+	0xec, 0xfe, 0x37, 0x40, 0x16, 0x3b, 0x0f, 0x64, 0x13, 0x54, 0x43, 0x6f, 0x9f, 0x19, 0x46, 0xa7,
+	0x7b, 0x6c, 0xb6, 0xda, 0xc3, 0xce, 0x0b, 0x5d, 0xbd, 0x45, 0xb6, 0x81, 0xcc, 0xa9, 0x91, 0x5a,
+	0x65, 0xff, 0x2f, 0xa3, 0x8d, 0x97, 0xd5, 0x5e, 0x13, 0xb6, 0x07, 0xba, 0xf1, 0x42, 0x37, 0xcc,
+	0xc1, 0xb0, 0x35, 0x3c, 0x1b, 0x98, 0xad, 0x76, 0x5b, 0xef, 0x0f, 0xf5, 0x43, 0xf5, 0x16, 0xb9,
+	0x07, 0xcd, 0x24, 0xef, 0xd9, 0xf0, 0xa4, 0x6d, 0x1e, 0xea, 0x27, 0xad, 0x57, 0x68, 0xf8, 0x2e,
+	0x7c, 0xb4, 0x84, 0xdf, 0x3f, 0xfb, 0xe6, 0xa4, 0x33, 0x78, 0x86, 0xab, 0x58, 0xd0, 0x6e, 0xe8,
+	0xcf, 0xf5, 0xf6, 0x10, 0x97, 0xd3, 0x85, 0x4a, 0xbc, 0xb8, 0x22, 0x5b, 0xb0, 0x7e, 0xa8, 0xf7,
+	0x7b, 0x83, 0xce, 0xd0, 0x6c, 0xf7, 0xba, 0x47, 0x1d, 0xe3, 0x14, 0x8d, 0x58, 0x87, 0x6a, 0x48,
+	0x1e, 0xbc, 0xd4, 0xfb, 0x43, 0x55, 0xe1, 0x4b, 0x0e, 0x49, 0x86, 0x7e, 0x74, 0xd6, 0x3d, 0xe4,
+	0x73, 0xed, 0xff, 0x42, 0x3e, 0x5f, 0xca, 0x85, 0xd5, 0x00, 0x06, 0x2f, 0x75, 0xbd, 0x6f, 0x76,
+	0x7b, 0x5d, 0x5d, 0xe8, 0x11, 0xe3, 0x97, 0xad, 0xce, 0x90, 0xef, 0x06, 0x3a, 0x5e, 0x90, 0xe2,
+	0x26, 0x47, 0x44, 0x7d, 0xd0, 0x6e, 0x9d, 0xb4, 0x84, 0xad, 0x07, 0x50, 0x8e, 0xd5, 0x37, 0x3c,
+	0x24, 0x06, 0x2f, 0x5b, 0x7c, 0xff, 0x5f, 0x9d, 0xea, 0xdd, 0xa1, 0x7a, 0x8b, 0xcf, 0xd6, 0x37,
+	0xf4, 0x70, 0xac, 0xec, 0xff, 0x8f, 0x02, 0x9b, 0xcb, 0x4a, 0x1c, 0xee, 0x11, 0xbe, 0xa1, 0x67,
+	0x86, 0x6e, 0x1a, 0x7a, 0x6b, 0xd0, 0xeb, 0x9a, 0x67, 0xdd, 0x6f, 0xbb, 0xbd, 0x97, 0x5d, 0xf5,
+	0xd6, 0x12, 0xde, 0xb0, 0x73, 0xaa, 0xf7, 0xce, 0xf8, 0x9a, 0xef, 0xc0, 0x4e, 0x8a, 0xd7, 0xed,
+	0x99, 0x46, 0xef, 0x6c, 0xa8, 0xab, 0x19, 0xd2, 0x80, 0xcd, 0x14, 0x53, 0x37, 0x8c, 0x9e, 0xa1,
+	0x66, 0xc9, 0x23, 0xd8, 0x4b, 0x71, 0x3a, 0xdd, 0x76, 0xcf, 0x30, 0xf4, 0xf6, 0x30, 0xb4, 0xde,
+	0x3c, 0xd4, 0x87, 0xad, 0xce, 0xc9, 0x40, 0xcd, 0x91, 0xcf, 0xe0, 0xe1, 0x82, 0xf4, 0xe0, 0xec,
+	0xe8, 0xa8, 0xd3, 0xee, 0x70, 0xc1, 0x6f, 0x5a, 0x27, 0x3c, 0x92, 0xd4, 0xb5, 0xfd, 0x4f, 0xa0,
+	0x18, 0x5e, 0x8b, 0x49, 0x05, 0x8a, 0x27, 0xbd, 0x5e, 0xdf, 0xe4, 0x76, 0xde, 0x22, 0x65, 0x28,
+	0xe0, 0xa8, 0xd3, 0x55, 0x95, 0xfd, 0x40, 0xbc, 0xfe, 0x88, 0xfd, 0xad, 0x42, 0xa9, 0xd3, 0xed,
+	0x0c, 0x3b, 0x2d, 0x11, 0x5c, 0x5b, 0xb0, 0xde, 0x37, 0xf4, 0xce, 0x69, 0xeb, 0x98, 0x4f, 0xf6,
+	0x42, 0x6f, 0x61, 0x80, 0x72, 0xd4, 0x2c, 0x44, 0x51, 0x19, 0x0a, 0x21, 0x1c, 0xb3, 0x04, 0x20,
+	0x2f, 0x11, 0x91, 0x13, 0xc8, 0x79, 0xd1, 0xeb, 0xb4, 0x75, 0x73, 0xa0, 0x0f, 0x87, 0x9c, 0xb8,
+	0xb6, 0x3f, 0x80, 0xad, 0xa5, 0x79, 0x09, 0x23, 0x40, 0x04, 0x63, 0xaf, 0x7b, 0xd2, 0xc1, 0xa0,
+	0xe0, 0x9b, 0x2d, 0x48, 0x87, 0xfa, 0xb1, 0xd1, 0x3a, 0x0c, 0x4d, 0x08, 0xe5, 0x8e, 0x8e, 0x50,
+	0x30, 0xf3, 0xf4, 0xef, 0xea, 0xa2, 0x9b, 0xd0, 0xc6, 0xff, 0xc8, 0x10, 0x03, 0x0a, 0xb2, 0x81,
+	0x4a, 0x56, 0xb5, 0x54, 0x9b, 0x5b, 0x89, 0x6b, 0x5c, 0xd4, 0x9e, 0xd9, 0xf9, 0xf3, 0xff, 0xfa,
+	0xd5, 0xdf, 0x66, 0xd6, 0xb5, 0xca, 0xc1, 0x9b, 0x27, 0x07, 0x5c, 0xe2, 0xc0, 0x9d, 0xb1, 0xaf,
+	0x95, 0x7d, 0xd2, 0x83, 0xbc, 0xe8, 0xb0, 0x92, 0x15, 0x2d, 0xd7, 0x55, 0x1a, 0xb7, 0x51, 0xa3,
+	0xaa, 0x95, 0x23, 0x8d, 0xb6, 0xc3, 0x15, 0x7e, 0x05, 0x05, 0xd9, 0x68, 0x89, 0x19, 0x99, 0x6c,
+	0xbd, 0x34, 0x97, 0x3d, 0x2c, 0xfd, 0x44, 0x21, 0xaf, 0xa0, 0x22, 0x57, 0x83, 0xa5, 0x35, 0x99,
+	0xcf, 0x1c, 0xaf, 0xcb, 0x9b, 0xdb, 0x69, 0xb2, 0xb4, 0xa8, 0x89, 0x16, 0x6d, 0x12, 0x12, 0x5f,
+	0xe3, 0x01, 0x43, 0x55, 0x66, 0xa4, 0x1a, 0xcb, 0xc1, 0x98, 0xea, 0x78, 0x15, 0xdd, 0xdc, 0x4e,
+	0x93, 0xa5, 0xea, 0x5d, 0x54, 0xdd, 0x24, 0x8d, 0x84, 0x6a, 0x2c, 0xad, 0x0e, 0x7e, 0x69, 0x4d,
+	0xd9, 0x0f, 0xe4, 0x17, 0x50, 0x3b, 0xa6, 0x4c, 0x78, 0xee, 0x83, 0xac, 0xbf, 0x8d, 0x53, 0x6c,
+	0x90, 0xf5, 0x98, 0x3f, 0xa5, 0xf1, 0x7f, 0x12, 0xd3, 0xfd, 0x41, 0xe6, 0xdf, 0x47, 0xdd, 0xb7,
+	0xc9, 0x4e, 0x5c, 0x77, 0xdc, 0xfa, 0x3f, 0x85, 0x5a, 0xf2, 0xdd, 0x95, 0xcc, 0x8f, 0xdb, 0xa5,
+	0x2f, 0xb5, 0xcd, 0xfb, 0x2b, 0xf9, 0xc9, 0x88, 0x23, 0xf5, 0x68, 0x4e, 0xf1, 0x3a, 0x4b, 0xfe,
+	0x18, 0x2a, 0xf1, 0x17, 0x35, 0xf2, 0xd1, 0x3c, 0x18, 0x16, 0x1f, 0xda, 0x9a, 0xcb, 0xde, 0x50,
+	0xb4, 0x3b, 0xa8, 0x7b, 0x4b, 0x53, 0x63, 0xeb, 0xe1, 0x8c, 0x80, 0x07, 0xa0, 0x03, 0xf5, 0xd4,
+	0x0b, 0x0d, 0x59, 0x34, 0x36, 0xf9, 0xaa, 0xd3, 0xdc, 0x5d, 0x2d, 0x20, 0x97, 0xd3, 0xc0, 0x29,
+	0x09, 0x59, 0x98, 0x92, 0x5c, 0x82, 0x9a, 0x7e, 0xc7, 0x21, 0x73, 0x7d, 0x2b, 0x9e, 0x78, 0x96,
+	0xaf, 0xeb, 0x2e, 0x4e, 0xb2, 0xb3, 0xbf, 0x95, 0x9e, 0xe4, 0xe0, 0x97, 0xf6, 0xf8, 0x07, 0xf2,
+	0x3d, 0x6c, 0x2c, 0x79, 0x05, 0x22, 0x0f, 0xe7, 0x93, 0xad, 0x7c, 0x23, 0x6a, 0xae, 0x68, 0x53,
+	0x87, 0x53, 0x6a, 0x73, 0xd0, 0x44, 0x7d, 0x6b, 0xee, 0xcc, 0x6b, 0x20, 0x8b, 0x4d, 0x71, 0xa2,
+	0x25, 0xdc, 0xb5, 0xb4, 0x9d, 0xde, 0x7c, 0x78, 0xa3, 0xcc, 0x4a, 0xc8, 0x46, 0xb3, 0x93, 0x00,
+	0x36, 0x96, 0x34, 0xcc, 0xe3, 0xab, 0x5d, 0xd9, 0x4e, 0x5f, 0xb9, 0x5a, 0x09, 0x84, 0xfd, 0x9d,
+	0xc5, 0xf9, 0x84, 0x8b, 0x03, 0xd8, 0x5e, 0xde, 0x77, 0x27, 0x9f, 0xc6, 0x54, 0xde, 0xd0, 0x2c,
+	0x6f, 0xee, 0xc4, 0x9a, 0x38, 0x71, 0x7e, 0x18, 0x41, 0x5a, 0x35, 0x9a, 0x9b, 0x5f, 0x12, 0xb9,
+	0x93, 0xcf, 0x61, 0x7d, 0xa1, 0x3d, 0x4f, 0xe6, 0xfd, 0xb8, 0x55, 0xad, 0xfb, 0xd5, 0x53, 0x6d,
+	0xe1, 0x54, 0x75, 0x92, 0x9c, 0x8a, 0xfc, 0x85, 0x02, 0x8d, 0x55, 0x8d, 0x7c, 0xb2, 0x17, 0x29,
+	0x7b, 0xc7, 0x73, 0x40, 0xf3, 0xf3, 0xf7, 0x90, 0x94, 0xfb, 0x2b, 0x0d, 0xd9, 0x4f, 0x19, 0xf2,
+	0x0a, 0xaa, 0x3c, 0xa1, 0x85, 0xb7, 0xb8, 0x20, 0x76, 0xf6, 0x24, 0xae, 0x8a, 0xcd, 0x9d, 0x05,
+	0xfa, 0xd2, 0xec, 0x12, 0x58, 0xec, 0x40, 0x5c, 0x0f, 0x39, 0xfa, 0x8f, 0x29, 0x4b, 0xdc, 0x32,
+	0x3e, 0x5a, 0x7e, 0x2d, 0x91, 0x53, 0xdc, 0x5d, 0xc1, 0x95, 0x13, 0xdd, 0xc3, 0x89, 0x1a, 0x64,
+	0x3b, 0x5a, 0x81, 0xb8, 0xa0, 0x1c, 0x5c, 0xa2, 0xdc, 0xeb, 0x3c, 0xfe, 0x23, 0xf5, 0xa7, 0xff,
+	0x3f, 0x00, 0x7c, 0xc9, 0x99, 0xad, 0xc8, 0x2a, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1148,9 +4058,52 @@ type SwapClientClient interface {
 	//*
 	//GetQuote returns a quote for a swap with the provided parameters.
 	GetLoopInQuote(ctx context.Context, in *QuoteRequest, opts ...grpc.CallOption) (*QuoteResponse, error)
+	//* loop: `groups`
+	//ListSwapGroups returns all groups of swaps that were created from a single
+	//split swap request, along with their combined state and cost.
+	ListSwapGroups(ctx context.Context, in *ListSwapGroupsRequest, opts ...grpc.CallOption) (*ListSwapGroupsResponse, error)
+	//* loop: `schedule`
+	//ScheduleSwap persists a loop out or loop in swap intent. The swap is
+	//initiated once the trigger conditions of the intent are met. Pending
+	//intents are evaluated on every block.
+	ScheduleSwap(ctx context.Context, in *ScheduleSwapRequest, opts ...grpc.CallOption) (*SwapIntent, error)
+	//* loop: `intents`
+	//ListSwapIntents returns all swap intents, including the ones that already
+	//launched their swap, failed or were canceled.
+	ListSwapIntents(ctx context.Context, in *ListSwapIntentsRequest, opts ...grpc.CallOption) (*ListSwapIntentsResponse, error)
+	//* loop: `cancelintent`
+	//CancelSwapIntent cancels a pending swap intent, so that its swap is never
+	//initiated.
+	CancelSwapIntent(ctx context.Context, in *CancelSwapIntentRequest, opts ...grpc.CallOption) (*SwapIntent, error)
+	//* loop: `recurring`
+	//CreateRecurringSwap persists a loop out or loop in swap that is initiated
+	//once per interval. Every run is recorded, including the ones that failed.
+	CreateRecurringSwap(ctx context.Context, in *CreateRecurringSwapRequest, opts ...grpc.CallOption) (*RecurringSwap, error)
+	//* loop: `recurring list`
+	//ListRecurringSwaps returns all recurring swaps together with their runs.
+	ListRecurringSwaps(ctx context.Context, in *ListRecurringSwapsRequest, opts ...grpc.CallOption) (*ListRecurringSwapsResponse, error)
+	//* loop: `recurring cancel`
+	//CancelRecurringSwap cancels an active recurring swap, so that it doesn't
+	//initiate any further swaps.
+	CancelRecurringSwap(ctx context.Context, in *CancelRecurringSwapRequest, opts ...grpc.CallOption) (*RecurringSwap, error)
+	//* loop: `dest register`
+	//RegisterDestDescriptor registers an xpub or output descriptor that Loop
+	//Out destination addresses are derived from when no destination is given.
+	RegisterDestDescriptor(ctx context.Context, in *RegisterDestDescriptorRequest, opts ...grpc.CallOption) (*DestDescriptor, error)
+	//* loop: `dest`
+	//GetDestDescriptor returns the registered destination descriptor.
+	GetDestDescriptor(ctx context.Context, in *GetDestDescriptorRequest, opts ...grpc.CallOption) (*DestDescriptor, error)
+	//* loop: `dest unregister`
+	//UnregisterDestDescriptor removes the registered destination descriptor.
+	//Loop Outs without a destination sweep to the lnd wallet again.
+	UnregisterDestDescriptor(ctx context.Context, in *UnregisterDestDescriptorRequest, opts ...grpc.CallOption) (*UnregisterDestDescriptorResponse, error)
 	//*
 	//GetLsatTokens returns all LSAT tokens the daemon ever paid for.
 	GetLsatTokens(ctx context.Context, in *TokensRequest, opts ...grpc.CallOption) (*TokensResponse, error)
+	//* loop: `health`
+	//GetServerHealth returns the connection health of all configured swap
+	//servers.
+	GetServerHealth(ctx context.Context, in *ServerHealthRequest, opts ...grpc.CallOption) (*ServerHealthResponse, error)
 }
 
 type swapClientClient struct {
@@ -1247,6 +4200,96 @@ func (c *swapClientClient) GetLoopInQuote(ctx context.Context, in *QuoteRequest,
 	return out, nil
 }
 
+func (c *swapClientClient) ListSwapGroups(ctx context.Context, in *ListSwapGroupsRequest, opts ...grpc.CallOption) (*ListSwapGroupsResponse, error) {
+	out := new(ListSwapGroupsResponse)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/ListSwapGroups", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapClientClient) ScheduleSwap(ctx context.Context, in *ScheduleSwapRequest, opts ...grpc.CallOption) (*SwapIntent, error) {
+	out := new(SwapIntent)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/ScheduleSwap", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapClientClient) ListSwapIntents(ctx context.Context, in *ListSwapIntentsRequest, opts ...grpc.CallOption) (*ListSwapIntentsResponse, error) {
+	out := new(ListSwapIntentsResponse)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/ListSwapIntents", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapClientClient) CancelSwapIntent(ctx context.Context, in *CancelSwapIntentRequest, opts ...grpc.CallOption) (*SwapIntent, error) {
+	out := new(SwapIntent)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/CancelSwapIntent", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapClientClient) CreateRecurringSwap(ctx context.Context, in *CreateRecurringSwapRequest, opts ...grpc.CallOption) (*RecurringSwap, error) {
+	out := new(RecurringSwap)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/CreateRecurringSwap", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapClientClient) ListRecurringSwaps(ctx context.Context, in *ListRecurringSwapsRequest, opts ...grpc.CallOption) (*ListRecurringSwapsResponse, error) {
+	out := new(ListRecurringSwapsResponse)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/ListRecurringSwaps", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapClientClient) CancelRecurringSwap(ctx context.Context, in *CancelRecurringSwapRequest, opts ...grpc.CallOption) (*RecurringSwap, error) {
+	out := new(RecurringSwap)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/CancelRecurringSwap", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapClientClient) RegisterDestDescriptor(ctx context.Context, in *RegisterDestDescriptorRequest, opts ...grpc.CallOption) (*DestDescriptor, error) {
+	out := new(DestDescriptor)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/RegisterDestDescriptor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapClientClient) GetDestDescriptor(ctx context.Context, in *GetDestDescriptorRequest, opts ...grpc.CallOption) (*DestDescriptor, error) {
+	out := new(DestDescriptor)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/GetDestDescriptor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapClientClient) UnregisterDestDescriptor(ctx context.Context, in *UnregisterDestDescriptorRequest, opts ...grpc.CallOption) (*UnregisterDestDescriptorResponse, error) {
+	out := new(UnregisterDestDescriptorResponse)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/UnregisterDestDescriptor", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *swapClientClient) GetLsatTokens(ctx context.Context, in *TokensRequest, opts ...grpc.CallOption) (*TokensResponse, error) {
 	out := new(TokensResponse)
 	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/GetLsatTokens", in, out, opts...)
@@ -1256,6 +4299,15 @@ func (c *swapClientClient) GetLsatTokens(ctx context.Context, in *TokensRequest,
 	return out, nil
 }
 
+func (c *swapClientClient) GetServerHealth(ctx context.Context, in *ServerHealthRequest, opts ...grpc.CallOption) (*ServerHealthResponse, error) {
+	out := new(ServerHealthResponse)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapClient/GetServerHealth", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SwapClientServer is the server API for SwapClient service.
 type SwapClientServer interface {
 	//* loop: `out`
@@ -1287,9 +4339,52 @@ type SwapClientServer interface {
 	//*
 	//GetQuote returns a quote for a swap with the provided parameters.
 	GetLoopInQuote(context.Context, *QuoteRequest) (*QuoteResponse, error)
+	//* loop: `groups`
+	//ListSwapGroups returns all groups of swaps that were created from a single
+	//split swap request, along with their combined state and cost.
+	ListSwapGroups(context.Context, *ListSwapGroupsRequest) (*ListSwapGroupsResponse, error)
+	//* loop: `schedule`
+	//ScheduleSwap persists a loop out or loop in swap intent. The swap is
+	//initiated once the trigger conditions of the intent are met. Pending
+	//intents are evaluated on every block.
+	ScheduleSwap(context.Context, *ScheduleSwapRequest) (*SwapIntent, error)
+	//* loop: `intents`
+	//ListSwapIntents returns all swap intents, including the ones that already
+	//launched their swap, failed or were canceled.
+	ListSwapIntents(context.Context, *ListSwapIntentsRequest) (*ListSwapIntentsResponse, error)
+	//* loop: `cancelintent`
+	//CancelSwapIntent cancels a pending swap intent, so that its swap is never
+	//initiated.
+	CancelSwapIntent(context.Context, *CancelSwapIntentRequest) (*SwapIntent, error)
+	//* loop: `recurring`
+	//CreateRecurringSwap persists a loop out or loop in swap that is initiated
+	//once per interval. Every run is recorded, including the ones that failed.
+	CreateRecurringSwap(context.Context, *CreateRecurringSwapRequest) (*RecurringSwap, error)
+	//* loop: `recurring list`
+	//ListRecurringSwaps returns all recurring swaps together with their runs.
+	ListRecurringSwaps(context.Context, *ListRecurringSwapsRequest) (*ListRecurringSwapsResponse, error)
+	//* loop: `recurring cancel`
+	//CancelRecurringSwap cancels an active recurring swap, so that it doesn't
+	//initiate any further swaps.
+	CancelRecurringSwap(context.Context, *CancelRecurringSwapRequest) (*RecurringSwap, error)
+	//* loop: `dest register`
+	//RegisterDestDescriptor registers an xpub or output descriptor that Loop
+	//Out destination addresses are derived from when no destination is given.
+	RegisterDestDescriptor(context.Context, *RegisterDestDescriptorRequest) (*DestDescriptor, error)
+	//* loop: `dest`
+	//GetDestDescriptor returns the registered destination descriptor.
+	GetDestDescriptor(context.Context, *GetDestDescriptorRequest) (*DestDescriptor, error)
+	//* loop: `dest unregister`
+	//UnregisterDestDescriptor removes the registered destination descriptor.
+	//Loop Outs without a destination sweep to the lnd wallet again.
+	UnregisterDestDescriptor(context.Context, *UnregisterDestDescriptorRequest) (*UnregisterDestDescriptorResponse, error)
 	//*
 	//GetLsatTokens returns all LSAT tokens the daemon ever paid for.
 	GetLsatTokens(context.Context, *TokensRequest) (*TokensResponse, error)
+	//* loop: `health`
+	//GetServerHealth returns the connection health of all configured swap
+	//servers.
+	GetServerHealth(context.Context, *ServerHealthRequest) (*ServerHealthResponse, error)
 }
 
 func RegisterSwapClientServer(s *grpc.Server, srv SwapClientServer) {
@@ -1425,6 +4520,186 @@ func _SwapClient_GetLoopInQuote_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SwapClient_ListSwapGroups_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSwapGroupsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).ListSwapGroups(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/ListSwapGroups",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).ListSwapGroups(ctx, req.(*ListSwapGroupsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwapClient_ScheduleSwap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ScheduleSwapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).ScheduleSwap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/ScheduleSwap",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).ScheduleSwap(ctx, req.(*ScheduleSwapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwapClient_ListSwapIntents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSwapIntentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).ListSwapIntents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/ListSwapIntents",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).ListSwapIntents(ctx, req.(*ListSwapIntentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwapClient_CancelSwapIntent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelSwapIntentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).CancelSwapIntent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/CancelSwapIntent",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).CancelSwapIntent(ctx, req.(*CancelSwapIntentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwapClient_CreateRecurringSwap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateRecurringSwapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).CreateRecurringSwap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/CreateRecurringSwap",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).CreateRecurringSwap(ctx, req.(*CreateRecurringSwapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwapClient_ListRecurringSwaps_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRecurringSwapsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).ListRecurringSwaps(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/ListRecurringSwaps",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).ListRecurringSwaps(ctx, req.(*ListRecurringSwapsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwapClient_CancelRecurringSwap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRecurringSwapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).CancelRecurringSwap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/CancelRecurringSwap",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).CancelRecurringSwap(ctx, req.(*CancelRecurringSwapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwapClient_RegisterDestDescriptor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterDestDescriptorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).RegisterDestDescriptor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/RegisterDestDescriptor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).RegisterDestDescriptor(ctx, req.(*RegisterDestDescriptorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwapClient_GetDestDescriptor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDestDescriptorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).GetDestDescriptor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/GetDestDescriptor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).GetDestDescriptor(ctx, req.(*GetDestDescriptorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwapClient_UnregisterDestDescriptor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnregisterDestDescriptorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).UnregisterDestDescriptor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/UnregisterDestDescriptor",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).UnregisterDestDescriptor(ctx, req.(*UnregisterDestDescriptorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _SwapClient_GetLsatTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(TokensRequest)
 	if err := dec(in); err != nil {
@@ -1443,6 +4718,24 @@ func _SwapClient_GetLsatTokens_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SwapClient_GetServerHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServerHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapClientServer).GetServerHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapClient/GetServerHealth",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapClientServer).GetServerHealth(ctx, req.(*ServerHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 var _SwapClient_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "looprpc.SwapClient",
 	HandlerType: (*SwapClientServer)(nil),
@@ -1471,10 +4764,54 @@ var _SwapClient_serviceDesc = grpc.ServiceDesc{
 			MethodName: "GetLoopInQuote",
 			Handler:    _SwapClient_GetLoopInQuote_Handler,
 		},
+		{
+			MethodName: "ListSwapGroups",
+			Handler:    _SwapClient_ListSwapGroups_Handler,
+		},
+		{
+			MethodName: "ScheduleSwap",
+			Handler:    _SwapClient_ScheduleSwap_Handler,
+		},
+		{
+			MethodName: "ListSwapIntents",
+			Handler:    _SwapClient_ListSwapIntents_Handler,
+		},
+		{
+			MethodName: "CancelSwapIntent",
+			Handler:    _SwapClient_CancelSwapIntent_Handler,
+		},
+		{
+			MethodName: "CreateRecurringSwap",
+			Handler:    _SwapClient_CreateRecurringSwap_Handler,
+		},
+		{
+			MethodName: "ListRecurringSwaps",
+			Handler:    _SwapClient_ListRecurringSwaps_Handler,
+		},
+		{
+			MethodName: "CancelRecurringSwap",
+			Handler:    _SwapClient_CancelRecurringSwap_Handler,
+		},
+		{
+			MethodName: "RegisterDestDescriptor",
+			Handler:    _SwapClient_RegisterDestDescriptor_Handler,
+		},
+		{
+			MethodName: "GetDestDescriptor",
+			Handler:    _SwapClient_GetDestDescriptor_Handler,
+		},
+		{
+			MethodName: "UnregisterDestDescriptor",
+			Handler:    _SwapClient_UnregisterDestDescriptor_Handler,
+		},
 		{
 			MethodName: "GetLsatTokens",
 			Handler:    _SwapClient_GetLsatTokens_Handler,
 		},
+		{
+			MethodName: "GetServerHealth",
+			Handler:    _SwapClient_GetServerHealth_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
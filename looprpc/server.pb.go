@@ -23,15 +23,151 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
 
+// *
+// The protocol version that the client speaks. The server uses it to determine
+// which features of the swap protocol the client supports.
+type ProtocolVersion int32
+
+const (
+	/// The protocol that was in use before protocol versions were introduced.
+	ProtocolVersion_LEGACY ProtocolVersion = 0
+	/// The client supports the htlc script versions that are returned in swap
+	/// responses.
+	ProtocolVersion_HTLC_SCRIPT_VERSIONS ProtocolVersion = 1
+	/// The client supports htlc scripts with a cooperative refund path and
+	/// requests cooperative refunds for failed loop ins.
+	ProtocolVersion_COOPERATIVE_REFUND ProtocolVersion = 2
+)
+
+var ProtocolVersion_name = map[int32]string{
+	0: "LEGACY",
+	1: "HTLC_SCRIPT_VERSIONS",
+	2: "COOPERATIVE_REFUND",
+}
+
+var ProtocolVersion_value = map[string]int32{
+	"LEGACY":               0,
+	"HTLC_SCRIPT_VERSIONS": 1,
+	"COOPERATIVE_REFUND":   2,
+}
+
+func (x ProtocolVersion) String() string {
+	return proto.EnumName(ProtocolVersion_name, int32(x))
+}
+
+func (ProtocolVersion) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{0}
+}
+
+// *
+// The version of the htlc script of a swap.
+type HtlcScriptVersion int32
+
+const (
+	/// The original htlc script.
+	HtlcScriptVersion_HTLC_SCRIPT_V1 HtlcScriptVersion = 0
+	/// The htlc script with a path that the sender and receiver can spend
+	/// together before the cltv expiry.
+	HtlcScriptVersion_HTLC_SCRIPT_V2 HtlcScriptVersion = 1
+)
+
+var HtlcScriptVersion_name = map[int32]string{
+	0: "HTLC_SCRIPT_V1",
+	1: "HTLC_SCRIPT_V2",
+}
+
+var HtlcScriptVersion_value = map[string]int32{
+	"HTLC_SCRIPT_V1": 0,
+	"HTLC_SCRIPT_V2": 1,
+}
+
+func (x HtlcScriptVersion) String() string {
+	return proto.EnumName(HtlcScriptVersion_name, int32(x))
+}
+
+func (HtlcScriptVersion) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{1}
+}
+
+type HtlcType int32
+
+const (
+	/// A pay-to-witness-script-hash output nested in a p2sh output.
+	HtlcType_HTLC_NP2WSH HtlcType = 0
+	/// A native pay-to-witness-script-hash output.
+	HtlcType_HTLC_P2WSH HtlcType = 1
+)
+
+var HtlcType_name = map[int32]string{
+	0: "HTLC_NP2WSH",
+	1: "HTLC_P2WSH",
+}
+
+var HtlcType_value = map[string]int32{
+	"HTLC_NP2WSH": 0,
+	"HTLC_P2WSH":  1,
+}
+
+func (x HtlcType) String() string {
+	return proto.EnumName(HtlcType_name, int32(x))
+}
+
+func (HtlcType) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{2}
+}
+
+// *
+// The state of a swap on the server side.
+type ServerSwapState int32
+
+const (
+	/// The server accepted the swap and is going to follow up on it.
+	ServerSwapState_SERVER_SWAP_ACCEPTED ServerSwapState = 0
+	/// The server delays the publication of the loop out htlc, for example
+	/// to batch it until the swap publication deadline.
+	ServerSwapState_SERVER_SWAP_HTLC_DELAYED ServerSwapState = 1
+	/// The server published the loop out htlc.
+	ServerSwapState_SERVER_SWAP_HTLC_PUBLISHED ServerSwapState = 2
+	/// The server won't continue with the swap.
+	ServerSwapState_SERVER_SWAP_REJECTED ServerSwapState = 3
+)
+
+var ServerSwapState_name = map[int32]string{
+	0: "SERVER_SWAP_ACCEPTED",
+	1: "SERVER_SWAP_HTLC_DELAYED",
+	2: "SERVER_SWAP_HTLC_PUBLISHED",
+	3: "SERVER_SWAP_REJECTED",
+}
+
+var ServerSwapState_value = map[string]int32{
+	"SERVER_SWAP_ACCEPTED":       0,
+	"SERVER_SWAP_HTLC_DELAYED":   1,
+	"SERVER_SWAP_HTLC_PUBLISHED": 2,
+	"SERVER_SWAP_REJECTED":       3,
+}
+
+func (x ServerSwapState) String() string {
+	return proto.EnumName(ServerSwapState_name, int32(x))
+}
+
+func (ServerSwapState) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{3}
+}
+
 type ServerLoopOutRequest struct {
 	ReceiverKey []byte `protobuf:"bytes,1,opt,name=receiver_key,json=receiverKey,proto3" json:"receiver_key,omitempty"`
 	SwapHash    []byte `protobuf:"bytes,2,opt,name=swap_hash,json=swapHash,proto3" json:"swap_hash,omitempty"`
 	Amt         uint64 `protobuf:"varint,3,opt,name=amt,proto3" json:"amt,omitempty"`
 	/// The unix time in seconds we want the on-chain swap to be published by.
-	SwapPublicationDeadline int64    `protobuf:"varint,4,opt,name=swap_publication_deadline,json=swapPublicationDeadline,proto3" json:"swap_publication_deadline,omitempty"`
-	XXX_NoUnkeyedLiteral    struct{} `json:"-"`
-	XXX_unrecognized        []byte   `json:"-"`
-	XXX_sizecache           int32    `json:"-"`
+	SwapPublicationDeadline int64 `protobuf:"varint,4,opt,name=swap_publication_deadline,json=swapPublicationDeadline,proto3" json:"swap_publication_deadline,omitempty"`
+	/// The id of the quote that this swap is based on. If set, the server
+	/// must use the fees of that quote, or fail if the quote has expired.
+	QuoteId []byte `protobuf:"bytes,5,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+	/// The protocol version that the client speaks.
+	ProtocolVersion      ProtocolVersion `protobuf:"varint,6,opt,name=protocol_version,json=protocolVersion,proto3,enum=looprpc.ProtocolVersion" json:"protocol_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
 func (m *ServerLoopOutRequest) Reset()         { *m = ServerLoopOutRequest{} }
@@ -87,14 +223,30 @@ func (m *ServerLoopOutRequest) GetSwapPublicationDeadline() int64 {
 	return 0
 }
 
+func (m *ServerLoopOutRequest) GetQuoteId() []byte {
+	if m != nil {
+		return m.QuoteId
+	}
+	return nil
+}
+
+func (m *ServerLoopOutRequest) GetProtocolVersion() ProtocolVersion {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return ProtocolVersion_LEGACY
+}
+
 type ServerLoopOutResponse struct {
-	SwapInvoice          string   `protobuf:"bytes,1,opt,name=swap_invoice,json=swapInvoice,proto3" json:"swap_invoice,omitempty"`
-	PrepayInvoice        string   `protobuf:"bytes,2,opt,name=prepay_invoice,json=prepayInvoice,proto3" json:"prepay_invoice,omitempty"`
-	SenderKey            []byte   `protobuf:"bytes,3,opt,name=sender_key,json=senderKey,proto3" json:"sender_key,omitempty"`
-	Expiry               int32    `protobuf:"varint,4,opt,name=expiry,proto3" json:"expiry,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	SwapInvoice   string `protobuf:"bytes,1,opt,name=swap_invoice,json=swapInvoice,proto3" json:"swap_invoice,omitempty"`
+	PrepayInvoice string `protobuf:"bytes,2,opt,name=prepay_invoice,json=prepayInvoice,proto3" json:"prepay_invoice,omitempty"`
+	SenderKey     []byte `protobuf:"bytes,3,opt,name=sender_key,json=senderKey,proto3" json:"sender_key,omitempty"`
+	Expiry        int32  `protobuf:"varint,4,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	/// The version of the htlc script of the swap.
+	HtlcVersion          HtlcScriptVersion `protobuf:"varint,5,opt,name=htlc_version,json=htlcVersion,proto3,enum=looprpc.HtlcScriptVersion" json:"htlc_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *ServerLoopOutResponse) Reset()         { *m = ServerLoopOutResponse{} }
@@ -150,14 +302,23 @@ func (m *ServerLoopOutResponse) GetExpiry() int32 {
 	return 0
 }
 
+func (m *ServerLoopOutResponse) GetHtlcVersion() HtlcScriptVersion {
+	if m != nil {
+		return m.HtlcVersion
+	}
+	return HtlcScriptVersion_HTLC_SCRIPT_V1
+}
+
 type ServerLoopOutQuoteRequest struct {
 	/// The swap amount. If zero, a quote for a maximum amt swap will be given.
 	Amt uint64 `protobuf:"varint,1,opt,name=amt,proto3" json:"amt,omitempty"`
 	/// The unix time in seconds we want the on-chain swap to be published by.
-	SwapPublicationDeadline int64    `protobuf:"varint,2,opt,name=swap_publication_deadline,json=swapPublicationDeadline,proto3" json:"swap_publication_deadline,omitempty"`
-	XXX_NoUnkeyedLiteral    struct{} `json:"-"`
-	XXX_unrecognized        []byte   `json:"-"`
-	XXX_sizecache           int32    `json:"-"`
+	SwapPublicationDeadline int64 `protobuf:"varint,2,opt,name=swap_publication_deadline,json=swapPublicationDeadline,proto3" json:"swap_publication_deadline,omitempty"`
+	/// The protocol version that the client speaks.
+	ProtocolVersion      ProtocolVersion `protobuf:"varint,3,opt,name=protocol_version,json=protocolVersion,proto3,enum=looprpc.ProtocolVersion" json:"protocol_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
 func (m *ServerLoopOutQuoteRequest) Reset()         { *m = ServerLoopOutQuoteRequest{} }
@@ -199,16 +360,28 @@ func (m *ServerLoopOutQuoteRequest) GetSwapPublicationDeadline() int64 {
 	return 0
 }
 
+func (m *ServerLoopOutQuoteRequest) GetProtocolVersion() ProtocolVersion {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return ProtocolVersion_LEGACY
+}
+
 type ServerLoopOutQuote struct {
 	SwapPaymentDest string `protobuf:"bytes,1,opt,name=swap_payment_dest,json=swapPaymentDest,proto3" json:"swap_payment_dest,omitempty"`
 	/// The total estimated swap fee given the quote amt.
 	SwapFee int64 `protobuf:"varint,2,opt,name=swap_fee,json=swapFee,proto3" json:"swap_fee,omitempty"`
 	/// Deprecated, total swap fee given quote amt is calculated in swap_fee.
-	SwapFeeRate          int64    `protobuf:"varint,3,opt,name=swap_fee_rate,json=swapFeeRate,proto3" json:"swap_fee_rate,omitempty"` // Deprecated: Do not use.
-	PrepayAmt            uint64   `protobuf:"varint,4,opt,name=prepay_amt,json=prepayAmt,proto3" json:"prepay_amt,omitempty"`
-	MinSwapAmount        uint64   `protobuf:"varint,5,opt,name=min_swap_amount,json=minSwapAmount,proto3" json:"min_swap_amount,omitempty"` // Deprecated: Do not use.
-	MaxSwapAmount        uint64   `protobuf:"varint,6,opt,name=max_swap_amount,json=maxSwapAmount,proto3" json:"max_swap_amount,omitempty"` // Deprecated: Do not use.
-	CltvDelta            int32    `protobuf:"varint,7,opt,name=cltv_delta,json=cltvDelta,proto3" json:"cltv_delta,omitempty"`
+	SwapFeeRate   int64  `protobuf:"varint,3,opt,name=swap_fee_rate,json=swapFeeRate,proto3" json:"swap_fee_rate,omitempty"` // Deprecated: Do not use.
+	PrepayAmt     uint64 `protobuf:"varint,4,opt,name=prepay_amt,json=prepayAmt,proto3" json:"prepay_amt,omitempty"`
+	MinSwapAmount uint64 `protobuf:"varint,5,opt,name=min_swap_amount,json=minSwapAmount,proto3" json:"min_swap_amount,omitempty"` // Deprecated: Do not use.
+	MaxSwapAmount uint64 `protobuf:"varint,6,opt,name=max_swap_amount,json=maxSwapAmount,proto3" json:"max_swap_amount,omitempty"` // Deprecated: Do not use.
+	CltvDelta     int32  `protobuf:"varint,7,opt,name=cltv_delta,json=cltvDelta,proto3" json:"cltv_delta,omitempty"`
+	/// The id of this quote. It can be passed to NewLoopOutSwap to lock the
+	/// quoted fees.
+	QuoteId []byte `protobuf:"bytes,8,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+	/// The unix time in seconds until which the quote is valid.
+	QuoteExpiry          int64    `protobuf:"varint,9,opt,name=quote_expiry,json=quoteExpiry,proto3" json:"quote_expiry,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -291,10 +464,26 @@ func (m *ServerLoopOutQuote) GetCltvDelta() int32 {
 	return 0
 }
 
+func (m *ServerLoopOutQuote) GetQuoteId() []byte {
+	if m != nil {
+		return m.QuoteId
+	}
+	return nil
+}
+
+func (m *ServerLoopOutQuote) GetQuoteExpiry() int64 {
+	if m != nil {
+		return m.QuoteExpiry
+	}
+	return 0
+}
+
 type ServerLoopOutTermsRequest struct {
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	/// The protocol version that the client speaks.
+	ProtocolVersion      ProtocolVersion `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3,enum=looprpc.ProtocolVersion" json:"protocol_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
 func (m *ServerLoopOutTermsRequest) Reset()         { *m = ServerLoopOutTermsRequest{} }
@@ -322,6 +511,13 @@ func (m *ServerLoopOutTermsRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_ServerLoopOutTermsRequest proto.InternalMessageInfo
 
+func (m *ServerLoopOutTermsRequest) GetProtocolVersion() ProtocolVersion {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return ProtocolVersion_LEGACY
+}
+
 type ServerLoopOutTerms struct {
 	MinSwapAmount        uint64   `protobuf:"varint,1,opt,name=min_swap_amount,json=minSwapAmount,proto3" json:"min_swap_amount,omitempty"`
 	MaxSwapAmount        uint64   `protobuf:"varint,2,opt,name=max_swap_amount,json=maxSwapAmount,proto3" json:"max_swap_amount,omitempty"`
@@ -370,13 +566,20 @@ func (m *ServerLoopOutTerms) GetMaxSwapAmount() uint64 {
 }
 
 type ServerLoopInRequest struct {
-	SenderKey            []byte   `protobuf:"bytes,1,opt,name=sender_key,json=senderKey,proto3" json:"sender_key,omitempty"`
-	SwapHash             []byte   `protobuf:"bytes,2,opt,name=swap_hash,json=swapHash,proto3" json:"swap_hash,omitempty"`
-	Amt                  uint64   `protobuf:"varint,3,opt,name=amt,proto3" json:"amt,omitempty"`
-	SwapInvoice          string   `protobuf:"bytes,4,opt,name=swap_invoice,json=swapInvoice,proto3" json:"swap_invoice,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	SenderKey   []byte `protobuf:"bytes,1,opt,name=sender_key,json=senderKey,proto3" json:"sender_key,omitempty"`
+	SwapHash    []byte `protobuf:"bytes,2,opt,name=swap_hash,json=swapHash,proto3" json:"swap_hash,omitempty"`
+	Amt         uint64 `protobuf:"varint,3,opt,name=amt,proto3" json:"amt,omitempty"`
+	SwapInvoice string `protobuf:"bytes,4,opt,name=swap_invoice,json=swapInvoice,proto3" json:"swap_invoice,omitempty"`
+	/// The id of the quote that this swap is based on. If set, the server
+	/// must use the fees of that quote, or fail if the quote has expired.
+	QuoteId []byte `protobuf:"bytes,5,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+	/// The output type of the htlc that the client wants to publish.
+	HtlcType HtlcType `protobuf:"varint,6,opt,name=htlc_type,json=htlcType,proto3,enum=looprpc.HtlcType" json:"htlc_type,omitempty"`
+	/// The protocol version that the client speaks.
+	ProtocolVersion      ProtocolVersion `protobuf:"varint,7,opt,name=protocol_version,json=protocolVersion,proto3,enum=looprpc.ProtocolVersion" json:"protocol_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
 func (m *ServerLoopInRequest) Reset()         { *m = ServerLoopInRequest{} }
@@ -432,12 +635,38 @@ func (m *ServerLoopInRequest) GetSwapInvoice() string {
 	return ""
 }
 
+func (m *ServerLoopInRequest) GetQuoteId() []byte {
+	if m != nil {
+		return m.QuoteId
+	}
+	return nil
+}
+
+func (m *ServerLoopInRequest) GetHtlcType() HtlcType {
+	if m != nil {
+		return m.HtlcType
+	}
+	return HtlcType_HTLC_NP2WSH
+}
+
+func (m *ServerLoopInRequest) GetProtocolVersion() ProtocolVersion {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return ProtocolVersion_LEGACY
+}
+
 type ServerLoopInResponse struct {
-	ReceiverKey          []byte   `protobuf:"bytes,1,opt,name=receiver_key,json=receiverKey,proto3" json:"receiver_key,omitempty"`
-	Expiry               int32    `protobuf:"varint,2,opt,name=expiry,proto3" json:"expiry,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	ReceiverKey []byte `protobuf:"bytes,1,opt,name=receiver_key,json=receiverKey,proto3" json:"receiver_key,omitempty"`
+	Expiry      int32  `protobuf:"varint,2,opt,name=expiry,proto3" json:"expiry,omitempty"`
+	/// The output type of the htlc that the server watches. Servers that
+	/// don't support the requested type fall back to HTLC_NP2WSH.
+	HtlcType HtlcType `protobuf:"varint,3,opt,name=htlc_type,json=htlcType,proto3,enum=looprpc.HtlcType" json:"htlc_type,omitempty"`
+	/// The version of the htlc script of the swap.
+	HtlcVersion          HtlcScriptVersion `protobuf:"varint,4,opt,name=htlc_version,json=htlcVersion,proto3,enum=looprpc.HtlcScriptVersion" json:"htlc_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
+	XXX_unrecognized     []byte            `json:"-"`
+	XXX_sizecache        int32             `json:"-"`
 }
 
 func (m *ServerLoopInResponse) Reset()         { *m = ServerLoopInResponse{} }
@@ -479,12 +708,28 @@ func (m *ServerLoopInResponse) GetExpiry() int32 {
 	return 0
 }
 
+func (m *ServerLoopInResponse) GetHtlcType() HtlcType {
+	if m != nil {
+		return m.HtlcType
+	}
+	return HtlcType_HTLC_NP2WSH
+}
+
+func (m *ServerLoopInResponse) GetHtlcVersion() HtlcScriptVersion {
+	if m != nil {
+		return m.HtlcVersion
+	}
+	return HtlcScriptVersion_HTLC_SCRIPT_V1
+}
+
 type ServerLoopInQuoteRequest struct {
 	/// The swap amount. If zero, a quote for a maximum amt swap will be given.
-	Amt                  uint64   `protobuf:"varint,1,opt,name=amt,proto3" json:"amt,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	Amt uint64 `protobuf:"varint,1,opt,name=amt,proto3" json:"amt,omitempty"`
+	/// The protocol version that the client speaks.
+	ProtocolVersion      ProtocolVersion `protobuf:"varint,2,opt,name=protocol_version,json=protocolVersion,proto3,enum=looprpc.ProtocolVersion" json:"protocol_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
 func (m *ServerLoopInQuoteRequest) Reset()         { *m = ServerLoopInQuoteRequest{} }
@@ -519,12 +764,24 @@ func (m *ServerLoopInQuoteRequest) GetAmt() uint64 {
 	return 0
 }
 
+func (m *ServerLoopInQuoteRequest) GetProtocolVersion() ProtocolVersion {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return ProtocolVersion_LEGACY
+}
+
 type ServerLoopInQuoteResponse struct {
-	SwapFee              int64    `protobuf:"varint,1,opt,name=swap_fee,json=swapFee,proto3" json:"swap_fee,omitempty"`
-	SwapFeeRate          int64    `protobuf:"varint,2,opt,name=swap_fee_rate,json=swapFeeRate,proto3" json:"swap_fee_rate,omitempty"`       // Deprecated: Do not use.
-	MinSwapAmount        uint64   `protobuf:"varint,4,opt,name=min_swap_amount,json=minSwapAmount,proto3" json:"min_swap_amount,omitempty"` // Deprecated: Do not use.
-	MaxSwapAmount        uint64   `protobuf:"varint,5,opt,name=max_swap_amount,json=maxSwapAmount,proto3" json:"max_swap_amount,omitempty"` // Deprecated: Do not use.
-	CltvDelta            int32    `protobuf:"varint,6,opt,name=cltv_delta,json=cltvDelta,proto3" json:"cltv_delta,omitempty"`
+	SwapFee       int64  `protobuf:"varint,1,opt,name=swap_fee,json=swapFee,proto3" json:"swap_fee,omitempty"`
+	SwapFeeRate   int64  `protobuf:"varint,2,opt,name=swap_fee_rate,json=swapFeeRate,proto3" json:"swap_fee_rate,omitempty"`       // Deprecated: Do not use.
+	MinSwapAmount uint64 `protobuf:"varint,4,opt,name=min_swap_amount,json=minSwapAmount,proto3" json:"min_swap_amount,omitempty"` // Deprecated: Do not use.
+	MaxSwapAmount uint64 `protobuf:"varint,5,opt,name=max_swap_amount,json=maxSwapAmount,proto3" json:"max_swap_amount,omitempty"` // Deprecated: Do not use.
+	CltvDelta     int32  `protobuf:"varint,6,opt,name=cltv_delta,json=cltvDelta,proto3" json:"cltv_delta,omitempty"`
+	/// The id of this quote. It can be passed to NewLoopInSwap to lock the
+	/// quoted fees.
+	QuoteId []byte `protobuf:"bytes,7,opt,name=quote_id,json=quoteId,proto3" json:"quote_id,omitempty"`
+	/// The unix time in seconds until which the quote is valid.
+	QuoteExpiry          int64    `protobuf:"varint,8,opt,name=quote_expiry,json=quoteExpiry,proto3" json:"quote_expiry,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -593,10 +850,26 @@ func (m *ServerLoopInQuoteResponse) GetCltvDelta() int32 {
 	return 0
 }
 
+func (m *ServerLoopInQuoteResponse) GetQuoteId() []byte {
+	if m != nil {
+		return m.QuoteId
+	}
+	return nil
+}
+
+func (m *ServerLoopInQuoteResponse) GetQuoteExpiry() int64 {
+	if m != nil {
+		return m.QuoteExpiry
+	}
+	return 0
+}
+
 type ServerLoopInTermsRequest struct {
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	/// The protocol version that the client speaks.
+	ProtocolVersion      ProtocolVersion `protobuf:"varint,1,opt,name=protocol_version,json=protocolVersion,proto3,enum=looprpc.ProtocolVersion" json:"protocol_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
 }
 
 func (m *ServerLoopInTermsRequest) Reset()         { *m = ServerLoopInTermsRequest{} }
@@ -624,6 +897,13 @@ func (m *ServerLoopInTermsRequest) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_ServerLoopInTermsRequest proto.InternalMessageInfo
 
+func (m *ServerLoopInTermsRequest) GetProtocolVersion() ProtocolVersion {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return ProtocolVersion_LEGACY
+}
+
 type ServerLoopInTerms struct {
 	MinSwapAmount        uint64   `protobuf:"varint,1,opt,name=min_swap_amount,json=minSwapAmount,proto3" json:"min_swap_amount,omitempty"`
 	MaxSwapAmount        uint64   `protobuf:"varint,2,opt,name=max_swap_amount,json=maxSwapAmount,proto3" json:"max_swap_amount,omitempty"`
@@ -671,7 +951,377 @@ func (m *ServerLoopInTerms) GetMaxSwapAmount() uint64 {
 	return 0
 }
 
+type ServerCooperativeLoopInRefundRequest struct {
+	/// The hash of the loop in swap.
+	SwapHash []byte `protobuf:"bytes,1,opt,name=swap_hash,json=swapHash,proto3" json:"swap_hash,omitempty"`
+	/// The serialized refund transaction without witness. Its only input
+	/// spends an htlc of the swap.
+	RefundTx []byte `protobuf:"bytes,2,opt,name=refund_tx,json=refundTx,proto3" json:"refund_tx,omitempty"`
+	/// The value of the htlc output that the refund transaction spends.
+	HtlcValue int64 `protobuf:"varint,3,opt,name=htlc_value,json=htlcValue,proto3" json:"htlc_value,omitempty"`
+	/// The protocol version that the client speaks.
+	ProtocolVersion      ProtocolVersion `protobuf:"varint,4,opt,name=protocol_version,json=protocolVersion,proto3,enum=looprpc.ProtocolVersion" json:"protocol_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ServerCooperativeLoopInRefundRequest) Reset()         { *m = ServerCooperativeLoopInRefundRequest{} }
+func (m *ServerCooperativeLoopInRefundRequest) String() string { return proto.CompactTextString(m) }
+func (*ServerCooperativeLoopInRefundRequest) ProtoMessage()    {}
+func (*ServerCooperativeLoopInRefundRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{12}
+}
+
+func (m *ServerCooperativeLoopInRefundRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerCooperativeLoopInRefundRequest.Unmarshal(m, b)
+}
+func (m *ServerCooperativeLoopInRefundRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerCooperativeLoopInRefundRequest.Marshal(b, m, deterministic)
+}
+func (m *ServerCooperativeLoopInRefundRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerCooperativeLoopInRefundRequest.Merge(m, src)
+}
+func (m *ServerCooperativeLoopInRefundRequest) XXX_Size() int {
+	return xxx_messageInfo_ServerCooperativeLoopInRefundRequest.Size(m)
+}
+func (m *ServerCooperativeLoopInRefundRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerCooperativeLoopInRefundRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerCooperativeLoopInRefundRequest proto.InternalMessageInfo
+
+func (m *ServerCooperativeLoopInRefundRequest) GetSwapHash() []byte {
+	if m != nil {
+		return m.SwapHash
+	}
+	return nil
+}
+
+func (m *ServerCooperativeLoopInRefundRequest) GetRefundTx() []byte {
+	if m != nil {
+		return m.RefundTx
+	}
+	return nil
+}
+
+func (m *ServerCooperativeLoopInRefundRequest) GetHtlcValue() int64 {
+	if m != nil {
+		return m.HtlcValue
+	}
+	return 0
+}
+
+func (m *ServerCooperativeLoopInRefundRequest) GetProtocolVersion() ProtocolVersion {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return ProtocolVersion_LEGACY
+}
+
+type ServerCooperativeLoopInRefundResponse struct {
+	/// The DER encoded signature of the receiver key over the refund
+	/// transaction, without sighash flag. It commits to SIGHASH_ALL.
+	ReceiverSig          []byte   `protobuf:"bytes,1,opt,name=receiver_sig,json=receiverSig,proto3" json:"receiver_sig,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServerCooperativeLoopInRefundResponse) Reset()         { *m = ServerCooperativeLoopInRefundResponse{} }
+func (m *ServerCooperativeLoopInRefundResponse) String() string { return proto.CompactTextString(m) }
+func (*ServerCooperativeLoopInRefundResponse) ProtoMessage()    {}
+func (*ServerCooperativeLoopInRefundResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{13}
+}
+
+func (m *ServerCooperativeLoopInRefundResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerCooperativeLoopInRefundResponse.Unmarshal(m, b)
+}
+func (m *ServerCooperativeLoopInRefundResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerCooperativeLoopInRefundResponse.Marshal(b, m, deterministic)
+}
+func (m *ServerCooperativeLoopInRefundResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerCooperativeLoopInRefundResponse.Merge(m, src)
+}
+func (m *ServerCooperativeLoopInRefundResponse) XXX_Size() int {
+	return xxx_messageInfo_ServerCooperativeLoopInRefundResponse.Size(m)
+}
+func (m *ServerCooperativeLoopInRefundResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerCooperativeLoopInRefundResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerCooperativeLoopInRefundResponse proto.InternalMessageInfo
+
+func (m *ServerCooperativeLoopInRefundResponse) GetReceiverSig() []byte {
+	if m != nil {
+		return m.ReceiverSig
+	}
+	return nil
+}
+
+type ServerSubscribeUpdatesRequest struct {
+	/// The hashes of the swaps to receive status updates for. Terms updates
+	/// are sent regardless of the swaps.
+	SwapHashes [][]byte `protobuf:"bytes,1,rep,name=swap_hashes,json=swapHashes,proto3" json:"swap_hashes,omitempty"`
+	/// The protocol version that the client speaks.
+	ProtocolVersion      ProtocolVersion `protobuf:"varint,2,opt,name=protocol_version,json=protocolVersion,proto3,enum=looprpc.ProtocolVersion" json:"protocol_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *ServerSubscribeUpdatesRequest) Reset()         { *m = ServerSubscribeUpdatesRequest{} }
+func (m *ServerSubscribeUpdatesRequest) String() string { return proto.CompactTextString(m) }
+func (*ServerSubscribeUpdatesRequest) ProtoMessage()    {}
+func (*ServerSubscribeUpdatesRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{14}
+}
+
+func (m *ServerSubscribeUpdatesRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerSubscribeUpdatesRequest.Unmarshal(m, b)
+}
+func (m *ServerSubscribeUpdatesRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerSubscribeUpdatesRequest.Marshal(b, m, deterministic)
+}
+func (m *ServerSubscribeUpdatesRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerSubscribeUpdatesRequest.Merge(m, src)
+}
+func (m *ServerSubscribeUpdatesRequest) XXX_Size() int {
+	return xxx_messageInfo_ServerSubscribeUpdatesRequest.Size(m)
+}
+func (m *ServerSubscribeUpdatesRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerSubscribeUpdatesRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerSubscribeUpdatesRequest proto.InternalMessageInfo
+
+func (m *ServerSubscribeUpdatesRequest) GetSwapHashes() [][]byte {
+	if m != nil {
+		return m.SwapHashes
+	}
+	return nil
+}
+
+func (m *ServerSubscribeUpdatesRequest) GetProtocolVersion() ProtocolVersion {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return ProtocolVersion_LEGACY
+}
+
+type ServerUpdate struct {
+	// Types that are valid to be assigned to Update:
+	//	*ServerUpdate_SwapUpdate
+	//	*ServerUpdate_TermsUpdate
+	Update               isServerUpdate_Update `protobuf_oneof:"update"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
+}
+
+func (m *ServerUpdate) Reset()         { *m = ServerUpdate{} }
+func (m *ServerUpdate) String() string { return proto.CompactTextString(m) }
+func (*ServerUpdate) ProtoMessage()    {}
+func (*ServerUpdate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{15}
+}
+
+func (m *ServerUpdate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerUpdate.Unmarshal(m, b)
+}
+func (m *ServerUpdate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerUpdate.Marshal(b, m, deterministic)
+}
+func (m *ServerUpdate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerUpdate.Merge(m, src)
+}
+func (m *ServerUpdate) XXX_Size() int {
+	return xxx_messageInfo_ServerUpdate.Size(m)
+}
+func (m *ServerUpdate) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerUpdate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerUpdate proto.InternalMessageInfo
+
+type isServerUpdate_Update interface {
+	isServerUpdate_Update()
+}
+
+type ServerUpdate_SwapUpdate struct {
+	SwapUpdate *ServerSwapUpdate `protobuf:"bytes,1,opt,name=swap_update,json=swapUpdate,proto3,oneof"`
+}
+
+type ServerUpdate_TermsUpdate struct {
+	TermsUpdate *ServerTermsUpdate `protobuf:"bytes,2,opt,name=terms_update,json=termsUpdate,proto3,oneof"`
+}
+
+func (*ServerUpdate_SwapUpdate) isServerUpdate_Update() {}
+
+func (*ServerUpdate_TermsUpdate) isServerUpdate_Update() {}
+
+func (m *ServerUpdate) GetUpdate() isServerUpdate_Update {
+	if m != nil {
+		return m.Update
+	}
+	return nil
+}
+
+func (m *ServerUpdate) GetSwapUpdate() *ServerSwapUpdate {
+	if x, ok := m.GetUpdate().(*ServerUpdate_SwapUpdate); ok {
+		return x.SwapUpdate
+	}
+	return nil
+}
+
+func (m *ServerUpdate) GetTermsUpdate() *ServerTermsUpdate {
+	if x, ok := m.GetUpdate().(*ServerUpdate_TermsUpdate); ok {
+		return x.TermsUpdate
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*ServerUpdate) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*ServerUpdate_SwapUpdate)(nil),
+		(*ServerUpdate_TermsUpdate)(nil),
+	}
+}
+
+type ServerSwapUpdate struct {
+	/// The hash of the swap.
+	SwapHash []byte `protobuf:"bytes,1,opt,name=swap_hash,json=swapHash,proto3" json:"swap_hash,omitempty"`
+	/// The state of the swap on the server side.
+	State ServerSwapState `protobuf:"varint,2,opt,name=state,proto3,enum=looprpc.ServerSwapState" json:"state,omitempty"`
+	/// An optional human readable explanation of the update.
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	/// The unix time in seconds at which the server expects to publish the
+	/// htlc. Only set for SERVER_SWAP_HTLC_DELAYED.
+	ExpectedPublication  int64    `protobuf:"varint,4,opt,name=expected_publication,json=expectedPublication,proto3" json:"expected_publication,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServerSwapUpdate) Reset()         { *m = ServerSwapUpdate{} }
+func (m *ServerSwapUpdate) String() string { return proto.CompactTextString(m) }
+func (*ServerSwapUpdate) ProtoMessage()    {}
+func (*ServerSwapUpdate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{16}
+}
+
+func (m *ServerSwapUpdate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerSwapUpdate.Unmarshal(m, b)
+}
+func (m *ServerSwapUpdate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerSwapUpdate.Marshal(b, m, deterministic)
+}
+func (m *ServerSwapUpdate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerSwapUpdate.Merge(m, src)
+}
+func (m *ServerSwapUpdate) XXX_Size() int {
+	return xxx_messageInfo_ServerSwapUpdate.Size(m)
+}
+func (m *ServerSwapUpdate) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerSwapUpdate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerSwapUpdate proto.InternalMessageInfo
+
+func (m *ServerSwapUpdate) GetSwapHash() []byte {
+	if m != nil {
+		return m.SwapHash
+	}
+	return nil
+}
+
+func (m *ServerSwapUpdate) GetState() ServerSwapState {
+	if m != nil {
+		return m.State
+	}
+	return ServerSwapState_SERVER_SWAP_ACCEPTED
+}
+
+func (m *ServerSwapUpdate) GetReason() string {
+	if m != nil {
+		return m.Reason
+	}
+	return ""
+}
+
+func (m *ServerSwapUpdate) GetExpectedPublication() int64 {
+	if m != nil {
+		return m.ExpectedPublication
+	}
+	return 0
+}
+
+type ServerTermsUpdate struct {
+	/// The new loop out terms.
+	LoopOutTerms *ServerLoopOutTerms `protobuf:"bytes,1,opt,name=loop_out_terms,json=loopOutTerms,proto3" json:"loop_out_terms,omitempty"`
+	/// The new loop in terms.
+	LoopInTerms *ServerLoopInTerms `protobuf:"bytes,2,opt,name=loop_in_terms,json=loopInTerms,proto3" json:"loop_in_terms,omitempty"`
+	/// Whether the swap fees changed. Quotes that were handed out before
+	/// may no longer be honored.
+	FeesChanged          bool     `protobuf:"varint,3,opt,name=fees_changed,json=feesChanged,proto3" json:"fees_changed,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *ServerTermsUpdate) Reset()         { *m = ServerTermsUpdate{} }
+func (m *ServerTermsUpdate) String() string { return proto.CompactTextString(m) }
+func (*ServerTermsUpdate) ProtoMessage()    {}
+func (*ServerTermsUpdate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_ad098daeda4239f7, []int{17}
+}
+
+func (m *ServerTermsUpdate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_ServerTermsUpdate.Unmarshal(m, b)
+}
+func (m *ServerTermsUpdate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_ServerTermsUpdate.Marshal(b, m, deterministic)
+}
+func (m *ServerTermsUpdate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_ServerTermsUpdate.Merge(m, src)
+}
+func (m *ServerTermsUpdate) XXX_Size() int {
+	return xxx_messageInfo_ServerTermsUpdate.Size(m)
+}
+func (m *ServerTermsUpdate) XXX_DiscardUnknown() {
+	xxx_messageInfo_ServerTermsUpdate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_ServerTermsUpdate proto.InternalMessageInfo
+
+func (m *ServerTermsUpdate) GetLoopOutTerms() *ServerLoopOutTerms {
+	if m != nil {
+		return m.LoopOutTerms
+	}
+	return nil
+}
+
+func (m *ServerTermsUpdate) GetLoopInTerms() *ServerLoopInTerms {
+	if m != nil {
+		return m.LoopInTerms
+	}
+	return nil
+}
+
+func (m *ServerTermsUpdate) GetFeesChanged() bool {
+	if m != nil {
+		return m.FeesChanged
+	}
+	return false
+}
+
 func init() {
+	proto.RegisterEnum("looprpc.ProtocolVersion", ProtocolVersion_name, ProtocolVersion_value)
+	proto.RegisterEnum("looprpc.HtlcScriptVersion", HtlcScriptVersion_name, HtlcScriptVersion_value)
+	proto.RegisterEnum("looprpc.HtlcType", HtlcType_name, HtlcType_value)
+	proto.RegisterEnum("looprpc.ServerSwapState", ServerSwapState_name, ServerSwapState_value)
 	proto.RegisterType((*ServerLoopOutRequest)(nil), "looprpc.ServerLoopOutRequest")
 	proto.RegisterType((*ServerLoopOutResponse)(nil), "looprpc.ServerLoopOutResponse")
 	proto.RegisterType((*ServerLoopOutQuoteRequest)(nil), "looprpc.ServerLoopOutQuoteRequest")
@@ -684,55 +1334,103 @@ func init() {
 	proto.RegisterType((*ServerLoopInQuoteResponse)(nil), "looprpc.ServerLoopInQuoteResponse")
 	proto.RegisterType((*ServerLoopInTermsRequest)(nil), "looprpc.ServerLoopInTermsRequest")
 	proto.RegisterType((*ServerLoopInTerms)(nil), "looprpc.ServerLoopInTerms")
+	proto.RegisterType((*ServerCooperativeLoopInRefundRequest)(nil), "looprpc.ServerCooperativeLoopInRefundRequest")
+	proto.RegisterType((*ServerCooperativeLoopInRefundResponse)(nil), "looprpc.ServerCooperativeLoopInRefundResponse")
+	proto.RegisterType((*ServerSubscribeUpdatesRequest)(nil), "looprpc.ServerSubscribeUpdatesRequest")
+	proto.RegisterType((*ServerUpdate)(nil), "looprpc.ServerUpdate")
+	proto.RegisterType((*ServerSwapUpdate)(nil), "looprpc.ServerSwapUpdate")
+	proto.RegisterType((*ServerTermsUpdate)(nil), "looprpc.ServerTermsUpdate")
 }
 
 func init() { proto.RegisterFile("server.proto", fileDescriptor_ad098daeda4239f7) }
 
 var fileDescriptor_ad098daeda4239f7 = []byte{
-	// 677 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x55, 0xdd, 0x4e, 0xdb, 0x4c,
-	0x10, 0x95, 0x9d, 0x10, 0xbe, 0x0c, 0x09, 0x7c, 0x6c, 0xff, 0x4c, 0x20, 0x15, 0x58, 0x2a, 0x42,
-	0xa8, 0x02, 0xa9, 0xbd, 0xeb, 0x1d, 0x15, 0x42, 0x45, 0x45, 0xa5, 0x18, 0xee, 0xad, 0x25, 0x99,
-	0x82, 0xd5, 0x78, 0x77, 0x6b, 0x6f, 0x02, 0x79, 0x81, 0x3e, 0x43, 0x7b, 0xdf, 0x57, 0xea, 0x83,
-	0xf4, 0x0d, 0xaa, 0xfd, 0x31, 0xb1, 0x9d, 0x38, 0x80, 0xd4, 0x3b, 0xf6, 0xcc, 0x61, 0xf6, 0xcc,
-	0x99, 0xb3, 0x0e, 0xb4, 0x52, 0x4c, 0x46, 0x98, 0xec, 0x89, 0x84, 0x4b, 0x4e, 0x16, 0x07, 0x9c,
-	0x8b, 0x44, 0xf4, 0x3a, 0x1b, 0x57, 0x9c, 0x5f, 0x0d, 0x70, 0x9f, 0x8a, 0x68, 0x9f, 0x32, 0xc6,
-	0x25, 0x95, 0x11, 0x67, 0xa9, 0xa1, 0xf9, 0xbf, 0x1c, 0x78, 0x7a, 0xae, 0xff, 0xef, 0x84, 0x73,
-	0x71, 0x3a, 0x94, 0x01, 0x7e, 0x1b, 0x62, 0x2a, 0xc9, 0x16, 0xb4, 0x12, 0xec, 0x61, 0x34, 0xc2,
-	0x24, 0xfc, 0x8a, 0x63, 0xcf, 0xd9, 0x74, 0x76, 0x5a, 0xc1, 0x52, 0x86, 0x7d, 0xc4, 0x31, 0x59,
-	0x87, 0x66, 0x7a, 0x43, 0x45, 0x78, 0x4d, 0xd3, 0x6b, 0xcf, 0xd5, 0xf5, 0xff, 0x14, 0xf0, 0x81,
-	0xa6, 0xd7, 0xe4, 0x7f, 0xa8, 0xd1, 0x58, 0x7a, 0xb5, 0x4d, 0x67, 0xa7, 0x1e, 0xa8, 0x3f, 0xc9,
-	0x3b, 0x58, 0xd3, 0x74, 0x31, 0xbc, 0x1c, 0x44, 0x3d, 0xad, 0x22, 0xec, 0x23, 0xed, 0x0f, 0x22,
-	0x86, 0x5e, 0x7d, 0xd3, 0xd9, 0xa9, 0x05, 0x2f, 0x14, 0xe1, 0xf3, 0xa4, 0x7e, 0x68, 0xcb, 0xfe,
-	0x0f, 0x07, 0x9e, 0x95, 0x64, 0xa6, 0x82, 0xb3, 0x14, 0x95, 0x4e, 0xdd, 0x35, 0x62, 0x23, 0x1e,
-	0xf5, 0x50, 0xeb, 0x6c, 0x06, 0x4b, 0x0a, 0x3b, 0x36, 0x10, 0x79, 0x05, 0xcb, 0x22, 0x41, 0x41,
-	0xc7, 0x77, 0x24, 0x57, 0x93, 0xda, 0x06, 0xcd, 0x68, 0x5d, 0x80, 0x14, 0x59, 0xdf, 0xce, 0x5b,
-	0xd3, 0xf3, 0x34, 0x0d, 0xa2, 0xa6, 0x7d, 0x0e, 0x0d, 0xbc, 0x15, 0x51, 0x32, 0xd6, 0x5a, 0x17,
-	0x02, 0x7b, 0xf2, 0x23, 0x58, 0x2b, 0x28, 0x3b, 0x1b, 0x72, 0x89, 0x99, 0x8b, 0xd6, 0x05, 0xe7,
-	0x81, 0x2e, 0xb8, 0xf3, 0x5d, 0xf8, 0xe9, 0x02, 0x99, 0xbe, 0x8b, 0xec, 0xc2, 0xaa, 0x69, 0x49,
-	0xc7, 0x31, 0x32, 0x19, 0xf6, 0x31, 0x95, 0xd6, 0x87, 0x15, 0xdd, 0xca, 0xe0, 0x87, 0x4a, 0xd0,
-	0x1a, 0xe8, 0x15, 0x85, 0x5f, 0x30, 0xbb, 0x6d, 0x51, 0x9d, 0x8f, 0x10, 0xc9, 0x36, 0xb4, 0xb3,
-	0x52, 0x98, 0x50, 0x89, 0xda, 0x82, 0xda, 0x7b, 0xd7, 0x73, 0x8c, 0x9d, 0x47, 0x88, 0x01, 0x95,
-	0xda, 0x27, 0x6b, 0xa7, 0x1a, 0xad, 0xae, 0x47, 0x6b, 0x1a, 0xe4, 0x20, 0x96, 0x64, 0x17, 0x56,
-	0xe2, 0x88, 0x85, 0xba, 0x15, 0x8d, 0xf9, 0x90, 0x49, 0x6f, 0x41, 0x71, 0x74, 0xa3, 0x76, 0x1c,
-	0xb1, 0xf3, 0x1b, 0x2a, 0x0e, 0x74, 0x41, 0x73, 0xe9, 0x6d, 0x81, 0xdb, 0xc8, 0x71, 0xe9, 0x6d,
-	0x8e, 0xdb, 0x05, 0xe8, 0x0d, 0xe4, 0x28, 0xec, 0xe3, 0x40, 0x52, 0x6f, 0x51, 0xef, 0xa0, 0xa9,
-	0x90, 0x43, 0x05, 0xf8, 0xeb, 0xa5, 0x35, 0x5c, 0x60, 0x12, 0xa7, 0x76, 0x0d, 0x7e, 0xbf, 0xe4,
-	0x9b, 0x2e, 0x92, 0xed, 0x69, 0xa5, 0x66, 0x51, 0x25, 0x95, 0xdb, 0xd3, 0x2a, 0x5d, 0xcb, 0xcb,
-	0x2b, 0xf4, 0xbf, 0x3b, 0xf0, 0x64, 0x72, 0xcd, 0x31, 0xcb, 0x42, 0x50, 0x0c, 0x96, 0x53, 0x0e,
-	0xd6, 0x23, 0x9f, 0x51, 0x39, 0xf0, 0xf5, 0xa9, 0xc0, 0xfb, 0x67, 0xf9, 0x37, 0xad, 0x74, 0x4c,
-	0xde, 0xca, 0x7d, 0x6f, 0x7a, 0x92, 0x72, 0xb7, 0x90, 0xf2, 0xd7, 0xe0, 0xe5, 0x5b, 0xce, 0x0f,
-	0xb9, 0xff, 0xdb, 0xc9, 0x6f, 0xe3, 0x8e, 0x6e, 0x65, 0xe4, 0x33, 0xe8, 0xdc, 0x93, 0x41, 0x77,
-	0x76, 0x06, 0x67, 0x84, 0xac, 0xfe, 0x88, 0x90, 0x2d, 0x3c, 0x2c, 0x64, 0x8d, 0x72, 0xc8, 0x3a,
-	0x45, 0x17, 0x0a, 0x19, 0xeb, 0xc1, 0xea, 0x54, 0xed, 0x5f, 0x47, 0xec, 0xcd, 0x9f, 0x1a, 0x80,
-	0x3a, 0x9a, 0x9b, 0xc8, 0x29, 0xb4, 0x0a, 0x89, 0xf6, 0xf7, 0xec, 0x57, 0x7f, 0xaf, 0xf2, 0x2d,
-	0x74, 0xd6, 0xe7, 0x70, 0xc8, 0x29, 0x2c, 0x7f, 0xc2, 0x1b, 0x0b, 0xa9, 0x8b, 0x48, 0x77, 0x36,
-	0x3d, 0xeb, 0xf6, 0xb2, 0xaa, 0x6c, 0x77, 0x3d, 0x51, 0x68, 0xbe, 0x55, 0x15, 0x0a, 0xf3, 0x79,
-	0xaa, 0x52, 0x68, 0x1a, 0x9c, 0xc0, 0x52, 0xde, 0xe0, 0xad, 0x19, 0xdc, 0xe2, 0x62, 0x3a, 0x9d,
-	0x6a, 0x0a, 0x39, 0x81, 0xb6, 0x9d, 0xf7, 0x58, 0xaf, 0x83, 0x6c, 0xcc, 0x24, 0x67, 0xad, 0xba,
-	0x15, 0x55, 0x3b, 0xec, 0x45, 0xa6, 0xcd, 0x48, 0x9d, 0xad, 0xad, 0x30, 0xaa, 0x3f, 0x8f, 0x62,
-	0xba, 0x5e, 0x36, 0xf4, 0x2f, 0xf5, 0xdb, 0xbf, 0x01, 0x00, 0x00, 0xff, 0xff, 0xc8, 0x49, 0xdb,
-	0x27, 0xe0, 0x07, 0x00, 0x00,
+	// 1357 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x56, 0xcd, 0x72, 0xdb, 0x46,
+	0x12, 0xd6, 0x80, 0x14, 0x45, 0x36, 0x29, 0x09, 0x1a, 0xcb, 0x36, 0x44, 0x59, 0x5e, 0x99, 0xb5,
+	0x56, 0xa9, 0xb4, 0xb5, 0xf2, 0x5a, 0x7b, 0xcb, 0x6f, 0xd1, 0x24, 0x1c, 0xd1, 0x61, 0x89, 0x0c,
+	0x40, 0xcb, 0xe5, 0x13, 0x02, 0x81, 0x63, 0x09, 0x15, 0x12, 0x80, 0x81, 0xa1, 0x44, 0x9e, 0x93,
+	0x3c, 0x41, 0xaa, 0x92, 0x77, 0xc8, 0x21, 0xf7, 0xdc, 0x7c, 0xc8, 0x3b, 0xe4, 0x75, 0x52, 0x98,
+	0x19, 0x90, 0x00, 0x08, 0x51, 0x66, 0x95, 0x6f, 0x98, 0xee, 0x9e, 0xfe, 0xf9, 0xba, 0xfb, 0x1b,
+	0x40, 0x25, 0x20, 0xfe, 0x35, 0xf1, 0x8f, 0x3d, 0xdf, 0xa5, 0x2e, 0x5e, 0x1b, 0xb8, 0xae, 0xe7,
+	0x7b, 0x56, 0xf5, 0xd1, 0xa5, 0xeb, 0x5e, 0x0e, 0xc8, 0x33, 0xd3, 0xb3, 0x9f, 0x99, 0x8e, 0xe3,
+	0x52, 0x93, 0xda, 0xae, 0x13, 0x70, 0xb3, 0xda, 0x4f, 0x12, 0x6c, 0xeb, 0xec, 0x5e, 0xdb, 0x75,
+	0xbd, 0xce, 0x88, 0x6a, 0xe4, 0xfd, 0x88, 0x04, 0x14, 0x3f, 0x81, 0x8a, 0x4f, 0x2c, 0x62, 0x5f,
+	0x13, 0xdf, 0xf8, 0x81, 0x4c, 0x14, 0xb4, 0x8f, 0x0e, 0x2b, 0x5a, 0x39, 0x92, 0x7d, 0x4b, 0x26,
+	0x78, 0x17, 0x4a, 0xc1, 0x8d, 0xe9, 0x19, 0x57, 0x66, 0x70, 0xa5, 0x48, 0x4c, 0x5f, 0x0c, 0x05,
+	0xa7, 0x66, 0x70, 0x85, 0x65, 0xc8, 0x99, 0x43, 0xaa, 0xe4, 0xf6, 0xd1, 0x61, 0x5e, 0x0b, 0x3f,
+	0xf1, 0x67, 0xb0, 0xc3, 0xcc, 0xbd, 0xd1, 0xc5, 0xc0, 0xb6, 0x58, 0x16, 0x46, 0x9f, 0x98, 0xfd,
+	0x81, 0xed, 0x10, 0x25, 0xbf, 0x8f, 0x0e, 0x73, 0xda, 0xc3, 0xd0, 0xa0, 0x3b, 0xd3, 0x37, 0x85,
+	0x1a, 0xef, 0x40, 0xf1, 0xfd, 0xc8, 0xa5, 0xc4, 0xb0, 0xfb, 0xca, 0x2a, 0x8b, 0xb4, 0xc6, 0xce,
+	0xad, 0x3e, 0x6e, 0x80, 0xcc, 0x4a, 0xb1, 0xdc, 0x81, 0x71, 0x4d, 0xfc, 0xc0, 0x76, 0x1d, 0xa5,
+	0xb0, 0x8f, 0x0e, 0x37, 0x4e, 0x94, 0x63, 0x81, 0xc1, 0x71, 0x57, 0x18, 0x9c, 0x73, 0xbd, 0xb6,
+	0xe9, 0x25, 0x05, 0xb5, 0xbf, 0x11, 0xdc, 0x4f, 0xc1, 0x10, 0x78, 0xae, 0x13, 0x90, 0x10, 0x07,
+	0x96, 0xb5, 0xed, 0x5c, 0xbb, 0xb6, 0x45, 0x18, 0x0e, 0x25, 0xad, 0x1c, 0xca, 0x5a, 0x5c, 0x84,
+	0x9f, 0xc2, 0x86, 0xe7, 0x13, 0xcf, 0x9c, 0x4c, 0x8d, 0x24, 0x66, 0xb4, 0xce, 0xa5, 0x91, 0xd9,
+	0x1e, 0x40, 0x40, 0x9c, 0xbe, 0xc0, 0x33, 0xc7, 0xaa, 0x28, 0x71, 0x49, 0x88, 0xe6, 0x03, 0x28,
+	0x90, 0xb1, 0x67, 0xfb, 0x13, 0x86, 0xc5, 0xaa, 0x26, 0x4e, 0xf8, 0x4b, 0xa8, 0x5c, 0xd1, 0x81,
+	0x35, 0xad, 0x6d, 0x95, 0xd5, 0x56, 0x9d, 0xd6, 0x76, 0x4a, 0x07, 0x96, 0x6e, 0xf9, 0xb6, 0x47,
+	0xa3, 0xea, 0xca, 0xa1, 0x7d, 0x54, 0xd9, 0x1f, 0x08, 0x76, 0x12, 0x95, 0x7d, 0x17, 0xe2, 0x16,
+	0x75, 0x59, 0x74, 0x09, 0x7d, 0x64, 0x97, 0xa4, 0xc5, 0x5d, 0xca, 0x6a, 0x45, 0x6e, 0xe9, 0x56,
+	0x48, 0x80, 0xe7, 0x13, 0xc6, 0x47, 0xb0, 0xc5, 0xf3, 0x32, 0x27, 0x43, 0xe2, 0x50, 0xa3, 0x4f,
+	0x02, 0x2a, 0x9a, 0xb1, 0xc9, 0xf2, 0xe1, 0xf2, 0x66, 0x58, 0xd5, 0x0e, 0xb0, 0x39, 0x34, 0xde,
+	0x91, 0x28, 0xe5, 0xb5, 0xf0, 0xfc, 0x92, 0x10, 0x7c, 0x00, 0xeb, 0x91, 0xca, 0xf0, 0x4d, 0x4a,
+	0x58, 0x7e, 0xb9, 0x17, 0x92, 0x82, 0x78, 0x4f, 0x5f, 0x12, 0xa2, 0x99, 0x94, 0x35, 0x4b, 0xf4,
+	0x34, 0xc4, 0x27, 0xcf, 0xf0, 0x29, 0x71, 0x49, 0x7d, 0x48, 0xf1, 0x11, 0x6c, 0x0e, 0x6d, 0xc7,
+	0x60, 0xae, 0xcc, 0xa1, 0x3b, 0x72, 0x28, 0xeb, 0x4b, 0x9e, 0x39, 0x5a, 0x1f, 0xda, 0x8e, 0x7e,
+	0x63, 0x7a, 0x75, 0xa6, 0x60, 0xb6, 0xe6, 0x38, 0x61, 0x5b, 0x88, 0xd9, 0x9a, 0xe3, 0x98, 0xed,
+	0x1e, 0x80, 0x35, 0xa0, 0xd7, 0x46, 0x9f, 0x0c, 0xa8, 0xa9, 0xac, 0xb1, 0x41, 0x28, 0x85, 0x92,
+	0x66, 0x28, 0x48, 0xac, 0x41, 0x31, 0xb9, 0x06, 0x4f, 0xa0, 0xc2, 0x55, 0x62, 0x88, 0x4a, 0xac,
+	0xee, 0x32, 0x93, 0xa9, 0x4c, 0x54, 0xfb, 0x3e, 0x35, 0x09, 0x3d, 0xe2, 0x0f, 0x83, 0x68, 0x12,
+	0xb2, 0x7a, 0x87, 0x96, 0xed, 0x5d, 0x1f, 0xf0, 0x7c, 0x04, 0x7c, 0x30, 0x0f, 0x16, 0x1f, 0xb8,
+	0x14, 0x50, 0x07, 0xf3, 0x40, 0x49, 0xc2, 0x2e, 0x0e, 0x52, 0xed, 0x57, 0x09, 0xee, 0xcd, 0xc2,
+	0xb4, 0x9c, 0xa8, 0x84, 0xe4, 0x82, 0xa1, 0xf4, 0x82, 0x2d, 0x49, 0x57, 0xe9, 0xc5, 0xcf, 0xcf,
+	0x2f, 0xfe, 0x02, 0x56, 0x3a, 0x86, 0x12, 0xdb, 0x5a, 0x3a, 0xf1, 0x88, 0xa0, 0xa3, 0xad, 0xc4,
+	0xca, 0xf6, 0x26, 0x1e, 0xd1, 0x8a, 0x57, 0xe2, 0x2b, 0x13, 0xfe, 0xb5, 0x65, 0xe1, 0xff, 0x80,
+	0x60, 0x3b, 0x09, 0xcc, 0x8c, 0xc4, 0xee, 0x22, 0xf3, 0x19, 0xfd, 0x48, 0x09, 0xfa, 0x49, 0x14,
+	0x92, 0xbb, 0xbb, 0x90, 0x34, 0x5d, 0xe5, 0x97, 0xa3, 0xab, 0xf7, 0xa0, 0xc4, 0x2b, 0xb8, 0x83,
+	0xac, 0xb2, 0x50, 0x93, 0x96, 0x45, 0xed, 0x17, 0x09, 0x76, 0x32, 0x62, 0x0a, 0xe8, 0xe2, 0x5c,
+	0x82, 0xee, 0xe0, 0x12, 0x29, 0x9b, 0x4b, 0x32, 0xc8, 0x22, 0xbf, 0x04, 0x59, 0xac, 0x7e, 0x1c,
+	0x59, 0x14, 0x16, 0x91, 0xc5, 0xda, 0x62, 0xb2, 0x28, 0xce, 0x93, 0x85, 0x91, 0x6c, 0xc4, 0xa7,
+	0xe7, 0x0a, 0x0b, 0xb6, 0xe6, 0x02, 0x7c, 0x72, 0xaa, 0xf8, 0x0b, 0xc1, 0xbf, 0x79, 0x94, 0x86,
+	0xeb, 0x7a, 0xc4, 0x37, 0xa9, 0x7d, 0x4d, 0xa2, 0xe5, 0x78, 0x37, 0x72, 0xfa, 0x51, 0x49, 0x09,
+	0x72, 0x40, 0x29, 0x72, 0xd8, 0x85, 0x92, 0xcf, 0xac, 0x0d, 0x3a, 0x8e, 0x98, 0x83, 0x0b, 0x7a,
+	0xe3, 0xb0, 0x0b, 0x7c, 0xe0, 0xcd, 0xc1, 0x48, 0x3c, 0x27, 0x1a, 0x5b, 0x99, 0xf3, 0x50, 0x90,
+	0x89, 0x55, 0x7e, 0x59, 0xac, 0x5e, 0xc1, 0xd3, 0x3b, 0xaa, 0xc8, 0x58, 0xf4, 0xc0, 0xbe, 0x4c,
+	0x2f, 0xba, 0x6e, 0x5f, 0xd6, 0x7e, 0x46, 0xb0, 0xc7, 0x9d, 0xe9, 0xa3, 0x8b, 0xc0, 0xf2, 0xed,
+	0x0b, 0xf2, 0xda, 0xeb, 0x9b, 0x94, 0x4c, 0xdb, 0xfb, 0x2f, 0x28, 0x4f, 0xb1, 0x20, 0x81, 0x82,
+	0xf6, 0x73, 0x87, 0x15, 0x0d, 0x22, 0x34, 0x48, 0xf0, 0x69, 0xd6, 0xee, 0x37, 0x04, 0x15, 0x9e,
+	0x07, 0x0f, 0x8f, 0xbf, 0x10, 0x61, 0x47, 0xec, 0xc8, 0x52, 0x2f, 0x9f, 0xec, 0x4c, 0x1d, 0x8a,
+	0x9c, 0x6f, 0x4c, 0x8f, 0xdb, 0x9f, 0xae, 0xf0, 0x9c, 0xc4, 0xed, 0xaf, 0xa1, 0x42, 0xc3, 0x11,
+	0x8a, 0xae, 0x4b, 0xec, 0x7a, 0x35, 0x75, 0x9d, 0x4d, 0xd9, 0xf4, 0x7e, 0x99, 0xce, 0x8e, 0x2f,
+	0x8a, 0x50, 0xe0, 0x57, 0x6b, 0xbf, 0x23, 0x90, 0xd3, 0xd1, 0x16, 0x0f, 0xc8, 0x31, 0xac, 0x06,
+	0x34, 0x8a, 0x1a, 0x47, 0x61, 0xe6, 0x46, 0x0f, 0xf5, 0x1a, 0x37, 0x0b, 0xc9, 0xd6, 0x27, 0x66,
+	0x20, 0x7e, 0x8f, 0x4a, 0x9a, 0x38, 0xe1, 0xe7, 0xb0, 0x4d, 0xc6, 0x1e, 0xb1, 0x28, 0xe9, 0xc7,
+	0x7f, 0xc0, 0xc4, 0xdf, 0xf1, 0xbd, 0x48, 0x17, 0xfb, 0xf7, 0xaa, 0xfd, 0x89, 0x60, 0x6b, 0xae,
+	0x36, 0x5c, 0x87, 0x8d, 0x30, 0x05, 0xc3, 0x1d, 0x51, 0x83, 0x15, 0x29, 0xe0, 0xdc, 0x4d, 0x65,
+	0x96, 0xf8, 0x13, 0xa8, 0x0c, 0x62, 0x27, 0xfc, 0x15, 0xac, 0x33, 0x17, 0xb6, 0x23, 0x3c, 0x64,
+	0x23, 0x1a, 0xa7, 0x87, 0xf2, 0x60, 0x76, 0x08, 0x47, 0xf1, 0x1d, 0x21, 0x81, 0x61, 0x5d, 0x99,
+	0xce, 0x25, 0xe9, 0xb3, 0x4a, 0x8b, 0x5a, 0x39, 0x94, 0x35, 0xb8, 0xe8, 0xa8, 0x03, 0x9b, 0xa9,
+	0x31, 0xc1, 0x00, 0x85, 0xb6, 0xfa, 0x4d, 0xbd, 0xf1, 0x56, 0x5e, 0xc1, 0x0a, 0x6c, 0x9f, 0xf6,
+	0xda, 0x0d, 0x43, 0x6f, 0x68, 0xad, 0x6e, 0xcf, 0x38, 0x57, 0x35, 0xbd, 0xd5, 0x39, 0xd3, 0x65,
+	0x84, 0x1f, 0x00, 0x6e, 0x74, 0x3a, 0x5d, 0x55, 0xab, 0xf7, 0x5a, 0xe7, 0xaa, 0xa1, 0xa9, 0x2f,
+	0x5f, 0x9f, 0x35, 0x65, 0xe9, 0xe8, 0x73, 0xd8, 0x9a, 0x7b, 0x5f, 0x30, 0x86, 0x8d, 0x84, 0x9b,
+	0xe7, 0xf2, 0xca, 0x9c, 0xec, 0x44, 0x46, 0x47, 0xff, 0x81, 0x62, 0xf4, 0x9e, 0xe1, 0x4d, 0x28,
+	0x33, 0xfd, 0x59, 0xf7, 0xe4, 0x8d, 0x7e, 0x2a, 0xaf, 0xe0, 0x0d, 0x00, 0x26, 0xe0, 0x67, 0x74,
+	0xf4, 0x23, 0x82, 0xcd, 0x54, 0x73, 0xc3, 0x7c, 0x75, 0x55, 0x3b, 0x57, 0x35, 0x43, 0x7f, 0x53,
+	0xef, 0x1a, 0xf5, 0x46, 0x43, 0xed, 0xf6, 0xd4, 0xa6, 0xbc, 0x82, 0x1f, 0x81, 0x12, 0xd7, 0x30,
+	0x4f, 0x4d, 0xb5, 0x5d, 0x7f, 0xab, 0x36, 0x65, 0x84, 0x1f, 0x43, 0x75, 0x4e, 0xdb, 0x7d, 0xfd,
+	0xa2, 0xdd, 0xd2, 0x4f, 0xd5, 0xa6, 0x2c, 0xa5, 0xfd, 0x6a, 0xea, 0x2b, 0xb5, 0x11, 0xfa, 0xcd,
+	0x9d, 0x7c, 0x58, 0x05, 0x60, 0xf1, 0x59, 0x26, 0xb8, 0x03, 0x95, 0xc4, 0x8f, 0x57, 0x6d, 0x51,
+	0xb7, 0xf9, 0xb2, 0x57, 0x17, 0x4d, 0x04, 0xee, 0xc0, 0xc6, 0x19, 0xb9, 0x11, 0xa2, 0x30, 0x10,
+	0xde, 0xcb, 0x36, 0x8f, 0xbc, 0x3d, 0xbe, 0x4d, 0x2d, 0xf8, 0x69, 0x96, 0x21, 0xff, 0xab, 0xbf,
+	0x25, 0xc3, 0xf8, 0xb3, 0x5f, 0xdd, 0x5d, 0x60, 0x83, 0xdb, 0x50, 0x8e, 0xbf, 0x1f, 0x4f, 0x16,
+	0x4c, 0xa7, 0x70, 0xb7, 0x60, 0x80, 0x71, 0x1b, 0xd6, 0x45, 0xbd, 0x2d, 0xf6, 0xda, 0xe0, 0x47,
+	0x99, 0xc6, 0x91, 0xab, 0xbd, 0x5b, 0xb4, 0xa2, 0xd8, 0x5e, 0x94, 0x1b, 0x4f, 0x35, 0x3b, 0xb7,
+	0x44, 0xa9, 0xb5, 0x45, 0x26, 0xc2, 0xeb, 0x18, 0x1e, 0xde, 0xf2, 0x0a, 0xe0, 0xff, 0xa6, 0xae,
+	0x2f, 0x7e, 0xf3, 0xaa, 0xc7, 0x1f, 0x6b, 0x2e, 0x22, 0xeb, 0x20, 0xa7, 0x9f, 0x0c, 0x7c, 0x90,
+	0xa6, 0xba, 0xec, 0x37, 0xa5, 0x7a, 0x3f, 0x65, 0xc7, 0xd5, 0xff, 0x43, 0x17, 0x05, 0xf6, 0x2e,
+	0xfc, 0xff, 0x9f, 0x01, 0x00, 0xf4, 0xb3, 0x19, 0xfb, 0xbe, 0x10, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -753,6 +1451,16 @@ type SwapServerClient interface {
 	LoopInTerms(ctx context.Context, in *ServerLoopInTermsRequest, opts ...grpc.CallOption) (*ServerLoopInTerms, error)
 	NewLoopInSwap(ctx context.Context, in *ServerLoopInRequest, opts ...grpc.CallOption) (*ServerLoopInResponse, error)
 	LoopInQuote(ctx context.Context, in *ServerLoopInQuoteRequest, opts ...grpc.CallOption) (*ServerLoopInQuoteResponse, error)
+	//*
+	//CooperativeLoopInRefund asks the server to co-sign a transaction that
+	//refunds a loop in htlc before its cltv expiry. The server only signs if
+	//the swap failed off-chain and the htlc script version has a cooperative
+	//refund path.
+	CooperativeLoopInRefund(ctx context.Context, in *ServerCooperativeLoopInRefundRequest, opts ...grpc.CallOption) (*ServerCooperativeLoopInRefundResponse, error)
+	//*
+	//SubscribeUpdates opens a stream on which the server pushes status updates
+	//of the given swaps and changes of its terms and fees.
+	SubscribeUpdates(ctx context.Context, in *ServerSubscribeUpdatesRequest, opts ...grpc.CallOption) (SwapServer_SubscribeUpdatesClient, error)
 }
 
 type swapServerClient struct {
@@ -817,6 +1525,47 @@ func (c *swapServerClient) LoopInQuote(ctx context.Context, in *ServerLoopInQuot
 	return out, nil
 }
 
+func (c *swapServerClient) CooperativeLoopInRefund(ctx context.Context, in *ServerCooperativeLoopInRefundRequest, opts ...grpc.CallOption) (*ServerCooperativeLoopInRefundResponse, error) {
+	out := new(ServerCooperativeLoopInRefundResponse)
+	err := c.cc.Invoke(ctx, "/looprpc.SwapServer/CooperativeLoopInRefund", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *swapServerClient) SubscribeUpdates(ctx context.Context, in *ServerSubscribeUpdatesRequest, opts ...grpc.CallOption) (SwapServer_SubscribeUpdatesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_SwapServer_serviceDesc.Streams[0], "/looprpc.SwapServer/SubscribeUpdates", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &swapServerSubscribeUpdatesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type SwapServer_SubscribeUpdatesClient interface {
+	Recv() (*ServerUpdate, error)
+	grpc.ClientStream
+}
+
+type swapServerSubscribeUpdatesClient struct {
+	grpc.ClientStream
+}
+
+func (x *swapServerSubscribeUpdatesClient) Recv() (*ServerUpdate, error) {
+	m := new(ServerUpdate)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // SwapServerServer is the server API for SwapServer service.
 type SwapServerServer interface {
 	LoopOutTerms(context.Context, *ServerLoopOutTermsRequest) (*ServerLoopOutTerms, error)
@@ -825,6 +1574,16 @@ type SwapServerServer interface {
 	LoopInTerms(context.Context, *ServerLoopInTermsRequest) (*ServerLoopInTerms, error)
 	NewLoopInSwap(context.Context, *ServerLoopInRequest) (*ServerLoopInResponse, error)
 	LoopInQuote(context.Context, *ServerLoopInQuoteRequest) (*ServerLoopInQuoteResponse, error)
+	//*
+	//CooperativeLoopInRefund asks the server to co-sign a transaction that
+	//refunds a loop in htlc before its cltv expiry. The server only signs if
+	//the swap failed off-chain and the htlc script version has a cooperative
+	//refund path.
+	CooperativeLoopInRefund(context.Context, *ServerCooperativeLoopInRefundRequest) (*ServerCooperativeLoopInRefundResponse, error)
+	//*
+	//SubscribeUpdates opens a stream on which the server pushes status updates
+	//of the given swaps and changes of its terms and fees.
+	SubscribeUpdates(*ServerSubscribeUpdatesRequest, SwapServer_SubscribeUpdatesServer) error
 }
 
 func RegisterSwapServerServer(s *grpc.Server, srv SwapServerServer) {
@@ -939,6 +1698,45 @@ func _SwapServer_LoopInQuote_Handler(srv interface{}, ctx context.Context, dec f
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SwapServer_CooperativeLoopInRefund_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ServerCooperativeLoopInRefundRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SwapServerServer).CooperativeLoopInRefund(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/looprpc.SwapServer/CooperativeLoopInRefund",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SwapServerServer).CooperativeLoopInRefund(ctx, req.(*ServerCooperativeLoopInRefundRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SwapServer_SubscribeUpdates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ServerSubscribeUpdatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SwapServerServer).SubscribeUpdates(m, &swapServerSubscribeUpdatesServer{stream})
+}
+
+type SwapServer_SubscribeUpdatesServer interface {
+	Send(*ServerUpdate) error
+	grpc.ServerStream
+}
+
+type swapServerSubscribeUpdatesServer struct {
+	grpc.ServerStream
+}
+
+func (x *swapServerSubscribeUpdatesServer) Send(m *ServerUpdate) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _SwapServer_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "looprpc.SwapServer",
 	HandlerType: (*SwapServerServer)(nil),
@@ -967,7 +1765,17 @@ var _SwapServer_serviceDesc = grpc.ServiceDesc{
 			MethodName: "LoopInQuote",
 			Handler:    _SwapServer_LoopInQuote_Handler,
 		},
+		{
+			MethodName: "CooperativeLoopInRefund",
+			Handler:    _SwapServer_CooperativeLoopInRefund_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeUpdates",
+			Handler:       _SwapServer_SubscribeUpdates_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "server.proto",
 }
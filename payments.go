@@ -0,0 +1,432 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/lndclient"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/zpay32"
+)
+
+const (
+	// paymentTimeout is the maximum time that lnd may spend on finding a
+	// route for an off-chain swap payment.
+	paymentTimeout = time.Minute
+)
+
+var (
+	// DefaultPaymentRetryPolicy is the retry policy that is used for the
+	// off-chain payments of loop out swaps by default.
+	DefaultPaymentRetryPolicy = PaymentRetryPolicy{
+		MaxAttempts: 3,
+		Backoff:     10 * time.Second,
+		MaxBackoff:  2 * time.Minute,
+		ExpiryDelta: 10,
+	}
+)
+
+// PaymentRetryPolicy describes how failed off-chain payments of a loop out
+// swap are retried. Every attempt uses the routing fee limit of the swap
+// contract. Retries that don't use a channel selected by the user are sent
+// over a different outgoing channel each time.
+type PaymentRetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts for each payment. A
+	// value of one or less disables retries.
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry. The delay is doubled
+	// for every following retry.
+	Backoff time.Duration
+
+	// MaxBackoff caps the delay between two attempts. Zero means no cap.
+	MaxBackoff time.Duration
+
+	// ExpiryDelta is the minimum number of blocks that must be left until
+	// the latest preimage reveal height of the swap for a retry to be
+	// started.
+	ExpiryDelta int32
+}
+
+// paymentResult is the outcome of an off-chain payment of a swap.
+type paymentResult struct {
+	// err is set if the payment failed.
+	err error
+
+	// failure is the reason why the payment failed. It is only set if lnd
+	// reported a final failed payment state.
+	failure loopdb.FailureReason
+
+	// paidAmt is the amount that was paid to the destination.
+	paidAmt btcutil.Amount
+
+	// paidFee is the routing fee that was paid.
+	paidFee btcutil.Amount
+
+	// firstHops are the outgoing channels of the htlcs that lnd sent for
+	// a failed payment.
+	firstHops []uint64
+}
+
+// payInvoice pays an invoice of the swap via the lnd router and delivers the
+// final result on the returned channel. Failed attempts are retried according
+// to the payment retry policy. No result is delivered if the context is
+// canceled.
+func (s *loopOutSwap) payInvoice(ctx context.Context,
+	payment loopdb.PaymentType, invoice string, maxFee btcutil.Amount,
+	outgoingChannel *uint64) chan paymentResult {
+
+	// Use buffer to prevent blocking.
+	resultChan := make(chan paymentResult, 1)
+
+	go func() {
+		result := s.payInvoiceWithRetry(
+			ctx, payment, invoice, maxFee, outgoingChannel,
+		)
+		if result != nil {
+			resultChan <- *result
+		}
+	}()
+
+	return resultChan
+}
+
+// payInvoiceWithRetry pays an invoice and retries failed attempts as long as
+// the payment retry policy allows it.
+func (s *loopOutSwap) payInvoiceWithRetry(ctx context.Context,
+	payment loopdb.PaymentType, invoice string, maxFee btcutil.Amount,
+	outgoingChannel *uint64) *paymentResult {
+
+	payReq, err := zpay32.Decode(invoice, s.lnd.ChainParams)
+	if err != nil {
+		return &paymentResult{err: err}
+	}
+	if payReq.MilliSat == nil {
+		return &paymentResult{err: errors.New("no amount in invoice")}
+	}
+	amt := payReq.MilliSat.ToSatoshis()
+	invoiceExpiry := payReq.Timestamp.Add(payReq.Expiry())
+
+	policy := s.paymentRetryPolicy
+	backoff := policy.Backoff
+	tried := make(map[uint64]struct{})
+	channel := outgoingChannel
+
+	// A resumed swap may have sent the payment in a previous run already.
+	// In that case, we pick up the existing payment instead of sending a
+	// new one.
+	var result *paymentResult
+	if s.resumed {
+		hash := lntypes.Hash(*payReq.PaymentHash)
+		result = trackPayment(ctx, s.lnd, hash, amt)
+		switch {
+		case result == nil:
+			return nil
+
+		case result.err == channeldb.ErrPaymentNotInitiated:
+			s.log.Infof("No existing %v found", payment)
+			result = nil
+
+		default:
+			s.log.Infof("Resumed existing %v", payment)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		// A payment that was picked up from a previous run counts as
+		// the first attempt.
+		if result == nil {
+			s.log.Infof("%v attempt %v (channel: %v)", payment,
+				attempt, formatChannel(channel))
+
+			result = sendPayment(
+				ctx, s.lnd, invoice, maxFee, channel,
+			)
+			if result == nil {
+				return nil
+			}
+		}
+
+		if result.err == nil {
+			s.log.Infof("%v attempt %v succeeded (fee: %v)",
+				payment, attempt, result.paidFee)
+
+			return result
+		}
+
+		s.log.Infof("%v attempt %v failed: %v", payment, attempt,
+			result.err)
+
+		// Record the channels that were tried, including the ones
+		// that lnd picked by itself, so that the retries use others.
+		if channel != nil {
+			tried[*channel] = struct{}{}
+		}
+		for _, firstHop := range result.firstHops {
+			tried[firstHop] = struct{}{}
+		}
+
+		if !s.canRetryPayment(ctx, attempt, invoiceExpiry, result) {
+			return result
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-s.htlcPublished:
+			s.log.Infof("Not retrying %v, htlc published", payment)
+			return result
+		case <-ctx.Done():
+			return nil
+		}
+
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+
+		// A channel that was selected by the user is used for all
+		// attempts.
+		if outgoingChannel == nil {
+			channel = s.nextPaymentChannel(ctx, amt, tried)
+		}
+		result = nil
+	}
+}
+
+// canRetryPayment returns whether a failed payment attempt may be retried.
+// Retries are only started for failures that may be resolved by another
+// route, while the htlc isn't published yet, while the invoice is still valid
+// and while there is enough time left before the preimage needs to be
+// revealed.
+func (s *loopOutSwap) canRetryPayment(ctx context.Context, attempt int,
+	invoiceExpiry time.Time, result *paymentResult) bool {
+
+	if attempt >= s.paymentRetryPolicy.MaxAttempts {
+		return false
+	}
+
+	select {
+	case <-s.htlcPublished:
+		s.log.Infof("Not retrying, htlc already published")
+		return false
+
+	default:
+	}
+
+	switch result.failure {
+	case loopdb.FailureReasonTimeout, loopdb.FailureReasonNoRoute,
+		loopdb.FailureReasonInsufficientBalance:
+
+	default:
+		return false
+	}
+
+	if time.Now().After(invoiceExpiry) {
+		s.log.Infof("Not retrying, invoice expired at %v",
+			invoiceExpiry)
+
+		return false
+	}
+
+	info, err := s.lnd.Client.GetInfo(ctx)
+	if err != nil {
+		s.log.Warnf("Not retrying, unable to get block height: %v",
+			err)
+
+		return false
+	}
+
+	maxRetryHeight := s.CltvExpiry - MinLoopOutPreimageRevealDelta -
+		s.paymentRetryPolicy.ExpiryDelta
+	if int32(info.BlockHeight) >= maxRetryHeight {
+		s.log.Infof("Not retrying, height %v at or beyond max retry "+
+			"height %v", info.BlockHeight, maxRetryHeight)
+
+		return false
+	}
+
+	return true
+}
+
+// nextPaymentChannel returns the active channel with the highest local
+// balance that can carry the given payment amount and that wasn't tried
+// before. If there is no such channel, nil is returned to let lnd pick the
+// channel.
+func (s *loopOutSwap) nextPaymentChannel(ctx context.Context,
+	amt btcutil.Amount, tried map[uint64]struct{}) *uint64 {
+
+	channels, err := s.lnd.Client.ListChannels(ctx)
+	if err != nil {
+		s.log.Warnf("Unable to list channels: %v", err)
+		return nil
+	}
+
+	var best *lndclient.ChannelInfo
+	for i, channel := range channels {
+		if _, ok := tried[channel.ChannelID]; ok {
+			continue
+		}
+
+		if !channel.Active || channel.LocalBalance < amt {
+			continue
+		}
+
+		if best == nil || channel.LocalBalance > best.LocalBalance {
+			best = &channels[i]
+		}
+	}
+
+	if best == nil {
+		return nil
+	}
+
+	return &best.ChannelID
+}
+
+// formatChannel returns a printable representation of an optional channel.
+func formatChannel(channel *uint64) string {
+	if channel == nil {
+		return "any"
+	}
+
+	return fmt.Sprintf("%v", *channel)
+}
+
+// sendPayment sends a payment via the lnd router and waits for its final
+// state.
+func sendPayment(ctx context.Context, lnd *lndclient.LndServices,
+	invoice string, maxFee btcutil.Amount,
+	outgoingChannel *uint64) *paymentResult {
+
+	hash, amt, err := swap.DecodeInvoice(lnd.ChainParams, invoice)
+	if err != nil {
+		return &paymentResult{err: err}
+	}
+
+	statusChan, errChan, err := lnd.Router.SendPayment(
+		ctx, lndclient.SendPaymentRequest{
+			Invoice:         invoice,
+			MaxFee:          maxFee,
+			OutgoingChannel: outgoingChannel,
+			Timeout:         paymentTimeout,
+		},
+	)
+	if err != nil {
+		return &paymentResult{err: err}
+	}
+
+	for {
+		select {
+		case status := <-statusChan:
+			result := finalPaymentResult(hash, amt, status)
+			if result != nil {
+				return result
+			}
+
+		case err := <-errChan:
+			// lnd refuses to send a payment that was already
+			// sent in a previous run. In that case, we follow the
+			// existing payment to its final state.
+			if err != channeldb.ErrAlreadyPaid {
+				return &paymentResult{err: err}
+			}
+
+			log.Infof("Payment %v already initiated, tracking",
+				hash)
+
+			return trackPayment(ctx, lnd, hash, amt)
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// trackPayment follows an existing payment via the lnd router and waits for
+// its final state. If lnd doesn't know the payment, the returned result holds
+// channeldb.ErrPaymentNotInitiated.
+func trackPayment(ctx context.Context, lnd *lndclient.LndServices,
+	hash lntypes.Hash, amt btcutil.Amount) *paymentResult {
+
+	statusChan, errChan, err := lnd.Router.TrackPayment(ctx, hash)
+	if err != nil {
+		return &paymentResult{err: err}
+	}
+
+	for {
+		select {
+		case status := <-statusChan:
+			result := finalPaymentResult(hash, amt, status)
+			if result != nil {
+				return result
+			}
+
+		case err := <-errChan:
+			return &paymentResult{err: err}
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// finalPaymentResult converts a payment status update into a payment result.
+// Nil is returned if the payment is still in flight.
+func finalPaymentResult(hash lntypes.Hash, amt btcutil.Amount,
+	status lndclient.PaymentStatus) *paymentResult {
+
+	switch status.State {
+	case routerrpc.PaymentState_IN_FLIGHT:
+		return nil
+
+	case routerrpc.PaymentState_SUCCEEDED:
+		log.Infof("Payment %v completed", hash)
+
+		return &paymentResult{
+			paidAmt: amt,
+			paidFee: status.Fee.ToSatoshis(),
+		}
+
+	default:
+		failure := paymentFailureReason(status.State)
+
+		log.Warnf("Payment %v failed: %v", hash, failure)
+
+		return &paymentResult{
+			err:       fmt.Errorf("payment failed: %v", failure),
+			failure:   failure,
+			firstHops: status.FirstHops,
+		}
+	}
+}
+
+// paymentFailureReason maps a failed payment state that is reported by lnd
+// to the failure reason that is stored with the swap.
+func paymentFailureReason(state routerrpc.PaymentState) loopdb.FailureReason {
+	switch state {
+	case routerrpc.PaymentState_FAILED_TIMEOUT:
+		return loopdb.FailureReasonTimeout
+
+	case routerrpc.PaymentState_FAILED_NO_ROUTE:
+		return loopdb.FailureReasonNoRoute
+
+	case routerrpc.PaymentState_FAILED_ERROR:
+		return loopdb.FailureReasonError
+
+	case routerrpc.PaymentState_FAILED_INCORRECT_PAYMENT_DETAILS:
+		return loopdb.FailureReasonIncorrectPaymentDetails
+
+	case routerrpc.PaymentState_FAILED_INSUFFICIENT_BALANCE:
+		return loopdb.FailureReasonInsufficientBalance
+
+	default:
+		return loopdb.FailureReasonUnknown
+	}
+}
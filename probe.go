@@ -0,0 +1,88 @@
+package loop
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/lndclient"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+)
+
+const (
+	// probeTimeout is the maximum time that lnd may spend on finding a
+	// route for a probe payment.
+	probeTimeout = time.Minute
+)
+
+// probeRoute sends a payment with a random hash to the destination to find
+// out whether the destination can be reached with the given amount. Because
+// nobody knows the preimage, the payment can never settle. If lnd reports
+// that the destination rejected the payment details, the payment reached the
+// destination and a route exists. If lnd runs out of routes to try,
+// ErrNoRouteToServer is returned.
+func probeRoute(ctx context.Context, router lndclient.RouterClient,
+	dest [33]byte, amt, maxFee btcutil.Amount,
+	outgoingChannel *uint64) error {
+
+	var hash [32]byte
+	if _, err := rand.Read(hash[:]); err != nil {
+		return err
+	}
+
+	log.Infof("Probing route to %x for %v (channel: %v)", dest, amt,
+		outgoingChannel)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	statusChan, errChan, err := router.SendPayment(
+		ctx, lndclient.SendPaymentRequest{
+			Target:          dest,
+			Amount:          amt,
+			PaymentHash:     hash,
+			MaxFee:          maxFee,
+			OutgoingChannel: outgoingChannel,
+			Timeout:         probeTimeout,
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case status := <-statusChan:
+			switch status.State {
+			case routerrpc.PaymentState_IN_FLIGHT:
+				continue
+
+			// The failure originated at the destination, so the
+			// payment made it all the way there.
+			case routerrpc.PaymentState_FAILED_INCORRECT_PAYMENT_DETAILS:
+				log.Infof("Probe reached %x", dest)
+				return nil
+
+			case routerrpc.PaymentState_FAILED_NO_ROUTE,
+				routerrpc.PaymentState_FAILED_INSUFFICIENT_BALANCE:
+
+				log.Warnf("Probe to %x failed: %v", dest,
+					status.State)
+
+				return lndclient.ErrNoRouteToServer
+
+			default:
+				return fmt.Errorf("probe to %x failed: %v",
+					dest, status.State)
+			}
+
+		case err := <-errChan:
+			return err
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
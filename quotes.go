@@ -0,0 +1,209 @@
+package loop
+
+import (
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+)
+
+var (
+	// ErrQuoteNotFound is returned when a swap references a quote id that
+	// was not handed out by this client.
+	ErrQuoteNotFound = errors.New("quote not found")
+
+	// ErrQuoteExpired is returned when a swap references a quote that is
+	// no longer valid.
+	ErrQuoteExpired = errors.New("quote expired")
+
+	// ErrQuoteAmountMismatch is returned when the amount of a swap differs
+	// from the amount of the quote it references.
+	ErrQuoteAmountMismatch = errors.New("swap amount does not match quote")
+)
+
+// cachedLoopOutQuote is a loop out quote along with the amount it was
+// requested for.
+type cachedLoopOutQuote struct {
+	amt   btcutil.Amount
+	quote *LoopOutQuote
+}
+
+// cachedLoopInQuote is a loop in quote along with the amount it was requested
+// for.
+type cachedLoopInQuote struct {
+	amt   btcutil.Amount
+	quote *LoopInQuote
+}
+
+// quoteCache keeps the quotes that were handed out by the client until they
+// expire, so that a swap that references a quote id can be checked against
+// the quoted figures. The zero value is ready to use.
+type quoteCache struct {
+	loopOut map[string]*cachedLoopOutQuote
+	loopIn  map[string]*cachedLoopInQuote
+
+	sync.Mutex
+}
+
+// addLoopOut stores a loop out quote. Quotes without id are not stored.
+func (c *quoteCache) addLoopOut(amt btcutil.Amount, quote *LoopOutQuote) {
+	if len(quote.QuoteID) == 0 {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.prune(time.Now())
+
+	if c.loopOut == nil {
+		c.loopOut = make(map[string]*cachedLoopOutQuote)
+	}
+	c.loopOut[hex.EncodeToString(quote.QuoteID)] = &cachedLoopOutQuote{
+		amt:   amt,
+		quote: quote,
+	}
+}
+
+// addLoopIn stores a loop in quote. Quotes without id are not stored.
+func (c *quoteCache) addLoopIn(amt btcutil.Amount, quote *LoopInQuote) {
+	if len(quote.QuoteID) == 0 {
+		return
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	c.prune(time.Now())
+
+	if c.loopIn == nil {
+		c.loopIn = make(map[string]*cachedLoopInQuote)
+	}
+	c.loopIn[hex.EncodeToString(quote.QuoteID)] = &cachedLoopInQuote{
+		amt:   amt,
+		quote: quote,
+	}
+}
+
+// takeLoopOut returns the valid loop out quote with the given id for the
+// given amount and server, and removes it from the cache. A quote only locks
+// its fees for a single swap. An empty server id matches any server.
+func (c *quoteCache) takeLoopOut(id []byte, amt btcutil.Amount,
+	serverID string) (*LoopOutQuote, error) {
+
+	c.Lock()
+	defer c.Unlock()
+
+	key := hex.EncodeToString(id)
+	cached, ok := c.loopOut[key]
+	if !ok {
+		return nil, ErrQuoteNotFound
+	}
+
+	err := checkQuote(
+		cached.amt, amt, cached.quote.QuoteExpiry,
+		cached.quote.ServerID, serverID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(c.loopOut, key)
+
+	return cached.quote, nil
+}
+
+// takeLoopIn is the loop in counterpart of takeLoopOut.
+func (c *quoteCache) takeLoopIn(id []byte, amt btcutil.Amount,
+	serverID string) (*LoopInQuote, error) {
+
+	c.Lock()
+	defer c.Unlock()
+
+	key := hex.EncodeToString(id)
+	cached, ok := c.loopIn[key]
+	if !ok {
+		return nil, ErrQuoteNotFound
+	}
+
+	err := checkQuote(
+		cached.amt, amt, cached.quote.QuoteExpiry,
+		cached.quote.ServerID, serverID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	delete(c.loopIn, key)
+
+	return cached.quote, nil
+}
+
+// clearServer removes all quotes of the given server, because the server no
+// longer honors them.
+func (c *quoteCache) clearServer(serverID string) {
+	c.Lock()
+	defer c.Unlock()
+
+	for id, cached := range c.loopOut {
+		if cached.quote.ServerID == serverID {
+			delete(c.loopOut, id)
+		}
+	}
+
+	for id, cached := range c.loopIn {
+		if cached.quote.ServerID == serverID {
+			delete(c.loopIn, id)
+		}
+	}
+}
+
+// prune removes all quotes that expired before the given time. The caller
+// must hold the lock.
+func (c *quoteCache) prune(now time.Time) {
+	for id, cached := range c.loopOut {
+		if quoteExpired(now, cached.quote.QuoteExpiry) {
+			delete(c.loopOut, id)
+		}
+	}
+
+	for id, cached := range c.loopIn {
+		if quoteExpired(now, cached.quote.QuoteExpiry) {
+			delete(c.loopIn, id)
+		}
+	}
+}
+
+// quoteExpired returns whether a quote with the given expiry is expired at the
+// given time. A zero expiry means that the server didn't set an expiry, so the
+// quote doesn't expire.
+func quoteExpired(now, expiry time.Time) bool {
+	return !expiry.IsZero() && now.After(expiry)
+}
+
+// checkQuote verifies that a quote for the given amount is still valid and
+// applies to a swap of the requested amount and server.
+func checkQuote(quoteAmt, swapAmt btcutil.Amount, expiry time.Time,
+	quoteServerID, swapServerID string) error {
+
+	if swapServerID != "" && swapServerID != quoteServerID {
+		return ErrQuoteServerMismatch
+	}
+
+	if quoteAmt != swapAmt {
+		log.Warnf("Swap amount %v differs from quoted amount %v",
+			swapAmt, quoteAmt)
+
+		return ErrQuoteAmountMismatch
+	}
+
+	if quoteExpired(time.Now(), expiry) {
+		log.Warnf("Quote expired at %v", expiry)
+
+		return ErrQuoteExpired
+	}
+
+	return nil
+}
@@ -0,0 +1,358 @@
+package loop
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+const (
+	// MinRecurringInterval is the shortest interval at which a recurring
+	// swap may initiate swaps. Recurring swaps are evaluated on every
+	// block, so shorter intervals wouldn't be honored anyway.
+	MinRecurringInterval = time.Hour
+)
+
+var (
+	// ErrRecurringNotActive is returned when a recurring swap that was
+	// already canceled is canceled again.
+	ErrRecurringNotActive = errors.New("recurring swap not active")
+
+	// ErrRecurringRunning is returned when a recurring swap is canceled
+	// while one of its runs is initiating a swap.
+	ErrRecurringRunning = errors.New("recurring swap is initiating a " +
+		"swap")
+
+	// ErrRecurringIntervalTooShort is returned when a recurring swap is
+	// created with an interval below MinRecurringInterval.
+	ErrRecurringIntervalTooShort = fmt.Errorf("recurring interval must "+
+		"be at least %v", MinRecurringInterval)
+
+	// ErrMaxPerPeriodTooLow is returned when the period limit of a
+	// recurring swap doesn't even allow a single swap.
+	ErrMaxPerPeriodTooLow = errors.New("maximum amount per period below " +
+		"swap amount")
+)
+
+// RecurringSchedule describes when a recurring swap initiates its swaps.
+type RecurringSchedule struct {
+	// StartTime is the time of the first run. If zero, the first run
+	// happens immediately.
+	StartTime time.Time
+
+	// Interval is the time between two runs.
+	Interval time.Duration
+
+	// Period is the sliding window over which the total swapped amount is
+	// limited to MaxPerPeriod. If zero, the interval is used.
+	Period time.Duration
+
+	// MaxPerPeriod is the maximum total amount that is swapped within
+	// Period. Zero disables the limit.
+	MaxPerPeriod btcutil.Amount
+}
+
+// createRecurring validates and persists a new active recurring swap.
+func (s *scheduler) createRecurring(recurring *loopdb.RecurringSwap) error {
+	if recurring.Interval < MinRecurringInterval {
+		return ErrRecurringIntervalTooShort
+	}
+
+	if recurring.MaxPerPeriod != 0 &&
+		recurring.MaxPerPeriod < recurring.Amount {
+
+		return ErrMaxPerPeriodTooLow
+	}
+
+	if _, err := rand.Read(recurring.ID[:]); err != nil {
+		return err
+	}
+
+	recurring.CreationTime = time.Now()
+	if recurring.StartTime.IsZero() {
+		recurring.StartTime = recurring.CreationTime
+	}
+	if recurring.Period == 0 {
+		recurring.Period = recurring.Interval
+	}
+	recurring.State = loopdb.RecurringStateActive
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log.Infof("Creating recurring %v swap %v for %v every %v",
+		recurring.Type, recurring.ID, recurring.Amount,
+		recurring.Interval)
+
+	return s.store.CreateRecurringSwap(recurring)
+}
+
+// cancelRecurring cancels an active recurring swap.
+func (s *scheduler) cancelRecurring(id loopdb.RecurringID) (
+	*loopdb.RecurringSwap, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.running[id]; ok {
+		return nil, ErrRecurringRunning
+	}
+
+	recurringSwaps, err := s.store.FetchRecurringSwaps()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, recurring := range recurringSwaps {
+		if recurring.ID != id {
+			continue
+		}
+
+		if recurring.State != loopdb.RecurringStateActive {
+			return nil, ErrRecurringNotActive
+		}
+
+		recurring.State = loopdb.RecurringStateCanceled
+		err := s.store.UpdateRecurringSwap(id, recurring.State)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Infof("Canceled recurring swap %v", id)
+
+		return recurring, nil
+	}
+
+	return nil, loopdb.ErrRecurringSwapNotFound
+}
+
+// dueRun is a run of a recurring swap that is due.
+type dueRun struct {
+	recurring *loopdb.RecurringSwap
+	slot      time.Time
+}
+
+// evaluateRecurring runs all active recurring swaps that are due at the
+// given time.
+func (s *scheduler) evaluateRecurring(ctx context.Context, now time.Time) {
+	for _, run := range s.dueRuns(now) {
+		s.runRecurring(ctx, run.recurring, run.slot, now)
+	}
+}
+
+// dueRuns returns the runs of the active recurring swaps that are due at the
+// given time and marks the recurring swaps as running.
+func (s *scheduler) dueRuns(now time.Time) []dueRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recurringSwaps, err := s.store.FetchRecurringSwaps()
+	if err != nil {
+		log.Errorf("Unable to fetch recurring swaps: %v", err)
+		return nil
+	}
+
+	var due []dueRun
+	for _, recurring := range recurringSwaps {
+		if recurring.State != loopdb.RecurringStateActive {
+			continue
+		}
+
+		if _, ok := s.running[recurring.ID]; ok {
+			continue
+		}
+
+		slot, ok := recurringSlot(recurring, now)
+		if !ok {
+			continue
+		}
+
+		s.running[recurring.ID] = struct{}{}
+		due = append(due, dueRun{
+			recurring: recurring,
+			slot:      slot,
+		})
+	}
+
+	return due
+}
+
+// recurringSlot returns the start of the interval that the given time falls
+// in and whether the recurring swap still needs to run for it. Intervals that
+// were missed entirely, for example because the daemon wasn't running, are
+// skipped rather than caught up on.
+func recurringSlot(recurring *loopdb.RecurringSwap, now time.Time) (time.Time,
+	bool) {
+
+	if now.Before(recurring.StartTime) {
+		return time.Time{}, false
+	}
+
+	elapsed := now.Sub(recurring.StartTime)
+	slot := recurring.StartTime.Add(
+		elapsed / recurring.Interval * recurring.Interval,
+	)
+
+	lastRun := recurring.LastRun()
+	if lastRun != nil && !slot.After(lastRun.ScheduledTime) {
+		return time.Time{}, false
+	}
+
+	return slot, true
+}
+
+// periodAmount returns the total amount of the successful runs of a
+// recurring swap within the period that ends at the given time.
+func periodAmount(recurring *loopdb.RecurringSwap,
+	now time.Time) btcutil.Amount {
+
+	periodStart := now.Add(-recurring.Period)
+
+	var total btcutil.Amount
+	for _, run := range recurring.Runs {
+		if run.Failed() || !run.Time.After(periodStart) {
+			continue
+		}
+
+		total += run.Amount
+	}
+
+	return total
+}
+
+// recurringSwaps returns the hashes of the swaps that the runs of a recurring
+// swap initiated.
+func recurringSwaps(
+	recurring *loopdb.RecurringSwap) map[lntypes.Hash]struct{} {
+
+	hashes := make(map[lntypes.Hash]struct{})
+	for _, run := range recurring.Runs {
+		if !run.Failed() {
+			hashes[run.SwapHash] = struct{}{}
+		}
+	}
+
+	return hashes
+}
+
+// runRecurring initiates the swap of a recurring swap that was marked as
+// running for the given slot and records the run. Runs that fail are recorded
+// as well, so that they can be reported to the user.
+func (s *scheduler) runRecurring(ctx context.Context,
+	recurring *loopdb.RecurringSwap, slot, now time.Time) {
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, recurring.ID)
+		s.mu.Unlock()
+	}()
+
+	run := &loopdb.RecurringRun{
+		ScheduledTime: slot,
+		Time:          now,
+		Amount:        recurring.Amount,
+	}
+
+	swapped := periodAmount(recurring, now)
+	if recurring.MaxPerPeriod != 0 &&
+		swapped+recurring.Amount > recurring.MaxPerPeriod {
+
+		run.Error = fmt.Sprintf("maximum of %v per %v exceeded, "+
+			"already swapped %v", recurring.MaxPerPeriod,
+			recurring.Period, swapped)
+	} else {
+		hash, err := s.initiate(
+			ctx, &recurring.SwapRequest, recurringSwaps(recurring),
+		)
+
+		// If the run was interrupted because we are shutting down,
+		// nothing is recorded and the slot is run again after
+		// restart if it is still current.
+		if err != nil && ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			run.Error = err.Error()
+		} else {
+			run.SwapHash = *hash
+		}
+	}
+
+	if run.Failed() {
+		log.Errorf("Recurring swap %v failed to initiate swap: %v",
+			recurring.ID, run.Error)
+	} else {
+		log.Infof("Recurring swap %v initiated swap %v", recurring.ID,
+			run.SwapHash)
+	}
+
+	err := s.store.AddRecurringRun(recurring.ID, run)
+	if err != nil {
+		log.Errorf("Unable to record run of recurring swap %v: %v",
+			recurring.ID, err)
+	}
+}
+
+// CreateRecurringLoopOut persists a recurring loop out swap. A swap with the
+// given request parameters is initiated on every interval of the schedule. If
+// the request has no destination address, every swap obtains a fresh one from
+// NextDestAddr. A fixed destination address is shared by all runs, but must
+// not be used by any other swap.
+func (s *Client) CreateRecurringLoopOut(request *OutRequest,
+	schedule *RecurringSchedule) (*loopdb.RecurringSwap, error) {
+
+	if request.DestAddr != nil {
+		if err := s.checkDestAddrs(request); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.createRecurring(outSwapRequest(request), schedule)
+}
+
+// CreateRecurringLoopIn persists a recurring loop in swap. A swap with the
+// given request parameters is initiated on every interval of the schedule.
+func (s *Client) CreateRecurringLoopIn(request *LoopInRequest,
+	schedule *RecurringSchedule) (*loopdb.RecurringSwap, error) {
+
+	return s.createRecurring(inSwapRequest(request), schedule)
+}
+
+// createRecurring persists a recurring swap with the given parameters.
+func (s *Client) createRecurring(request loopdb.SwapRequest,
+	schedule *RecurringSchedule) (*loopdb.RecurringSwap, error) {
+
+	recurring := &loopdb.RecurringSwap{
+		StartTime:    schedule.StartTime,
+		Interval:     schedule.Interval,
+		Period:       schedule.Period,
+		MaxPerPeriod: schedule.MaxPerPeriod,
+		SwapRequest:  request,
+	}
+	if err := s.scheduler.createRecurring(recurring); err != nil {
+		return nil, err
+	}
+
+	return recurring, nil
+}
+
+// FetchRecurringSwaps returns all recurring swaps, including the canceled
+// ones, together with their runs.
+func (s *Client) FetchRecurringSwaps() ([]*loopdb.RecurringSwap, error) {
+	return s.Store.FetchRecurringSwaps()
+}
+
+// CancelRecurringSwap cancels an active recurring swap, so that it doesn't
+// initiate any further swaps.
+func (s *Client) CancelRecurringSwap(id loopdb.RecurringID) (
+	*loopdb.RecurringSwap, error) {
+
+	return s.scheduler.cancelRecurring(id)
+}
@@ -0,0 +1,244 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// TestRecurringSwaps tests that recurring swaps initiate one swap per
+// interval, honor their period limit and record failed runs.
+func TestRecurringSwaps(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+	store := newStoreMock(t)
+
+	var (
+		loopOuts []*OutRequest
+		loopErr  error
+	)
+	swapHash := lntypes.Hash{1}
+
+	s := newScheduler(&schedulerConfig{
+		lnd:   &lnd.LndServices,
+		store: store,
+		loopOut: func(_ context.Context, req *OutRequest) (
+			*lntypes.Hash, btcutil.Address, error) {
+
+			loopOuts = append(loopOuts, req)
+			if loopErr != nil {
+				return nil, nil, loopErr
+			}
+			return &swapHash, nil, nil
+		},
+	})
+
+	request := loopdb.SwapRequest{
+		Type:       swap.TypeOut,
+		Amount:     100000,
+		DestAddr:   testAddr,
+		MaxSwapFee: 1000,
+	}
+
+	// Intervals that are too short and limits that don't allow a single
+	// swap are rejected.
+	err := s.createRecurring(&loopdb.RecurringSwap{
+		Interval:    time.Minute,
+		SwapRequest: request,
+	})
+	if err != ErrRecurringIntervalTooShort {
+		t.Fatalf("expected interval too short, got %v", err)
+	}
+
+	err = s.createRecurring(&loopdb.RecurringSwap{
+		Interval:     time.Hour,
+		MaxPerPeriod: 50000,
+		SwapRequest:  request,
+	})
+	if err != ErrMaxPerPeriodTooLow {
+		t.Fatalf("expected max per period too low, got %v", err)
+	}
+
+	start := time.Unix(1500000000, 0)
+	day := 24 * time.Hour
+
+	recurring := &loopdb.RecurringSwap{
+		StartTime:    start,
+		Interval:     day,
+		Period:       3 * day,
+		MaxPerPeriod: 200000,
+		SwapRequest:  request,
+	}
+	if err := s.createRecurring(recurring); err != nil {
+		t.Fatal(err)
+	}
+
+	// assertRuns evaluates the recurring swap at the given time and asserts
+	// the total number of runs and the error of the last run.
+	assertRuns := func(now time.Time, expectedRuns int,
+		expectedErr string) {
+
+		t.Helper()
+
+		s.evaluateRecurring(context.Background(), now)
+
+		stored := store.recurringSwaps[recurring.ID]
+		if len(stored.Runs) != expectedRuns {
+			t.Fatalf("expected %v runs, got %v", expectedRuns,
+				len(stored.Runs))
+		}
+
+		lastRun := stored.LastRun()
+		if lastRun == nil {
+			return
+		}
+		if lastRun.Error != expectedErr {
+			t.Fatalf("expected error %q, got %q", expectedErr,
+				lastRun.Error)
+		}
+		if !lastRun.Failed() && lastRun.SwapHash != swapHash {
+			t.Fatalf("unexpected swap hash %v", lastRun.SwapHash)
+		}
+	}
+
+	// Nothing runs before the start time.
+	assertRuns(start.Add(-time.Minute), 0, "")
+
+	// The first interval runs once.
+	assertRuns(start, 1, "")
+	assertRuns(start.Add(time.Hour), 1, "")
+	if len(loopOuts) != 1 || loopOuts[0].Amount != 100000 ||
+		loopOuts[0].DestAddr != testAddr {
+
+		t.Fatalf("unexpected loop out requests: %v", loopOuts)
+	}
+
+	// The second interval runs as well, after which the period limit is
+	// reached. The third run fails without initiating a swap.
+	assertRuns(start.Add(day), 2, "")
+	assertRuns(start.Add(2*day), 3, "maximum of 0.002 BTC per 72h0m0s "+
+		"exceeded, already swapped 0.002 BTC")
+	if len(loopOuts) != 2 {
+		t.Fatalf("expected 2 loop outs, got %v", len(loopOuts))
+	}
+
+	// Once the first swap drops out of the period, the next run succeeds
+	// again.
+	assertRuns(start.Add(3*day+time.Minute), 4, "")
+
+	// Swap failures are recorded.
+	loopErr = errors.New("swap amount too low")
+	assertRuns(start.Add(4*day), 5, "swap amount too low")
+	loopErr = nil
+
+	// Missed intervals are skipped instead of being caught up on.
+	assertRuns(start.Add(10*day+time.Hour), 6, "")
+	assertRuns(start.Add(10*day+2*time.Hour), 6, "")
+	lastRun := store.recurringSwaps[recurring.ID].LastRun()
+	if !lastRun.ScheduledTime.Equal(start.Add(10 * day)) {
+		t.Fatalf("unexpected scheduled time %v", lastRun.ScheduledTime)
+	}
+
+	// After cancellation, no more swaps are initiated.
+	if _, err := s.cancelRecurring(recurring.ID); err != nil {
+		t.Fatal(err)
+	}
+	_, err = s.cancelRecurring(recurring.ID)
+	if err != ErrRecurringNotActive {
+		t.Fatalf("expected recurring swap not active, got %v", err)
+	}
+
+	assertRuns(start.Add(11*day), 6, "")
+	if len(loopOuts) != 5 {
+		t.Fatalf("expected 5 loop outs, got %v", len(loopOuts))
+	}
+}
+
+// TestRecurringLoopOutFixedDest tests that a recurring loop out with a fixed
+// destination address persists the address and that all of its runs sweep to
+// it, while the address is still rejected if another swap used it.
+func TestRecurringLoopOutFixedDest(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+	store := newStoreMock(t)
+	client := newSwapClient(&clientConfig{
+		LndServices: &lnd.LndServices,
+		Store:       store,
+	})
+
+	// Every initiated swap records its destination, so that the address
+	// counts as used for the next run.
+	var swapHash lntypes.Hash
+	client.scheduler.loopOut = func(_ context.Context, req *OutRequest) (
+		*lntypes.Hash, btcutil.Address, error) {
+
+		if err := client.checkDestAddrs(req); err != nil {
+			return nil, nil, err
+		}
+
+		swapHash[0]++
+		store.loopOutSwaps[swapHash] = &loopdb.LoopOutContract{
+			DestAddr: req.DestAddr,
+		}
+
+		hash := swapHash
+		return &hash, nil, nil
+	}
+
+	start := time.Unix(1500000000, 0)
+	schedule := &RecurringSchedule{
+		StartTime: start,
+		Interval:  time.Hour,
+	}
+	recurring, err := client.CreateRecurringLoopOut(
+		&OutRequest{
+			Amount:   100000,
+			DestAddr: testAddr,
+		}, schedule,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored := store.recurringSwaps[recurring.ID]
+	if stored.DestAddr != testAddr {
+		t.Fatalf("expected destination %v, got %v", testAddr,
+			stored.DestAddr)
+	}
+
+	client.scheduler.evaluateRecurring(context.Background(), start)
+	client.scheduler.evaluateRecurring(
+		context.Background(), start.Add(time.Hour),
+	)
+
+	stored = store.recurringSwaps[recurring.ID]
+	if len(stored.Runs) != 2 {
+		t.Fatalf("expected 2 runs, got %v", len(stored.Runs))
+	}
+	for i, run := range stored.Runs {
+		if run.Failed() {
+			t.Fatalf("run %v failed: %v", i, run.Error)
+		}
+	}
+
+	// Another recurring swap can't sweep to the address that the first
+	// one uses.
+	_, err = client.CreateRecurringLoopOut(
+		&OutRequest{
+			Amount:   100000,
+			DestAddr: testAddr,
+		}, schedule,
+	)
+	if err != ErrDestAddrReused {
+		t.Fatalf("expected reused address, got %v", err)
+	}
+}
@@ -0,0 +1,444 @@
+package loop
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/lndclient"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+)
+
+var (
+	// ErrIntentNotPending is returned when a swap intent that is launching
+	// or already launched its swap or that was canceled is canceled.
+	ErrIntentNotPending = errors.New("swap intent not pending")
+
+	// ErrNoFeeConfTarget is returned when a swap intent has a fee rate
+	// condition without a confirmation target for the fee estimate.
+	ErrNoFeeConfTarget = errors.New("fee rate condition requires a " +
+		"confirmation target")
+)
+
+// schedulerConfig contains the dependencies of the scheduler.
+type schedulerConfig struct {
+	lnd *lndclient.LndServices
+
+	store loopdb.SwapStore
+
+	// loopOut initiates a loop out swap.
+	loopOut func(context.Context, *OutRequest) (*lntypes.Hash,
+		btcutil.Address, error)
+
+	// loopIn initiates a loop in swap.
+	loopIn func(context.Context, *LoopInRequest) (*lntypes.Hash,
+		btcutil.Address, error)
+
+	// destAddr returns a fresh destination address for loop outs that
+	// were persisted without one.
+	destAddr func(context.Context) (btcutil.Address, error)
+}
+
+// scheduler launches the swaps of persisted swap intents once their trigger
+// conditions are met and initiates the swaps of recurring swaps. Both are
+// evaluated on every block.
+type scheduler struct {
+	// mu serializes the selection of due intents and recurring swaps with
+	// their creation and cancellation. It is not held while swaps are
+	// initiated. Instead, the intents and recurring swaps that are
+	// initiating a swap are tracked, so that they can't be canceled in
+	// the meantime.
+	mu sync.Mutex
+
+	// launching holds the intents whose swaps are being initiated.
+	launching map[loopdb.IntentID]struct{}
+
+	// running holds the recurring swaps whose runs are initiating a swap.
+	running map[loopdb.RecurringID]struct{}
+
+	schedulerConfig
+}
+
+// newScheduler returns a new scheduler instance.
+func newScheduler(cfg *schedulerConfig) *scheduler {
+	return &scheduler{
+		launching:       make(map[loopdb.IntentID]struct{}),
+		running:         make(map[loopdb.RecurringID]struct{}),
+		schedulerConfig: *cfg,
+	}
+}
+
+// run evaluates the pending intents at the given height and then on every
+// block that is delivered on blockChan, until the context is canceled.
+func (s *scheduler) run(ctx context.Context, blockChan <-chan interface{},
+	height int32) error {
+
+	log.Infof("Starting swap scheduler at height %v", height)
+
+	s.evaluate(ctx, height)
+
+	for {
+		select {
+		case h := <-blockChan:
+			s.evaluate(ctx, h.(int32))
+
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// schedule persists a new pending swap intent.
+func (s *scheduler) schedule(intent *loopdb.SwapIntent) error {
+	trigger := &intent.Trigger
+	if trigger.MaxFeeRate != 0 && trigger.FeeConfTarget <= 0 {
+		return ErrNoFeeConfTarget
+	}
+
+	if _, err := rand.Read(intent.ID[:]); err != nil {
+		return err
+	}
+	intent.CreationTime = time.Now()
+	intent.IntentUpdate = loopdb.IntentUpdate{
+		State: loopdb.IntentStatePending,
+		Time:  intent.CreationTime,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log.Infof("Scheduling %v swap intent %v for %v", intent.Type,
+		intent.ID, intent.Amount)
+
+	return s.store.CreateSwapIntent(intent)
+}
+
+// cancel cancels a pending swap intent.
+func (s *scheduler) cancel(id loopdb.IntentID) (*loopdb.SwapIntent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.launching[id]; ok {
+		return nil, ErrIntentNotPending
+	}
+
+	intents, err := s.store.FetchSwapIntents()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, intent := range intents {
+		if intent.ID != id {
+			continue
+		}
+
+		if intent.State != loopdb.IntentStatePending {
+			return nil, ErrIntentNotPending
+		}
+
+		intent.IntentUpdate = loopdb.IntentUpdate{
+			State: loopdb.IntentStateCanceled,
+			Time:  time.Now(),
+		}
+		err := s.store.UpdateSwapIntent(id, &intent.IntentUpdate)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Infof("Canceled swap intent %v", id)
+
+		return intent, nil
+	}
+
+	return nil, loopdb.ErrSwapIntentNotFound
+}
+
+// evaluate launches the swaps of all pending intents whose trigger conditions
+// are met at the given height and runs the recurring swaps that are due.
+func (s *scheduler) evaluate(ctx context.Context, height int32) {
+	s.evaluateIntents(ctx, height)
+	s.evaluateRecurring(ctx, time.Now())
+}
+
+// evaluateIntents launches the swaps of all pending intents whose trigger
+// conditions are met at the given height.
+func (s *scheduler) evaluateIntents(ctx context.Context, height int32) {
+	for _, intent := range s.dueIntents(ctx, height) {
+		s.launch(ctx, intent)
+	}
+}
+
+// dueIntents returns the pending intents whose trigger conditions are met at
+// the given height and marks them as launching.
+func (s *scheduler) dueIntents(ctx context.Context,
+	height int32) []*loopdb.SwapIntent {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	intents, err := s.store.FetchSwapIntents()
+	if err != nil {
+		log.Errorf("Unable to fetch swap intents: %v", err)
+		return nil
+	}
+
+	// Intents usually share their confirmation targets, so we only
+	// estimate the fee once per target and block.
+	feeRates := make(map[int32]chainfee.SatPerKWeight)
+
+	var due []*loopdb.SwapIntent
+	for _, intent := range intents {
+		if intent.State != loopdb.IntentStatePending {
+			continue
+		}
+
+		if _, ok := s.launching[intent.ID]; ok {
+			continue
+		}
+
+		triggered, err := s.triggered(ctx, intent, height, feeRates)
+		if err != nil {
+			log.Warnf("Unable to evaluate swap intent %v: %v",
+				intent.ID, err)
+
+			continue
+		}
+		if !triggered {
+			continue
+		}
+
+		s.launching[intent.ID] = struct{}{}
+		due = append(due, intent)
+	}
+
+	return due
+}
+
+// triggered returns whether the trigger conditions of an intent are met at
+// the given height.
+func (s *scheduler) triggered(ctx context.Context, intent *loopdb.SwapIntent,
+	height int32, feeRates map[int32]chainfee.SatPerKWeight) (bool,
+	error) {
+
+	trigger := &intent.Trigger
+
+	if !trigger.NotBefore.IsZero() && time.Now().Before(trigger.NotBefore) {
+		return false, nil
+	}
+
+	if height < trigger.NotBeforeHeight {
+		return false, nil
+	}
+
+	if trigger.DeadlineHeight != 0 && height >= trigger.DeadlineHeight {
+		log.Infof("Swap intent %v reached deadline height %v",
+			intent.ID, trigger.DeadlineHeight)
+
+		return true, nil
+	}
+
+	if trigger.MaxFeeRate == 0 {
+		return true, nil
+	}
+
+	feeRate, ok := feeRates[trigger.FeeConfTarget]
+	if !ok {
+		var err error
+		feeRate, err = s.lnd.WalletKit.EstimateFee(
+			ctx, trigger.FeeConfTarget,
+		)
+		if err != nil {
+			return false, err
+		}
+		feeRates[trigger.FeeConfTarget] = feeRate
+	}
+
+	if feeRate > trigger.MaxFeeRate {
+		log.Debugf("Swap intent %v waiting for fee rate %v, "+
+			"estimate is %v", intent.ID, trigger.MaxFeeRate,
+			feeRate)
+
+		return false, nil
+	}
+
+	log.Infof("Swap intent %v triggered at fee rate %v", intent.ID,
+		feeRate)
+
+	return true, nil
+}
+
+// initiate initiates a swap with the given request parameters. The own swaps
+// are the earlier swaps of the same recurring swap, if any.
+func (s *scheduler) initiate(ctx context.Context, req *loopdb.SwapRequest,
+	ownSwaps map[lntypes.Hash]struct{}) (*lntypes.Hash, error) {
+
+	switch req.Type {
+	case swap.TypeOut:
+		// Without a destination address, every swap obtains a fresh
+		// one, so that recurring swaps don't reuse their address.
+		destAddr := req.DestAddr
+		if destAddr == nil {
+			var err error
+			destAddr, err = s.destAddr(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		hash, _, err := s.loopOut(ctx, &OutRequest{
+			Amount:              req.Amount,
+			DestAddr:            destAddr,
+			MaxSwapRoutingFee:   req.MaxSwapRoutingFee,
+			MaxPrepayRoutingFee: req.MaxPrepayRoutingFee,
+			MaxSwapFee:          req.MaxSwapFee,
+			MaxPrepayAmount:     req.MaxPrepayAmount,
+			MaxMinerFee:         req.MaxMinerFee,
+			SweepConfTarget:     req.ConfTarget,
+			LoopOutChannel:      req.Channel,
+			ServerID:            req.ServerID,
+			ownSwaps:            ownSwaps,
+		})
+		return hash, err
+
+	case swap.TypeIn:
+		hash, _, err := s.loopIn(ctx, &LoopInRequest{
+			Amount:         req.Amount,
+			MaxSwapFee:     req.MaxSwapFee,
+			MaxMinerFee:    req.MaxMinerFee,
+			HtlcConfTarget: req.ConfTarget,
+			LoopInChannel:  req.Channel,
+			ExternalHtlc:   req.ExternalHtlc,
+			ServerID:       req.ServerID,
+		})
+		return hash, err
+
+	default:
+		return nil, fmt.Errorf("unknown swap type %v", req.Type)
+	}
+}
+
+// launch initiates the swap of an intent that was marked as launching and
+// records the outcome.
+func (s *scheduler) launch(ctx context.Context, intent *loopdb.SwapIntent) {
+	defer func() {
+		s.mu.Lock()
+		delete(s.launching, intent.ID)
+		s.mu.Unlock()
+	}()
+
+	hash, err := s.initiate(ctx, &intent.SwapRequest, nil)
+
+	// If the launch was interrupted because we are shutting down, the
+	// intent remains pending and is evaluated again after restart.
+	if err != nil && ctx.Err() != nil {
+		return
+	}
+
+	update := loopdb.IntentUpdate{
+		Time: time.Now(),
+	}
+	if err != nil {
+		log.Errorf("Swap intent %v failed to launch swap: %v",
+			intent.ID, err)
+
+		update.State = loopdb.IntentStateFailed
+		update.Error = err.Error()
+	} else {
+		log.Infof("Swap intent %v launched swap %v", intent.ID, hash)
+
+		update.State = loopdb.IntentStateLaunched
+		update.SwapHash = *hash
+	}
+
+	err = s.store.UpdateSwapIntent(intent.ID, &update)
+	if err != nil {
+		log.Errorf("Unable to update swap intent %v: %v", intent.ID,
+			err)
+	}
+}
+
+// outSwapRequest returns the persisted parameters of a loop out request.
+func outSwapRequest(request *OutRequest) loopdb.SwapRequest {
+	return loopdb.SwapRequest{
+		Type:                swap.TypeOut,
+		Amount:              request.Amount,
+		DestAddr:            request.DestAddr,
+		MaxSwapFee:          request.MaxSwapFee,
+		MaxMinerFee:         request.MaxMinerFee,
+		MaxPrepayAmount:     request.MaxPrepayAmount,
+		MaxSwapRoutingFee:   request.MaxSwapRoutingFee,
+		MaxPrepayRoutingFee: request.MaxPrepayRoutingFee,
+		ConfTarget:          request.SweepConfTarget,
+		Channel:             request.LoopOutChannel,
+		ServerID:            request.ServerID,
+	}
+}
+
+// inSwapRequest returns the persisted parameters of a loop in request.
+func inSwapRequest(request *LoopInRequest) loopdb.SwapRequest {
+	return loopdb.SwapRequest{
+		Type:         swap.TypeIn,
+		Amount:       request.Amount,
+		MaxSwapFee:   request.MaxSwapFee,
+		MaxMinerFee:  request.MaxMinerFee,
+		ConfTarget:   request.HtlcConfTarget,
+		Channel:      request.LoopInChannel,
+		ExternalHtlc: request.ExternalHtlc,
+		ServerID:     request.ServerID,
+	}
+}
+
+// ScheduleLoopOut persists a loop out swap intent. The swap is initiated with
+// the given request parameters once the trigger conditions are met. If the
+// request has no destination address, a fresh one is obtained from
+// NextDestAddr when the swap is initiated.
+func (s *Client) ScheduleLoopOut(request *OutRequest,
+	trigger loopdb.IntentTrigger) (*loopdb.SwapIntent, error) {
+
+	intent := &loopdb.SwapIntent{
+		Trigger:     trigger,
+		SwapRequest: outSwapRequest(request),
+	}
+	if err := s.scheduler.schedule(intent); err != nil {
+		return nil, err
+	}
+
+	return intent, nil
+}
+
+// ScheduleLoopIn persists a loop in swap intent. The swap is initiated with
+// the given request parameters once the trigger conditions are met.
+func (s *Client) ScheduleLoopIn(request *LoopInRequest,
+	trigger loopdb.IntentTrigger) (*loopdb.SwapIntent, error) {
+
+	intent := &loopdb.SwapIntent{
+		Trigger:     trigger,
+		SwapRequest: inSwapRequest(request),
+	}
+	if err := s.scheduler.schedule(intent); err != nil {
+		return nil, err
+	}
+
+	return intent, nil
+}
+
+// FetchSwapIntents returns all swap intents, including the ones that already
+// launched their swap, failed or were canceled.
+func (s *Client) FetchSwapIntents() ([]*loopdb.SwapIntent, error) {
+	return s.Store.FetchSwapIntents()
+}
+
+// CancelSwapIntent cancels a pending swap intent, so that its swap is never
+// initiated.
+func (s *Client) CancelSwapIntent(id loopdb.IntentID) (*loopdb.SwapIntent,
+	error) {
+
+	return s.scheduler.cancel(id)
+}
@@ -0,0 +1,300 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
+)
+
+// TestSchedulerTrigger tests the evaluation of the trigger conditions of swap
+// intents.
+func TestSchedulerTrigger(t *testing.T) {
+	lnd := test.NewMockLnd()
+	lnd.SetFeeEstimate(6, 2500)
+
+	future := time.Now().Add(time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name      string
+		trigger   loopdb.IntentTrigger
+		height    int32
+		triggered bool
+	}{
+		{
+			name:      "no conditions",
+			height:    600,
+			triggered: true,
+		},
+		{
+			name: "before start time",
+			trigger: loopdb.IntentTrigger{
+				NotBefore: future,
+			},
+			height: 600,
+		},
+		{
+			name: "after start time",
+			trigger: loopdb.IntentTrigger{
+				NotBefore: past,
+			},
+			height:    600,
+			triggered: true,
+		},
+		{
+			name: "before start height",
+			trigger: loopdb.IntentTrigger{
+				NotBeforeHeight: 601,
+			},
+			height: 600,
+		},
+		{
+			name: "fee rate too high",
+			trigger: loopdb.IntentTrigger{
+				MaxFeeRate:    2000,
+				FeeConfTarget: 6,
+			},
+			height: 600,
+		},
+		{
+			name: "fee rate low enough",
+			trigger: loopdb.IntentTrigger{
+				MaxFeeRate:    2500,
+				FeeConfTarget: 6,
+			},
+			height:    600,
+			triggered: true,
+		},
+		{
+			name: "deadline reached",
+			trigger: loopdb.IntentTrigger{
+				MaxFeeRate:     2000,
+				FeeConfTarget:  6,
+				DeadlineHeight: 600,
+			},
+			height:    600,
+			triggered: true,
+		},
+		{
+			name: "deadline before start time",
+			trigger: loopdb.IntentTrigger{
+				NotBefore:      future,
+				DeadlineHeight: 600,
+			},
+			height: 600,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			s := newScheduler(&schedulerConfig{
+				lnd: &lnd.LndServices,
+			})
+
+			triggered, err := s.triggered(
+				context.Background(), &loopdb.SwapIntent{
+					Trigger: test.trigger,
+				}, test.height,
+				make(map[int32]chainfee.SatPerKWeight),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if triggered != test.triggered {
+				t.Fatalf("expected triggered %v, got %v",
+					test.triggered, triggered)
+			}
+		})
+	}
+}
+
+// TestSchedulerLaunch tests that triggered intents launch their swap exactly
+// once and that canceled intents never do.
+func TestSchedulerLaunch(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+	lnd.SetFeeEstimate(6, 5000)
+
+	store := newStoreMock(t)
+
+	var (
+		s         *scheduler
+		loopOuts  []*OutRequest
+		loopIns   []*LoopInRequest
+		cancelErr error
+	)
+	swapHash := lntypes.Hash{1}
+
+	s = newScheduler(&schedulerConfig{
+		lnd:   &lnd.LndServices,
+		store: store,
+		loopOut: func(_ context.Context, req *OutRequest) (
+			*lntypes.Hash, btcutil.Address, error) {
+
+			loopOuts = append(loopOuts, req)
+			return &swapHash, nil, nil
+		},
+		loopIn: func(_ context.Context, req *LoopInRequest) (
+			*lntypes.Hash, btcutil.Address, error) {
+
+			loopIns = append(loopIns, req)
+
+			// The scheduler isn't locked while the swap is
+			// initiated, but the intent can't be canceled.
+			for id, intent := range store.swapIntents {
+				if intent.Type == swap.TypeIn {
+					_, cancelErr = s.cancel(id)
+				}
+			}
+
+			return nil, nil, errors.New("swap amount too low")
+		},
+	})
+
+	channel := uint64(5)
+	loopOutIntent := &loopdb.SwapIntent{
+		Trigger: loopdb.IntentTrigger{
+			MaxFeeRate:     2500,
+			FeeConfTarget:  6,
+			DeadlineHeight: 610,
+		},
+		SwapRequest: loopdb.SwapRequest{
+			Type:       swap.TypeOut,
+			Amount:     2000000,
+			DestAddr:   testAddr,
+			MaxSwapFee: 5000,
+			ConfTarget: 2,
+			Channel:    &channel,
+		},
+	}
+	loopInIntent := &loopdb.SwapIntent{
+		Trigger: loopdb.IntentTrigger{
+			NotBeforeHeight: 605,
+		},
+		SwapRequest: loopdb.SwapRequest{
+			Type:       swap.TypeIn,
+			Amount:     500000,
+			ConfTarget: 6,
+		},
+	}
+	canceledIntent := &loopdb.SwapIntent{
+		SwapRequest: loopdb.SwapRequest{
+			Type:     swap.TypeOut,
+			Amount:   100000,
+			DestAddr: testAddr,
+		},
+	}
+
+	for _, intent := range []*loopdb.SwapIntent{
+		loopOutIntent, loopInIntent, canceledIntent,
+	} {
+		if err := s.schedule(intent); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := s.cancel(canceledIntent.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Canceling twice or canceling an unknown intent fails.
+	_, err = s.cancel(canceledIntent.ID)
+	if err != ErrIntentNotPending {
+		t.Fatalf("expected intent not pending, got %v", err)
+	}
+	_, err = s.cancel(loopdb.IntentID{9})
+	if err != loopdb.ErrSwapIntentNotFound {
+		t.Fatalf("expected intent not found, got %v", err)
+	}
+
+	// An intent with a fee rate condition needs a confirmation target.
+	err = s.schedule(&loopdb.SwapIntent{
+		Trigger: loopdb.IntentTrigger{
+			MaxFeeRate: 2500,
+		},
+		SwapRequest: loopdb.SwapRequest{
+			Type: swap.TypeIn,
+		},
+	})
+	if err != ErrNoFeeConfTarget {
+		t.Fatalf("expected missing conf target, got %v", err)
+	}
+
+	assertState := func(intent *loopdb.SwapIntent,
+		expected loopdb.IntentState) *loopdb.SwapIntent {
+
+		t.Helper()
+
+		stored := store.swapIntents[intent.ID]
+		if stored.State != expected {
+			t.Fatalf("expected intent state %v, got %v", expected,
+				stored.State)
+		}
+
+		return stored
+	}
+
+	// Nothing triggers while the fee rate is too high and the start
+	// height isn't reached.
+	s.evaluate(context.Background(), 600)
+	if len(loopOuts) != 0 || len(loopIns) != 0 {
+		t.Fatal("expected no swaps")
+	}
+	assertState(loopOutIntent, loopdb.IntentStatePending)
+	assertState(loopInIntent, loopdb.IntentStatePending)
+
+	// Once the start height is reached, the loop in is launched. It fails
+	// and the error is recorded.
+	s.evaluate(context.Background(), 605)
+	if len(loopIns) != 1 || loopIns[0].Amount != 500000 ||
+		loopIns[0].HtlcConfTarget != 6 {
+
+		t.Fatalf("unexpected loop in requests: %v", loopIns)
+	}
+	stored := assertState(loopInIntent, loopdb.IntentStateFailed)
+	if stored.Error != "swap amount too low" {
+		t.Fatalf("unexpected error: %v", stored.Error)
+	}
+	if cancelErr != ErrIntentNotPending {
+		t.Fatalf("expected launching intent not to be canceled, got %v",
+			cancelErr)
+	}
+
+	// When the fee rate drops, the loop out is launched.
+	lnd.SetFeeEstimate(6, 2000)
+	s.evaluate(context.Background(), 606)
+	if len(loopOuts) != 1 {
+		t.Fatalf("expected one loop out, got %v", len(loopOuts))
+	}
+	req := loopOuts[0]
+	if req.Amount != 2000000 || req.DestAddr != testAddr ||
+		req.MaxSwapFee != 5000 || req.SweepConfTarget != 2 ||
+		*req.LoopOutChannel != channel {
+
+		t.Fatalf("unexpected loop out request: %v", req)
+	}
+	stored = assertState(loopOutIntent, loopdb.IntentStateLaunched)
+	if stored.SwapHash != swapHash {
+		t.Fatalf("unexpected swap hash: %v", stored.SwapHash)
+	}
+	assertState(canceledIntent, loopdb.IntentStateCanceled)
+
+	// Launched intents are not launched again.
+	s.evaluate(context.Background(), 620)
+	if len(loopOuts) != 1 || len(loopIns) != 1 {
+		t.Fatal("expected no new swaps")
+	}
+}
@@ -7,7 +7,10 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/swap"
 	"github.com/lightninglabs/loop/test"
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/lnwire"
@@ -23,6 +26,9 @@ var (
 	testFixedPrepayAmount       = btcutil.Amount(100)
 	testMinSwapAmount           = btcutil.Amount(10000)
 	testMaxSwapAmount           = btcutil.Amount(1000000)
+
+	// testServerID is the id of the default server in the tests.
+	testServerID = "test"
 )
 
 // serverMock is used in client unit tests to simulate swap server behaviour.
@@ -35,6 +41,40 @@ type serverMock struct {
 
 	swapInvoice string
 	swapHash    lntypes.Hash
+
+	// swapFee, quoteID and quoteExpiry are returned with every quote.
+	swapFee     btcutil.Amount
+	quoteID     []byte
+	quoteExpiry time.Time
+
+	// termsErr is returned by the terms calls if set.
+	termsErr error
+
+	// swapQuoteID is the quote id of the last swap request.
+	swapQuoteID []byte
+
+	// htlcVersion is the htlc script version that is returned for new
+	// swaps.
+	htlcVersion swap.ScriptVersion
+
+	// loopInHtlc is the htlc of the last loop in swap.
+	loopInHtlc *swap.Htlc
+
+	// coopRefundErr is returned by CooperativeLoopInRefund if set.
+	coopRefundErr error
+
+	// coopRefundTx is the last transaction that the server was asked to
+	// co-sign.
+	coopRefundTx *wire.MsgTx
+
+	// updates and updateErrs deliver the server updates and errors of
+	// all subscriptions.
+	updates    chan *ServerUpdate
+	updateErrs chan error
+
+	// subscriptions receives the swap hashes of update subscriptions if
+	// there is room in its buffer.
+	subscriptions chan []lntypes.Hash
 }
 
 var _ swapServerClient = (*serverMock)(nil)
@@ -47,17 +87,28 @@ func newServerMock() *serverMock {
 		swapInvoiceAmt:   50950,
 		prepayInvoiceAmt: 100,
 
-		height: 600,
+		height:  600,
+		swapFee: testSwapFee,
+
+		// Default to the script version that the fake success
+		// witnesses in the tests are valid for.
+		htlcVersion: swap.HtlcScriptV1,
+
+		updates:       make(chan *ServerUpdate),
+		updateErrs:    make(chan error),
+		subscriptions: make(chan []lntypes.Hash, 1),
 	}
 }
 
 func (s *serverMock) NewLoopOutSwap(ctx context.Context,
 	swapHash lntypes.Hash, amount btcutil.Amount,
-	receiverKey [33]byte, _ time.Time) (
+	receiverKey [33]byte, _ time.Time, quoteID []byte) (
 	*newLoopOutResponse, error) {
 
 	_, senderKey := test.CreateKey(100)
 
+	s.swapQuoteID = quoteID
+
 	if amount != s.expectedSwapAmt {
 		return nil, errors.New("unexpected test swap amount")
 	}
@@ -82,12 +133,17 @@ func (s *serverMock) NewLoopOutSwap(ctx context.Context,
 		swapInvoice:   swapPayReqString,
 		prepayInvoice: prePayReqString,
 		expiry:        s.height + testLoopOutOnChainCltvDelta,
+		htlcVersion:   s.htlcVersion,
 	}, nil
 }
 
 func (s *serverMock) GetLoopOutTerms(ctx context.Context) (
 	*LoopOutTerms, error) {
 
+	if s.termsErr != nil {
+		return nil, s.termsErr
+	}
+
 	return &LoopOutTerms{
 		MinSwapAmount: testMinSwapAmount,
 		MaxSwapAmount: testMaxSwapAmount,
@@ -100,10 +156,12 @@ func (s *serverMock) GetLoopOutQuote(ctx context.Context, amt btcutil.Amount,
 	dest := [33]byte{1, 2, 3}
 
 	return &LoopOutQuote{
-		SwapFee:         testSwapFee,
+		SwapFee:         s.swapFee,
 		SwapPaymentDest: dest,
 		CltvDelta:       testLoopOutOnChainCltvDelta,
 		PrepayAmount:    testFixedPrepayAmount,
+		QuoteID:         s.quoteID,
+		QuoteExpiry:     s.quoteExpiry,
 	}, nil
 }
 
@@ -127,11 +185,13 @@ func getInvoice(hash lntypes.Hash, amt btcutil.Amount, memo string) (string, err
 
 func (s *serverMock) NewLoopInSwap(ctx context.Context,
 	swapHash lntypes.Hash, amount btcutil.Amount,
-	senderKey [33]byte, swapInvoice string) (
-	*newLoopInResponse, error) {
+	senderKey [33]byte, swapInvoice string, quoteID []byte,
+	htlcType swap.HtlcOutputType) (*newLoopInResponse, error) {
 
 	_, receiverKey := test.CreateKey(101)
 
+	s.swapQuoteID = quoteID
+
 	if amount != s.expectedSwapAmt {
 		return nil, errors.New("unexpected test swap amount")
 	}
@@ -145,14 +205,58 @@ func (s *serverMock) NewLoopInSwap(ctx context.Context,
 	resp := &newLoopInResponse{
 		expiry:      s.height + testChargeOnChainCltvDelta,
 		receiverKey: receiverKeyArray,
+		htlcType:    htlcType,
+		htlcVersion: s.htlcVersion,
+	}
+
+	htlc, err := swap.NewHtlc(
+		resp.htlcVersion, resp.expiry, senderKey, receiverKeyArray,
+		swapHash, htlcType, &chaincfg.TestNet3Params,
+	)
+	if err != nil {
+		return nil, err
 	}
+	s.loopInHtlc = htlc
 
 	return resp, nil
 }
 
+func (s *serverMock) CooperativeLoopInRefund(ctx context.Context,
+	swapHash lntypes.Hash, refundTx *wire.MsgTx,
+	htlcValue btcutil.Amount) ([]byte, error) {
+
+	if s.coopRefundErr != nil {
+		return nil, s.coopRefundErr
+	}
+
+	if s.loopInHtlc == nil || swapHash != s.swapHash {
+		return nil, errors.New("unknown swap")
+	}
+
+	s.coopRefundTx = refundTx
+
+	receiverKey, _ := test.CreateKey(101)
+
+	sig, err := txscript.RawTxInWitnessSignature(
+		refundTx, txscript.NewTxSigHashes(refundTx), 0,
+		int64(htlcValue), s.loopInHtlc.Script, txscript.SigHashAll,
+		receiverKey,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Strip the sighash flag.
+	return sig[:len(sig)-1], nil
+}
+
 func (s *serverMock) GetLoopInTerms(ctx context.Context) (
 	*LoopInTerms, error) {
 
+	if s.termsErr != nil {
+		return nil, s.termsErr
+	}
+
 	return &LoopInTerms{
 		MinSwapAmount: testMinSwapAmount,
 		MaxSwapAmount: testMaxSwapAmount,
@@ -163,7 +267,21 @@ func (s *serverMock) GetLoopInQuote(ctx context.Context, amt btcutil.Amount) (
 	*LoopInQuote, error) {
 
 	return &LoopInQuote{
-		SwapFee:   testSwapFee,
-		CltvDelta: testChargeOnChainCltvDelta,
+		SwapFee:     s.swapFee,
+		CltvDelta:   testChargeOnChainCltvDelta,
+		QuoteID:     s.quoteID,
+		QuoteExpiry: s.quoteExpiry,
 	}, nil
 }
+
+func (s *serverMock) SubscribeUpdates(ctx context.Context,
+	swapHashes []lntypes.Hash) (<-chan *ServerUpdate, <-chan error,
+	error) {
+
+	select {
+	case s.subscriptions <- swapHashes:
+	default:
+	}
+
+	return s.updates, s.updateErrs, nil
+}
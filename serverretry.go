@@ -0,0 +1,423 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// ErrServerUnavailable is returned without contacting the server while
+	// its circuit breaker is open because of repeated failed calls.
+	ErrServerUnavailable = errors.New("swap server unavailable, try " +
+		"again later")
+
+	// defaultServerRetryPolicy is the retry policy that is used for calls
+	// to the swap servers.
+	defaultServerRetryPolicy = serverRetryPolicy{
+		maxAttempts:      3,
+		backoff:          time.Second,
+		maxBackoff:       10 * time.Second,
+		failureThreshold: 5,
+		cooldown:         30 * time.Second,
+	}
+)
+
+// serverRetryPolicy describes how calls to a swap server that fail because of
+// a transient error are retried and when the server is considered down.
+type serverRetryPolicy struct {
+	// maxAttempts is the maximum number of attempts for each call.
+	maxAttempts int
+
+	// backoff is the delay before the first retry. The delay is doubled
+	// for every following retry and randomized by up to half of its
+	// value, so that clients don't retry in lockstep.
+	backoff time.Duration
+
+	// maxBackoff caps the delay between two attempts.
+	maxBackoff time.Duration
+
+	// failureThreshold is the number of consecutive failed attempts after
+	// which the circuit breaker opens.
+	failureThreshold int
+
+	// cooldown is the time during which calls fail fast once the circuit
+	// breaker opened. After the cooldown, a single call is let through to
+	// probe the server.
+	cooldown time.Duration
+}
+
+// delay returns the randomized delay before the given retry, starting at one.
+func (p *serverRetryPolicy) delay(retry int) time.Duration {
+	delay := p.backoff
+	for i := 1; i < retry && delay < p.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > p.maxBackoff {
+		delay = p.maxBackoff
+	}
+
+	half := int64(delay / 2)
+	if half <= 0 {
+		return delay
+	}
+	return time.Duration(half + rand.Int63n(half+1))
+}
+
+// ServerState describes the health of the connection to a swap server.
+type ServerState uint8
+
+const (
+	// ServerStateOnline indicates that the last call to the server
+	// succeeded.
+	ServerStateOnline ServerState = iota
+
+	// ServerStateDegraded indicates that recent calls to the server failed,
+	// but not often enough to consider it down.
+	ServerStateDegraded
+
+	// ServerStateOffline indicates that the circuit breaker of the server
+	// is open. Calls fail without contacting the server until the
+	// cooldown has passed and a probing call succeeds.
+	ServerStateOffline
+)
+
+// String returns a string representation of the server state.
+func (s ServerState) String() string {
+	switch s {
+	case ServerStateOnline:
+		return "Online"
+
+	case ServerStateDegraded:
+		return "Degraded"
+
+	case ServerStateOffline:
+		return "Offline"
+
+	default:
+		return "Unknown"
+	}
+}
+
+// ServerHealth is the connection health of a swap server.
+type ServerHealth struct {
+	// ServerID identifies the server.
+	ServerID string
+
+	// State is the current state of the connection.
+	State ServerState
+
+	// ConsecutiveFailures is the number of attempts that failed since the
+	// last successful call.
+	ConsecutiveFailures int
+
+	// LastError is the error of the last failed attempt, if any.
+	LastError error
+
+	// LastSuccess is the time of the last successful call. It is zero if
+	// no call succeeded yet.
+	LastSuccess time.Time
+
+	// RetryAt is the time at which calls are let through again. It is only
+	// set if the server is offline.
+	RetryAt time.Time
+}
+
+// circuitBreaker tracks the outcome of the calls to a server and fails calls
+// fast while the server appears to be down.
+type circuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu          sync.Mutex
+	failures    int
+	lastErr     error
+	lastSuccess time.Time
+	openUntil   time.Time
+	probing     bool
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+// allow returns ErrServerUnavailable if a call must not be made. Once the
+// cooldown passed, only one call at a time is allowed until a call succeeds.
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < b.threshold {
+		return nil
+	}
+
+	if b.probing || b.now().Before(b.openUntil) {
+		return ErrServerUnavailable
+	}
+
+	b.probing = true
+	return nil
+}
+
+// record registers the outcome of an allowed call. Errors that are not
+// transient show that the server is reachable and count as success.
+func (b *circuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+
+	if !isTransientError(err) {
+		b.failures = 0
+		b.lastSuccess = b.now()
+		return
+	}
+
+	b.failures++
+	b.lastErr = err
+	if b.failures >= b.threshold {
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// release ends an allowed call without recording its outcome.
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// health returns the current connection health.
+func (b *circuitBreaker) health() ServerHealth {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	health := ServerHealth{
+		ConsecutiveFailures: b.failures,
+		LastSuccess:         b.lastSuccess,
+	}
+
+	switch {
+	case b.failures >= b.threshold:
+		health.State = ServerStateOffline
+		health.RetryAt = b.openUntil
+
+	case b.failures > 0:
+		health.State = ServerStateDegraded
+
+	default:
+		health.State = ServerStateOnline
+	}
+
+	if b.failures > 0 {
+		health.LastError = b.lastErr
+	}
+
+	return health
+}
+
+// isTransientError returns whether a call failed because the server could not
+// be reached or was temporarily unable to process it.
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	rpcStatus, ok := status.FromError(err)
+	if !ok {
+		return false
+	}
+
+	switch rpcStatus.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded,
+		codes.ResourceExhausted, codes.Aborted:
+
+		return true
+
+	default:
+		return false
+	}
+}
+
+// retryServerClient wraps a swap server client and retries calls that failed
+// because of a transient error. Terms, quotes and refund signatures can be
+// requested any number of times. The initiation of a swap is only retried
+// because the server recognizes a repeated request by the idempotency key
+// that is sent along, which is the swap hash. All calls are guarded by a
+// circuit breaker.
+type retryServerClient struct {
+	swapServerClient
+
+	policy  serverRetryPolicy
+	breaker *circuitBreaker
+}
+
+var _ swapServerClient = (*retryServerClient)(nil)
+
+func newRetryServerClient(client swapServerClient,
+	policy serverRetryPolicy) *retryServerClient {
+
+	return &retryServerClient{
+		swapServerClient: client,
+		policy:           policy,
+		breaker: newCircuitBreaker(
+			policy.failureThreshold, policy.cooldown,
+		),
+	}
+}
+
+// call executes f until it succeeds, fails with an error that is not
+// transient or the retry policy is exhausted.
+func (c *retryServerClient) call(ctx context.Context, f func() error) error {
+	for attempt := 1; ; attempt++ {
+		if err := c.breaker.allow(); err != nil {
+			return err
+		}
+
+		err := f()
+
+		// A call that was canceled by the caller tells nothing about
+		// the server.
+		if ctx.Err() != nil {
+			c.breaker.release()
+			return err
+		}
+
+		c.breaker.record(err)
+		if !isTransientError(err) || attempt >= c.policy.maxAttempts {
+			return err
+		}
+
+		delay := c.policy.delay(attempt)
+		log.Debugf("Server call failed (attempt %v): %v, retrying "+
+			"in %v", attempt, err, delay)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+func (c *retryServerClient) GetLoopOutTerms(ctx context.Context) (
+	*LoopOutTerms, error) {
+
+	var terms *LoopOutTerms
+	err := c.call(ctx, func() error {
+		var err error
+		terms, err = c.swapServerClient.GetLoopOutTerms(ctx)
+		return err
+	})
+
+	return terms, err
+}
+
+func (c *retryServerClient) GetLoopOutQuote(ctx context.Context,
+	amt btcutil.Amount, swapPublicationDeadline time.Time) (
+	*LoopOutQuote, error) {
+
+	var quote *LoopOutQuote
+	err := c.call(ctx, func() error {
+		var err error
+		quote, err = c.swapServerClient.GetLoopOutQuote(
+			ctx, amt, swapPublicationDeadline,
+		)
+		return err
+	})
+
+	return quote, err
+}
+
+func (c *retryServerClient) GetLoopInTerms(ctx context.Context) (
+	*LoopInTerms, error) {
+
+	var terms *LoopInTerms
+	err := c.call(ctx, func() error {
+		var err error
+		terms, err = c.swapServerClient.GetLoopInTerms(ctx)
+		return err
+	})
+
+	return terms, err
+}
+
+func (c *retryServerClient) GetLoopInQuote(ctx context.Context,
+	amt btcutil.Amount) (*LoopInQuote, error) {
+
+	var quote *LoopInQuote
+	err := c.call(ctx, func() error {
+		var err error
+		quote, err = c.swapServerClient.GetLoopInQuote(ctx, amt)
+		return err
+	})
+
+	return quote, err
+}
+
+func (c *retryServerClient) NewLoopOutSwap(ctx context.Context,
+	swapHash lntypes.Hash, amount btcutil.Amount, receiverKey [33]byte,
+	swapPublicationDeadline time.Time, quoteID []byte) (
+	*newLoopOutResponse, error) {
+
+	var resp *newLoopOutResponse
+	err := c.call(ctx, func() error {
+		var err error
+		resp, err = c.swapServerClient.NewLoopOutSwap(
+			ctx, swapHash, amount, receiverKey,
+			swapPublicationDeadline, quoteID,
+		)
+		return err
+	})
+
+	return resp, err
+}
+
+func (c *retryServerClient) NewLoopInSwap(ctx context.Context,
+	swapHash lntypes.Hash, amount btcutil.Amount, senderKey [33]byte,
+	swapInvoice string, quoteID []byte, htlcType swap.HtlcOutputType) (
+	*newLoopInResponse, error) {
+
+	var resp *newLoopInResponse
+	err := c.call(ctx, func() error {
+		var err error
+		resp, err = c.swapServerClient.NewLoopInSwap(
+			ctx, swapHash, amount, senderKey, swapInvoice,
+			quoteID, htlcType,
+		)
+		return err
+	})
+
+	return resp, err
+}
+
+func (c *retryServerClient) CooperativeLoopInRefund(ctx context.Context,
+	swapHash lntypes.Hash, refundTx *wire.MsgTx,
+	htlcValue btcutil.Amount) ([]byte, error) {
+
+	var sig []byte
+	err := c.call(ctx, func() error {
+		var err error
+		sig, err = c.swapServerClient.CooperativeLoopInRefund(
+			ctx, swapHash, refundTx, htlcValue,
+		)
+		return err
+	})
+
+	return sig, err
+}
@@ -0,0 +1,151 @@
+package loop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lightninglabs/loop/test"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// flakyServer is a server mock that fails terms requests with the queued
+// errors before it serves them.
+type flakyServer struct {
+	*serverMock
+
+	errs  []error
+	calls int
+}
+
+func (s *flakyServer) GetLoopOutTerms(ctx context.Context) (
+	*LoopOutTerms, error) {
+
+	s.calls++
+	if len(s.errs) > 0 {
+		err := s.errs[0]
+		s.errs = s.errs[1:]
+		return nil, err
+	}
+
+	return s.serverMock.GetLoopOutTerms(ctx)
+}
+
+// TestServerRetry tests that transient errors are retried, that the circuit
+// breaker fails calls fast once the server is considered down and that it
+// lets a probing call through after the cooldown.
+func TestServerRetry(t *testing.T) {
+	defer test.Guard(t)()
+
+	server := &flakyServer{serverMock: newServerMock()}
+	client := newRetryServerClient(server, serverRetryPolicy{
+		maxAttempts:      3,
+		backoff:          time.Millisecond,
+		maxBackoff:       2 * time.Millisecond,
+		failureThreshold: 4,
+		cooldown:         time.Minute,
+	})
+
+	now := time.Now()
+	client.breaker.now = func() time.Time {
+		return now
+	}
+
+	ctx := context.Background()
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	expectCall := func(expectedErr error, expectedCalls int,
+		expectedState ServerState) {
+
+		t.Helper()
+
+		server.calls = 0
+		_, err := client.GetLoopOutTerms(ctx)
+		if err != expectedErr {
+			t.Fatalf("expected error %v, got %v", expectedErr, err)
+		}
+		if server.calls != expectedCalls {
+			t.Fatalf("expected %v calls, got %v", expectedCalls,
+				server.calls)
+		}
+		state := client.breaker.health().State
+		if state != expectedState {
+			t.Fatalf("expected state %v, got %v", expectedState,
+				state)
+		}
+	}
+
+	// Transient errors are retried until the call succeeds.
+	server.errs = []error{unavailable, unavailable}
+	expectCall(nil, 3, ServerStateOnline)
+
+	// Errors that are not transient are returned right away and show that
+	// the server is reachable.
+	invalid := status.Error(codes.InvalidArgument, "invalid")
+	server.errs = []error{invalid}
+	expectCall(invalid, 1, ServerStateOnline)
+
+	// A call that fails on every attempt degrades the server.
+	server.errs = []error{unavailable, unavailable, unavailable}
+	expectCall(unavailable, 3, ServerStateDegraded)
+
+	// Once the failure threshold is reached, the breaker opens and stops
+	// the retries.
+	server.errs = []error{unavailable, unavailable}
+	expectCall(ErrServerUnavailable, 1, ServerStateOffline)
+
+	health := client.breaker.health()
+	if health.ConsecutiveFailures != 4 || health.LastError != unavailable ||
+		!health.RetryAt.Equal(now.Add(time.Minute)) {
+
+		t.Fatalf("unexpected health %+v", health)
+	}
+
+	// While the breaker is open, calls fail without contacting the server.
+	expectCall(ErrServerUnavailable, 0, ServerStateOffline)
+
+	// After the cooldown, a probing call is let through. If it fails, the
+	// breaker opens again.
+	now = now.Add(time.Minute)
+	expectCall(ErrServerUnavailable, 1, ServerStateOffline)
+	expectCall(ErrServerUnavailable, 0, ServerStateOffline)
+
+	// A successful probe closes the breaker.
+	now = now.Add(time.Minute)
+	expectCall(nil, 1, ServerStateOnline)
+
+	health = client.breaker.health()
+	if health.ConsecutiveFailures != 0 || health.LastError != nil ||
+		!health.LastSuccess.Equal(now) {
+
+		t.Fatalf("unexpected health %+v", health)
+	}
+}
+
+// TestServerRetryDelay tests that the retry delay grows exponentially up to
+// the maximum and is randomized within half of its value.
+func TestServerRetryDelay(t *testing.T) {
+	policy := serverRetryPolicy{
+		backoff:    time.Second,
+		maxBackoff: 10 * time.Second,
+	}
+
+	for retry, expected := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 8 * time.Second,
+		5: 10 * time.Second,
+		9: 10 * time.Second,
+	} {
+		for i := 0; i < 100; i++ {
+			delay := policy.delay(retry)
+			if delay < expected/2 || delay > expected {
+				t.Fatalf("retry %v: delay %v not within "+
+					"[%v, %v]", retry, delay, expected/2,
+					expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,514 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/lndclient"
+	"github.com/lightninglabs/loop/lsat"
+	"github.com/lightningnetwork/lnd/tor"
+)
+
+var (
+	// ErrUnknownServer is returned when a request references a swap
+	// server that is not configured.
+	ErrUnknownServer = errors.New("unknown swap server")
+
+	// ErrQuoteServerMismatch is returned when a swap request references a
+	// quote of a different server than the one requested.
+	ErrQuoteServerMismatch = errors.New("quote was issued by a different " +
+		"swap server")
+
+	// serverLsatDir is the directory below the client directory that
+	// holds the LSAT stores of all servers but the default server.
+	serverLsatDir = "servers"
+)
+
+// ServerConfig describes the connection to a swap server.
+type ServerConfig struct {
+	// ID identifies the server. It is stored in the contract of every
+	// swap with this server, so it must not change while swaps are
+	// pending.
+	ID string
+
+	// Address is the host:port of the server.
+	Address string
+
+	// Insecure disables tls for the connection to the server.
+	Insecure bool
+
+	// TLSPath optionally specifies the tls certificate of the server.
+	TLSPath string
+
+	// Proxy is the optional host:port of a SOCKS5 proxy, such as Tor,
+	// that connections to the server are made through. It is required to
+	// connect to an onion address.
+	Proxy string
+
+	// StreamIsolation makes every connection through the proxy use its
+	// own Tor circuit.
+	StreamIsolation bool
+}
+
+// swapServer is a connection to one of the configured swap servers.
+type swapServer struct {
+	swapServerClient
+
+	// id identifies the server in the contracts of its swaps.
+	id string
+
+	// lsatStore holds the LSAT tokens that were obtained from the server.
+	lsatStore lsat.Store
+
+	// breaker tracks the connection health of the server. It is nil if
+	// the calls to the server are not guarded by a circuit breaker.
+	breaker *circuitBreaker
+}
+
+// validateServerConfigs checks that at least one server is configured and
+// that all server ids are unique and can be used as a directory name.
+func validateServerConfigs(servers []ServerConfig) error {
+	if len(servers) == 0 {
+		return errors.New("no swap server configured")
+	}
+
+	ids := make(map[string]struct{}, len(servers))
+	for _, server := range servers {
+		if server.ID == "" {
+			return fmt.Errorf("swap server %v has no id",
+				server.Address)
+		}
+
+		for _, c := range server.ID {
+			valid := c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' ||
+				c >= '0' && c <= '9' || c == '-' || c == '_'
+			if !valid {
+				return fmt.Errorf("invalid swap server id %q",
+					server.ID)
+			}
+		}
+
+		if _, ok := ids[server.ID]; ok {
+			return fmt.Errorf("duplicate swap server id %v",
+				server.ID)
+		}
+		ids[server.ID] = struct{}{}
+
+		if server.Address == "" {
+			return fmt.Errorf("swap server %v has no address",
+				server.ID)
+		}
+
+		// Onion addresses can only be reached through Tor.
+		host, _, err := net.SplitHostPort(server.Address)
+		if err == nil && tor.IsOnionHost(host) && server.Proxy == "" {
+			return fmt.Errorf("swap server %v has an onion "+
+				"address, but no proxy is configured",
+				server.ID)
+		}
+	}
+
+	return nil
+}
+
+// connectServers connects to all configured servers and returns them along
+// with a function that closes the connections. The default server keeps its
+// LSAT store in the client directory, so that existing tokens remain valid.
+// The other servers each get their own directory.
+func connectServers(dbDir string, servers []ServerConfig,
+	lnd *lndclient.LndServices, maxLSATCost,
+	maxLSATFee btcutil.Amount) ([]*swapServer, func(), error) {
+
+	connected := make([]*swapServer, 0, len(servers))
+	conns := make([]*grpcSwapServerClient, 0, len(servers))
+	closeAll := func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}
+
+	for i, cfg := range servers {
+		lsatDir := dbDir
+		if i > 0 {
+			lsatDir = filepath.Join(dbDir, serverLsatDir, cfg.ID)
+		}
+
+		lsatStore, err := lsat.NewFileStore(lsatDir)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+
+		client, err := newSwapServerClient(
+			&servers[i], lsatStore, lnd, maxLSATCost, maxLSATFee,
+		)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("swap server %v: %v",
+				cfg.ID, err)
+		}
+		conns = append(conns, client)
+
+		retryClient := newRetryServerClient(
+			client, defaultServerRetryPolicy,
+		)
+		connected = append(connected, &swapServer{
+			swapServerClient: retryClient,
+			id:               cfg.ID,
+			lsatStore:        lsatStore,
+			breaker:          retryClient.breaker,
+		})
+	}
+
+	return connected, closeAll, nil
+}
+
+// getServer returns the server with the given id. An empty id refers to the
+// default server, which is the first configured server.
+func (s *Client) getServer(id string) (*swapServer, error) {
+	if id == "" {
+		return s.Servers[0], nil
+	}
+
+	for _, server := range s.Servers {
+		if server.id == id {
+			return server, nil
+		}
+	}
+
+	return nil, ErrUnknownServer
+}
+
+// requestServers returns the servers that a request may be executed with. If
+// the request names a server, only that server is returned.
+func (s *Client) requestServers(id string) ([]*swapServer, error) {
+	if id == "" {
+		return s.Servers, nil
+	}
+
+	server, err := s.getServer(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*swapServer{server}, nil
+}
+
+// newSwapConfig returns the configuration of a swap with the given server.
+func (s *Client) newSwapConfig(server *swapServer) *swapConfig {
+	return &swapConfig{
+		lnd:      s.lndServices,
+		store:    s.Store,
+		server:   server,
+		serverID: server.id,
+	}
+}
+
+// LsatStores returns the LSAT stores of all configured servers by server id.
+func (s *Client) LsatStores() map[string]lsat.Store {
+	stores := make(map[string]lsat.Store, len(s.Servers))
+	for _, server := range s.Servers {
+		stores[server.id] = server.lsatStore
+	}
+
+	return stores
+}
+
+// ServerHealth returns the connection health of all configured servers.
+// Servers without circuit breaker are reported as online.
+func (s *Client) ServerHealth() []ServerHealth {
+	health := make([]ServerHealth, 0, len(s.Servers))
+	for _, server := range s.Servers {
+		serverHealth := ServerHealth{State: ServerStateOnline}
+		if server.breaker != nil {
+			serverHealth = server.breaker.health()
+		}
+		serverHealth.ServerID = server.id
+
+		health = append(health, serverHealth)
+	}
+
+	return health
+}
+
+// bestLoopOutQuote quotes a loop out with every server that the request
+// allows and returns the server with the lowest total cost along with its
+// part amounts and quotes. Servers that reject the amount or fail to quote
+// are skipped. If no server is eligible, the error of the first server is
+// returned.
+func (s *Client) bestLoopOutQuote(ctx context.Context,
+	request *LoopOutQuoteRequest) (*swapServer, []btcutil.Amount,
+	[]*LoopOutQuote, error) {
+
+	servers, err := s.requestServers(request.ServerID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		best       *swapServer
+		bestParts  []btcutil.Amount
+		bestQuotes []*LoopOutQuote
+		bestCost   btcutil.Amount
+		firstErr   error
+	)
+	for _, server := range servers {
+		parts, quotes, err := s.quoteLoopOut(ctx, server, request)
+		if err != nil {
+			log.Debugf("Loop out quote of server %v: %v",
+				server.id, err)
+
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		cost := loopOutCost(quotes)
+		if best != nil && cost >= bestCost {
+			continue
+		}
+
+		best, bestParts, bestQuotes, bestCost = server, parts, quotes,
+			cost
+	}
+
+	if best == nil {
+		return nil, nil, nil, firstErr
+	}
+
+	if len(servers) > 1 {
+		log.Infof("Selected server %v for loop out of %v at cost %v",
+			best.id, request.Amount, bestCost)
+	}
+
+	return best, bestParts, bestQuotes, nil
+}
+
+// quoteLoopOut quotes a loop out with a single server. Amounts above the
+// server maximum are split into parts if the request allows it.
+func (s *Client) quoteLoopOut(ctx context.Context, server *swapServer,
+	request *LoopOutQuoteRequest) ([]btcutil.Amount, []*LoopOutQuote,
+	error) {
+
+	terms, err := server.GetLoopOutTerms(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if request.Amount < terms.MinSwapAmount {
+		return nil, nil, ErrSwapAmountTooLow
+	}
+
+	if request.Amount > terms.MaxSwapAmount {
+		if !request.Split {
+			return nil, nil, ErrSwapAmountTooHigh
+		}
+
+		return s.splitLoopOutQuote(ctx, server, request, terms)
+	}
+
+	quote, err := s.getLoopOutQuote(ctx, server, request.Amount, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []btcutil.Amount{request.Amount}, []*LoopOutQuote{quote}, nil
+}
+
+// loopOutCost returns the total estimated cost of the given loop out quotes.
+func loopOutCost(quotes []*LoopOutQuote) btcutil.Amount {
+	var cost btcutil.Amount
+	for _, quote := range quotes {
+		cost += quote.SwapFee + quote.MinerFee + quote.SwapRoutingFee +
+			quote.PrepayRoutingFee
+	}
+
+	return cost
+}
+
+// bestLoopInQuote is the loop in counterpart of bestLoopOutQuote.
+func (s *Client) bestLoopInQuote(ctx context.Context,
+	request *LoopInQuoteRequest) (*swapServer, []btcutil.Amount,
+	[]*LoopInQuote, error) {
+
+	servers, err := s.requestServers(request.ServerID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var (
+		best       *swapServer
+		bestParts  []btcutil.Amount
+		bestQuotes []*LoopInQuote
+		bestCost   btcutil.Amount
+		firstErr   error
+	)
+	for _, server := range servers {
+		parts, quotes, err := s.quoteLoopIn(ctx, server, request)
+		if err != nil {
+			log.Debugf("Loop in quote of server %v: %v",
+				server.id, err)
+
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+
+		cost := loopInCost(quotes)
+		if best != nil && cost >= bestCost {
+			continue
+		}
+
+		best, bestParts, bestQuotes, bestCost = server, parts, quotes,
+			cost
+	}
+
+	if best == nil {
+		return nil, nil, nil, firstErr
+	}
+
+	if len(servers) > 1 {
+		log.Infof("Selected server %v for loop in of %v at cost %v",
+			best.id, request.Amount, bestCost)
+	}
+
+	return best, bestParts, bestQuotes, nil
+}
+
+// quoteLoopIn quotes a loop in with a single server. Amounts above the server
+// maximum are split into parts if the request allows it.
+func (s *Client) quoteLoopIn(ctx context.Context, server *swapServer,
+	request *LoopInQuoteRequest) ([]btcutil.Amount, []*LoopInQuote,
+	error) {
+
+	terms, err := server.GetLoopInTerms(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if request.Amount < terms.MinSwapAmount {
+		return nil, nil, ErrSwapAmountTooLow
+	}
+
+	if request.Amount > terms.MaxSwapAmount {
+		if !request.Split {
+			return nil, nil, ErrSwapAmountTooHigh
+		}
+
+		return s.splitLoopInQuote(ctx, server, request, terms)
+	}
+
+	quote, err := s.getLoopInQuote(ctx, server, request.Amount, request)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []btcutil.Amount{request.Amount}, []*LoopInQuote{quote}, nil
+}
+
+// loopInCost returns the total estimated cost of the given loop in quotes.
+func loopInCost(quotes []*LoopInQuote) btcutil.Amount {
+	var cost btcutil.Amount
+	for _, quote := range quotes {
+		cost += quote.SwapFee + quote.MinerFee
+	}
+
+	return cost
+}
+
+// loopOutServer returns the server to execute a loop out request with, along
+// with the quote that the swap is checked against. A swap that references a
+// quote is executed with the server that issued the quote, and uses up the
+// quote. Otherwise the
+// requested server is used or, if none is requested and several servers are
+// configured, the cheapest eligible server.
+func (s *Client) loopOutServer(ctx context.Context,
+	request *OutRequest) (*swapServer, *LoopOutQuote, error) {
+
+	if len(request.QuoteID) > 0 {
+		quote, err := s.quotes.takeLoopOut(
+			request.QuoteID, request.Amount, request.ServerID,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		server, err := s.getServer(quote.ServerID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return server, quote, nil
+	}
+
+	if request.ServerID != "" || len(s.Servers) == 1 {
+		server, err := s.getServer(request.ServerID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return server, nil, nil
+	}
+
+	// The selection quote isn't locked at the server, so the swap is only
+	// checked against the limits of the request.
+	server, _, _, err := s.bestLoopOutQuote(ctx, &LoopOutQuoteRequest{
+		Amount:                  request.Amount,
+		SweepConfTarget:         request.SweepConfTarget,
+		SwapPublicationDeadline: request.SwapPublicationDeadline,
+		LoopOutChannel:          request.LoopOutChannel,
+		SweepOutputs:            len(request.SweepOutputs),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return server, nil, nil
+}
+
+// loopInServer is the loop in counterpart of loopOutServer.
+func (s *Client) loopInServer(ctx context.Context,
+	request *LoopInRequest) (*swapServer, *LoopInQuote, error) {
+
+	if len(request.QuoteID) > 0 {
+		quote, err := s.quotes.takeLoopIn(
+			request.QuoteID, request.Amount, request.ServerID,
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		server, err := s.getServer(quote.ServerID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return server, quote, nil
+	}
+
+	if request.ServerID != "" || len(s.Servers) == 1 {
+		server, err := s.getServer(request.ServerID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return server, nil, nil
+	}
+
+	server, _, _, err := s.bestLoopInQuote(ctx, &LoopInQuoteRequest{
+		Amount:         request.Amount,
+		HtlcConfTarget: request.HtlcConfTarget,
+		ExternalHtlc:   request.ExternalHtlc,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return server, nil, nil
+}
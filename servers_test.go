@@ -0,0 +1,154 @@
+package loop
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/test"
+)
+
+// TestServerSelection tests that quotes are requested from all servers, that
+// the cheapest eligible server is selected and that swaps are bound to the
+// server of their quote or contract.
+func TestServerSelection(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+
+	defaultServer := newServerMock()
+	altServer := newServerMock()
+	altServer.swapFee = testSwapFee - 10
+
+	client := newSwapClient(&clientConfig{
+		LndServices: &lnd.LndServices,
+		Store:       newStoreMock(t),
+		Servers: []*swapServer{
+			{swapServerClient: defaultServer, id: testServerID},
+			{swapServerClient: altServer, id: "alt"},
+		},
+	})
+
+	ctx := context.Background()
+	quoteReq := &LoopInQuoteRequest{
+		Amount:       testMinSwapAmount,
+		ExternalHtlc: true,
+	}
+
+	// The cheaper server is selected.
+	quote, err := client.LoopInQuote(ctx, quoteReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.ServerID != "alt" || quote.SwapFee != altServer.swapFee {
+		t.Fatalf("expected quote of alt server, got %v at %v",
+			quote.ServerID, quote.SwapFee)
+	}
+
+	// A requested server is used even if it is more expensive.
+	quoteReq.ServerID = testServerID
+	quote, err = client.LoopInQuote(ctx, quoteReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.ServerID != testServerID || quote.SwapFee != testSwapFee {
+		t.Fatalf("expected quote of default server, got %v at %v",
+			quote.ServerID, quote.SwapFee)
+	}
+
+	quoteReq.ServerID = "unknown"
+	_, err = client.LoopInQuote(ctx, quoteReq)
+	if err != ErrUnknownServer {
+		t.Fatalf("expected unknown server, got %v", err)
+	}
+
+	// A server that fails to quote is skipped.
+	altServer.termsErr = errors.New("unavailable")
+	quoteReq.ServerID = ""
+	quote, err = client.LoopInQuote(ctx, quoteReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if quote.ServerID != testServerID {
+		t.Fatalf("expected quote of default server, got %v",
+			quote.ServerID)
+	}
+
+	// If no server is eligible, the error of the first server is
+	// returned.
+	defaultServer.termsErr = errors.New("down")
+	_, err = client.LoopInQuote(ctx, quoteReq)
+	if err != defaultServer.termsErr {
+		t.Fatalf("expected error of default server, got %v", err)
+	}
+
+	// A swap that references a quote is executed with the server that
+	// issued it.
+	addQuote := func() {
+		client.quotes.addLoopIn(testMinSwapAmount, &LoopInQuote{
+			QuoteID:     []byte{1},
+			QuoteExpiry: time.Now().Add(time.Hour),
+			ServerID:    "alt",
+		})
+	}
+	addQuote()
+	swapReq := &LoopInRequest{
+		Amount:  testMinSwapAmount,
+		QuoteID: []byte{1},
+	}
+	server, _, err := client.loopInServer(ctx, swapReq)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if server.id != "alt" {
+		t.Fatalf("expected alt server, got %v", server.id)
+	}
+
+	// The quote is used up by the swap.
+	_, _, err = client.loopInServer(ctx, swapReq)
+	if err != ErrQuoteNotFound {
+		t.Fatalf("expected quote to be used up, got %v", err)
+	}
+
+	// A rejected swap doesn't use up the quote.
+	addQuote()
+	swapReq.ServerID = testServerID
+	_, _, err = client.loopInServer(ctx, swapReq)
+	if err != ErrQuoteServerMismatch {
+		t.Fatalf("expected quote server mismatch, got %v", err)
+	}
+
+	// Only the quotes of a server that changed its fees are dropped.
+	client.quotes.clearServer(testServerID)
+	if len(client.quotes.loopIn) != 1 {
+		t.Fatal("expected quote of other server to be kept")
+	}
+	client.quotes.clearServer("alt")
+	if len(client.quotes.loopIn) != 0 {
+		t.Fatal("expected quote to be dropped")
+	}
+
+	// Resumed swaps use the server of their contract. Swaps without
+	// server id belong to the default server, and swaps of a server that
+	// is no longer configured are resumed without server.
+	for id, expected := range map[string]swapServerClient{
+		"":           defaultServer,
+		testServerID: defaultServer,
+		"alt":        altServer,
+		"removed":    nil,
+	} {
+		cfg := client.resumeSwapConfig(
+			testPreimage.Hash(), &loopdb.SwapContract{ServerID: id},
+		)
+
+		var server swapServerClient
+		if cfg.server != nil {
+			server = cfg.server.(*swapServer).swapServerClient
+		}
+		if server != expected {
+			t.Fatalf("unexpected server for swap of server %q", id)
+		}
+	}
+}
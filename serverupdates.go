@@ -0,0 +1,252 @@
+package loop
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightningnetwork/lnd/channeldb"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	// serverUpdatesRetryDelay is the time to wait before the subscription
+	// to server updates is renewed after it failed.
+	serverUpdatesRetryDelay = time.Minute
+)
+
+// runServerUpdates forwards the updates of the swaps to the status channel
+// and keeps a subscription to the server updates of the pending swaps with
+// each server. The initial pending swaps are given by server id. Every swap
+// update is also passed to the subscription of the server of the swap.
+func (s *Client) runServerUpdates(ctx context.Context,
+	pendingHashes map[string][]lntypes.Hash, swapUpdates <-chan SwapInfo,
+	statusChan chan<- SwapInfo, serverUpdateChan chan<- ServerUpdate) {
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	serverSwapUpdates := make(map[string]chan SwapInfo, len(s.Servers))
+	for _, server := range s.Servers {
+		updates := make(chan SwapInfo)
+		serverSwapUpdates[server.id] = updates
+
+		wg.Add(1)
+		go func(server *swapServer) {
+			defer wg.Done()
+
+			s.runServerSubscription(
+				ctx, server, pendingHashes[server.id], updates,
+				serverUpdateChan,
+			)
+		}(server)
+	}
+
+	for {
+		select {
+		case info := <-swapUpdates:
+			// Swaps of servers that are no longer configured
+			// aren't subscribed to.
+			server, err := s.getServer(info.ServerID)
+			if err == nil {
+				select {
+				case serverSwapUpdates[server.id] <- info:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			select {
+			case statusChan <- info:
+			case <-ctx.Done():
+				return
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// runServerSubscription keeps a subscription to the server updates of all
+// pending swaps with a single server. The set of pending swaps is learned from
+// the swap updates, and the subscription is renewed whenever a swap becomes
+// pending. Server swap updates are persisted before they are delivered on the
+// server update channel, which may be nil.
+func (s *Client) runServerSubscription(ctx context.Context,
+	server *swapServer, pendingHashes []lntypes.Hash,
+	swapUpdates <-chan SwapInfo, serverUpdateChan chan<- ServerUpdate) {
+
+	pendingSwaps := make(map[lntypes.Hash]struct{})
+	for _, hash := range pendingHashes {
+		pendingSwaps[hash] = struct{}{}
+	}
+
+	var (
+		updateChan  <-chan *ServerUpdate
+		errChan     <-chan error
+		cancelSub   = func() {}
+		retry       <-chan time.Time
+		unsupported bool
+	)
+	defer func() {
+		cancelSub()
+	}()
+
+	// handleErr ends the current subscription. Unless the server doesn't
+	// support the subscription at all, it is retried after a delay.
+	handleErr := func(err error) {
+		cancelSub()
+		updateChan, errChan = nil, nil
+
+		if status.Code(err) == codes.Unimplemented {
+			log.Infof("Server %v doesn't push updates", server.id)
+			unsupported = true
+			return
+		}
+
+		log.Warnf("Update subscription of server %v failed, retrying "+
+			"in %v: %v", server.id, serverUpdatesRetryDelay, err)
+
+		retry = time.After(serverUpdatesRetryDelay)
+	}
+
+	subscribe := func() {
+		cancelSub()
+		updateChan, errChan = nil, nil
+
+		hashes := make([]lntypes.Hash, 0, len(pendingSwaps))
+		for hash := range pendingSwaps {
+			hashes = append(hashes, hash)
+		}
+
+		var subCtx context.Context
+		subCtx, cancelSub = context.WithCancel(ctx)
+
+		var err error
+		updateChan, errChan, err = server.SubscribeUpdates(
+			subCtx, hashes,
+		)
+		if err != nil {
+			handleErr(err)
+		}
+	}
+
+	subscribe()
+
+	for {
+		select {
+		case info := <-swapUpdates:
+			_, known := pendingSwaps[info.SwapHash]
+			pending := info.State.Type() == loopdb.StateTypePending
+
+			switch {
+			// The server stops sending updates for a swap once it
+			// completes, so there is no need to resubscribe.
+			case !pending:
+				delete(pendingSwaps, info.SwapHash)
+
+			// A failed subscription includes the new swap when it
+			// is retried.
+			case !known:
+				pendingSwaps[info.SwapHash] = struct{}{}
+
+				if !unsupported && retry == nil {
+					subscribe()
+				}
+			}
+
+		case update := <-updateChan:
+			update.ServerID = server.id
+			s.processServerUpdate(ctx, update)
+
+			if serverUpdateChan == nil {
+				continue
+			}
+
+			select {
+			case serverUpdateChan <- *update:
+			case <-ctx.Done():
+				return
+			}
+
+		case err := <-errChan:
+			handleErr(err)
+
+		case <-retry:
+			retry = nil
+			subscribe()
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// processServerUpdate records a server update of a swap in its history and
+// drops the cached quotes if the server changed its fees. A loop in that the
+// server rejected is failed off-chain.
+func (s *Client) processServerUpdate(ctx context.Context,
+	update *ServerUpdate) {
+
+	if update.SwapUpdate != nil {
+		log.Infof("Server update for swap %v: %v %v", update.SwapHash,
+			update.SwapUpdate.State, update.SwapUpdate.Reason)
+
+		err := s.Store.StoreServerUpdate(
+			update.SwapHash, update.SwapUpdate,
+		)
+		if err != nil {
+			log.Errorf("Storing server update for swap %v: %v",
+				update.SwapHash, err)
+		}
+
+		if update.SwapUpdate.State == loopdb.ServerSwapRejected {
+			s.cancelRejectedLoopIn(ctx, update.SwapHash)
+		}
+	}
+
+	if update.TermsUpdate != nil && update.TermsUpdate.FeesChanged {
+		log.Infof("Fees of server %v changed, dropping its cached "+
+			"quotes", update.ServerID)
+
+		s.quotes.clearServer(update.ServerID)
+	}
+}
+
+// cancelRejectedLoopIn cancels the swap invoice of a pending loop in that the
+// server rejected. The server can then no longer pay the invoice to learn the
+// preimage, and the swap reacts to the canceled invoice by asking the server
+// to co-sign refunds of its deposits instead of waiting for the htlc to
+// expire.
+func (s *Client) cancelRejectedLoopIn(ctx context.Context, hash lntypes.Hash) {
+	loopIns, err := s.Store.FetchLoopInSwaps()
+	if err != nil {
+		log.Errorf("Fetching loop ins: %v", err)
+		return
+	}
+
+	for _, loopIn := range loopIns {
+		if loopIn.Hash != hash {
+			continue
+		}
+
+		if loopIn.State().State.Type() != loopdb.StateTypePending {
+			return
+		}
+
+		log.Infof("Server rejected loop in %v, canceling swap invoice",
+			hash)
+
+		err := s.lndServices.Invoices.CancelInvoice(ctx, hash)
+		if err != nil && err != channeldb.ErrInvoiceAlreadySettled {
+			log.Errorf("Canceling swap invoice of loop in %v: %v",
+				hash, err)
+		}
+
+		return
+	}
+}
@@ -0,0 +1,186 @@
+package loop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lntypes"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// TestServerUpdates tests that the client subscribes to the server updates of
+// its pending swaps, persists and forwards them, and drops cached quotes when
+// the server changes its fees.
+func TestServerUpdates(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+	server := newServerMock()
+	store := newStoreMock(t)
+
+	client := newSwapClient(&clientConfig{
+		LndServices: &lnd.LndServices,
+		Store:       store,
+		Servers: []*swapServer{
+			{swapServerClient: server, id: testServerID},
+		},
+	})
+
+	pendingHash := lntypes.Hash{1}
+	store.loopOutSwaps[pendingHash] = &loopdb.LoopOutContract{}
+
+	swapUpdates := make(chan SwapInfo)
+	statusChan := make(chan SwapInfo)
+	serverUpdateChan := make(chan ServerUpdate)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		client.runServerUpdates(
+			ctx, map[string][]lntypes.Hash{
+				testServerID: {pendingHash},
+			}, swapUpdates, statusChan, serverUpdateChan,
+		)
+		close(done)
+	}()
+
+	expectSubscription := func(expected ...lntypes.Hash) {
+		t.Helper()
+
+		var hashes []lntypes.Hash
+		select {
+		case hashes = <-server.subscriptions:
+		case <-time.After(test.Timeout):
+			t.Fatal("expected subscription")
+		}
+
+		if len(hashes) != len(expected) {
+			t.Fatalf("expected subscription for %v, got %v",
+				expected, hashes)
+		}
+		subscribed := make(map[lntypes.Hash]bool)
+		for _, hash := range hashes {
+			subscribed[hash] = true
+		}
+		for _, hash := range expected {
+			if !subscribed[hash] {
+				t.Fatalf("swap %v not subscribed", hash)
+			}
+		}
+	}
+
+	// forwardSwapUpdate passes a swap update through the client.
+	forwardSwapUpdate := func(hash lntypes.Hash, state loopdb.SwapState) {
+		t.Helper()
+
+		info := SwapInfo{SwapHash: hash}
+		info.State = state
+		swapUpdates <- info
+
+		forwarded := <-statusChan
+		if forwarded.SwapHash != hash || forwarded.State != state {
+			t.Fatalf("unexpected swap update %v", forwarded)
+		}
+	}
+
+	// The client subscribes to the swaps that are pending at startup.
+	expectSubscription(pendingHash)
+
+	// A swap update is persisted and delivered.
+	swapUpdate := &loopdb.ServerSwapUpdate{
+		Time:                testTime,
+		State:               loopdb.ServerHtlcDelayed,
+		Reason:              "batching",
+		ExpectedPublication: testTime.Add(time.Hour),
+	}
+	server.updates <- &ServerUpdate{
+		SwapHash:   pendingHash,
+		SwapUpdate: swapUpdate,
+	}
+
+	stored := <-store.serverUpdateChan
+	if stored != *swapUpdate {
+		t.Fatalf("unexpected stored update %v", stored)
+	}
+
+	update := <-serverUpdateChan
+	if update.SwapHash != pendingHash || update.SwapUpdate != swapUpdate {
+		t.Fatalf("unexpected server update %v", update)
+	}
+
+	// A new swap renews the subscription to include it.
+	newHash := lntypes.Hash{2}
+	forwardSwapUpdate(newHash, loopdb.StateInitiated)
+	expectSubscription(pendingHash, newHash)
+
+	// A fee change drops the cached quotes.
+	client.quotes.addLoopOut(testMinSwapAmount, &LoopOutQuote{
+		QuoteID:     []byte{1},
+		QuoteExpiry: time.Now().Add(time.Hour),
+		ServerID:    testServerID,
+	})
+	server.updates <- &ServerUpdate{
+		TermsUpdate: &TermsUpdate{FeesChanged: true},
+	}
+
+	update = <-serverUpdateChan
+	if update.ServerID != testServerID || update.TermsUpdate == nil ||
+		!update.TermsUpdate.FeesChanged {
+
+		t.Fatalf("unexpected server update %v", update)
+	}
+
+	if len(client.quotes.loopOut) != 0 {
+		t.Fatal("expected cached quote to be dropped")
+	}
+
+	// A rejection fails a pending loop in off-chain by canceling its swap
+	// invoice, while the invoices of loop outs belong to the server.
+	loopInHash := lntypes.Hash{4}
+	store.loopInSwaps[loopInHash] = &loopdb.LoopInContract{}
+
+	for _, hash := range []lntypes.Hash{pendingHash, loopInHash} {
+		server.updates <- &ServerUpdate{
+			SwapHash: hash,
+			SwapUpdate: &loopdb.ServerSwapUpdate{
+				Time:  testTime,
+				State: loopdb.ServerSwapRejected,
+			},
+		}
+		<-store.serverUpdateChan
+		<-serverUpdateChan
+	}
+
+	select {
+	case hash := <-lnd.FailInvoiceChannel:
+		if hash != loopInHash {
+			t.Fatalf("unexpected canceled invoice %v", hash)
+		}
+	case <-time.After(test.Timeout):
+		t.Fatal("expected swap invoice to be canceled")
+	}
+
+	select {
+	case hash := <-lnd.FailInvoiceChannel:
+		t.Fatalf("unexpected canceled invoice %v", hash)
+	default:
+	}
+
+	// A server that doesn't support the subscription isn't asked again.
+	server.updateErrs <- status.Error(codes.Unimplemented, "unknown")
+
+	forwardSwapUpdate(lntypes.Hash{3}, loopdb.StateInitiated)
+
+	select {
+	case hashes := <-server.subscriptions:
+		t.Fatalf("unexpected subscription %v", hashes)
+	default:
+	}
+
+	cancel()
+	<-done
+}
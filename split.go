@@ -0,0 +1,672 @@
+package loop
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/swap"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+var (
+	// ErrMinerFeeTooHigh is returned when the combined miner fee estimate
+	// of a split swap exceeds the miner fee limit of the request.
+	ErrMinerFeeTooHigh = errors.New("miner fee too high")
+)
+
+// SwapGroupState describes the combined state of all swaps in a group.
+type SwapGroupState uint8
+
+const (
+	// GroupStatePending indicates that at least one of the swaps in the
+	// group is still pending.
+	GroupStatePending SwapGroupState = iota
+
+	// GroupStateSuccess indicates that all swaps in the group completed
+	// successfully.
+	GroupStateSuccess
+
+	// GroupStateFailed indicates that all swaps in the group failed.
+	GroupStateFailed
+
+	// GroupStatePartial indicates that all swaps in the group are final,
+	// but only some of them completed successfully.
+	GroupStatePartial
+)
+
+// String returns a string representation of the group state.
+func (s SwapGroupState) String() string {
+	switch s {
+	case GroupStatePending:
+		return "Pending"
+
+	case GroupStateSuccess:
+		return "Success"
+
+	case GroupStateFailed:
+		return "Failed"
+
+	case GroupStatePartial:
+		return "PartialSuccess"
+
+	default:
+		return "Unknown"
+	}
+}
+
+// splitSwapAmount splits the given amount into the smallest number of parts
+// that each fit within the server's maximum swap amount. The parts are as
+// equal as possible, the remainder is distributed over the first parts.
+func splitSwapAmount(amt, minAmt, maxAmt btcutil.Amount) ([]btcutil.Amount,
+	error) {
+
+	if amt < minAmt {
+		return nil, ErrSwapAmountTooLow
+	}
+
+	if maxAmt <= 0 {
+		return nil, ErrSwapAmountTooHigh
+	}
+
+	numParts := int64((amt + maxAmt - 1) / maxAmt)
+
+	partAmt := int64(amt) / numParts
+	remainder := int64(amt) % numParts
+
+	// Because the number of parts is minimal, the smallest part can only
+	// drop below the server minimum if the terms are very narrow.
+	if btcutil.Amount(partAmt) < minAmt {
+		return nil, ErrSwapAmountTooLow
+	}
+
+	parts := make([]btcutil.Amount, numParts)
+	for i := range parts {
+		parts[i] = btcutil.Amount(partAmt)
+		if int64(i) < remainder {
+			parts[i]++
+		}
+	}
+
+	return parts, nil
+}
+
+// allocateLimit distributes a total limit over a number of parts proportional
+// to the given weights. If all weights are zero, the limit is split evenly.
+// The last part receives whatever is left after rounding, so that the sum of
+// the allocations always equals the total. When the total is at least the sum
+// of the weights, every part receives at least its own weight.
+func allocateLimit(total btcutil.Amount,
+	weights []btcutil.Amount) []btcutil.Amount {
+
+	allocations := make([]btcutil.Amount, len(weights))
+	if len(weights) == 0 {
+		return allocations
+	}
+
+	var weightSum btcutil.Amount
+	for _, w := range weights {
+		weightSum += w
+	}
+
+	var allocated btcutil.Amount
+	for i, w := range weights[:len(weights)-1] {
+		if weightSum == 0 {
+			allocations[i] = total / btcutil.Amount(len(weights))
+		} else {
+			allocations[i] = btcutil.Amount(
+				int64(total) * int64(w) / int64(weightSum),
+			)
+		}
+		allocated += allocations[i]
+	}
+	allocations[len(weights)-1] = total - allocated
+
+	return allocations
+}
+
+// newGroupID returns a new random group id.
+func newGroupID() (loopdb.GroupID, error) {
+	var id loopdb.GroupID
+	if _, err := rand.Read(id[:]); err != nil {
+		return id, err
+	}
+
+	return id, nil
+}
+
+// splitLoopOutQuote quotes all parts of a split loop out with the given server
+// and returns the individual part quotes.
+func (s *Client) splitLoopOutQuote(ctx context.Context, server *swapServer,
+	request *LoopOutQuoteRequest, terms *LoopOutTerms) ([]btcutil.Amount,
+	[]*LoopOutQuote, error) {
+
+	parts, err := splitSwapAmount(
+		request.Amount, terms.MinSwapAmount, terms.MaxSwapAmount,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	quotes := make([]*LoopOutQuote, len(parts))
+	for i, amt := range parts {
+		quotes[i], err = s.getLoopOutQuote(ctx, server, amt, request)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return parts, quotes, nil
+}
+
+// splitLoopInQuote quotes all parts of a split loop in with the given server
+// and returns the individual part quotes.
+func (s *Client) splitLoopInQuote(ctx context.Context, server *swapServer,
+	request *LoopInQuoteRequest, terms *LoopInTerms) ([]btcutil.Amount,
+	[]*LoopInQuote, error) {
+
+	parts, err := splitSwapAmount(
+		request.Amount, terms.MinSwapAmount, terms.MaxSwapAmount,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	quotes := make([]*LoopInQuote, len(parts))
+	for i, amt := range parts {
+		quotes[i], err = s.getLoopInQuote(ctx, server, amt, request)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return parts, quotes, nil
+}
+
+// sumLoopOutQuotes combines the quotes of all parts of a split loop out.
+func sumLoopOutQuotes(quotes []*LoopOutQuote) *LoopOutQuote {
+	total := &LoopOutQuote{
+		SwapPaymentDest: quotes[0].SwapPaymentDest,
+		NumSwaps:        len(quotes),
+		ServerID:        quotes[0].ServerID,
+	}
+
+	for _, quote := range quotes {
+		total.SwapFee += quote.SwapFee
+		total.PrepayAmount += quote.PrepayAmount
+		total.MinerFee += quote.MinerFee
+		total.SwapRoutingFee += quote.SwapRoutingFee
+		total.PrepayRoutingFee += quote.PrepayRoutingFee
+
+		// Report the most restrictive delta of all parts.
+		if quote.CltvDelta > total.CltvDelta {
+			total.CltvDelta = quote.CltvDelta
+		}
+	}
+
+	return total
+}
+
+// sumLoopInQuotes combines the quotes of all parts of a split loop in.
+func sumLoopInQuotes(quotes []*LoopInQuote) *LoopInQuote {
+	total := &LoopInQuote{
+		NumSwaps: len(quotes),
+		ServerID: quotes[0].ServerID,
+	}
+
+	for _, quote := range quotes {
+		total.SwapFee += quote.SwapFee
+		total.MinerFee += quote.MinerFee
+
+		if quote.CltvDelta > total.CltvDelta {
+			total.CltvDelta = quote.CltvDelta
+		}
+	}
+
+	return total
+}
+
+// splitOutRequest checks the combined quotes of all parts against the limits
+// of the original request and divides those limits over the parts.
+func splitOutRequest(request *OutRequest, parts []btcutil.Amount,
+	quotes []*LoopOutQuote) ([]*OutRequest, error) {
+
+	total := sumLoopOutQuotes(quotes)
+
+	if total.SwapFee > request.MaxSwapFee {
+		log.Warnf("Combined swap fee %v exceeds limit %v",
+			total.SwapFee, request.MaxSwapFee)
+
+		return nil, ErrSwapFeeTooHigh
+	}
+
+	if total.PrepayAmount > request.MaxPrepayAmount {
+		log.Warnf("Combined prepay amount %v exceeds limit %v",
+			total.PrepayAmount, request.MaxPrepayAmount)
+
+		return nil, ErrPrepayAmountTooHigh
+	}
+
+	if total.MinerFee > request.MaxMinerFee {
+		log.Warnf("Combined miner fee %v exceeds limit %v",
+			total.MinerFee, request.MaxMinerFee)
+
+		return nil, ErrMinerFeeTooHigh
+	}
+
+	swapFees := make([]btcutil.Amount, len(quotes))
+	prepayAmts := make([]btcutil.Amount, len(quotes))
+	minerFees := make([]btcutil.Amount, len(quotes))
+	for i, quote := range quotes {
+		swapFees[i] = quote.SwapFee
+		prepayAmts[i] = quote.PrepayAmount
+		minerFees[i] = quote.MinerFee
+	}
+
+	maxSwapFees := allocateLimit(request.MaxSwapFee, swapFees)
+	maxPrepayAmts := allocateLimit(request.MaxPrepayAmount, prepayAmts)
+	maxMinerFees := allocateLimit(request.MaxMinerFee, minerFees)
+	maxSwapRoutingFees := allocateLimit(request.MaxSwapRoutingFee, parts)
+	maxPrepayRoutingFees := allocateLimit(
+		request.MaxPrepayRoutingFee, prepayAmts,
+	)
+
+	requests := make([]*OutRequest, len(parts))
+	for i, amt := range parts {
+		partRequest := *request
+		partRequest.Amount = amt
+		partRequest.MaxSwapFee = maxSwapFees[i]
+		partRequest.MaxPrepayAmount = maxPrepayAmts[i]
+		partRequest.MaxMinerFee = maxMinerFees[i]
+		partRequest.MaxSwapRoutingFee = maxSwapRoutingFees[i]
+		partRequest.MaxPrepayRoutingFee = maxPrepayRoutingFees[i]
+		partRequest.QuoteID = quotes[i].QuoteID
+
+		requests[i] = &partRequest
+	}
+
+	return requests, nil
+}
+
+// splitInRequest checks the combined quotes of all parts against the limits
+// of the original request and divides those limits over the parts.
+func splitInRequest(request *LoopInRequest, parts []btcutil.Amount,
+	quotes []*LoopInQuote) ([]*LoopInRequest, error) {
+
+	total := sumLoopInQuotes(quotes)
+
+	if total.SwapFee > request.MaxSwapFee {
+		log.Warnf("Combined swap fee %v exceeds limit %v",
+			total.SwapFee, request.MaxSwapFee)
+
+		return nil, ErrSwapFeeTooHigh
+	}
+
+	if total.MinerFee > request.MaxMinerFee {
+		log.Warnf("Combined miner fee %v exceeds limit %v",
+			total.MinerFee, request.MaxMinerFee)
+
+		return nil, ErrMinerFeeTooHigh
+	}
+
+	swapFees := make([]btcutil.Amount, len(quotes))
+	minerFees := make([]btcutil.Amount, len(quotes))
+	for i, quote := range quotes {
+		swapFees[i] = quote.SwapFee
+		minerFees[i] = quote.MinerFee
+	}
+
+	maxSwapFees := allocateLimit(request.MaxSwapFee, swapFees)
+	maxMinerFees := allocateLimit(request.MaxMinerFee, minerFees)
+
+	requests := make([]*LoopInRequest, len(parts))
+	for i, amt := range parts {
+		partRequest := *request
+		partRequest.Amount = amt
+		partRequest.MaxSwapFee = maxSwapFees[i]
+		partRequest.MaxMinerFee = maxMinerFees[i]
+		partRequest.QuoteID = quotes[i].QuoteID
+
+		requests[i] = &partRequest
+	}
+
+	return requests, nil
+}
+
+// assignPartDestAddrs gives every part of a split loop out except for the
+// first one a fresh destination address and checks it.
+func (s *Client) assignPartDestAddrs(ctx context.Context,
+	requests []*OutRequest) error {
+
+	for i := 1; i < len(requests); i++ {
+		addr, err := s.NextDestAddr(ctx)
+		if err != nil {
+			return err
+		}
+
+		requests[i].DestAddr = addr
+		if err := s.checkDestAddrs(requests[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LoopOutSplit initiates a loop out that may exceed the server's maximum swap
+// amount. The amount is split into server-sized parts which are each quoted.
+// If the combined quotes fit within the limits of the request, the limits are
+// divided over the parts and all swaps are launched as a single group.
+//
+// The first part sweeps to the destination address of the request. Every
+// further part sweeps to a fresh address from NextDestAddr, so that no
+// address is used twice.
+//
+// When the call returns, the group and all of its swaps have been persisted
+// and will be resumed automatically after restarts. If a part fails after the
+// group was created, the group id and the parts that were already launched
+// are returned along with the error.
+func (s *Client) LoopOutSplit(globalCtx context.Context,
+	request *OutRequest) (*loopdb.GroupID, []*SwapGroupPart, error) {
+
+	log.Infof("LoopOut split %v to %v (channel: %v)",
+		request.Amount, request.DestAddr, request.LoopOutChannel,
+	)
+
+	if request.IdempotencyKey != "" {
+		return nil, nil, ErrIdempotencyKeySplit
+	}
+
+	if err := validateLabels(request.Label, request.Metadata); err != nil {
+		return nil, nil, err
+	}
+
+	// The sweep outputs can't be repeated for every part without reusing
+	// their addresses.
+	if len(request.SweepOutputs) != 0 {
+		return nil, nil, ErrSplitSweepOutputs
+	}
+
+	if err := s.waitForInitialized(globalCtx); err != nil {
+		return nil, nil, err
+	}
+
+	// Check the destination of the first part before contacting the
+	// server.
+	if err := s.checkDestAddrs(request); err != nil {
+		return nil, nil, err
+	}
+
+	// All parts are executed with the same server, which is the cheapest
+	// eligible server unless the request names one.
+	server, parts, quotes, err := s.bestLoopOutQuote(
+		globalCtx, &LoopOutQuoteRequest{
+			Amount:                  request.Amount,
+			SweepConfTarget:         request.SweepConfTarget,
+			SwapPublicationDeadline: request.SwapPublicationDeadline,
+			LoopOutChannel:          request.LoopOutChannel,
+			Split:                   true,
+			ServerID:                request.ServerID,
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	requests, err := splitOutRequest(request, parts, quotes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.assignPartDestAddrs(globalCtx, requests); err != nil {
+		return nil, nil, err
+	}
+
+	// Probe all parts before the first swap is initiated, so that a
+	// missing route doesn't leave a partially launched group behind.
+	if request.ProbeRoute {
+		for i, partRequest := range requests {
+			err := s.probeLoopOut(
+				globalCtx, server, partRequest, quotes[i],
+			)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	groupID, err := s.createSwapGroup(swap.TypeOut, request.Amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	swapCfg := s.newSwapConfig(server)
+
+	result := make([]*SwapGroupPart, 0, len(requests))
+	for i, partRequest := range requests {
+		initiationHeight := s.executor.height()
+		swap, err := newLoopOutSwap(
+			globalCtx, swapCfg, initiationHeight, partRequest,
+			quotes[i],
+		)
+		if err != nil {
+			return &groupID, result, fmt.Errorf("group %v: "+
+				"unable to initiate part %v of %v (%v parts "+
+				"already launched): %v", groupID, i+1,
+				len(requests), i, err)
+		}
+
+		// The swap is persisted and committed to with the server, so
+		// it is executed even if it can't be linked to the group.
+		s.executor.initiateSwap(globalCtx, swap)
+
+		result = append(result, &SwapGroupPart{
+			Hash:        swap.hash,
+			HtlcAddress: swap.htlc.Address,
+			Amount:      partRequest.Amount,
+		})
+
+		err = s.Store.AddSwapToGroup(groupID, swap.hash)
+		if err != nil {
+			return &groupID, result, fmt.Errorf("group %v: "+
+				"unable to add part %v of %v (swap %v, %v "+
+				"parts launched) to group: %v", groupID, i+1,
+				len(requests), swap.hash, i+1, err)
+		}
+	}
+
+	return &groupID, result, nil
+}
+
+// LoopInSplit initiates a loop in that may exceed the server's maximum swap
+// amount. It is the loop in counterpart of LoopOutSplit.
+func (s *Client) LoopInSplit(globalCtx context.Context,
+	request *LoopInRequest) (*loopdb.GroupID, []*SwapGroupPart, error) {
+
+	log.Infof("Loop in split %v (channel: %v)",
+		request.Amount, request.LoopInChannel,
+	)
+
+	if request.IdempotencyKey != "" {
+		return nil, nil, ErrIdempotencyKeySplit
+	}
+
+	if err := validateLabels(request.Label, request.Metadata); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.waitForInitialized(globalCtx); err != nil {
+		return nil, nil, err
+	}
+
+	server, parts, quotes, err := s.bestLoopInQuote(
+		globalCtx, &LoopInQuoteRequest{
+			Amount:         request.Amount,
+			HtlcConfTarget: request.HtlcConfTarget,
+			ExternalHtlc:   request.ExternalHtlc,
+			Split:          true,
+			ServerID:       request.ServerID,
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	requests, err := splitInRequest(request, parts, quotes)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	groupID, err := s.createSwapGroup(swap.TypeIn, request.Amount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	swapCfg := s.newSwapConfig(server)
+
+	result := make([]*SwapGroupPart, 0, len(requests))
+	for i, partRequest := range requests {
+		initiationHeight := s.executor.height()
+		swap, err := newLoopInSwap(
+			globalCtx, swapCfg, initiationHeight, partRequest,
+			quotes[i],
+		)
+		if err != nil {
+			return &groupID, result, fmt.Errorf("group %v: "+
+				"unable to initiate part %v of %v (%v parts "+
+				"already launched): %v", groupID, i+1,
+				len(requests), i, err)
+		}
+
+		// The swap is persisted and committed to with the server, so
+		// it is executed even if it can't be linked to the group.
+		s.executor.initiateSwap(globalCtx, swap)
+
+		result = append(result, &SwapGroupPart{
+			Hash:        swap.hash,
+			HtlcAddress: swap.htlc.Address,
+			Amount:      partRequest.Amount,
+		})
+
+		err = s.Store.AddSwapToGroup(groupID, swap.hash)
+		if err != nil {
+			return &groupID, result, fmt.Errorf("group %v: "+
+				"unable to add part %v of %v (swap %v, %v "+
+				"parts launched) to group: %v", groupID, i+1,
+				len(requests), swap.hash, i+1, err)
+		}
+	}
+
+	return &groupID, result, nil
+}
+
+// createSwapGroup persists a new, empty swap group.
+func (s *Client) createSwapGroup(swapType swap.Type,
+	amt btcutil.Amount) (loopdb.GroupID, error) {
+
+	groupID, err := newGroupID()
+	if err != nil {
+		return groupID, err
+	}
+
+	err = s.Store.CreateSwapGroup(&loopdb.SwapGroup{
+		ID:              groupID,
+		Type:            swapType,
+		AmountRequested: amt,
+		InitiationTime:  time.Now(),
+	})
+	if err != nil {
+		return groupID, fmt.Errorf("cannot store swap group: %v", err)
+	}
+
+	log.Infof("Created swap group %v", groupID)
+
+	return groupID, nil
+}
+
+// FetchSwapGroups returns all swap groups in the database, along with the
+// combined state and cost of their swaps.
+func (s *Client) FetchSwapGroups() ([]*SwapGroupInfo, error) {
+	groups, err := s.Store.FetchSwapGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	swaps, err := s.FetchSwaps()
+	if err != nil {
+		return nil, err
+	}
+
+	swapsByHash := make(map[lntypes.Hash]*SwapInfo, len(swaps))
+	for _, swp := range swaps {
+		swapsByHash[swp.SwapHash] = swp
+	}
+
+	infos := make([]*SwapGroupInfo, 0, len(groups))
+	for _, group := range groups {
+		members := make([]*SwapInfo, 0, len(group.Swaps))
+		for _, hash := range group.Swaps {
+			swp, ok := swapsByHash[hash]
+			if !ok {
+				return nil, fmt.Errorf("swap %v of group %v "+
+					"not found", hash, group.ID)
+			}
+			members = append(members, swp)
+		}
+
+		infos = append(infos, newSwapGroupInfo(group, members))
+	}
+
+	return infos, nil
+}
+
+// newSwapGroupInfo combines the state and costs of the given group members.
+func newSwapGroupInfo(group *loopdb.SwapGroup,
+	members []*SwapInfo) *SwapGroupInfo {
+
+	info := &SwapGroupInfo{
+		SwapGroup: *group,
+		Swaps:     members,
+	}
+
+	var (
+		pending bool
+		success int
+	)
+	for _, swp := range members {
+		info.Cost.Server += swp.Cost.Server
+		info.Cost.Onchain += swp.Cost.Onchain
+		info.Cost.Offchain += swp.Cost.Offchain
+
+		switch swp.State.Type() {
+		case loopdb.StateTypePending:
+			pending = true
+
+		case loopdb.StateTypeSuccess:
+			success++
+			info.AmountSwapped += swp.AmountRequested
+		}
+	}
+
+	switch {
+	case pending:
+		info.State = GroupStatePending
+
+	// A group without any swaps failed before its first swap could be
+	// created, so it is reported as failed rather than pending forever.
+	case len(members) > 0 && success == len(members):
+		info.State = GroupStateSuccess
+
+	case success == 0:
+		info.State = GroupStateFailed
+
+	default:
+		info.State = GroupStatePartial
+	}
+
+	return info
+}
@@ -0,0 +1,367 @@
+package loop
+
+import (
+	"bytes"
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightninglabs/loop/test"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
+)
+
+// TestSplitSwapAmount tests the division of a swap amount into server-sized
+// parts.
+func TestSplitSwapAmount(t *testing.T) {
+	tests := []struct {
+		name          string
+		amt           btcutil.Amount
+		expectedParts []btcutil.Amount
+		expectedErr   error
+	}{
+		{
+			name:          "below minimum",
+			amt:           testMinSwapAmount - 1,
+			expectedErr:   ErrSwapAmountTooLow,
+			expectedParts: nil,
+		},
+		{
+			name:          "single part",
+			amt:           testMaxSwapAmount,
+			expectedParts: []btcutil.Amount{testMaxSwapAmount},
+		},
+		{
+			name: "two parts",
+			amt:  testMaxSwapAmount + 1,
+			expectedParts: []btcutil.Amount{
+				testMaxSwapAmount/2 + 1, testMaxSwapAmount / 2,
+			},
+		},
+		{
+			name: "three parts with remainder",
+			amt:  2*testMaxSwapAmount + 2,
+			expectedParts: []btcutil.Amount{
+				666668, 666667, 666667,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			parts, err := splitSwapAmount(
+				test.amt, testMinSwapAmount, testMaxSwapAmount,
+			)
+			if err != test.expectedErr {
+				t.Fatalf("expected error %v, got %v",
+					test.expectedErr, err)
+			}
+
+			if !reflect.DeepEqual(parts, test.expectedParts) {
+				t.Fatalf("expected parts %v, got %v",
+					test.expectedParts, parts)
+			}
+		})
+	}
+}
+
+// TestSplitOutRequest tests that the limits of a split loop out request are
+// checked against the combined quotes and divided over the parts.
+func TestSplitOutRequest(t *testing.T) {
+	parts := []btcutil.Amount{600000, 400000}
+	quotes := []*LoopOutQuote{
+		{SwapFee: 60, PrepayAmount: 10, MinerFee: 100},
+		{SwapFee: 40, PrepayAmount: 10, MinerFee: 100},
+	}
+
+	request := &OutRequest{
+		Amount:              1000000,
+		MaxSwapFee:          201,
+		MaxPrepayAmount:     20,
+		MaxMinerFee:         300,
+		MaxSwapRoutingFee:   1000,
+		MaxPrepayRoutingFee: 50,
+	}
+
+	requests, err := splitOutRequest(request, parts, quotes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type limits struct {
+		amt, swapFee, prepay, minerFee, swapRouting,
+		prepayRouting btcutil.Amount
+	}
+
+	expected := []limits{
+		{600000, 120, 10, 150, 600, 25},
+		{400000, 81, 10, 150, 400, 25},
+	}
+
+	for i, req := range requests {
+		got := limits{
+			req.Amount, req.MaxSwapFee, req.MaxPrepayAmount,
+			req.MaxMinerFee, req.MaxSwapRoutingFee,
+			req.MaxPrepayRoutingFee,
+		}
+		if got != expected[i] {
+			t.Fatalf("part %v: expected %+v, got %+v", i,
+				expected[i], got)
+		}
+	}
+
+	// A combined fee above the request limit must be rejected, even though
+	// each of the parts individually fits within it.
+	request.MaxMinerFee = 199
+	_, err = splitOutRequest(request, parts, quotes)
+	if err != ErrMinerFeeTooHigh {
+		t.Fatalf("expected miner fee error, got %v", err)
+	}
+}
+
+// TestAssignPartDestAddrs tests that every part of a split loop out except for
+// the first one sweeps to a fresh destination address.
+func TestAssignPartDestAddrs(t *testing.T) {
+	defer test.Guard(t)()
+
+	lnd := test.NewMockLnd()
+	lndServices := lnd.LndServices
+	lndServices.ChainParams = &chaincfg.MainNetParams
+
+	store := newStoreMock(t)
+	client := newSwapClient(&clientConfig{
+		LndServices: &lndServices,
+		Store:       store,
+	})
+
+	_, err := client.RegisterDestDescriptor(testAccountXpub)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	destAddr, err := btcutil.NewAddressWitnessScriptHash(
+		make([]byte, 32), lndServices.ChainParams,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requests, err := splitOutRequest(
+		&OutRequest{
+			Amount:      1500000,
+			DestAddr:    destAddr,
+			MaxSwapFee:  1000,
+			MaxMinerFee: 1000,
+		},
+		[]btcutil.Amount{500000, 500000, 500000},
+		[]*LoopOutQuote{{}, {}, {}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = client.assignPartDestAddrs(context.Background(), requests)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		destAddr.String(), testFirstAddr, testSecondAddr,
+	}
+	for i, req := range requests {
+		if req.DestAddr.String() != expected[i] {
+			t.Fatalf("part %v: expected destination %v, got %v",
+				i, expected[i], req.DestAddr)
+		}
+	}
+}
+
+// TestLoopOutSplitPartFailure tests that the group and the parts that were
+// already launched are returned when a later part of a split loop out fails.
+func TestLoopOutSplitPartFailure(t *testing.T) {
+	defer test.Guard(t)()
+
+	ctx := createClientTestContext(t, nil)
+
+	// The amount is split into two parts of 500001 and 500000. The server
+	// only accepts the first one.
+	ctx.serverMock.expectedSwapAmt = 500001
+	ctx.serverMock.swapInvoiceAmt = 500001 + testSwapFee -
+		testFixedPrepayAmount
+
+	groupID, parts, err := ctx.swapClient.LoopOutSplit(
+		context.Background(), &OutRequest{
+			Amount:              1000001,
+			DestAddr:            testAddr,
+			MaxMinerFee:         100000,
+			SweepConfTarget:     2,
+			MaxSwapFee:          1000,
+			MaxPrepayAmount:     200,
+			MaxPrepayRoutingFee: 100000,
+			MaxSwapRoutingFee:   100000,
+		},
+	)
+	if err == nil {
+		t.Fatal("expected second part to fail")
+	}
+	if groupID == nil {
+		t.Fatal("expected group id")
+	}
+	if len(parts) != 1 || parts[0].Amount != 500001 {
+		t.Fatalf("expected first part to be returned, got %v", parts)
+	}
+
+	group := ctx.store.swapGroups[*groupID]
+	if len(group.Swaps) != 1 || group.Swaps[0] != parts[0].Hash {
+		t.Fatalf("unexpected group swaps %v", group.Swaps)
+	}
+
+	// The launched part is executed.
+	ctx.assertStored()
+	ctx.assertStatus(loopdb.StateInitiated)
+
+	signalSwapPaymentResult := ctx.AssertPaid(swapInvoiceDesc)
+	signalPrepaymentResult := ctx.AssertPaid(prepayInvoiceDesc)
+
+	ctx.AssertRegisterConf()
+
+	signalSwapPaymentResult(
+		test.PaymentStateError(routerrpc.PaymentState_FAILED_NO_ROUTE),
+	)
+	signalPrepaymentResult(
+		test.PaymentStateError(routerrpc.PaymentState_FAILED_NO_ROUTE),
+	)
+	ctx.assertStatus(loopdb.StateFailOffchainPayments)
+	ctx.assertStoreFinished(loopdb.StateFailOffchainPayments)
+
+	ctx.finish()
+}
+
+// TestSplitInRequest tests that the limits of a split loop in are divided
+// over the parts and that every part references the quote of its amount.
+func TestSplitInRequest(t *testing.T) {
+	parts := []btcutil.Amount{600000, 400000}
+	quotes := []*LoopInQuote{
+		{SwapFee: 60, MinerFee: 100, QuoteID: []byte{1}},
+		{SwapFee: 40, MinerFee: 100, QuoteID: []byte{2}},
+	}
+
+	request := &LoopInRequest{
+		Amount:      1000000,
+		MaxSwapFee:  201,
+		MaxMinerFee: 300,
+	}
+
+	requests, err := splitInRequest(request, parts, quotes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, req := range requests {
+		if req.Amount != parts[i] {
+			t.Fatalf("part %v: expected amount %v, got %v", i,
+				parts[i], req.Amount)
+		}
+		if !bytes.Equal(req.QuoteID, quotes[i].QuoteID) {
+			t.Fatalf("part %v: expected quote id %x, got %x", i,
+				quotes[i].QuoteID, req.QuoteID)
+		}
+	}
+
+	request.MaxSwapFee = 99
+	_, err = splitInRequest(request, parts, quotes)
+	if err != ErrSwapFeeTooHigh {
+		t.Fatalf("expected swap fee error, got %v", err)
+	}
+}
+
+// TestSwapGroupState tests the combined state of a swap group.
+func TestSwapGroupState(t *testing.T) {
+	newSwap := func(state loopdb.SwapState,
+		amt btcutil.Amount) *SwapInfo {
+
+		return &SwapInfo{
+			SwapStateData: loopdb.SwapStateData{
+				State: state,
+				Cost:  loopdb.SwapCost{Server: 10, Onchain: 5},
+			},
+			SwapContract: loopdb.SwapContract{
+				AmountRequested: amt,
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		swaps         []*SwapInfo
+		expectedState SwapGroupState
+		expectedAmt   btcutil.Amount
+	}{
+		{
+			name: "pending",
+			swaps: []*SwapInfo{
+				newSwap(loopdb.StateSuccess, 100),
+				newSwap(loopdb.StateInitiated, 100),
+			},
+			expectedState: GroupStatePending,
+			expectedAmt:   100,
+		},
+		{
+			name: "success",
+			swaps: []*SwapInfo{
+				newSwap(loopdb.StateSuccess, 100),
+				newSwap(loopdb.StateSuccess, 200),
+			},
+			expectedState: GroupStateSuccess,
+			expectedAmt:   300,
+		},
+		{
+			name: "partial",
+			swaps: []*SwapInfo{
+				newSwap(loopdb.StateSuccess, 100),
+				newSwap(loopdb.StateFailTimeout, 200),
+			},
+			expectedState: GroupStatePartial,
+			expectedAmt:   100,
+		},
+		{
+			name: "failed",
+			swaps: []*SwapInfo{
+				newSwap(loopdb.StateFailOffchainPayments, 100),
+			},
+			expectedState: GroupStateFailed,
+		},
+		{
+			name:          "empty",
+			expectedState: GroupStateFailed,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+
+		t.Run(test.name, func(t *testing.T) {
+			info := newSwapGroupInfo(&loopdb.SwapGroup{}, test.swaps)
+
+			if info.State != test.expectedState {
+				t.Fatalf("expected state %v, got %v",
+					test.expectedState, info.State)
+			}
+
+			if info.AmountSwapped != test.expectedAmt {
+				t.Fatalf("expected amount %v, got %v",
+					test.expectedAmt, info.AmountSwapped)
+			}
+
+			expectedServer := btcutil.Amount(10 * len(test.swaps))
+			if info.Cost.Server != expectedServer {
+				t.Fatalf("expected server cost %v, got %v",
+					expectedServer, info.Cost.Server)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/btcsuite/btcd/wire"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/test"
 	"github.com/lightningnetwork/lnd/lntypes"
@@ -21,6 +22,18 @@ type storeMock struct {
 	loopInUpdates    map[lntypes.Hash][]loopdb.SwapStateData
 	loopInStoreChan  chan loopdb.LoopInContract
 	loopInUpdateChan chan loopdb.SwapStateData
+	loopInDeposits   map[lntypes.Hash]map[wire.OutPoint]loopdb.LoopInDeposit
+
+	serverUpdateChan chan loopdb.ServerSwapUpdate
+
+	swapGroups map[loopdb.GroupID]*loopdb.SwapGroup
+
+	swapIntents map[loopdb.IntentID]*loopdb.SwapIntent
+
+	recurringSwaps map[loopdb.RecurringID]*loopdb.RecurringSwap
+
+	destDescriptor    string
+	descriptorIndexes map[string]uint32
 
 	t *testing.T
 }
@@ -37,7 +50,19 @@ func newStoreMock(t *testing.T) *storeMock {
 		loopInUpdateChan: make(chan loopdb.SwapStateData, 1),
 		loopInSwaps:      make(map[lntypes.Hash]*loopdb.LoopInContract),
 		loopInUpdates:    make(map[lntypes.Hash][]loopdb.SwapStateData),
-		t:                t,
+		loopInDeposits: make(
+			map[lntypes.Hash]map[wire.OutPoint]loopdb.LoopInDeposit,
+		),
+
+		serverUpdateChan: make(chan loopdb.ServerSwapUpdate, 1),
+
+		swapGroups:  make(map[loopdb.GroupID]*loopdb.SwapGroup),
+		swapIntents: make(map[loopdb.IntentID]*loopdb.SwapIntent),
+		recurringSwaps: make(
+			map[loopdb.RecurringID]*loopdb.RecurringSwap,
+		),
+		descriptorIndexes: make(map[string]uint32),
+		t:                 t,
 	}
 }
 
@@ -100,12 +125,19 @@ func (s *storeMock) FetchLoopInSwaps() ([]*loopdb.LoopIn, error) {
 			}
 		}
 
+		var deposits []*loopdb.LoopInDeposit
+		for _, deposit := range s.loopInDeposits[hash] {
+			deposit := deposit
+			deposits = append(deposits, &deposit)
+		}
+
 		swap := &loopdb.LoopIn{
 			Loop: loopdb.Loop{
 				Hash:   hash,
 				Events: events,
 			},
 			Contract: contract,
+			Deposits: deposits,
 		}
 		result = append(result, swap)
 	}
@@ -171,6 +203,258 @@ func (s *storeMock) UpdateLoopIn(hash lntypes.Hash, time time.Time,
 	return nil
 }
 
+// StoreLoopInDeposit adds or updates an htlc deposit of a loop in swap.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) StoreLoopInDeposit(hash lntypes.Hash,
+	deposit *loopdb.LoopInDeposit) error {
+
+	if _, ok := s.loopInSwaps[hash]; !ok {
+		return errors.New("swap does not exists")
+	}
+
+	deposits, ok := s.loopInDeposits[hash]
+	if !ok {
+		deposits = make(map[wire.OutPoint]loopdb.LoopInDeposit)
+		s.loopInDeposits[hash] = deposits
+	}
+	deposits[deposit.Outpoint] = *deposit
+
+	return nil
+}
+
+// StoreServerUpdate appends an update that the server pushed for a loop out or
+// loop in swap.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) StoreServerUpdate(hash lntypes.Hash,
+	update *loopdb.ServerSwapUpdate) error {
+
+	_, loopOut := s.loopOutSwaps[hash]
+	_, loopIn := s.loopInSwaps[hash]
+	if !loopOut && !loopIn {
+		return errors.New("swap does not exists")
+	}
+
+	s.serverUpdateChan <- *update
+
+	return nil
+}
+
+// CreateSwapGroup adds a new swap group to the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) CreateSwapGroup(group *loopdb.SwapGroup) error {
+	_, ok := s.swapGroups[group.ID]
+	if ok {
+		return errors.New("swap group already exists")
+	}
+
+	groupCopy := *group
+	groupCopy.Swaps = append([]lntypes.Hash{}, group.Swaps...)
+	s.swapGroups[group.ID] = &groupCopy
+
+	return nil
+}
+
+// AddSwapToGroup links the swap with the given hash to an existing swap group.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) AddSwapToGroup(id loopdb.GroupID,
+	hash lntypes.Hash) error {
+
+	group, ok := s.swapGroups[id]
+	if !ok {
+		return errors.New("swap group does not exist")
+	}
+
+	group.Swaps = append(group.Swaps, hash)
+
+	return nil
+}
+
+// FetchSwapGroups returns all swap groups currently in the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) FetchSwapGroups() ([]*loopdb.SwapGroup, error) {
+	result := []*loopdb.SwapGroup{}
+
+	for _, group := range s.swapGroups {
+		groupCopy := *group
+		groupCopy.Swaps = append([]lntypes.Hash{}, group.Swaps...)
+		result = append(result, &groupCopy)
+	}
+
+	return result, nil
+}
+
+// CreateSwapIntent adds a new swap intent to the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) CreateSwapIntent(intent *loopdb.SwapIntent) error {
+	_, ok := s.swapIntents[intent.ID]
+	if ok {
+		return errors.New("swap intent already exists")
+	}
+
+	intentCopy := *intent
+	s.swapIntents[intent.ID] = &intentCopy
+
+	return nil
+}
+
+// UpdateSwapIntent replaces the state of an existing swap intent.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) UpdateSwapIntent(id loopdb.IntentID,
+	update *loopdb.IntentUpdate) error {
+
+	intent, ok := s.swapIntents[id]
+	if !ok {
+		return loopdb.ErrSwapIntentNotFound
+	}
+
+	intent.IntentUpdate = *update
+
+	return nil
+}
+
+// FetchSwapIntents returns all swap intents currently in the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) FetchSwapIntents() ([]*loopdb.SwapIntent, error) {
+	result := []*loopdb.SwapIntent{}
+
+	for _, intent := range s.swapIntents {
+		intentCopy := *intent
+		result = append(result, &intentCopy)
+	}
+
+	return result, nil
+}
+
+// CreateRecurringSwap adds a new recurring swap to the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) CreateRecurringSwap(
+	recurring *loopdb.RecurringSwap) error {
+
+	_, ok := s.recurringSwaps[recurring.ID]
+	if ok {
+		return errors.New("recurring swap already exists")
+	}
+
+	recurringCopy := *recurring
+	s.recurringSwaps[recurring.ID] = &recurringCopy
+
+	return nil
+}
+
+// UpdateRecurringSwap replaces the state of an existing recurring swap.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) UpdateRecurringSwap(id loopdb.RecurringID,
+	state loopdb.RecurringState) error {
+
+	recurring, ok := s.recurringSwaps[id]
+	if !ok {
+		return loopdb.ErrRecurringSwapNotFound
+	}
+
+	recurring.State = state
+
+	return nil
+}
+
+// AddRecurringRun records a run of an existing recurring swap.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) AddRecurringRun(id loopdb.RecurringID,
+	run *loopdb.RecurringRun) error {
+
+	recurring, ok := s.recurringSwaps[id]
+	if !ok {
+		return loopdb.ErrRecurringSwapNotFound
+	}
+
+	runCopy := *run
+	recurring.Runs = append(recurring.Runs, &runCopy)
+
+	return nil
+}
+
+// FetchRecurringSwaps returns all recurring swaps currently in the store.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) FetchRecurringSwaps() ([]*loopdb.RecurringSwap, error) {
+	result := []*loopdb.RecurringSwap{}
+
+	for _, recurring := range s.recurringSwaps {
+		recurringCopy := *recurring
+		recurringCopy.Runs = append(
+			[]*loopdb.RecurringRun(nil), recurring.Runs...,
+		)
+		result = append(result, &recurringCopy)
+	}
+
+	return result, nil
+}
+
+// RegisterDestDescriptor makes the given descriptor the source of loop out
+// destination addresses.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) RegisterDestDescriptor(descriptor string) (
+	*loopdb.DestDescriptor, error) {
+
+	s.destDescriptor = descriptor
+
+	return &loopdb.DestDescriptor{
+		Descriptor: descriptor,
+		NextIndex:  s.descriptorIndexes[descriptor],
+	}, nil
+}
+
+// UnregisterDestDescriptor removes the registered destination descriptor.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) UnregisterDestDescriptor() error {
+	if s.destDescriptor == "" {
+		return loopdb.ErrNoDestDescriptor
+	}
+
+	s.destDescriptor = ""
+	return nil
+}
+
+// FetchDestDescriptor returns the registered destination descriptor.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) FetchDestDescriptor() (*loopdb.DestDescriptor, error) {
+	if s.destDescriptor == "" {
+		return nil, loopdb.ErrNoDestDescriptor
+	}
+
+	return &loopdb.DestDescriptor{
+		Descriptor: s.destDescriptor,
+		NextIndex:  s.descriptorIndexes[s.destDescriptor],
+	}, nil
+}
+
+// NextDestIndex reserves the next derivation index of the registered
+// destination descriptor.
+//
+// NOTE: Part of the loopdb.SwapStore interface.
+func (s *storeMock) NextDestIndex() (*loopdb.DestDescriptor, error) {
+	dest, err := s.FetchDestDescriptor()
+	if err != nil {
+		return nil, err
+	}
+
+	s.descriptorIndexes[s.destDescriptor]++
+	return dest, nil
+}
+
 func (s *storeMock) Close() error {
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/lightninglabs/loop/lndclient"
 	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/swap"
@@ -21,6 +22,19 @@ type swapKit struct {
 	lastUpdateTime time.Time
 	cost           loopdb.SwapCost
 	state          loopdb.SwapState
+	paymentFailure *loopdb.PaymentFailure
+
+	// refundTxHash is the hash of the timeout tx that refunded the htlc
+	// of a loop in swap.
+	refundTxHash *chainhash.Hash
+
+	// deposits are the outputs that pay to the htlc of a loop in swap.
+	deposits []*loopdb.LoopInDeposit
+
+	// sweepStrategy describes how the htlc is swept. It is only set for
+	// loop out swaps.
+	sweepStrategy *SweepStrategy
+
 	executeConfig
 	swapConfig
 
@@ -34,9 +48,8 @@ func newSwapKit(hash lntypes.Hash, swapType swap.Type, cfg *swapConfig,
 
 	// Compose expected on-chain swap script
 	htlc, err := swap.NewHtlc(
-		contract.CltvExpiry, contract.SenderKey,
-		contract.ReceiverKey, hash, outputType,
-		cfg.lnd.ChainParams,
+		contract.HtlcVersion, contract.CltvExpiry, contract.SenderKey,
+		contract.ReceiverKey, hash, outputType, cfg.lnd.ChainParams,
 	)
 	if err != nil {
 		return nil, err
@@ -69,12 +82,26 @@ func (s *swapKit) sendUpdate(ctx context.Context) error {
 		SwapType:     s.swapType,
 		LastUpdate:   s.lastUpdateTime,
 		SwapStateData: loopdb.SwapStateData{
-			State: s.state,
-			Cost:  s.cost,
+			State:          s.state,
+			Cost:           s.cost,
+			PaymentFailure: s.paymentFailure,
+			RefundTxHash:   s.refundTxHash,
 		},
 		HtlcAddress: s.htlc.Address,
 	}
 
+	// Copy the sweep strategy, because its status is modified by the
+	// swap while the update is consumed.
+	if s.sweepStrategy != nil {
+		strategy := *s.sweepStrategy
+		info.SweepStrategy = &strategy
+	}
+
+	// Copy the deposits for the same reason.
+	for _, deposit := range s.deposits {
+		info.Deposits = append(info.Deposits, *deposit)
+	}
+
 	s.log.Infof("state %v", info.State)
 
 	select {
@@ -95,4 +122,7 @@ type swapConfig struct {
 	lnd    *lndclient.LndServices
 	store  loopdb.SwapStore
 	server swapServerClient
+
+	// serverID identifies the server in the contract of the swap.
+	serverID string
 }
@@ -1,7 +1,6 @@
 package swap
 
 import (
-	"bytes"
 	"crypto/sha256"
 	"errors"
 
@@ -25,15 +24,33 @@ const (
 	HtlcNP2WSH
 )
 
+// String returns the string representation of the htlc output type.
+func (h HtlcOutputType) String() string {
+	switch h {
+	case HtlcP2WSH:
+		return "P2WSH"
+
+	case HtlcNP2WSH:
+		return "NP2WSH"
+
+	default:
+		return "Unknown"
+	}
+}
+
 // Htlc contains relevant htlc information from the receiver perspective.
 type Htlc struct {
 	Script      []byte
 	PkScript    []byte
 	Hash        lntypes.Hash
+	Version     ScriptVersion
 	OutputType  HtlcOutputType
 	ChainParams *chaincfg.Params
 	Address     btcutil.Address
 	SigScript   []byte
+
+	// htlcScript builds the witnesses of the script version.
+	htlcScript htlcScript
 }
 
 var (
@@ -45,17 +62,23 @@ var (
 	// the maximum value for cltv expiry to get the maximum (worst case)
 	// script size.
 	QuoteHtlc, _ = NewHtlc(
-		^int32(0), quoteKey, quoteKey, quoteHash, HtlcP2WSH,
-		&chaincfg.MainNetParams,
+		LatestScriptVersion, ^int32(0), quoteKey, quoteKey, quoteHash,
+		HtlcP2WSH, &chaincfg.MainNetParams,
 	)
 )
 
 // NewHtlc returns a new instance.
-func NewHtlc(cltvExpiry int32, senderKey, receiverKey [33]byte,
-	hash lntypes.Hash, outputType HtlcOutputType,
-	chainParams *chaincfg.Params) (*Htlc, error) {
+func NewHtlc(version ScriptVersion, cltvExpiry int32,
+	senderKey, receiverKey [33]byte, hash lntypes.Hash,
+	outputType HtlcOutputType, chainParams *chaincfg.Params) (*Htlc,
+	error) {
+
+	htlcScript, ok := htlcScripts[version]
+	if !ok {
+		return nil, ErrUnknownScriptVersion
+	}
 
-	script, err := swapHTLCScript(
+	script, err := htlcScript.script(
 		cltvExpiry, senderKey, receiverKey, hash,
 	)
 	if err != nil {
@@ -123,59 +146,15 @@ func NewHtlc(cltvExpiry int32, senderKey, receiverKey [33]byte,
 		Hash:        hash,
 		Script:      script,
 		PkScript:    pkScript,
+		Version:     version,
 		OutputType:  outputType,
 		ChainParams: chainParams,
 		Address:     address,
 		SigScript:   sigScript,
+		htlcScript:  htlcScript,
 	}, nil
 }
 
-// SwapHTLCScript returns the on-chain HTLC witness script.
-//
-// OP_SIZE 32 OP_EQUAL
-// OP_IF
-//    OP_HASH160 <ripemd160(swap_hash)> OP_EQUALVERIFY
-//    <recvr key>
-// OP_ELSE
-//    OP_DROP
-//    <cltv timeout> OP_CHECKLOCKTIMEVERIFY OP_DROP
-//    <sender key>
-// OP_ENDIF
-// OP_CHECKSIG
-func swapHTLCScript(cltvExpiry int32, senderHtlcKey,
-	receiverHtlcKey [33]byte, swapHash lntypes.Hash) ([]byte, error) {
-
-	builder := txscript.NewScriptBuilder()
-
-	builder.AddOp(txscript.OP_SIZE)
-	builder.AddInt64(32)
-	builder.AddOp(txscript.OP_EQUAL)
-
-	builder.AddOp(txscript.OP_IF)
-
-	builder.AddOp(txscript.OP_HASH160)
-	builder.AddData(input.Ripemd160H(swapHash[:]))
-	builder.AddOp(txscript.OP_EQUALVERIFY)
-
-	builder.AddData(receiverHtlcKey[:])
-
-	builder.AddOp(txscript.OP_ELSE)
-
-	builder.AddOp(txscript.OP_DROP)
-
-	builder.AddInt64(int64(cltvExpiry))
-	builder.AddOp(txscript.OP_CHECKLOCKTIMEVERIFY)
-	builder.AddOp(txscript.OP_DROP)
-
-	builder.AddData(senderHtlcKey[:])
-
-	builder.AddOp(txscript.OP_ENDIF)
-
-	builder.AddOp(txscript.OP_CHECKSIG)
-
-	return builder.Script()
-}
-
 // GenSuccessWitness returns the success script to spend this htlc with the
 // preimage.
 func (h *Htlc) GenSuccessWitness(receiverSig []byte,
@@ -185,78 +164,85 @@ func (h *Htlc) GenSuccessWitness(receiverSig []byte,
 		return nil, errors.New("preimage doesn't match hash")
 	}
 
-	witnessStack := make(wire.TxWitness, 3)
-	witnessStack[0] = append(receiverSig, byte(txscript.SigHashAll))
-	witnessStack[1] = preimage[:]
-	witnessStack[2] = h.Script
-
-	return witnessStack, nil
+	return h.htlcScript.successWitness(h.Script, receiverSig, preimage),
+		nil
 }
 
 // IsSuccessWitness checks whether the given stack is valid for redeeming the
 // htlc.
 func (h *Htlc) IsSuccessWitness(witness wire.TxWitness) bool {
-	if len(witness) != 3 {
-		return false
-	}
-
-	isTimeoutTx := bytes.Equal([]byte{0}, witness[1])
-
-	return !isTimeoutTx
+	return h.htlcScript.isSuccessWitness(witness)
 }
 
 // GenTimeoutWitness returns the timeout script to spend this htlc after
 // timeout.
 func (h *Htlc) GenTimeoutWitness(senderSig []byte) (wire.TxWitness, error) {
+	return h.htlcScript.timeoutWitness(h.Script, senderSig), nil
+}
+
+// SupportsCooperativeRefund returns whether the script version of the htlc has
+// a path that the sender and receiver can spend together before the expiry.
+func (h *Htlc) SupportsCooperativeRefund() bool {
+	_, ok := h.htlcScript.(cooperativeRefundScript)
+	return ok
+}
+
+// GenCooperativeRefundWitness returns the witness to spend this htlc with the
+// signatures of the sender and the receiver before the expiry.
+func (h *Htlc) GenCooperativeRefundWitness(senderSig,
+	receiverSig []byte) (wire.TxWitness, error) {
 
-	witnessStack := make(wire.TxWitness, 3)
-	witnessStack[0] = append(senderSig, byte(txscript.SigHashAll))
-	witnessStack[1] = []byte{0}
-	witnessStack[2] = h.Script
+	coopScript, ok := h.htlcScript.(cooperativeRefundScript)
+	if !ok {
+		return nil, ErrNoCooperativeRefund
+	}
 
-	return witnessStack, nil
+	return coopScript.cooperativeRefundWitness(
+		h.Script, senderSig, receiverSig,
+	), nil
 }
 
 // AddSuccessToEstimator adds a successful spend to a weight estimator.
 func (h *Htlc) AddSuccessToEstimator(estimator *input.TxWeightEstimator) {
-	// Calculate maximum success witness size
-	//
-	// - number_of_witness_elements: 1 byte
-	// - receiver_sig_length: 1 byte
-	// - receiver_sig: 73 bytes
-	// - preimage_length: 1 byte
-	// - preimage: 33 bytes
-	// - witness_script_length: 1 byte
-	// - witness_script: len(script) bytes
-	maxSuccessWitnessSize := 1 + 1 + 73 + 1 + 33 + 1 + len(h.Script)
+	h.addToEstimator(
+		estimator, h.htlcScript.maxSuccessWitnessSize(h.Script),
+	)
+}
 
-	switch h.OutputType {
-	case HtlcP2WSH:
-		estimator.AddWitnessInput(maxSuccessWitnessSize)
+// AddTimeoutToEstimator adds a timeout spend to a weight estimator.
+func (h *Htlc) AddTimeoutToEstimator(estimator *input.TxWeightEstimator) {
+	h.addToEstimator(
+		estimator, h.htlcScript.maxTimeoutWitnessSize(h.Script),
+	)
+}
 
-	case HtlcNP2WSH:
-		estimator.AddNestedP2WSHInput(maxSuccessWitnessSize)
+// AddCooperativeRefundToEstimator adds a cooperative refund spend to a weight
+// estimator. Script versions without a cooperative path are estimated as a
+// timeout spend.
+func (h *Htlc) AddCooperativeRefundToEstimator(
+	estimator *input.TxWeightEstimator) {
+
+	coopScript, ok := h.htlcScript.(cooperativeRefundScript)
+	if !ok {
+		h.AddTimeoutToEstimator(estimator)
+		return
 	}
+
+	h.addToEstimator(
+		estimator, coopScript.maxCooperativeRefundWitnessSize(h.Script),
+	)
 }
 
-// AddTimeoutToEstimator adds a timeout spend to a weight estimator.
-func (h *Htlc) AddTimeoutToEstimator(estimator *input.TxWeightEstimator) {
-	// Calculate maximum timeout witness size
-	//
-	// - number_of_witness_elements: 1 byte
-	// - sender_sig_length: 1 byte
-	// - sender_sig: 73 bytes
-	// - zero_length: 1 byte
-	// - zero: 1 byte
-	// - witness_script_length: 1 byte
-	// - witness_script: len(script) bytes
-	maxTimeoutWitnessSize := 1 + 1 + 73 + 1 + 1 + 1 + len(h.Script)
+// addToEstimator adds a spend of the htlc output with a witness of the given
+// size to a weight estimator.
+func (h *Htlc) addToEstimator(estimator *input.TxWeightEstimator,
+	witnessSize int) {
 
 	switch h.OutputType {
 	case HtlcP2WSH:
-		estimator.AddWitnessInput(maxTimeoutWitnessSize)
+		estimator.AddWitnessInput(witnessSize)
 
 	case HtlcNP2WSH:
-		estimator.AddNestedP2WSHInput(maxTimeoutWitnessSize)
+		estimator.AddNestedP2WSHInput(witnessSize)
 	}
 }
@@ -0,0 +1,384 @@
+package swap
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/lightningnetwork/lnd/input"
+	"github.com/lightningnetwork/lnd/lntypes"
+)
+
+// ScriptVersion defines the version of the htlc script. The version is stored
+// with every swap, so that a new script design doesn't affect swaps that use
+// an earlier one.
+type ScriptVersion uint8
+
+const (
+	// HtlcScriptV1 is the original htlc script, which is spent by the
+	// receiver with the preimage or by the sender after the cltv expiry.
+	HtlcScriptV1 ScriptVersion = 0
+
+	// HtlcScriptV2 adds a path that the sender and receiver can spend
+	// together before the cltv expiry, so that the sender can be refunded
+	// early when the swap fails off-chain.
+	HtlcScriptV2 ScriptVersion = 1
+
+	// LatestScriptVersion is the most recent script version that is
+	// supported.
+	LatestScriptVersion = HtlcScriptV2
+)
+
+// String returns the string representation of the script version.
+func (v ScriptVersion) String() string {
+	switch v {
+	case HtlcScriptV1:
+		return "V1"
+
+	case HtlcScriptV2:
+		return "V2"
+
+	default:
+		return "Unknown"
+	}
+}
+
+var (
+	// ErrUnknownScriptVersion is returned when an htlc is created for a
+	// script version that isn't in the registry.
+	ErrUnknownScriptVersion = errors.New("unknown htlc script version")
+
+	// ErrNoCooperativeRefund is returned when a cooperative refund witness
+	// is requested for a script version that doesn't have a cooperative
+	// path.
+	ErrNoCooperativeRefund = errors.New("htlc script version doesn't " +
+		"support cooperative refunds")
+)
+
+// htlcScript is a version of the htlc script. It builds the witness script and
+// the witnesses that spend it, and knows their maximum sizes for fee
+// estimation.
+type htlcScript interface {
+	// script returns the witness script of the htlc.
+	script(cltvExpiry int32, senderKey, receiverKey [33]byte,
+		hash lntypes.Hash) ([]byte, error)
+
+	// successWitness returns the witness that spends the htlc with the
+	// preimage.
+	successWitness(script, receiverSig []byte,
+		preimage lntypes.Preimage) wire.TxWitness
+
+	// isSuccessWitness returns whether the witness spends the htlc with
+	// the preimage.
+	isSuccessWitness(witness wire.TxWitness) bool
+
+	// timeoutWitness returns the witness that spends the htlc after the
+	// cltv expiry.
+	timeoutWitness(script, senderSig []byte) wire.TxWitness
+
+	// maxSuccessWitnessSize returns the maximum size of a success witness.
+	maxSuccessWitnessSize(script []byte) int
+
+	// maxTimeoutWitnessSize returns the maximum size of a timeout witness.
+	maxTimeoutWitnessSize(script []byte) int
+}
+
+// cooperativeRefundScript is implemented by htlc script versions with a path
+// that the sender and receiver can spend together before the cltv expiry.
+type cooperativeRefundScript interface {
+	// cooperativeRefundWitness returns the witness that spends the htlc
+	// with the signatures of the sender and the receiver.
+	cooperativeRefundWitness(script, senderSig,
+		receiverSig []byte) wire.TxWitness
+
+	// maxCooperativeRefundWitnessSize returns the maximum size of a
+	// cooperative refund witness.
+	maxCooperativeRefundWitnessSize(script []byte) int
+}
+
+// htlcScripts is the registry of htlc script versions. A version must never be
+// changed once it has been used for a swap.
+var htlcScripts = map[ScriptVersion]htlcScript{
+	HtlcScriptV1: &htlcScriptV1{},
+	HtlcScriptV2: &htlcScriptV2{},
+}
+
+// IsSupportedScriptVersion returns whether htlcs of the given script version
+// can be created.
+func IsSupportedScriptVersion(version ScriptVersion) bool {
+	_, ok := htlcScripts[version]
+	return ok
+}
+
+// htlcScriptV1 is the original htlc script.
+type htlcScriptV1 struct{}
+
+// script returns the on-chain HTLC witness script.
+//
+//	OP_SIZE 32 OP_EQUAL
+//	OP_IF
+//	   OP_HASH160 <ripemd160(swap_hash)> OP_EQUALVERIFY
+//	   <recvr key>
+//	OP_ELSE
+//	   OP_DROP
+//	   <cltv timeout> OP_CHECKLOCKTIMEVERIFY OP_DROP
+//	   <sender key>
+//	OP_ENDIF
+//	OP_CHECKSIG
+func (h *htlcScriptV1) script(cltvExpiry int32, senderHtlcKey,
+	receiverHtlcKey [33]byte, swapHash lntypes.Hash) ([]byte, error) {
+
+	builder := txscript.NewScriptBuilder()
+
+	builder.AddOp(txscript.OP_SIZE)
+	builder.AddInt64(32)
+	builder.AddOp(txscript.OP_EQUAL)
+
+	builder.AddOp(txscript.OP_IF)
+
+	builder.AddOp(txscript.OP_HASH160)
+	builder.AddData(input.Ripemd160H(swapHash[:]))
+	builder.AddOp(txscript.OP_EQUALVERIFY)
+
+	builder.AddData(receiverHtlcKey[:])
+
+	builder.AddOp(txscript.OP_ELSE)
+
+	builder.AddOp(txscript.OP_DROP)
+
+	builder.AddInt64(int64(cltvExpiry))
+	builder.AddOp(txscript.OP_CHECKLOCKTIMEVERIFY)
+	builder.AddOp(txscript.OP_DROP)
+
+	builder.AddData(senderHtlcKey[:])
+
+	builder.AddOp(txscript.OP_ENDIF)
+
+	builder.AddOp(txscript.OP_CHECKSIG)
+
+	return builder.Script()
+}
+
+// successWitness returns the witness that spends the htlc with the preimage.
+func (h *htlcScriptV1) successWitness(script, receiverSig []byte,
+	preimage lntypes.Preimage) wire.TxWitness {
+
+	witnessStack := make(wire.TxWitness, 3)
+	witnessStack[0] = append(receiverSig, byte(txscript.SigHashAll))
+	witnessStack[1] = preimage[:]
+	witnessStack[2] = script
+
+	return witnessStack
+}
+
+// isSuccessWitness returns whether the witness spends the htlc with the
+// preimage.
+func (h *htlcScriptV1) isSuccessWitness(witness wire.TxWitness) bool {
+	if len(witness) != 3 {
+		return false
+	}
+
+	isTimeoutTx := bytes.Equal([]byte{0}, witness[1])
+
+	return !isTimeoutTx
+}
+
+// timeoutWitness returns the witness that spends the htlc after the cltv
+// expiry.
+func (h *htlcScriptV1) timeoutWitness(script,
+	senderSig []byte) wire.TxWitness {
+
+	witnessStack := make(wire.TxWitness, 3)
+	witnessStack[0] = append(senderSig, byte(txscript.SigHashAll))
+	witnessStack[1] = []byte{0}
+	witnessStack[2] = script
+
+	return witnessStack
+}
+
+// maxSuccessWitnessSize returns the maximum size of a success witness.
+func (h *htlcScriptV1) maxSuccessWitnessSize(script []byte) int {
+	// Calculate maximum success witness size
+	//
+	// - number_of_witness_elements: 1 byte
+	// - receiver_sig_length: 1 byte
+	// - receiver_sig: 73 bytes
+	// - preimage_length: 1 byte
+	// - preimage: 33 bytes
+	// - witness_script_length: 1 byte
+	// - witness_script: len(script) bytes
+	return 1 + 1 + 73 + 1 + 33 + 1 + len(script)
+}
+
+// maxTimeoutWitnessSize returns the maximum size of a timeout witness.
+func (h *htlcScriptV1) maxTimeoutWitnessSize(script []byte) int {
+	// Calculate maximum timeout witness size
+	//
+	// - number_of_witness_elements: 1 byte
+	// - sender_sig_length: 1 byte
+	// - sender_sig: 73 bytes
+	// - zero_length: 1 byte
+	// - zero: 1 byte
+	// - witness_script_length: 1 byte
+	// - witness_script: len(script) bytes
+	return 1 + 1 + 73 + 1 + 1 + 1 + len(script)
+}
+
+// htlcScriptV2 is the htlc script with a cooperative refund path.
+type htlcScriptV2 struct{}
+
+// script returns the on-chain HTLC witness script. The sender signature is
+// required on both refund paths, which are selected by a second flag.
+//
+//	OP_IF
+//	   OP_SIZE 32 OP_EQUALVERIFY
+//	   OP_HASH160 <ripemd160(swap_hash)> OP_EQUALVERIFY
+//	   <recvr key> OP_CHECKSIG
+//	OP_ELSE
+//	   <sender key> OP_CHECKSIGVERIFY
+//	   OP_IF
+//	      <recvr key> OP_CHECKSIG
+//	   OP_ELSE
+//	      <cltv timeout> OP_CHECKLOCKTIMEVERIFY
+//	   OP_ENDIF
+//	OP_ENDIF
+func (h *htlcScriptV2) script(cltvExpiry int32, senderHtlcKey,
+	receiverHtlcKey [33]byte, swapHash lntypes.Hash) ([]byte, error) {
+
+	builder := txscript.NewScriptBuilder()
+
+	builder.AddOp(txscript.OP_IF)
+
+	builder.AddOp(txscript.OP_SIZE)
+	builder.AddInt64(32)
+	builder.AddOp(txscript.OP_EQUALVERIFY)
+
+	builder.AddOp(txscript.OP_HASH160)
+	builder.AddData(input.Ripemd160H(swapHash[:]))
+	builder.AddOp(txscript.OP_EQUALVERIFY)
+
+	builder.AddData(receiverHtlcKey[:])
+	builder.AddOp(txscript.OP_CHECKSIG)
+
+	builder.AddOp(txscript.OP_ELSE)
+
+	builder.AddData(senderHtlcKey[:])
+	builder.AddOp(txscript.OP_CHECKSIGVERIFY)
+
+	builder.AddOp(txscript.OP_IF)
+
+	builder.AddData(receiverHtlcKey[:])
+	builder.AddOp(txscript.OP_CHECKSIG)
+
+	builder.AddOp(txscript.OP_ELSE)
+
+	builder.AddInt64(int64(cltvExpiry))
+	builder.AddOp(txscript.OP_CHECKLOCKTIMEVERIFY)
+
+	builder.AddOp(txscript.OP_ENDIF)
+
+	builder.AddOp(txscript.OP_ENDIF)
+
+	return builder.Script()
+}
+
+// successWitness returns the witness that spends the htlc with the preimage.
+func (h *htlcScriptV2) successWitness(script, receiverSig []byte,
+	preimage lntypes.Preimage) wire.TxWitness {
+
+	witnessStack := make(wire.TxWitness, 4)
+	witnessStack[0] = append(receiverSig, byte(txscript.SigHashAll))
+	witnessStack[1] = preimage[:]
+	witnessStack[2] = []byte{1}
+	witnessStack[3] = script
+
+	return witnessStack
+}
+
+// isSuccessWitness returns whether the witness spends the htlc with the
+// preimage.
+func (h *htlcScriptV2) isSuccessWitness(witness wire.TxWitness) bool {
+	if len(witness) != 4 {
+		return false
+	}
+
+	return bytes.Equal([]byte{1}, witness[2])
+}
+
+// timeoutWitness returns the witness that spends the htlc after the cltv
+// expiry.
+func (h *htlcScriptV2) timeoutWitness(script,
+	senderSig []byte) wire.TxWitness {
+
+	witnessStack := make(wire.TxWitness, 4)
+	witnessStack[0] = nil
+	witnessStack[1] = append(senderSig, byte(txscript.SigHashAll))
+	witnessStack[2] = nil
+	witnessStack[3] = script
+
+	return witnessStack
+}
+
+// cooperativeRefundWitness returns the witness that spends the htlc with the
+// signatures of the sender and the receiver.
+func (h *htlcScriptV2) cooperativeRefundWitness(script, senderSig,
+	receiverSig []byte) wire.TxWitness {
+
+	witnessStack := make(wire.TxWitness, 5)
+	witnessStack[0] = append(receiverSig, byte(txscript.SigHashAll))
+	witnessStack[1] = []byte{1}
+	witnessStack[2] = append(senderSig, byte(txscript.SigHashAll))
+	witnessStack[3] = nil
+	witnessStack[4] = script
+
+	return witnessStack
+}
+
+// maxSuccessWitnessSize returns the maximum size of a success witness.
+func (h *htlcScriptV2) maxSuccessWitnessSize(script []byte) int {
+	// Calculate maximum success witness size
+	//
+	// - number_of_witness_elements: 1 byte
+	// - receiver_sig_length: 1 byte
+	// - receiver_sig: 73 bytes
+	// - preimage_length: 1 byte
+	// - preimage: 32 bytes
+	// - one_length: 1 byte
+	// - one: 1 byte
+	// - witness_script_length: 1 byte
+	// - witness_script: len(script) bytes
+	return 1 + 1 + 73 + 1 + 32 + 1 + 1 + 1 + len(script)
+}
+
+// maxTimeoutWitnessSize returns the maximum size of a timeout witness.
+func (h *htlcScriptV2) maxTimeoutWitnessSize(script []byte) int {
+	// Calculate maximum timeout witness size
+	//
+	// - number_of_witness_elements: 1 byte
+	// - empty_length: 1 byte
+	// - sender_sig_length: 1 byte
+	// - sender_sig: 73 bytes
+	// - empty_length: 1 byte
+	// - witness_script_length: 1 byte
+	// - witness_script: len(script) bytes
+	return 1 + 1 + 1 + 73 + 1 + 1 + len(script)
+}
+
+// maxCooperativeRefundWitnessSize returns the maximum size of a cooperative
+// refund witness.
+func (h *htlcScriptV2) maxCooperativeRefundWitnessSize(script []byte) int {
+	// Calculate maximum cooperative refund witness size
+	//
+	// - number_of_witness_elements: 1 byte
+	// - receiver_sig_length: 1 byte
+	// - receiver_sig: 73 bytes
+	// - one_length: 1 byte
+	// - one: 1 byte
+	// - sender_sig_length: 1 byte
+	// - sender_sig: 73 bytes
+	// - empty_length: 1 byte
+	// - witness_script_length: 1 byte
+	// - witness_script: len(script) bytes
+	return 1 + 1 + 73 + 1 + 1 + 1 + 73 + 1 + 1 + len(script)
+}
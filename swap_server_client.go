@@ -1,23 +1,41 @@
 package loop
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
 	"crypto/tls"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/wire"
 	"github.com/btcsuite/btcutil"
 	"github.com/lightninglabs/loop/lndclient"
+	"github.com/lightninglabs/loop/loopdb"
 	"github.com/lightninglabs/loop/looprpc"
 	"github.com/lightninglabs/loop/lsat"
+	"github.com/lightninglabs/loop/swap"
 	"github.com/lightningnetwork/lnd/lntypes"
+	"golang.org/x/net/proxy"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
 )
 
+// protocolVersion is the version of the swap protocol that the client speaks.
+// It is sent to the server with every request.
+const protocolVersion = looprpc.ProtocolVersion_COOPERATIVE_REFUND
+
+// idempotencyKeyHeader is the metadata key of the idempotency key that is sent
+// with requests that create state on the server. The server answers a repeated
+// request with the same key with its original response, which makes it safe to
+// retry these requests.
+const idempotencyKeyHeader = "idempotency-key"
+
 type swapServerClient interface {
 	GetLoopOutTerms(ctx context.Context) (
 		*LoopOutTerms, error)
@@ -35,13 +53,25 @@ type swapServerClient interface {
 	NewLoopOutSwap(ctx context.Context,
 		swapHash lntypes.Hash, amount btcutil.Amount,
 		receiverKey [33]byte,
-		swapPublicationDeadline time.Time) (
+		swapPublicationDeadline time.Time, quoteID []byte) (
 		*newLoopOutResponse, error)
 
 	NewLoopInSwap(ctx context.Context,
 		swapHash lntypes.Hash, amount btcutil.Amount,
-		senderKey [33]byte, swapInvoice string) (
-		*newLoopInResponse, error)
+		senderKey [33]byte, swapInvoice string, quoteID []byte,
+		htlcType swap.HtlcOutputType) (*newLoopInResponse, error)
+
+	// CooperativeLoopInRefund asks the server to co-sign a transaction
+	// that refunds a loop in htlc before its expiry. It returns the DER
+	// encoded signature of the receiver key.
+	CooperativeLoopInRefund(ctx context.Context, swapHash lntypes.Hash,
+		refundTx *wire.MsgTx, htlcValue btcutil.Amount) ([]byte, error)
+
+	// SubscribeUpdates subscribes to the status updates of the given
+	// swaps and to changes of the server terms. The subscription ends
+	// when the context is canceled or an error is delivered.
+	SubscribeUpdates(ctx context.Context, swapHashes []lntypes.Hash) (
+		<-chan *ServerUpdate, <-chan error, error)
 }
 
 type grpcSwapServerClient struct {
@@ -51,8 +81,8 @@ type grpcSwapServerClient struct {
 
 var _ swapServerClient = (*grpcSwapServerClient)(nil)
 
-func newSwapServerClient(address string, insecure bool, tlsPath string,
-	lsatStore lsat.Store, lnd *lndclient.LndServices,
+func newSwapServerClient(cfg *ServerConfig, lsatStore lsat.Store,
+	lnd *lndclient.LndServices,
 	maxLSATCost, maxLSATFee btcutil.Amount) (*grpcSwapServerClient, error) {
 
 	// Create the server connection with the interceptor that will handle
@@ -60,9 +90,7 @@ func newSwapServerClient(address string, insecure bool, tlsPath string,
 	clientInterceptor := lsat.NewInterceptor(
 		lnd, lsatStore, serverRPCTimeout, maxLSATCost, maxLSATFee,
 	)
-	serverConn, err := getSwapServerConn(
-		address, insecure, tlsPath, clientInterceptor,
-	)
+	serverConn, err := getSwapServerConn(cfg, clientInterceptor)
 	if err != nil {
 		return nil, err
 	}
@@ -81,7 +109,9 @@ func (s *grpcSwapServerClient) GetLoopOutTerms(ctx context.Context) (
 	rpcCtx, rpcCancel := context.WithTimeout(ctx, globalCallTimeout)
 	defer rpcCancel()
 	terms, err := s.server.LoopOutTerms(rpcCtx,
-		&looprpc.ServerLoopOutTermsRequest{},
+		&looprpc.ServerLoopOutTermsRequest{
+			ProtocolVersion: protocolVersion,
+		},
 	)
 	if err != nil {
 		return nil, err
@@ -103,6 +133,7 @@ func (s *grpcSwapServerClient) GetLoopOutQuote(ctx context.Context,
 		&looprpc.ServerLoopOutQuoteRequest{
 			Amt:                     uint64(amt),
 			SwapPublicationDeadline: swapPublicationDeadline.Unix(),
+			ProtocolVersion:         protocolVersion,
 		},
 	)
 	if err != nil {
@@ -119,11 +150,15 @@ func (s *grpcSwapServerClient) GetLoopOutQuote(ctx context.Context,
 	var destArray [33]byte
 	copy(destArray[:], dest)
 
+	quoteExpiry := unmarshallQuoteExpiry(quoteResp.QuoteExpiry)
+
 	return &LoopOutQuote{
 		PrepayAmount:    btcutil.Amount(quoteResp.PrepayAmt),
 		SwapFee:         btcutil.Amount(quoteResp.SwapFee),
 		CltvDelta:       quoteResp.CltvDelta,
 		SwapPaymentDest: destArray,
+		QuoteID:         quoteResp.QuoteId,
+		QuoteExpiry:     quoteExpiry,
 	}, nil
 }
 
@@ -133,7 +168,9 @@ func (s *grpcSwapServerClient) GetLoopInTerms(ctx context.Context) (
 	rpcCtx, rpcCancel := context.WithTimeout(ctx, globalCallTimeout)
 	defer rpcCancel()
 	terms, err := s.server.LoopInTerms(rpcCtx,
-		&looprpc.ServerLoopInTermsRequest{},
+		&looprpc.ServerLoopInTermsRequest{
+			ProtocolVersion: protocolVersion,
+		},
 	)
 	if err != nil {
 		return nil, err
@@ -152,7 +189,8 @@ func (s *grpcSwapServerClient) GetLoopInQuote(ctx context.Context,
 	defer rpcCancel()
 	quoteResp, err := s.server.LoopInQuote(rpcCtx,
 		&looprpc.ServerLoopInQuoteRequest{
-			Amt: uint64(amt),
+			Amt:             uint64(amt),
+			ProtocolVersion: protocolVersion,
 		},
 	)
 	if err != nil {
@@ -160,17 +198,21 @@ func (s *grpcSwapServerClient) GetLoopInQuote(ctx context.Context,
 	}
 
 	return &LoopInQuote{
-		SwapFee:   btcutil.Amount(quoteResp.SwapFee),
-		CltvDelta: quoteResp.CltvDelta,
+		SwapFee:     btcutil.Amount(quoteResp.SwapFee),
+		CltvDelta:   quoteResp.CltvDelta,
+		QuoteID:     quoteResp.QuoteId,
+		QuoteExpiry: unmarshallQuoteExpiry(quoteResp.QuoteExpiry),
 	}, nil
 }
 
 func (s *grpcSwapServerClient) NewLoopOutSwap(ctx context.Context,
 	swapHash lntypes.Hash, amount btcutil.Amount,
-	receiverKey [33]byte, swapPublicationDeadline time.Time) (
-	*newLoopOutResponse, error) {
+	receiverKey [33]byte, swapPublicationDeadline time.Time,
+	quoteID []byte) (*newLoopOutResponse, error) {
 
-	rpcCtx, rpcCancel := context.WithTimeout(ctx, globalCallTimeout)
+	rpcCtx, rpcCancel := context.WithTimeout(
+		withIdempotencyKey(ctx, swapHash), globalCallTimeout,
+	)
 	defer rpcCancel()
 	swapResp, err := s.server.NewLoopOutSwap(rpcCtx,
 		&looprpc.ServerLoopOutRequest{
@@ -178,6 +220,8 @@ func (s *grpcSwapServerClient) NewLoopOutSwap(ctx context.Context,
 			Amt:                     uint64(amount),
 			ReceiverKey:             receiverKey[:],
 			SwapPublicationDeadline: swapPublicationDeadline.Unix(),
+			QuoteId:                 quoteID,
+			ProtocolVersion:         protocolVersion,
 		},
 	)
 	if err != nil {
@@ -193,26 +237,48 @@ func (s *grpcSwapServerClient) NewLoopOutSwap(ctx context.Context,
 		return nil, fmt.Errorf("invalid sender key: %v", err)
 	}
 
+	htlcVersion, err := unmarshallHtlcVersion(swapResp.HtlcVersion)
+	if err != nil {
+		return nil, err
+	}
+
 	return &newLoopOutResponse{
 		swapInvoice:   swapResp.SwapInvoice,
 		prepayInvoice: swapResp.PrepayInvoice,
 		senderKey:     senderKey,
 		expiry:        swapResp.Expiry,
+		htlcVersion:   htlcVersion,
 	}, nil
 }
 
 func (s *grpcSwapServerClient) NewLoopInSwap(ctx context.Context,
 	swapHash lntypes.Hash, amount btcutil.Amount, senderKey [33]byte,
-	swapInvoice string) (*newLoopInResponse, error) {
+	swapInvoice string, quoteID []byte, htlcType swap.HtlcOutputType) (
+	*newLoopInResponse, error) {
+
+	var rpcHtlcType looprpc.HtlcType
+	switch htlcType {
+	case swap.HtlcNP2WSH:
+		rpcHtlcType = looprpc.HtlcType_HTLC_NP2WSH
+	case swap.HtlcP2WSH:
+		rpcHtlcType = looprpc.HtlcType_HTLC_P2WSH
+	default:
+		return nil, fmt.Errorf("unknown htlc type %v", htlcType)
+	}
 
-	rpcCtx, rpcCancel := context.WithTimeout(ctx, globalCallTimeout)
+	rpcCtx, rpcCancel := context.WithTimeout(
+		withIdempotencyKey(ctx, swapHash), globalCallTimeout,
+	)
 	defer rpcCancel()
 	swapResp, err := s.server.NewLoopInSwap(rpcCtx,
 		&looprpc.ServerLoopInRequest{
-			SwapHash:    swapHash[:],
-			Amt:         uint64(amount),
-			SenderKey:   senderKey[:],
-			SwapInvoice: swapInvoice,
+			SwapHash:        swapHash[:],
+			Amt:             uint64(amount),
+			SenderKey:       senderKey[:],
+			SwapInvoice:     swapInvoice,
+			QuoteId:         quoteID,
+			HtlcType:        rpcHtlcType,
+			ProtocolVersion: protocolVersion,
 		},
 	)
 	if err != nil {
@@ -228,18 +294,208 @@ func (s *grpcSwapServerClient) NewLoopInSwap(ctx context.Context,
 		return nil, fmt.Errorf("invalid sender key: %v", err)
 	}
 
+	var respHtlcType swap.HtlcOutputType
+	switch swapResp.HtlcType {
+	case looprpc.HtlcType_HTLC_NP2WSH:
+		respHtlcType = swap.HtlcNP2WSH
+	case looprpc.HtlcType_HTLC_P2WSH:
+		respHtlcType = swap.HtlcP2WSH
+	default:
+		return nil, fmt.Errorf("unknown htlc type %v",
+			swapResp.HtlcType)
+	}
+
+	htlcVersion, err := unmarshallHtlcVersion(swapResp.HtlcVersion)
+	if err != nil {
+		return nil, err
+	}
+
 	return &newLoopInResponse{
 		receiverKey: receiverKey,
 		expiry:      swapResp.Expiry,
+		htlcType:    respHtlcType,
+		htlcVersion: htlcVersion,
 	}, nil
 }
 
+func (s *grpcSwapServerClient) CooperativeLoopInRefund(ctx context.Context,
+	swapHash lntypes.Hash, refundTx *wire.MsgTx,
+	htlcValue btcutil.Amount) ([]byte, error) {
+
+	var txBuf bytes.Buffer
+	if err := refundTx.Serialize(&txBuf); err != nil {
+		return nil, err
+	}
+
+	rpcCtx, rpcCancel := context.WithTimeout(ctx, globalCallTimeout)
+	defer rpcCancel()
+	resp, err := s.server.CooperativeLoopInRefund(rpcCtx,
+		&looprpc.ServerCooperativeLoopInRefundRequest{
+			SwapHash:        swapHash[:],
+			RefundTx:        txBuf.Bytes(),
+			HtlcValue:       int64(htlcValue),
+			ProtocolVersion: protocolVersion,
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.ReceiverSig, nil
+}
+
+func (s *grpcSwapServerClient) SubscribeUpdates(ctx context.Context,
+	swapHashes []lntypes.Hash) (<-chan *ServerUpdate, <-chan error,
+	error) {
+
+	rpcHashes := make([][]byte, 0, len(swapHashes))
+	for _, hash := range swapHashes {
+		hash := hash
+		rpcHashes = append(rpcHashes, hash[:])
+	}
+
+	// The stream lives until the context is canceled, so it isn't subject
+	// to the call timeout.
+	stream, err := s.server.SubscribeUpdates(ctx,
+		&looprpc.ServerSubscribeUpdatesRequest{
+			SwapHashes:      rpcHashes,
+			ProtocolVersion: protocolVersion,
+		},
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	updateChan := make(chan *ServerUpdate)
+	errChan := make(chan error, 1)
+	go func() {
+		for {
+			rpcUpdate, err := stream.Recv()
+			if err != nil {
+				errChan <- err
+				return
+			}
+
+			update, err := unmarshallServerUpdate(rpcUpdate)
+			if err != nil {
+				errChan <- err
+				return
+			}
+
+			// Skip updates of a kind that this client doesn't
+			// know.
+			if update == nil {
+				continue
+			}
+
+			select {
+			case updateChan <- update:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updateChan, errChan, nil
+}
+
+// unmarshallServerUpdate converts an update that the server pushed. It returns
+// nil for updates that this client doesn't know.
+func unmarshallServerUpdate(rpcUpdate *looprpc.ServerUpdate) (*ServerUpdate,
+	error) {
+
+	if rpcTerms := rpcUpdate.GetTermsUpdate(); rpcTerms != nil {
+		terms := &TermsUpdate{
+			FeesChanged: rpcTerms.FeesChanged,
+		}
+		if rpcTerms.LoopOutTerms != nil {
+			terms.LoopOutTerms = &LoopOutTerms{
+				MinSwapAmount: btcutil.Amount(
+					rpcTerms.LoopOutTerms.MinSwapAmount,
+				),
+				MaxSwapAmount: btcutil.Amount(
+					rpcTerms.LoopOutTerms.MaxSwapAmount,
+				),
+			}
+		}
+		if rpcTerms.LoopInTerms != nil {
+			terms.LoopInTerms = &LoopInTerms{
+				MinSwapAmount: btcutil.Amount(
+					rpcTerms.LoopInTerms.MinSwapAmount,
+				),
+				MaxSwapAmount: btcutil.Amount(
+					rpcTerms.LoopInTerms.MaxSwapAmount,
+				),
+			}
+		}
+
+		return &ServerUpdate{TermsUpdate: terms}, nil
+	}
+
+	rpcSwap := rpcUpdate.GetSwapUpdate()
+	if rpcSwap == nil {
+		return nil, nil
+	}
+
+	hash, err := lntypes.MakeHash(rpcSwap.SwapHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var state loopdb.ServerSwapState
+	switch rpcSwap.State {
+	case looprpc.ServerSwapState_SERVER_SWAP_ACCEPTED:
+		state = loopdb.ServerSwapAccepted
+	case looprpc.ServerSwapState_SERVER_SWAP_HTLC_DELAYED:
+		state = loopdb.ServerHtlcDelayed
+	case looprpc.ServerSwapState_SERVER_SWAP_HTLC_PUBLISHED:
+		state = loopdb.ServerHtlcPublished
+	case looprpc.ServerSwapState_SERVER_SWAP_REJECTED:
+		state = loopdb.ServerSwapRejected
+	default:
+		log.Warnf("Unknown server state %v for swap %v",
+			rpcSwap.State, hash)
+
+		return nil, nil
+	}
+
+	update := &loopdb.ServerSwapUpdate{
+		Time:   time.Now(),
+		State:  state,
+		Reason: rpcSwap.Reason,
+	}
+	if rpcSwap.ExpectedPublication != 0 {
+		update.ExpectedPublication = time.Unix(
+			rpcSwap.ExpectedPublication, 0,
+		)
+	}
+
+	return &ServerUpdate{
+		SwapHash:   hash,
+		SwapUpdate: update,
+	}, nil
+}
+
+// unmarshallHtlcVersion converts the htlc script version that the server chose
+// for a swap and checks that the client supports it.
+func unmarshallHtlcVersion(version looprpc.HtlcScriptVersion) (
+	swap.ScriptVersion, error) {
+
+	htlcVersion := swap.ScriptVersion(version)
+	if !swap.IsSupportedScriptVersion(htlcVersion) {
+		return 0, fmt.Errorf("server requires unsupported htlc "+
+			"script version %v", version)
+	}
+
+	return htlcVersion, nil
+}
+
 func (s *grpcSwapServerClient) Close() {
 	s.conn.Close()
 }
 
 // getSwapServerConn returns a connection to the swap server.
-func getSwapServerConn(address string, insecure bool, tlsPath string,
+func getSwapServerConn(cfg *ServerConfig,
 	interceptor *lsat.Interceptor) (*grpc.ClientConn, error) {
 
 	// Create a dial options array.
@@ -247,17 +503,28 @@ func getSwapServerConn(address string, insecure bool, tlsPath string,
 		interceptor.UnaryInterceptor,
 	)}
 
+	// If a proxy is configured, all connections to the server are dialed
+	// through it. The server address is resolved by the proxy, so that
+	// neither our ip address nor dns lookups of the server are exposed.
+	if cfg.Proxy != "" {
+		opts = append(opts, grpc.WithContextDialer(
+			proxyDialer(cfg.Proxy, cfg.StreamIsolation),
+		))
+	}
+
 	// There are three options to connect to a swap server, either insecure,
 	// using a self-signed certificate or with a certificate signed by a
 	// public CA.
 	switch {
-	case insecure:
+	case cfg.Insecure:
 		opts = append(opts, grpc.WithInsecure())
 
-	case tlsPath != "":
+	case cfg.TLSPath != "":
 		// Load the specified TLS certificate and build
 		// transport credentials
-		creds, err := credentials.NewClientTLSFromFile(tlsPath, "")
+		creds, err := credentials.NewClientTLSFromFile(
+			cfg.TLSPath, "",
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -268,7 +535,7 @@ func getSwapServerConn(address string, insecure bool, tlsPath string,
 		opts = append(opts, grpc.WithTransportCredentials(creds))
 	}
 
-	conn, err := grpc.Dial(address, opts...)
+	conn, err := grpc.Dial(cfg.Address, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to connect to RPC server: %v",
 			err)
@@ -277,14 +544,67 @@ func getSwapServerConn(address string, insecure bool, tlsPath string,
 	return conn, nil
 }
 
+// unmarshallQuoteExpiry converts the unix quote expiry returned by the server
+// into a time. Servers that don't support quote ids return zero, which is
+// mapped to the zero time.
+func unmarshallQuoteExpiry(expiry int64) time.Time {
+	if expiry == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(expiry, 0)
+}
+
 type newLoopOutResponse struct {
 	swapInvoice   string
 	prepayInvoice string
 	senderKey     [33]byte
 	expiry        int32
+	htlcVersion   swap.ScriptVersion
 }
 
 type newLoopInResponse struct {
 	receiverKey [33]byte
 	expiry      int32
+	htlcType    swap.HtlcOutputType
+	htlcVersion swap.ScriptVersion
+}
+
+// proxyDialer returns a dialer that connects through the SOCKS5 proxy at the
+// given address. With stream isolation, every connection uses fresh random
+// credentials, which makes Tor build a new circuit for it.
+func proxyDialer(proxyAddr string, streamIsolation bool) func(context.Context,
+	string) (net.Conn, error) {
+
+	return func(_ context.Context, address string) (net.Conn, error) {
+		var auth *proxy.Auth
+		if streamIsolation {
+			var b [16]byte
+			if _, err := rand.Read(b[:]); err != nil {
+				return nil, err
+			}
+
+			auth = &proxy.Auth{
+				User:     hex.EncodeToString(b[:8]),
+				Password: hex.EncodeToString(b[8:]),
+			}
+		}
+
+		dialer, err := proxy.SOCKS5("tcp", proxyAddr, auth, proxy.Direct)
+		if err != nil {
+			return nil, err
+		}
+
+		return dialer.Dial("tcp", address)
+	}
+}
+
+// withIdempotencyKey adds the swap hash as idempotency key to the outgoing
+// metadata of the context.
+func withIdempotencyKey(ctx context.Context,
+	swapHash lntypes.Hash) context.Context {
+
+	return metadata.AppendToOutgoingContext(
+		ctx, idempotencyKeyHeader, swapHash.String(),
+	)
 }
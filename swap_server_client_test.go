@@ -0,0 +1,293 @@
+package loop
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/lightninglabs/loop/looprpc"
+	"github.com/lightninglabs/loop/lsat"
+	"github.com/lightninglabs/loop/test"
+	"google.golang.org/grpc"
+)
+
+// termsServer is a swap server that only serves loop out terms.
+type termsServer struct {
+	looprpc.SwapServerServer
+}
+
+func (s *termsServer) LoopOutTerms(ctx context.Context,
+	req *looprpc.ServerLoopOutTermsRequest) (
+	*looprpc.ServerLoopOutTerms, error) {
+
+	return &looprpc.ServerLoopOutTerms{
+		MinSwapAmount: 123,
+		MaxSwapAmount: 456,
+	}, nil
+}
+
+// socksRequest is a connect request that the socks stand-in received.
+type socksRequest struct {
+	target string
+	user   string
+}
+
+// socksStandIn is a minimal SOCKS5 proxy that records the connect requests
+// it receives and relays the connections to their target.
+type socksStandIn struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	requests []socksRequest
+}
+
+func newSocksStandIn(t *testing.T) *socksStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := &socksStandIn{listener: listener}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.handle(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *socksStandIn) getRequests() []socksRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]socksRequest(nil), s.requests...)
+}
+
+func (s *socksStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+
+	req, err := s.handshake(conn)
+	if err != nil {
+		return
+	}
+
+	target, err := net.Dial("tcp", req.target)
+	if err != nil {
+		return
+	}
+	defer target.Close()
+
+	s.mu.Lock()
+	s.requests = append(s.requests, *req)
+	s.mu.Unlock()
+
+	// Report success with an unspecified bound address.
+	_, err = conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	if err != nil {
+		return
+	}
+
+	go func() {
+		_, _ = io.Copy(target, conn)
+		target.Close()
+	}()
+	_, _ = io.Copy(conn, target)
+}
+
+// handshake reads the method negotiation, the optional username/password
+// authentication and the connect request of a client.
+func (s *socksStandIn) handshake(conn net.Conn) (*socksRequest, error) {
+	readN := func(n int) ([]byte, error) {
+		b := make([]byte, n)
+		_, err := io.ReadFull(conn, b)
+		return b, err
+	}
+
+	header, err := readN(2)
+	if err != nil {
+		return nil, err
+	}
+	methods, err := readN(int(header[1]))
+	if err != nil {
+		return nil, err
+	}
+
+	// Prefer username/password authentication if it is offered.
+	method := byte(0)
+	for _, m := range methods {
+		if m == 2 {
+			method = 2
+		}
+	}
+	if _, err := conn.Write([]byte{5, method}); err != nil {
+		return nil, err
+	}
+
+	req := &socksRequest{}
+	if method == 2 {
+		header, err := readN(2)
+		if err != nil {
+			return nil, err
+		}
+		user, err := readN(int(header[1]))
+		if err != nil {
+			return nil, err
+		}
+		passLen, err := readN(1)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := readN(int(passLen[0])); err != nil {
+			return nil, err
+		}
+		if _, err := conn.Write([]byte{1, 0}); err != nil {
+			return nil, err
+		}
+		req.user = string(user)
+	}
+
+	header, err = readN(4)
+	if err != nil {
+		return nil, err
+	}
+	if header[1] != 1 {
+		return nil, errors.New("only connect is supported")
+	}
+
+	var host string
+	switch header[3] {
+	case 1:
+		ip, err := readN(net.IPv4len)
+		if err != nil {
+			return nil, err
+		}
+		host = net.IP(ip).String()
+
+	case 3:
+		hostLen, err := readN(1)
+		if err != nil {
+			return nil, err
+		}
+		name, err := readN(int(hostLen[0]))
+		if err != nil {
+			return nil, err
+		}
+		host = string(name)
+
+	default:
+		return nil, errors.New("unsupported address type")
+	}
+
+	port, err := readN(2)
+	if err != nil {
+		return nil, err
+	}
+	req.target = net.JoinHostPort(
+		host, strconv.Itoa(int(binary.BigEndian.Uint16(port))),
+	)
+
+	return req, nil
+}
+
+// TestSwapServerProxy tests that the connection to the swap server goes
+// through the configured proxy and that every connection uses its own
+// credentials if stream isolation is enabled.
+func TestSwapServerProxy(t *testing.T) {
+	defer test.Guard(t)()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	grpcServer := grpc.NewServer()
+	looprpc.RegisterSwapServerServer(grpcServer, &termsServer{})
+	go func() { _ = grpcServer.Serve(listener) }()
+	defer grpcServer.Stop()
+
+	proxy := newSocksStandIn(t)
+	defer proxy.listener.Close()
+
+	lnd := test.NewMockLnd()
+	tempDir, err := ioutil.TempDir("", "proxytest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	lsatStore, err := lsat.NewFileStore(tempDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	serverAddr := listener.Addr().String()
+	connect := func(streamIsolation bool) {
+		client, err := newSwapServerClient(&ServerConfig{
+			Address:         serverAddr,
+			Insecure:        true,
+			Proxy:           proxy.listener.Addr().String(),
+			StreamIsolation: streamIsolation,
+		}, lsatStore, &lnd.LndServices, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer client.Close()
+
+		terms, err := client.GetLoopOutTerms(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if terms.MinSwapAmount != 123 || terms.MaxSwapAmount != 456 {
+			t.Fatalf("unexpected terms %v", terms)
+		}
+	}
+
+	// Without stream isolation, no credentials are sent.
+	connect(false)
+	requests := proxy.getRequests()
+	if len(requests) != 1 {
+		t.Fatalf("expected one proxied connection, got %v",
+			len(requests))
+	}
+	if requests[0].target != serverAddr || requests[0].user != "" {
+		t.Fatalf("unexpected request %v", requests[0])
+	}
+
+	// With stream isolation, every connection uses different
+	// credentials.
+	connect(true)
+	connect(true)
+	requests = proxy.getRequests()
+	if len(requests) != 3 {
+		t.Fatalf("expected three proxied connections, got %v",
+			len(requests))
+	}
+	for _, req := range requests[1:] {
+		if req.target != serverAddr || req.user == "" {
+			t.Fatalf("unexpected request %v", req)
+		}
+	}
+	if requests[1].user == requests[2].user {
+		t.Fatal("expected different credentials per connection")
+	}
+
+	// An onion address can't be reached without proxy.
+	err = validateServerConfigs([]ServerConfig{{
+		ID:      "onion",
+		Address: "3g2upl4pq6kufc4m.onion:11009",
+	}})
+	if err == nil {
+		t.Fatal("expected onion address without proxy to fail")
+	}
+}
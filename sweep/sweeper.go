@@ -12,6 +12,7 @@ import (
 	"github.com/lightninglabs/loop/swap"
 	"github.com/lightningnetwork/lnd/input"
 	"github.com/lightningnetwork/lnd/keychain"
+	"github.com/lightningnetwork/lnd/lnwallet/chainfee"
 )
 
 // Sweeper creates htlc sweep txes.
@@ -19,14 +20,14 @@ type Sweeper struct {
 	Lnd *lndclient.LndServices
 }
 
-// CreateSweepTx creates an htlc sweep tx.
+// CreateSweepTx creates an htlc sweep tx that pays to the given outputs. The
+// fee of the tx is the htlc amount minus the total value of the outputs.
 func (s *Sweeper) CreateSweepTx(
 	globalCtx context.Context, height int32,
 	htlc *swap.Htlc, htlcOutpoint wire.OutPoint,
 	keyBytes [33]byte,
 	witnessFunc func(sig []byte) (wire.TxWitness, error),
-	amount, fee btcutil.Amount,
-	destAddr btcutil.Address) (*wire.MsgTx, error) {
+	amount btcutil.Amount, outputs []*wire.TxOut) (*wire.MsgTx, error) {
 
 	// Compose tx.
 	sweepTx := wire.NewMsgTx(2)
@@ -39,17 +40,11 @@ func (s *Sweeper) CreateSweepTx(
 		SignatureScript:  htlc.SigScript,
 	})
 
-	// Add output for the destination address.
-	sweepPkScript, err := txscript.PayToAddrScript(destAddr)
-	if err != nil {
-		return nil, err
+	// Add the outputs for the destination addresses.
+	for _, output := range outputs {
+		sweepTx.AddTxOut(output)
 	}
 
-	sweepTx.AddTxOut(&wire.TxOut{
-		PkScript: sweepPkScript,
-		Value:    int64(amount - fee),
-	})
-
 	// Generate a signature for the swap htlc transaction.
 
 	key, err := btcec.ParsePubKey(keyBytes[:], btcec.S256())
@@ -86,12 +81,12 @@ func (s *Sweeper) CreateSweepTx(
 	return sweepTx, nil
 }
 
-// GetSweepFee calculates the required tx fee to spend to P2WKH. It takes a
-// function that is expected to add the weight of the input to the weight
-// estimator.
+// GetSweepFee calculates the required tx fee to spend to the destination
+// addresses. It takes a function that is expected to add the weight of the
+// input to the weight estimator.
 func (s *Sweeper) GetSweepFee(ctx context.Context,
 	addInputEstimate func(*input.TxWeightEstimator),
-	destAddr btcutil.Address, sweepConfTarget int32) (
+	destAddrs []btcutil.Address, sweepConfTarget int32) (
 	btcutil.Amount, error) {
 
 	// Get fee estimate from lnd.
@@ -100,19 +95,33 @@ func (s *Sweeper) GetSweepFee(ctx context.Context,
 		return 0, fmt.Errorf("estimate fee: %v", err)
 	}
 
+	return s.GetSweepFeeForRate(addInputEstimate, destAddrs, feeRate)
+}
+
+// GetSweepFeeForRate calculates the required tx fee to spend to the
+// destination addresses at the given fee rate. Every address adds the weight
+// of one output.
+func (s *Sweeper) GetSweepFeeForRate(
+	addInputEstimate func(*input.TxWeightEstimator),
+	destAddrs []btcutil.Address, feeRate chainfee.SatPerKWeight) (
+	btcutil.Amount, error) {
+
 	// Calculate weight for this tx.
 	var weightEstimate input.TxWeightEstimator
-	switch destAddr.(type) {
-	case *btcutil.AddressWitnessScriptHash:
-		weightEstimate.AddP2WSHOutput()
-	case *btcutil.AddressWitnessPubKeyHash:
-		weightEstimate.AddP2WKHOutput()
-	case *btcutil.AddressScriptHash:
-		weightEstimate.AddP2SHOutput()
-	case *btcutil.AddressPubKeyHash:
-		weightEstimate.AddP2PKHOutput()
-	default:
-		return 0, fmt.Errorf("unknown address type %T", destAddr)
+	for _, destAddr := range destAddrs {
+		switch destAddr.(type) {
+		case *btcutil.AddressWitnessScriptHash:
+			weightEstimate.AddP2WSHOutput()
+		case *btcutil.AddressWitnessPubKeyHash:
+			weightEstimate.AddP2WKHOutput()
+		case *btcutil.AddressScriptHash:
+			weightEstimate.AddP2SHOutput()
+		case *btcutil.AddressPubKeyHash:
+			weightEstimate.AddP2PKHOutput()
+		default:
+			return 0, fmt.Errorf("unknown address type %T",
+				destAddr)
+		}
 	}
 
 	addInputEstimate(&weightEstimate)
@@ -0,0 +1,158 @@
+package loop
+
+import (
+	"errors"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/loopdb"
+	"github.com/lightningnetwork/lnd/lnwallet"
+)
+
+var (
+	// ErrInvalidSweepOutput is returned when a sweep output doesn't
+	// specify exactly one of a fixed amount and a weight.
+	ErrInvalidSweepOutput = errors.New("sweep output requires either " +
+		"an amount or a weight")
+
+	// ErrNoWeightedSweepOutput is returned when none of the sweep outputs
+	// is weighted. At least one weighted output is required to absorb the
+	// sweep fee, which isn't known when the swap is initiated.
+	ErrNoWeightedSweepOutput = errors.New("at least one weighted sweep " +
+		"output required")
+
+	// ErrSweepOutputDestMismatch is returned when the destination address
+	// of a loop out request isn't the address of its first sweep output.
+	ErrSweepOutputDestMismatch = errors.New("destination address must be " +
+		"the address of the first sweep output")
+
+	// ErrSweepOutputDust is returned when a sweep output would be below
+	// the dust limit.
+	ErrSweepOutputDust = errors.New("sweep output below dust limit")
+
+	// ErrSweepOutputsExceedAmount is returned when the fixed sweep outputs
+	// and the maximum miner fee leave nothing for the weighted outputs.
+	ErrSweepOutputsExceedAmount = errors.New("fixed sweep outputs and " +
+		"max miner fee exceed swap amount")
+
+	// ErrSplitSweepOutputs is returned when a split loop out has sweep
+	// outputs. Their addresses would be reused by every part.
+	ErrSplitSweepOutputs = errors.New("sweep outputs not supported for " +
+		"split swaps")
+)
+
+// validateSweepOutputs checks that the sweep outputs of a loop out request
+// can be paid from the swap amount.
+func validateSweepOutputs(request *OutRequest) error {
+	if len(request.SweepOutputs) == 0 {
+		return nil
+	}
+
+	var (
+		fixed    btcutil.Amount
+		weighted bool
+	)
+	for _, output := range request.SweepOutputs {
+		switch {
+		case output.Addr == nil:
+			return errors.New("sweep output address required")
+
+		case (output.Amount == 0) == (output.Weight == 0):
+			return ErrInvalidSweepOutput
+
+		case output.Amount < 0:
+			return ErrInvalidSweepOutput
+
+		case output.Weight != 0:
+			weighted = true
+
+		case output.Amount < lnwallet.DefaultDustLimit():
+			return ErrSweepOutputDust
+
+		default:
+			fixed += output.Amount
+		}
+	}
+
+	if !weighted {
+		return ErrNoWeightedSweepOutput
+	}
+
+	if request.DestAddr == nil || request.SweepOutputs[0].Addr.String() !=
+		request.DestAddr.String() {
+
+		return ErrSweepOutputDestMismatch
+	}
+
+	if fixed+request.MaxMinerFee >= request.Amount {
+		return ErrSweepOutputsExceedAmount
+	}
+
+	return nil
+}
+
+// sweepOutputAddrs returns the addresses of the given sweep outputs.
+func sweepOutputAddrs(outputs []loopdb.SweepOutput) []btcutil.Address {
+	addrs := make([]btcutil.Address, 0, len(outputs))
+	for _, output := range outputs {
+		addrs = append(addrs, output.Addr)
+	}
+
+	return addrs
+}
+
+// sweepTxOuts divides the value of a sweep tx after fees over its outputs.
+// Fixed outputs receive their amount and the remainder is divided over the
+// weighted outputs in proportion to their weights. The rounding remainder goes
+// to the last weighted output.
+func sweepTxOuts(outputs []loopdb.SweepOutput,
+	value btcutil.Amount) ([]*wire.TxOut, error) {
+
+	remaining := value
+	var totalWeight, lastWeighted int
+	for i, output := range outputs {
+		remaining -= output.Amount
+		totalWeight += int(output.Weight)
+		if output.Weight != 0 {
+			lastWeighted = i
+		}
+	}
+
+	if totalWeight == 0 {
+		return nil, ErrNoWeightedSweepOutput
+	}
+
+	dustLimit := lnwallet.DefaultDustLimit()
+
+	txOuts := make([]*wire.TxOut, 0, len(outputs))
+	distributed := btcutil.Amount(0)
+	for i, output := range outputs {
+		amt := output.Amount
+		if output.Weight != 0 {
+			amt = remaining * btcutil.Amount(output.Weight) /
+				btcutil.Amount(totalWeight)
+
+			if i == lastWeighted {
+				amt = remaining - distributed
+			}
+			distributed += amt
+		}
+
+		if amt < dustLimit {
+			return nil, ErrSweepOutputDust
+		}
+
+		pkScript, err := txscript.PayToAddrScript(output.Addr)
+		if err != nil {
+			return nil, err
+		}
+
+		txOuts = append(txOuts, &wire.TxOut{
+			PkScript: pkScript,
+			Value:    int64(amt),
+		})
+	}
+
+	return txOuts, nil
+}
@@ -0,0 +1,154 @@
+package loop
+
+import (
+	"testing"
+
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcutil"
+	"github.com/lightninglabs/loop/loopdb"
+)
+
+// TestSweepOutputs tests the validation of sweep outputs and the division of
+// the swept value over them.
+func TestSweepOutputs(t *testing.T) {
+	hotAddr, err := btcutil.NewAddressWitnessPubKeyHash(
+		make([]byte, 20), &chaincfg.TestNet3Params,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weighted := []loopdb.SweepOutput{
+		{Addr: testAddr, Weight: 70},
+		{Addr: hotAddr, Weight: 30},
+	}
+	fixed := []loopdb.SweepOutput{
+		{Addr: testAddr, Weight: 1},
+		{Addr: hotAddr, Amount: 20000},
+	}
+
+	validateTests := []struct {
+		name    string
+		dest    btcutil.Address
+		outputs []loopdb.SweepOutput
+		err     error
+	}{
+		{
+			name: "no outputs",
+			dest: testAddr,
+		},
+		{
+			name:    "weighted",
+			dest:    testAddr,
+			outputs: weighted,
+		},
+		{
+			name:    "fixed",
+			dest:    testAddr,
+			outputs: fixed,
+		},
+		{
+			name:    "dest mismatch",
+			dest:    hotAddr,
+			outputs: weighted,
+			err:     ErrSweepOutputDestMismatch,
+		},
+		{
+			name: "amount and weight",
+			dest: testAddr,
+			outputs: []loopdb.SweepOutput{
+				{Addr: testAddr, Amount: 20000, Weight: 1},
+			},
+			err: ErrInvalidSweepOutput,
+		},
+		{
+			name: "no weighted output",
+			dest: testAddr,
+			outputs: []loopdb.SweepOutput{
+				{Addr: testAddr, Amount: 20000},
+			},
+			err: ErrNoWeightedSweepOutput,
+		},
+		{
+			name: "dust",
+			dest: testAddr,
+			outputs: []loopdb.SweepOutput{
+				{Addr: testAddr, Weight: 1},
+				{Addr: hotAddr, Amount: 100},
+			},
+			err: ErrSweepOutputDust,
+		},
+		{
+			name: "exceeds amount",
+			dest: testAddr,
+			outputs: []loopdb.SweepOutput{
+				{Addr: testAddr, Weight: 1},
+				{Addr: hotAddr, Amount: 40000},
+			},
+			err: ErrSweepOutputsExceedAmount,
+		},
+	}
+
+	for _, test := range validateTests {
+		request := &OutRequest{
+			Amount:       50000,
+			DestAddr:     test.dest,
+			MaxMinerFee:  10000,
+			SweepOutputs: test.outputs,
+		}
+		err := validateSweepOutputs(request)
+		if err != test.err {
+			t.Fatalf("%v: expected error %v, got %v", test.name,
+				test.err, err)
+		}
+	}
+
+	// assertValues asserts the values and addresses of the tx outputs
+	// that the given value is divided into.
+	assertValues := func(outputs []loopdb.SweepOutput,
+		value btcutil.Amount, expected []int64) {
+
+		t.Helper()
+
+		txOuts, err := sweepTxOuts(outputs, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(txOuts) != len(expected) {
+			t.Fatalf("expected %v outputs, got %v", len(expected),
+				len(txOuts))
+		}
+
+		for i, txOut := range txOuts {
+			if txOut.Value != expected[i] {
+				t.Fatalf("expected output %v value %v, got %v",
+					i, expected[i], txOut.Value)
+			}
+
+			pkScript, err := txscript.PayToAddrScript(
+				outputs[i].Addr,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(txOut.PkScript) != string(pkScript) {
+				t.Fatalf("unexpected pk script of output %v", i)
+			}
+		}
+	}
+
+	// The weighted value is divided 70/30 with the rounding remainder
+	// going to the last weighted output.
+	assertValues(weighted, 45001, []int64{31500, 13501})
+
+	// Fixed outputs are paid first.
+	assertValues(fixed, 45000, []int64{25000, 20000})
+
+	// A value that doesn't leave enough for the weighted outputs is
+	// rejected.
+	_, err = sweepTxOuts(fixed, 20100)
+	if err != ErrSweepOutputDust {
+		t.Fatalf("expected dust error, got %v", err)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/btcsuite/btcd/wire"
 	"github.com/lightninglabs/loop/lndclient"
 	"github.com/lightningnetwork/lnd/chainntnfs"
+	"github.com/lightningnetwork/lnd/lnrpc/routerrpc"
 	"github.com/lightningnetwork/lnd/lntypes"
 	"github.com/lightningnetwork/lnd/zpay32"
 )
@@ -19,6 +20,7 @@ type Context struct {
 	Lnd            *LndMockServices
 	FailedInvoices map[lntypes.Hash]struct{}
 	PaidInvoices   map[string]func(error)
+	TrackedHashes  map[lntypes.Hash]func(error)
 }
 
 // NewContext instanties a new common test context.
@@ -30,6 +32,7 @@ func NewContext(t *testing.T,
 		Lnd:            lnd,
 		FailedInvoices: make(map[lntypes.Hash]struct{}),
 		PaidInvoices:   make(map[string]func(error)),
+		TrackedHashes:  make(map[lntypes.Hash]func(error)),
 	}
 }
 
@@ -111,8 +114,20 @@ func (ctx *Context) AssertRegisterConf() *ConfRegistration {
 	return confIntent
 }
 
-// AssertPaid asserts that the expected payment request has been paid. This
-// function returns a complete function to signal the final payment result.
+// PaymentStateError can be passed to the function returned by AssertPaid to
+// let the payment fail with the given final payment state.
+type PaymentStateError routerrpc.PaymentState
+
+// Error returns the name of the payment state.
+func (e PaymentStateError) Error() string {
+	return routerrpc.PaymentState(e).String()
+}
+
+// AssertPaid asserts that the expected payment request has been paid via the
+// router. This function returns a complete function to signal the final
+// payment result. A nil result lets the payment succeed, a PaymentStateError
+// lets it fail with the given state and any other error is returned as an rpc
+// error.
 func (ctx *Context) AssertPaid(
 	expectedMemo string) func(error) {
 
@@ -124,30 +139,22 @@ func (ctx *Context) AssertPaid(
 
 	// Assert that client pays swap invoice.
 	for {
-		var swapPayment PaymentChannelMessage
+		var swapPayment RouterPaymentChannelMessage
 		select {
-		case swapPayment = <-ctx.Lnd.SendPaymentChannel:
+		case swapPayment = <-ctx.Lnd.RouterSendPaymentChannel:
 		case <-time.After(Timeout):
 			ctx.T.Fatalf("no payment sent for invoice: %v",
 				expectedMemo)
 		}
 
-		payReq := ctx.DecodeInvoice(swapPayment.PaymentRequest)
+		payReq := ctx.DecodeInvoice(swapPayment.Invoice)
 
 		if _, ok := ctx.PaidInvoices[*payReq.Description]; ok {
 			ctx.T.Fatalf("duplicate invoice paid: %v",
 				*payReq.Description)
 		}
 
-		done := func(result error) {
-			select {
-			case swapPayment.Done <- lndclient.PaymentResult{
-				Err: result,
-			}:
-			case <-time.After(Timeout):
-				ctx.T.Fatalf("payment result not consumed")
-			}
-		}
+		done := ctx.paymentResultFunc(swapPayment.TrackPaymentMessage)
 
 		ctx.PaidInvoices[*payReq.Description] = done
 
@@ -157,6 +164,77 @@ func (ctx *Context) AssertPaid(
 	}
 }
 
+// AssertTrackPayment asserts that the payment with the given hash is tracked
+// via the router. This function returns a complete function to signal the
+// result of the tracked payment in the same way as AssertPaid. Return
+// channeldb.ErrPaymentNotInitiated to signal that the payment doesn't exist.
+func (ctx *Context) AssertTrackPayment(expectedHash lntypes.Hash) func(error) {
+	ctx.T.Helper()
+
+	if done, ok := ctx.TrackedHashes[expectedHash]; ok {
+		return done
+	}
+
+	for {
+		var payment TrackPaymentMessage
+		select {
+		case payment = <-ctx.Lnd.TrackPaymentChannel:
+		case <-time.After(Timeout):
+			ctx.T.Fatalf("payment not tracked: %v", expectedHash)
+		}
+
+		if _, ok := ctx.TrackedHashes[payment.Hash]; ok {
+			ctx.T.Fatalf("duplicate payment tracked: %v",
+				payment.Hash)
+		}
+
+		done := ctx.paymentResultFunc(payment)
+		ctx.TrackedHashes[payment.Hash] = done
+
+		if payment.Hash == expectedHash {
+			return done
+		}
+	}
+}
+
+// paymentResultFunc returns a function that signals the final result of a
+// payment. A nil result lets the payment succeed, a PaymentStateError lets it
+// fail with the given state and any other error is returned as an rpc error.
+func (ctx *Context) paymentResultFunc(payment TrackPaymentMessage) func(error) {
+	return func(result error) {
+		if result == nil {
+			ctx.sendPaymentUpdate(
+				payment, routerrpc.PaymentState_SUCCEEDED,
+			)
+			return
+		}
+
+		if state, ok := result.(PaymentStateError); ok {
+			ctx.sendPaymentUpdate(
+				payment, routerrpc.PaymentState(state),
+			)
+			return
+		}
+
+		select {
+		case payment.Errors <- result:
+		case <-time.After(Timeout):
+			ctx.T.Fatalf("payment error not consumed")
+		}
+	}
+}
+
+// sendPaymentUpdate delivers a payment state update to the payer.
+func (ctx *Context) sendPaymentUpdate(payment TrackPaymentMessage,
+	state routerrpc.PaymentState) {
+
+	select {
+	case payment.Updates <- lndclient.PaymentStatus{State: state}:
+	case <-time.After(Timeout):
+		ctx.T.Fatalf("payment update not consumed")
+	}
+}
+
 // AssertSettled asserts that an invoice with the given hash is settled.
 func (ctx *Context) AssertSettled(
 	expectedHash lntypes.Hash) lntypes.Preimage {
@@ -137,3 +137,25 @@ func (h *mockLightningClient) ListTransactions(
 	h.lnd.lock.Unlock()
 	return txs, nil
 }
+
+// QueryRoutes returns a single hop route to the destination.
+func (h *mockLightningClient) QueryRoutes(ctx context.Context,
+	req *lndclient.QueryRoutesRequest) (*lndclient.Route, error) {
+
+	if h.lnd.NoRoute {
+		return nil, lndclient.ErrNoRoute
+	}
+
+	return &lndclient.Route{
+		TotalFee:      h.lnd.RouteFee,
+		TotalTimeLock: 600,
+		NumHops:       1,
+	}, nil
+}
+
+// ListChannels returns the channels of the mock lnd node.
+func (h *mockLightningClient) ListChannels(ctx context.Context) (
+	[]lndclient.ChannelInfo, error) {
+
+	return h.lnd.Channels, nil
+}
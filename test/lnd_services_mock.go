@@ -6,7 +6,9 @@ import (
 	"time"
 
 	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
 	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
 	"github.com/lightninglabs/loop/lndclient"
 	"github.com/lightningnetwork/lnd/chainntnfs"
 	"github.com/lightningnetwork/lnd/lntypes"
@@ -137,6 +139,18 @@ type LndMockServices struct {
 
 	Transactions []*wire.MsgTx
 
+	// TxLabels are the labels that were attached to transactions.
+	TxLabels map[chainhash.Hash]string
+
+	// RouteFee is the routing fee of the routes returned by QueryRoutes.
+	RouteFee btcutil.Amount
+
+	// NoRoute makes QueryRoutes fail as if no route exists.
+	NoRoute bool
+
+	// Channels are the channels returned by ListChannels.
+	Channels []lndclient.ChannelInfo
+
 	WaitForFinished func()
 
 	lock sync.Mutex
@@ -161,6 +175,25 @@ func (s *LndMockServices) AddTx(tx *wire.MsgTx) {
 	s.lock.Unlock()
 }
 
+// LabelTx records the label of the given transaction.
+func (s *LndMockServices) LabelTx(txid chainhash.Hash, label string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.TxLabels == nil {
+		s.TxLabels = make(map[chainhash.Hash]string)
+	}
+	s.TxLabels[txid] = label
+}
+
+// TxLabel returns the label of the given transaction.
+func (s *LndMockServices) TxLabel(txid chainhash.Hash) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.TxLabels[txid]
+}
+
 // IsDone checks whether all channels have been fully emptied. If not this may
 // indicate unexpected behaviour of the code under test.
 func (s *LndMockServices) IsDone() error {
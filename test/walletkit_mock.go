@@ -65,6 +65,13 @@ func (m *mockWalletKit) PublishTransaction(ctx context.Context, tx *wire.MsgTx)
 	return nil
 }
 
+func (m *mockWalletKit) LabelTransaction(ctx context.Context,
+	txid chainhash.Hash, label string) error {
+
+	m.lnd.LabelTx(txid, label)
+	return nil
+}
+
 func (m *mockWalletKit) SendOutputs(ctx context.Context, outputs []*wire.TxOut,
 	feeRate chainfee.SatPerKWeight) (*wire.MsgTx, error) {
 
@@ -51,7 +51,7 @@ func newSwapClient(config *clientConfig) *Client {
 		createExpiryTimer: config.CreateExpiryTimer,
 	})
 
-	return &Client{
+	client := &Client{
 		errChan:      make(chan error),
 		clientConfig: *config,
 		lndServices:  lndServices,
@@ -59,6 +59,15 @@ func newSwapClient(config *clientConfig) *Client {
 		executor:     executor,
 		resumeReady:  make(chan struct{}),
 	}
+	client.scheduler = newScheduler(&schedulerConfig{
+		lnd:      lndServices,
+		store:    config.Store,
+		loopOut:  client.LoopOut,
+		loopIn:   client.LoopIn,
+		destAddr: client.NextDestAddr,
+	})
+
+	return client
 }
 
 func createClientTestContext(t *testing.T,
@@ -86,9 +95,11 @@ func createClientTestContext(t *testing.T,
 
 	swapClient := newSwapClient(&clientConfig{
 		LndServices:       &clientLnd.LndServices,
-		Server:            serverMock,
 		Store:             store,
 		CreateExpiryTimer: timerFactory,
+		Servers: []*swapServer{
+			{swapServerClient: serverMock, id: testServerID},
+		},
 	})
 
 	statusChan := make(chan SwapInfo)
@@ -113,6 +124,7 @@ func createClientTestContext(t *testing.T,
 		err := swapClient.Run(
 			runCtx,
 			statusChan,
+			nil,
 		)
 		log.Errorf("client run: %v", err)
 		ctx.runErr <- err